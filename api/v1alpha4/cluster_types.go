@@ -30,6 +30,16 @@ import (
 
 const (
 	ClusterFinalizer = "cluster.cluster.x-k8s.io"
+
+	// SkipNodeDeletionAnnotation is the annotation set on a Cluster whose Node lifecycle is managed externally, e.g. by
+	// the cloud provider; if set, the Machine controller does not delete the Nodes of the Machines being deleted, and
+	// leaves their cleanup to the external system. Nodes are still drained, unless draining is excluded.
+	SkipNodeDeletionAnnotation = "cluster.x-k8s.io/skip-node-deletion"
+
+	// MaxConcurrentDrainsAnnotation is the annotation set on a Cluster to limit the number of its Machines whose Node
+	// is drained concurrently, e.g. when remediations and rollouts happen at the same time in a small cluster; the value
+	// must be a positive integer. Machines exceeding the limit wait for the other drains to complete before draining.
+	MaxConcurrentDrainsAnnotation = "cluster.x-k8s.io/max-concurrent-drains"
 )
 
 // ANCHOR: ClusterSpec
@@ -57,10 +67,40 @@ type ClusterSpec struct {
 	// for provisioning infrastructure for a cluster in said provider.
 	// +optional
 	InfrastructureRef *corev1.ObjectReference `json:"infrastructureRef,omitempty"`
+
+	// WorkloadComponentHealthChecks is an optional list of components running in the workload cluster, e.g. the CNI
+	// or the CSI driver, whose health is summarized in the WorkloadComponentsHealthy condition of the Cluster.
+	// +optional
+	WorkloadComponentHealthChecks []WorkloadComponentHealthCheck `json:"workloadComponentHealthChecks,omitempty"`
 }
 
 // ANCHOR_END: ClusterSpec
 
+// ANCHOR: WorkloadComponentHealthCheck
+
+// WorkloadComponentHealthCheck identifies an object in the workload cluster whose health is checked.
+// DaemonSets are healthy when all their scheduled Pods are ready, Deployments and StatefulSets when all their
+// replicas are ready; objects of other kinds are healthy when their Ready or Available condition, if any, is true.
+type WorkloadComponentHealthCheck struct {
+	// APIVersion of the object, e.g. apps/v1.
+	// +kubebuilder:validation:MinLength=1
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object, e.g. DaemonSet.
+	// +kubebuilder:validation:MinLength=1
+	Kind string `json:"kind"`
+
+	// Namespace of the object; it must be empty for cluster-scoped objects.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// ANCHOR_END: WorkloadComponentHealthCheck
+
 // ANCHOR: ClusterNetwork
 
 // ClusterNetwork specifies the different networking
@@ -17,8 +17,11 @@ limitations under the License.
 package v1alpha4
 
 import (
+	"strconv"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -87,6 +90,32 @@ func (c *Cluster) validate() error {
 
 	}
 
+	if value, ok := c.Annotations[MaxConcurrentDrainsAnnotation]; ok {
+		if max, err := strconv.Atoi(value); err != nil || max <= 0 {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("metadata", "annotations", MaxConcurrentDrainsAnnotation),
+					value,
+					"must be a positive integer",
+				),
+			)
+		}
+	}
+
+	for i, check := range c.Spec.WorkloadComponentHealthChecks {
+		if _, err := schema.ParseGroupVersion(check.APIVersion); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(
+					field.NewPath("spec", "workloadComponentHealthChecks").Index(i).Child("apiVersion"),
+					check.APIVersion,
+					err.Error(),
+				),
+			)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
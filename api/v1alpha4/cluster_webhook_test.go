@@ -63,6 +63,25 @@ func TestClusterValidation(t *testing.T) {
 	invalidCPNamespace := valid.DeepCopy()
 	invalidCPNamespace.Spec.InfrastructureRef.Namespace = "baz"
 
+	validMaxConcurrentDrains := valid.DeepCopy()
+	validMaxConcurrentDrains.Annotations = map[string]string{MaxConcurrentDrainsAnnotation: "2"}
+
+	invalidMaxConcurrentDrains := valid.DeepCopy()
+	invalidMaxConcurrentDrains.Annotations = map[string]string{MaxConcurrentDrainsAnnotation: "0"}
+
+	notANumberMaxConcurrentDrains := valid.DeepCopy()
+	notANumberMaxConcurrentDrains.Annotations = map[string]string{MaxConcurrentDrainsAnnotation: "two"}
+
+	validWorkloadComponentHealthChecks := valid.DeepCopy()
+	validWorkloadComponentHealthChecks.Spec.WorkloadComponentHealthChecks = []WorkloadComponentHealthCheck{
+		{APIVersion: "apps/v1", Kind: "DaemonSet", Namespace: "kube-system", Name: "calico-node"},
+	}
+
+	invalidWorkloadComponentHealthChecks := valid.DeepCopy()
+	invalidWorkloadComponentHealthChecks.Spec.WorkloadComponentHealthChecks = []WorkloadComponentHealthCheck{
+		{APIVersion: "apps/v1/beta", Kind: "DaemonSet", Namespace: "kube-system", Name: "calico-node"},
+	}
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -83,6 +102,31 @@ func TestClusterValidation(t *testing.T) {
 			expectErr: false,
 			c:         valid,
 		},
+		{
+			name:      "should succeed when max concurrent drains is a positive integer",
+			expectErr: false,
+			c:         validMaxConcurrentDrains,
+		},
+		{
+			name:      "should return error when max concurrent drains is not positive",
+			expectErr: true,
+			c:         invalidMaxConcurrentDrains,
+		},
+		{
+			name:      "should return error when max concurrent drains is not an integer",
+			expectErr: true,
+			c:         notANumberMaxConcurrentDrains,
+		},
+		{
+			name:      "should succeed when workload component health checks are valid",
+			expectErr: false,
+			c:         validWorkloadComponentHealthChecks,
+		},
+		{
+			name:      "should return error when a workload component health check has an invalid apiVersion",
+			expectErr: true,
+			c:         invalidWorkloadComponentHealthChecks,
+		},
 	}
 
 	for _, tt := range tests {
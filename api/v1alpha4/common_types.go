@@ -71,6 +71,38 @@ const (
 	// that was cloned for the machine. This annotation is set only during cloning a template. Older/adopted machines will not have this annotation.
 	TemplateClonedFromGroupKindAnnotation = "cluster.x-k8s.io/cloned-from-groupkind"
 
+	// InfrastructureTemplateHashAnnotation is the annotation set on MachineSets and MachineDeployments that stores the hash
+	// of the spec of the referenced infrastructure template. It is used to resolve the template again if it gets deleted and
+	// recreated with a different name, e.g. by GitOps workflows.
+	InfrastructureTemplateHashAnnotation = "cluster.x-k8s.io/infrastructure-template-hash"
+
+	// BootstrapTemplateHashAnnotation is the annotation set on MachineSets and MachineDeployments that stores the hash
+	// of the spec of the referenced bootstrap config template. It is used to resolve the template again if it gets deleted and
+	// recreated with a different name, e.g. by GitOps workflows.
+	BootstrapTemplateHashAnnotation = "cluster.x-k8s.io/bootstrap-template-hash"
+
+	// BootstrapDataEncryptionAnnotation is the annotation set on bootstrap data secrets whose value is encrypted;
+	// the value of the annotation is the name of the encryption provider that must be used to decrypt the data
+	// before serving it to the infrastructure.
+	BootstrapDataEncryptionAnnotation = "cluster.x-k8s.io/bootstrap-data-encryption"
+
+	// ControlPlaneRemediationBlockedAnnotation is the annotation set by the control plane provider on the control plane object
+	// while it is not safe to remediate control plane Machines, e.g. while the control plane is provisioning, upgrading or scaling;
+	// the value of the annotation is the reason why remediation is blocked. MachineHealthChecks do not mark control plane
+	// Machines for remediation while the annotation is set.
+	ControlPlaneRemediationBlockedAnnotation = "cluster.x-k8s.io/control-plane-remediation-blocked"
+
+	// ControlPlaneRemediationRequestAnnotation is the annotation set by MachineHealthChecks on the control plane object with
+	// the name of the control plane Machine marked for remediation; the control plane provider should remediate only this Machine,
+	// and MachineHealthChecks do not mark other control plane Machines for remediation until the annotation is removed, once
+	// the Machine has been remediated and the control plane is back to the desired number of Machines.
+	ControlPlaneRemediationRequestAnnotation = "cluster.x-k8s.io/control-plane-remediation-request"
+
+	// NodeShutdownRequestedAnnotation is the annotation set on nodes before they are drained, when the Machine's
+	// NodeShutdownGracePeriod is set, to signal the upcoming shutdown to the applications running on them; its value
+	// is the time, in RFC3339 format, after which the node is drained.
+	NodeShutdownRequestedAnnotation = "cluster.x-k8s.io/shutdown-requested"
+
 	// ClusterSecretType defines the type of secret created by core components
 	ClusterSecretType corev1.SecretType = "cluster.x-k8s.io/secret" //nolint:gosec
 
@@ -187,3 +219,71 @@ type ObjectMeta struct {
 	// +patchStrategy=merge
 	OwnerReferences []metav1.OwnerReference `json:"ownerReferences,omitempty" patchStrategy:"merge" patchMergeKey:"uid"`
 }
+
+// ANCHOR: OperationRecord
+
+// OperationOutcome is the outcome of an operation recorded in the operation history of an object.
+type OperationOutcome string
+
+const (
+	// OperationStarted documents an operation that has been started and is not completed yet.
+	OperationStarted OperationOutcome = "Started"
+
+	// OperationSucceeded documents an operation that has been completed successfully.
+	OperationSucceeded OperationOutcome = "Succeeded"
+
+	// OperationFailed documents an operation that failed.
+	OperationFailed OperationOutcome = "Failed"
+)
+
+// MaxOperationHistory is the maximum number of operations kept in the operation history of an object.
+const MaxOperationHistory = 5
+
+// OperationRecord records a significant operation performed by a controller on an object.
+type OperationRecord struct {
+	// Operation is a short, human readable description of the operation, e.g. "drain node".
+	Operation string `json:"operation"`
+
+	// Outcome of the operation.
+	Outcome OperationOutcome `json:"outcome"`
+
+	// Message is a human readable message with details about the operation, e.g. the error for failed operations.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Time is the time the operation has been recorded at.
+	Time metav1.Time `json:"time"`
+}
+
+// ANCHOR_END: OperationRecord
+
+// ANCHOR: TemplateMetadataPolicy
+
+// TemplateMetadataPolicy controls the labels and the annotations of the objects cloned from templates, e.g.
+// the infrastructure machines and the bootstrap configs.
+// The metadata of the template spec is always cloned; in all the lists below, a key ending with "/" matches
+// all the keys with that prefix.
+type TemplateMetadataPolicy struct {
+	// PropagateLabels is a list of keys of the labels of the template object itself to be cloned,
+	// in addition to the labels of the template spec.
+	// +optional
+	PropagateLabels []string `json:"propagateLabels,omitempty"`
+
+	// PropagateAnnotations is a list of keys of the annotations of the template object itself to be cloned,
+	// in addition to the annotations of the template spec.
+	// +optional
+	PropagateAnnotations []string `json:"propagateAnnotations,omitempty"`
+
+	// StripLabels is a list of keys of the labels not to be cloned from the template, e.g. the bookkeeping
+	// labels of GitOps tools. The labels set by Cluster API on the cloned objects are never stripped.
+	// +optional
+	StripLabels []string `json:"stripLabels,omitempty"`
+
+	// StripAnnotations is a list of keys of the annotations not to be cloned from the template, e.g. the
+	// bookkeeping annotations of GitOps tools. The annotations set by Cluster API on the cloned objects
+	// are never stripped.
+	// +optional
+	StripAnnotations []string `json:"stripAnnotations,omitempty"`
+}
+
+// ANCHOR_END: TemplateMetadataPolicy
@@ -48,6 +48,10 @@ const (
 	// to be available.
 	// NOTE: This reason is used only as a fallback when the infrastructure object is not reporting its own ready condition.
 	WaitingForInfrastructureFallbackReason = "WaitingForInfrastructure"
+
+	// InsufficientCapacityReason (Severity=Warning) documents a machine whose infrastructure can't be provisioned
+	// because the infrastructure object reports the InsufficientCapacity failure hint.
+	InsufficientCapacityReason = "InsufficientCapacity"
 )
 
 // ANCHOR_END: CommonConditions
@@ -72,6 +76,42 @@ const (
 	// NOTE: Having the control plane machine available is a pre-condition for joining additional control planes
 	// or workers nodes.
 	WaitingForControlPlaneAvailableReason = "WaitingForControlPlaneAvailable"
+
+	// ControlPlaneEndpointTrustedCondition reports if the certificate served by the workload cluster API server
+	// can be verified using the certificate authority stored in the Cluster's kubeconfig secret.
+	ControlPlaneEndpointTrustedCondition ConditionType = "ControlPlaneEndpointTrusted"
+
+	// CertificateAuthorityMismatchReason (Severity=Error) documents a Cluster whose API server is serving a certificate
+	// not signed by the certificate authority in the kubeconfig secret, e.g. because the CA has been rotated
+	// without updating the kubeconfig secret.
+	CertificateAuthorityMismatchReason = "CertificateAuthorityMismatch"
+
+	// WorkloadComponentsHealthyCondition reports the health of the components running in the workload cluster which
+	// are listed in the Cluster's WorkloadComponentHealthChecks, e.g. the CNI or the CSI driver.
+	WorkloadComponentsHealthyCondition ConditionType = "WorkloadComponentsHealthy"
+
+	// WorkloadComponentsUnhealthyReason (Severity=Warning) documents a Cluster with components in the workload cluster
+	// which are missing or not healthy; the condition message lists them.
+	WorkloadComponentsUnhealthyReason = "WorkloadComponentsUnhealthy"
+
+	// WorkloadComponentsCheckFailedReason (Severity=Warning) documents a Cluster whose workload cluster components
+	// can't be checked, e.g. because the workload cluster API server is not reachable.
+	WorkloadComponentsCheckFailedReason = "WorkloadComponentsCheckFailed"
+
+	// DeletionBlockedCondition documents a Cluster whose deletion is waiting for descendants or referenced objects
+	// to be deleted; the condition message lists the objects still existing, and for the ones being deleted
+	// the finalizers they are still holding and for how long they have been deleted.
+	DeletionBlockedCondition ConditionType = "DeletionBlocked"
+
+	// WaitingForDescendantsDeletionReason documents a Cluster waiting for its descendants, e.g. MachineDeployments
+	// or Machines, to be deleted.
+	WaitingForDescendantsDeletionReason = "WaitingForDescendantsDeletion"
+
+	// WaitingForControlPlaneDeletionReason documents a Cluster waiting for its control plane object to be deleted.
+	WaitingForControlPlaneDeletionReason = "WaitingForControlPlaneDeletion"
+
+	// WaitingForInfrastructureDeletionReason documents a Cluster waiting for its infrastructure object to be deleted.
+	WaitingForInfrastructureDeletionReason = "WaitingForInfrastructureDeletion"
 )
 
 // Conditions and condition Reasons for the Machine object
@@ -101,11 +141,31 @@ const (
 	// PreDrainDeleteHookSucceededCondition reports a machine waiting for a PreDrainDeleteHook before being delete.
 	PreDrainDeleteHookSucceededCondition ConditionType = "PreDrainDeleteHookSucceeded"
 
+	// NodeShutdownSignaledCondition reports the upcoming shutdown of the machine node being signaled to the
+	// applications running on it before draining the node, and the controller waiting for the grace period to expire.
+	NodeShutdownSignaledCondition ConditionType = "NodeShutdownSignaled"
+
+	// WaitingForNodeShutdownGracePeriodReason (Severity=Info) documents a machine waiting for the node shutdown
+	// grace period to expire before draining the node.
+	WaitingForNodeShutdownGracePeriodReason = "WaitingForNodeShutdownGracePeriod"
+
 	// PreTerminateDeleteHookSucceededCondition reports a machine waiting for a PreDrainDeleteHook before being delete.
 	PreTerminateDeleteHookSucceededCondition ConditionType = "PreTerminateDeleteHookSucceeded"
 
 	// WaitingExternalHookReason (Severity=Info) provide evidence that we are waiting for an external hook to complete.
 	WaitingExternalHookReason = "WaitingExternalHook"
+
+	// ExternalHookTimeoutExceededReason (Severity=Warning) documents external hooks being skipped because
+	// the Machine has been waiting for them longer than the configured timeout.
+	ExternalHookTimeoutExceededReason = "ExternalHookTimeoutExceeded"
+
+	// ForceDeleteRequestedReason (Severity=Warning) documents external hooks or node draining being skipped
+	// because the force-delete annotation has been set on the Machine.
+	ForceDeleteRequestedReason = "ForceDeleteRequested"
+
+	// NodeRetainedReason (Severity=Info) documents node draining and deletion being skipped because of the
+	// RetainNode deletion policy of the Machine.
+	NodeRetainedReason = "NodeRetained"
 )
 
 const (
@@ -121,6 +181,14 @@ const (
 
 	// UnhealthyNodeConditionReason is the reason used when a machine's node has one of the MachineHealthCheck's unhealthy conditions.
 	UnhealthyNodeConditionReason = "UnhealthyNode"
+
+	// NodeUnreachableReason is the reason used when a machine's node has the node.kubernetes.io/unreachable taint
+	// for longer than the MachineHealthCheck's unreachable taint timeout.
+	NodeUnreachableReason = "NodeUnreachable"
+
+	// NodeLeaseStaleReason is the reason used when a machine's node kubelet Lease has not been renewed
+	// for longer than the MachineHealthCheck's node lease staleness threshold.
+	NodeLeaseStaleReason = "NodeLeaseStale"
 )
 
 const (
@@ -183,4 +251,33 @@ const (
 	// TooManyUnhealthy is the reason used when too many Machines are unhealthy and the MachineHealthCheck is blocked
 	// from making any further remediations.
 	TooManyUnhealthyReason = "TooManyUnhealthy"
+
+	// ClusterInfrastructureNotReadyReason (Severity=Info) documents a MachineHealthCheck not checking the health of
+	// its Machines because the Cluster infrastructure is not ready yet.
+	ClusterInfrastructureNotReadyReason = "ClusterInfrastructureNotReady"
+
+	// ControlPlaneNotInitializedReason (Severity=Info) documents a MachineHealthCheck not checking the health of
+	// its Machines because the Cluster control plane is not initialized yet.
+	ControlPlaneNotInitializedReason = "ControlPlaneNotInitialized"
+)
+
+// Conditions and condition Reasons for the MachineSet object
+
+const (
+	// TemplatesResolvedCondition documents that the bootstrap config and infrastructure templates referenced by
+	// a MachineSet exist and can be used to create new Machines.
+	TemplatesResolvedCondition ConditionType = "TemplatesResolved"
+
+	// FailedTemplateResolutionReason (Severity=Error) documents a MachineSet referencing a bootstrap config or
+	// infrastructure template which does not exist and that can't be resolved by its template hash; Machines
+	// can't be created until the reference is fixed.
+	FailedTemplateResolutionReason = "FailedTemplateResolution"
+
+	// ScaleDownUnblockedCondition documents that the scale down of a MachineSet with the
+	// ScaleDownProtectedPodSelectorAnnotation is not blocked by Machines whose Node runs protected Pods.
+	ScaleDownUnblockedCondition ConditionType = "ScaleDownUnblocked"
+
+	// ScaleDownProtectedPodsReason (Severity=Warning) documents a MachineSet which can't delete enough Machines to
+	// scale down, because the Nodes of the other Machines run Pods matching the ScaleDownProtectedPodSelectorAnnotation.
+	ScaleDownProtectedPodsReason = "ScaleDownProtectedPods"
 )
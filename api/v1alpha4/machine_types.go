@@ -49,8 +49,65 @@ const (
 	// to pause reconciliation of deletion. These hooks will prevent removal of
 	// an instance from an infrastructure provider until all are removed.
 	PreTerminateDeleteHookAnnotationPrefix = "pre-terminate.delete.hook.machine.cluster.x-k8s.io"
+
+	// ForceDeleteAnnotation annotation can be set by an operator on a Machine being deleted to skip
+	// the remaining pre-drain.delete and pre-terminate.delete lifecycle hooks as well as node draining,
+	// e.g. when the controller owning a hook is not running anymore.
+	ForceDeleteAnnotation = "cluster.x-k8s.io/force-delete"
+
+	// NodeNameAnnotation annotation can be set by bootstrap providers on a Machine to document the name the Node
+	// will register with; it is used to match the Node when the infrastructure provider doesn't report a ProviderID
+	// and the NodeMatchingFallback feature is enabled.
+	NodeNameAnnotation = "cluster.x-k8s.io/node-name"
 )
 
+// MachineFailureHint is a hint reported by infrastructure providers in the infrastructure machine
+// status.failureHint field, documenting a non-terminal reason for the infrastructure not being provisioned.
+type MachineFailureHint string
+
+const (
+	// InsufficientCapacityFailureHint documents that the infrastructure provider can't provision the machine
+	// because there is not enough capacity in the machine's failure domain.
+	InsufficientCapacityFailureHint MachineFailureHint = "InsufficientCapacity"
+)
+
+// MachineInstanceState is the lifecycle state of the instance backing a machine, as reported by infrastructure
+// providers in the infrastructure machine status.instanceState field.
+type MachineInstanceState string
+
+const (
+	// InstanceStateRunning documents that the instance is running.
+	InstanceStateRunning MachineInstanceState = "Running"
+
+	// InstanceStateStopped documents that the instance is stopped, and it can be started again.
+	InstanceStateStopped MachineInstanceState = "Stopped"
+
+	// InstanceStateTerminatedExternally documents that the instance has been terminated outside of Cluster API,
+	// e.g. by the cloud provider or by an operator; the machine is marked as failed without waiting for the
+	// node to become unhealthy, so it can be remediated.
+	InstanceStateTerminatedExternally MachineInstanceState = "TerminatedExternally"
+)
+
+// MachineInstanceMetadata is the provider-independent metadata of the instance backing a machine, as reported by
+// infrastructure providers in the infrastructure machine status.instanceMetadata field.
+type MachineInstanceMetadata struct {
+	// InstanceType is the type, size or flavor of the instance, e.g. m5.large.
+	// +optional
+	InstanceType string `json:"instanceType,omitempty"`
+
+	// Zone is the zone or location the instance is running in, e.g. us-east-1a.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Image is the identifier of the image the instance has been created from, e.g. an AMI ID.
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// PriceTier is the pricing model of the instance, e.g. OnDemand, Spot or Reserved.
+	// +optional
+	PriceTier string `json:"priceTier,omitempty"`
+}
+
 // ANCHOR: MachineSpec
 
 // MachineSpec defines the desired state of Machine
@@ -95,10 +152,53 @@ type MachineSpec struct {
 	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 	// +optional
 	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// NodeDeletionTimeout is the total amount of time that the controller will spend on deleting the node
+	// once the infrastructure of the Machine has been deleted; once expired, the node is left behind.
+	// Defaults to 10 seconds; a value of 0 means that the controller retries the deletion without any time limitations.
+	// +optional
+	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
+
+	// PreDrainDeleteHookTimeout is the total amount of time that the controller will wait for the
+	// pre-drain.delete lifecycle hooks to be removed; once expired, the remaining hooks are skipped.
+	// The default value is 0, meaning that the controller waits for the hooks without any time limitations.
+	// +optional
+	PreDrainDeleteHookTimeout *metav1.Duration `json:"preDrainDeleteHookTimeout,omitempty"`
+
+	// PreTerminateDeleteHookTimeout is the total amount of time that the controller will wait for the
+	// pre-terminate.delete lifecycle hooks to be removed; once expired, the remaining hooks are skipped.
+	// The default value is 0, meaning that the controller waits for the hooks without any time limitations.
+	// +optional
+	PreTerminateDeleteHookTimeout *metav1.Duration `json:"preTerminateDeleteHookTimeout,omitempty"`
+
+	// NodeShutdownGracePeriod is the amount of time that the controller waits before draining the node, after
+	// signaling the upcoming shutdown to the applications running on it by setting the
+	// cluster.x-k8s.io/shutdown-requested annotation on the node; it gives stateful applications the time
+	// to checkpoint their state. The default value is 0, meaning that the node is drained without signaling.
+	// +optional
+	NodeShutdownGracePeriod *metav1.Duration `json:"nodeShutdownGracePeriod,omitempty"`
+
+	// DeletionPolicy defines what happens to the node when the machine is deleted. With "RetainNode", the node
+	// is neither drained nor deleted, e.g. for troubleshooting or when the node lifecycle is owned externally,
+	// while the infrastructure and bootstrap objects are still deleted. Defaults to "Delete".
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;RetainNode
+	DeletionPolicy MachineDeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
 // ANCHOR_END: MachineSpec
 
+// MachineDeletionPolicy defines what happens to the node of a machine when the machine is deleted.
+type MachineDeletionPolicy string
+
+const (
+	// DeleteMachineDeletionPolicy drains and deletes the node of the machine.
+	DeleteMachineDeletionPolicy MachineDeletionPolicy = "Delete"
+
+	// RetainNodeMachineDeletionPolicy leaves the node of the machine in place, without draining it.
+	RetainNodeMachineDeletionPolicy MachineDeletionPolicy = "RetainNode"
+)
+
 // ANCHOR: MachineStatus
 
 // MachineStatus defines the observed state of Machine
@@ -161,6 +261,35 @@ type MachineStatus struct {
 	// +optional
 	Addresses MachineAddresses `json:"addresses,omitempty"`
 
+	// Capacity represents the total resources of the machine, e.g. cpu and memory.
+	// This field is copied from the Node once it exists; before that, it is copied from the
+	// infrastructure provider reference if it reports a status.capacity field.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+
+	// NodeInfo is the system information of the machine, e.g. architecture and OS image.
+	// This field is copied from the Node once it exists; before that, it is copied from the
+	// infrastructure provider reference if it reports a status.nodeInfo field.
+	// +optional
+	NodeInfo *corev1.NodeSystemInfo `json:"nodeInfo,omitempty"`
+
+	// InstanceState is the lifecycle state of the instance backing the machine.
+	// This field is copied from the infrastructure provider reference, if it reports a status.instanceState field.
+	// +optional
+	InstanceState MachineInstanceState `json:"instanceState,omitempty"`
+
+	// InstanceMetadata is the metadata of the instance backing the machine, e.g. its instance type and zone.
+	// This field is copied from the infrastructure provider reference, if it reports a status.instanceMetadata field.
+	// +optional
+	InstanceMetadata *MachineInstanceMetadata `json:"instanceMetadata,omitempty"`
+
+	// DiagnosticsRef is a reference to the ConfigMap holding the bootstrap diagnostics of the machine, e.g. an excerpt
+	// of its console or cloud-init logs, collected when its node did not start up within the node startup timeout of
+	// a MachineHealthCheck. It is set only if the infrastructure or the bootstrap provider reference reports a
+	// status.bootstrapDiagnostics field.
+	// +optional
+	DiagnosticsRef *corev1.ObjectReference `json:"diagnosticsRef,omitempty"`
+
 	// Phase represents the current phase of machine actuation.
 	// E.g. Pending, Running, Terminating, Failed etc.
 	// +optional
@@ -181,6 +310,11 @@ type MachineStatus struct {
 	// Conditions defines current service state of the Machine.
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
+
+	// OperationHistory records the last significant operations performed on the Machine, oldest first,
+	// e.g. draining or deleting its Node; it is capped to the last 5 operations.
+	// +optional
+	OperationHistory []OperationRecord `json:"operationHistory,omitempty"`
 }
 
 // ANCHOR_END: MachineStatus
@@ -253,6 +387,14 @@ func (m *Machine) SetConditions(conditions Conditions) {
 	m.Status.Conditions = conditions
 }
 
+func (m *Machine) GetOperationHistory() []OperationRecord {
+	return m.Status.OperationHistory
+}
+
+func (m *Machine) SetOperationHistory(history []OperationRecord) {
+	m.Status.OperationHistory = history
+}
+
 // +kubebuilder:object:root=true
 
 // MachineList contains a list of Machine
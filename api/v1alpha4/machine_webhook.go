@@ -22,8 +22,10 @@ import (
 	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -127,6 +129,19 @@ func (m *Machine) validate(old *Machine) error {
 		}
 	}
 
+	// Machines controlled by another object, e.g. a MachineSet or a KubeadmControlPlane, are created with the version
+	// validated on the owner; validating them again would prevent owners already using an unsupported version from
+	// scaling up or remediating Machines.
+	if metav1.GetControllerOf(m) == nil {
+		var oldVersion *string
+		if old != nil {
+			oldVersion = old.Spec.Version
+		}
+		if err := kubernetesversion.Validate(field.NewPath("spec", "version"), m.Spec.Version, oldVersion); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
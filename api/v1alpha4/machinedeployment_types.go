@@ -40,6 +40,22 @@ const (
 	// is machinedeployment.spec.replicas + maxSurge. Used by the underlying machine sets to estimate their
 	// proportions in case the deployment has surge replicas.
 	MaxReplicasAnnotation = "machinedeployment.clusters.x-k8s.io/max-replicas"
+
+	// PreRolloutHookAnnotationPrefix annotation specifies the prefix we search each annotation for before a
+	// machine deployment starts replacing its machines. The rollout waits until all these hooks are acknowledged
+	// by setting their value to the revision of the new machine set, as documented by RolloutHookPendingAnnotation.
+	PreRolloutHookAnnotationPrefix = "pre-rollout.hook.machinedeployment.cluster.x-k8s.io"
+	// PostRolloutHookAnnotationPrefix annotation specifies the prefix we search each annotation for after a
+	// machine deployment has replaced all its machines. Old machine sets are not cleaned up, and a new rollout
+	// doesn't start, until all these hooks are acknowledged by setting their value to the revision of the rollout.
+	PostRolloutHookAnnotationPrefix = "post-rollout.hook.machinedeployment.cluster.x-k8s.io"
+	// RolloutHookPendingAnnotation is set on a machine deployment waiting for rollout hooks to be acknowledged,
+	// in the form <pre-rollout|post-rollout>/<revision>, e.g. "pre-rollout/3".
+	RolloutHookPendingAnnotation = "machinedeployment.clusters.x-k8s.io/rollout-hook-pending"
+	// PromoteCanaryAnnotation promotes the canary of a machine deployment rollout when its value is the revision
+	// of the rollout; it is required to complete a rollout when the canary uses manual promotion, and it promotes
+	// the canary without waiting for it to become healthy otherwise.
+	PromoteCanaryAnnotation = "machinedeployment.clusters.x-k8s.io/promote-canary"
 )
 
 // ANCHOR: MachineDeploymentSpec
@@ -76,6 +92,29 @@ type MachineDeploymentSpec struct {
 	// +optional
 	MinReadySeconds *int32 `json:"minReadySeconds,omitempty"`
 
+	// ProvisioningConcurrency is the maximum number of machines that can be provisioning at the same time
+	// in each of the MachineSets of the deployment, i.e. created but not yet Running.
+	// Defaults to nil (no limit).
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ProvisioningConcurrency *int32 `json:"provisioningConcurrency,omitempty"`
+
+	// NamingTemplate is a Go template used to generate the names of the machines created by the MachineSets
+	// of the deployment; see MachineSetSpec.NamingTemplate for the available values and functions.
+	// +optional
+	NamingTemplate string `json:"namingTemplate,omitempty"`
+
+	// TemplateMetadataPolicy controls the labels and the annotations cloned from the bootstrap and the
+	// infrastructure templates by the MachineSets of the deployment; see MachineSetSpec.TemplateMetadataPolicy.
+	// +optional
+	TemplateMetadataPolicy *TemplateMetadataPolicy `json:"templateMetadataPolicy,omitempty"`
+
+	// InfrastructureDeletedPolicy defines how the MachineSets of the deployment handle the machines whose
+	// infrastructure machine has been deleted outside of Cluster API; see MachineSetSpec.InfrastructureDeletedPolicy.
+	// +optional
+	// +kubebuilder:validation:Enum=Fail;Replace
+	InfrastructureDeletedPolicy MachineSetInfrastructureDeletedPolicy `json:"infrastructureDeletedPolicy,omitempty"`
+
 	// The number of old MachineSets to retain to allow rollback.
 	// This is a pointer to distinguish between explicit zero and not specified.
 	// Defaults to 1.
@@ -156,10 +195,38 @@ type MachineRollingUpdateDeployment struct {
 	// +kubebuilder:validation:Enum=Random;Newest;Oldest
 	// +optional
 	DeletePolicy *string `json:"deletePolicy,omitempty"`
+
+	// Canary configures a canary phase at the beginning of each rollout: only the canary machines are
+	// created from the new template until they are healthy for the soak period and, if required,
+	// the canary is promoted.
+	// +optional
+	Canary *MachineDeploymentCanary `json:"canary,omitempty"`
 }
 
 // ANCHOR_END: MachineRollingUpdateDeployment
 
+// MachineDeploymentCanary describes the canary phase of a rolling update.
+type MachineDeploymentCanary struct {
+	// Replicas is the number of machines created from the new template during the canary phase.
+	// Value can be an absolute number (ex: 1) or a percentage of desired machines (ex: 10%).
+	// Absolute number is calculated from percentage by rounding up, and it is at least 1.
+	Replicas intstr.IntOrString `json:"replicas"`
+
+	// SoakSeconds is the number of seconds all the canary machines must stay healthy before the canary
+	// can be promoted. A canary machine is healthy when it is available and it has not failed
+	// a MachineHealthCheck.
+	// Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	SoakSeconds *int32 `json:"soakSeconds,omitempty"`
+
+	// ManualPromotion pauses the rollout after the soak period until the canary is promoted by setting
+	// the PromoteCanaryAnnotation to the revision of the rollout.
+	// Defaults to false, i.e. the rollout continues automatically.
+	// +optional
+	ManualPromotion bool `json:"manualPromotion,omitempty"`
+}
+
 // ANCHOR: MachineDeploymentStatus
 
 // MachineDeploymentStatus defines the observed state of MachineDeployment
@@ -204,10 +271,63 @@ type MachineDeploymentStatus struct {
 	// Phase represents the current phase of a MachineDeployment (ScalingUp, ScalingDown, Running, Failed, or Unknown).
 	// +optional
 	Phase string `json:"phase,omitempty"`
+
+	// OperationHistory records the last significant operations performed on the MachineDeployment, oldest first,
+	// e.g. scaling its MachineSets; it is capped to the last 5 operations.
+	// +optional
+	OperationHistory []OperationRecord `json:"operationHistory,omitempty"`
+
+	// MachinesByPhase counts the Machines of all the MachineSets of this deployment in each phase.
+	// +optional
+	MachinesByPhase MachinesByPhase `json:"machinesByPhase,omitempty"`
+
+	// Canary reports the progress of the canary phase of the latest rollout, if the deployment uses a canary.
+	// +optional
+	Canary *MachineDeploymentCanaryStatus `json:"canary,omitempty"`
 }
 
 // ANCHOR_END: MachineDeploymentStatus
 
+// MachineDeploymentCanaryPhase is the phase of the canary of a rollout.
+type MachineDeploymentCanaryPhase string
+
+const (
+	// MachineDeploymentCanaryPhaseProgressing indicates the canary machines are being created or are not healthy yet.
+	MachineDeploymentCanaryPhaseProgressing = MachineDeploymentCanaryPhase("Progressing")
+
+	// MachineDeploymentCanaryPhaseSoaking indicates all the canary machines are healthy, and they must stay
+	// healthy until the end of the soak period.
+	MachineDeploymentCanaryPhaseSoaking = MachineDeploymentCanaryPhase("Soaking")
+
+	// MachineDeploymentCanaryPhaseWaitingForPromotion indicates the canary has soaked, and the rollout waits for
+	// the canary to be promoted with the PromoteCanaryAnnotation.
+	MachineDeploymentCanaryPhaseWaitingForPromotion = MachineDeploymentCanaryPhase("WaitingForPromotion")
+
+	// MachineDeploymentCanaryPhasePromoted indicates the canary has been promoted and the rollout continues.
+	MachineDeploymentCanaryPhasePromoted = MachineDeploymentCanaryPhase("Promoted")
+)
+
+// MachineDeploymentCanaryStatus describes the progress of the canary of a rollout.
+type MachineDeploymentCanaryStatus struct {
+	// Revision is the revision of the rollout the canary belongs to.
+	Revision string `json:"revision"`
+
+	// Phase is the phase of the canary.
+	Phase MachineDeploymentCanaryPhase `json:"phase"`
+
+	// Replicas is the number of canary machines.
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// HealthyReplicas is the number of canary machines which are healthy.
+	// +optional
+	HealthyReplicas int32 `json:"healthyReplicas,omitempty"`
+
+	// HealthySince is the time all the canary machines became healthy, i.e. the start of the soak period.
+	// +optional
+	HealthySince *metav1.Time `json:"healthySince,omitempty"`
+}
+
 // MachineDeploymentPhase indicates the progress of the machine deployment
 type MachineDeploymentPhase string
 
@@ -268,6 +388,14 @@ type MachineDeployment struct {
 	Status MachineDeploymentStatus `json:"status,omitempty"`
 }
 
+func (m *MachineDeployment) GetOperationHistory() []OperationRecord {
+	return m.Status.OperationHistory
+}
+
+func (m *MachineDeployment) SetOperationHistory(history []OperationRecord) {
+	m.Status.OperationHistory = history
+}
+
 // +kubebuilder:object:root=true
 
 // MachineDeploymentList contains a list of MachineDeployment
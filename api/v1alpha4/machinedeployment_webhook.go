@@ -25,7 +25,9 @@ import (
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/util/naming"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -92,6 +94,33 @@ func (m *MachineDeployment) validate(old *MachineDeployment) error {
 		)
 	}
 
+	if m.Spec.NamingTemplate != "" {
+		if err := naming.Validate(m.Spec.NamingTemplate); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "namingTemplate"), m.Spec.NamingTemplate, err.Error()),
+			)
+		}
+	}
+
+	if err := validateScaleDownProtectedPodSelector(m.Annotations); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	if m.Spec.Strategy != nil && m.Spec.Strategy.RollingUpdate != nil && m.Spec.Strategy.RollingUpdate.Canary != nil {
+		if err := validateCanaryReplicas(m.Spec.Strategy.RollingUpdate.Canary.Replicas); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	var oldVersion *string
+	if old != nil {
+		oldVersion = old.Spec.Template.Spec.Version
+	}
+	if err := kubernetesversion.Validate(field.NewPath("spec", "template", "spec", "version"), m.Spec.Template.Spec.Version, oldVersion); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -99,6 +128,19 @@ func (m *MachineDeployment) validate(old *MachineDeployment) error {
 	return apierrors.NewInvalid(GroupVersion.WithKind("MachineDeployment").GroupKind(), m.Name, allErrs)
 }
 
+// validateCanaryReplicas checks that the canary replicas are a positive number or percentage.
+func validateCanaryReplicas(replicas intstr.IntOrString) *field.Error {
+	fldPath := field.NewPath("spec", "strategy", "rollingUpdate", "canary", "replicas")
+	value, err := intstr.GetValueFromIntOrPercent(&replicas, 100, true)
+	if err != nil {
+		return field.Invalid(fldPath, replicas.String(), err.Error())
+	}
+	if value < 1 {
+		return field.Invalid(fldPath, replicas.String(), "must be a positive number or percentage")
+	}
+	return nil
+}
+
 // PopulateDefaultsMachineDeployment fills in default field values.
 // This is also called during MachineDeployment sync.
 func PopulateDefaultsMachineDeployment(d *MachineDeployment) {
@@ -21,8 +21,11 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
 )
 
 func TestMachineDeploymentDefault(t *testing.T) {
@@ -50,10 +53,12 @@ func TestMachineDeploymentDefault(t *testing.T) {
 
 func TestMachineDeploymentValidation(t *testing.T) {
 	tests := []struct {
-		name      string
-		selectors map[string]string
-		labels    map[string]string
-		expectErr bool
+		name           string
+		selectors      map[string]string
+		labels         map[string]string
+		namingTemplate string
+		canary         *MachineDeploymentCanary
+		expectErr      bool
 	}{
 		{
 			name:      "should return error on mismatch",
@@ -85,6 +90,41 @@ func TestMachineDeploymentValidation(t *testing.T) {
 			labels:    map[string]string{"-123-foo": "bar"},
 			expectErr: true,
 		},
+		{
+			name:           "should not return error for valid naming template",
+			selectors:      map[string]string{"foo": "bar"},
+			labels:         map[string]string{"foo": "bar"},
+			namingTemplate: "{{ .OwnerName }}-{{ .Index }}",
+			expectErr:      false,
+		},
+		{
+			name:           "should return error for invalid naming template",
+			selectors:      map[string]string{"foo": "bar"},
+			labels:         map[string]string{"foo": "bar"},
+			namingTemplate: "{{ .OwnerName }}_{{ .Index }}",
+			expectErr:      true,
+		},
+		{
+			name:      "should not return error for valid canary replicas",
+			selectors: map[string]string{"foo": "bar"},
+			labels:    map[string]string{"foo": "bar"},
+			canary:    &MachineDeploymentCanary{Replicas: intstr.FromString("10%")},
+			expectErr: false,
+		},
+		{
+			name:      "should return error for zero canary replicas",
+			selectors: map[string]string{"foo": "bar"},
+			labels:    map[string]string{"foo": "bar"},
+			canary:    &MachineDeploymentCanary{Replicas: intstr.FromInt(0)},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for invalid canary percentage",
+			selectors: map[string]string{"foo": "bar"},
+			labels:    map[string]string{"foo": "bar"},
+			canary:    &MachineDeploymentCanary{Replicas: intstr.FromString("ten")},
+			expectErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -100,6 +140,10 @@ func TestMachineDeploymentValidation(t *testing.T) {
 							Labels: tt.labels,
 						},
 					},
+					NamingTemplate: tt.namingTemplate,
+					Strategy: &MachineDeploymentStrategy{
+						RollingUpdate: &MachineRollingUpdateDeployment{Canary: tt.canary},
+					},
 				},
 			}
 			if tt.expectErr {
@@ -176,3 +220,95 @@ func TestMachineDeploymentClusterNameImmutable(t *testing.T) {
 		})
 	}
 }
+
+func TestSupportedKubernetesVersionValidation(t *testing.T) {
+	defer func() { _ = kubernetesversion.Set(kubernetesversion.Window{}) }()
+
+	newMachineDeployment := func(version string) *MachineDeployment {
+		return &MachineDeployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "foobar"},
+			Spec: MachineDeploymentSpec{
+				Template: MachineTemplateSpec{
+					Spec: MachineSpec{Version: pointer.StringPtr(version)},
+				},
+			},
+		}
+	}
+	newMachine := func(version string, controlled bool) *Machine {
+		m := &Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "foobar"},
+			Spec: MachineSpec{
+				Bootstrap:         Bootstrap{DataSecretName: pointer.StringPtr("data")},
+				InfrastructureRef: corev1.ObjectReference{Namespace: "foobar"},
+				Version:           pointer.StringPtr(version),
+			},
+		}
+		if controlled {
+			m.OwnerReferences = []metav1.OwnerReference{{Kind: "MachineSet", Name: "ms", Controller: pointer.BoolPtr(true)}}
+		}
+		return m
+	}
+
+	tests := []struct {
+		name      string
+		s         kubernetesversion.Window
+		validator func() error
+		wantErr   bool
+	}{
+		{
+			name:      "accepts versions inside the window",
+			s:         kubernetesversion.Window{Min: "v1.18", Max: "v1.20"},
+			validator: newMachineDeployment("v1.20.4").ValidateCreate,
+		},
+		{
+			name:      "rejects versions older than the minimum version",
+			s:         kubernetesversion.Window{Min: "v1.18"},
+			validator: newMachineDeployment("v1.17.9").ValidateCreate,
+			wantErr:   true,
+		},
+		{
+			name:      "rejects versions newer than the maximum version",
+			s:         kubernetesversion.Window{Max: "v1.20"},
+			validator: newMachine("v1.21.0", false).ValidateCreate,
+			wantErr:   true,
+		},
+		{
+			name:      "accepts versions outside of the window with the Warn policy",
+			s:         kubernetesversion.Window{Min: "v1.18", Policy: kubernetesversion.PolicyWarn},
+			validator: newMachineDeployment("v1.17.9").ValidateCreate,
+		},
+		{
+			name: "accepts updates not changing an unsupported version",
+			s:    kubernetesversion.Window{Min: "v1.18"},
+			validator: func() error {
+				return newMachineDeployment("v1.17.9").ValidateUpdate(newMachineDeployment("v1.17.9"))
+			},
+		},
+		{
+			name: "rejects updates to an unsupported version",
+			s:    kubernetesversion.Window{Min: "v1.18"},
+			validator: func() error {
+				return newMachineDeployment("v1.17.9").ValidateUpdate(newMachineDeployment("v1.18.2"))
+			},
+			wantErr: true,
+		},
+		{
+			name:      "accepts controlled Machines with an unsupported version",
+			s:         kubernetesversion.Window{Min: "v1.18"},
+			validator: newMachine("v1.17.9", true).ValidateCreate,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(kubernetesversion.Set(tt.s)).To(Succeed())
+
+			err := tt.validator()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
@@ -50,6 +50,18 @@ type MachineHealthCheckSpec struct {
 	// +optional
 	NodeStartupTimeout *metav1.Duration `json:"nodeStartupTimeout,omitempty"`
 
+	// UnreachableTaintTimeout is the maximum duration a node is allowed to have the
+	// node.kubernetes.io/unreachable taint, as reported by the taint's timeAdded, before
+	// being considered unhealthy. If unset, the unreachable taint is not taken into account.
+	// +optional
+	UnreachableTaintTimeout *metav1.Duration `json:"unreachableTaintTimeout,omitempty"`
+
+	// NodeLeaseStalenessThreshold is the maximum duration since the last renewal of the
+	// kubelet Lease of a node (in the kube-node-lease namespace) before the node is considered
+	// unhealthy. If unset, node leases are not taken into account.
+	// +optional
+	NodeLeaseStalenessThreshold *metav1.Duration `json:"nodeLeaseStalenessThreshold,omitempty"`
+
 	// RemediationTemplate is a reference to a remediation template
 	// provided by an infrastructure provider.
 	//
@@ -58,10 +70,36 @@ type MachineHealthCheckSpec struct {
 	// a controller that lives outside of Cluster API.
 	// +optional
 	RemediationTemplate *corev1.ObjectReference `json:"remediationTemplate,omitempty"`
+
+	// Remediation defines how the MachineHealthCheck acts on the unhealthy machines.
+	// +optional
+	Remediation *MachineHealthCheckRemediation `json:"remediation,omitempty"`
 }
 
 // ANCHOR_END: MachineHealthCHeckSpec
 
+// MachineHealthCheckRemediationMode defines whether unhealthy machines are remediated.
+type MachineHealthCheckRemediationMode string
+
+const (
+	// MachineHealthCheckRemediationModeEnforce remediates the unhealthy machines.
+	MachineHealthCheckRemediationModeEnforce = MachineHealthCheckRemediationMode("Enforce")
+
+	// MachineHealthCheckRemediationModeObserve evaluates the health of the machines, but never remediates them;
+	// the machines which would be remediated are reported by events, metrics and the MachineHealthCheck status.
+	MachineHealthCheckRemediationModeObserve = MachineHealthCheckRemediationMode("Observe")
+)
+
+// MachineHealthCheckRemediation defines how the MachineHealthCheck acts on the unhealthy machines.
+type MachineHealthCheckRemediation struct {
+	// Mode is the remediation mode, either Enforce or Observe; defaults to Enforce.
+	// Observe allows to tune the unhealthy conditions and timeouts of a MachineHealthCheck before enforcing them,
+	// by reporting the machines which would be remediated without remediating them.
+	// +kubebuilder:validation:Enum=Enforce;Observe
+	// +optional
+	Mode MachineHealthCheckRemediationMode `json:"mode,omitempty"`
+}
+
 // ANCHOR: UnhealthyCondition
 
 // UnhealthyCondition represents a Node condition type and value with a timeout
@@ -106,6 +144,11 @@ type MachineHealthCheckStatus struct {
 	// +optional
 	Targets []string `json:"targets,omitempty"`
 
+	// ObservedUnhealthyTargets is the list of unhealthy machines which would be remediated if the remediation mode
+	// was Enforce; it is set only in the Observe remediation mode.
+	// +optional
+	ObservedUnhealthyTargets []string `json:"observedUnhealthyTargets,omitempty"`
+
 	// Conditions defines current service state of the MachineHealthCheck.
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
@@ -133,6 +176,11 @@ type MachineHealthCheck struct {
 	Status MachineHealthCheckStatus `json:"status,omitempty"`
 }
 
+// IsObserveOnly returns true if the MachineHealthCheck evaluates the health of its machines without remediating them.
+func (m *MachineHealthCheck) IsObserveOnly() bool {
+	return m.Spec.Remediation != nil && m.Spec.Remediation.Mode == MachineHealthCheckRemediationModeObserve
+}
+
 func (m *MachineHealthCheck) GetConditions() Conditions {
 	return m.Status.Conditions
 }
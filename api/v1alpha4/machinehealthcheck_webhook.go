@@ -76,6 +76,10 @@ func (m *MachineHealthCheck) Default() {
 	if m.Spec.NodeStartupTimeout == nil {
 		m.Spec.NodeStartupTimeout = &defaultNodeStartupTimeout
 	}
+
+	if m.Spec.Remediation != nil && m.Spec.Remediation.Mode == "" {
+		m.Spec.Remediation.Mode = MachineHealthCheckRemediationModeEnforce
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
@@ -137,6 +141,20 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 		)
 	}
 
+	if m.Spec.UnreachableTaintTimeout != nil && m.Spec.UnreachableTaintTimeout.Duration < 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "unreachableTaintTimeout"), m.Spec.UnreachableTaintTimeout.Seconds(), "must be greater than or equal to 0"),
+		)
+	}
+
+	if m.Spec.NodeLeaseStalenessThreshold != nil && m.Spec.NodeLeaseStalenessThreshold.Duration <= 0 {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "nodeLeaseStalenessThreshold"), m.Spec.NodeLeaseStalenessThreshold.Seconds(), "must be greater than 0"),
+		)
+	}
+
 	if m.Spec.MaxUnhealthy != nil {
 		if _, err := intstr.GetValueFromIntOrPercent(m.Spec.MaxUnhealthy, 0, false); err != nil {
 			allErrs = append(
@@ -153,6 +171,18 @@ func (m *MachineHealthCheck) validate(old *MachineHealthCheck) error {
 		}
 	}
 
+	if m.Spec.Remediation != nil {
+		switch m.Spec.Remediation.Mode {
+		case "", MachineHealthCheckRemediationModeEnforce, MachineHealthCheckRemediationModeObserve:
+		default:
+			allErrs = append(
+				allErrs,
+				field.NotSupported(field.NewPath("spec", "remediation", "mode"), m.Spec.Remediation.Mode,
+					[]string{string(MachineHealthCheckRemediationModeEnforce), string(MachineHealthCheckRemediationModeObserve)}),
+			)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -36,6 +36,12 @@ func TestMachineHealthCheckDefault(t *testing.T) {
 	g.Expect(mhc.Spec.MaxUnhealthy.String()).To(Equal("100%"))
 	g.Expect(mhc.Spec.NodeStartupTimeout).ToNot(BeNil())
 	g.Expect(*mhc.Spec.NodeStartupTimeout).To(Equal(metav1.Duration{Duration: 10 * time.Minute}))
+	g.Expect(mhc.Spec.Remediation).To(BeNil())
+
+	mhc.Spec.Remediation = &MachineHealthCheckRemediation{}
+	mhc.Default()
+
+	g.Expect(mhc.Spec.Remediation.Mode).To(Equal(MachineHealthCheckRemediationModeEnforce))
 }
 
 func TestMachineHealthCheckLabelSelectorAsSelectorValidation(t *testing.T) {
@@ -200,6 +206,74 @@ func TestMachineHealthCheckNodeStartupTimeout(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckUnreachableTaintAndNodeLease(t *testing.T) {
+	zero := metav1.Duration{Duration: 0}
+	oneMinute := metav1.Duration{Duration: 1 * time.Minute}
+	minusOneMinute := metav1.Duration{Duration: -1 * time.Minute}
+
+	tests := []struct {
+		name                        string
+		unreachableTaintTimeout     *metav1.Duration
+		nodeLeaseStalenessThreshold *metav1.Duration
+		expectErr                   bool
+	}{
+		{
+			name:      "when neither the unreachableTaintTimeout nor the nodeLeaseStalenessThreshold are given",
+			expectErr: false,
+		},
+		{
+			name:                        "when both are greater than 0",
+			unreachableTaintTimeout:     &oneMinute,
+			nodeLeaseStalenessThreshold: &oneMinute,
+			expectErr:                   false,
+		},
+		{
+			name:                    "when the unreachableTaintTimeout is 0",
+			unreachableTaintTimeout: &zero,
+			expectErr:               false,
+		},
+		{
+			name:                    "when the unreachableTaintTimeout is less than 0",
+			unreachableTaintTimeout: &minusOneMinute,
+			expectErr:               true,
+		},
+		{
+			name:                        "when the nodeLeaseStalenessThreshold is 0",
+			nodeLeaseStalenessThreshold: &zero,
+			expectErr:                   true,
+		},
+		{
+			name:                        "when the nodeLeaseStalenessThreshold is less than 0",
+			nodeLeaseStalenessThreshold: &minusOneMinute,
+			expectErr:                   true,
+		},
+	}
+
+	for _, tt := range tests {
+		g := NewWithT(t)
+
+		mhc := &MachineHealthCheck{
+			Spec: MachineHealthCheckSpec{
+				UnreachableTaintTimeout:     tt.unreachableTaintTimeout,
+				NodeLeaseStalenessThreshold: tt.nodeLeaseStalenessThreshold,
+				Selector: metav1.LabelSelector{
+					MatchLabels: map[string]string{
+						"test": "test",
+					},
+				},
+			},
+		}
+
+		if tt.expectErr {
+			g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+		} else {
+			g.Expect(mhc.ValidateCreate()).To(Succeed())
+			g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+		}
+	}
+}
+
 func TestMachineHealthCheckMaxUnhealthy(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -253,6 +327,62 @@ func TestMachineHealthCheckMaxUnhealthy(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckRemediationMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      MachineHealthCheckRemediationMode
+		expectErr bool
+	}{
+		{
+			name:      "when the mode is empty",
+			mode:      "",
+			expectErr: false,
+		},
+		{
+			name:      "when the mode is Enforce",
+			mode:      MachineHealthCheckRemediationModeEnforce,
+			expectErr: false,
+		},
+		{
+			name:      "when the mode is Observe",
+			mode:      MachineHealthCheckRemediationModeObserve,
+			expectErr: false,
+		},
+		{
+			name:      "when the mode is unknown",
+			mode:      "DryRun",
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mhc := &MachineHealthCheck{
+				Spec: MachineHealthCheckSpec{
+					Selector: metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							"test": "test",
+						},
+					},
+					Remediation: &MachineHealthCheckRemediation{
+						Mode: tt.mode,
+					},
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(mhc.ValidateCreate()).NotTo(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).NotTo(Succeed())
+			} else {
+				g.Expect(mhc.ValidateCreate()).To(Succeed())
+				g.Expect(mhc.ValidateUpdate(mhc)).To(Succeed())
+			}
+		})
+	}
+}
+
 func TestMachineHealthCheckSelectorValidation(t *testing.T) {
 	g := NewWithT(t)
 	mhc := &MachineHealthCheck{}
@@ -49,6 +49,35 @@ type MachineSetSpec struct {
 	// +kubebuilder:validation:Enum=Random;Newest;Oldest
 	DeletePolicy string `json:"deletePolicy,omitempty"`
 
+	// ProvisioningConcurrency is the maximum number of machines that can be provisioning at the same time,
+	// i.e. created but not yet Running; when scaling up, the remaining machines are created as the
+	// provisioning ones become Running.
+	// Defaults to nil (no limit).
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ProvisioningConcurrency *int32 `json:"provisioningConcurrency,omitempty"`
+
+	// NamingTemplate is a Go template used to generate the names of the machines created by the MachineSet.
+	// The template can reference .ClusterName, .OwnerName (the name of the owning MachineDeployment, or of the
+	// MachineSet itself) and .Index, which is incremented when a generated name collides with an existing machine;
+	// the functions "random N" and "trunc N STRING" are also available.
+	// Defaults to an empty string, meaning that the names are generated from the MachineSet name with a random suffix.
+	// +optional
+	NamingTemplate string `json:"namingTemplate,omitempty"`
+
+	// TemplateMetadataPolicy controls the labels and the annotations cloned from the bootstrap and the
+	// infrastructure templates onto the objects created for each machine.
+	// Defaults to nil, meaning that only the metadata of the template specs is cloned.
+	// +optional
+	TemplateMetadataPolicy *TemplateMetadataPolicy `json:"templateMetadataPolicy,omitempty"`
+
+	// InfrastructureDeletedPolicy defines how the MachineSet handles the machines whose infrastructure machine
+	// has been deleted outside of Cluster API after being ready; these machines are always marked as failed.
+	// Defaults to "Fail". Valid values are "Fail" and "Replace".
+	// +optional
+	// +kubebuilder:validation:Enum=Fail;Replace
+	InfrastructureDeletedPolicy MachineSetInfrastructureDeletedPolicy `json:"infrastructureDeletedPolicy,omitempty"`
+
 	// Selector is a label query over machines that should match the replica count.
 	// Label keys and values that must match in order to be controlled by this MachineSet.
 	// It must match the machine template's labels.
@@ -105,6 +134,26 @@ const (
 	OldestMachineSetDeletePolicy MachineSetDeletePolicy = "Oldest"
 )
 
+// ScaleDownProtectedPodSelectorAnnotation is the annotation set on a MachineSet, or on a MachineDeployment which
+// copies it to its MachineSets, to protect from scale down the Machines whose Node runs Pods matching the label
+// selector in its value, e.g. "cluster.x-k8s.io/scale-down-protected". The protected Machines are deleted only when
+// marked with the DeleteMachineAnnotation; if there are not enough other Machines to delete, the scale down is blocked
+// until the Pods are gone, and reported by the ScaleDownUnblocked condition.
+const ScaleDownProtectedPodSelectorAnnotation = "cluster.x-k8s.io/scale-down-protected-pod-selector"
+
+// MachineSetInfrastructureDeletedPolicy defines how a MachineSet handles the machines whose infrastructure
+// machine has been deleted outside of Cluster API. Defaults to "Fail".
+type MachineSetInfrastructureDeletedPolicy string
+
+const (
+	// FailMachineSetInfrastructureDeletedPolicy leaves the failed machines in place, so they can be
+	// investigated, and remediated by a MachineHealthCheck or by the user.
+	FailMachineSetInfrastructureDeletedPolicy MachineSetInfrastructureDeletedPolicy = "Fail"
+
+	// ReplaceMachineSetInfrastructureDeletedPolicy deletes the failed machines, so they are replaced by new ones.
+	ReplaceMachineSetInfrastructureDeletedPolicy MachineSetInfrastructureDeletedPolicy = "Replace"
+)
+
 // ANCHOR: MachineSetStatus
 
 // MachineSetStatus defines the observed state of MachineSet
@@ -157,6 +206,80 @@ type MachineSetStatus struct {
 	FailureReason *capierrors.MachineSetStatusError `json:"failureReason,omitempty"`
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
+
+	// Conditions defines current service state of the MachineSet.
+	// +optional
+	Conditions Conditions `json:"conditions,omitempty"`
+
+	// UnavailableFailureDomains lists the failure domains where infrastructure providers recently reported
+	// that Machines of this MachineSet could not be provisioned, e.g. because of insufficient capacity.
+	// New Machines are not created in these failure domains until the entries expire.
+	// +optional
+	UnavailableFailureDomains []UnavailableFailureDomain `json:"unavailableFailureDomains,omitempty"`
+
+	// MachinesByPhase counts the Machines of this MachineSet in each phase.
+	// +optional
+	MachinesByPhase MachinesByPhase `json:"machinesByPhase,omitempty"`
+}
+
+// MachinesByPhase counts Machines by phase.
+type MachinesByPhase struct {
+	// Pending is the number of Machines in the Pending or Unknown phase.
+	// +optional
+	Pending int32 `json:"pending,omitempty"`
+
+	// Provisioning is the number of Machines in the Provisioning or Provisioned phase.
+	// +optional
+	Provisioning int32 `json:"provisioning,omitempty"`
+
+	// Running is the number of Machines in the Running phase.
+	// +optional
+	Running int32 `json:"running,omitempty"`
+
+	// Deleting is the number of Machines in the Deleting or Deleted phase.
+	// +optional
+	Deleting int32 `json:"deleting,omitempty"`
+
+	// Failed is the number of Machines in the Failed phase.
+	// +optional
+	Failed int32 `json:"failed,omitempty"`
+}
+
+// Add adds the counts of other to the counts of m.
+func (m *MachinesByPhase) Add(other MachinesByPhase) {
+	m.Pending += other.Pending
+	m.Provisioning += other.Provisioning
+	m.Running += other.Running
+	m.Deleting += other.Deleting
+	m.Failed += other.Failed
+}
+
+// Count counts a Machine in the given phase.
+func (m *MachinesByPhase) Count(phase MachinePhase) {
+	switch phase {
+	case MachinePhaseProvisioning, MachinePhaseProvisioned:
+		m.Provisioning++
+	case MachinePhaseRunning:
+		m.Running++
+	case MachinePhaseDeleting, MachinePhaseDeleted:
+		m.Deleting++
+	case MachinePhaseFailed:
+		m.Failed++
+	default:
+		m.Pending++
+	}
+}
+
+// UnavailableFailureDomain is a failure domain where Machines recently could not be provisioned.
+type UnavailableFailureDomain struct {
+	// Name of the failure domain.
+	Name string `json:"name"`
+
+	// FailureHint is the hint reported by the infrastructure provider, e.g. InsufficientCapacity.
+	FailureHint MachineFailureHint `json:"failureHint"`
+
+	// LastReportedTime is the last time a Machine reported the failure hint for this failure domain.
+	LastReportedTime metav1.Time `json:"lastReportedTime"`
 }
 
 // ANCHOR_END: MachineSetStatus
@@ -202,6 +325,14 @@ type MachineSet struct {
 	Status MachineSetStatus `json:"status,omitempty"`
 }
 
+func (m *MachineSet) GetConditions() Conditions {
+	return m.Status.Conditions
+}
+
+func (m *MachineSet) SetConditions(conditions Conditions) {
+	m.Status.Conditions = conditions
+}
+
 // +kubebuilder:object:root=true
 
 // MachineSetList contains a list of MachineSet
@@ -24,6 +24,8 @@ import (
 	"k8s.io/apimachinery/pkg/labels"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
+	"sigs.k8s.io/cluster-api/util/naming"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -111,9 +113,50 @@ func (m *MachineSet) validate(old *MachineSet) error {
 		)
 	}
 
+	if m.Spec.NamingTemplate != "" {
+		if err := naming.Validate(m.Spec.NamingTemplate); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "namingTemplate"), m.Spec.NamingTemplate, err.Error()),
+			)
+		}
+	}
+
+	if err := validateScaleDownProtectedPodSelector(m.Annotations); err != nil {
+		allErrs = append(allErrs, err)
+	}
+
+	// MachineSets controlled by another object, e.g. a MachineDeployment, are created with the version validated on the
+	// owner; validating them again would prevent owners already using an unsupported version from rolling out changes.
+	if metav1.GetControllerOf(m) == nil {
+		var oldVersion *string
+		if old != nil {
+			oldVersion = old.Spec.Template.Spec.Version
+		}
+		if err := kubernetesversion.Validate(field.NewPath("spec", "template", "spec", "version"), m.Spec.Template.Spec.Version, oldVersion); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 
 	return apierrors.NewInvalid(GroupVersion.WithKind("MachineSet").GroupKind(), m.Name, allErrs)
 }
+
+// validateScaleDownProtectedPodSelector validates the ScaleDownProtectedPodSelectorAnnotation, if set.
+func validateScaleDownProtectedPodSelector(annotations map[string]string) *field.Error {
+	value, ok := annotations[ScaleDownProtectedPodSelectorAnnotation]
+	if !ok {
+		return nil
+	}
+	if _, err := labels.Parse(value); err != nil || value == "" {
+		return field.Invalid(
+			field.NewPath("metadata", "annotations", ScaleDownProtectedPodSelectorAnnotation),
+			value,
+			"must be a non-empty label selector, e.g. cluster.x-k8s.io/scale-down-protected",
+		)
+	}
+	return nil
+}
@@ -151,3 +151,111 @@ func TestMachineSetClusterNameImmutable(t *testing.T) {
 		})
 	}
 }
+
+func TestMachineSetNamingTemplateValidation(t *testing.T) {
+	tests := []struct {
+		name           string
+		namingTemplate string
+		expectErr      bool
+	}{
+		{
+			name:           "should succeed without a naming template",
+			namingTemplate: "",
+			expectErr:      false,
+		},
+		{
+			name:           "should succeed with a valid naming template",
+			namingTemplate: "{{ .ClusterName }}-{{ .OwnerName }}-{{ random 5 }}",
+			expectErr:      false,
+		},
+		{
+			name:           "should return error when the naming template can't be parsed",
+			namingTemplate: "{{ .OwnerName",
+			expectErr:      true,
+		},
+		{
+			name:           "should return error when the naming template renders an invalid name",
+			namingTemplate: "{{ .OwnerName }}_{{ .Index }}",
+			expectErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := &MachineSet{
+				Spec: MachineSetSpec{
+					NamingTemplate: tt.namingTemplate,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(ms.ValidateCreate()).NotTo(Succeed())
+				g.Expect(ms.ValidateUpdate(ms)).NotTo(Succeed())
+			} else {
+				g.Expect(ms.ValidateCreate()).To(Succeed())
+				g.Expect(ms.ValidateUpdate(ms)).To(Succeed())
+			}
+		})
+	}
+}
+
+func TestMachineSetScaleDownProtectedPodSelectorValidation(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		expectErr   bool
+	}{
+		{
+			name:        "should succeed without the annotation",
+			annotations: nil,
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed with a label key",
+			annotations: map[string]string{ScaleDownProtectedPodSelectorAnnotation: "cluster.x-k8s.io/scale-down-protected"},
+			expectErr:   false,
+		},
+		{
+			name:        "should succeed with a set based selector",
+			annotations: map[string]string{ScaleDownProtectedPodSelectorAnnotation: "app in (etcd-backup, kafka)"},
+			expectErr:   false,
+		},
+		{
+			name:        "should return error with an empty selector",
+			annotations: map[string]string{ScaleDownProtectedPodSelectorAnnotation: ""},
+			expectErr:   true,
+		},
+		{
+			name:        "should return error with an invalid selector",
+			annotations: map[string]string{ScaleDownProtectedPodSelectorAnnotation: "app in (kafka"},
+			expectErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := &MachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tt.annotations,
+				},
+			}
+			md := &MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: tt.annotations,
+				},
+			}
+
+			if tt.expectErr {
+				g.Expect(ms.ValidateCreate()).NotTo(Succeed())
+				g.Expect(md.ValidateCreate()).NotTo(Succeed())
+			} else {
+				g.Expect(ms.ValidateCreate()).To(Succeed())
+				g.Expect(md.ValidateCreate()).To(Succeed())
+			}
+		})
+	}
+}
@@ -176,6 +176,11 @@ func (in *ClusterSpec) DeepCopyInto(out *ClusterSpec) {
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.WorkloadComponentHealthChecks != nil {
+		in, out := &in.WorkloadComponentHealthChecks, &out.WorkloadComponentHealthChecks
+		*out = make([]WorkloadComponentHealthCheck, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterSpec.
@@ -374,7 +379,7 @@ func (in *MachineDeployment) DeepCopyInto(out *MachineDeployment) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeployment.
@@ -395,6 +400,46 @@ func (in *MachineDeployment) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeploymentCanary) DeepCopyInto(out *MachineDeploymentCanary) {
+	*out = *in
+	out.Replicas = in.Replicas
+	if in.SoakSeconds != nil {
+		in, out := &in.SoakSeconds, &out.SoakSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeploymentCanary.
+func (in *MachineDeploymentCanary) DeepCopy() *MachineDeploymentCanary {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeploymentCanary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineDeploymentCanaryStatus) DeepCopyInto(out *MachineDeploymentCanaryStatus) {
+	*out = *in
+	if in.HealthySince != nil {
+		in, out := &in.HealthySince, &out.HealthySince
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeploymentCanaryStatus.
+func (in *MachineDeploymentCanaryStatus) DeepCopy() *MachineDeploymentCanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineDeploymentCanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineDeploymentList) DeepCopyInto(out *MachineDeploymentList) {
 	*out = *in
@@ -447,6 +492,16 @@ func (in *MachineDeploymentSpec) DeepCopyInto(out *MachineDeploymentSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ProvisioningConcurrency != nil {
+		in, out := &in.ProvisioningConcurrency, &out.ProvisioningConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TemplateMetadataPolicy != nil {
+		in, out := &in.TemplateMetadataPolicy, &out.TemplateMetadataPolicy
+		*out = new(TemplateMetadataPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.RevisionHistoryLimit != nil {
 		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
 		*out = new(int32)
@@ -472,6 +527,19 @@ func (in *MachineDeploymentSpec) DeepCopy() *MachineDeploymentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineDeploymentStatus) DeepCopyInto(out *MachineDeploymentStatus) {
 	*out = *in
+	if in.OperationHistory != nil {
+		in, out := &in.OperationHistory, &out.OperationHistory
+		*out = make([]OperationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.MachinesByPhase = in.MachinesByPhase
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(MachineDeploymentCanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineDeploymentStatus.
@@ -563,6 +631,21 @@ func (in *MachineHealthCheckList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineHealthCheckRemediation) DeepCopyInto(out *MachineHealthCheckRemediation) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineHealthCheckRemediation.
+func (in *MachineHealthCheckRemediation) DeepCopy() *MachineHealthCheckRemediation {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineHealthCheckRemediation)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineHealthCheckSpec) DeepCopyInto(out *MachineHealthCheckSpec) {
 	*out = *in
@@ -582,11 +665,26 @@ func (in *MachineHealthCheckSpec) DeepCopyInto(out *MachineHealthCheckSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.UnreachableTaintTimeout != nil {
+		in, out := &in.UnreachableTaintTimeout, &out.UnreachableTaintTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NodeLeaseStalenessThreshold != nil {
+		in, out := &in.NodeLeaseStalenessThreshold, &out.NodeLeaseStalenessThreshold
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.RemediationTemplate != nil {
 		in, out := &in.RemediationTemplate, &out.RemediationTemplate
 		*out = new(v1.ObjectReference)
 		**out = **in
 	}
+	if in.Remediation != nil {
+		in, out := &in.Remediation, &out.Remediation
+		*out = new(MachineHealthCheckRemediation)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineHealthCheckSpec.
@@ -607,6 +705,11 @@ func (in *MachineHealthCheckStatus) DeepCopyInto(out *MachineHealthCheckStatus)
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ObservedUnhealthyTargets != nil {
+		in, out := &in.ObservedUnhealthyTargets, &out.ObservedUnhealthyTargets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(Conditions, len(*in))
@@ -626,6 +729,21 @@ func (in *MachineHealthCheckStatus) DeepCopy() *MachineHealthCheckStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachineInstanceMetadata) DeepCopyInto(out *MachineInstanceMetadata) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineInstanceMetadata.
+func (in *MachineInstanceMetadata) DeepCopy() *MachineInstanceMetadata {
+	if in == nil {
+		return nil
+	}
+	out := new(MachineInstanceMetadata)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MachineList) DeepCopyInto(out *MachineList) {
 	*out = *in
@@ -676,6 +794,11 @@ func (in *MachineRollingUpdateDeployment) DeepCopyInto(out *MachineRollingUpdate
 		*out = new(string)
 		**out = **in
 	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(MachineDeploymentCanary)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineRollingUpdateDeployment.
@@ -755,6 +878,16 @@ func (in *MachineSetSpec) DeepCopyInto(out *MachineSetSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ProvisioningConcurrency != nil {
+		in, out := &in.ProvisioningConcurrency, &out.ProvisioningConcurrency
+		*out = new(int32)
+		**out = **in
+	}
+	if in.TemplateMetadataPolicy != nil {
+		in, out := &in.TemplateMetadataPolicy, &out.TemplateMetadataPolicy
+		*out = new(TemplateMetadataPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 	in.Selector.DeepCopyInto(&out.Selector)
 	in.Template.DeepCopyInto(&out.Template)
 }
@@ -782,6 +915,21 @@ func (in *MachineSetStatus) DeepCopyInto(out *MachineSetStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.UnavailableFailureDomains != nil {
+		in, out := &in.UnavailableFailureDomains, &out.UnavailableFailureDomains
+		*out = make([]UnavailableFailureDomain, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	out.MachinesByPhase = in.MachinesByPhase
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineSetStatus.
@@ -819,6 +967,26 @@ func (in *MachineSpec) DeepCopyInto(out *MachineSpec) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.NodeDeletionTimeout != nil {
+		in, out := &in.NodeDeletionTimeout, &out.NodeDeletionTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PreDrainDeleteHookTimeout != nil {
+		in, out := &in.PreDrainDeleteHookTimeout, &out.PreDrainDeleteHookTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.PreTerminateDeleteHookTimeout != nil {
+		in, out := &in.PreTerminateDeleteHookTimeout, &out.PreTerminateDeleteHookTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NodeShutdownGracePeriod != nil {
+		in, out := &in.NodeShutdownGracePeriod, &out.NodeShutdownGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineSpec.
@@ -863,6 +1031,28 @@ func (in *MachineStatus) DeepCopyInto(out *MachineStatus) {
 		*out = make(MachineAddresses, len(*in))
 		copy(*out, *in)
 	}
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(v1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+	if in.NodeInfo != nil {
+		in, out := &in.NodeInfo, &out.NodeInfo
+		*out = new(v1.NodeSystemInfo)
+		**out = **in
+	}
+	if in.InstanceMetadata != nil {
+		in, out := &in.InstanceMetadata, &out.InstanceMetadata
+		*out = new(MachineInstanceMetadata)
+		**out = **in
+	}
+	if in.DiagnosticsRef != nil {
+		in, out := &in.DiagnosticsRef, &out.DiagnosticsRef
+		*out = new(v1.ObjectReference)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(Conditions, len(*in))
@@ -870,6 +1060,13 @@ func (in *MachineStatus) DeepCopyInto(out *MachineStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.OperationHistory != nil {
+		in, out := &in.OperationHistory, &out.OperationHistory
+		*out = make([]OperationRecord, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachineStatus.
@@ -899,6 +1096,21 @@ func (in *MachineTemplateSpec) DeepCopy() *MachineTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MachinesByPhase) DeepCopyInto(out *MachinesByPhase) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MachinesByPhase.
+func (in *MachinesByPhase) DeepCopy() *MachinesByPhase {
+	if in == nil {
+		return nil
+	}
+	out := new(MachinesByPhase)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NetworkRanges) DeepCopyInto(out *NetworkRanges) {
 	*out = *in
@@ -955,6 +1167,73 @@ func (in *ObjectMeta) DeepCopy() *ObjectMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperationRecord) DeepCopyInto(out *OperationRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperationRecord.
+func (in *OperationRecord) DeepCopy() *OperationRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(OperationRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplateMetadataPolicy) DeepCopyInto(out *TemplateMetadataPolicy) {
+	*out = *in
+	if in.PropagateLabels != nil {
+		in, out := &in.PropagateLabels, &out.PropagateLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PropagateAnnotations != nil {
+		in, out := &in.PropagateAnnotations, &out.PropagateAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StripLabels != nil {
+		in, out := &in.StripLabels, &out.StripLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.StripAnnotations != nil {
+		in, out := &in.StripAnnotations, &out.StripAnnotations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplateMetadataPolicy.
+func (in *TemplateMetadataPolicy) DeepCopy() *TemplateMetadataPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplateMetadataPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnavailableFailureDomain) DeepCopyInto(out *UnavailableFailureDomain) {
+	*out = *in
+	in.LastReportedTime.DeepCopyInto(&out.LastReportedTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnavailableFailureDomain.
+func (in *UnavailableFailureDomain) DeepCopy() *UnavailableFailureDomain {
+	if in == nil {
+		return nil
+	}
+	out := new(UnavailableFailureDomain)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *UnhealthyCondition) DeepCopyInto(out *UnhealthyCondition) {
 	*out = *in
@@ -970,3 +1249,18 @@ func (in *UnhealthyCondition) DeepCopy() *UnhealthyCondition {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadComponentHealthCheck) DeepCopyInto(out *WorkloadComponentHealthCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadComponentHealthCheck.
+func (in *WorkloadComponentHealthCheck) DeepCopy() *WorkloadComponentHealthCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadComponentHealthCheck)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -62,6 +62,13 @@ type KubeadmConfigSpec struct {
 	// +optional
 	PreKubeadmCommands []string `json:"preKubeadmCommands,omitempty"`
 
+	// PreKubeadmChecks specifies checks, e.g. waiting for the instance metadata service, DNS or time sync, which
+	// must succeed before kubeadm runs; each check is retried until it succeeds or its timeout expires.
+	// The checks run after PreKubeadmCommands. If a check fails, kubeadm doesn't run and the failure is reported
+	// in /run/cluster-api/bootstrap-failure.message.
+	// +optional
+	PreKubeadmChecks []PreKubeadmCheck `json:"preKubeadmChecks,omitempty"`
+
 	// PostKubeadmCommands specifies extra commands to run after kubeadm runs
 	// +optional
 	PostKubeadmCommands []string `json:"postKubeadmCommands,omitempty"`
@@ -74,6 +81,14 @@ type KubeadmConfigSpec struct {
 	// +optional
 	NTP *NTP `json:"ntp,omitempty"`
 
+	// Proxy specifies the HTTP proxy settings of the container runtime and of the kubelet.
+	// +optional
+	Proxy *ProxyConfiguration `json:"proxy,omitempty"`
+
+	// RegistryMirrors specifies the mirrors of the container image registries used by containerd.
+	// +optional
+	RegistryMirrors []RegistryMirror `json:"registryMirrors,omitempty"`
+
 	// Format specifies the output format of the bootstrap data
 	// +optional
 	Format Format `json:"format,omitempty"`
@@ -97,6 +112,51 @@ type KubeadmConfigSpec struct {
 	UseExperimentalRetryJoin bool `json:"useExperimentalRetryJoin,omitempty"`
 }
 
+// PreKubeadmCheck defines a check which must succeed before kubeadm runs.
+type PreKubeadmCheck struct {
+	// Name of the check, used for reporting failures; it must be a DNS label, unique among all checks.
+	Name string `json:"name"`
+
+	// Command is the shell command performing the check; the check succeeds when the command exits with 0,
+	// e.g. "getent hosts registry.k8s.io".
+	Command string `json:"command"`
+
+	// Timeout is the maximum time spent retrying the check. Defaults to 5m.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// Interval is the time between two attempts of the check. Defaults to 5s.
+	// +optional
+	Interval *metav1.Duration `json:"interval,omitempty"`
+}
+
+// ProxyConfiguration defines the HTTP proxy settings of a node, set as the HTTP_PROXY, HTTPS_PROXY and NO_PROXY
+// environment variables of the containerd and kubelet services.
+type ProxyConfiguration struct {
+	// HTTPProxy is the URL of the proxy for HTTP requests.
+	// +optional
+	HTTPProxy string `json:"httpProxy,omitempty"`
+
+	// HTTPSProxy is the URL of the proxy for HTTPS requests.
+	// +optional
+	HTTPSProxy string `json:"httpsProxy,omitempty"`
+
+	// NoProxy is the list of hosts, domains and CIDRs that must be reached without the proxy,
+	// e.g. the control plane endpoint and the pod and service CIDRs.
+	// +optional
+	NoProxy []string `json:"noProxy,omitempty"`
+}
+
+// RegistryMirror defines the mirrors of a container image registry.
+type RegistryMirror struct {
+	// Registry is the host of the registry to mirror, e.g. docker.io or registry.example.com:5000.
+	Registry string `json:"registry"`
+
+	// Endpoints are the URLs of the mirrors, tried in order before the registry itself.
+	// +kubebuilder:validation:MinItems=1
+	Endpoints []string `json:"endpoints"`
+}
+
 // KubeadmConfigStatus defines the observed state of KubeadmConfig
 type KubeadmConfigStatus struct {
 	// Ready indicates the BootstrapData field is ready to be consumed
@@ -18,6 +18,7 @@ package v1alpha4
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -145,6 +146,173 @@ func TestClusterValidate(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		"valid proxy and registry mirrors": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Proxy: &ProxyConfiguration{
+						HTTPProxy:  "http://proxy.example.com:3128",
+						HTTPSProxy: "http://proxy.example.com:3128",
+						NoProxy:    []string{"10.0.0.0/8", ".svc"},
+					},
+					RegistryMirrors: []RegistryMirror{
+						{
+							Registry:  "docker.io",
+							Endpoints: []string{"https://mirror.example.com"},
+						},
+					},
+				},
+			},
+		},
+		"invalid proxy URL": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					Proxy: &ProxyConfiguration{
+						HTTPSProxy: "proxy.example.com:3128",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid registry mirror endpoint": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RegistryMirrors: []RegistryMirror{
+						{
+							Registry:  "docker.io",
+							Endpoints: []string{"mirror.example.com"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid with duplicate registry mirrors": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					RegistryMirrors: []RegistryMirror{
+						{
+							Registry:  "docker.io",
+							Endpoints: []string{"https://mirror.example.com"},
+						},
+						{
+							Registry:  "docker.io",
+							Endpoints: []string{"https://other-mirror.example.com"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"valid pre-kubeadm checks": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PreKubeadmChecks: []PreKubeadmCheck{
+						{
+							Name:     "dns",
+							Command:  "getent hosts registry.k8s.io",
+							Timeout:  &metav1.Duration{Duration: 10 * time.Minute},
+							Interval: &metav1.Duration{Duration: 10 * time.Second},
+						},
+						{
+							Name:    "time-sync",
+							Command: "timedatectl show -p NTPSynchronized --value | grep -q yes",
+						},
+					},
+				},
+			},
+		},
+		"invalid pre-kubeadm check name": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PreKubeadmChecks: []PreKubeadmCheck{
+						{
+							Name:    "Time Sync",
+							Command: "true",
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid with duplicate pre-kubeadm checks": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PreKubeadmChecks: []PreKubeadmCheck{
+						{
+							Name:    "dns",
+							Command: "true",
+						},
+						{
+							Name:    "dns",
+							Command: "true",
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid pre-kubeadm check without command": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PreKubeadmChecks: []PreKubeadmCheck{
+						{
+							Name: "dns",
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		"invalid pre-kubeadm check timeout": {
+			in: &KubeadmConfig{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "baz",
+					Namespace: "default",
+				},
+				Spec: KubeadmConfigSpec{
+					PreKubeadmChecks: []PreKubeadmCheck{
+						{
+							Name:    "dns",
+							Command: "true",
+							Timeout: &metav1.Duration{},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
 	}
 
 	for name, tt := range cases {
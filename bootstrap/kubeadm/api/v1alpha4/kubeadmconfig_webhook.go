@@ -18,9 +18,11 @@ package v1alpha4
 
 import (
 	"fmt"
+	"net/url"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -32,6 +34,10 @@ var (
 	MissingSecretNameMsg     = "secret file source must specify non-empty secret name"
 	MissingSecretKeyMsg      = "secret file source must specify non-empty secret key"
 	PathConflictMsg          = "path property must be unique among all files"
+	RegistryConflictMsg      = "registry property must be unique among all registry mirrors"
+	InvalidURLMsg            = "must be an absolute http or https URL"
+	CheckNameConflictMsg     = "name property must be unique among all pre-kubeadm checks"
+	PositiveDurationMsg      = "must be greater than zero"
 )
 
 func (c *KubeadmConfig) SetupWebhookWithManager(mgr ctrl.Manager) error {
@@ -115,8 +121,67 @@ func (c *KubeadmConfigSpec) validate(name string) error {
 		knownPaths[file.Path] = struct{}{}
 	}
 
+	if c.Proxy != nil {
+		if c.Proxy.HTTPProxy != "" && !isHTTPURL(c.Proxy.HTTPProxy) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "proxy", "httpProxy"), c.Proxy.HTTPProxy, InvalidURLMsg))
+		}
+		if c.Proxy.HTTPSProxy != "" && !isHTTPURL(c.Proxy.HTTPSProxy) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "proxy", "httpsProxy"), c.Proxy.HTTPSProxy, InvalidURLMsg))
+		}
+	}
+
+	knownRegistries := map[string]struct{}{}
+
+	for i, mirror := range c.RegistryMirrors {
+		if mirror.Registry == "" {
+			allErrs = append(allErrs, field.Required(field.NewPath("spec", "registryMirrors", fmt.Sprintf("%d", i), "registry"), ""))
+		}
+		if _, conflict := knownRegistries[mirror.Registry]; conflict {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "registryMirrors", fmt.Sprintf("%d", i), "registry"), mirror.Registry, RegistryConflictMsg))
+		}
+		knownRegistries[mirror.Registry] = struct{}{}
+
+		for j, endpoint := range mirror.Endpoints {
+			if !isHTTPURL(endpoint) {
+				allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "registryMirrors", fmt.Sprintf("%d", i), "endpoints", fmt.Sprintf("%d", j)), endpoint, InvalidURLMsg))
+			}
+		}
+	}
+
+	knownChecks := map[string]struct{}{}
+
+	for i, check := range c.PreKubeadmChecks {
+		checkPath := field.NewPath("spec", "preKubeadmChecks", fmt.Sprintf("%d", i))
+		for _, msg := range validation.IsDNS1123Label(check.Name) {
+			allErrs = append(allErrs, field.Invalid(checkPath.Child("name"), check.Name, msg))
+		}
+		if _, conflict := knownChecks[check.Name]; conflict {
+			allErrs = append(allErrs, field.Invalid(checkPath.Child("name"), check.Name, CheckNameConflictMsg))
+		}
+		knownChecks[check.Name] = struct{}{}
+
+		if check.Command == "" {
+			allErrs = append(allErrs, field.Required(checkPath.Child("command"), ""))
+		}
+		if check.Timeout != nil && check.Timeout.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(checkPath.Child("timeout"), check.Timeout.Duration.String(), PositiveDurationMsg))
+		}
+		if check.Interval != nil && check.Interval.Duration <= 0 {
+			allErrs = append(allErrs, field.Invalid(checkPath.Child("interval"), check.Interval.Duration.String(), PositiveDurationMsg))
+		}
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
 	return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmConfig").GroupKind(), name, allErrs)
 }
+
+// isHTTPURL returns true if s is an absolute URL with the http or https scheme.
+func isHTTPURL(s string) bool {
+	u, err := url.Parse(s)
+	if err != nil {
+		return false
+	}
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
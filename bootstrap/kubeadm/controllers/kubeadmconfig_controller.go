@@ -74,6 +74,9 @@ type KubeadmConfigReconciler struct {
 	Client          client.Client
 	KubeadmInitLock InitLocker
 
+	// BootstrapDataEncrypter, if set, encrypts the bootstrap data before it is stored in the bootstrap data secret.
+	BootstrapDataEncrypter bsutil.BootstrapDataEncrypter
+
 	remoteClientGetter remote.ClusterClientGetter
 }
 
@@ -226,6 +229,16 @@ func (r *KubeadmConfigReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		return ctrl.Result{}, nil
 	// Status is ready means a config has been generated.
 	case config.Status.Ready:
+		// If the config owned by a MachinePool has changed, regenerate the bootstrap data; the infrastructure provider
+		// uses the new data for instances created afterwards, without replacing the existing ones.
+		if configOwner.IsMachinePool() && cluster.Status.ControlPlaneInitialized &&
+			config.Status.ObservedGeneration != 0 && config.Generation != config.Status.ObservedGeneration {
+			log.Info("Config owned by a MachinePool has changed, regenerating the bootstrap data")
+			if config.Spec.JoinConfiguration == nil {
+				config.Spec.JoinConfiguration = &kubeadmv1beta1.JoinConfiguration{}
+			}
+			return r.joinWorker(ctx, scope)
+		}
 		if config.Spec.JoinConfiguration != nil && config.Spec.JoinConfiguration.Discovery.BootstrapToken != nil {
 			if !configOwner.IsInfrastructureReady() {
 				// If the BootstrapToken has been generated for a join and the infrastructure is not ready.
@@ -425,7 +438,10 @@ func (r *KubeadmConfigReconciler) handleClusterNotInitialized(ctx context.Contex
 		BaseUserData: cloudinit.BaseUserData{
 			AdditionalFiles:     files,
 			NTP:                 scope.Config.Spec.NTP,
+			Proxy:               scope.Config.Spec.Proxy,
+			RegistryMirrors:     scope.Config.Spec.RegistryMirrors,
 			PreKubeadmCommands:  scope.Config.Spec.PreKubeadmCommands,
+			PreKubeadmChecks:    scope.Config.Spec.PreKubeadmChecks,
 			PostKubeadmCommands: scope.Config.Spec.PostKubeadmCommands,
 			Users:               scope.Config.Spec.Users,
 			Mounts:              scope.Config.Spec.Mounts,
@@ -500,7 +516,10 @@ func (r *KubeadmConfigReconciler) joinWorker(ctx context.Context, scope *Scope)
 		BaseUserData: cloudinit.BaseUserData{
 			AdditionalFiles:      files,
 			NTP:                  scope.Config.Spec.NTP,
+			Proxy:                scope.Config.Spec.Proxy,
+			RegistryMirrors:      scope.Config.Spec.RegistryMirrors,
 			PreKubeadmCommands:   scope.Config.Spec.PreKubeadmCommands,
+			PreKubeadmChecks:     scope.Config.Spec.PreKubeadmChecks,
 			PostKubeadmCommands:  scope.Config.Spec.PostKubeadmCommands,
 			Users:                scope.Config.Spec.Users,
 			Mounts:               scope.Config.Spec.Mounts,
@@ -579,7 +598,10 @@ func (r *KubeadmConfigReconciler) joinControlplane(ctx context.Context, scope *S
 		BaseUserData: cloudinit.BaseUserData{
 			AdditionalFiles:      files,
 			NTP:                  scope.Config.Spec.NTP,
+			Proxy:                scope.Config.Spec.Proxy,
+			RegistryMirrors:      scope.Config.Spec.RegistryMirrors,
 			PreKubeadmCommands:   scope.Config.Spec.PreKubeadmCommands,
+			PreKubeadmChecks:     scope.Config.Spec.PreKubeadmChecks,
 			PostKubeadmCommands:  scope.Config.Spec.PostKubeadmCommands,
 			Users:                scope.Config.Spec.Users,
 			Mounts:               scope.Config.Spec.Mounts,
@@ -858,6 +880,9 @@ func (r *KubeadmConfigReconciler) storeBootstrapData(ctx context.Context, scope
 		},
 		Type: clusterv1.ClusterSecretType,
 	}
+	if err := bsutil.EncryptBootstrapData(ctx, secret, r.BootstrapDataEncrypter); err != nil {
+		return err
+	}
 
 	// as secret creation and scope.Config status patch are not atomic operations
 	// it is possible that secret creation happens but the config.Status patches are not applied
@@ -1023,6 +1023,67 @@ func TestBootstrapTokenRotationMachinePool(t *testing.T) {
 	g.Expect(foundNew).To(BeTrue())
 }
 
+// Ensure the bootstrap data of a MachinePool is regenerated when its config changes.
+func TestKubeadmConfigReconciler_Reconcile_RegenerateMachinePoolBootstrapDataOnChange(t *testing.T) {
+	_ = feature.MutableGates.Set("MachinePool=true")
+	g := NewWithT(t)
+
+	cluster := newCluster("cluster")
+	cluster.Status.InfrastructureReady = true
+	cluster.Status.ControlPlaneInitialized = true
+	cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{Host: "100.105.150.1", Port: 6443}
+
+	controlPlaneInitMachine := newControlPlaneMachine(cluster, "control-plane-init-machine")
+	initConfig := newControlPlaneInitKubeadmConfig(controlPlaneInitMachine, "control-plane-init-config")
+	workerMachinePool := newWorkerMachinePool(cluster)
+	workerJoinConfig := newWorkerPoolJoinKubeadmConfig(workerMachinePool)
+	workerJoinConfig.Generation = 2
+	workerJoinConfig.Spec.PreKubeadmCommands = []string{"echo new registry credentials"}
+	workerJoinConfig.Status.Ready = true
+	workerJoinConfig.Status.DataSecretName = pointer.StringPtr(workerJoinConfig.Name)
+	workerJoinConfig.Status.ObservedGeneration = 1
+	dataSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: workerJoinConfig.Namespace,
+			Name:      workerJoinConfig.Name,
+		},
+		Data: map[string][]byte{
+			"value": []byte("previous bootstrap data"),
+		},
+	}
+	objects := []client.Object{
+		cluster,
+		workerMachinePool,
+		workerJoinConfig,
+		dataSecret,
+	}
+
+	objects = append(objects, createSecrets(t, cluster, initConfig)...)
+	myclient := helpers.NewFakeClientWithScheme(setupScheme(), objects...)
+	k := &KubeadmConfigReconciler{
+		Client:             myclient,
+		KubeadmInitLock:    &myInitLocker{},
+		remoteClientGetter: fakeremote.NewClusterClient,
+	}
+	request := ctrl.Request{
+		NamespacedName: client.ObjectKey{
+			Namespace: "default",
+			Name:      "workerpool-join-cfg",
+		},
+	}
+	_, err := k.Reconcile(ctx, request)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	cfg, err := getKubeadmConfig(myclient, "workerpool-join-cfg")
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(cfg.Status.Ready).To(BeTrue())
+	g.Expect(cfg.Status.ObservedGeneration).To(Equal(int64(2)))
+
+	s := &corev1.Secret{}
+	g.Expect(myclient.Get(ctx, client.ObjectKeyFromObject(dataSecret), s)).To(Succeed())
+	g.Expect(string(s.Data["value"])).To(ContainSubstring("echo new registry credentials"))
+}
+
 // Ensure the discovery portion of the JoinConfiguration gets generated correctly.
 func TestKubeadmConfigReconciler_Reconcile_DiscoveryReconcileBehaviors(t *testing.T) {
 	k := &KubeadmConfigReconciler{
@@ -42,11 +42,14 @@ const (
 type BaseUserData struct {
 	Header               string
 	PreKubeadmCommands   []string
+	PreKubeadmChecks     []bootstrapv1.PreKubeadmCheck
 	PostKubeadmCommands  []string
 	AdditionalFiles      []bootstrapv1.File
 	WriteFiles           []bootstrapv1.File
 	Users                []bootstrapv1.User
 	NTP                  *bootstrapv1.NTP
+	Proxy                *bootstrapv1.ProxyConfiguration
+	RegistryMirrors      []bootstrapv1.RegistryMirror
 	DiskSetup            *bootstrapv1.DiskSetup
 	Mounts               []bootstrapv1.MountPoints
 	ControlPlane         bool
@@ -54,10 +57,15 @@ type BaseUserData struct {
 	KubeadmCommand       string
 	KubeadmVerbosity     string
 	SentinelFileCommand  string
+
+	// PreKubeadmChecksCommand runs the pre-kubeadm checks; kubeadm runs only if it succeeds.
+	PreKubeadmChecksCommand string
 }
 
 func (input *BaseUserData) prepare() error {
 	input.Header = cloudConfigHeader
+	input.prepareContainerRuntime()
+	input.preparePreKubeadmChecks()
 	input.WriteFiles = append(input.WriteFiles, input.AdditionalFiles...)
 	input.KubeadmCommand = fmt.Sprintf(standardJoinCommand, input.KubeadmVerbosity)
 	if input.UseExperimentalRetry {
@@ -18,9 +18,11 @@ package cloudinit
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	infrav1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
@@ -179,3 +181,107 @@ func TestNewInitControlPlaneDiskMounts(t *testing.T) {
 	g.Expect(out).To(ContainSubstring(expectedFSSetup))
 	g.Expect(out).To(ContainSubstring(expectedMounts))
 }
+
+func TestNewInitControlPlaneContainerRuntime(t *testing.T) {
+	g := NewWithT(t)
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			Header:             "test",
+			PreKubeadmCommands: []string{"echo hello"},
+			Proxy: &bootstrapv1.ProxyConfiguration{
+				HTTPProxy:  "http://proxy.example.com:3128",
+				HTTPSProxy: "http://proxy.example.com:3128",
+				NoProxy:    []string{"10.0.0.0/8", ".svc"},
+			},
+			RegistryMirrors: []bootstrapv1.RegistryMirror{
+				{
+					Registry:  "docker.io",
+					Endpoints: []string{"https://mirror.example.com"},
+				},
+			},
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration:    "my-init-config",
+	}
+
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	expectedFiles := []string{
+		`-   path: /etc/systemd/system/containerd.service.d/http-proxy.conf
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [Service]
+      Environment="HTTP_PROXY=http://proxy.example.com:3128"
+      Environment="HTTPS_PROXY=http://proxy.example.com:3128"
+      Environment="NO_PROXY=10.0.0.0/8,.svc"`,
+		`-   path: /etc/systemd/system/kubelet.service.d/http-proxy.conf`,
+		`-   path: /etc/containerd/certs.d/docker.io/hosts.toml
+    owner: root:root
+    permissions: '0644'
+    content: |
+      [host."https://mirror.example.com"]
+        capabilities = ["pull", "resolve"]`,
+	}
+	for _, f := range expectedFiles {
+		g.Expect(out).To(ContainSubstring(f))
+	}
+	g.Expect(out).To(ContainSubstring(`  - "systemctl daemon-reload && systemctl restart containerd"
+  - "echo hello"`))
+}
+
+func TestPreKubeadmChecks(t *testing.T) {
+	g := NewWithT(t)
+
+	checks := []bootstrapv1.PreKubeadmCheck{
+		{
+			Name:    "dns",
+			Command: "getent hosts registry.k8s.io",
+		},
+		{
+			Name:     "time-sync",
+			Command:  "timedatectl show -p NTPSynchronized --value | grep -q yes",
+			Timeout:  &metav1.Duration{Duration: 90 * time.Second},
+			Interval: &metav1.Duration{Duration: 1500 * time.Millisecond},
+		},
+	}
+
+	cpinput := &ControlPlaneInput{
+		BaseUserData: BaseUserData{
+			PreKubeadmChecks: checks,
+		},
+		Certificates:         secret.Certificates{},
+		ClusterConfiguration: "my-cluster-config",
+		InitConfiguration:    "my-init-config",
+	}
+	out, err := NewInitControlPlane(cpinput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).To(ContainSubstring(`-   path: /usr/local/bin/kubeadm-pre-checks
+    owner: root
+    permissions: '0755'`))
+	g.Expect(out).To(ContainSubstring(`      check_0() (
+      getent hosts registry.k8s.io
+      )
+      run_check dns 300 5 check_0`))
+	g.Expect(out).To(ContainSubstring(`      run_check time-sync 90 2 check_1`))
+	g.Expect(out).To(ContainSubstring(`  - '/usr/local/bin/kubeadm-pre-checks && kubeadm init --config /run/kubeadm/kubeadm.yaml  && echo success > /run/cluster-api/bootstrap-success.complete'`))
+
+	nodeInput := &NodeInput{
+		BaseUserData: BaseUserData{
+			PreKubeadmChecks: checks,
+		},
+		JoinConfiguration: "my-join-config",
+	}
+	out, err = NewNode(nodeInput)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).To(ContainSubstring(`-   path: /usr/local/bin/kubeadm-pre-checks`))
+	g.Expect(out).To(ContainSubstring(`  - /usr/local/bin/kubeadm-pre-checks && kubeadm join --config /run/kubeadm/kubeadm-join-config.yaml  && echo success > /run/cluster-api/bootstrap-success.complete`))
+
+	// Without checks, the kubeadm command runs unconditionally.
+	out, err = NewNode(&NodeInput{JoinConfiguration: "my-join-config"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(out).NotTo(ContainSubstring("kubeadm-pre-checks"))
+}
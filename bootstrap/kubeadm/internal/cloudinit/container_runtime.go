@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	containerdProxyDropInPath = "/etc/systemd/system/containerd.service.d/http-proxy.conf"
+	kubeletProxyDropInPath    = "/etc/systemd/system/kubelet.service.d/http-proxy.conf"
+	containerdHostsDir        = "/etc/containerd/certs.d"
+
+	// restartContainerdCommand applies the proxy settings to containerd, before any other command runs.
+	restartContainerdCommand = "systemctl daemon-reload && systemctl restart containerd"
+)
+
+// prepareContainerRuntime adds the files and the commands configuring the container runtime and the kubelet; the
+// files are written before the additional files, so they can be overridden.
+func (input *BaseUserData) prepareContainerRuntime() {
+	input.WriteFiles = append(input.WriteFiles, containerRuntimeFiles(input.Proxy, input.RegistryMirrors)...)
+	if input.Proxy != nil {
+		input.PreKubeadmCommands = append([]string{restartContainerdCommand}, input.PreKubeadmCommands...)
+	}
+}
+
+// containerRuntimeFiles returns the files configuring the proxy settings of the containerd and kubelet services and
+// the containerd registry mirrors.
+func containerRuntimeFiles(proxy *bootstrapv1.ProxyConfiguration, mirrors []bootstrapv1.RegistryMirror) []bootstrapv1.File {
+	files := []bootstrapv1.File{}
+
+	if proxy != nil {
+		dropIn := proxyDropIn(proxy)
+		for _, p := range []string{containerdProxyDropInPath, kubeletProxyDropInPath} {
+			files = append(files, bootstrapv1.File{
+				Path:        p,
+				Owner:       "root:root",
+				Permissions: "0644",
+				Content:     dropIn,
+			})
+		}
+	}
+
+	// The mirrors are configured with the hosts.toml files of containerd, which are read on every image pull.
+	for _, mirror := range mirrors {
+		var b strings.Builder
+		for _, endpoint := range mirror.Endpoints {
+			fmt.Fprintf(&b, "[host.%q]\n  capabilities = [\"pull\", \"resolve\"]\n", endpoint)
+		}
+		files = append(files, bootstrapv1.File{
+			Path:        path.Join(containerdHostsDir, mirror.Registry, "hosts.toml"),
+			Owner:       "root:root",
+			Permissions: "0644",
+			Content:     b.String(),
+		})
+	}
+
+	return files
+}
+
+// proxyDropIn returns a systemd drop-in setting the proxy environment variables of a service.
+func proxyDropIn(proxy *bootstrapv1.ProxyConfiguration) string {
+	var b strings.Builder
+	b.WriteString("[Service]\n")
+	if proxy.HTTPProxy != "" {
+		fmt.Fprintf(&b, "Environment=\"HTTP_PROXY=%s\"\n", proxy.HTTPProxy)
+	}
+	if proxy.HTTPSProxy != "" {
+		fmt.Fprintf(&b, "Environment=\"HTTPS_PROXY=%s\"\n", proxy.HTTPSProxy)
+	}
+	if len(proxy.NoProxy) > 0 {
+		fmt.Fprintf(&b, "Environment=\"NO_PROXY=%s\"\n", strings.Join(proxy.NoProxy, ","))
+	}
+	return b.String()
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudinit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
+)
+
+const (
+	preKubeadmChecksScriptName        = "/usr/local/bin/kubeadm-pre-checks"
+	preKubeadmChecksScriptOwner       = "root"
+	preKubeadmChecksScriptPermissions = "0755"
+
+	// bootstrapFailureMessageFile is written with the reason of a bootstrap failure, so that infrastructure providers
+	// can report it next to the missing /run/cluster-api/bootstrap-success.complete sentinel file.
+	bootstrapFailureMessageFile = "/run/cluster-api/bootstrap-failure.message"
+
+	defaultPreKubeadmCheckTimeout  = 5 * time.Minute
+	defaultPreKubeadmCheckInterval = 5 * time.Second
+
+	preKubeadmChecksScriptHeader = `#!/bin/bash
+# Runs the pre-kubeadm checks, retrying each check until it succeeds or its timeout expires.
+# If a check fails, the failure is written to ` + bootstrapFailureMessageFile + ` and the script exits with 1,
+# so that kubeadm doesn't run.
+
+run_check() {
+  local name="$1" timeout="$2" interval="$3" check="$4"
+  local deadline=$(($(date +%s) + timeout))
+  until "${check}"; do
+    if [ "$(date +%s)" -ge "${deadline}" ]; then
+      echo "pre-kubeadm check ${name} did not succeed within ${timeout}s" | tee ` + bootstrapFailureMessageFile + ` >&2
+      exit 1
+    fi
+    sleep "${interval}"
+  done
+}
+`
+)
+
+// preparePreKubeadmChecks adds the script running the pre-kubeadm checks, if any, and sets the command running it
+// before kubeadm.
+func (input *BaseUserData) preparePreKubeadmChecks() {
+	if len(input.PreKubeadmChecks) == 0 {
+		return
+	}
+	input.WriteFiles = append(input.WriteFiles, bootstrapv1.File{
+		Path:        preKubeadmChecksScriptName,
+		Owner:       preKubeadmChecksScriptOwner,
+		Permissions: preKubeadmChecksScriptPermissions,
+		Content:     preKubeadmChecksScript(input.PreKubeadmChecks),
+	})
+	input.PreKubeadmChecksCommand = preKubeadmChecksScriptName
+}
+
+// preKubeadmChecksScript returns the script running the pre-kubeadm checks; each check command runs in a subshell,
+// so that it can't exit the script. Check names are DNS labels, so they don't need to be quoted.
+func preKubeadmChecksScript(checks []bootstrapv1.PreKubeadmCheck) string {
+	var b strings.Builder
+	b.WriteString(preKubeadmChecksScriptHeader)
+	for i, check := range checks {
+		timeout, interval := defaultPreKubeadmCheckTimeout, defaultPreKubeadmCheckInterval
+		if check.Timeout != nil {
+			timeout = check.Timeout.Duration
+		}
+		if check.Interval != nil {
+			interval = check.Interval.Duration
+		}
+		fmt.Fprintf(&b, "\ncheck_%d() (\n%s\n)\n", i, check.Command)
+		fmt.Fprintf(&b, "run_check %s %d %d check_%d\n", check.Name, durationSeconds(timeout), durationSeconds(interval), i)
+	}
+	return b.String()
+}
+
+// durationSeconds returns a duration in seconds, rounded up to at least one second.
+func durationSeconds(d time.Duration) int64 {
+	s := int64((d + time.Second - 1) / time.Second)
+	if s < 1 {
+		return 1
+	}
+	return s
+}
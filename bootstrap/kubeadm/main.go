@@ -36,6 +36,9 @@ import (
 	kubeadmbootstrapcontrollers "sigs.k8s.io/cluster-api/bootstrap/kubeadm/controllers"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/internal/managercache"
+	"sigs.k8s.io/cluster-api/util/metrics"
+	"sigs.k8s.io/cluster-api/util/uncached"
 	"sigs.k8s.io/cluster-api/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -60,14 +63,17 @@ func init() {
 
 var (
 	metricsBindAddr             string
+	metricsSecure               bool
+	metricsCertDir              string
 	enableLeaderElection        bool
 	leaderElectionLeaseDuration time.Duration
 	leaderElectionRenewDeadline time.Duration
 	leaderElectionRetryPeriod   time.Duration
-	watchNamespace              string
+	watchNamespaces             []string
 	profilerAddress             string
 	kubeadmConfigConcurrency    int
 	syncPeriod                  time.Duration
+	secretReadCacheTTL          time.Duration
 	webhookPort                 int
 )
 
@@ -75,6 +81,12 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&metricsBindAddr, "metrics-bind-addr", ":8080",
 		"The address the metric endpoint binds to.")
 
+	fs.BoolVar(&metricsSecure, "metrics-secure", false,
+		"Serve the metric endpoint over HTTPS, authenticating and authorizing requests with TokenReviews and SubjectAccessReviews.")
+
+	fs.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+		"Directory containing the tls.crt and tls.key files used for serving the metric endpoint when --metrics-secure is set. If unspecified, a self-signed certificate is generated.")
+
 	fs.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 
@@ -87,8 +99,8 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&leaderElectionRetryPeriod, "leader-elect-retry-period", 2*time.Second,
 		"Duration the LeaderElector clients should wait between tries of actions (duration string)")
 
-	fs.StringVar(&watchNamespace, "namespace", "",
-		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
+	fs.StringSliceVar(&watchNamespaces, "namespace", nil,
+		"Comma-separated list of namespaces that the controller watches to reconcile cluster-api objects, with one cache per namespace. If unspecified, the controller watches for cluster-api objects across all namespaces.")
 
 	fs.StringVar(&profilerAddress, "profiler-address", "",
 		"Bind address to expose the pprof profiler (e.g. localhost:6060)")
@@ -99,6 +111,9 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
+	fs.DurationVar(&secretReadCacheTTL, "secret-read-cache-ttl", 0,
+		"The time bootstrap data and kubeconfig secrets are served from a read-through cache after being read, reducing the reads of secrets from the API server; secrets written by other clients can be stale up to this time. 0 disables the cache")
+
 	fs.DurationVar(&kubeadmbootstrapcontrollers.DefaultTokenTTL, "bootstrap-token-ttl", 15*time.Minute,
 		"The amount of time the bootstrap token will be valid")
 
@@ -124,16 +139,26 @@ func main() {
 		}()
 	}
 
+	// When serving the metrics securely, the controller-runtime metrics server is disabled.
+	managerMetricsBindAddr := metricsBindAddr
+	if metricsSecure {
+		managerMetricsBindAddr = "0"
+	}
+
+	watchNamespace, newCache := managercache.ForNamespaces(watchNamespaces)
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme:             scheme,
-		MetricsBindAddress: metricsBindAddr,
+		MetricsBindAddress: managerMetricsBindAddr,
 		LeaderElection:     enableLeaderElection,
 		LeaderElectionID:   "kubeadm-bootstrap-manager-leader-election-capi",
 		LeaseDuration:      &leaderElectionLeaseDuration,
 		RenewDeadline:      &leaderElectionRenewDeadline,
 		RetryPeriod:        &leaderElectionRetryPeriod,
 		Namespace:          watchNamespace,
+		NewCache:           newCache,
 		SyncPeriod:         &syncPeriod,
+		ClientBuilder:      uncached.NewClientBuilder(uncached.Options{SecretTTL: secretReadCacheTTL}),
 		ClientDisableCacheFor: []client.Object{
 			&corev1.ConfigMap{},
 			&corev1.Secret{},
@@ -145,6 +170,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	if metricsSecure {
+		metricsServer, err := metrics.NewSecureServer(metrics.SecureServerOptions{
+			BindAddress: metricsBindAddr,
+			CertDir:     metricsCertDir,
+			Config:      mgr.GetConfig(),
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create secure metrics server")
+			os.Exit(1)
+		}
+		if err := mgr.Add(metricsServer); err != nil {
+			setupLog.Error(err, "unable to add secure metrics server to the manager")
+			os.Exit(1)
+		}
+	}
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
@@ -0,0 +1,196 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// dataKeySize is the size of the data encryption keys generated by the envelope encrypter (AES-256).
+const dataKeySize = 32
+
+// BootstrapDataEncrypter encrypts bootstrap data before it is stored in a secret, and decrypts it
+// when it is served to the infrastructure provider.
+type BootstrapDataEncrypter interface {
+	// Name identifies the encrypter; it is stored in the BootstrapDataEncryptionAnnotation of the secrets
+	// written through the encrypter, so the same encrypter can be selected to decrypt them.
+	Name() string
+
+	// Encrypt returns the encrypted data.
+	Encrypt(ctx context.Context, plaintext []byte) ([]byte, error)
+
+	// Decrypt returns the data decrypted.
+	Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error)
+}
+
+// KeyService wraps and unwraps data encryption keys with a key encryption key, e.g. by calling out to a KMS plugin;
+// the key encryption key never leaves the key service.
+type KeyService interface {
+	// WrapKey returns the data encryption key encrypted with the key encryption key.
+	WrapKey(ctx context.Context, dataKey []byte) ([]byte, error)
+
+	// UnwrapKey returns the data encryption key decrypted with the key encryption key.
+	UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error)
+}
+
+// NewEnvelopeEncrypter returns a BootstrapDataEncrypter implementing envelope encryption: the data is encrypted
+// with AES-GCM using a data encryption key generated for each secret, which is stored next to the data after
+// being wrapped by the given key service.
+func NewEnvelopeEncrypter(name string, keys KeyService) BootstrapDataEncrypter {
+	return &envelopeEncrypter{name: name, keys: keys}
+}
+
+type envelopeEncrypter struct {
+	name string
+	keys KeyService
+}
+
+func (e *envelopeEncrypter) Name() string {
+	return e.name
+}
+
+// Encrypt returns the wrapped data encryption key length (2 bytes, big endian), the wrapped data encryption key,
+// the nonce and the data encrypted with the data encryption key.
+func (e *envelopeEncrypter) Encrypt(ctx context.Context, plaintext []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, errors.Wrap(err, "failed to generate data encryption key")
+	}
+	wrappedKey, err := e.keys.WrapKey(ctx, dataKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to wrap data encryption key")
+	}
+	if len(wrappedKey) > 0xFFFF {
+		return nil, errors.Errorf("wrapped data encryption key is too long: %d bytes", len(wrappedKey))
+	}
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	out := make([]byte, 2, 2+len(wrappedKey)+len(nonce)+len(plaintext)+gcm.Overhead())
+	binary.BigEndian.PutUint16(out, uint16(len(wrappedKey)))
+	out = append(out, wrappedKey...)
+	out = append(out, nonce...)
+	return gcm.Seal(out, nonce, plaintext, nil), nil
+}
+
+func (e *envelopeEncrypter) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < 2 {
+		return nil, errors.New("encrypted data is too short")
+	}
+	keyLen := int(binary.BigEndian.Uint16(ciphertext))
+	ciphertext = ciphertext[2:]
+	if len(ciphertext) < keyLen {
+		return nil, errors.New("encrypted data is too short")
+	}
+
+	dataKey, err := e.keys.UnwrapKey(ctx, ciphertext[:keyLen])
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unwrap data encryption key")
+	}
+	ciphertext = ciphertext[keyLen:]
+
+	gcm, err := newGCM(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+	plaintext, err := gcm.Open(nil, ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt data")
+	}
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create cipher")
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create GCM cipher")
+	}
+	return gcm, nil
+}
+
+// EncryptBootstrapData encrypts the value of a bootstrap data secret with the given encrypter, if any, and sets
+// the BootstrapDataEncryptionAnnotation accordingly.
+func EncryptBootstrapData(ctx context.Context, s *corev1.Secret, encrypter BootstrapDataEncrypter) error {
+	if encrypter == nil {
+		return nil
+	}
+	value, err := encrypter.Encrypt(ctx, s.Data["value"])
+	if err != nil {
+		return errors.Wrapf(err, "failed to encrypt bootstrap data with %q", encrypter.Name())
+	}
+	s.Data["value"] = value
+	if s.Annotations == nil {
+		s.Annotations = map[string]string{}
+	}
+	s.Annotations[clusterv1.BootstrapDataEncryptionAnnotation] = encrypter.Name()
+	return nil
+}
+
+// GetBootstrapData returns the plaintext value of the bootstrap data secret with the given name; infrastructure
+// providers should use it to read bootstrap data, so secrets encrypted at rest are decrypted only when served to the
+// infrastructure. The given decrypters are matched by name against the BootstrapDataEncryptionAnnotation.
+func GetBootstrapData(ctx context.Context, c client.Reader, namespace, name string, decrypters ...BootstrapDataEncrypter) ([]byte, error) {
+	s := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: namespace, Name: name}
+	if err := c.Get(ctx, key, s); err != nil {
+		return nil, errors.Wrapf(err, "failed to retrieve bootstrap data secret %s/%s", namespace, name)
+	}
+
+	value, ok := s.Data["value"]
+	if !ok {
+		return nil, errors.Errorf("bootstrap data secret %s/%s value key is missing", namespace, name)
+	}
+
+	provider, encrypted := s.Annotations[clusterv1.BootstrapDataEncryptionAnnotation]
+	if !encrypted {
+		return value, nil
+	}
+	for _, d := range decrypters {
+		if d.Name() != provider {
+			continue
+		}
+		plaintext, err := d.Decrypt(ctx, value)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decrypt bootstrap data secret %s/%s", namespace, name)
+		}
+		return plaintext, nil
+	}
+	return nil, errors.Errorf("bootstrap data secret %s/%s is encrypted with %q, but no matching decrypter is configured", namespace, name, provider)
+}
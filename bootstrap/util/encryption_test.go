@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// xorKeyService is a KeyService wrapping keys with a XOR, for testing purposes only.
+type xorKeyService struct{}
+
+func (xorKeyService) WrapKey(_ context.Context, dataKey []byte) ([]byte, error) {
+	wrapped := make([]byte, len(dataKey))
+	for i := range dataKey {
+		wrapped[i] = dataKey[i] ^ 0x5a
+	}
+	return wrapped, nil
+}
+
+func (k xorKeyService) UnwrapKey(ctx context.Context, wrappedKey []byte) ([]byte, error) {
+	return k.WrapKey(ctx, wrappedKey)
+}
+
+func TestEnvelopeEncrypter(t *testing.T) {
+	g := NewWithT(t)
+
+	plaintext := []byte("#cloud-config\nruncmd: [kubeadm join]")
+	e := NewEnvelopeEncrypter("test", xorKeyService{})
+
+	ciphertext, err := e.Encrypt(ctx, plaintext)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(bytes.Contains(ciphertext, plaintext)).To(BeFalse())
+
+	decrypted, err := e.Decrypt(ctx, ciphertext)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(decrypted).To(Equal(plaintext))
+
+	// Tampered data fails to decrypt.
+	ciphertext[len(ciphertext)-1] ^= 0xff
+	_, err = e.Decrypt(ctx, ciphertext)
+	g.Expect(err).To(HaveOccurred())
+
+	_, err = e.Decrypt(ctx, []byte{0x00})
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestGetBootstrapData(t *testing.T) {
+	plaintext := []byte("bootstrap data")
+	encrypter := NewEnvelopeEncrypter("test", xorKeyService{})
+
+	newSecret := func(name string, encrypt bool) *corev1.Secret {
+		s := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: name},
+			Data:       map[string][]byte{"value": plaintext},
+		}
+		if encrypt {
+			g := NewWithT(t)
+			g.Expect(EncryptBootstrapData(ctx, s, encrypter)).To(Succeed())
+			g.Expect(s.Annotations).To(HaveKeyWithValue(clusterv1.BootstrapDataEncryptionAnnotation, "test"))
+			g.Expect(s.Data["value"]).NotTo(Equal(plaintext))
+		}
+		return s
+	}
+	c := fake.NewClientBuilder().WithObjects(newSecret("plain", false), newSecret("encrypted", true)).Build()
+
+	tests := []struct {
+		name       string
+		secret     string
+		decrypters []BootstrapDataEncrypter
+		wantErr    bool
+	}{
+		{
+			name:   "plaintext secret",
+			secret: "plain",
+		},
+		{
+			name:       "plaintext secret with decrypters",
+			secret:     "plain",
+			decrypters: []BootstrapDataEncrypter{encrypter},
+		},
+		{
+			name:       "encrypted secret",
+			secret:     "encrypted",
+			decrypters: []BootstrapDataEncrypter{NewEnvelopeEncrypter("other", xorKeyService{}), encrypter},
+		},
+		{
+			name:    "encrypted secret without decrypters",
+			secret:  "encrypted",
+			wantErr: true,
+		},
+		{
+			name:       "encrypted secret without a matching decrypter",
+			secret:     "encrypted",
+			decrypters: []BootstrapDataEncrypter{NewEnvelopeEncrypter("other", xorKeyService{})},
+			wantErr:    true,
+		},
+		{
+			name:    "missing secret",
+			secret:  "missing",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := GetBootstrapData(ctx, c, metav1.NamespaceDefault, tt.secret, tt.decrypters...)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(plaintext))
+		})
+	}
+}
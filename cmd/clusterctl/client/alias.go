@@ -38,9 +38,16 @@ type ComponentsOptions repository.ComponentsOptions
 // Template wraps a YAML file that defines the cluster objects (Cluster, Machines etc.).
 type Template repository.Template
 
+// WorkerGroup defines a group of worker machines of a workload cluster, which is generated as a MachineDeployment.
+type WorkerGroup repository.WorkerGroup
+
 // UpgradePlan defines a list of possible upgrade targets for a management group.
 type UpgradePlan cluster.UpgradePlan
 
+// InstalledProvider describes a provider instance installed in the management cluster, with the API Version of
+// Cluster API (contract) supported by its version and the versions available for upgrade.
+type InstalledProvider cluster.InstalledProvider
+
 // CertManagerUpgradePlan defines the upgrade plan if cert-manager needs to be
 // upgraded to a different version.
 type CertManagerUpgradePlan cluster.CertManagerUpgradePlan
@@ -51,3 +58,6 @@ type Kubeconfig cluster.Kubeconfig
 // Processor defines the methods necessary for creating a specific yaml
 // processor.
 type Processor yaml.Processor
+
+// ObjectGraph defines the graph of the Cluster API objects existing in a management cluster.
+type ObjectGraph cluster.ObjectGraph
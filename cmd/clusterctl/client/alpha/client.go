@@ -19,11 +19,15 @@ package alpha
 // Client is the alpha client
 type Client interface {
 	Rollout() Rollout
+	SupportBundle() SupportBundle
+	MachineDeploymentGenerator() MachineDeploymentGenerator
 }
 
 // alphaClient implements Client.
 type alphaClient struct {
-	rollout Rollout
+	rollout                    Rollout
+	supportBundle              SupportBundle
+	machineDeploymentGenerator MachineDeploymentGenerator
 }
 
 // ensure alphaClient implements Client.
@@ -39,6 +43,20 @@ func InjectRollout(rollout Rollout) Option {
 	}
 }
 
+// InjectSupportBundle allows to override the support bundle implementation to use.
+func InjectSupportBundle(supportBundle SupportBundle) Option {
+	return func(c *alphaClient) {
+		c.supportBundle = supportBundle
+	}
+}
+
+// InjectMachineDeploymentGenerator allows to override the MachineDeployment generator implementation to use.
+func InjectMachineDeploymentGenerator(machineDeploymentGenerator MachineDeploymentGenerator) Option {
+	return func(c *alphaClient) {
+		c.machineDeploymentGenerator = machineDeploymentGenerator
+	}
+}
+
 // New returns a Client.
 func New(options ...Option) Client {
 	return newAlphaClient(options...)
@@ -55,9 +73,27 @@ func newAlphaClient(options ...Option) *alphaClient {
 		client.rollout = newRolloutClient()
 	}
 
+	// if there is an injected support bundle, use it, otherwise use a default one
+	if client.supportBundle == nil {
+		client.supportBundle = newSupportBundleClient()
+	}
+
+	// if there is an injected MachineDeployment generator, use it, otherwise use a default one
+	if client.machineDeploymentGenerator == nil {
+		client.machineDeploymentGenerator = newMachineDeploymentGenerator()
+	}
+
 	return client
 }
 
 func (c *alphaClient) Rollout() Rollout {
 	return c.rollout
 }
+
+func (c *alphaClient) SupportBundle() SupportBundle {
+	return c.supportBundle
+}
+
+func (c *alphaClient) MachineDeploymentGenerator() MachineDeploymentGenerator {
+	return c.machineDeploymentGenerator
+}
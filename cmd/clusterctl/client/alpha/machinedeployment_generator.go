@@ -0,0 +1,287 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// MachineDeploymentVariablePrefix is the prefix of the variables overriding fields of the generated MachineDeployment.
+	MachineDeploymentVariablePrefix = "machinedeployment."
+
+	// BootstrapVariablePrefix is the prefix of the variables overriding fields of the generated bootstrap config template.
+	BootstrapVariablePrefix = "bootstrap."
+
+	// InfrastructureVariablePrefix is the prefix of the variables overriding fields of the generated infrastructure
+	// machine template.
+	InfrastructureVariablePrefix = "infrastructure."
+)
+
+// GenerateMachineDeploymentOptions carries the options supported by MachineDeploymentGenerator.
+type GenerateMachineDeploymentOptions struct {
+	// Namespace of the MachineDeployment to clone; the generated objects are in the same namespace.
+	Namespace string
+
+	// From is the name of the MachineDeployment to clone.
+	From string
+
+	// Name of the generated MachineDeployment, and of the generated bootstrap config and infrastructure machine templates.
+	Name string
+
+	// Replicas of the generated MachineDeployment. If nil, the replicas of the cloned MachineDeployment are used.
+	Replicas *int32
+
+	// Variables override fields of the generated objects. Keys are field paths, e.g. spec.template.spec.version, prefixed by
+	// the object to override: machinedeployment., bootstrap. or infrastructure.; values are parsed as YAML.
+	Variables map[string]string
+}
+
+// MachineDeploymentGenerator defines the behavior of a MachineDeployment generator implementation.
+type MachineDeploymentGenerator interface {
+	// Generate returns a new MachineDeployment, with its bootstrap config and infrastructure machine templates, by cloning
+	// an existing MachineDeployment and its templates.
+	Generate(proxy cluster.Proxy, options GenerateMachineDeploymentOptions) ([]unstructured.Unstructured, error)
+}
+
+var _ MachineDeploymentGenerator = &machineDeploymentGenerator{}
+
+type machineDeploymentGenerator struct{}
+
+func newMachineDeploymentGenerator() MachineDeploymentGenerator {
+	return &machineDeploymentGenerator{}
+}
+
+func (g *machineDeploymentGenerator) Generate(proxy cluster.Proxy, options GenerateMachineDeploymentOptions) ([]unstructured.Unstructured, error) {
+	if options.From == "" {
+		return nil, errors.New("the name of the MachineDeployment to clone must be set")
+	}
+	if options.Name == "" {
+		return nil, errors.New("the name of the MachineDeployment to generate must be set")
+	}
+	variables, err := splitMachineDeploymentVariables(options.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	c, err := proxy.NewClient()
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := getMachineDeployment(proxy, options.From, options.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureNotExists(c, clusterv1.GroupVersion.WithKind("MachineDeployment").GroupKind().String(), &clusterv1.MachineDeployment{}, options.Namespace, options.Name); err != nil {
+		return nil, err
+	}
+
+	md := &clusterv1.MachineDeployment{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "MachineDeployment",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        options.Name,
+			Namespace:   options.Namespace,
+			Labels:      from.Labels,
+			Annotations: cloneAnnotations(from.Annotations),
+		},
+		Spec: *from.Spec.DeepCopy(),
+	}
+	if options.Replicas != nil {
+		md.Spec.Replicas = options.Replicas
+	}
+	md.Spec.Paused = false
+	// The MachineDeployment name label, if set by the webhooks, must select the Machines of the generated MachineDeployment.
+	if _, ok := md.Spec.Selector.MatchLabels[clusterv1.MachineDeploymentLabelName]; ok {
+		md.Spec.Selector.MatchLabels[clusterv1.MachineDeploymentLabelName] = options.Name
+	}
+	if _, ok := md.Spec.Template.Labels[clusterv1.MachineDeploymentLabelName]; ok {
+		md.Spec.Template.Labels[clusterv1.MachineDeploymentLabelName] = options.Name
+	}
+
+	var objs []unstructured.Unstructured
+	if ref := md.Spec.Template.Spec.Bootstrap.ConfigRef; ref != nil {
+		template, err := cloneTemplate(c, ref, options.Name, variables[BootstrapVariablePrefix])
+		if err != nil {
+			return nil, err
+		}
+		ref.Name = options.Name
+		objs = append(objs, *template)
+	}
+	template, err := cloneTemplate(c, &md.Spec.Template.Spec.InfrastructureRef, options.Name, variables[InfrastructureVariablePrefix])
+	if err != nil {
+		return nil, err
+	}
+	md.Spec.Template.Spec.InfrastructureRef.Name = options.Name
+	objs = append(objs, *template)
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(md)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to convert MachineDeployment %s/%s", md.Namespace, md.Name)
+	}
+	mdObj := unstructured.Unstructured{Object: content}
+	unstructured.RemoveNestedField(mdObj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(mdObj.Object, "spec", "template", "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(mdObj.Object, "status")
+	if err := setVariables(&mdObj, variables[MachineDeploymentVariablePrefix]); err != nil {
+		return nil, err
+	}
+
+	return append([]unstructured.Unstructured{mdObj}, objs...), nil
+}
+
+// cloneTemplate returns a copy of the template referenced by ref with the given name, and with the given variables set.
+func cloneTemplate(c client.Client, ref *corev1.ObjectReference, name string, variables map[string]interface{}) (*unstructured.Unstructured, error) {
+	from := &unstructured.Unstructured{}
+	from.SetGroupVersionKind(ref.GroupVersionKind())
+	if err := c.Get(context.TODO(), client.ObjectKey{Namespace: ref.Namespace, Name: ref.Name}, from); err != nil {
+		return nil, errors.Wrapf(err, "error reading %s %s/%s", ref.Kind, ref.Namespace, ref.Name)
+	}
+
+	existing := &unstructured.Unstructured{}
+	existing.SetGroupVersionKind(ref.GroupVersionKind())
+	if err := ensureNotExists(c, ref.Kind, existing, ref.Namespace, name); err != nil {
+		return nil, err
+	}
+
+	template := &unstructured.Unstructured{}
+	template.SetAPIVersion(from.GetAPIVersion())
+	template.SetKind(from.GetKind())
+	template.SetName(name)
+	template.SetNamespace(from.GetNamespace())
+	template.SetLabels(from.GetLabels())
+	template.SetAnnotations(cloneAnnotations(from.GetAnnotations()))
+	if spec, ok := from.Object["spec"]; ok {
+		template.Object["spec"] = runtime.DeepCopyJSONValue(spec)
+	}
+	if err := setVariables(template, variables); err != nil {
+		return nil, err
+	}
+	return template, nil
+}
+
+// ensureNotExists returns an error if an object with the given name already exists.
+func ensureNotExists(c client.Client, kind string, obj client.Object, namespace, name string) error {
+	err := c.Get(context.TODO(), client.ObjectKey{Namespace: namespace, Name: name}, obj)
+	switch {
+	case err == nil:
+		return errors.Errorf("%s %s/%s already exists", kind, namespace, name)
+	case apierrors.IsNotFound(err):
+		return nil
+	default:
+		return errors.Wrapf(err, "error reading %s %s/%s", kind, namespace, name)
+	}
+}
+
+// cloneAnnotations returns the annotations of a cloned object, without the ones recording the state of the object.
+func cloneAnnotations(annotations map[string]string) map[string]string {
+	ret := map[string]string{}
+	for k, v := range annotations {
+		if strings.HasPrefix(k, "machinedeployment.clusters.x-k8s.io/") || k == corev1.LastAppliedConfigAnnotation {
+			continue
+		}
+		ret[k] = v
+	}
+	if len(ret) == 0 {
+		return nil
+	}
+	return ret
+}
+
+// splitMachineDeploymentVariables parses the variables, and splits them by the prefix of the object they override.
+func splitMachineDeploymentVariables(variables map[string]string) (map[string]map[string]interface{}, error) {
+	ret := map[string]map[string]interface{}{}
+	for k, v := range variables {
+		prefix := ""
+		for _, p := range []string{MachineDeploymentVariablePrefix, BootstrapVariablePrefix, InfrastructureVariablePrefix} {
+			if strings.HasPrefix(k, p) {
+				prefix = p
+			}
+		}
+		path := strings.TrimPrefix(k, prefix)
+		if prefix == "" || path == "" {
+			return nil, errors.Errorf("invalid variable %q: it must be a field path prefixed by %s, %s or %s",
+				k, MachineDeploymentVariablePrefix, BootstrapVariablePrefix, InfrastructureVariablePrefix)
+		}
+		if path == "metadata" || strings.HasPrefix(path, "metadata.name") || strings.HasPrefix(path, "metadata.namespace") {
+			return nil, errors.Errorf("invalid variable %q: the name and the namespace of the generated objects cannot be overridden", k)
+		}
+
+		var value interface{}
+		if err := yaml.Unmarshal([]byte(v), &value); err != nil {
+			return nil, errors.Wrapf(err, "invalid value for variable %q", k)
+		}
+		if ret[prefix] == nil {
+			ret[prefix] = map[string]interface{}{}
+		}
+		ret[prefix][path] = normalizeVariableValue(value)
+	}
+	return ret, nil
+}
+
+// normalizeVariableValue converts the whole numbers parsed from YAML, which are float64, to int64, so they are
+// marshalled as integers.
+func normalizeVariableValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case float64:
+		if v == float64(int64(v)) {
+			return int64(v)
+		}
+	case map[string]interface{}:
+		for k, e := range v {
+			v[k] = normalizeVariableValue(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = normalizeVariableValue(e)
+		}
+	}
+	return value
+}
+
+// setVariables sets the fields of an object; fields are set in lexical order of their path, so that a field can be
+// set after setting its parent.
+func setVariables(obj *unstructured.Unstructured, variables map[string]interface{}) error {
+	paths := make([]string, 0, len(variables))
+	for path := range variables {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := unstructured.SetNestedField(obj.Object, variables[path], strings.Split(path, ".")...); err != nil {
+			return errors.Wrapf(err, "failed to set %s of %s %s/%s", path, obj.GetKind(), obj.GetNamespace(), obj.GetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	fakebootstrap "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/bootstrap"
+	fakeinfrastructure "sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test/providers/infrastructure"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_machineDeploymentGenerator_Generate(t *testing.T) {
+	md := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "md-0",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "cluster1"},
+			Annotations: map[string]string{
+				clusterv1.RevisionAnnotation: "3",
+				"owner":                      "team-a",
+			},
+		},
+		Spec: clusterv1.MachineDeploymentSpec{
+			ClusterName: "cluster1",
+			Replicas:    pointer.Int32Ptr(3),
+			Paused:      true,
+			Selector: metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					clusterv1.ClusterLabelName:           "cluster1",
+					clusterv1.MachineDeploymentLabelName: "md-0",
+				},
+			},
+			Template: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{
+						clusterv1.ClusterLabelName:           "cluster1",
+						clusterv1.MachineDeploymentLabelName: "md-0",
+					},
+				},
+				Spec: clusterv1.MachineSpec{
+					ClusterName: "cluster1",
+					Version:     pointer.StringPtr("v1.20.2"),
+					Bootstrap: clusterv1.Bootstrap{
+						ConfigRef: &corev1.ObjectReference{
+							APIVersion: fakebootstrap.GroupVersion.String(),
+							Kind:       "GenericBootstrapConfigTemplate",
+							Namespace:  "default",
+							Name:       "md-0-bootstrap",
+						},
+					},
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: fakeinfrastructure.GroupVersion.String(),
+						Kind:       "GenericInfrastructureMachineTemplate",
+						Namespace:  "default",
+						Name:       "md-0-infra",
+					},
+				},
+			},
+		},
+	}
+	bootstrapTemplate := &fakebootstrap.GenericBootstrapConfigTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "md-0-bootstrap",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "cluster1"},
+		},
+	}
+	infrastructureTemplate := &fakeinfrastructure.GenericInfrastructureMachineTemplate{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "md-0-infra",
+			Labels:    map[string]string{clusterv1.ClusterLabelName: "cluster1"},
+		},
+	}
+	existing := &clusterv1.MachineDeployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "default",
+			Name:      "md-existing",
+		},
+	}
+
+	tests := []struct {
+		name    string
+		options GenerateMachineDeploymentOptions
+		check   func(g *WithT, objs []unstructured.Unstructured)
+		wantErr bool
+	}{
+		{
+			name: "clones the MachineDeployment and its templates",
+			options: GenerateMachineDeploymentOptions{
+				Namespace: "default",
+				From:      "md-0",
+				Name:      "md-1",
+			},
+			check: func(g *WithT, objs []unstructured.Unstructured) {
+				g.Expect(objs).To(HaveLen(3))
+
+				g.Expect(objs[0].GetKind()).To(Equal("MachineDeployment"))
+				g.Expect(objs[0].GetName()).To(Equal("md-1"))
+				g.Expect(objs[0].GetNamespace()).To(Equal("default"))
+				g.Expect(objs[0].GetLabels()).To(Equal(md.Labels))
+				g.Expect(objs[0].GetAnnotations()).To(Equal(map[string]string{"owner": "team-a"}))
+				g.Expect(nestedField(objs[0].Object, "spec", "replicas")).To(Equal(int64(3)))
+				g.Expect(nestedField(objs[0].Object, "spec", "paused")).To(BeNil())
+				g.Expect(nestedField(objs[0].Object, "spec", "selector", "matchLabels", clusterv1.MachineDeploymentLabelName)).To(Equal("md-1"))
+				g.Expect(nestedField(objs[0].Object, "spec", "template", "metadata", "labels", clusterv1.MachineDeploymentLabelName)).To(Equal("md-1"))
+				g.Expect(nestedField(objs[0].Object, "spec", "template", "spec", "bootstrap", "configRef", "name")).To(Equal("md-1"))
+				g.Expect(nestedField(objs[0].Object, "spec", "template", "spec", "infrastructureRef", "name")).To(Equal("md-1"))
+				g.Expect(objs[0].Object).ToNot(HaveKey("status"))
+
+				g.Expect(objs[1].GetKind()).To(Equal("GenericBootstrapConfigTemplate"))
+				g.Expect(objs[1].GetName()).To(Equal("md-1"))
+				g.Expect(objs[1].GetLabels()).To(Equal(bootstrapTemplate.Labels))
+
+				g.Expect(objs[2].GetKind()).To(Equal("GenericInfrastructureMachineTemplate"))
+				g.Expect(objs[2].GetName()).To(Equal("md-1"))
+				g.Expect(objs[2].GetLabels()).To(Equal(infrastructureTemplate.Labels))
+			},
+		},
+		{
+			name: "overrides the replicas and the variables",
+			options: GenerateMachineDeploymentOptions{
+				Namespace: "default",
+				From:      "md-0",
+				Name:      "md-1",
+				Replicas:  pointer.Int32Ptr(5),
+				Variables: map[string]string{
+					"machinedeployment.spec.template.spec.version":        "v1.20.4",
+					"infrastructure.spec.template.spec.instanceType":      "m5.xlarge",
+					"infrastructure.spec.template.spec.rootVolume.size":   "100",
+					"bootstrap.spec.template.spec.joinConfiguration.name": "worker",
+				},
+			},
+			check: func(g *WithT, objs []unstructured.Unstructured) {
+				g.Expect(objs).To(HaveLen(3))
+				g.Expect(nestedField(objs[0].Object, "spec", "replicas")).To(Equal(int64(5)))
+				g.Expect(nestedField(objs[0].Object, "spec", "template", "spec", "version")).To(Equal("v1.20.4"))
+				g.Expect(nestedField(objs[1].Object, "spec", "template", "spec", "joinConfiguration", "name")).To(Equal("worker"))
+				g.Expect(nestedField(objs[2].Object, "spec", "template", "spec", "instanceType")).To(Equal("m5.xlarge"))
+				g.Expect(nestedField(objs[2].Object, "spec", "template", "spec", "rootVolume", "size")).To(Equal(int64(100)))
+			},
+		},
+		{
+			name: "fails if the MachineDeployment to clone does not exist",
+			options: GenerateMachineDeploymentOptions{
+				Namespace: "default",
+				From:      "md-missing",
+				Name:      "md-1",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the MachineDeployment to generate already exists",
+			options: GenerateMachineDeploymentOptions{
+				Namespace: "default",
+				From:      "md-0",
+				Name:      "md-existing",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if the templates to generate already exist",
+			options: GenerateMachineDeploymentOptions{
+				Namespace: "default",
+				From:      "md-0",
+				Name:      "md-0-infra",
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if a variable has no object prefix",
+			options: GenerateMachineDeploymentOptions{
+				Namespace: "default",
+				From:      "md-0",
+				Name:      "md-1",
+				Variables: map[string]string{"spec.replicas": "3"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "fails if a variable overrides the name",
+			options: GenerateMachineDeploymentOptions{
+				Namespace: "default",
+				From:      "md-0",
+				Name:      "md-1",
+				Variables: map[string]string{"infrastructure.metadata.name": "foo"},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			proxy := test.NewFakeProxy().WithObjs([]client.Object{md.DeepCopy(), bootstrapTemplate.DeepCopy(), infrastructureTemplate.DeepCopy(), existing.DeepCopy()}...)
+			objs, err := newMachineDeploymentGenerator().Generate(proxy, tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			tt.check(g, objs)
+		})
+	}
+}
+
+func nestedField(obj map[string]interface{}, fields ...string) interface{} {
+	value, _, _ := unstructured.NestedFieldNoCopy(obj, fields...)
+	return value
+}
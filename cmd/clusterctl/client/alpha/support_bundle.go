@@ -0,0 +1,454 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// redactedValue replaces sensitive values in the support bundle.
+	redactedValue = "REDACTED"
+
+	// supportBundleErrorsFile is the file listing the errors occurred while collecting the support bundle.
+	supportBundleErrorsFile = "errors.log"
+)
+
+var (
+	// sensitiveFields are the fields that might carry a kubeconfig, bootstrap data or credentials in any kind, e.g. the files
+	// and the users of a KubeadmConfig, the bootstrap tokens of the kubeadm configuration or the user data of an infrastructure machine.
+	sensitiveFields = sets.NewString("content", "passwd", "token", "tlsBootstrapToken", "kubeconfig", "bootstrapData", "userData", "customData")
+
+	// sensitiveContentMarkers identify string values embedding a kubeconfig or a private key, wherever they are stored.
+	sensitiveContentMarkers = []string{"client-key-data", "client-certificate-data", "PRIVATE KEY-----"}
+)
+
+// SupportBundleOptions carries the options supported by SupportBundle.
+type SupportBundleOptions struct {
+	// Namespaces where to collect Cluster API objects and events from.
+	// If empty, objects are collected from all the namespaces.
+	Namespaces []string
+
+	// LogTailLines defines the number of lines to collect from the end of each provider controller log.
+	// If not positive, the entire log is collected.
+	LogTailLines int64
+}
+
+// SupportBundle defines the behavior of a support bundle implementation.
+type SupportBundle interface {
+	// Collect gathers the Cluster API objects and secrets, the provider controller logs, the webhook configurations,
+	// the CRD versions and the events existing in a management cluster, and writes them as a gzipped tarball to w.
+	// Secret values, and kubeconfigs or bootstrap data stored in other kinds, are redacted. Errors occurring while
+	// collecting single items do not stop the collection, and are reported in the errors.log file of the tarball.
+	Collect(proxy cluster.Proxy, w io.Writer, options SupportBundleOptions) error
+}
+
+var _ SupportBundle = &supportBundle{}
+
+type supportBundle struct {
+	// getPodLogs returns the logs of a container of a Pod; it can be overridden for testing purposes.
+	getPodLogs func(ctx context.Context, proxy cluster.Proxy, pod *corev1.Pod, container string, tailLines int64) ([]byte, error)
+}
+
+func newSupportBundleClient() SupportBundle {
+	return &supportBundle{
+		getPodLogs: getPodLogs,
+	}
+}
+
+// supportBundleWriter writes the entries of a support bundle into a gzipped tarball,
+// keeping track of the errors occurred while collecting them.
+type supportBundleWriter struct {
+	tw   *tar.Writer
+	now  time.Time
+	errs []string
+}
+
+func (s *supportBundleWriter) writeFile(name string, data []byte) error {
+	if err := s.tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: s.now,
+	}); err != nil {
+		return errors.Wrapf(err, "failed to write header for %q", name)
+	}
+	if _, err := s.tw.Write(data); err != nil {
+		return errors.Wrapf(err, "failed to write %q", name)
+	}
+	return nil
+}
+
+func (s *supportBundleWriter) writeYAML(name string, obj interface{}) error {
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		s.recordError(errors.Wrapf(err, "failed to marshal %q", name))
+		return nil
+	}
+	return s.writeFile(name, data)
+}
+
+func (s *supportBundleWriter) recordError(err error) {
+	s.errs = append(s.errs, err.Error())
+}
+
+func (s *supportBundle) Collect(proxy cluster.Proxy, w io.Writer, options SupportBundleOptions) error {
+	ctx := context.TODO()
+
+	c, err := proxy.NewClient()
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	bundle := &supportBundleWriter{
+		tw:  tar.NewWriter(gw),
+		now: time.Now(),
+	}
+
+	namespaces := options.Namespaces
+	if len(namespaces) == 0 {
+		// An empty namespace lists objects in all the namespaces.
+		namespaces = []string{""}
+	}
+
+	collectors := []func() error{
+		func() error { return s.collectCRDsAndObjects(ctx, c, bundle, namespaces) },
+		func() error { return s.collectClusterSecrets(ctx, c, bundle, namespaces) },
+		func() error { return s.collectWebhookConfigurations(ctx, c, bundle) },
+		func() error { return s.collectProvidersAndLogs(ctx, proxy, c, bundle, options.LogTailLines) },
+		func() error { return s.collectEvents(ctx, c, bundle, namespaces) },
+	}
+	for _, collect := range collectors {
+		if err := collect(); err != nil {
+			return err
+		}
+	}
+
+	if len(bundle.errs) > 0 {
+		var buf bytes.Buffer
+		for _, e := range bundle.errs {
+			fmt.Fprintln(&buf, e)
+		}
+		if err := bundle.writeFile(supportBundleErrorsFile, buf.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := bundle.tw.Close(); err != nil {
+		return errors.Wrap(err, "failed to close the support bundle tarball")
+	}
+	return errors.Wrap(gw.Close(), "failed to close the support bundle tarball")
+}
+
+// crdVersions summarizes the versions of a CRD.
+type crdVersions struct {
+	Name           string   `json:"name"`
+	ServedVersions []string `json:"servedVersions"`
+	StorageVersion string   `json:"storageVersion"`
+	StoredVersions []string `json:"storedVersions"`
+}
+
+// collectCRDsAndObjects collects the versions of the CRDs installed by clusterctl, and all the objects of those types.
+func (s *supportBundle) collectCRDsAndObjects(ctx context.Context, c client.Client, bundle *supportBundleWriter, namespaces []string) error {
+	crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := c.List(ctx, crdList, client.HasLabels{clusterctlv1.ClusterctlLabelName}); err != nil {
+		bundle.recordError(errors.Wrap(err, "failed to list CRDs"))
+		return nil
+	}
+	sort.Slice(crdList.Items, func(i, j int) bool {
+		return crdList.Items[i].Name < crdList.Items[j].Name
+	})
+
+	versions := make([]crdVersions, 0, len(crdList.Items))
+	for i := range crdList.Items {
+		crd := &crdList.Items[i]
+
+		v := crdVersions{
+			Name:           crd.Name,
+			StoredVersions: crd.Status.StoredVersions,
+		}
+		for _, version := range crd.Spec.Versions {
+			if version.Served {
+				v.ServedVersions = append(v.ServedVersions, version.Name)
+			}
+			if version.Storage {
+				v.StorageVersion = version.Name
+			}
+		}
+		versions = append(versions, v)
+
+		if v.StorageVersion == "" {
+			continue
+		}
+
+		crdNamespaces := namespaces
+		if crd.Spec.Scope == apiextensionsv1.ClusterScoped {
+			crdNamespaces = []string{""}
+		}
+		for _, namespace := range crdNamespaces {
+			list := &unstructured.UnstructuredList{}
+			list.SetAPIVersion(crd.Spec.Group + "/" + v.StorageVersion)
+			list.SetKind(crd.Spec.Names.Kind + "List")
+			if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+				bundle.recordError(errors.Wrapf(err, "failed to list %s", crd.Spec.Names.Kind))
+				continue
+			}
+			for j := range list.Items {
+				obj := &list.Items[j]
+				sanitize(obj)
+				if err := bundle.writeYAML(objectPath("resources", obj.GetNamespace(), crd.Spec.Names.Kind, obj.GetName()), obj.Object); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return bundle.writeYAML("crds.yaml", versions)
+}
+
+// collectClusterSecrets collects the secrets linked to a Cluster, e.g. the kubeconfig or the certificates, with their values redacted.
+func (s *supportBundle) collectClusterSecrets(ctx context.Context, c client.Client, bundle *supportBundleWriter, namespaces []string) error {
+	for _, namespace := range namespaces {
+		secrets := &corev1.SecretList{}
+		if err := c.List(ctx, secrets, client.InNamespace(namespace), client.HasLabels{clusterv1.ClusterLabelName}); err != nil {
+			bundle.recordError(errors.Wrapf(err, "failed to list secrets in namespace %q", namespace))
+			continue
+		}
+		for i := range secrets.Items {
+			if err := s.writeTypedObject(bundle, &secrets.Items[i], "Secret"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// collectWebhookConfigurations collects the webhook configurations installed by clusterctl.
+func (s *supportBundle) collectWebhookConfigurations(ctx context.Context, c client.Client, bundle *supportBundleWriter) error {
+	validating := &admissionregistrationv1.ValidatingWebhookConfigurationList{}
+	if err := c.List(ctx, validating, client.HasLabels{clusterctlv1.ClusterctlLabelName}); err != nil {
+		bundle.recordError(errors.Wrap(err, "failed to list ValidatingWebhookConfigurations"))
+	}
+	for i := range validating.Items {
+		obj := &validating.Items[i]
+		if err := s.writeTypedObject(bundle, obj, "ValidatingWebhookConfiguration"); err != nil {
+			return err
+		}
+	}
+
+	mutating := &admissionregistrationv1.MutatingWebhookConfigurationList{}
+	if err := c.List(ctx, mutating, client.HasLabels{clusterctlv1.ClusterctlLabelName}); err != nil {
+		bundle.recordError(errors.Wrap(err, "failed to list MutatingWebhookConfigurations"))
+	}
+	for i := range mutating.Items {
+		obj := &mutating.Items[i]
+		if err := s.writeTypedObject(bundle, obj, "MutatingWebhookConfiguration"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// collectProvidersAndLogs collects the provider inventory and the logs of the provider controllers.
+func (s *supportBundle) collectProvidersAndLogs(ctx context.Context, proxy cluster.Proxy, c client.Client, bundle *supportBundleWriter, tailLines int64) error {
+	providers := &clusterctlv1.ProviderList{}
+	if err := c.List(ctx, providers); err != nil {
+		bundle.recordError(errors.Wrap(err, "failed to list providers"))
+		return nil
+	}
+	for i := range providers.Items {
+		if err := s.writeTypedObject(bundle, &providers.Items[i], "Provider"); err != nil {
+			return err
+		}
+	}
+
+	providerNamespaces := sets.NewString()
+	for _, p := range providers.Items {
+		providerNamespaces.Insert(p.Namespace)
+	}
+
+	for _, namespace := range providerNamespaces.List() {
+		pods := &corev1.PodList{}
+		if err := c.List(ctx, pods, client.InNamespace(namespace), client.HasLabels{clusterv1.ProviderLabelName}); err != nil {
+			bundle.recordError(errors.Wrapf(err, "failed to list pods in namespace %q", namespace))
+			continue
+		}
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if err := s.writeTypedObject(bundle, pod, "Pod"); err != nil {
+				return err
+			}
+			for _, container := range pod.Spec.Containers {
+				logs, err := s.getPodLogs(ctx, proxy, pod, container.Name, tailLines)
+				if err != nil {
+					bundle.recordError(errors.Wrapf(err, "failed to get logs for container %q of pod %s/%s", container.Name, pod.Namespace, pod.Name))
+					continue
+				}
+				if err := bundle.writeFile(path.Join("logs", pod.Namespace, pod.Name, container.Name+".log"), logs); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// collectEvents collects the events in the given namespaces.
+func (s *supportBundle) collectEvents(ctx context.Context, c client.Client, bundle *supportBundleWriter, namespaces []string) error {
+	for _, namespace := range namespaces {
+		events := &corev1.EventList{}
+		if err := c.List(ctx, events, client.InNamespace(namespace)); err != nil {
+			bundle.recordError(errors.Wrapf(err, "failed to list events in namespace %q", namespace))
+			continue
+		}
+		for i := range events.Items {
+			if err := s.writeTypedObject(bundle, &events.Items[i], "Event"); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeTypedObject sanitizes and writes a typed object into the support bundle.
+func (s *supportBundle) writeTypedObject(bundle *supportBundleWriter, obj client.Object, kind string) error {
+	u := &unstructured.Unstructured{}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		bundle.recordError(errors.Wrapf(err, "failed to convert %s %s/%s", kind, obj.GetNamespace(), obj.GetName()))
+		return nil
+	}
+	u.SetUnstructuredContent(content)
+	u.SetKind(kind)
+	sanitize(u)
+	return bundle.writeYAML(objectPath("resources", u.GetNamespace(), kind, u.GetName()), u.Object)
+}
+
+// sanitize removes noisy or sensitive information from an object before adding it to the support bundle.
+func sanitize(obj *unstructured.Unstructured) {
+	obj.SetManagedFields(nil)
+
+	// The last applied configuration might contain sensitive information, e.g. the data of a Secret.
+	annotations := obj.GetAnnotations()
+	delete(annotations, corev1.LastAppliedConfigAnnotation)
+	obj.SetAnnotations(annotations)
+
+	redactSensitiveValues(obj.Object)
+
+	if obj.GetKind() != "Secret" {
+		return
+	}
+	for _, field := range []string{"data", "stringData"} {
+		data, ok, _ := unstructured.NestedMap(obj.Object, field)
+		if !ok {
+			continue
+		}
+		for k := range data {
+			data[k] = redactedValue
+		}
+		_ = unstructured.SetNestedMap(obj.Object, data, field)
+	}
+}
+
+// redactSensitiveValues recursively redacts the values of the sensitive fields, and the strings embedding a kubeconfig or
+// a private key, in the given object content.
+func redactSensitiveValues(content map[string]interface{}) {
+	for k, v := range content {
+		if v != nil && sensitiveFields.Has(k) {
+			content[k] = redactedValue
+			continue
+		}
+		content[k] = redactSensitiveValue(v)
+	}
+}
+
+func redactSensitiveValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		redactSensitiveValues(value)
+	case []interface{}:
+		for i := range value {
+			value[i] = redactSensitiveValue(value[i])
+		}
+	case string:
+		for _, marker := range sensitiveContentMarkers {
+			if strings.Contains(value, marker) {
+				return redactedValue
+			}
+		}
+	}
+	return v
+}
+
+// objectPath returns the path of an object in the support bundle.
+func objectPath(dir, namespace, kind, name string) string {
+	if namespace == "" {
+		return path.Join(dir, "cluster-scoped", kind, name+".yaml")
+	}
+	return path.Join(dir, namespace, kind, name+".yaml")
+}
+
+// getPodLogs returns the logs of a container of a Pod using the Kubernetes API.
+func getPodLogs(ctx context.Context, proxy cluster.Proxy, pod *corev1.Pod, container string, tailLines int64) ([]byte, error) {
+	config, err := proxy.GetConfig()
+	if err != nil {
+		return nil, err
+	}
+	cs, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the Kubernetes client set")
+	}
+
+	logOptions := &corev1.PodLogOptions{
+		Container: container,
+	}
+	if tailLines > 0 {
+		logOptions.TailLines = &tailLines
+	}
+	stream, err := cs.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOptions).Stream(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+
+	return ioutil.ReadAll(stream)
+}
@@ -0,0 +1,257 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alpha
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func Test_supportBundle_Collect(t *testing.T) {
+	g := NewWithT(t)
+
+	objs := []client.Object{
+		test.FakeCustomResourceDefinition(clusterv1.GroupVersion.Group, "Cluster", "v1alpha4"),
+		&clusterv1.Cluster{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Cluster",
+				APIVersion: clusterv1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster1",
+				Namespace: "ns1",
+				Annotations: map[string]string{
+					corev1.LastAppliedConfigAnnotation: "{}",
+				},
+			},
+		},
+		&clusterv1.Cluster{
+			TypeMeta: metav1.TypeMeta{
+				Kind:       "Cluster",
+				APIVersion: clusterv1.GroupVersion.String(),
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster2",
+				Namespace: "ns2",
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster1-kubeconfig",
+				Namespace: "ns1",
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName: "cluster1",
+				},
+			},
+			Data: map[string][]byte{
+				"value": []byte("super-secret"),
+			},
+		},
+		&clusterctlv1.Provider{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster-api",
+				Namespace: "capi-system",
+			},
+			ProviderName: "cluster-api",
+			Type:         string(clusterctlv1.CoreProviderType),
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "capi-controller-manager",
+				Namespace: "capi-system",
+				Labels: map[string]string{
+					clusterv1.ProviderLabelName: "cluster-api",
+				},
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "manager"}},
+			},
+		},
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "cluster1.event",
+				Namespace: "ns1",
+			},
+			Reason: "Provisioned",
+		},
+	}
+
+	s := &supportBundle{
+		getPodLogs: func(_ context.Context, _ cluster.Proxy, pod *corev1.Pod, container string, tailLines int64) ([]byte, error) {
+			if container != "manager" {
+				return nil, errors.New("container not found")
+			}
+			return []byte("controller started\n"), nil
+		},
+	}
+
+	var buf bytes.Buffer
+	err := s.Collect(test.NewFakeProxy().WithObjs(objs...), &buf, SupportBundleOptions{Namespaces: []string{"ns1"}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	files := readTarball(g, &buf)
+	g.Expect(files).To(HaveKey("crds.yaml"))
+	g.Expect(files["crds.yaml"]).To(ContainSubstring("storageVersion: v1alpha4"))
+
+	g.Expect(files).To(HaveKey("resources/ns1/Cluster/cluster1.yaml"))
+	g.Expect(files["resources/ns1/Cluster/cluster1.yaml"]).NotTo(ContainSubstring(corev1.LastAppliedConfigAnnotation))
+	g.Expect(files).NotTo(HaveKey("resources/ns2/Cluster/cluster2.yaml"), "objects outside of the requested namespaces should not be collected")
+
+	g.Expect(files).To(HaveKey("resources/ns1/Secret/cluster1-kubeconfig.yaml"))
+	g.Expect(files["resources/ns1/Secret/cluster1-kubeconfig.yaml"]).To(ContainSubstring(redactedValue))
+	g.Expect(files["resources/ns1/Secret/cluster1-kubeconfig.yaml"]).NotTo(ContainSubstring("c3VwZXItc2VjcmV0"))
+
+	g.Expect(files).To(HaveKey("resources/capi-system/Provider/cluster-api.yaml"))
+	g.Expect(files).To(HaveKey("resources/capi-system/Pod/capi-controller-manager.yaml"))
+	g.Expect(files).To(HaveKeyWithValue("logs/capi-system/capi-controller-manager/manager.log", "controller started\n"))
+	g.Expect(files).To(HaveKey("resources/ns1/Event/cluster1.event.yaml"))
+	g.Expect(files).NotTo(HaveKey(supportBundleErrorsFile))
+}
+
+func Test_sanitize(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "Secret",
+			"apiVersion": "v1",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "bar",
+				"annotations": map[string]interface{}{
+					corev1.LastAppliedConfigAnnotation: "{\"data\":{\"value\":\"c2VjcmV0\"}}",
+					"keep":                             "me",
+				},
+				"managedFields": []interface{}{
+					map[string]interface{}{"manager": "kubectl"},
+				},
+			},
+			"data": map[string]interface{}{
+				"value": "c2VjcmV0",
+			},
+			"stringData": map[string]interface{}{
+				"other": "secret",
+			},
+		},
+	}
+
+	sanitize(obj)
+
+	g.Expect(obj.GetManagedFields()).To(BeEmpty())
+	g.Expect(obj.GetAnnotations()).To(Equal(map[string]string{"keep": "me"}))
+	g.Expect(obj.Object["data"]).To(Equal(map[string]interface{}{"value": redactedValue}))
+	g.Expect(obj.Object["stringData"]).To(Equal(map[string]interface{}{"other": redactedValue}))
+}
+
+func Test_sanitize_redactsSensitiveValuesInAnyKind(t *testing.T) {
+	g := NewWithT(t)
+
+	obj := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "KubeadmConfig",
+			"apiVersion": "bootstrap.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "foo",
+				"namespace": "bar",
+				"annotations": map[string]interface{}{
+					"kubeconfig-copy": "users:\n- user:\n    client-key-data: c2VjcmV0\n",
+				},
+			},
+			"spec": map[string]interface{}{
+				"files": []interface{}{
+					map[string]interface{}{
+						"path":    "/etc/kubernetes/admin.conf",
+						"content": "apiVersion: v1\nkind: Config\n",
+					},
+				},
+				"users": []interface{}{
+					map[string]interface{}{
+						"name":   "capi",
+						"passwd": "secret",
+					},
+				},
+				"joinConfiguration": map[string]interface{}{
+					"discovery": map[string]interface{}{
+						"bootstrapToken": map[string]interface{}{
+							"token":             "abcdef.0123456789abcdef",
+							"apiServerEndpoint": "10.0.0.1:6443",
+						},
+					},
+				},
+			},
+			"status": map[string]interface{}{
+				"ready":         true,
+				"bootstrapData": "I2Nsb3VkLWNvbmZpZw==",
+			},
+		},
+	}
+
+	sanitize(obj)
+
+	g.Expect(obj.GetAnnotations()).To(Equal(map[string]string{"kubeconfig-copy": redactedValue}))
+
+	file := obj.Object["spec"].(map[string]interface{})["files"].([]interface{})[0].(map[string]interface{})
+	g.Expect(file["path"]).To(Equal("/etc/kubernetes/admin.conf"))
+	g.Expect(file["content"]).To(Equal(redactedValue))
+
+	user := obj.Object["spec"].(map[string]interface{})["users"].([]interface{})[0].(map[string]interface{})
+	g.Expect(user["name"]).To(Equal("capi"))
+	g.Expect(user["passwd"]).To(Equal(redactedValue))
+
+	token, _, _ := unstructured.NestedString(obj.Object, "spec", "joinConfiguration", "discovery", "bootstrapToken", "token")
+	g.Expect(token).To(Equal(redactedValue))
+	endpoint, _, _ := unstructured.NestedString(obj.Object, "spec", "joinConfiguration", "discovery", "bootstrapToken", "apiServerEndpoint")
+	g.Expect(endpoint).To(Equal("10.0.0.1:6443"))
+
+	g.Expect(obj.Object["status"]).To(Equal(map[string]interface{}{"ready": true, "bootstrapData": redactedValue}))
+}
+
+func readTarball(g *WithT, r io.Reader) map[string]string {
+	gr, err := gzip.NewReader(r)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	files := map[string]string{}
+	tr := tar.NewReader(gr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		g.Expect(err).NotTo(HaveOccurred())
+
+		data, err := ioutil.ReadAll(tr)
+		g.Expect(err).NotTo(HaveOccurred())
+		files[header.Name] = string(data)
+	}
+	return files
+}
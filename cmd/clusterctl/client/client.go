@@ -21,6 +21,7 @@ import (
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/alpha"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/registry"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/tree"
 )
@@ -39,6 +40,10 @@ type Client interface {
 	// InitImages returns the list of images required for executing the init command.
 	InitImages(options InitOptions) ([]string, error)
 
+	// ValidateImages checks that each of the given images can be pulled from its registry, e.g. the images returned by
+	// InitImages after applying the image overrides.
+	ValidateImages(images []string) error
+
 	// GetClusterTemplate returns a workload cluster template.
 	GetClusterTemplate(options GetClusterTemplateOptions) (Template, error)
 
@@ -58,6 +63,11 @@ type Client interface {
 	//   - Upgrade to the latest version in the the v1alpha3 series: ....
 	PlanUpgrade(options PlanUpgradeOptions) ([]UpgradePlan, error)
 
+	// GetProviderInventory returns the providers installed in the management cluster, with their version, namespace
+	// and watched namespace, the API Version of Cluster API (contract) supported by their version and the versions
+	// available for upgrade, i.e. the data behind the upgrade plans.
+	GetProviderInventory(options GetProviderInventoryOptions) ([]InstalledProvider, error)
+
 	// PlanCertManagerUpgrade returns a CertManagerUpgradePlan.
 	PlanCertManagerUpgrade(options PlanUpgradeOptions) (CertManagerUpgradePlan, error)
 
@@ -71,6 +81,10 @@ type Client interface {
 	// DescribeCluster returns the object tree representing the status of a Cluster API cluster.
 	DescribeCluster(options DescribeClusterOptions) (*tree.ObjectTree, error)
 
+	// DescribeObjectGraph returns the graph of the Cluster API objects existing in the management cluster,
+	// including ownership relations between objects.
+	DescribeObjectGraph(options DescribeObjectGraphOptions) (*ObjectGraph, error)
+
 	// Interface for alpha features in clusterctl
 	AlphaClient
 }
@@ -83,6 +97,10 @@ type AlphaClient interface {
 	RolloutPause(options RolloutOptions) error
 	// RolloutResume provides rollout resume of paused cluster-api resources
 	RolloutResume(options RolloutOptions) error
+	// SupportBundle collects the information required for troubleshooting a management cluster into a tarball.
+	SupportBundle(options SupportBundleOptions) error
+	// GenerateMachineDeployment returns the YAML of a new MachineDeployment, with its templates, cloned from an existing one.
+	GenerateMachineDeployment(options GenerateMachineDeploymentOptions) ([]byte, error)
 }
 
 // YamlPrinter exposes methods that prints the processed template and
@@ -91,6 +109,10 @@ type YamlPrinter interface {
 	// Variables required by the template.
 	Variables() []string
 
+	// VariableMap returns the variables required by the template, with their default values;
+	// variables without a default value are mapped to nil.
+	VariableMap() map[string]*string
+
 	// Yaml returns yaml defining all the cluster template objects as a byte array.
 	Yaml() ([]byte, error)
 }
@@ -100,6 +122,7 @@ type clusterctlClient struct {
 	configClient            config.Client
 	repositoryClientFactory RepositoryClientFactory
 	clusterClientFactory    ClusterClientFactory
+	registryClient          registry.Client
 	alphaClient             alpha.Client
 }
 
@@ -148,6 +171,13 @@ func InjectClusterClientFactory(factory ClusterClientFactory) Option {
 	}
 }
 
+// InjectRegistryClient allows to override the default client used for checking images against their registries.
+func InjectRegistryClient(registryClient registry.Client) Option {
+	return func(c *clusterctlClient) {
+		c.registryClient = registryClient
+	}
+}
+
 // New returns a configClient.
 func New(path string, options ...Option) (Client, error) {
 	return newClusterctlClient(path, options...)
@@ -179,6 +209,11 @@ func newClusterctlClient(path string, options ...Option) (*clusterctlClient, err
 		client.clusterClientFactory = defaultClusterFactory(client.configClient)
 	}
 
+	// if there is an injected registryClient, use it, otherwise use a default one.
+	if client.registryClient == nil {
+		client.registryClient = registry.New()
+	}
+
 	// if there is an injected alphaClient, use it, otherwise use a default one.
 	if client.alphaClient == nil {
 		c := alpha.New()
@@ -95,6 +95,10 @@ func (f fakeClient) InitImages(options InitOptions) ([]string, error) {
 	return f.internalClient.InitImages(options)
 }
 
+func (f fakeClient) ValidateImages(images []string) error {
+	return f.internalClient.ValidateImages(images)
+}
+
 func (f fakeClient) Delete(options DeleteOptions) error {
 	return f.internalClient.Delete(options)
 }
@@ -107,6 +111,10 @@ func (f fakeClient) PlanUpgrade(options PlanUpgradeOptions) ([]UpgradePlan, erro
 	return f.internalClient.PlanUpgrade(options)
 }
 
+func (f fakeClient) GetProviderInventory(options GetProviderInventoryOptions) ([]InstalledProvider, error) {
+	return f.internalClient.GetProviderInventory(options)
+}
+
 func (f fakeClient) PlanCertManagerUpgrade(options PlanUpgradeOptions) (CertManagerUpgradePlan, error) {
 	return f.internalClient.PlanCertManagerUpgrade(options)
 }
@@ -127,6 +135,10 @@ func (f fakeClient) DescribeCluster(options DescribeClusterOptions) (*tree.Objec
 	return f.internalClient.DescribeCluster(options)
 }
 
+func (f fakeClient) DescribeObjectGraph(options DescribeObjectGraphOptions) (*ObjectGraph, error) {
+	return f.internalClient.DescribeObjectGraph(options)
+}
+
 func (f fakeClient) RolloutPause(options RolloutOptions) error {
 	return f.internalClient.RolloutPause(options)
 }
@@ -135,6 +147,14 @@ func (f fakeClient) RolloutResume(options RolloutOptions) error {
 	return f.internalClient.RolloutResume(options)
 }
 
+func (f fakeClient) SupportBundle(options SupportBundleOptions) error {
+	return f.internalClient.SupportBundle(options)
+}
+
+func (f fakeClient) GenerateMachineDeployment(options GenerateMachineDeploymentOptions) ([]byte, error) {
+	return f.internalClient.GenerateMachineDeployment(options)
+}
+
 // newFakeClient returns a clusterctl client that allows to execute tests on a set of fake config, fake repositories and fake clusters.
 // you can use WithCluster and WithRepository to prepare for the test case.
 func newFakeClient(configClient config.Client) *fakeClient {
@@ -235,6 +255,10 @@ func (p *fakeCertManagerClient) EnsureInstalled() error {
 	return nil
 }
 
+func (p *fakeCertManagerClient) CheckAvailable() error {
+	return nil
+}
+
 func (p *fakeCertManagerClient) EnsureLatestVersion() error {
 	return nil
 }
@@ -294,6 +318,10 @@ func (f *fakeClusterClient) ObjectMover() cluster.ObjectMover {
 	return f.fakeObjectMover
 }
 
+func (f *fakeClusterClient) ObjectGraphDescriber() cluster.ObjectGraphDescriber {
+	return f.internalclient.ObjectGraphDescriber()
+}
+
 func (f *fakeClusterClient) ProviderUpgrader() cluster.ProviderUpgrader {
 	return f.internalclient.ProviderUpgrader()
 }
@@ -364,6 +392,10 @@ func (f fakeConfigClient) ImageMeta() config.ImageMetaClient {
 	return f.internalclient.ImageMeta()
 }
 
+func (f fakeConfigClient) Move() config.MoveClient {
+	return f.internalclient.Move()
+}
+
 func (f *fakeConfigClient) WithVar(key, value string) *fakeConfigClient {
 	f.fakeReader.WithVar(key, value)
 	return f
@@ -540,3 +572,11 @@ func (f *fakeComponentClient) Get(options repository.ComponentsOptions) (reposit
 		},
 	)
 }
+
+func (f *fakeComponentClient) Describe(options repository.ComponentsOptions) (*repository.ComponentsDescription, error) {
+	c, err := repository.New(f.provider, f.configClient, repository.InjectRepository(f.fakeRepository))
+	if err != nil {
+		return nil, err
+	}
+	return c.Components().(repository.ComponentsDescriber).Describe(options)
+}
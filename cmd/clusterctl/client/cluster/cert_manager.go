@@ -65,6 +65,10 @@ type CertManagerClient interface {
 	// This is required to install a new provider.
 	EnsureInstalled() error
 
+	// CheckAvailable checks cert-manager is running and its API is available, without installing it.
+	// This is required to move objects to a target management cluster.
+	CheckAvailable() error
+
 	// EnsureLatestVersion checks the cert-manager version currently installed, and if it is
 	// older than the version currently embedded in clusterctl, upgrades it.
 	EnsureLatestVersion() error
@@ -168,6 +172,14 @@ func (cm *certManagerClient) EnsureInstalled() error {
 	return cm.install()
 }
 
+// CheckAvailable checks cert-manager is running and its API is available, without installing it.
+func (cm *certManagerClient) CheckAvailable() error {
+	if err := cm.waitForAPIReady(ctx, false); err != nil {
+		return errors.Wrap(err, "cert-manager is not available")
+	}
+	return nil
+}
+
 func (cm *certManagerClient) install() error {
 	// Gets the cert-manager objects from the embedded assets.
 	objs, err := cm.getManifestObjs()
@@ -368,8 +380,8 @@ func (cm *certManagerClient) getManifestObjs() ([]unstructured.Unstructured, err
 		return nil, errors.Wrap(err, "failed to parse yaml for cert-manager manifest")
 	}
 
-	objs, err = util.FixImages(objs, func(image string) (string, error) {
-		return cm.configClient.ImageMeta().AlterImage(certManagerImageComponent, image)
+	objs, err = util.FixImages(objs, func(container, image string) (string, error) {
+		return cm.configClient.ImageMeta().AlterContainerImage(certManagerImageComponent, container, image)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to apply image override to the cert-manager manifest")
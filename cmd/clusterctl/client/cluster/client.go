@@ -81,6 +81,10 @@ type Client interface {
 	// from one management cluster to another management cluster.
 	ObjectMover() ObjectMover
 
+	// ObjectGraphDescriber returns an ObjectGraphDescriber that supports discovering the graph of Cluster API objects
+	// existing in the management cluster.
+	ObjectGraphDescriber() ObjectGraphDescriber
+
 	// ProviderUpgrader returns a ProviderUpgrader that supports upgrading Cluster API providers.
 	ProviderUpgrader() ProviderUpgrader
 
@@ -134,11 +138,15 @@ func (c *clusterClient) ProviderInstaller() ProviderInstaller {
 }
 
 func (c *clusterClient) ObjectMover() ObjectMover {
-	return newObjectMover(c.proxy, c.ProviderInventory())
+	return newObjectMover(c.proxy, c.ProviderInventory(), c.configClient)
+}
+
+func (c *clusterClient) ObjectGraphDescriber() ObjectGraphDescriber {
+	return newObjectGraphDescriber(c.proxy)
 }
 
 func (c *clusterClient) ProviderUpgrader() ProviderUpgrader {
-	return newProviderUpgrader(c.configClient, c.repositoryClientFactory, c.ProviderInventory(), c.ProviderComponents())
+	return newProviderUpgrader(c.proxy, c.configClient, c.repositoryClientFactory, c.ProviderInventory(), c.ProviderComponents(), c.pollImmediateWaiter)
 }
 
 func (c *clusterClient) Template() TemplateClient {
@@ -228,6 +236,9 @@ type Proxy interface {
 	// ValidateKubernetesVersion returns an error if management cluster version less than minimumKubernetesVersion
 	ValidateKubernetesVersion() error
 
+	// GetServerVersion returns the Kubernetes version of the management cluster.
+	GetServerVersion() (string, error)
+
 	// NewClient returns a new controller runtime Client object for working on the management cluster
 	NewClient() (client.Client, error)
 
@@ -18,39 +18,52 @@ package cluster
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ObjectMover defines methods for moving Cluster API objects to another management cluster.
 type ObjectMover interface {
 	// Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster.
-	Move(namespace string, toCluster Client, dryRun bool) error
+	// If clusterSelector is not empty, only the Clusters matching it and their dependent objects are moved.
+	Move(namespace string, toCluster Client, dryRun bool, clusterSelector labels.Selector) error
 }
 
 // objectMover implements the ObjectMover interface.
 type objectMover struct {
 	fromProxy             Proxy
 	fromProviderInventory InventoryClient
+	configClient          config.Client
 	dryRun                bool
 }
 
 // ensure objectMover implements the ObjectMover interface.
 var _ ObjectMover = &objectMover{}
 
-func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) error {
+func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool, clusterSelector labels.Selector) (reterr error) {
 	log := logf.Log
+	progress := logf.NewProgress("move", 3)
+	defer func() {
+		progress.Done(reterr)
+	}()
+
 	log.Info("Performing move...")
 	o.dryRun = dryRun
 	if o.dryRun {
@@ -59,21 +72,26 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 		log.Info("********************************************************")
 	}
 
+	progress.Step("Discovering Cluster API objects")
 	objectGraph := newObjectGraph(o.fromProxy)
 
-	// checks that all the required providers in place in the target cluster.
-	if !o.dryRun {
-		if err := o.checkTargetProviders(namespace, toCluster.ProviderInventory()); err != nil {
-			return err
-		}
-	}
-
 	// Gets all the types defines by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
 	err := objectGraph.getDiscoveryTypes()
 	if err != nil {
 		return err
 	}
 
+	// Adds the types of the additional objects to be moved, as defined in the clusterctl configuration, if any.
+	if o.configClient != nil {
+		additionalObjects, err := o.configClient.Move().AdditionalObjects()
+		if err != nil {
+			return err
+		}
+		if err := objectGraph.addAdditionalObjectTypes(additionalObjects); err != nil {
+			return err
+		}
+	}
+
 	// Discovery the object graph for the selected types:
 	// - Nodes are defined the Kubernetes objects (Clusters, Machines etc.) identified during the discovery process.
 	// - Edges are derived by the OwnerReferences between nodes.
@@ -81,10 +99,18 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 		return err
 	}
 
+	// Restrict the object graph to the Clusters matching the selector, if any.
+	if clusterSelector != nil && !clusterSelector.Empty() {
+		if err := objectGraph.filterClusters(clusterSelector); err != nil {
+			return err
+		}
+	}
+
 	// Checks if Cluster API has already completed the provisioning of the infrastructure for the objects involved in the move operation.
 	// This is required because if the infrastructure is provisioned, then we can reasonably assume that the objects we are moving are
 	// not currently waiting for long-running reconciliation loops, and so we can safely rely on the pause field on the Cluster object
 	// for blocking any further object reconciliation on the source objects.
+	progress.Step("Checking the clusters are ready for move")
 	if err := o.checkProvisioningCompleted(objectGraph); err != nil {
 		return err
 	}
@@ -92,7 +118,13 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 	// Check whether nodes are not included in GVK considered for move
 	objectGraph.checkVirtualNode()
 
+	// Checks that the target cluster is ready to receive the objects before mutating anything.
+	if err := o.checkTargetCluster(namespace, objectGraph, toCluster); err != nil {
+		return err
+	}
+
 	// Move the objects to the target cluster.
+	progress.Step("Moving Cluster API objects")
 	var proxy Proxy
 	if !o.dryRun {
 		proxy = toCluster.Proxy()
@@ -105,10 +137,11 @@ func (o *objectMover) Move(namespace string, toCluster Client, dryRun bool) erro
 	return nil
 }
 
-func newObjectMover(fromProxy Proxy, fromProviderInventory InventoryClient) *objectMover {
+func newObjectMover(fromProxy Proxy, fromProviderInventory InventoryClient, configClient config.Client) *objectMover {
 	return &objectMover{
 		fromProxy:             fromProxy,
 		fromProviderInventory: fromProviderInventory,
+		configClient:          configClient,
 	}
 }
 
@@ -166,6 +199,28 @@ func (o *objectMover) checkProvisioningCompleted(graph *objectGraph) error {
 		}
 	}
 
+	// Checking all the machine pools have the infrastructure ready and a NodeRef for each replica.
+	readMachinePoolsBackoff := newReadBackoff()
+	machinePools := graph.getMachinePools()
+	for i := range machinePools {
+		machinePool := machinePools[i]
+		machinePoolObj := &expv1.MachinePool{}
+		if err := retryWithExponentialBackoff(readMachinePoolsBackoff, func() error {
+			return getMachinePoolObj(o.fromProxy, machinePool, machinePoolObj)
+		}); err != nil {
+			return err
+		}
+
+		if !machinePoolObj.Status.InfrastructureReady {
+			errList = append(errList, errors.Errorf("cannot start the move operation while %q %s/%s is still provisioning the infrastructure", machinePoolObj.GroupVersionKind(), machinePoolObj.GetNamespace(), machinePoolObj.GetName()))
+			continue
+		}
+
+		if machinePoolObj.Spec.Replicas != nil && int32(len(machinePoolObj.Status.NodeRefs)) < *machinePoolObj.Spec.Replicas {
+			errList = append(errList, errors.Errorf("cannot start the move operation while %q %s/%s is still provisioning the nodes", machinePoolObj.GroupVersionKind(), machinePoolObj.GetNamespace(), machinePoolObj.GetName()))
+		}
+	}
+
 	return kerrors.NewAggregate(errList)
 }
 
@@ -205,6 +260,24 @@ func getMachineObj(proxy Proxy, machine *node, machineObj *clusterv1.Machine) er
 	return nil
 }
 
+// getMachinePoolObj retrieves the the machinePoolObj corresponding to a node with type MachinePool.
+func getMachinePoolObj(proxy Proxy, machinePool *node, machinePoolObj *expv1.MachinePool) error {
+	c, err := proxy.NewClient()
+	if err != nil {
+		return err
+	}
+	machinePoolObjKey := client.ObjectKey{
+		Namespace: machinePool.identity.Namespace,
+		Name:      machinePool.identity.Name,
+	}
+
+	if err := c.Get(ctx, machinePoolObjKey, machinePoolObj); err != nil {
+		return errors.Wrapf(err, "error reading %q %s/%s",
+			machinePoolObj.GroupVersionKind(), machinePoolObj.GetNamespace(), machinePoolObj.GetName())
+	}
+	return nil
+}
+
 // Move moves all the Cluster API objects existing in a namespace (or from all the namespaces if empty) to a target management cluster
 func (o *objectMover) move(graph *objectGraph, toProxy Proxy) error {
 	log := logf.Log
@@ -304,9 +377,10 @@ func getMoveSequence(graph *objectGraph) *moveSequence {
 
 			// Check if all the ownerReferences are already included in the move sequence; if yes, add the node to move group,
 			// otherwise skip it (the node will be re-processed in the next group).
+			// Owners not existing in the source cluster are not moved, so they are ignored.
 			ownersInPlace := true
 			for owner := range n.owners {
-				if !moveSequence.hasNode(owner) {
+				if !owner.virtual && !moveSequence.hasNode(owner) {
 					ownersInPlace = false
 					break
 				}
@@ -540,6 +614,11 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 	if len(nodeToCreate.owners) > 0 {
 		ownerRefs := []metav1.OwnerReference{}
 		for ownerNode := range nodeToCreate.owners {
+			// Skip the owners not existing in the source cluster, e.g. the ClusterResourceSet of an orphaned ClusterResourceSetBinding,
+			// given that they are not moved and an OwnerReference without UID is invalid.
+			if ownerNode.virtual {
+				continue
+			}
 			ownerRef := metav1.OwnerReference{
 				APIVersion: ownerNode.identity.APIVersion,
 				Kind:       ownerNode.identity.Kind,
@@ -584,6 +663,17 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 				existingTargetObj.GroupVersionKind(), existingTargetObj.GetNamespace(), existingTargetObj.GetName())
 		}
 
+		// Shared nodes might have been moved already together with other Clusters; preserve the existing OwnerReferences.
+		if nodeToCreate.shared {
+			ownerRefs := obj.GetOwnerReferences()
+			for _, existingOwnerRef := range existingTargetObj.GetOwnerReferences() {
+				if !hasOwnerReference(ownerRefs, existingOwnerRef) {
+					ownerRefs = append(ownerRefs, existingOwnerRef)
+				}
+			}
+			obj.SetOwnerReferences(ownerRefs)
+		}
+
 		obj.SetUID(existingTargetObj.GetUID())
 		obj.SetResourceVersion(existingTargetObj.GetResourceVersion())
 		if err := cTo.Update(ctx, obj); err != nil {
@@ -598,6 +688,16 @@ func (o *objectMover) createTargetObject(nodeToCreate *node, toProxy Proxy) erro
 	return nil
 }
 
+// hasOwnerReference returns true if the list of OwnerReferences includes one referencing the same owner.
+func hasOwnerReference(ownerRefs []metav1.OwnerReference, ref metav1.OwnerReference) bool {
+	for _, r := range ownerRefs {
+		if r.UID == ref.UID {
+			return true
+		}
+	}
+	return false
+}
+
 // deleteGroup deletes all the Kubernetes objects from the source management cluster corresponding to the object graph nodes in a moveGroup.
 func (o *objectMover) deleteGroup(group moveGroup) error {
 	deleteSourceObjectBackoff := newWriteBackoff()
@@ -610,6 +710,11 @@ func (o *objectMover) deleteGroup(group moveGroup) error {
 			continue
 		}
 
+		// Don't delete nodes used by Clusters not being moved
+		if nodeToDelete.shared {
+			continue
+		}
+
 		// Delete the Kubernetes object corresponding to the current node.
 		// Nb. The operation is wrapped in a retry loop to make move more resilient to unexpected conditions.
 		err := retryWithExponentialBackoff(deleteSourceObjectBackoff, func() error {
@@ -752,3 +857,106 @@ func (o *objectMover) checkTargetProviders(namespace string, toInventory Invento
 
 	return kerrors.NewAggregate(errList)
 }
+
+// checkTargetCluster checks that the target management cluster is ready to receive the objects being moved,
+// reporting the outcome of each check; all the checks are run before returning an error so the user
+// can fix all the problems at once.
+func (o *objectMover) checkTargetCluster(namespace string, graph *objectGraph, toCluster Client) error {
+	if o.dryRun {
+		return nil
+	}
+
+	log := logf.Log
+	log.Info("Checking the target cluster is ready for move")
+
+	checks := []struct {
+		name  string
+		check func() error
+	}{
+		{
+			name:  "Providers",
+			check: func() error { return o.checkTargetProviders(namespace, toCluster.ProviderInventory()) },
+		},
+		{
+			name:  "CustomResourceDefinitions",
+			check: func() error { return o.checkTargetCRDs(graph, toCluster.Proxy()) },
+		},
+		{
+			name: "cert-manager",
+			check: func() error {
+				certManager, err := toCluster.CertManager()
+				if err != nil {
+					return err
+				}
+				return certManager.CheckAvailable()
+			},
+		},
+	}
+
+	errList := []error{}
+	for _, c := range checks {
+		if err := c.check(); err != nil {
+			log.Info("Target cluster check failed", "Check", c.name, "Error", err.Error())
+			errList = append(errList, err)
+			continue
+		}
+		log.Info("Target cluster check passed", "Check", c.name)
+	}
+
+	if len(errList) > 0 {
+		return errors.Wrap(kerrors.NewAggregate(errList), "target cluster is not ready for move")
+	}
+	return nil
+}
+
+// checkTargetCRDs checks that the target cluster has CRDs serving all the kinds and versions of the objects being moved.
+func (o *objectMover) checkTargetCRDs(graph *objectGraph, toProxy Proxy) error {
+	if o.dryRun {
+		return nil
+	}
+
+	crdList := &apiextensionsv1.CustomResourceDefinitionList{}
+	if err := getCRDList(toProxy, crdList); err != nil {
+		return errors.Wrap(err, "failed to get the list of CRDs from the target cluster")
+	}
+
+	// Gets the versions served by the target cluster for each group/kind.
+	targetVersions := map[schema.GroupKind]sets.String{}
+	for _, crd := range crdList.Items {
+		gk := schema.GroupKind{Group: crd.Spec.Group, Kind: crd.Spec.Names.Kind}
+		targetVersions[gk] = sets.NewString()
+		for _, v := range crd.Spec.Versions {
+			// Versions defined in the CRD but no longer served can't be used for creating the moved objects.
+			if !v.Served {
+				continue
+			}
+			targetVersions[gk].Insert(v.Name)
+		}
+	}
+
+	// Checks all the kinds and versions of the objects being moved.
+	errList := []error{}
+	checked := sets.NewString()
+	for _, node := range graph.getNodes() {
+		// Skips objects not being moved and core types (e.g. Secrets), which are always available.
+		if node.virtual {
+			continue
+		}
+		gvk := node.identity.GroupVersionKind()
+		if gvk.Group == "" || checked.Has(gvk.String()) {
+			continue
+		}
+		checked.Insert(gvk.String())
+
+		versions, ok := targetVersions[gvk.GroupKind()]
+		if !ok {
+			errList = append(errList, errors.Errorf("CRD for %s not found in the target cluster", gvk.GroupKind()))
+			continue
+		}
+		if !versions.Has(gvk.Version) {
+			errList = append(errList, errors.Errorf("CRD for %s in the target cluster does not serve version %s (versions: %s)", gvk.GroupKind(), gvk.Version, strings.Join(versions.List(), ", ")))
+		}
+	}
+
+	return kerrors.NewAggregate(errList)
+}
@@ -24,9 +24,13 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -272,9 +276,12 @@ var moveTests = []struct {
 				// owned by Clusters
 				"/v1, Kind=Secret, ns1/cluster1-ca",
 				"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
-				"bootstrap.cluster.x-k8s.io/v1alpha4, Kind=GenericBootstrapConfigTemplate, ns1/mp1",
 				"exp.cluster.x-k8s.io/v1alpha4, Kind=MachinePool, ns1/mp1",
 				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureCluster, ns1/cluster1",
+			},
+			{ //group 3 (objects with ownerReferences in group 1,2)
+				// owned by MachinePools
+				"bootstrap.cluster.x-k8s.io/v1alpha4, Kind=GenericBootstrapConfigTemplate, ns1/mp1",
 				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureMachineTemplate, ns1/mp1",
 			},
 		},
@@ -411,6 +418,49 @@ var moveTests = []struct {
 			},
 		},
 	},
+	{
+		name: "A ClusterResourceSet applied to a cluster with MachinePool",
+		fields: moveTestsFields{
+			objs: func() []client.Object {
+				objs := []client.Object{}
+				objs = append(objs, test.NewFakeCluster("ns1", "cluster1").
+					WithMachinePools(
+						test.NewFakeMachinePool("mp1"),
+					).Objs()...)
+
+				objs = append(objs, test.NewFakeClusterResourceSet("ns1", "crs1").
+					WithSecret("resource-s1").
+					ApplyToCluster(test.SelectClusterObj(objs, "ns1", "cluster1")).
+					Objs()...)
+
+				return objs
+			}(),
+		},
+		wantMoveGroups: [][]string{
+			{ //group 1
+				// Cluster
+				"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1",
+				// ClusterResourceSet
+				"addons.cluster.x-k8s.io/v1alpha4, Kind=ClusterResourceSet, ns1/crs1",
+			},
+			{ //group 2 (objects with ownerReferences in group 1)
+				// owned by Clusters
+				"/v1, Kind=Secret, ns1/cluster1-ca",
+				"/v1, Kind=Secret, ns1/cluster1-kubeconfig",
+				"exp.cluster.x-k8s.io/v1alpha4, Kind=MachinePool, ns1/mp1",
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureCluster, ns1/cluster1",
+				// owned by ClusterResourceSet
+				"/v1, Kind=Secret, ns1/resource-s1",
+				// owned by ClusterResourceSet & Cluster
+				"addons.cluster.x-k8s.io/v1alpha4, Kind=ClusterResourceSetBinding, ns1/cluster1",
+			},
+			{ //group 3 (objects with ownerReferences in group 1,2)
+				// owned by MachinePools
+				"bootstrap.cluster.x-k8s.io/v1alpha4, Kind=GenericBootstrapConfigTemplate, ns1/mp1",
+				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureMachineTemplate, ns1/mp1",
+			},
+		},
+	},
 	{
 		name: "Cluster and global + namespaced external objects with force-move label",
 		fields: moveTestsFields{
@@ -620,6 +670,162 @@ func Test_objectMover_move(t *testing.T) {
 	}
 }
 
+func Test_objectMover_move_withClusterSelector(t *testing.T) {
+	g := NewWithT(t)
+
+	sharedInfrastructureTemplate := test.NewFakeInfrastructureTemplate("shared")
+	objs := []client.Object{
+		sharedInfrastructureTemplate,
+	}
+	objs = append(objs, test.NewFakeCluster("ns1", "cluster1").
+		WithLabels(map[string]string{"env": "prod"}).
+		WithMachineSets(
+			test.NewFakeMachineSet("cluster1-ms1").
+				WithInfrastructureTemplate(sharedInfrastructureTemplate).
+				WithMachines(
+					test.NewFakeMachine("cluster1-m1"),
+				),
+		).Objs()...)
+	objs = append(objs, test.NewFakeCluster("ns1", "cluster2").
+		WithMachineSets(
+			test.NewFakeMachineSet("cluster2-ms1").
+				WithInfrastructureTemplate(sharedInfrastructureTemplate).
+				WithMachines(
+					test.NewFakeMachine("cluster2-m1"),
+				),
+		).Objs()...)
+
+	// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+	graph := getObjectGraphWithObjs(objs)
+
+	// Get all the types to be considered for discovery
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+	// trigger discovery the content of the source cluster, then restrict it to the Clusters matching the selector
+	g.Expect(graph.Discovery("")).To(Succeed())
+	g.Expect(graph.filterClusters(labels.SelectorFromSet(labels.Set{"env": "prod"}))).To(Succeed())
+
+	// gets a fakeProxy to an empty cluster with all the required CRDs
+	toProxy := getFakeProxyWithCRDs()
+
+	// Run move
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+	g.Expect(mover.move(graph, toProxy)).To(Succeed())
+
+	csFrom, err := graph.proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	csTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	exists := func(c client.Client, apiVersion, kind, name string) *unstructured.Unstructured {
+		o := &unstructured.Unstructured{}
+		o.SetAPIVersion(apiVersion)
+		o.SetKind(kind)
+		if err := c.Get(ctx, client.ObjectKey{Namespace: "ns1", Name: name}, o); err != nil {
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			return nil
+		}
+		return o
+	}
+
+	// The selected cluster is moved.
+	g.Expect(exists(csFrom, clusterv1.GroupVersion.String(), "Cluster", "cluster1")).To(BeNil())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Cluster", "cluster1")).NotTo(BeNil())
+	g.Expect(exists(csFrom, clusterv1.GroupVersion.String(), "Machine", "cluster1-m1")).To(BeNil())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Machine", "cluster1-m1")).NotTo(BeNil())
+
+	// The other cluster is not moved.
+	g.Expect(exists(csFrom, clusterv1.GroupVersion.String(), "Cluster", "cluster2")).NotTo(BeNil())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Cluster", "cluster2")).To(BeNil())
+	g.Expect(exists(csFrom, clusterv1.GroupVersion.String(), "Machine", "cluster2-m1")).NotTo(BeNil())
+	g.Expect(exists(csTo, clusterv1.GroupVersion.String(), "Machine", "cluster2-m1")).To(BeNil())
+
+	// The shared object is copied to the target cluster, without OwnerReferences to objects not being moved, and preserved in the source cluster.
+	templateAPIVersion := sharedInfrastructureTemplate.GetObjectKind().GroupVersionKind().GroupVersion().String()
+	g.Expect(exists(csFrom, templateAPIVersion, "GenericInfrastructureMachineTemplate", "shared")).NotTo(BeNil())
+	sharedTo := exists(csTo, templateAPIVersion, "GenericInfrastructureMachineTemplate", "shared")
+	g.Expect(sharedTo).NotTo(BeNil())
+	g.Expect(sharedTo.GetOwnerReferences()).NotTo(BeEmpty())
+	for _, ref := range sharedTo.GetOwnerReferences() {
+		g.Expect(ref.Name).NotTo(HavePrefix("cluster2"))
+	}
+}
+
+func Test_objectMover_move_orphanedClusterResourceSetBinding(t *testing.T) {
+	g := NewWithT(t)
+
+	objs := test.NewFakeCluster("ns1", "cluster1").
+		WithMachinePools(
+			test.NewFakeMachinePool("mp1"),
+		).Objs()
+
+	// A ClusterResourceSetBinding of a ClusterResourceSet which has been deleted, and without the OwnerReference to its Cluster.
+	objs = append(objs, &addonsv1.ClusterResourceSetBinding{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: addonsv1.GroupVersion.String(),
+			Kind:       "ClusterResourceSetBinding",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "ns1",
+			Name:      "cluster1",
+			UID:       "ns1, cluster1-binding",
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: addonsv1.GroupVersion.String(),
+					Kind:       "ClusterResourceSet",
+					Name:       "deleted-crs",
+					UID:        "ns1, deleted-crs",
+				},
+			},
+		},
+	})
+
+	// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+	graph := getObjectGraphWithObjs(objs)
+
+	// Get all the types to be considered for discovery
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+	// trigger discovery the content of the source cluster
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	// The binding is moved together with its Cluster.
+	moveSequence := getMoveSequence(graph)
+	g.Expect(moveSequence.groups).To(HaveLen(3))
+	movedNodes := []string{}
+	for _, node := range moveSequence.groups[1] {
+		movedNodes = append(movedNodes, node.identity.Kind+"/"+node.identity.Name)
+	}
+	g.Expect(movedNodes).To(ContainElement("ClusterResourceSetBinding/cluster1"))
+
+	// gets a fakeProxy to an empty cluster with all the required CRDs
+	toProxy := getFakeProxyWithCRDs()
+
+	// Run move
+	mover := objectMover{
+		fromProxy: graph.proxy,
+	}
+	g.Expect(mover.move(graph, toProxy)).To(Succeed())
+
+	csFrom, err := graph.proxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+	csTo, err := toProxy.NewClient()
+	g.Expect(err).NotTo(HaveOccurred())
+
+	key := client.ObjectKey{Namespace: "ns1", Name: "cluster1"}
+
+	// The binding is deleted from the source cluster, instead of being left behind.
+	err = csFrom.Get(ctx, key, &addonsv1.ClusterResourceSetBinding{})
+	g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+	// The binding is created in the target cluster, without the OwnerReference to the deleted ClusterResourceSet.
+	bindingTo := &addonsv1.ClusterResourceSetBinding{}
+	g.Expect(csTo.Get(ctx, key, bindingTo)).To(Succeed())
+	g.Expect(bindingTo.OwnerReferences).To(BeEmpty())
+}
+
 func Test_objectMover_checkProvisioningCompleted(t *testing.T) {
 	type fields struct {
 		objs []client.Object
@@ -743,6 +949,101 @@ func Test_objectMover_checkProvisioningCompleted(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "Blocks with a MachinePool without InfrastructureReady",
+			fields: fields{
+				objs: []client.Object{
+					&clusterv1.Cluster{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "Cluster",
+							APIVersion: clusterv1.GroupVersion.String(),
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns1",
+							Name:      "cluster1",
+							UID:       "cluster1",
+						},
+						Status: clusterv1.ClusterStatus{
+							InfrastructureReady:     true,
+							ControlPlaneInitialized: true,
+						},
+					},
+					&expv1.MachinePool{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "MachinePool",
+							APIVersion: expv1.GroupVersion.String(),
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns1",
+							Name:      "machinepool1",
+							OwnerReferences: []metav1.OwnerReference{
+								{
+									APIVersion: clusterv1.GroupVersion.String(),
+									Kind:       "Cluster",
+									Name:       "cluster1",
+									UID:        "cluster1",
+								},
+							},
+						},
+						Spec: expv1.MachinePoolSpec{
+							Replicas: pointer.Int32Ptr(2),
+						},
+						Status: expv1.MachinePoolStatus{
+							InfrastructureReady: false,
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "Blocks with a MachinePool without a NodeRef for each replica",
+			fields: fields{
+				objs: []client.Object{
+					&clusterv1.Cluster{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "Cluster",
+							APIVersion: clusterv1.GroupVersion.String(),
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns1",
+							Name:      "cluster1",
+							UID:       "cluster1",
+						},
+						Status: clusterv1.ClusterStatus{
+							InfrastructureReady:     true,
+							ControlPlaneInitialized: true,
+						},
+					},
+					&expv1.MachinePool{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "MachinePool",
+							APIVersion: expv1.GroupVersion.String(),
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns1",
+							Name:      "machinepool1",
+							OwnerReferences: []metav1.OwnerReference{
+								{
+									APIVersion: clusterv1.GroupVersion.String(),
+									Kind:       "Cluster",
+									Name:       "cluster1",
+									UID:        "cluster1",
+								},
+							},
+						},
+						Spec: expv1.MachinePoolSpec{
+							Replicas: pointer.Int32Ptr(2),
+						},
+						Status: expv1.MachinePoolStatus{
+							InfrastructureReady: true,
+							NodeRefs:            []corev1.ObjectReference{{Name: "node1"}},
+						},
+					},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "Pass",
 			fields: fields{
@@ -783,6 +1084,31 @@ func Test_objectMover_checkProvisioningCompleted(t *testing.T) {
 							NodeRef: &corev1.ObjectReference{},
 						},
 					},
+					&expv1.MachinePool{
+						TypeMeta: metav1.TypeMeta{
+							Kind:       "MachinePool",
+							APIVersion: expv1.GroupVersion.String(),
+						},
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "ns1",
+							Name:      "machinepool1",
+							OwnerReferences: []metav1.OwnerReference{
+								{
+									APIVersion: clusterv1.GroupVersion.String(),
+									Kind:       "Cluster",
+									Name:       "cluster1",
+									UID:        "cluster1",
+								},
+							},
+						},
+						Spec: expv1.MachinePoolSpec{
+							Replicas: pointer.Int32Ptr(2),
+						},
+						Status: expv1.MachinePoolStatus{
+							InfrastructureReady: true,
+							NodeRefs:            []corev1.ObjectReference{{Name: "node1"}, {Name: "node2"}},
+						},
+					},
 				},
 			},
 			wantErr: false,
@@ -945,6 +1271,94 @@ func Test_objectsMoverService_checkTargetProviders(t *testing.T) {
 	}
 }
 
+func Test_objectMover_checkTargetCRDs(t *testing.T) {
+	// getFakeProxyWithCRDsExcept returns a fakeProxy with all the CRDs for the types involved in the test, except the one for the given kind,
+	// which is replaced with a CRD serving the given versions, if any.
+	getFakeProxyWithCRDsExcept := func(kind string, versions ...string) *test.FakeProxy {
+		proxy := test.NewFakeProxy()
+		for _, o := range test.FakeCRDList() {
+			if o.Spec.Names.Kind == kind {
+				if len(versions) == 0 {
+					continue
+				}
+				o = test.FakeCustomResourceDefinition(o.Spec.Group, kind, versions...)
+			}
+			proxy.WithObjs(o)
+		}
+		return proxy
+	}
+
+	// getFakeProxyWithCRDNotServing returns a fakeProxy with all the CRDs for the types involved in the test, but with the CRD
+	// for the given kind defining the version of the objects without serving it.
+	getFakeProxyWithCRDNotServing := func(kind string) *test.FakeProxy {
+		proxy := test.NewFakeProxy()
+		for _, o := range test.FakeCRDList() {
+			if o.Spec.Names.Kind == kind {
+				o = test.FakeCustomResourceDefinition(o.Spec.Group, kind, "v1alpha5", "v1alpha4")
+				o.Spec.Versions[1].Served = false
+			}
+			proxy.WithObjs(o)
+		}
+		return proxy
+	}
+
+	tests := []struct {
+		name    string
+		toProxy Proxy
+		wantErr bool
+	}{
+		{
+			name:    "all the CRDs in place",
+			toProxy: getFakeProxyWithCRDs(),
+			wantErr: false,
+		},
+		{
+			name:    "all the CRDs in place, serving additional versions",
+			toProxy: getFakeProxyWithCRDsExcept("Cluster", "v1alpha4", "v1alpha5"),
+			wantErr: false,
+		},
+		{
+			name:    "fails if a CRD is missing",
+			toProxy: getFakeProxyWithCRDsExcept("GenericInfrastructureCluster"),
+			wantErr: true,
+		},
+		{
+			name:    "fails if a CRD does not serve the version of the objects",
+			toProxy: getFakeProxyWithCRDsExcept("Cluster", "v1alpha5"),
+			wantErr: true,
+		},
+		{
+			name:    "fails if a CRD defines the version of the objects without serving it",
+			toProxy: getFakeProxyWithCRDNotServing("Cluster"),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			// Create an objectGraph bound a source cluster with all the CRDs for the types involved in the test.
+			graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "foo").Objs())
+
+			// Get all the types to be considered for discovery
+			g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+
+			// trigger discovery the content of the source cluster
+			g.Expect(graph.Discovery("")).To(Succeed())
+
+			o := &objectMover{
+				fromProxy: graph.proxy,
+			}
+			err := o.checkTargetCRDs(graph, tt.toProxy)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+		})
+	}
+}
+
 func Test_objectMoverService_ensureNamespace(t *testing.T) {
 	type args struct {
 		toProxy   Proxy
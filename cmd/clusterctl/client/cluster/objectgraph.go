@@ -26,11 +26,14 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	secretutil "sigs.k8s.io/cluster-api/util/secret"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -53,7 +56,8 @@ type node struct {
 	// E.g. secrets are soft-owned by a cluster via a naming convention, but without an explicit OwnerReference.
 	softOwners map[*node]empty
 
-	// forceMove is set to true if the CRD of this object has the "move" label attached.
+	// forceMove is set to true if the CRD of this object has the "move" label attached, or if the object matches
+	// the additional objects in the clusterctl move configuration.
 	// This ensures the node is moved, regardless of its owner refs.
 	forceMove bool
 
@@ -73,11 +77,33 @@ type node struct {
 	// tenantCRSs define the list of ClusterResourceSet which are tenant for the node, no matter if the node has a direct OwnerReference to the ClusterResourceSet or if
 	// the node is linked to a ClusterResourceSet indirectly in the OwnerReference chain.
 	tenantCRSs map[*node]empty
+
+	// shared is set to true if the node is used by Clusters not being moved, when moving only the Clusters matching a selector.
+	// Shared nodes are copied to the target management cluster, but they are not deleted from the source management cluster.
+	shared bool
 }
 
 type discoveryTypeInfo struct {
 	typeMeta  metav1.TypeMeta
 	forceMove bool
+
+	// additionalObjectSelectors select the objects of this type to be moved, as defined by the additional objects
+	// in the clusterctl move configuration; objects matching any of the selectors are force moved.
+	additionalObjectSelectors []labels.Selector
+
+	// additionalOnly is set to true if this type is discovered only because of the additional objects in the
+	// clusterctl move configuration; in this case only the objects matching additionalObjectSelectors are discovered.
+	additionalOnly bool
+}
+
+// isAdditionalObject returns true if an object with the given labels matches any of the additional object selectors.
+func (t *discoveryTypeInfo) isAdditionalObject(objLabels map[string]string) bool {
+	for _, selector := range t.additionalObjectSelectors {
+		if selector.Matches(labels.Set(objLabels)) {
+			return true
+		}
+	}
+	return false
 }
 
 // markObserved marks the fact that a node was observed as a concrete object.
@@ -103,6 +129,16 @@ func (n *node) isSoftOwnedBy(other *node) bool {
 	return ok
 }
 
+// hasClusterOwner returns true if the node has an OwnerReference to a Cluster existing in the graph.
+func (n *node) hasClusterOwner() bool {
+	for owner := range n.owners {
+		if !owner.virtual && owner.identity.GroupVersionKind().GroupKind() == clusterv1.GroupVersion.WithKind("Cluster").GroupKind() {
+			return true
+		}
+	}
+	return false
+}
+
 // objectGraph manages the Kubernetes object graph that is generated during the discovery phase for the move operation.
 type objectGraph struct {
 	proxy     Proxy
@@ -215,7 +251,7 @@ func (o *objectGraph) getForceMove(kind, apiVersion string, labels map[string]st
 	kindAPIStr := getKindAPIString(metav1.TypeMeta{Kind: kind, APIVersion: apiVersion})
 
 	if discoveryType, ok := o.types[kindAPIStr]; ok {
-		return discoveryType.forceMove
+		return discoveryType.forceMove || discoveryType.isAdditionalObject(labels)
 	}
 	return false
 }
@@ -269,6 +305,33 @@ func (o *objectGraph) getDiscoveryTypes() error {
 	return nil
 }
 
+// addAdditionalObjectTypes adds to the types to be considered for the move discovery phase the additional objects
+// defined in the clusterctl move configuration, e.g. cloud credential Secrets or IPAM pools.
+// Objects matching the additional object selectors are force moved; types which are not already considered for the
+// move discovery phase are added, but only the objects matching the selectors are discovered.
+func (o *objectGraph) addAdditionalObjectTypes(additionalObjects []config.MoveAdditionalObject) error {
+	for i := range additionalObjects {
+		additionalObject := additionalObjects[i]
+		selector, err := metav1.LabelSelectorAsSelector(&additionalObject.Selector)
+		if err != nil {
+			return errors.Wrapf(err, "failed to parse the selector of the additional objects of kind %s", additionalObject.Kind)
+		}
+
+		typeMeta := metav1.TypeMeta{Kind: additionalObject.Kind, APIVersion: additionalObject.APIVersion}
+		kindAPIStr := getKindAPIString(typeMeta)
+		discoveryType, ok := o.types[kindAPIStr]
+		if !ok {
+			discoveryType = &discoveryTypeInfo{
+				typeMeta:       typeMeta,
+				additionalOnly: true,
+			}
+			o.types[kindAPIStr] = discoveryType
+		}
+		discoveryType.additionalObjectSelectors = append(discoveryType.additionalObjectSelectors, selector)
+	}
+	return nil
+}
+
 // getKindAPIString returns a concatenated string of the API name and the plural of the kind
 // Ex: KIND=Foo API NAME=foo.bar.domain.tld => foos.foo.bar.domain.tld
 func getKindAPIString(typeMeta metav1.TypeMeta) string {
@@ -317,6 +380,9 @@ func (o *objectGraph) Discovery(namespace string) error {
 		log.V(5).Info(typeMeta.Kind, "Count", len(objList.Items))
 		for i := range objList.Items {
 			obj := objList.Items[i]
+			if discoveryType.additionalOnly && !discoveryType.isAdditionalObject(obj.GetLabels()) {
+				continue
+			}
 			o.addObj(&obj)
 		}
 	}
@@ -396,6 +462,17 @@ func (o *objectGraph) getCRSs() []*node {
 	return clusters
 }
 
+// getCRSBindings returns the list of ClusterResourceSetBinding existing in the object graph.
+func (o *objectGraph) getCRSBindings() []*node {
+	bindings := []*node{}
+	for _, node := range o.uidToNode {
+		if node.identity.GroupVersionKind().GroupKind() == addonsv1.GroupVersion.WithKind("ClusterResourceSetBinding").GroupKind() {
+			bindings = append(bindings, node)
+		}
+	}
+	return bindings
+}
+
 // getMoveNodes returns the list of nodes existing in the object graph that belong at least to one Cluster or to a ClusterResourceSet
 // or to a CRD containing the "move" label.
 func (o *objectGraph) getMoveNodes() []*node {
@@ -419,10 +496,35 @@ func (o *objectGraph) getMachines() []*node {
 	return machines
 }
 
+// getMachinePools returns the list of MachinePool existing in the object graph.
+func (o *objectGraph) getMachinePools() []*node {
+	machinePools := []*node{}
+	for _, node := range o.uidToNode {
+		if node.identity.GroupVersionKind().GroupKind() == expv1.GroupVersion.WithKind("MachinePool").GroupKind() {
+			machinePools = append(machinePools, node)
+		}
+	}
+	return machinePools
+}
+
 // setSoftOwnership searches for soft ownership relations such as secrets linked to the cluster by a naming convention (without any explicit OwnerReference).
 func (o *objectGraph) setSoftOwnership() {
 	log := logf.Log
 	clusters := o.getClusters()
+
+	// ClusterResourceSetBindings are named after their Cluster; link the ones missing the OwnerReference to the Cluster, e.g. because it
+	// was orphaned, so they are moved and deleted together with the Cluster, instead of being left behind in the source cluster.
+	for _, binding := range o.getCRSBindings() {
+		if binding.hasClusterOwner() {
+			continue
+		}
+		for _, cluster := range clusters {
+			if binding.identity.Name == cluster.identity.Name && binding.identity.Namespace == cluster.identity.Namespace {
+				binding.addSoftOwner(cluster)
+			}
+		}
+	}
+
 	for _, secret := range o.getSecrets() {
 		// If the secret has at least one OwnerReference ignore it.
 		// NB. Cluster API generated secrets have an explicit OwnerReference to the ControlPlane or the KubeadmConfig object while user provided secrets might not have one.
@@ -466,6 +568,10 @@ func (o *objectGraph) setClusterTenant(node, tenant *node) {
 // setClusterTenants sets the ClusterResourceSet tenants for the ClusterResourceSet itself and all their dependent object tree.
 func (o *objectGraph) setCRSTenants() {
 	for _, crs := range o.getCRSs() {
+		// ClusterResourceSets referenced by orphaned ClusterResourceSetBindings, but not existing anymore, are not moved.
+		if crs.virtual {
+			continue
+		}
 		o.setCRSTenant(crs, crs)
 	}
 }
@@ -480,6 +586,58 @@ func (o *objectGraph) setCRSTenant(node, tenant *node) {
 	}
 }
 
+// filterClusters restricts the object graph to the Clusters matching the given selector and to their dependent object tree.
+// Nodes belonging also to Clusters not being moved, as well as nodes not belonging to any Cluster but moved because of a ClusterResourceSet
+// or of the "move" label, are marked as shared, so they are copied to the target management cluster without being deleted from the source one.
+func (o *objectGraph) filterClusters(selector labels.Selector) error {
+	log := logf.Log
+
+	for _, cluster := range o.getClusters() {
+		clusterObj := &clusterv1.Cluster{}
+		if err := getClusterObj(o.proxy, cluster, clusterObj); err != nil {
+			return err
+		}
+		if selector.Matches(labels.Set(clusterObj.Labels)) {
+			continue
+		}
+
+		log.V(5).Info("Excluding Cluster from move (not matching the selector)", "Cluster", cluster.identity.Name, "Namespace", cluster.identity.Namespace)
+		for _, n := range o.getNodes() {
+			if _, ok := n.tenantClusters[cluster]; !ok {
+				continue
+			}
+			delete(n.tenantClusters, cluster)
+			n.shared = true
+
+			// Remove the nodes belonging only to Clusters not being moved from the graph.
+			if len(n.tenantClusters) == 0 {
+				delete(o.uidToNode, n.identity.UID)
+			}
+		}
+	}
+
+	for _, n := range o.getNodes() {
+		// Nodes not belonging to any Cluster are moved because of a ClusterResourceSet or of the "move" label;
+		// they might be used by the Clusters not being moved as well.
+		if len(n.tenantClusters) == 0 && (len(n.tenantCRSs) > 0 || n.forceMove) {
+			n.shared = true
+		}
+
+		// Remove the references to the nodes not being moved, so the OwnerReferences to them are not recreated in the target cluster.
+		for owner := range n.owners {
+			if _, ok := o.uidToNode[owner.identity.UID]; !ok {
+				delete(n.owners, owner)
+			}
+		}
+		for owner := range n.softOwners {
+			if _, ok := o.uidToNode[owner.identity.UID]; !ok {
+				delete(n.softOwners, owner)
+			}
+		}
+	}
+	return nil
+}
+
 // checkVirtualNode logs if nodes are still virtual
 func (o *objectGraph) checkVirtualNode() {
 	log := logf.Log
@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ObjectGraph is a read-only representation of the graph of Cluster API objects existing in a management cluster,
+// as discovered by clusterctl (e.g. during move).
+type ObjectGraph struct {
+	// Nodes contains all the objects in the graph, sorted by kind, namespace and name.
+	Nodes []ObjectGraphNode
+}
+
+// ObjectGraphNode defines an object in the ObjectGraph.
+type ObjectGraphNode struct {
+	// Object identifies the object, including its GroupVersionKind.
+	Object corev1.ObjectReference
+
+	// Virtual is true if the object was referenced, e.g. by an OwnerReference, but it has not been observed as
+	// a concrete object during discovery.
+	Virtual bool
+
+	// IsGlobal is true if the object is not namespaced.
+	IsGlobal bool
+
+	// ForceMove is true if the object is moved regardless of its owners, e.g. because its CRD has the clusterctl move label.
+	ForceMove bool
+
+	// Owners lists the objects owning this object through an OwnerReference.
+	Owners []ObjectGraphOwner
+
+	// SoftOwners lists the objects owning this object without an explicit OwnerReference (virtual edges),
+	// e.g. secrets linked to a Cluster by a naming convention.
+	SoftOwners []corev1.ObjectReference
+
+	// TenantClusters lists the Clusters this object belongs to, directly or indirectly through the ownership chain.
+	TenantClusters []corev1.ObjectReference
+
+	// TenantClusterResourceSets lists the ClusterResourceSets this object belongs to, directly or indirectly
+	// through the ownership chain.
+	TenantClusterResourceSets []corev1.ObjectReference
+}
+
+// ObjectGraphOwner defines an OwnerReference edge in the ObjectGraph.
+type ObjectGraphOwner struct {
+	// Object identifies the owner object.
+	Object corev1.ObjectReference
+
+	// Controller is true if the owner is the managing controller of the object.
+	Controller bool
+
+	// BlockOwnerDeletion is true if the owner can't be deleted before the object is removed.
+	BlockOwnerDeletion bool
+}
+
+// ObjectGraphDescriber defines methods for discovering the graph of Cluster API objects existing in a management cluster.
+type ObjectGraphDescriber interface {
+	// Describe returns the graph of the Cluster API objects existing in a namespace (or in all the namespaces if empty).
+	Describe(namespace string) (*ObjectGraph, error)
+}
+
+// objectGraphDescriber implements the ObjectGraphDescriber interface.
+type objectGraphDescriber struct {
+	proxy Proxy
+}
+
+// ensure objectGraphDescriber implements the ObjectGraphDescriber interface.
+var _ ObjectGraphDescriber = &objectGraphDescriber{}
+
+func newObjectGraphDescriber(proxy Proxy) *objectGraphDescriber {
+	return &objectGraphDescriber{
+		proxy: proxy,
+	}
+}
+
+func (d *objectGraphDescriber) Describe(namespace string) (*ObjectGraph, error) {
+	graph := newObjectGraph(d.proxy)
+
+	// Gets all the types defines by the CRDs installed by clusterctl plus the ConfigMap/Secret core types.
+	if err := graph.getDiscoveryTypes(); err != nil {
+		return nil, err
+	}
+
+	if err := graph.Discovery(namespace); err != nil {
+		return nil, err
+	}
+
+	return graph.describe(), nil
+}
+
+// describe converts the internal representation of the object graph into an ObjectGraph.
+func (o *objectGraph) describe() *ObjectGraph {
+	graph := &ObjectGraph{
+		Nodes: make([]ObjectGraphNode, 0, len(o.uidToNode)),
+	}
+
+	for _, n := range o.uidToNode {
+		item := ObjectGraphNode{
+			Object:    n.identity,
+			Virtual:   n.virtual,
+			IsGlobal:  n.isGlobal,
+			ForceMove: n.forceMove,
+		}
+
+		for owner, attributes := range n.owners {
+			item.Owners = append(item.Owners, ObjectGraphOwner{
+				Object:             owner.identity,
+				Controller:         attributes.Controller != nil && *attributes.Controller,
+				BlockOwnerDeletion: attributes.BlockOwnerDeletion != nil && *attributes.BlockOwnerDeletion,
+			})
+		}
+		sort.Slice(item.Owners, func(i, j int) bool {
+			return objectReferenceLess(item.Owners[i].Object, item.Owners[j].Object)
+		})
+
+		item.SoftOwners = nodeSetToSortedReferences(n.softOwners)
+		item.TenantClusters = nodeSetToSortedReferences(n.tenantClusters)
+		item.TenantClusterResourceSets = nodeSetToSortedReferences(n.tenantCRSs)
+
+		graph.Nodes = append(graph.Nodes, item)
+	}
+
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return objectReferenceLess(graph.Nodes[i].Object, graph.Nodes[j].Object)
+	})
+	return graph
+}
+
+func nodeSetToSortedReferences(nodes map[*node]empty) []corev1.ObjectReference {
+	if len(nodes) == 0 {
+		return nil
+	}
+	refs := make([]corev1.ObjectReference, 0, len(nodes))
+	for n := range nodes {
+		refs = append(refs, n.identity)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		return objectReferenceLess(refs[i], refs[j])
+	})
+	return refs
+}
+
+func objectReferenceLess(a, b corev1.ObjectReference) bool {
+	if a.Kind != b.Kind {
+		return a.Kind < b.Kind
+	}
+	if a.Namespace != b.Namespace {
+		return a.Namespace < b.Namespace
+	}
+	if a.Name != b.Name {
+		return a.Name < b.Name
+	}
+	return a.APIVersion < b.APIVersion
+}
@@ -0,0 +1,61 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func TestObjectGraph_describe(t *testing.T) {
+	g := NewWithT(t)
+
+	// Create an objectGraph bound to a source cluster with all the CRDs for the types involved in the test.
+	graph := getObjectGraphWithObjs(test.NewFakeCluster("ns1", "cluster1").Objs())
+
+	// Get all the types to be considered for discovery
+	g.Expect(getFakeDiscoveryTypes(graph)).To(Succeed())
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	got := graph.describe()
+	g.Expect(got.Nodes).To(HaveLen(4))
+
+	// Nodes are sorted by kind, namespace and name.
+	g.Expect(got.Nodes[0].Object.Kind).To(Equal("Cluster"))
+	g.Expect(got.Nodes[1].Object.Kind).To(Equal("GenericInfrastructureCluster"))
+	g.Expect(got.Nodes[2].Object.Name).To(Equal("cluster1-ca"))
+	g.Expect(got.Nodes[3].Object.Name).To(Equal("cluster1-kubeconfig"))
+
+	cluster := got.Nodes[0]
+	g.Expect(cluster.Owners).To(BeEmpty())
+	g.Expect(cluster.Virtual).To(BeFalse())
+	g.Expect(cluster.TenantClusters).To(ConsistOf(cluster.Object))
+
+	infraCluster := got.Nodes[1]
+	g.Expect(infraCluster.Owners).To(HaveLen(1))
+	g.Expect(infraCluster.Owners[0].Object).To(Equal(cluster.Object))
+	g.Expect(infraCluster.TenantClusters).To(ConsistOf(cluster.Object))
+
+	// The CA secret is linked to the Cluster by a naming convention only.
+	caSecret := got.Nodes[2]
+	g.Expect(caSecret.Owners).To(BeEmpty())
+	g.Expect(caSecret.SoftOwners).To(ConsistOf(cluster.Object))
+	g.Expect(caSecret.TenantClusters).To(ConsistOf(cluster.Object))
+}
@@ -25,9 +25,11 @@ import (
 
 	"github.com/pkg/errors"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -734,12 +736,12 @@ var objectGraphsTests = []struct {
 				},
 				"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureMachineTemplate, ns1/mp1": {
 					owners: []string{
-						"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1",
+						"exp.cluster.x-k8s.io/v1alpha4, Kind=MachinePool, ns1/mp1",
 					},
 				},
 				"bootstrap.cluster.x-k8s.io/v1alpha4, Kind=GenericBootstrapConfigTemplate, ns1/mp1": {
 					owners: []string{
-						"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1",
+						"exp.cluster.x-k8s.io/v1alpha4, Kind=MachinePool, ns1/mp1",
 					},
 				},
 			},
@@ -1257,6 +1259,59 @@ func TestObjectGraph_DiscoveryByNamespace(t *testing.T) {
 	}
 }
 
+func TestObjectGraph_DiscoveryWithAdditionalObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	newObj := func(obj client.Object, kind, name string, labels map[string]string) client.Object {
+		obj.SetNamespace("ns1")
+		obj.SetName(name)
+		obj.SetLabels(labels)
+		obj.SetUID(types.UID(fmt.Sprintf("/v1, Kind=%s, ns1/%s", kind, name)))
+		return obj
+	}
+	moveLabels := map[string]string{"move": "true"}
+
+	objs := test.NewFakeCluster("ns1", "cluster1").Objs()
+	objs = append(objs,
+		newObj(&corev1.Secret{}, "Secret", "cloud-credentials", moveLabels),
+		newObj(&corev1.Secret{}, "Secret", "other-credentials", nil),
+		newObj(&corev1.ServiceAccount{}, "ServiceAccount", "ipam", moveLabels),
+		newObj(&corev1.ServiceAccount{}, "ServiceAccount", "other", nil),
+	)
+
+	// Create an objectGraph bound to a source cluster with all the CRDs for the types involved in the test.
+	graph := getObjectGraphWithObjs(objs)
+
+	// Get all the types to be considered for discovery, including the additional objects.
+	g.Expect(graph.getDiscoveryTypes()).To(Succeed())
+	g.Expect(graph.addAdditionalObjectTypes([]config.MoveAdditionalObject{
+		{APIVersion: "v1", Kind: "Secret", Selector: metav1.LabelSelector{MatchLabels: moveLabels}},
+		{APIVersion: "v1", Kind: "ServiceAccount", Selector: metav1.LabelSelector{MatchLabels: moveLabels}},
+	})).To(Succeed())
+
+	// Given that the Fake client behaves in a different way than real client, for this test we are required to add the List suffix to all the types.
+	for _, discoveryType := range graph.types {
+		discoveryType.typeMeta.Kind = fmt.Sprintf("%sList", discoveryType.typeMeta.Kind)
+	}
+
+	g.Expect(graph.Discovery("")).To(Succeed())
+
+	forceMove := map[string]bool{}
+	for uid, n := range graph.uidToNode {
+		forceMove[string(uid)] = n.forceMove
+	}
+	// NB. ServiceAccounts are not Cluster API types, so only the ones matching the selector are discovered.
+	g.Expect(forceMove).To(Equal(map[string]bool{
+		"cluster.x-k8s.io/v1alpha4, Kind=Cluster, ns1/cluster1":                                     false,
+		"infrastructure.cluster.x-k8s.io/v1alpha4, Kind=GenericInfrastructureCluster, ns1/cluster1": false,
+		"/v1, Kind=Secret, ns1/cluster1-ca":                                                         false,
+		"/v1, Kind=Secret, ns1/cluster1-kubeconfig":                                                 false,
+		"/v1, Kind=Secret, ns1/cloud-credentials":                                                   true,
+		"/v1, Kind=Secret, ns1/other-credentials":                                                   false,
+		"/v1, Kind=ServiceAccount, ns1/ipam":                                                        true,
+	}))
+}
+
 func Test_objectGraph_setSoftOwnership(t *testing.T) {
 	type fields struct {
 		objs []client.Object
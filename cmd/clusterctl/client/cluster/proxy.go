@@ -77,29 +77,39 @@ func (k *proxy) CurrentNamespace() (string, error) {
 }
 
 func (k *proxy) ValidateKubernetesVersion() error {
-	config, err := k.GetConfig()
+	serverVersion, err := k.GetServerVersion()
 	if err != nil {
 		return err
 	}
 
-	client := discovery.NewDiscoveryClientForConfigOrDie(config)
-	serverVersion, err := client.ServerVersion()
-	if err != nil {
-		return errors.Wrap(err, "failed to retrieve server version")
-	}
-
-	compver, err := utilversion.MustParseGeneric(serverVersion.String()).Compare(minimumKubernetesVersion)
+	compver, err := utilversion.MustParseGeneric(serverVersion).Compare(minimumKubernetesVersion)
 	if err != nil {
 		return errors.Wrap(err, "failed to parse and compare server version")
 	}
 
 	if compver == -1 {
-		return errors.Errorf("unsupported management cluster server version: %s - minimum required version is %s", serverVersion.String(), minimumKubernetesVersion)
+		return errors.Errorf("unsupported management cluster server version: %s - minimum required version is %s", serverVersion, minimumKubernetesVersion)
 	}
 
 	return nil
 }
 
+// GetServerVersion returns the Kubernetes version of the management cluster.
+func (k *proxy) GetServerVersion() (string, error) {
+	config, err := k.GetConfig()
+	if err != nil {
+		return "", err
+	}
+
+	client := discovery.NewDiscoveryClientForConfigOrDie(config)
+	serverVersion, err := client.ServerVersion()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to retrieve server version")
+	}
+
+	return serverVersion.String(), nil
+}
+
 // GetConfig returns the config for a kubernetes client.
 func (k *proxy) GetConfig() (*rest.Config, error) {
 	config, err := k.configLoadingRules.Load()
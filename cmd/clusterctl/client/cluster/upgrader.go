@@ -17,13 +17,29 @@ limitations under the License.
 package cluster
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/version"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	waitProviderInterval = 5 * time.Second
+	waitProviderTimeout  = 5 * time.Minute
 )
 
 // ProviderUpgrader defines methods for supporting provider upgrade.
@@ -36,10 +52,29 @@ type ProviderUpgrader interface {
 	Plan() ([]UpgradePlan, error)
 
 	// ApplyPlan executes an upgrade following an UpgradePlan generated by clusterctl.
-	ApplyPlan(coreProvider clusterctlv1.Provider, clusterAPIVersion string) error
+	ApplyPlan(options UpgradeOptions, coreProvider clusterctlv1.Provider, clusterAPIVersion string) error
 
 	// ApplyCustomPlan plan executes an upgrade using the UpgradeItems provided by the user.
-	ApplyCustomPlan(coreProvider clusterctlv1.Provider, providersToUpgrade ...UpgradeItem) error
+	ApplyCustomPlan(options UpgradeOptions, coreProvider clusterctlv1.Provider, providersToUpgrade ...UpgradeItem) error
+
+	// InstalledProviders returns the providers installed in the management cluster, with the API Version of Cluster API
+	// (contract) supported by their version and the versions available for upgrade.
+	InstalledProviders(options InstalledProvidersOptions) ([]InstalledProvider, error)
+}
+
+// UpgradeOptions defines the options used when applying an upgrade.
+type UpgradeOptions struct {
+	// IgnoreVersionSkew applies the upgrade even if it violates the Kubernetes version skew policy of Cluster API.
+	IgnoreVersionSkew bool
+
+	// NoRollback leaves the providers as they are when the upgrade fails, instead of rolling back the providers
+	// already upgraded to the versions recorded in the inventory before the upgrade. Rollbacks are supported only
+	// for upgrades within the same API Version of Cluster API (contract).
+	NoRollback bool
+
+	// WaitProviderTimeout is the time to wait for each upgraded provider to become available before considering the
+	// upgrade failed. Defaults to 5 minutes.
+	WaitProviderTimeout time.Duration
 }
 
 // UpgradePlan defines a list of possible upgrade targets for a management group.
@@ -47,6 +82,10 @@ type UpgradePlan struct {
 	Contract     string
 	CoreProvider clusterctlv1.Provider
 	Providers    []UpgradeItem
+
+	// VersionSkewViolations are the rules of the Kubernetes version skew policy of Cluster API violated by the plan,
+	// given the Kubernetes version of the management cluster.
+	VersionSkewViolations []VersionSkewViolation
 }
 
 // UpgradeRef returns a string identifying the upgrade plan; this string is derived by the core provider which is
@@ -77,10 +116,12 @@ func (u *UpgradeItem) UpgradeRef() string {
 }
 
 type providerUpgrader struct {
+	proxy                   Proxy
 	configClient            config.Client
 	repositoryClientFactory RepositoryClientFactory
 	providerInventory       InventoryClient
 	providerComponents      ComponentsClient
+	pollImmediateWaiter     PollImmediateWaiter
 }
 
 var _ ProviderUpgrader = &providerUpgrader{}
@@ -94,6 +135,13 @@ func (u *providerUpgrader) Plan() ([]UpgradePlan, error) {
 		return nil, err
 	}
 
+	// Gets the Kubernetes version of the management cluster, used to check the upgrade plans against the Kubernetes
+	// version skew policy of Cluster API.
+	managementVersion, err := u.proxy.GetServerVersion()
+	if err != nil {
+		return nil, err
+	}
+
 	var ret []UpgradePlan
 	for _, managementGroup := range managementGroups {
 		// The core provider is driving all the plan logic for each management group, because all the providers
@@ -131,6 +179,7 @@ func (u *providerUpgrader) Plan() ([]UpgradePlan, error) {
 			if upgradePlan.isPartialUpgrade() && coreUpgradeInfo.currentContract != contract {
 				continue
 			}
+			upgradePlan.VersionSkewViolations = versionSkewViolations(managementVersion, contract)
 
 			ret = append(ret, *upgradePlan)
 		}
@@ -139,7 +188,7 @@ func (u *providerUpgrader) Plan() ([]UpgradePlan, error) {
 	return ret, nil
 }
 
-func (u *providerUpgrader) ApplyPlan(coreProvider clusterctlv1.Provider, contract string) error {
+func (u *providerUpgrader) ApplyPlan(options UpgradeOptions, coreProvider clusterctlv1.Provider, contract string) error {
 	log := logf.Log
 	log.Info("Performing upgrade...")
 
@@ -155,11 +204,16 @@ func (u *providerUpgrader) ApplyPlan(coreProvider clusterctlv1.Provider, contrac
 		return err
 	}
 
+	// Checks the upgrade plan against the Kubernetes version skew policy of Cluster API.
+	if err := u.checkVersionSkew(upgradePlan, options); err != nil {
+		return err
+	}
+
 	// Do the upgrade
-	return u.doUpgrade(upgradePlan)
+	return u.doUpgrade(upgradePlan, options)
 }
 
-func (u *providerUpgrader) ApplyCustomPlan(coreProvider clusterctlv1.Provider, upgradeItems ...UpgradeItem) error {
+func (u *providerUpgrader) ApplyCustomPlan(options UpgradeOptions, coreProvider clusterctlv1.Provider, upgradeItems ...UpgradeItem) error {
 	log := logf.Log
 	log.Info("Performing upgrade...")
 
@@ -170,8 +224,13 @@ func (u *providerUpgrader) ApplyCustomPlan(coreProvider clusterctlv1.Provider, u
 		return err
 	}
 
+	// Checks the upgrade plan against the Kubernetes version skew policy of Cluster API.
+	if err := u.checkVersionSkew(upgradePlan, options); err != nil {
+		return err
+	}
+
 	// Do the upgrade
-	return u.doUpgrade(upgradePlan)
+	return u.doUpgrade(upgradePlan, options)
 }
 
 // getUpgradePlan returns the upgrade plan for a specific managementGroup/contract
@@ -226,6 +285,12 @@ func (u *providerUpgrader) createCustomPlan(coreProvider clusterctlv1.Provider,
 		return nil, err
 	}
 
+	// Pins the upgrade items without a namespace to the only instance of the provider in the management group.
+	upgradeItems, err = resolveUpgradeItems(managementGroup, upgradeItems)
+	if err != nil {
+		return nil, err
+	}
+
 	// Gets the API Version of Cluster API (contract).
 	// The this is required to ensure all the providers in a management group are consistent with the contract supported by the core provider.
 	// e.g if the core provider is v1alpha3, all the provider in the same management group should be v1alpha3 as well.
@@ -297,6 +362,38 @@ func (u *providerUpgrader) createCustomPlan(coreProvider clusterctlv1.Provider,
 	return upgradePlan, nil
 }
 
+// resolveUpgradeItems sets the namespace of the upgrade items not pinned to a namespace, which is allowed only if the
+// management group has a single instance of the provider, and rejects the upgrade items referring to the same instance.
+func resolveUpgradeItems(managementGroup *ManagementGroup, upgradeItems []UpgradeItem) ([]UpgradeItem, error) {
+	resolved := make([]UpgradeItem, 0, len(upgradeItems))
+	instanceNames := sets.NewString()
+	for _, upgradeItem := range upgradeItems {
+		if upgradeItem.Namespace == "" {
+			var namespaces []string
+			for _, provider := range managementGroup.Providers {
+				if provider.ProviderName == upgradeItem.ProviderName && provider.Type == upgradeItem.Type {
+					namespaces = append(namespaces, provider.Namespace)
+				}
+			}
+			switch len(namespaces) {
+			case 0:
+				return nil, errors.Errorf("unable to complete that upgrade: the provider %s is not part of the %s management group", upgradeItem.ProviderName, managementGroup.CoreProvider.InstanceName())
+			case 1:
+				upgradeItem.Namespace = namespaces[0]
+			default:
+				return nil, errors.Errorf("unable to complete that upgrade: the %s management group has multiple instances of the provider %s, in the namespaces %s. Please specify the instance to upgrade in the form namespace/name:version", managementGroup.CoreProvider.InstanceName(), upgradeItem.ProviderName, strings.Join(namespaces, ", "))
+			}
+		}
+
+		if instanceNames.Has(upgradeItem.InstanceName()) {
+			return nil, errors.Errorf("unable to complete that upgrade: the provider %s is included in the upgrade more than once", upgradeItem.InstanceName())
+		}
+		instanceNames.Insert(upgradeItem.InstanceName())
+		resolved = append(resolved, upgradeItem)
+	}
+	return resolved, nil
+}
+
 // getProviderContractByVersion returns the contract that a provider will support if updated to the given target version.
 func (u *providerUpgrader) getProviderContractByVersion(provider clusterctlv1.Provider, targetVersion string) (string, error) {
 	targetSemVersion, err := version.ParseSemantic(targetVersion)
@@ -341,41 +438,232 @@ func (u *providerUpgrader) getUpgradeComponents(provider UpgradeItem) (repositor
 	return components, nil
 }
 
-func (u *providerUpgrader) doUpgrade(upgradePlan *UpgradePlan) error {
+func (u *providerUpgrader) doUpgrade(upgradePlan *UpgradePlan, options UpgradeOptions) (reterr error) {
+	steps := 0
+	for _, upgradeItem := range upgradePlan.Providers {
+		if upgradeItem.NextVersion != "" {
+			steps++
+		}
+	}
+	progress := logf.NewProgress("upgrade", steps)
+	defer func() {
+		progress.Done(reterr)
+	}()
+
+	// Gets the providers installed before the upgrade, so they can be rolled back if the upgrade fails.
+	installed, err := u.providerInventory.List()
+	if err != nil {
+		return err
+	}
+
+	var upgraded []clusterctlv1.Provider
 	for _, upgradeItem := range upgradePlan.Providers {
 		// If there is not a specified next version, skip it (we are already up-to-date).
 		if upgradeItem.NextVersion == "" {
 			continue
 		}
 
+		progress.Step(fmt.Sprintf("Upgrading %s to %s", upgradeItem.InstanceName(), upgradeItem.NextVersion))
+
 		// Gets the provider components for the target version.
 		components, err := u.getUpgradeComponents(upgradeItem)
 		if err != nil {
-			return err
+			return u.rollback(upgraded, upgradePlan.Contract, options, err)
+		}
+
+		for _, provider := range installed.Items {
+			if provider.InstanceName() == upgradeItem.InstanceName() {
+				upgraded = append(upgraded, provider)
+			}
+		}
+
+		if err := u.replaceProvider(upgradeItem.Provider, components, options); err != nil {
+			return u.rollback(upgraded, upgradePlan.Contract, options, err)
+		}
+	}
+	return nil
+}
+
+// replaceProvider replaces the components of a provider with the given ones, and waits for the provider to be healthy.
+func (u *providerUpgrader) replaceProvider(provider clusterctlv1.Provider, components repository.Components, options UpgradeOptions) error {
+	// Delete the provider, preserving CRD and namespace.
+	if err := u.providerComponents.Delete(DeleteOptions{
+		Provider:         provider,
+		IncludeNamespace: false,
+		IncludeCRDs:      false,
+	}); err != nil {
+		return err
+	}
+
+	// Install the new version of the provider components.
+	if err := installComponentsAndUpdateInventory(components, u.providerComponents, u.providerInventory); err != nil {
+		return err
+	}
+
+	return u.waitForProviderHealthy(components, options.WaitProviderTimeout)
+}
+
+// rollback reinstalls the given providers, in reverse order, with the versions recorded in the inventory before the
+// upgrade; the returned error reports both the upgrade error and the outcome of the rollback.
+// NOTE: Rollbacks are supported only for upgrades within the same contract, because reinstalling a provider
+// supporting a previous contract can't revert the changes applied by the upgrade, e.g. the objects already
+// converted to the new storage version of the CRDs.
+func (u *providerUpgrader) rollback(providers []clusterctlv1.Provider, contract string, options UpgradeOptions, upgradeErr error) error {
+	if options.NoRollback || len(providers) == 0 {
+		return upgradeErr
+	}
+
+	for _, provider := range providers {
+		providerContract, err := u.getProviderContractByVersion(provider, provider.Version)
+		if err != nil {
+			return errors.Errorf("upgrade failed: %v; unable to roll back: %v", upgradeErr, err)
+		}
+		if providerContract != contract {
+			return errors.Errorf("upgrade failed: %v; unable to roll back: the %s provider was upgraded from the %s to the %s contract, and rollbacks are supported only within the same contract", upgradeErr, provider.InstanceName(), providerContract, contract)
+		}
+	}
+
+	log := logf.Log
+	log.Info("Upgrade failed, rolling back", "Error", upgradeErr.Error())
+
+	var rolledBack []string
+	var errs []error
+	for i := len(providers) - 1; i >= 0; i-- {
+		provider := providers[i]
+		components, err := u.getUpgradeComponents(UpgradeItem{Provider: provider, NextVersion: provider.Version})
+		if err == nil {
+			err = u.replaceProvider(provider, components, options)
+		}
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to roll back the %s provider to %s", provider.InstanceName(), provider.Version))
+			continue
+		}
+		log.Info("Rolled back", "Provider", provider.InstanceName(), "Version", provider.Version)
+		rolledBack = append(rolledBack, fmt.Sprintf("%s to %s", provider.InstanceName(), provider.Version))
+	}
+
+	if len(errs) > 0 {
+		return errors.Errorf("upgrade failed: %v; rollback failed: %v; rolled back providers: [%s]", upgradeErr, kerrors.NewAggregate(errs), strings.Join(rolledBack, ", "))
+	}
+	return errors.Wrapf(upgradeErr, "upgrade failed, rolled back providers: [%s]", strings.Join(rolledBack, ", "))
+}
+
+// waitForProviderHealthy waits for the Deployments of the provider components to be Available, and for the Services
+// of their webhooks to have ready endpoints; if timeout is zero, waitProviderTimeout is used.
+func (u *providerUpgrader) waitForProviderHealthy(components repository.Components, timeout time.Duration) error {
+	if timeout == 0 {
+		timeout = waitProviderTimeout
+	}
+
+	log := logf.Log
+	log.Info("Waiting for the provider to be available", "Provider", components.ManifestLabel(), "Version", components.Version())
+
+	deployments, services, err := providerHealthCheckTargets(append(components.SharedObjs(), components.InstanceObjs()...))
+	if err != nil {
+		return err
+	}
+
+	var reason string
+	if err := u.pollImmediateWaiter(waitProviderInterval, timeout, func() (bool, error) {
+		c, err := u.proxy.NewClient()
+		if err != nil {
+			return false, err
 		}
 
-		// Delete the provider, preserving CRD and namespace.
-		if err := u.providerComponents.Delete(DeleteOptions{
-			Provider:         upgradeItem.Provider,
-			IncludeNamespace: false,
-			IncludeCRDs:      false,
-		}); err != nil {
-			return err
+		for _, key := range deployments {
+			deployment := &appsv1.Deployment{}
+			if err := c.Get(ctx, key, deployment); err != nil {
+				reason = fmt.Sprintf("failed to get Deployment %s: %v", key, err)
+				return false, nil
+			}
+			if !isDeploymentAvailable(deployment) {
+				reason = fmt.Sprintf("Deployment %s is not available", key)
+				return false, nil
+			}
 		}
 
-		// Install the new version of the provider components.
-		if err := installComponentsAndUpdateInventory(components, u.providerComponents, u.providerInventory); err != nil {
-			return err
+		for _, key := range services {
+			endpoints := &corev1.Endpoints{}
+			if err := c.Get(ctx, key, endpoints); err != nil && !apierrors.IsNotFound(err) {
+				reason = fmt.Sprintf("failed to get Endpoints %s: %v", key, err)
+				return false, nil
+			}
+			if !hasReadyEndpoints(endpoints) {
+				reason = fmt.Sprintf("webhook Service %s has no ready endpoints", key)
+				return false, nil
+			}
 		}
+		return true, nil
+	}); err != nil {
+		return errors.Wrapf(err, "the %s provider %s did not become available: %s", components.ManifestLabel(), components.Version(), reason)
 	}
 	return nil
 }
 
-func newProviderUpgrader(configClient config.Client, repositoryClientFactory RepositoryClientFactory, providerInventory InventoryClient, providerComponents ComponentsClient) *providerUpgrader {
+// providerHealthCheckTargets returns the Deployments and the Services of the webhooks among the given objects.
+func providerHealthCheckTargets(objs []unstructured.Unstructured) ([]client.ObjectKey, []client.ObjectKey, error) {
+	deploymentGroupKind := appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind()
+	validatingWebhookGroupKind := admissionregistrationv1.SchemeGroupVersion.WithKind("ValidatingWebhookConfiguration").GroupKind()
+	mutatingWebhookGroupKind := admissionregistrationv1.SchemeGroupVersion.WithKind("MutatingWebhookConfiguration").GroupKind()
+
+	var deployments, services []client.ObjectKey
+	seen := sets.NewString()
+	for _, obj := range objs {
+		switch obj.GroupVersionKind().GroupKind() {
+		case deploymentGroupKind:
+			deployments = append(deployments, client.ObjectKey{Namespace: obj.GetNamespace(), Name: obj.GetName()})
+		case validatingWebhookGroupKind, mutatingWebhookGroupKind:
+			webhooks, _, err := unstructured.NestedSlice(obj.Object, "webhooks")
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to get the webhooks of %s %s", obj.GetKind(), obj.GetName())
+			}
+			for _, webhook := range webhooks {
+				webhookMap, ok := webhook.(map[string]interface{})
+				if !ok {
+					continue
+				}
+				namespace, _, _ := unstructured.NestedString(webhookMap, "clientConfig", "service", "namespace")
+				name, _, _ := unstructured.NestedString(webhookMap, "clientConfig", "service", "name")
+				key := client.ObjectKey{Namespace: namespace, Name: name}
+				if name == "" || seen.Has(key.String()) {
+					continue
+				}
+				seen.Insert(key.String())
+				services = append(services, key)
+			}
+		}
+	}
+	return deployments, services, nil
+}
+
+func isDeploymentAvailable(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.Generation {
+		return false
+	}
+	for _, c := range deployment.Status.Conditions {
+		if c.Type == appsv1.DeploymentAvailable {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func hasReadyEndpoints(endpoints *corev1.Endpoints) bool {
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+func newProviderUpgrader(proxy Proxy, configClient config.Client, repositoryClientFactory RepositoryClientFactory, providerInventory InventoryClient, providerComponents ComponentsClient, pollImmediateWaiter PollImmediateWaiter) *providerUpgrader {
 	return &providerUpgrader{
+		proxy:                   proxy,
 		configClient:            configClient,
 		repositoryClientFactory: repositoryClientFactory,
 		providerInventory:       providerInventory,
 		providerComponents:      providerComponents,
+		pollImmediateWaiter:     pollImmediateWaiter,
 	}
 }
@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+)
+
+// InstalledProvidersOptions defines the options used when listing the installed providers.
+type InstalledProvidersOptions struct {
+	// SkipRepositories does not read the provider repositories, so the providers are listed without accessing the
+	// network; in this case the Contract and the AvailableUpgrades of the providers are not set.
+	SkipRepositories bool
+}
+
+// InstalledProvider describes a provider instance installed in the management cluster.
+type InstalledProvider struct {
+	// Provider is the inventory item of the provider instance, with its name, type, version, namespace and
+	// watched namespace.
+	clusterctlv1.Provider
+
+	// ManagementGroup is the instance name of the core provider of the management group the provider belongs to,
+	// e.g. capi-system/cluster-api.
+	ManagementGroup string
+
+	// Contract is the API Version of Cluster API (contract) supported by the installed version of the provider,
+	// e.g. v1alpha4; it is empty if the version is not part of any release series of the provider.
+	Contract string
+
+	// AvailableUpgrades are the latest versions of the provider available for upgrade, one for each API Version of
+	// Cluster API (contract); they are the same versions proposed by the upgrade plans of the management group.
+	AvailableUpgrades []AvailableUpgrade
+}
+
+// AvailableUpgrade defines a version a provider can be upgraded to.
+type AvailableUpgrade struct {
+	// Contract is the API Version of Cluster API (contract) supported by Version.
+	Contract string
+
+	// Version is the latest version of the provider supporting Contract, e.g. v0.4.1.
+	Version string
+}
+
+func (u *providerUpgrader) InstalledProviders(options InstalledProvidersOptions) ([]InstalledProvider, error) {
+	managementGroups, err := u.providerInventory.GetManagementGroups()
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []InstalledProvider
+	for _, managementGroup := range managementGroups {
+		for _, provider := range managementGroup.Providers {
+			installed := InstalledProvider{
+				Provider:        provider,
+				ManagementGroup: managementGroup.CoreProvider.InstanceName(),
+			}
+			if !options.SkipRepositories {
+				providerUpgradeInfo, err := u.getUpgradeInfo(provider)
+				if err != nil {
+					return nil, err
+				}
+
+				installed.Contract = providerUpgradeInfo.currentContract
+				for _, contract := range providerUpgradeInfo.getContractsForUpgrade() {
+					if nextVersion := providerUpgradeInfo.getLatestNextVersion(contract); nextVersion != nil {
+						installed.AvailableUpgrades = append(installed.AvailableUpgrades, AvailableUpgrade{
+							Contract: contract,
+							Version:  versionTag(nextVersion),
+						})
+					}
+				}
+			}
+			ret = append(ret, installed)
+		}
+	}
+	return ret, nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_providerUpgrader_InstalledProviders(t *testing.T) {
+	reader := test.NewFakeReader().
+		WithProvider("cluster-api", clusterctlv1.CoreProviderType, "https://somewhere.com").
+		WithProvider("infra", clusterctlv1.InfrastructureProviderType, "https://somewhere.com")
+	repositories := map[string]repository.Repository{
+		"cluster-api": test.NewFakeRepository().
+			WithVersions("v1.0.0", "v1.0.1", "v2.0.0").
+			WithMetadata("v2.0.0", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 1, Minor: 0, Contract: "v1alpha3"},
+					{Major: 2, Minor: 0, Contract: "v1alpha4"},
+				},
+			}),
+		"infrastructure-infra": test.NewFakeRepository().
+			WithVersions("v2.0.0").
+			WithMetadata("v2.0.0", &clusterctlv1.Metadata{
+				ReleaseSeries: []clusterctlv1.ReleaseSeries{
+					{Major: 2, Minor: 0, Contract: "v1alpha3"},
+				},
+			}),
+	}
+	proxy := test.NewFakeProxy().
+		WithProviderInventory("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system", "").
+		WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system", "")
+
+	tests := []struct {
+		name    string
+		options InstalledProvidersOptions
+		want    []InstalledProvider
+	}{
+		{
+			name:    "lists the installed providers with their contract and the available upgrades",
+			options: InstalledProvidersOptions{},
+			want: []InstalledProvider{
+				{
+					Provider:        fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system", ""),
+					ManagementGroup: "cluster-api-system/cluster-api",
+					Contract:        "v1alpha3",
+					AvailableUpgrades: []AvailableUpgrade{
+						{Contract: "v1alpha3", Version: "v1.0.1"},
+						{Contract: "v1alpha4", Version: "v2.0.0"},
+					},
+				},
+				{
+					Provider:        fakeProvider("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system", ""),
+					ManagementGroup: "cluster-api-system/cluster-api",
+					Contract:        "v1alpha3",
+				},
+			},
+		},
+		{
+			name:    "lists the installed providers without reading the repositories",
+			options: InstalledProvidersOptions{SkipRepositories: true},
+			want: []InstalledProvider{
+				{
+					Provider:        fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system", ""),
+					ManagementGroup: "cluster-api-system/cluster-api",
+				},
+				{
+					Provider:        fakeProvider("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system", ""),
+					ManagementGroup: "cluster-api-system/cluster-api",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			configClient, _ := config.New("", config.InjectReader(reader))
+
+			u := &providerUpgrader{
+				configClient: configClient,
+				repositoryClientFactory: func(provider config.Provider, configClient config.Client, options ...repository.Option) (repository.Client, error) {
+					return repository.New(provider, configClient, repository.InjectRepository(repositories[provider.ManifestLabel()]))
+				},
+				providerInventory: newInventoryClient(proxy, nil),
+				proxy:             proxy,
+			}
+			got, err := u.InstalledProviders(tt.options)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+)
+
+const (
+	// ManagementClusterVersionSkewRule is the rule requiring the Kubernetes version of the management cluster
+	// to be supported by the API Version of Cluster API (contract) of the providers.
+	ManagementClusterVersionSkewRule = "ManagementClusterVersion"
+)
+
+// kubernetesVersionRange defines the Kubernetes v1 minor versions supported by an API Version of Cluster API (contract).
+type kubernetesVersionRange struct {
+	minMinor, maxMinor uint
+}
+
+func (r kubernetesVersionRange) contains(v *version.Version) bool {
+	return v.Major() == 1 && v.Minor() >= r.minMinor && v.Minor() <= r.maxMinor
+}
+
+func (r kubernetesVersionRange) String() string {
+	return fmt.Sprintf("v1.%d to v1.%d", r.minMinor, r.maxMinor)
+}
+
+// supportedKubernetesVersions are the Kubernetes versions supported for the management cluster by each API Version
+// of Cluster API (contract), as documented in the Cluster API version support policy.
+// Contracts not listed here are not checked.
+var supportedKubernetesVersions = map[string]kubernetesVersionRange{
+	"v1alpha2": {minMinor: 13, maxMinor: 16},
+	"v1alpha3": {minMinor: 16, maxMinor: 19},
+}
+
+// VersionSkewViolation describes a rule of the Kubernetes version skew policy of Cluster API violated by an upgrade plan.
+type VersionSkewViolation struct {
+	// Rule is the violated rule, e.g. ManagementClusterVersion.
+	Rule string
+
+	// Message details the violation.
+	Message string
+}
+
+// versionSkewViolations returns the rules violated by the Kubernetes version of the management cluster when
+// upgrading to the given contract.
+// NOTE: The workload clusters are not checked, given that the objects describing them can be read only through the
+// contract currently installed, and that their Kubernetes version is validated by the providers managing them.
+func versionSkewViolations(managementVersion, contract string) []VersionSkewViolation {
+	supported, ok := supportedKubernetesVersions[contract]
+	if !ok {
+		return nil
+	}
+
+	parsed, err := version.ParseGeneric(managementVersion)
+	if err != nil || supported.contains(parsed) {
+		return nil
+	}
+	return []VersionSkewViolation{
+		{
+			Rule:    ManagementClusterVersionSkewRule,
+			Message: fmt.Sprintf("the management cluster runs Kubernetes %s, while the %s API Version of Cluster API (contract) supports Kubernetes %s", managementVersion, contract, supported),
+		},
+	}
+}
+
+// checkVersionSkew sets the version skew violations of an upgrade plan, and returns an error listing them, if any,
+// unless the user asked to ignore them.
+func (u *providerUpgrader) checkVersionSkew(upgradePlan *UpgradePlan, options UpgradeOptions) error {
+	managementVersion, err := u.proxy.GetServerVersion()
+	if err != nil {
+		return err
+	}
+	upgradePlan.VersionSkewViolations = versionSkewViolations(managementVersion, upgradePlan.Contract)
+	if len(upgradePlan.VersionSkewViolations) == 0 || options.IgnoreVersionSkew {
+		return nil
+	}
+
+	messages := make([]string, 0, len(upgradePlan.VersionSkewViolations))
+	for _, v := range upgradePlan.VersionSkewViolations {
+		messages = append(messages, fmt.Sprintf("%s: %s", v.Rule, v.Message))
+	}
+	return errors.Errorf("unable to complete that upgrade: the upgrade violates the Kubernetes version skew policy of Cluster API:\n- %s\nUse the --ignore-version-skew flag to upgrade anyway", strings.Join(messages, "\n- "))
+}
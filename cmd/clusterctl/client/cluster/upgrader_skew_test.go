@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cluster
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_versionSkewViolations(t *testing.T) {
+	tests := []struct {
+		name              string
+		managementVersion string
+		contract          string
+		wantViolations    []VersionSkewViolation
+	}{
+		{
+			name:              "no violations",
+			managementVersion: "v1.19.1",
+			contract:          "v1alpha3",
+		},
+		{
+			name:              "contracts without documented skew are not checked",
+			managementVersion: "v1.10.0",
+			contract:          "v1alpha4",
+		},
+		{
+			name:              "unparsable versions are not checked",
+			managementVersion: "unknown",
+			contract:          "v1alpha3",
+		},
+		{
+			name:              "management cluster version not supported",
+			managementVersion: "v1.20.2",
+			contract:          "v1alpha3",
+			wantViolations: []VersionSkewViolation{
+				{
+					Rule:    ManagementClusterVersionSkewRule,
+					Message: "the management cluster runs Kubernetes v1.20.2, while the v1alpha3 API Version of Cluster API (contract) supports Kubernetes v1.16 to v1.19",
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(versionSkewViolations(tt.managementVersion, tt.contract)).To(Equal(tt.wantViolations))
+		})
+	}
+}
+
+func Test_providerUpgrader_checkVersionSkew(t *testing.T) {
+	g := NewWithT(t)
+
+	u := &providerUpgrader{
+		proxy: test.NewFakeProxy().WithServerVersion("v1.20.2"),
+	}
+
+	plan := &UpgradePlan{Contract: "v1alpha3"}
+	err := u.checkVersionSkew(plan, UpgradeOptions{})
+	g.Expect(err).To(MatchError(ContainSubstring("ManagementClusterVersion: the management cluster runs Kubernetes v1.20.2")))
+	g.Expect(plan.VersionSkewViolations).To(HaveLen(1))
+
+	// The upgrade is allowed if the user asks to ignore the version skew.
+	plan = &UpgradePlan{Contract: "v1alpha3"}
+	g.Expect(u.checkVersionSkew(plan, UpgradeOptions{IgnoreVersionSkew: true})).To(Succeed())
+	g.Expect(plan.VersionSkewViolations).To(HaveLen(1))
+
+	plan = &UpgradePlan{Contract: "v1alpha4"}
+	g.Expect(u.checkVersionSkew(plan, UpgradeOptions{})).To(Succeed())
+	g.Expect(plan.VersionSkewViolations).To(BeEmpty())
+}
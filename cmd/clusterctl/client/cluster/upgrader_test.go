@@ -17,10 +17,14 @@ limitations under the License.
 package cluster
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
@@ -544,6 +548,7 @@ func Test_providerUpgrader_Plan(t *testing.T) {
 					return repository.New(provider, configClient, repository.InjectRepository(tt.fields.repository[provider.ManifestLabel()]))
 				},
 				providerInventory: newInventoryClient(tt.fields.proxy, nil),
+				proxy:             tt.fields.proxy,
 			}
 			got, err := u.Plan()
 			if tt.wantErr {
@@ -845,3 +850,228 @@ func Test_providerUpgrader_createCustomPlan(t *testing.T) {
 		})
 	}
 }
+
+func Test_providerUpgrader_doUpgrade(t *testing.T) {
+	deploymentYAML := func(available bool) []byte {
+		yaml := "apiVersion: v1\n" +
+			"kind: Namespace\n" +
+			"metadata:\n" +
+			"  name: infra-system\n" +
+			"---\n" +
+			"apiVersion: apps/v1\n" +
+			"kind: Deployment\n" +
+			"metadata:\n" +
+			"  name: infra-controller-manager\n" +
+			"  namespace: infra-system\n"
+		if available {
+			yaml += "status:\n" +
+				"  conditions:\n" +
+				"  - type: Available\n" +
+				"    status: \"True\"\n"
+		}
+		return []byte(yaml)
+	}
+
+	tests := []struct {
+		name          string
+		options       UpgradeOptions
+		nextVersion   string
+		contract      string
+		nextAvailable bool
+		wantErr       string
+		wantVersion   string
+		wantTimeout   time.Duration
+	}{
+		{
+			name:          "upgrade a provider becoming available",
+			nextVersion:   "v2.0.1",
+			contract:      "v1alpha3",
+			nextAvailable: true,
+			wantVersion:   "v2.0.1",
+			wantTimeout:   waitProviderTimeout,
+		},
+		{
+			name:          "wait for a provider to become available with the given timeout",
+			options:       UpgradeOptions{WaitProviderTimeout: 10 * time.Minute},
+			nextVersion:   "v2.0.1",
+			contract:      "v1alpha3",
+			nextAvailable: true,
+			wantVersion:   "v2.0.1",
+			wantTimeout:   10 * time.Minute,
+		},
+		{
+			name:          "roll back a provider not becoming available",
+			nextVersion:   "v2.0.1",
+			contract:      "v1alpha3",
+			nextAvailable: false,
+			wantErr:       "upgrade failed, rolled back providers: [infra-system/infrastructure-infra to v2.0.0]",
+			wantVersion:   "v2.0.0",
+			wantTimeout:   waitProviderTimeout,
+		},
+		{
+			name:          "do not roll back a provider not becoming available if rollback is disabled",
+			options:       UpgradeOptions{NoRollback: true},
+			nextVersion:   "v2.0.1",
+			contract:      "v1alpha3",
+			nextAvailable: false,
+			wantErr:       "Deployment infra-system/infra-controller-manager is not available",
+			wantVersion:   "v2.0.1",
+			wantTimeout:   waitProviderTimeout,
+		},
+		{
+			name:          "do not roll back a provider upgraded to another contract",
+			nextVersion:   "v3.0.0",
+			contract:      "v1alpha4",
+			nextAvailable: false,
+			wantErr:       "the infra-system/infrastructure-infra provider was upgraded from the v1alpha3 to the v1alpha4 contract, and rollbacks are supported only within the same contract",
+			wantVersion:   "v3.0.0",
+			wantTimeout:   waitProviderTimeout,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			reader := test.NewFakeReader().
+				WithProvider("infra", clusterctlv1.InfrastructureProviderType, "https://somewhere.com")
+			repo := test.NewFakeRepository().
+				WithPaths("root", "components.yaml").
+				WithDefaultVersion("v3.0.0").
+				WithVersions("v2.0.0", "v2.0.1", "v3.0.0").
+				WithMetadata("v3.0.0", &clusterctlv1.Metadata{
+					ReleaseSeries: []clusterctlv1.ReleaseSeries{
+						{Major: 2, Minor: 0, Contract: "v1alpha3"},
+						{Major: 3, Minor: 0, Contract: "v1alpha4"},
+					},
+				}).
+				WithFile("v2.0.0", "components.yaml", deploymentYAML(true)).
+				WithFile(tt.nextVersion, "components.yaml", deploymentYAML(tt.nextAvailable))
+			proxy := test.NewFakeProxy().
+				WithProviderInventory("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system", "")
+
+			var timeouts []time.Duration
+			configClient, _ := config.New("", config.InjectReader(reader))
+			u := newProviderUpgrader(proxy, configClient,
+				func(provider config.Provider, configClient config.Client, options ...repository.Option) (repository.Client, error) {
+					return repository.New(provider, configClient, repository.InjectRepository(repo))
+				},
+				newInventoryClient(proxy, nil),
+				newComponentsClient(proxy),
+				func(interval, timeout time.Duration, condition wait.ConditionFunc) error {
+					timeouts = append(timeouts, timeout)
+					ok, err := condition()
+					if err != nil {
+						return err
+					}
+					if !ok {
+						return wait.ErrWaitTimeout
+					}
+					return nil
+				},
+			)
+
+			upgradePlan := &UpgradePlan{
+				Contract:     tt.contract,
+				CoreProvider: fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system", ""),
+				Providers: []UpgradeItem{
+					{
+						Provider:    fakeProvider("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system", ""),
+						NextVersion: tt.nextVersion,
+					},
+				},
+			}
+			err := u.doUpgrade(upgradePlan, tt.options)
+			if tt.wantErr != "" {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err.Error()).To(ContainSubstring(tt.wantErr))
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(timeouts).NotTo(BeEmpty())
+			for _, timeout := range timeouts {
+				g.Expect(timeout).To(Equal(tt.wantTimeout))
+			}
+
+			providers, err := u.providerInventory.List()
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(providers.Items).To(HaveLen(1), fmt.Sprintf("%v", providers.Items))
+			g.Expect(providers.Items[0].Version).To(Equal(tt.wantVersion))
+		})
+	}
+}
+
+func Test_resolveUpgradeItems(t *testing.T) {
+	managementGroup := &ManagementGroup{
+		CoreProvider: fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system", ""),
+		Providers: []clusterctlv1.Provider{
+			fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system", ""),
+			fakeProvider("kubeadm", clusterctlv1.BootstrapProviderType, "v1.0.0", "kubeadm-system", ""),
+			fakeProvider("aws", clusterctlv1.InfrastructureProviderType, "v2.0.0", "tenant1-system", "tenant1"),
+			fakeProvider("aws", clusterctlv1.InfrastructureProviderType, "v2.0.0", "tenant2-system", "tenant2"),
+		},
+	}
+	upgradeItem := func(name string, providerType clusterctlv1.ProviderType, namespace string) UpgradeItem {
+		return UpgradeItem{
+			Provider: clusterctlv1.Provider{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: namespace,
+					Name:      clusterctlv1.ManifestLabel(name, providerType),
+				},
+				ProviderName: name,
+				Type:         string(providerType),
+			},
+			NextVersion: "v1.0.1",
+		}
+	}
+
+	tests := []struct {
+		name         string
+		upgradeItems []UpgradeItem
+		want         []UpgradeItem
+		wantErr      bool
+	}{
+		{
+			name:         "the namespace of a provider with a single instance is resolved",
+			upgradeItems: []UpgradeItem{upgradeItem("kubeadm", clusterctlv1.BootstrapProviderType, "")},
+			want:         []UpgradeItem{upgradeItem("kubeadm", clusterctlv1.BootstrapProviderType, "kubeadm-system")},
+			wantErr:      false,
+		},
+		{
+			name:         "instances pinned to a namespace are kept",
+			upgradeItems: []UpgradeItem{upgradeItem("aws", clusterctlv1.InfrastructureProviderType, "tenant2-system")},
+			want:         []UpgradeItem{upgradeItem("aws", clusterctlv1.InfrastructureProviderType, "tenant2-system")},
+			wantErr:      false,
+		},
+		{
+			name:         "fails if the provider has multiple instances",
+			upgradeItems: []UpgradeItem{upgradeItem("aws", clusterctlv1.InfrastructureProviderType, "")},
+			wantErr:      true,
+		},
+		{
+			name:         "fails if the provider is not part of the management group",
+			upgradeItems: []UpgradeItem{upgradeItem("azure", clusterctlv1.InfrastructureProviderType, "")},
+			wantErr:      true,
+		},
+		{
+			name: "fails if an instance is included more than once",
+			upgradeItems: []UpgradeItem{
+				upgradeItem("kubeadm", clusterctlv1.BootstrapProviderType, ""),
+				upgradeItem("kubeadm", clusterctlv1.BootstrapProviderType, "kubeadm-system"),
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := resolveUpgradeItems(managementGroup, tt.upgradeItems)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -18,6 +18,8 @@ package cluster
 
 import (
 	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
 	utilkubeconfig "sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -28,11 +30,21 @@ type WorkloadCluster interface {
 	GetKubeconfig(workloadClusterName string, namespace string) (string, error)
 }
 
+// WorkloadClusterRESTConfigGetter is implemented by the WorkloadClusters that can return the rest.Config for
+// accessing a workload cluster.
+type WorkloadClusterRESTConfigGetter interface {
+	// GetRESTConfig returns the rest.Config for accessing the workload cluster, without writing its kubeconfig to disk.
+	GetRESTConfig(workloadClusterName string, namespace string) (*rest.Config, error)
+}
+
 // workloadCluster implements WorkloadCluster.
 type workloadCluster struct {
 	proxy Proxy
 }
 
+// ensure workloadCluster implements WorkloadClusterRESTConfigGetter.
+var _ WorkloadClusterRESTConfigGetter = &workloadCluster{}
+
 // newWorkloadCluster returns a workloadCluster.
 func newWorkloadCluster(proxy Proxy) *workloadCluster {
 	return &workloadCluster{
@@ -56,3 +68,16 @@ func (p *workloadCluster) GetKubeconfig(workloadClusterName string, namespace st
 	}
 	return string(dataBytes), nil
 }
+
+func (p *workloadCluster) GetRESTConfig(workloadClusterName string, namespace string) (*rest.Config, error) {
+	kubeconfig, err := p.GetKubeconfig(workloadClusterName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	config, err := clientcmd.RESTConfigFromKubeConfig([]byte(kubeconfig))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse the kubeconfig of the workload cluster %q in namespace %q", workloadClusterName, namespace)
+	}
+	return config, nil
+}
@@ -98,3 +98,77 @@ users:
 	}
 
 }
+
+func Test_WorkloadCluster_GetRESTConfig(t *testing.T) {
+	kubeconfigSecret := func(kubeconfig string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test1-kubeconfig",
+				Namespace: "test",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "test1"},
+			},
+			Data: map[string][]byte{
+				secret.KubeconfigDataName: []byte(kubeconfig),
+			},
+		}
+	}
+
+	validKubeConfig := `
+clusters:
+- cluster:
+    server: https://test-cluster-api:6443
+  name: test1
+contexts:
+- context:
+    cluster: test1
+    user: test1-admin
+  name: test1-admin@test1
+current-context: test1-admin@test1
+kind: Config
+preferences: {}
+users:
+- name: test1-admin
+  user:
+    token: test1-token
+`
+
+	tests := []struct {
+		name      string
+		expectErr bool
+		proxy     Proxy
+	}{
+		{
+			name:      "return the rest config",
+			expectErr: false,
+			proxy:     test.NewFakeProxy().WithObjs(kubeconfigSecret(validKubeConfig)),
+		},
+		{
+			name:      "return error if the kubeconfig is invalid",
+			expectErr: true,
+			proxy:     test.NewFakeProxy().WithObjs(kubeconfigSecret("invalid")),
+		},
+		{
+			name:      "return error if cannot find secret",
+			expectErr: true,
+			proxy:     test.NewFakeProxy(),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			wc := newWorkloadCluster(tt.proxy)
+			config, err := wc.GetRESTConfig("test1", "test")
+
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(config.Host).To(Equal("https://test-cluster-api:6443"))
+			g.Expect(config.BearerToken).To(Equal("test1-token"))
+		})
+	}
+}
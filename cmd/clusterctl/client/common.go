@@ -21,6 +21,7 @@ import (
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/version"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 )
@@ -86,6 +87,39 @@ func parseProviderName(provider string) (name string, version string, err error)
 	return name, version, nil
 }
 
+// parseProviderInstance parses the abbreviated syntax for name[:version][:namespace], which allows to install
+// multiple instances of the same provider, each one in its own namespace and watching only that namespace.
+// It returns the provider in the form name[:version] and the namespace for the provider instance, if any.
+// NB. When only two segments are provided, the second one is considered a version if it is a valid semantic
+// version (e.g. v0.5.0), a namespace otherwise.
+func parseProviderInstance(provider string) (nameAndVersion string, namespace string, err error) {
+	t := strings.Split(strings.ToLower(provider), ":")
+	switch {
+	case len(t) > 3:
+		return "", "", errors.Errorf("invalid provider name %q. Provider name should be in the form name[:version][:namespace]", provider)
+	case len(t) == 3:
+		if !isProviderVersion(t[1]) {
+			return "", "", errors.Errorf("invalid provider name %q. Provider name should be in the form name[:version][:namespace] and version should be valid", provider)
+		}
+		nameAndVersion, namespace = strings.Join(t[:2], ":"), t[2]
+	case len(t) == 2 && !isProviderVersion(t[1]):
+		nameAndVersion, namespace = t[0], t[1]
+	default:
+		return provider, "", nil
+	}
+
+	if err := validateDNS1123Label(namespace); err != nil {
+		return "", "", errors.Wrapf(err, "invalid provider name %q. Provider name should be in the form name[:version][:namespace] and the namespace should be valid", provider)
+	}
+	return nameAndVersion, namespace, nil
+}
+
+// isProviderVersion returns true if the given value is a valid semantic version.
+func isProviderVersion(value string) bool {
+	_, err := version.ParseSemantic(value)
+	return err == nil
+}
+
 func validateDNS1123Label(label string) error {
 	errs := validation.IsDNS1123Label(label)
 	if len(errs) != 0 {
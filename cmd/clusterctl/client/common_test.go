@@ -68,3 +68,65 @@ func Test_parseProviderName(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseProviderInstance(t *testing.T) {
+	tests := []struct {
+		name               string
+		provider           string
+		wantNameAndVersion string
+		wantNamespace      string
+		wantErr            bool
+	}{
+		{
+			name:               "simple name",
+			provider:           "provider",
+			wantNameAndVersion: "provider",
+		},
+		{
+			name:               "name & version",
+			provider:           "provider:v1.0.0",
+			wantNameAndVersion: "provider:v1.0.0",
+		},
+		{
+			name:               "name & namespace",
+			provider:           "provider:ns1",
+			wantNameAndVersion: "provider",
+			wantNamespace:      "ns1",
+		},
+		{
+			name:               "name & version & namespace",
+			provider:           "provider:v1.0.0:ns1",
+			wantNameAndVersion: "provider:v1.0.0",
+			wantNamespace:      "ns1",
+		},
+		{
+			name:     "fails for an invalid version",
+			provider: "provider:ns1:ns2",
+			wantErr:  true,
+		},
+		{
+			name:     "fails for an invalid namespace",
+			provider: "provider:v1.0.0:ns_1",
+			wantErr:  true,
+		},
+		{
+			name:     "fails for too many segments",
+			provider: "provider:v1.0.0:ns1:ns2",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			gotNameAndVersion, gotNamespace, err := parseProviderInstance(tt.provider)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(gotNameAndVersion).To(Equal(tt.wantNameAndVersion))
+			g.Expect(gotNamespace).To(Equal(tt.wantNamespace))
+		})
+	}
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package client
 
 import (
+	"fmt"
 	"io"
 	"io/ioutil"
 	"strconv"
@@ -27,6 +28,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/version"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
 )
@@ -61,6 +63,85 @@ func (c *clusterctlClient) GetProviderComponents(provider string, providerType c
 	return components, nil
 }
 
+// ProviderComponentsDescriber exposes the troubleshooting of the provider configuration; it is not part of Client,
+// so the implementations of Client are not required to support it.
+type ProviderComponentsDescriber interface {
+	// DescribeProviderComponents returns where the components of a given provider would be read from, i.e. the
+	// provider repository, the version and the override files.
+	DescribeProviderComponents(provider string, providerType clusterctlv1.ProviderType) (ProviderComponentsDescription, error)
+}
+
+// Ensure clusterctlClient implements ProviderComponentsDescriber.
+var _ ProviderComponentsDescriber = &clusterctlClient{}
+
+// NewProviderComponentsDescriber returns a ProviderComponentsDescriber.
+func NewProviderComponentsDescriber(path string, options ...Option) (ProviderComponentsDescriber, error) {
+	return newClusterctlClient(path, options...)
+}
+
+func (c *clusterctlClient) DescribeProviderComponents(provider string, providerType clusterctlv1.ProviderType) (ProviderComponentsDescription, error) {
+	name, version, err := parseProviderName(provider)
+	if err != nil {
+		return ProviderComponentsDescription{}, err
+	}
+
+	providerConfig, err := c.configClient.Providers().Get(name, providerType)
+	if err != nil {
+		return ProviderComponentsDescription{}, err
+	}
+
+	providersClient, ok := c.configClient.Providers().(config.UserDefinedProvidersClient)
+	if !ok {
+		return ProviderComponentsDescription{}, errors.New("the providers configuration client does not support describing providers")
+	}
+	userDefined, err := providersClient.IsUserDefined(name, providerType)
+	if err != nil {
+		return ProviderComponentsDescription{}, err
+	}
+
+	repositoryClient, err := c.repositoryClientFactory(RepositoryClientFactoryInput{Provider: providerConfig})
+	if err != nil {
+		return ProviderComponentsDescription{}, err
+	}
+
+	componentsClient, ok := repositoryClient.Components().(repository.ComponentsDescriber)
+	if !ok {
+		return ProviderComponentsDescription{}, errors.Errorf("the repository client for the %s with name %s does not support describing components", providerType, name)
+	}
+	d, err := componentsClient.Describe(repository.ComponentsOptions{Version: version})
+	if err != nil {
+		return ProviderComponentsDescription{}, err
+	}
+
+	out := ProviderComponentsDescription{
+		Name:                  providerConfig.Name(),
+		Type:                  string(providerConfig.Type()),
+		URL:                   providerConfig.URL(),
+		URLSource:             DescriptionSourceDefault,
+		Version:               d.Version,
+		VersionResolution:     d.VersionResolution,
+		OverridesFolder:       d.OverridesFolder,
+		OverridesFolderSource: DescriptionSourceDefault,
+		Files:                 []ProviderFileDescription{},
+	}
+	if userDefined {
+		out.URLSource = DescriptionSourceConfig
+	}
+	if d.OverridesFolderFromConfig {
+		out.OverridesFolderSource = DescriptionSourceConfig
+	}
+	for _, f := range d.Files {
+		out.Files = append(out.Files, ProviderFileDescription{
+			Name:         f.Name,
+			Source:       string(f.Source),
+			OverridePath: f.OverridePath,
+			Found:        f.Found,
+			Error:        f.Error,
+		})
+	}
+	return out, nil
+}
+
 // ReaderSourceOptions define the options to be used when reading a template
 // from an arbitrary reader
 type ReaderSourceOptions struct {
@@ -152,6 +233,11 @@ type GetClusterTemplateOptions struct {
 	// It can be set through the cli flag, WORKER_MACHINE_COUNT environment variable or will default to 0
 	WorkerMachineCount *int64
 
+	// WorkerGroups defines the groups of worker machines to be added to the workload cluster, each one with its own
+	// name, number of machines and Kubernetes version. If set, the MachineDeployment defined in the template is replaced
+	// by one MachineDeployment for each group; it can't be used together with WorkerMachineCount.
+	WorkerGroups []WorkerGroup
+
 	// ListVariablesOnly sets the GetClusterTemplate method to return the list of variables expected by the template
 	// without executing any further processing.
 	ListVariablesOnly bool
@@ -159,6 +245,10 @@ type GetClusterTemplateOptions struct {
 	// YamlProcessor defines the yaml processor to use for the cluster
 	// template processing. If not defined, SimpleProcessor will be used.
 	YamlProcessor Processor
+
+	// LocalRepositoryCache is the folder of the local repository cache; if set, provider repositories are read
+	// from the cache only, without accessing the network. See SyncRepositoryCache for populating the cache.
+	LocalRepositoryCache string
 }
 
 // numSources return the number of template sources currently set on a GetClusterTemplateOptions.
@@ -223,6 +313,11 @@ func (c *clusterctlClient) GetClusterTemplate(options GetClusterTemplateOptions)
 		options.ProviderRepositorySource = &ProviderRepositorySourceOptions{}
 	}
 
+	// If a local repository cache is provided, read the provider repositories from the cache only.
+	if options.LocalRepositoryCache != "" {
+		c = c.withLocalRepositoryCache(options.LocalRepositoryCache)
+	}
+
 	// Gets  the client for the current management cluster
 	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{options.Kubeconfig, options.YamlProcessor})
 	if err != nil {
@@ -241,23 +336,64 @@ func (c *clusterctlClient) GetClusterTemplate(options GetClusterTemplateOptions)
 		options.TargetNamespace = currentNamespace
 	}
 
+	if err := validateWorkerGroups(options); err != nil {
+		return nil, err
+	}
+
 	// Inject some of the templateOptions into the configClient so they can be consumed as a variables from the template.
 	if err := c.templateOptionsToVariables(options); err != nil {
 		return nil, err
 	}
 
 	// Gets the workload cluster template from the selected source
-	if options.ProviderRepositorySource != nil {
-		return c.getTemplateFromRepository(cluster, options)
+	var template Template
+	switch {
+	case options.ProviderRepositorySource != nil:
+		template, err = c.getTemplateFromRepository(cluster, options)
+	case options.ConfigMapSource != nil:
+		template, err = c.getTemplateFromConfigMap(cluster, *options.ConfigMapSource, options.TargetNamespace, options.ListVariablesOnly)
+	case options.URLSource != nil:
+		template, err = c.getTemplateFromURL(cluster, *options.URLSource, options.TargetNamespace, options.ListVariablesOnly)
+	default:
+		return nil, errors.New("unable to read custom template. Please specify a template source")
 	}
-	if options.ConfigMapSource != nil {
-		return c.getTemplateFromConfigMap(cluster, *options.ConfigMapSource, options.TargetNamespace, options.ListVariablesOnly)
+	if err != nil {
+		return nil, err
 	}
-	if options.URLSource != nil {
-		return c.getTemplateFromURL(cluster, *options.URLSource, options.TargetNamespace, options.ListVariablesOnly)
+
+	// Generates a MachineDeployment for each worker group, if any.
+	if len(options.WorkerGroups) == 0 || options.ListVariablesOnly {
+		return template, nil
 	}
+	groups := make([]repository.WorkerGroup, 0, len(options.WorkerGroups))
+	for _, g := range options.WorkerGroups {
+		groups = append(groups, repository.WorkerGroup(g))
+	}
+	return repository.NewTemplateWithWorkerGroups(template, options.ClusterName, groups)
+}
 
-	return nil, errors.New("unable to read custom template. Please specify a template source")
+// validateWorkerGroups validates the worker groups of the templateOptions.
+func validateWorkerGroups(options GetClusterTemplateOptions) error {
+	if len(options.WorkerGroups) == 0 {
+		return nil
+	}
+	if options.WorkerMachineCount != nil {
+		return errors.New("invalid worker groups: WorkerGroups and WorkerMachineCount can't be used together")
+	}
+	for _, g := range options.WorkerGroups {
+		if err := validateDNS1123Domanin(fmt.Sprintf("%s-%s", options.ClusterName, g.Name)); err != nil {
+			return errors.Wrapf(err, "invalid name for worker group %q", g.Name)
+		}
+		if g.Replicas < 0 {
+			return errors.Errorf("invalid replicas for worker group %q. Please use a number greater or equal than 0", g.Name)
+		}
+		if g.KubernetesVersion != "" {
+			if _, err := version.ParseSemantic(g.KubernetesVersion); err != nil {
+				return errors.Errorf("invalid KubernetesVersion for worker group %q. Please use a semantic version number", g.Name)
+			}
+		}
+	}
+	return nil
 }
 
 // getTemplateFromRepository returns a workload cluster template from a provider repository.
@@ -25,6 +25,7 @@ import (
 // 1. The configuration of the providers (name, type and URL of the provider repository)
 // 2. Variables used when installing providers/creating clusters. Variables can be read from the environment or from the config file
 // 3. The configuration about image overrides
+// 4. The configuration of the move operation (e.g. additional objects to be moved)
 type Client interface {
 	// Providers provide access to provider configurations.
 	Providers() ProvidersClient
@@ -34,6 +35,9 @@ type Client interface {
 
 	// ImageMeta provide access to to image meta configurations.
 	ImageMeta() ImageMetaClient
+
+	// Move provide access to move configurations.
+	Move() MoveClient
 }
 
 // configClient implements Client.
@@ -56,6 +60,10 @@ func (c *configClient) ImageMeta() ImageMetaClient {
 	return newImageMetaClient(c.reader)
 }
 
+func (c *configClient) Move() MoveClient {
+	return newMoveClient(c.reader)
+}
+
 // Option is a configuration option supplied to New
 type Option func(*configClient)
 
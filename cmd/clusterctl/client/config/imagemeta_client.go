@@ -33,6 +33,10 @@ const (
 type ImageMetaClient interface {
 	// AlterImage alters an image name according to the current image override configurations.
 	AlterImage(component, image string) (string, error)
+
+	// AlterContainerImage alters the image name of a container according to the current image override configurations,
+	// including the ones defined for the container name.
+	AlterContainerImage(component, container, image string) (string, error)
 }
 
 // imageMetaClient implements ImageMetaClient.
@@ -52,13 +56,17 @@ func newImageMetaClient(reader Reader) *imageMetaClient {
 }
 
 func (p *imageMetaClient) AlterImage(component, imageString string) (string, error) {
+	return p.AlterContainerImage(component, "", imageString)
+}
+
+func (p *imageMetaClient) AlterContainerImage(component, containerName, imageString string) (string, error) {
 	image, err := container.ImageFromString(imageString)
 	if err != nil {
 		return "", err
 	}
 
-	// Gets the image meta that applies to the selected component/image; if none, returns early
-	meta, err := p.getImageMeta(component, image.Name)
+	// Gets the image meta that applies to the selected component/image/container; if none, returns early
+	meta, err := p.getImageMeta(component, image.Name, containerName)
 	if err != nil {
 		return "", err
 	}
@@ -66,14 +74,23 @@ func (p *imageMetaClient) AlterImage(component, imageString string) (string, err
 		return imageString, nil
 	}
 
-	// Apply the image meta to image name
-	return meta.ApplyToImage(image), nil
+	// Apply the image meta to image name, and ensure the result is a valid image reference
+	altered := meta.ApplyToImage(image)
+	if _, err := container.ImageFromString(altered); err != nil {
+		return "", errors.Wrapf(err, "invalid image override for %s", imageMetaCacheKey(component, image.Name))
+	}
+	return altered, nil
 }
 
-// getImageMeta returns the image meta that applies to the selected component/image
-func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta, error) {
+// getImageMeta returns the image meta that applies to the selected component/image/container
+func (p *imageMetaClient) getImageMeta(component, imageName, containerName string) (*imageMeta, error) {
+	cacheKey := imageMetaCacheKey(component, imageName)
+	if containerName != "" {
+		cacheKey = fmt.Sprintf("%s#%s", cacheKey, containerName)
+	}
+
 	// if the image meta for the component is already known, return it
-	if im, ok := p.imageMetaCache[imageMetaCacheKey(component, imageName)]; ok {
+	if im, ok := p.imageMetaCache[cacheKey]; ok {
 		return im, nil
 	}
 
@@ -85,7 +102,7 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 
 	// If there are not image override configurations, return.
 	if meta == nil {
-		p.imageMetaCache[imageMetaCacheKey(component, imageName)] = nil
+		p.imageMetaCache[cacheKey] = nil
 		return nil, nil
 	}
 
@@ -93,6 +110,7 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 	//	- all the components,
 	//	- the component (and to all its images)
 	//	- the selected component/image
+	//	- the selected component/container, e.g. cluster-api/manager or cluster-api/kube-rbac-proxy
 	//	and returns the union of all the above.
 	m := &imageMeta{}
 	if allMeta, ok := meta[allImageConfig]; ok {
@@ -102,12 +120,17 @@ func (p *imageMetaClient) getImageMeta(component, imageName string) (*imageMeta,
 	if componentMeta, ok := meta[component]; ok {
 		m.Union(&componentMeta)
 	}
-	p.imageMetaCache[component] = m
 
 	if imageNameMeta, ok := meta[imageMetaCacheKey(component, imageName)]; ok {
 		m.Union(&imageNameMeta)
 	}
-	p.imageMetaCache[imageMetaCacheKey(component, imageName)] = m
+
+	if containerName != "" && containerName != imageName {
+		if containerMeta, ok := meta[imageMetaCacheKey(component, containerName)]; ok {
+			m.Union(&containerMeta)
+		}
+	}
+	p.imageMetaCache[cacheKey] = m
 
 	return m, nil
 }
@@ -123,6 +146,10 @@ type imageMeta struct {
 
 	// Tag allows to specify a tag for the images.
 	Tag string `json:"tag,omitempty"`
+
+	// Digest allows to pin the images to a digest, e.g. sha256:<hex>; a digest set together with a tag is
+	// used in place of the tag when pulling the images.
+	Digest string `json:"digest,omitempty"`
 }
 
 // Union allows to merge two imageMeta transformation; in case both the imageMeta defines new values for the same field,
@@ -133,6 +160,11 @@ func (i *imageMeta) Union(other *imageMeta) {
 	}
 	if other.Tag != "" {
 		i.Tag = other.Tag
+		// A digest pins the content of a specific tag, so it is not inherited when the tag is overridden.
+		i.Digest = ""
+	}
+	if other.Digest != "" {
+		i.Digest = other.Digest
 	}
 }
 
@@ -144,6 +176,11 @@ func (i *imageMeta) ApplyToImage(image container.Image) string {
 	}
 	if i.Tag != "" {
 		image.Tag = i.Tag
+		// The digest of the original image pins the content of the original tag, so it is dropped.
+		image.Digest = ""
+	}
+	if i.Digest != "" {
+		image.Digest = i.Digest
 	}
 
 	// returns the resulting image name
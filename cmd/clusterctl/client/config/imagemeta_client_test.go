@@ -256,3 +256,99 @@ func Test_imageMetaClient_AlterImage(t *testing.T) {
 		})
 	}
 }
+
+func Test_imageMetaClient_AlterContainerImage(t *testing.T) {
+	digest := "sha256:0e5b6d3fc9f4ef4b3d3a5d9f0d2e3bd64c5a23a8e7c9e0f0a39f4ce1d7c1f3a2"
+
+	type args struct {
+		component string
+		container string
+		image     string
+	}
+	tests := []struct {
+		name    string
+		reader  Reader
+		args    args
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "image config for cluster-api/manager: image for the manager container should be changed",
+			reader: test.NewFakeReader().WithImageMeta("cluster-api/manager", "foo-repository.io", "foo-tag"),
+			args: args{
+				component: "cluster-api",
+				container: "manager",
+				image:     "gcr.io/k8s-staging-cluster-api/cluster-api-controller:v0.4.0",
+			},
+			want: "foo-repository.io/cluster-api-controller:foo-tag",
+		},
+		{
+			name:   "image config for cluster-api/manager: image for the kube-rbac-proxy container should not be changed",
+			reader: test.NewFakeReader().WithImageMeta("cluster-api/manager", "foo-repository.io", "foo-tag"),
+			args: args{
+				component: "cluster-api",
+				container: "kube-rbac-proxy",
+				image:     "gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0",
+			},
+			want: "gcr.io/kubebuilder/kube-rbac-proxy:v0.8.0",
+		},
+		{
+			name: "image config for cluster-api/manager and for cluster-api/cluster-api-controller: the container config takes precedence",
+			reader: test.NewFakeReader().
+				WithImageMeta("cluster-api/cluster-api-controller", "foo-repository.io", "foo-tag").
+				WithImageMeta("cluster-api/manager", "", "bar-tag"),
+			args: args{
+				component: "cluster-api",
+				container: "manager",
+				image:     "gcr.io/k8s-staging-cluster-api/cluster-api-controller:v0.4.0",
+			},
+			want: "foo-repository.io/cluster-api-controller:bar-tag",
+		},
+		{
+			name:   "image config with digest: image should be pinned to the digest",
+			reader: test.NewFakeReader().WithImageMeta("cluster-api/manager", "foo-repository.io", "").WithImageMetaDigest("cluster-api/manager", digest),
+			args: args{
+				component: "cluster-api",
+				container: "manager",
+				image:     "gcr.io/k8s-staging-cluster-api/cluster-api-controller:v0.4.0",
+			},
+			want: "foo-repository.io/cluster-api-controller:v0.4.0@" + digest,
+		},
+		{
+			name:   "image config with tag: the digest of the image should be dropped",
+			reader: test.NewFakeReader().WithImageMeta("cluster-api", "", "foo-tag"),
+			args: args{
+				component: "cluster-api",
+				container: "manager",
+				image:     "gcr.io/k8s-staging-cluster-api/cluster-api-controller:v0.4.0@" + digest,
+			},
+			want: "gcr.io/k8s-staging-cluster-api/cluster-api-controller:foo-tag",
+		},
+		{
+			name:   "fails if invalid digest",
+			reader: test.NewFakeReader().WithImageMetaDigest("cluster-api", "invalid"),
+			args: args{
+				component: "cluster-api",
+				container: "manager",
+				image:     "gcr.io/k8s-staging-cluster-api/cluster-api-controller:v0.4.0",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			p := newImageMetaClient(tt.reader)
+
+			got, err := p.AlterContainerImage(tt.args.component, tt.args.container, tt.args.image)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	moveConfigKey = "move"
+)
+
+// MoveAdditionalObject defines a set of objects, not managed by Cluster API, to be included in a move operation;
+// e.g. cloud credential Secrets or IPAM pools.
+type MoveAdditionalObject struct {
+	// APIVersion of the objects, e.g. v1 or ipam.example.com/v1alpha1.
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// Kind of the objects, e.g. Secret or IPPool.
+	Kind string `json:"kind,omitempty"`
+
+	// Selector selects the objects of the given kind to be moved. An empty selector selects all the objects.
+	Selector metav1.LabelSelector `json:"selector,omitempty"`
+}
+
+// moveConfig defines the move configurations.
+type moveConfig struct {
+	AdditionalObjects []MoveAdditionalObject `json:"additionalObjects,omitempty"`
+}
+
+// MoveClient has methods to work with move configurations.
+type MoveClient interface {
+	// AdditionalObjects returns the objects, not managed by Cluster API, to be included in a move operation.
+	AdditionalObjects() ([]MoveAdditionalObject, error)
+}
+
+// moveClient implements MoveClient.
+type moveClient struct {
+	reader Reader
+}
+
+// ensure moveClient implements MoveClient.
+var _ MoveClient = &moveClient{}
+
+func newMoveClient(reader Reader) *moveClient {
+	return &moveClient{
+		reader: reader,
+	}
+}
+
+func (p *moveClient) AdditionalObjects() ([]MoveAdditionalObject, error) {
+	config := moveConfig{}
+	if err := p.reader.UnmarshalKey(moveConfigKey, &config); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal move configurations")
+	}
+
+	for i, o := range config.AdditionalObjects {
+		if o.Kind == "" {
+			return nil, errors.Errorf("invalid move configuration: additionalObjects[%d].kind must be set", i)
+		}
+		if _, err := schema.ParseGroupVersion(o.APIVersion); err != nil || o.APIVersion == "" {
+			return nil, errors.Errorf("invalid move configuration: additionalObjects[%d].apiVersion %q is not a valid API version", i, o.APIVersion)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(&config.AdditionalObjects[i].Selector); err != nil {
+			return nil, errors.Wrapf(err, "invalid move configuration: additionalObjects[%d].selector is not a valid label selector", i)
+		}
+	}
+	return config.AdditionalObjects, nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_moveClient_AdditionalObjects(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  string
+		want    []MoveAdditionalObject
+		wantErr bool
+	}{
+		{
+			name: "no move config",
+			want: nil,
+		},
+		{
+			name: "additional objects",
+			config: `
+additionalObjects:
+- apiVersion: v1
+  kind: Secret
+  selector:
+    matchLabels:
+      example.com/credentials: ""
+- apiVersion: ipam.example.com/v1alpha1
+  kind: IPPool
+`,
+			want: []MoveAdditionalObject{
+				{
+					APIVersion: "v1",
+					Kind:       "Secret",
+					Selector:   metav1.LabelSelector{MatchLabels: map[string]string{"example.com/credentials": ""}},
+				},
+				{
+					APIVersion: "ipam.example.com/v1alpha1",
+					Kind:       "IPPool",
+				},
+			},
+		},
+		{
+			name: "fails if kind is missing",
+			config: `
+additionalObjects:
+- apiVersion: v1
+`,
+			wantErr: true,
+		},
+		{
+			name: "fails if apiVersion is not valid",
+			config: `
+additionalObjects:
+- apiVersion: ipam.example.com/v1alpha1/v1
+  kind: IPPool
+`,
+			wantErr: true,
+		},
+		{
+			name: "fails if selector is not valid",
+			config: `
+additionalObjects:
+- apiVersion: v1
+  kind: Secret
+  selector:
+    matchExpressions:
+    - key: foo
+      operator: Unknown
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			reader := test.NewFakeReader()
+			if tt.config != "" {
+				reader.WithVar(moveConfigKey, tt.config)
+			}
+
+			got, err := newMoveClient(reader).AdditionalObjects()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -68,6 +68,14 @@ type ProvidersClient interface {
 	Get(name string, providerType clusterctlv1.ProviderType) (Provider, error)
 }
 
+// UserDefinedProvidersClient is implemented by the ProvidersClients that can tell where a provider configuration
+// is read from.
+type UserDefinedProvidersClient interface {
+	// IsUserDefined returns true if the configuration for the provider with a given name/type is read from the
+	// clusterctl configuration file, either adding a new provider or overriding a hard-coded one.
+	IsUserDefined(name string, providerType clusterctlv1.ProviderType) (bool, error)
+}
+
 // providersClient implements ProvidersClient.
 type providersClient struct {
 	reader Reader
@@ -76,6 +84,9 @@ type providersClient struct {
 // ensure providersClient implements ProvidersClient.
 var _ ProvidersClient = &providersClient{}
 
+// ensure providersClient implements UserDefinedProvidersClient.
+var _ UserDefinedProvidersClient = &providersClient{}
+
 func newProvidersClient(reader Reader) *providersClient {
 	return &providersClient{
 		reader: reader,
@@ -199,18 +210,12 @@ func (p *providersClient) List() ([]Provider, error) {
 
 	// Gets user defined provider configurations, validate them, and merges with
 	// hard-coded configurations handling conflicts (user defined take precedence on hard-coded)
-
-	userDefinedProviders := []configProvider{}
-	if err := p.reader.UnmarshalKey(ProvidersConfigKey, &userDefinedProviders); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal providers from the clusterctl configuration file")
+	userDefinedProviders, err := p.userDefined()
+	if err != nil {
+		return nil, err
 	}
 
-	for _, u := range userDefinedProviders {
-		provider := NewProvider(u.Name, u.URL, u.Type)
-		if err := validateProvider(provider); err != nil {
-			return nil, errors.Wrapf(err, "error validating configuration for the %s with name %s. Please fix the providers value in clusterctl configuration file", provider.Type(), provider.Name())
-		}
-
+	for _, provider := range userDefinedProviders {
 		override := false
 		for i := range providers {
 			if providers[i].SameAs(provider) {
@@ -232,6 +237,24 @@ func (p *providersClient) List() ([]Provider, error) {
 	return providers, nil
 }
 
+// userDefined returns the validated provider configurations read from the clusterctl configuration file.
+func (p *providersClient) userDefined() ([]Provider, error) {
+	userDefinedProviders := []configProvider{}
+	if err := p.reader.UnmarshalKey(ProvidersConfigKey, &userDefinedProviders); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal providers from the clusterctl configuration file")
+	}
+
+	providers := make([]Provider, 0, len(userDefinedProviders))
+	for _, u := range userDefinedProviders {
+		provider := NewProvider(u.Name, u.URL, u.Type)
+		if err := validateProvider(provider); err != nil {
+			return nil, errors.Wrapf(err, "error validating configuration for the %s with name %s. Please fix the providers value in clusterctl configuration file", provider.Type(), provider.Name())
+		}
+		providers = append(providers, provider)
+	}
+	return providers, nil
+}
+
 func (p *providersClient) Get(name string, providerType clusterctlv1.ProviderType) (Provider, error) {
 	l, err := p.List()
 	if err != nil {
@@ -248,6 +271,21 @@ func (p *providersClient) Get(name string, providerType clusterctlv1.ProviderTyp
 	return nil, errors.Errorf("failed to get configuration for the %s with name %s. Please check the provider name and/or add configuration for new providers using the .clusterctl config file", providerType, name)
 }
 
+func (p *providersClient) IsUserDefined(name string, providerType clusterctlv1.ProviderType) (bool, error) {
+	userDefinedProviders, err := p.userDefined()
+	if err != nil {
+		return false, err
+	}
+
+	provider := NewProvider(name, "", providerType)
+	for _, u := range userDefinedProviders {
+		if u.SameAs(provider) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func validateProvider(r Provider) error {
 	if r.Name() == "" {
 		return errors.New("name value cannot be empty")
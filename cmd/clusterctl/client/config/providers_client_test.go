@@ -331,3 +331,47 @@ func Test_providers_Get(t *testing.T) {
 		})
 	}
 }
+
+func Test_providers_IsUserDefined(t *testing.T) {
+	reader := test.NewFakeReader().
+		WithProvider(ClusterAPIProviderName, clusterctlv1.CoreProviderType, "https://github.com/myorg/myforkofclusterapi/releases/latest/core_components.yaml").
+		WithProvider("foo", clusterctlv1.InfrastructureProviderType, "https://github.com/foo/infrastructure-foo/releases/latest/infrastructure-components.yaml")
+
+	tests := []struct {
+		name         string
+		providerName string
+		providerType clusterctlv1.ProviderType
+		want         bool
+	}{
+		{
+			name:         "hard-coded provider",
+			providerName: KubeadmBootstrapProviderName,
+			providerType: clusterctlv1.BootstrapProviderType,
+			want:         false,
+		},
+		{
+			name:         "hard-coded provider overridden in the configuration file",
+			providerName: ClusterAPIProviderName,
+			providerType: clusterctlv1.CoreProviderType,
+			want:         true,
+		},
+		{
+			name:         "provider added in the configuration file",
+			providerName: "foo",
+			providerType: clusterctlv1.InfrastructureProviderType,
+			want:         true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			p := &providersClient{
+				reader: reader,
+			}
+			got, err := p.IsUserDefined(tt.providerName, tt.providerType)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -195,6 +195,57 @@ func Test_clusterctlClient_GetProviderComponents(t *testing.T) {
 	}
 }
 
+func Test_clusterctlClient_DescribeProviderComponents(t *testing.T) {
+	g := NewWithT(t)
+
+	overridesFolder, err := ioutil.TempDir("", "overrides")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(overridesFolder)
+
+	overridePath := filepath.Join(overridesFolder, "cluster-api", "v1.0.0", "components.yaml")
+	g.Expect(os.MkdirAll(filepath.Dir(overridePath), 0755)).To(Succeed())
+	g.Expect(ioutil.WriteFile(overridePath, componentsYAML("ns1"), 0600)).To(Succeed())
+
+	config1 := newFakeConfig().
+		WithVar("overridesFolder", overridesFolder).
+		WithProvider(capiProviderConfig)
+
+	repository1 := newFakeRepository(capiProviderConfig, config1).
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v1.0.0").
+		WithFile("v1.0.0", "components.yaml", componentsYAML("ns1"))
+
+	client := newFakeClient(config1).
+		WithRepository(repository1)
+
+	got, err := client.internalClient.DescribeProviderComponents(capiProviderConfig.Name(), capiProviderConfig.Type())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got).To(Equal(ProviderComponentsDescription{
+		Name:                  capiProviderConfig.Name(),
+		Type:                  string(capiProviderConfig.Type()),
+		URL:                   capiProviderConfig.URL(),
+		URLSource:             DescriptionSourceConfig,
+		Version:               "v1.0.0",
+		VersionResolution:     "version from the repository URL",
+		OverridesFolder:       overridesFolder,
+		OverridesFolderSource: DescriptionSourceConfig,
+		Files: []ProviderFileDescription{
+			{
+				Name:         "components.yaml",
+				Source:       "override",
+				OverridePath: overridePath,
+				Found:        true,
+			},
+			{
+				Name:         "metadata.yaml",
+				Source:       "repository",
+				OverridePath: filepath.Join(overridesFolder, "cluster-api", "v1.0.0", "metadata.yaml"),
+				Error:        "unable to get file metadata.yaml for version v1.0.0",
+			},
+		},
+	}))
+}
+
 func Test_getComponentsByName_withEmptyVariables(t *testing.T) {
 	g := NewWithT(t)
 
@@ -613,6 +664,77 @@ func Test_clusterctlClient_GetClusterTemplate(t *testing.T) {
 	}
 }
 
+func Test_validateWorkerGroups(t *testing.T) {
+	tests := []struct {
+		name    string
+		options GetClusterTemplateOptions
+		wantErr bool
+	}{
+		{
+			name: "no worker groups",
+			options: GetClusterTemplateOptions{
+				ClusterName:        "test",
+				WorkerMachineCount: pointer.Int64Ptr(3),
+			},
+		},
+		{
+			name: "valid worker groups",
+			options: GetClusterTemplateOptions{
+				ClusterName: "test",
+				WorkerGroups: []WorkerGroup{
+					{Name: "md-gpu", Replicas: 3},
+					{Name: "md-small", Replicas: 0, KubernetesVersion: "v1.20.2"},
+				},
+			},
+		},
+		{
+			name: "worker groups and worker machine count",
+			options: GetClusterTemplateOptions{
+				ClusterName:        "test",
+				WorkerMachineCount: pointer.Int64Ptr(3),
+				WorkerGroups:       []WorkerGroup{{Name: "md-gpu", Replicas: 3}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid name",
+			options: GetClusterTemplateOptions{
+				ClusterName:  "test",
+				WorkerGroups: []WorkerGroup{{Name: "MD_GPU", Replicas: 3}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid replicas",
+			options: GetClusterTemplateOptions{
+				ClusterName:  "test",
+				WorkerGroups: []WorkerGroup{{Name: "md-gpu", Replicas: -1}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid Kubernetes version",
+			options: GetClusterTemplateOptions{
+				ClusterName:  "test",
+				WorkerGroups: []WorkerGroup{{Name: "md-gpu", Replicas: 3, KubernetesVersion: "1.20"}},
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			err := validateWorkerGroups(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
 func Test_clusterctlClient_ProcessYAML(t *testing.T) {
 	g := NewWithT(t)
 	template := `v1: ${VAR1:=default1}
@@ -45,6 +45,13 @@ type DescribeClusterOptions struct {
 	// DisableGrouping disable grouping machines objects in case the ready condition
 	// has the same Status, Severity and Reason
 	DisableGrouping bool
+
+	// ShowConditionTypes is a list of comma separated condition types; if set, only the conditions of the given types
+	// are shown for the objects selected by ShowOtherConditions, or for all the objects if ShowOtherConditions is empty.
+	ShowConditionTypes string
+
+	// CollapseReady hides the children of the objects whose ready condition is true.
+	CollapseReady bool
 }
 
 // DescribeCluster returns the object tree representing the status of a Cluster API cluster.
@@ -75,5 +82,52 @@ func (c *clusterctlClient) DescribeCluster(options DescribeClusterOptions) (*tre
 		ShowOtherConditions: options.ShowOtherConditions,
 		DisableNoEcho:       options.DisableNoEcho,
 		DisableGrouping:     options.DisableGrouping,
+		ShowConditionTypes:  options.ShowConditionTypes,
+		CollapseReady:       options.CollapseReady,
 	})
 }
+
+// DescribeObjectGraphOptions carries the options supported by DescribeObjectGraph.
+type DescribeObjectGraphOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the Cluster API objects are located. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// AllNamespaces, if set, discovers the Cluster API objects in all the namespaces; Namespace is ignored.
+	AllNamespaces bool
+}
+
+// DescribeObjectGraph returns the graph of the Cluster API objects existing in the management cluster.
+func (c *clusterctlClient) DescribeObjectGraph(options DescribeObjectGraphOptions) (*ObjectGraph, error) {
+	// gets access to the management cluster
+	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := cluster.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, err
+	}
+
+	namespace := options.Namespace
+	if options.AllNamespaces {
+		namespace = ""
+	} else if namespace == "" {
+		// If the option specifying the Namespace is empty, try to detect it.
+		currentNamespace, err := cluster.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		namespace = currentNamespace
+	}
+
+	graph, err := cluster.ObjectGraphDescriber().Describe(namespace)
+	if err != nil {
+		return nil, err
+	}
+	return (*ObjectGraph)(graph), nil
+}
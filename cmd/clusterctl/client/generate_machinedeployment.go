@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/alpha"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+// GenerateMachineDeploymentOptions carries the options supported by GenerateMachineDeployment.
+type GenerateMachineDeploymentOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace of the MachineDeployment to clone. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// From is the name of the MachineDeployment to clone.
+	From string
+
+	// Name of the generated MachineDeployment, and of its bootstrap config and infrastructure machine templates.
+	Name string
+
+	// Replicas of the generated MachineDeployment. If nil, the replicas of the cloned MachineDeployment are used.
+	Replicas *int32
+
+	// Variables override fields of the generated objects. Keys are field paths prefixed by the object to override,
+	// e.g. machinedeployment.spec.template.spec.version or infrastructure.spec.template.spec.instanceType;
+	// values are parsed as YAML.
+	Variables map[string]string
+}
+
+// GenerateMachineDeployment returns the YAML of a new MachineDeployment, with its bootstrap config and infrastructure
+// machine templates, cloned from an existing MachineDeployment of a cluster.
+func (c *clusterctlClient) GenerateMachineDeployment(options GenerateMachineDeploymentOptions) ([]byte, error) {
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// If the option specifying the Namespace is empty, try to detect it.
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		options.Namespace = currentNamespace
+	}
+
+	objs, err := c.alphaClient.MachineDeploymentGenerator().Generate(clusterClient.Proxy(), alpha.GenerateMachineDeploymentOptions{
+		Namespace: options.Namespace,
+		From:      options.From,
+		Name:      options.Name,
+		Replicas:  options.Replicas,
+		Variables: options.Variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return utilyaml.FromUnstructured(objs)
+}
@@ -20,6 +20,7 @@ import (
 	"sort"
 
 	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
@@ -44,6 +45,8 @@ type InitOptions struct {
 	BootstrapProviders []string
 
 	// InfrastructureProviders and versions (e.g. aws:v0.5.0) to add to the management cluster.
+	// Multiple instances of the same provider can be added, each one installed in and watching its own
+	// namespace, using the name[:version][:namespace] syntax (e.g. aws:ns1, aws:v0.5.0:ns2).
 	InfrastructureProviders []string
 
 	// ControlPlaneProviders and versions (e.g. kubeadm:v0.3.0) to add to the management cluster.
@@ -58,6 +61,11 @@ type InitOptions struct {
 	// If unspecified, the providers watches for Cluster API objects across all namespaces.
 	WatchingNamespace string
 
+	// ProvidersFile is the path of a file defining the providers to add to the management cluster, their versions,
+	// namespaces and variables; see InitProvidersFile for the format. It can't be used together with CoreProvider,
+	// BootstrapProviders, ControlPlaneProviders and InfrastructureProviders.
+	ProvidersFile string
+
 	// LogUsageInstructions instructs the init command to print the usage instructions in case of first run.
 	LogUsageInstructions bool
 
@@ -67,8 +75,17 @@ type InitOptions struct {
 }
 
 // Init initializes a management cluster by adding the requested list of providers.
-func (c *clusterctlClient) Init(options InitOptions) ([]Components, error) {
+func (c *clusterctlClient) Init(options InitOptions) (_ []Components, reterr error) {
 	log := logf.Log
+	progress := logf.NewProgress("init", 4)
+	defer func() {
+		progress.Done(reterr)
+	}()
+
+	// adds the providers defined in the providers file, if any
+	if err := c.applyProvidersFile(&options); err != nil {
+		return nil, err
+	}
 
 	// gets access to the management cluster
 	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
@@ -84,6 +101,7 @@ func (c *clusterctlClient) Init(options InitOptions) ([]Components, error) {
 	// checks if the cluster already contains a Core provider.
 	// if not we consider this the first time init is executed, and thus we enforce the installation of a core provider,
 	// a bootstrap provider and a control-plane provider (if not already explicitly requested by the user)
+	progress.Step("Fetching providers")
 	log.Info("Fetching providers")
 	firstRun := c.addDefaultProviders(cluster, &options)
 
@@ -100,11 +118,13 @@ func (c *clusterctlClient) Init(options InitOptions) ([]Components, error) {
 	// - Providers combines in valid management groups
 	//   - All the providers should belong to one/only one management groups
 	//   - All the providers in a management group must support the same API Version of Cluster API (contract)
+	progress.Step("Validating the management cluster")
 	if err := installer.Validate(); err != nil {
 		return nil, err
 	}
 
 	// Before installing the providers, ensure the cert-manager Webhook is in place.
+	progress.Step("Installing cert-manager")
 	certManager, err := cluster.CertManager()
 	if err != nil {
 		return nil, err
@@ -114,6 +134,7 @@ func (c *clusterctlClient) Init(options InitOptions) ([]Components, error) {
 		return nil, err
 	}
 
+	progress.Step("Installing providers")
 	components, err := installer.Install()
 	if err != nil {
 		return nil, err
@@ -140,6 +161,11 @@ func (c *clusterctlClient) Init(options InitOptions) ([]Components, error) {
 
 // Init returns the list of images required for init.
 func (c *clusterctlClient) InitImages(options InitOptions) ([]string, error) {
+	// adds the providers defined in the providers file, if any
+	if err := c.applyProvidersFile(&options); err != nil {
+		return nil, err
+	}
+
 	// gets access to the management cluster
 	cluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
 	if err != nil {
@@ -179,6 +205,20 @@ func (c *clusterctlClient) InitImages(options InitOptions) ([]string, error) {
 	return images, nil
 }
 
+// ValidateImages checks that each of the given images can be pulled from its registry.
+func (c *clusterctlClient) ValidateImages(images []string) error {
+	log := logf.Log
+
+	var errs []error
+	for _, image := range images {
+		log.V(3).Info("Checking image", "Image", image)
+		if err := c.registryClient.CheckImage(image); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
 func (c *clusterctlClient) setupInstaller(cluster cluster.Client, options InitOptions) (cluster.ProviderInstaller, error) {
 	installer := cluster.ProviderInstaller()
 
@@ -251,12 +291,23 @@ func (c *clusterctlClient) addToInstaller(options addToInstallerOptions, provide
 			}
 			continue
 		}
+		// Parse the abbreviated syntax for name[:version][:namespace]; if a namespace is defined, the provider instance
+		// is installed in and watches only this namespace, thus allowing to install multiple instances of the same provider.
+		nameAndVersion, namespace, err := parseProviderInstance(provider)
+		if err != nil {
+			return err
+		}
+
 		componentsOptions := repository.ComponentsOptions{
 			TargetNamespace:   options.targetNamespace,
 			WatchingNamespace: options.watchingNamespace,
 			SkipVariables:     options.skipVariables,
 		}
-		components, err := c.getComponentsByName(provider, providerType, componentsOptions)
+		if namespace != "" {
+			componentsOptions.TargetNamespace = namespace
+			componentsOptions.WatchingNamespace = namespace
+		}
+		components, err := c.getComponentsByName(nameAndVersion, providerType, componentsOptions)
 		if err != nil {
 			return errors.Wrapf(err, "failed to get provider components for the %q provider", provider)
 		}
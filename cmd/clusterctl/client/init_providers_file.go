@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+// InitProvidersFile defines the providers to add to a management cluster, as read from the file
+// passed to InitOptions.ProvidersFile, e.g.
+//
+//	core:
+//	  name: cluster-api
+//	  version: v0.4.0
+//	bootstrap:
+//	- name: kubeadm
+//	  version: v0.4.0
+//	infrastructure:
+//	- name: aws
+//	  version: v0.7.0
+//	  namespace: capa-system
+//	  variables:
+//	    AWS_B64ENCODED_CREDENTIALS: ...
+//	variables:
+//	  EXP_CLUSTER_RESOURCE_SET: "true"
+type InitProvidersFile struct {
+	// Core is the core provider to add to the management cluster.
+	Core *InitProvider `json:"core,omitempty"`
+
+	// Bootstrap is the list of bootstrap providers to add to the management cluster.
+	Bootstrap []InitProvider `json:"bootstrap,omitempty"`
+
+	// ControlPlane is the list of control plane providers to add to the management cluster.
+	ControlPlane []InitProvider `json:"controlPlane,omitempty"`
+
+	// Infrastructure is the list of infrastructure providers to add to the management cluster.
+	Infrastructure []InitProvider `json:"infrastructure,omitempty"`
+
+	// Variables to use when processing the components YAML of all the providers; they take precedence over
+	// the environment variables and the clusterctl config file.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// InitProvider defines a provider to add to a management cluster.
+type InitProvider struct {
+	// Name of the provider, e.g. aws.
+	Name string `json:"name"`
+
+	// Version of the provider, e.g. v0.7.0. If unspecified, the provider's latest release is used.
+	Version string `json:"version,omitempty"`
+
+	// Namespace the provider is installed in and watches. If unspecified, the target and watching
+	// namespaces of the init command are used.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Variables to use when processing the components YAML of the provider.
+	// NB. Variables are global to the init command, so a variable can't be set to different values for different providers.
+	Variables map[string]string `json:"variables,omitempty"`
+}
+
+// String returns the provider in the name[:version][:namespace] syntax.
+func (p InitProvider) String() string {
+	t := []string{p.Name}
+	if p.Version != "" {
+		t = append(t, p.Version)
+	}
+	if p.Namespace != "" {
+		t = append(t, p.Namespace)
+	}
+	return strings.Join(t, ":")
+}
+
+// readInitProvidersFile reads an InitProvidersFile from path.
+func readInitProvidersFile(path string) (*InitProvidersFile, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read providers file %q", path)
+	}
+
+	providersFile := &InitProvidersFile{}
+	if err := yaml.UnmarshalStrict(content, providersFile); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse providers file %q", path)
+	}
+	return providersFile, nil
+}
+
+// applyProvidersFile adds the providers defined in options.ProvidersFile, if any, to options, and sets the
+// corresponding variables.
+func (c *clusterctlClient) applyProvidersFile(options *InitOptions) error {
+	if options.ProvidersFile == "" {
+		return nil
+	}
+
+	if options.CoreProvider != "" || len(options.BootstrapProviders) > 0 || len(options.ControlPlaneProviders) > 0 || len(options.InfrastructureProviders) > 0 {
+		return errors.New("providers can't be specified both in a providers file and in the init options")
+	}
+
+	providersFile, err := readInitProvidersFile(options.ProvidersFile)
+	if err != nil {
+		return err
+	}
+
+	variables := map[string]string{}
+	for k, v := range providersFile.Variables {
+		variables[k] = v
+	}
+	providerVariables := map[string]string{}
+	addProviders := func(providers []InitProvider) ([]string, error) {
+		var ret []string
+		for _, p := range providers {
+			if p.Name == "" {
+				return nil, errors.Errorf("invalid providers file %q: provider name can't be empty", options.ProvidersFile)
+			}
+			for k, v := range p.Variables {
+				if current, ok := variables[k]; ok && current != v {
+					if owner, ok := providerVariables[k]; ok {
+						return nil, errors.Errorf("invalid providers file %q: variable %q is set to different values for providers %q and %q", options.ProvidersFile, k, owner, p.Name)
+					}
+					return nil, errors.Errorf("invalid providers file %q: variable %q of provider %q is set to a different value in the global variables", options.ProvidersFile, k, p.Name)
+				}
+				variables[k] = v
+				providerVariables[k] = p.Name
+			}
+			ret = append(ret, p.String())
+		}
+		return ret, nil
+	}
+
+	if providersFile.Core != nil {
+		core, err := addProviders([]InitProvider{*providersFile.Core})
+		if err != nil {
+			return err
+		}
+		options.CoreProvider = core[0]
+	}
+	if options.BootstrapProviders, err = addProviders(providersFile.Bootstrap); err != nil {
+		return err
+	}
+	if options.ControlPlaneProviders, err = addProviders(providersFile.ControlPlane); err != nil {
+		return err
+	}
+	if options.InfrastructureProviders, err = addProviders(providersFile.Infrastructure); err != nil {
+		return err
+	}
+
+	for k, v := range variables {
+		c.configClient.Variables().Set(k, v)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_clusterctlClient_applyProvidersFile(t *testing.T) {
+	tests := []struct {
+		name          string
+		providersFile string
+		options       InitOptions
+		wantOptions   InitOptions
+		wantVariables map[string]string
+		wantErr       bool
+	}{
+		{
+			name: "providers, versions, namespaces and variables are read from the file",
+			providersFile: `
+core:
+  name: cluster-api
+  version: v0.4.0
+bootstrap:
+- name: kubeadm
+controlPlane:
+- name: kubeadm
+  version: v0.4.0
+infrastructure:
+- name: infra
+  version: v0.4.0
+  namespace: ns1
+  variables:
+    INFRA_CREDENTIALS: secret
+- name: infra
+  namespace: ns2
+variables:
+  EXP_FEATURE: "true"
+`,
+			wantOptions: InitOptions{
+				CoreProvider:            "cluster-api:v0.4.0",
+				BootstrapProviders:      []string{"kubeadm"},
+				ControlPlaneProviders:   []string{"kubeadm:v0.4.0"},
+				InfrastructureProviders: []string{"infra:v0.4.0:ns1", "infra:ns2"},
+			},
+			wantVariables: map[string]string{
+				"INFRA_CREDENTIALS": "secret",
+				"EXP_FEATURE":       "true",
+			},
+		},
+		{
+			name: "fails if providers are also set in the options",
+			providersFile: `
+infrastructure:
+- name: infra
+`,
+			options: InitOptions{InfrastructureProviders: []string{"infra"}},
+			wantErr: true,
+		},
+		{
+			name: "fails if a provider has no name",
+			providersFile: `
+infrastructure:
+- version: v0.4.0
+`,
+			wantErr: true,
+		},
+		{
+			name: "fails if a field is unknown",
+			providersFile: `
+infrastructures:
+- name: infra
+`,
+			wantErr: true,
+		},
+		{
+			name: "fails if a variable is set to different values for different providers",
+			providersFile: `
+bootstrap:
+- name: kubeadm
+  variables:
+    VAR: a
+infrastructure:
+- name: infra
+  variables:
+    VAR: b
+`,
+			wantErr: true,
+		},
+		{
+			name: "fails if a provider variable is set to a different value in the global variables",
+			providersFile: `
+infrastructure:
+- name: infra
+  variables:
+    VAR: a
+variables:
+  VAR: b
+`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir, err := ioutil.TempDir("", "clusterctl")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, "providers.yaml")
+			g.Expect(ioutil.WriteFile(path, []byte(tt.providersFile), 0600)).To(Succeed())
+
+			c := newFakeClient(newFakeConfig())
+			options := tt.options
+			options.ProvidersFile = path
+
+			err = c.internalClient.applyProvidersFile(&options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			tt.wantOptions.ProvidersFile = path
+			g.Expect(options).To(Equal(tt.wantOptions))
+			for k, v := range tt.wantVariables {
+				got, err := c.configClient.Variables().Get(k)
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(got).To(Equal(v))
+			}
+		})
+	}
+}
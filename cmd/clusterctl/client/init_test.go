@@ -428,6 +428,34 @@ func Test_clusterctlClient_Init(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Init (with a NOT empty cluster) adds multiple instances of the same provider",
+			field: field{
+				client: fakeInitializedCluster(), // clusterctl client for an management cluster with capi installed (with repository setup for capi, bootstrap, control plane and infra provider)
+				hasCRD: true,
+			},
+			args: args{
+				coreProvider:           "", // with a NOT empty cluster, a core provider should NOT be added automatically
+				infrastructureProvider: []string{"infra:nsa", "infra:v3.1.0:nsb"},
+				targetNameSpace:        "",
+				watchingNamespace:      "",
+			},
+			want: []want{
+				{
+					provider:          infraProviderConfig,
+					version:           "v3.0.0",
+					targetNamespace:   "nsa",
+					watchingNamespace: "nsa",
+				},
+				{
+					provider:          infraProviderConfig,
+					version:           "v3.1.0",
+					targetNamespace:   "nsb",
+					watchingNamespace: "nsb",
+				},
+			},
+			wantErr: false,
+		},
 		{
 			name: "Fails when opting out from coreProvider automatic installation",
 			field: field{
@@ -554,6 +582,53 @@ var (
 )
 
 // setup a cluster client and the fake configuration for testing
+func Test_clusterctlClient_ValidateImages(t *testing.T) {
+	tests := []struct {
+		name    string
+		images  []string
+		wantErr bool
+	}{
+		{
+			name:    "pass if all the images can be pulled",
+			images:  []string{"myorg.io/cluster-api-controller:v0.4.0", "myorg.io/kube-rbac-proxy:v0.8.0"},
+			wantErr: false,
+		},
+		{
+			name:    "fails if any image can't be pulled",
+			images:  []string{"myorg.io/cluster-api-controller:v0.4.0", "myorg.io/missing:v0.4.0"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := &clusterctlClient{
+				registryClient: fakeRegistryClient{missing: "myorg.io/missing:v0.4.0"},
+			}
+
+			err := c.ValidateImages(tt.images)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+// fakeRegistryClient is a registry client where all the images exist, except for the missing one.
+type fakeRegistryClient struct {
+	missing string
+}
+
+func (f fakeRegistryClient) CheckImage(image string) error {
+	if image == f.missing {
+		return errors.Errorf("image %q not found", image)
+	}
+	return nil
+}
+
 func setupCluster(providers []Provider, certManagerClient cluster.CertManagerClient) (*fakeConfigClient, *fakeClient) {
 	// create a config variables client which does not have the value for
 	// SOME_VARIABLE as expected in the infra components YAML
@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+// GetProviderInventoryOptions carries the options supported by GetProviderInventory.
+type GetProviderInventoryOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty, default discovery rules apply.
+	Kubeconfig Kubeconfig
+
+	// SkipRepositories lists the installed providers without reading the provider repositories, i.e. without
+	// accessing the network; in this case the Contract and the AvailableUpgrades of the providers are not set.
+	SkipRepositories bool
+}
+
+func (c *clusterctlClient) GetProviderInventory(options GetProviderInventoryOptions) ([]InstalledProvider, error) {
+	// Get the client for interacting with the management cluster.
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	// Ensures the custom resource definitions required by clusterctl are in place.
+	if err := clusterClient.ProviderInventory().EnsureCustomResourceDefinitions(); err != nil {
+		return nil, err
+	}
+
+	installedProviders, err := clusterClient.ProviderUpgrader().InstalledProviders(cluster.InstalledProvidersOptions{
+		SkipRepositories: options.SkipRepositories,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// InstalledProvider is an alias for cluster.InstalledProvider; this makes the conversion
+	aliasInstalledProviders := make([]InstalledProvider, len(installedProviders))
+	for i, provider := range installedProviders {
+		aliasInstalledProviders[i] = InstalledProvider(provider)
+	}
+	return aliasInstalledProviders, nil
+}
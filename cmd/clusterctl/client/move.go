@@ -17,6 +17,8 @@ limitations under the License.
 package client
 
 import (
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
 )
 
@@ -36,9 +38,19 @@ type MoveOptions struct {
 
 	// DryRun means the move action is a dry run, no real action will be performed
 	DryRun bool
+
+	// ClusterSelector is a label selector restricting the move to the matching Clusters and to their dependent objects.
+	// Objects used also by Clusters not being moved are copied to the target management cluster, but they are not
+	// deleted from the source one. If empty, all the Clusters in the namespace are moved.
+	ClusterSelector string
 }
 
 func (c *clusterctlClient) Move(options MoveOptions) error {
+	clusterSelector, err := labels.Parse(options.ClusterSelector)
+	if err != nil {
+		return errors.Wrapf(err, "invalid cluster selector %q", options.ClusterSelector)
+	}
+
 	// Get the client for interacting with the source management cluster.
 	fromCluster, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.FromKubeconfig})
 	if err != nil {
@@ -73,7 +85,7 @@ func (c *clusterctlClient) Move(options MoveOptions) error {
 		options.Namespace = currentNamespace
 	}
 
-	if err := fromCluster.ObjectMover().Move(options.Namespace, toCluster, options.DryRun); err != nil {
+	if err := fromCluster.ObjectMover().Move(options.Namespace, toCluster, options.DryRun, clusterSelector); err != nil {
 		return err
 	}
 
@@ -0,0 +1,195 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// UpgradePlanOutput is a machine readable representation of the upgrade plans for a management cluster.
+type UpgradePlanOutput struct {
+	CertManager      CertManagerUpgradePlanOutput       `json:"certManager"`
+	ManagementGroups []ManagementGroupUpgradePlanOutput `json:"managementGroups"`
+}
+
+// CertManagerUpgradePlanOutput is a machine readable representation of the cert-manager upgrade plan.
+type CertManagerUpgradePlanOutput struct {
+	From          string `json:"from"`
+	To            string `json:"to"`
+	ShouldUpgrade bool   `json:"shouldUpgrade"`
+}
+
+// ManagementGroupUpgradePlanOutput is a machine readable representation of the upgrade plan for a management group
+// to the latest releases for an API Version of Cluster API (contract).
+type ManagementGroupUpgradePlanOutput struct {
+	// ManagementGroup is the name of the management group, as expected by the upgrade apply command.
+	ManagementGroup string `json:"managementGroup"`
+
+	// Contract is the API Version of Cluster API (contract) of the upgrade plan.
+	Contract string `json:"contract"`
+
+	// Providers of the management group, sorted by type, name and namespace.
+	Providers []ProviderUpgradePlanOutput `json:"providers"`
+
+	// VersionSkewViolations are the rules of the Kubernetes version skew policy of Cluster API violated by the plan.
+	VersionSkewViolations []VersionSkewViolationOutput `json:"versionSkewViolations,omitempty"`
+}
+
+// ProviderUpgradePlanOutput is a machine readable representation of the upgrade plan for a provider.
+type ProviderUpgradePlanOutput struct {
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	Type           string `json:"type"`
+	CurrentVersion string `json:"currentVersion"`
+
+	// NextVersion is empty if the provider is already up to date.
+	NextVersion string `json:"nextVersion,omitempty"`
+}
+
+// VersionSkewViolationOutput is a machine readable representation of a violation of the Kubernetes version skew
+// policy of Cluster API.
+type VersionSkewViolationOutput struct {
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// NewUpgradePlanOutput returns the machine readable representation of the given upgrade plans; management groups
+// are sorted by namespace of the core provider and contract.
+func NewUpgradePlanOutput(certManagerPlan CertManagerUpgradePlan, plans []UpgradePlan) UpgradePlanOutput {
+	out := UpgradePlanOutput{
+		CertManager: CertManagerUpgradePlanOutput{
+			From:          certManagerPlan.From,
+			To:            certManagerPlan.To,
+			ShouldUpgrade: certManagerPlan.ShouldUpgrade,
+		},
+		ManagementGroups: []ManagementGroupUpgradePlanOutput{},
+	}
+
+	sorted := append([]UpgradePlan{}, plans...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].CoreProvider.Namespace < sorted[j].CoreProvider.Namespace ||
+			(sorted[i].CoreProvider.Namespace == sorted[j].CoreProvider.Namespace && sorted[i].Contract < sorted[j].Contract)
+	})
+
+	for _, plan := range sorted {
+		group := ManagementGroupUpgradePlanOutput{
+			ManagementGroup: plan.CoreProvider.InstanceName(),
+			Contract:        plan.Contract,
+			Providers:       []ProviderUpgradePlanOutput{},
+		}
+		for _, item := range plan.Providers {
+			group.Providers = append(group.Providers, ProviderUpgradePlanOutput{
+				Name:           item.Provider.Name,
+				Namespace:      item.Provider.Namespace,
+				Type:           item.Provider.Type,
+				CurrentVersion: item.Provider.Version,
+				NextVersion:    item.NextVersion,
+			})
+		}
+		sort.Slice(group.Providers, func(i, j int) bool {
+			a, b := group.Providers[i], group.Providers[j]
+			return a.Type < b.Type ||
+				(a.Type == b.Type && a.Name < b.Name) ||
+				(a.Type == b.Type && a.Name == b.Name && a.Namespace < b.Namespace)
+		})
+		for _, v := range plan.VersionSkewViolations {
+			group.VersionSkewViolations = append(group.VersionSkewViolations, VersionSkewViolationOutput{
+				Rule:    v.Rule,
+				Message: v.Message,
+			})
+		}
+		out.ManagementGroups = append(out.ManagementGroups, group)
+	}
+
+	return out
+}
+
+// ProviderComponentsOutput is a machine readable representation of the information about the components of a provider.
+type ProviderComponentsOutput struct {
+	Name              string   `json:"name"`
+	Type              string   `json:"type"`
+	URL               string   `json:"url"`
+	Version           string   `json:"version"`
+	File              string   `json:"file"`
+	TargetNamespace   string   `json:"targetNamespace"`
+	WatchingNamespace string   `json:"watchingNamespace"`
+	Variables         []string `json:"variables,omitempty"`
+	Images            []string `json:"images,omitempty"`
+}
+
+// NewProviderComponentsOutput returns the machine readable representation of the information about the given
+// provider components; the URL is the base URL of the provider repository, without the version and the file name.
+func NewProviderComponentsOutput(c Components) ProviderComponentsOutput {
+	dir, file := filepath.Split(c.URL())
+	baseURL, _ := filepath.Split(strings.TrimSuffix(dir, "/"))
+	return ProviderComponentsOutput{
+		Name:              c.Name(),
+		Type:              string(c.Type()),
+		URL:               baseURL,
+		Version:           c.Version(),
+		File:              file,
+		TargetNamespace:   c.TargetNamespace(),
+		WatchingNamespace: c.WatchingNamespace(),
+		Variables:         c.Variables(),
+		Images:            c.Images(),
+	}
+}
+
+const (
+	// DescriptionSourceDefault is used for values which are not set in the clusterctl configuration file.
+	DescriptionSourceDefault = "default"
+
+	// DescriptionSourceConfig is used for values set in the clusterctl configuration file.
+	DescriptionSourceConfig = "clusterctl config"
+)
+
+// ProviderComponentsDescription is a machine readable description of where the components of a provider are read from.
+type ProviderComponentsDescription struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	URL  string `json:"url"`
+
+	// URLSource is either "clusterctl config" for providers defined in the clusterctl configuration file,
+	// or "default" for the providers hard-coded in clusterctl.
+	URLSource string `json:"urlSource"`
+
+	Version string `json:"version"`
+
+	// VersionResolution explains how the version was determined.
+	VersionResolution string `json:"versionResolution"`
+
+	OverridesFolder string `json:"overridesFolder"`
+
+	// OverridesFolderSource is either "clusterctl config" if the folder is defined by the overridesFolder variable,
+	// or "default".
+	OverridesFolderSource string `json:"overridesFolderSource"`
+
+	Files []ProviderFileDescription `json:"files"`
+}
+
+// ProviderFileDescription is a machine readable description of where a provider file is read from.
+type ProviderFileDescription struct {
+	Name string `json:"name"`
+
+	// Source is either "override" or "repository".
+	Source       string `json:"source"`
+	OverridePath string `json:"overridePath"`
+	Found        bool   `json:"found"`
+	Error        string `json:"error,omitempty"`
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+)
+
+func TestNewUpgradePlanOutput(t *testing.T) {
+	g := NewWithT(t)
+
+	core := fakeProvider("cluster-api", clusterctlv1.CoreProviderType, "v1.0.0", "cluster-api-system")
+	infra := fakeProvider("infra", clusterctlv1.InfrastructureProviderType, "v2.0.0", "infra-system")
+
+	plans := []UpgradePlan{
+		{
+			Contract:     "v1alpha4",
+			CoreProvider: core,
+			Providers: []cluster.UpgradeItem{
+				{Provider: infra, NextVersion: "v3.0.0"},
+				{Provider: core, NextVersion: "v2.0.0"},
+			},
+			VersionSkewViolations: []cluster.VersionSkewViolation{
+				{Rule: "ManagementClusterVersion", Message: "not supported"},
+			},
+		},
+		{
+			Contract:     "v1alpha3",
+			CoreProvider: core,
+			Providers: []cluster.UpgradeItem{
+				{Provider: core},
+			},
+		},
+	}
+
+	got := NewUpgradePlanOutput(CertManagerUpgradePlan{From: "v1.1.0", To: "v1.2.0", ShouldUpgrade: true}, plans)
+
+	g.Expect(got).To(Equal(UpgradePlanOutput{
+		CertManager: CertManagerUpgradePlanOutput{From: "v1.1.0", To: "v1.2.0", ShouldUpgrade: true},
+		ManagementGroups: []ManagementGroupUpgradePlanOutput{
+			{
+				ManagementGroup: core.InstanceName(),
+				Contract:        "v1alpha3",
+				Providers: []ProviderUpgradePlanOutput{
+					{Name: core.Name, Namespace: "cluster-api-system", Type: "CoreProvider", CurrentVersion: "v1.0.0"},
+				},
+			},
+			{
+				ManagementGroup: core.InstanceName(),
+				Contract:        "v1alpha4",
+				Providers: []ProviderUpgradePlanOutput{
+					{Name: core.Name, Namespace: "cluster-api-system", Type: "CoreProvider", CurrentVersion: "v1.0.0", NextVersion: "v2.0.0"},
+					{Name: infra.Name, Namespace: "infra-system", Type: "InfrastructureProvider", CurrentVersion: "v2.0.0", NextVersion: "v3.0.0"},
+				},
+				VersionSkewViolations: []VersionSkewViolationOutput{
+					{Rule: "ManagementClusterVersion", Message: "not supported"},
+				},
+			},
+		},
+	}))
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registry implements the checks of container images against the registries serving them.
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/distribution/registry/client/auth/challenge"
+	"github.com/pkg/errors"
+)
+
+const (
+	// dockerHubDomain is the domain of the images hosted on Docker Hub, e.g. docker.io/library/busybox.
+	dockerHubDomain = "docker.io"
+
+	// dockerHubRegistry is the host serving the registry API of Docker Hub.
+	dockerHubRegistry = "registry-1.docker.io"
+
+	defaultTimeout = 30 * time.Second
+)
+
+// manifestMediaTypes are the media types of the image manifests and manifest lists accepted from the registries.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.oci.image.index.v1+json",
+}
+
+// Client has methods to check container images against the registries serving them.
+type Client interface {
+	// CheckImage returns an error if an image can't be pulled anonymously, i.e. if the registry serving the image
+	// does not have a manifest for its tag or digest, or if it can't be reached.
+	CheckImage(image string) error
+}
+
+// registryClient implements Client.
+type registryClient struct {
+	httpClient *http.Client
+}
+
+// ensure registryClient implements Client.
+var _ Client = &registryClient{}
+
+// Option is a configuration option supplied to New.
+type Option func(*registryClient)
+
+// InjectHTTPClient allows to override the HTTP client used to reach the registries.
+func InjectHTTPClient(httpClient *http.Client) Option {
+	return func(c *registryClient) {
+		c.httpClient = httpClient
+	}
+}
+
+// New returns a Client.
+func New(options ...Option) Client {
+	c := &registryClient{}
+	for _, o := range options {
+		o(c)
+	}
+
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{Timeout: defaultTimeout}
+	}
+	return c
+}
+
+func (c *registryClient) CheckImage(image string) error {
+	named, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse image %q", image)
+	}
+
+	host := reference.Domain(named)
+	if host == dockerHubDomain {
+		host = dockerHubRegistry
+	}
+
+	// The digest, if any, takes precedence over the tag, given that it is what the container runtime pulls.
+	ref := "latest"
+	if tagged, ok := named.(reference.Tagged); ok {
+		ref = tagged.Tag()
+	}
+	if digested, ok := named.(reference.Digested); ok {
+		ref = digested.Digest().String()
+	}
+
+	manifestURL := fmt.Sprintf("https://%s/v2/%s/manifests/%s", host, reference.Path(named), ref)
+	resp, err := c.headManifest(manifestURL, "")
+	if err != nil {
+		return errors.Wrapf(err, "failed to check image %q", image)
+	}
+
+	// Registries require a token, even for anonymous pulls, as described in https://docs.docker.com/registry/spec/auth/token/.
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := c.getToken(resp, fmt.Sprintf("repository:%s:pull", reference.Path(named)))
+		if err != nil {
+			return errors.Wrapf(err, "failed to check image %q", image)
+		}
+		if token != "" {
+			resp, err = c.headManifest(manifestURL, token)
+			if err != nil {
+				return errors.Wrapf(err, "failed to check image %q", image)
+			}
+		}
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return errors.Errorf("image %q not found", image)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return errors.Errorf("image %q can't be pulled anonymously: %s", image, resp.Status)
+	default:
+		return errors.Errorf("failed to check image %q: %s", image, resp.Status)
+	}
+}
+
+// headManifest sends a HEAD request for an image manifest.
+func (c *registryClient) headManifest(manifestURL, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// getToken gets an anonymous token for the given scope from the authorization server in the Bearer challenge of
+// an unauthorized response; it returns an empty token if the response has no Bearer challenge.
+func (c *registryClient) getToken(resp *http.Response, scope string) (string, error) {
+	for _, ch := range challenge.ResponseChallenges(resp) {
+		if !strings.EqualFold(ch.Scheme, "bearer") {
+			continue
+		}
+
+		realm, err := url.Parse(ch.Parameters["realm"])
+		if err != nil || realm.Host == "" {
+			return "", errors.Errorf("invalid realm %q in the registry authentication challenge", ch.Parameters["realm"])
+		}
+		query := realm.Query()
+		if service, ok := ch.Parameters["service"]; ok {
+			query.Set("service", service)
+		}
+		query.Set("scope", scope)
+		realm.RawQuery = query.Encode()
+
+		tokenResp, err := c.httpClient.Get(realm.String())
+		if err != nil {
+			return "", errors.Wrap(err, "failed to get a registry token")
+		}
+		defer tokenResp.Body.Close()
+		if tokenResp.StatusCode != http.StatusOK {
+			return "", errors.Errorf("failed to get a registry token: %s", tokenResp.Status)
+		}
+
+		// Token servers return the token either in the token or in the access_token field.
+		token := struct {
+			Token       string `json:"token"`
+			AccessToken string `json:"access_token"`
+		}{}
+		if err := json.NewDecoder(tokenResp.Body).Decode(&token); err != nil {
+			return "", errors.Wrap(err, "failed to decode the registry token")
+		}
+		if token.Token != "" {
+			return token.Token, nil
+		}
+		return token.AccessToken, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+)
+
+func Test_registryClient_CheckImage(t *testing.T) {
+	digest := "sha256:0e5b6d3fc9f4ef4b3d3a5d9f0d2e3bd64c5a23a8e7c9e0f0a39f4ce1d7c1f3a2"
+
+	var server *httptest.Server
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("scope") != "repository:private/controller:pull" || r.URL.Query().Get("service") != "registry" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"token": "foo-token"}`)
+	})
+	mux.HandleFunc("/v2/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/public/controller/manifests/v0.4.0", "/v2/public/controller/manifests/" + digest:
+			w.WriteHeader(http.StatusOK)
+		case "/v2/private/controller/manifests/v0.4.0":
+			if r.Header.Get("Authorization") != "Bearer foo-token" {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL))
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		case "/v2/restricted/controller/manifests/v0.4.0":
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	server = httptest.NewTLSServer(mux)
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	tests := []struct {
+		name    string
+		image   string
+		wantErr bool
+	}{
+		{
+			name:    "pass if the tag exists",
+			image:   host + "/public/controller:v0.4.0",
+			wantErr: false,
+		},
+		{
+			name:    "pass if the digest exists",
+			image:   host + "/public/controller:v0.5.0@" + digest,
+			wantErr: false,
+		},
+		{
+			name:    "pass if the tag exists, with an anonymous token",
+			image:   host + "/private/controller:v0.4.0",
+			wantErr: false,
+		},
+		{
+			name:    "fails if the tag does not exist",
+			image:   host + "/public/controller:v0.5.0",
+			wantErr: true,
+		},
+		{
+			name:    "fails if the image can't be pulled anonymously",
+			image:   host + "/restricted/controller:v0.4.0",
+			wantErr: true,
+		},
+		{
+			name:    "fails if the image is invalid",
+			image:   "invalid:invalid:invalid",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := New(InjectHTTPClient(server.Client()))
+
+			err := c.CheckImage(tt.image)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
@@ -150,8 +150,23 @@ type Repository interface {
 
 var _ Repository = &test.FakeRepository{}
 
-//repositoryFactory returns the repository implementation corresponding to the provider URL.
+// repositoryFactory returns the repository implementation corresponding to the provider URL, or the
+// local repository cache, if defined.
 func repositoryFactory(providerConfig config.Provider, configVariablesClient config.VariablesClient) (Repository, error) {
+	// if the local repository cache is defined, read the provider repository from the cache only
+	if cacheDir := localRepositoryCache(configVariablesClient); cacheDir != "" {
+		repo, err := newCachedRepository(providerConfig, configVariablesClient, cacheDir)
+		if err != nil {
+			return nil, errors.Wrap(err, "error creating the local repository cache client")
+		}
+		return repo, nil
+	}
+
+	return sourceRepositoryFactory(providerConfig, configVariablesClient)
+}
+
+// sourceRepositoryFactory returns the repository implementation corresponding to the provider URL.
+func sourceRepositoryFactory(providerConfig config.Provider, configVariablesClient config.VariablesClient) (Repository, error) {
 	// parse the repository url
 	rURL, err := url.Parse(providerConfig.URL())
 	if err != nil {
@@ -221,8 +221,8 @@ func NewComponents(input ComponentsInput) (*components, error) {
 	}
 
 	// Apply image overrides, if defined
-	objs, err = util.FixImages(objs, func(image string) (string, error) {
-		return input.ConfigClient.ImageMeta().AlterImage(input.Provider.ManifestLabel(), image)
+	objs, err = util.FixImages(objs, func(container, image string) (string, error) {
+		return input.ConfigClient.ImageMeta().AlterContainerImage(input.Provider.ManifestLabel(), container, image)
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to apply image overrides")
@@ -17,6 +17,9 @@ limitations under the License.
 package repository
 
 import (
+	"os"
+	"strings"
+
 	"github.com/pkg/errors"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
@@ -29,6 +32,62 @@ type ComponentsClient interface {
 	Get(options ComponentsOptions) (Components, error)
 }
 
+// ComponentsDescriber is implemented by the ComponentsClients that can describe where the provider components
+// and metadata would be read from.
+type ComponentsDescriber interface {
+	// Describe returns where the provider components and metadata would be read from, without processing them;
+	// this is intended for troubleshooting the overrides layer and the provider repository configuration.
+	Describe(options ComponentsOptions) (*ComponentsDescription, error)
+}
+
+// FileSource defines the source a provider file is read from.
+type FileSource string
+
+const (
+	// FileSourceOverride is used for files read from the overrides folder.
+	FileSourceOverride FileSource = "override"
+
+	// FileSourceRepository is used for files read from the provider repository.
+	FileSourceRepository FileSource = "repository"
+)
+
+// ComponentsDescription describes where the provider components and metadata are read from.
+type ComponentsDescription struct {
+	// Version is the version of the provider the files are read for.
+	Version string
+
+	// VersionResolution explains how Version was determined.
+	VersionResolution string
+
+	// OverridesFolder is the folder where override files are looked up.
+	OverridesFolder string
+
+	// OverridesFolderFromConfig is true if the OverridesFolder is defined by the overridesFolder variable.
+	OverridesFolderFromConfig bool
+
+	// Files are the provider files, i.e. the components YAML and metadata.yaml.
+	Files []FileDescription
+}
+
+// FileDescription describes where a provider file is read from.
+type FileDescription struct {
+	// Name is the name of the file, relative to the root path of the provider repository.
+	Name string
+
+	// Source is where the file is read from; files are read from the overrides folder, if they exist there,
+	// otherwise from the provider repository.
+	Source FileSource
+
+	// OverridePath is the path where the override file is looked up.
+	OverridePath string
+
+	// Found is true if the file exists in Source.
+	Found bool
+
+	// Error is the reason why the file can't be read from Source, if any.
+	Error string
+}
+
 // componentsClient implements ComponentsClient.
 type componentsClient struct {
 	provider     config.Provider
@@ -40,6 +99,9 @@ type componentsClient struct {
 // ensure componentsClient implements ComponentsClient.
 var _ ComponentsClient = &componentsClient{}
 
+// ensure componentsClient implements ComponentsDescriber.
+var _ ComponentsDescriber = &componentsClient{}
+
 // newComponentsClient returns a componentsClient.
 func newComponentsClient(provider config.Provider, repository Repository, configClient config.Client) *componentsClient {
 	return &componentsClient{
@@ -85,3 +147,51 @@ func (f *componentsClient) Get(options ComponentsOptions) (Components, error) {
 
 	return NewComponents(ComponentsInput{f.provider, f.configClient, f.processor, file, options})
 }
+
+// Describe returns where the components would be read from.
+func (f *componentsClient) Describe(options ComponentsOptions) (*ComponentsDescription, error) {
+	d := &ComponentsDescription{
+		Version:           options.Version,
+		VersionResolution: "requested version",
+	}
+	if d.Version == "" {
+		d.Version = f.repository.DefaultVersion()
+		d.VersionResolution = "version from the repository URL"
+		if strings.Contains(f.provider.URL(), "/latest/") {
+			d.VersionResolution = "latest release in the repository, given that the repository URL points to latest"
+		}
+	}
+	d.OverridesFolder, d.OverridesFolderFromConfig = overridesFolder(f.configClient.Variables())
+
+	for _, name := range []string{f.repository.ComponentsPath(), "metadata.yaml"} {
+		file := FileDescription{
+			Name: name,
+			OverridePath: newOverride(&newOverrideInput{
+				configVariablesClient: f.configClient.Variables(),
+				provider:              f.provider,
+				version:               d.Version,
+				filePath:              name,
+			}).Path(),
+		}
+
+		_, err := os.Stat(file.OverridePath)
+		switch {
+		case err == nil:
+			file.Source = FileSourceOverride
+			file.Found = true
+		case os.IsNotExist(err):
+			file.Source = FileSourceRepository
+			if _, err := f.repository.GetFile(d.Version, name); err != nil {
+				file.Error = err.Error()
+			} else {
+				file.Found = true
+			}
+		default:
+			return nil, errors.Wrapf(err, "failed to read local override for %s", file.OverridePath)
+		}
+
+		d.Files = append(d.Files, file)
+	}
+
+	return d, nil
+}
@@ -18,6 +18,8 @@ package repository
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/gomega"
@@ -353,3 +355,89 @@ func Test_componentsClient_Get(t *testing.T) {
 		})
 	}
 }
+
+func Test_componentsClient_Describe(t *testing.T) {
+	g := NewWithT(t)
+
+	tmpDir := createTempDir(t)
+	defer os.RemoveAll(tmpDir)
+
+	createLocalTestProviderFile(t, tmpDir, "infrastructure-infra/v1.0.0/components.yaml", "foo: bar")
+
+	configClient, err := config.New("", config.InjectReader(test.NewFakeReader().WithVar(overrideFolderKey, tmpDir)))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	repository := test.NewFakeRepository().
+		WithPaths("root", "components.yaml").
+		WithDefaultVersion("v1.0.0").
+		WithFile("v1.0.0", "components.yaml", []byte("foo: bar")).
+		WithFile("v1.0.0", "metadata.yaml", []byte("foo: bar")).
+		WithFile("v2.0.0", "metadata.yaml", []byte("foo: bar"))
+
+	tests := []struct {
+		name     string
+		provider config.Provider
+		version  string
+		want     *ComponentsDescription
+	}{
+		{
+			name:     "default version, with override",
+			provider: config.NewProvider("infra", "https://github.com/infra/infra/releases/v1.0.0/components.yaml", clusterctlv1.InfrastructureProviderType),
+			want: &ComponentsDescription{
+				Version:                   "v1.0.0",
+				VersionResolution:         "version from the repository URL",
+				OverridesFolder:           tmpDir,
+				OverridesFolderFromConfig: true,
+				Files: []FileDescription{
+					{
+						Name:         "components.yaml",
+						Source:       FileSourceOverride,
+						OverridePath: filepath.Join(tmpDir, "infrastructure-infra", "v1.0.0", "components.yaml"),
+						Found:        true,
+					},
+					{
+						Name:         "metadata.yaml",
+						Source:       FileSourceRepository,
+						OverridePath: filepath.Join(tmpDir, "infrastructure-infra", "v1.0.0", "metadata.yaml"),
+						Found:        true,
+					},
+				},
+			},
+		},
+		{
+			name:     "requested version, without override",
+			provider: config.NewProvider("infra", "https://github.com/infra/infra/releases/latest/components.yaml", clusterctlv1.InfrastructureProviderType),
+			version:  "v2.0.0",
+			want: &ComponentsDescription{
+				Version:                   "v2.0.0",
+				VersionResolution:         "requested version",
+				OverridesFolder:           tmpDir,
+				OverridesFolderFromConfig: true,
+				Files: []FileDescription{
+					{
+						Name:         "components.yaml",
+						Source:       FileSourceRepository,
+						OverridePath: filepath.Join(tmpDir, "infrastructure-infra", "v2.0.0", "components.yaml"),
+						Error:        "unable to get file components.yaml for version v2.0.0",
+					},
+					{
+						Name:         "metadata.yaml",
+						Source:       FileSourceRepository,
+						OverridePath: filepath.Join(tmpDir, "infrastructure-infra", "v2.0.0", "metadata.yaml"),
+						Found:        true,
+					},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			f := newComponentsClient(tt.provider, repository, configClient)
+			got, err := f.Describe(ComponentsOptions{Version: tt.version})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
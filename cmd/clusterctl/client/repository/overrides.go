@@ -65,12 +65,7 @@ func newOverride(o *newOverrideInput) Overrider {
 // Path returns the fully formed path to the file within the specified
 // overrides config.
 func (o *overrides) Path() string {
-	basepath := filepath.Join(homedir.HomeDir(), config.ConfigFolder, overrideFolder)
-	f, err := o.configVariablesClient.Get(overrideFolderKey)
-	if err == nil && len(strings.TrimSpace(f)) != 0 {
-		basepath = f
-	}
-
+	basepath, _ := overridesFolder(o.configVariablesClient)
 	return filepath.Join(
 		basepath,
 		o.providerLabel,
@@ -79,6 +74,16 @@ func (o *overrides) Path() string {
 	)
 }
 
+// overridesFolder returns the overrides folder, and true if it is defined by the overridesFolder variable
+// instead of being the default $HOME/.cluster-api/overrides.
+func overridesFolder(configVariablesClient config.VariablesClient) (string, bool) {
+	f, err := configVariablesClient.Get(overrideFolderKey)
+	if err == nil && len(strings.TrimSpace(f)) != 0 {
+		return f, true
+	}
+	return filepath.Join(homedir.HomeDir(), config.ConfigFolder, overrideFolder), false
+}
+
 // getLocalOverride return local override file from the config folder, if it exists.
 // This is required for development purposes, but it can be used also in production as a workaround for problems on the official repositories
 func getLocalOverride(info *newOverrideInput) ([]byte, error) {
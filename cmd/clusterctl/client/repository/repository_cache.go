@@ -0,0 +1,150 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/version"
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	yaml "sigs.k8s.io/cluster-api/cmd/clusterctl/client/yamlprocessor"
+	logf "sigs.k8s.io/cluster-api/cmd/clusterctl/log"
+)
+
+// LocalRepositoryCacheKey is the variable defining the folder of the local repository cache; when it is set,
+// provider repositories are read from the cache only, without accessing the network.
+const LocalRepositoryCacheKey = "localRepositoryCache"
+
+// localRepositoryCache returns the folder of the local repository cache, if defined.
+func localRepositoryCache(configVariablesClient config.VariablesClient) string {
+	f, err := configVariablesClient.Get(LocalRepositoryCacheKey)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(f)
+}
+
+// newCachedRepository returns a localRepository reading the provider repository from the local repository cache,
+// which uses the same layout of local repositories: {cache}/{provider-label}/{version}/{components.yaml}.
+// The default version is the latest version stored in the cache.
+func newCachedRepository(providerConfig config.Provider, configVariablesClient config.VariablesClient, cacheDir string) (*localRepository, error) {
+	rURL, err := url.Parse(providerConfig.URL())
+	if err != nil {
+		return nil, errors.Errorf("failed to parse repository url %q", providerConfig.URL())
+	}
+
+	repo := &localRepository{
+		providerConfig:        providerConfig,
+		configVariablesClient: configVariablesClient,
+		basepath:              filepath.Clean(cacheDir),
+		providerLabel:         providerConfig.ManifestLabel(),
+		componentsPath:        path.Base(rURL.Path),
+	}
+
+	repo.defaultVersion, err = repo.getLatestRelease()
+	if err != nil || repo.defaultVersion == "" {
+		return nil, errors.Errorf("provider %q not found in the local repository cache %q. Please run clusterctl repository sync", providerConfig.ManifestLabel(), cacheDir)
+	}
+	return repo, nil
+}
+
+// SyncLocalRepositoryCacheOptions carries the options for storing a provider repository in the local repository cache.
+type SyncLocalRepositoryCacheOptions struct {
+	// Directory is the folder of the local repository cache.
+	Directory string
+
+	// Version of the provider to store in the cache; if empty, the default version of the provider repository is used.
+	Version string
+
+	// Flavors of the cluster templates to store in the cache in addition to the default one.
+	Flavors []string
+}
+
+// SyncLocalRepositoryCache stores a version of a provider repository in the local repository cache, and returns
+// the version stored. The provider repository is always read from its source, no matter of the cache configuration.
+func SyncLocalRepositoryCache(providerConfig config.Provider, configVariablesClient config.VariablesClient, options SyncLocalRepositoryCacheOptions) (string, error) {
+	repo, err := sourceRepositoryFactory(providerConfig, configVariablesClient)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get repository client for the %s with name %s", providerConfig.Type(), providerConfig.Name())
+	}
+	return syncLocalRepositoryCache(repo, providerConfig, options)
+}
+
+func syncLocalRepositoryCache(repo Repository, providerConfig config.Provider, options SyncLocalRepositoryCacheOptions) (string, error) {
+	log := logf.Log
+
+	if options.Directory == "" {
+		return "", errors.New("invalid arguments: please provide the folder of the local repository cache")
+	}
+
+	v := options.Version
+	if v == "" {
+		v = repo.DefaultVersion()
+	}
+	if _, err := version.ParseSemantic(v); err != nil {
+		return "", errors.Errorf("invalid version %q for provider %q. Please use a semantic version number", v, providerConfig.ManifestLabel())
+	}
+
+	// Read all the files before writing to the cache, so a failure doesn't leave an incomplete version in the cache.
+	files := map[string][]byte{}
+	for _, name := range []string{repo.ComponentsPath(), "metadata.yaml"} {
+		log.V(5).Info("Fetching", "File", name, "Provider", providerConfig.ManifestLabel(), "Version", v)
+		content, err := repo.GetFile(v, name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to read %q from provider's repository %q", name, providerConfig.ManifestLabel())
+		}
+		files[name] = content
+	}
+
+	// Cluster templates are expected to exist for infrastructure providers only; the default template is optional,
+	// while the templates for the requested flavors must exist.
+	if providerConfig.Type() == clusterctlv1.InfrastructureProviderType {
+		processor := yaml.NewSimpleProcessor()
+		name := processor.GetTemplateName(v, "")
+		if content, err := repo.GetFile(v, name); err == nil {
+			files[name] = content
+		} else {
+			log.V(1).Info("Skipping the default cluster template", "Provider", providerConfig.ManifestLabel(), "Version", v, "Error", err.Error())
+		}
+		for _, flavor := range options.Flavors {
+			name := processor.GetTemplateName(v, flavor)
+			content, err := repo.GetFile(v, name)
+			if err != nil {
+				return "", errors.Wrapf(err, "failed to read %q from provider's repository %q", name, providerConfig.ManifestLabel())
+			}
+			files[name] = content
+		}
+	}
+
+	dir := filepath.Join(options.Directory, providerConfig.ManifestLabel(), v)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return "", errors.Wrapf(err, "failed to create the local repository cache folder %q", dir)
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			return "", errors.Wrapf(err, "failed to write %q to the local repository cache", name)
+		}
+	}
+	return v, nil
+}
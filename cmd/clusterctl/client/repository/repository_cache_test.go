@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	clusterctlv1 "sigs.k8s.io/cluster-api/cmd/clusterctl/api/v1alpha3"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
+)
+
+func Test_syncLocalRepositoryCache(t *testing.T) {
+	provider := config.NewProvider("foo", "https://github.com/foo/infrastructure-foo/releases/latest/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+	repository := test.NewFakeRepository().
+		WithPaths("root", "infrastructure-components.yaml").
+		WithDefaultVersion("v1.0.1").
+		WithFile("v1.0.0", "infrastructure-components.yaml", []byte("components-v1.0.0")).
+		WithFile("v1.0.0", "metadata.yaml", []byte("metadata-v1.0.0")).
+		WithFile("v1.0.1", "infrastructure-components.yaml", []byte("components-v1.0.1")).
+		WithFile("v1.0.1", "metadata.yaml", []byte("metadata-v1.0.1")).
+		WithFile("v1.0.1", "cluster-template.yaml", []byte("template-v1.0.1")).
+		WithFile("v1.0.1", "cluster-template-bar.yaml", []byte("template-bar-v1.0.1"))
+
+	tests := []struct {
+		name      string
+		options   SyncLocalRepositoryCacheOptions
+		wantFiles map[string]string
+		wantErr   bool
+	}{
+		{
+			name:    "stores the default version with the default template",
+			options: SyncLocalRepositoryCacheOptions{},
+			wantFiles: map[string]string{
+				"v1.0.1/infrastructure-components.yaml": "components-v1.0.1",
+				"v1.0.1/metadata.yaml":                  "metadata-v1.0.1",
+				"v1.0.1/cluster-template.yaml":          "template-v1.0.1",
+			},
+		},
+		{
+			name:    "stores the templates of the requested flavors",
+			options: SyncLocalRepositoryCacheOptions{Flavors: []string{"bar"}},
+			wantFiles: map[string]string{
+				"v1.0.1/infrastructure-components.yaml": "components-v1.0.1",
+				"v1.0.1/metadata.yaml":                  "metadata-v1.0.1",
+				"v1.0.1/cluster-template.yaml":          "template-v1.0.1",
+				"v1.0.1/cluster-template-bar.yaml":      "template-bar-v1.0.1",
+			},
+		},
+		{
+			name:    "stores a specific version without the optional default template",
+			options: SyncLocalRepositoryCacheOptions{Version: "v1.0.0"},
+			wantFiles: map[string]string{
+				"v1.0.0/infrastructure-components.yaml": "components-v1.0.0",
+				"v1.0.0/metadata.yaml":                  "metadata-v1.0.0",
+			},
+		},
+		{
+			name:    "fails if the template of a requested flavor does not exist",
+			options: SyncLocalRepositoryCacheOptions{Version: "v1.0.0", Flavors: []string{"bar"}},
+			wantErr: true,
+		},
+		{
+			name:    "fails if the version is not a semantic version",
+			options: SyncLocalRepositoryCacheOptions{Version: "latest"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			dir, err := ioutil.TempDir("", "cache")
+			g.Expect(err).NotTo(HaveOccurred())
+			defer os.RemoveAll(dir)
+
+			tt.options.Directory = dir
+			_, err = syncLocalRepositoryCache(repository, provider, tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(filepath.Join(dir, provider.ManifestLabel())).NotTo(BeADirectory())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			stored := map[string]string{}
+			err = filepath.Walk(filepath.Join(dir, provider.ManifestLabel()), func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				content, err := ioutil.ReadFile(path)
+				if err != nil {
+					return err
+				}
+				rel, err := filepath.Rel(filepath.Join(dir, provider.ManifestLabel()), path)
+				if err != nil {
+					return err
+				}
+				stored[filepath.ToSlash(rel)] = string(content)
+				return nil
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(stored).To(Equal(tt.wantFiles))
+		})
+	}
+}
+
+func Test_repositoryFactory_localRepositoryCache(t *testing.T) {
+	g := NewWithT(t)
+
+	dir, err := ioutil.TempDir("", "cache")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer os.RemoveAll(dir)
+
+	provider := config.NewProvider("foo", "https://github.com/foo/infrastructure-foo/releases/latest/infrastructure-components.yaml", clusterctlv1.InfrastructureProviderType)
+	configVariablesClient := test.NewFakeVariableClient().WithVar(LocalRepositoryCacheKey, dir)
+
+	// Fails if the provider is not in the cache, without accessing the provider repository.
+	_, err = repositoryFactory(provider, configVariablesClient)
+	g.Expect(err).To(HaveOccurred())
+
+	for _, version := range []string{"v1.0.0", "v1.1.0"} {
+		repository := test.NewFakeRepository().
+			WithPaths("", "infrastructure-components.yaml").
+			WithFile(version, "infrastructure-components.yaml", []byte("components-"+version)).
+			WithFile(version, "metadata.yaml", []byte("metadata-"+version))
+		_, err := syncLocalRepositoryCache(repository, provider, SyncLocalRepositoryCacheOptions{Directory: dir, Version: version})
+		g.Expect(err).NotTo(HaveOccurred())
+	}
+
+	// Reads the provider repository from the cache, defaulting to the latest version stored.
+	repo, err := repositoryFactory(provider, configVariablesClient)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(repo.DefaultVersion()).To(Equal("v1.1.0"))
+	g.Expect(repo.ComponentsPath()).To(Equal("infrastructure-components.yaml"))
+
+	content, err := repo.GetFile("v1.0.0", repo.ComponentsPath())
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(string(content)).To(Equal("components-v1.0.0"))
+}
@@ -34,6 +34,11 @@ type Template interface {
 	// This value is derived by the template YAML.
 	Variables() []string
 
+	// VariableMap returns the variables required by the template, with their default values;
+	// variables without a default value are mapped to nil.
+	// This value is derived by the template YAML.
+	VariableMap() map[string]*string
+
 	// TargetNamespace where the template objects will be installed.
 	TargetNamespace() string
 
@@ -47,6 +52,7 @@ type Template interface {
 // template implements Template.
 type template struct {
 	variables       []string
+	variableMap     map[string]*string
 	targetNamespace string
 	objs            []unstructured.Unstructured
 }
@@ -58,6 +64,10 @@ func (t *template) Variables() []string {
 	return t.variables
 }
 
+func (t *template) VariableMap() map[string]*string {
+	return t.variableMap
+}
+
 func (t *template) TargetNamespace() string {
 	return t.targetNamespace
 }
@@ -85,9 +95,15 @@ func NewTemplate(input TemplateInput) (*template, error) {
 		return nil, err
 	}
 
+	variableMap, err := getVariableMap(input.Processor, input.RawArtifact, variables)
+	if err != nil {
+		return nil, err
+	}
+
 	if input.ListVariablesOnly {
 		return &template{
 			variables:       variables,
+			variableMap:     variableMap,
 			targetNamespace: input.TargetNamespace,
 		}, nil
 	}
@@ -110,7 +126,22 @@ func NewTemplate(input TemplateInput) (*template, error) {
 
 	return &template{
 		variables:       variables,
+		variableMap:     variableMap,
 		targetNamespace: input.TargetNamespace,
 		objs:            objs,
 	}, nil
 }
+
+// getVariableMap returns the variables required by the template with their default values, if the
+// processor can provide them; otherwise all the variables are mapped to nil.
+func getVariableMap(processor yaml.Processor, rawArtifact []byte, variables []string) (map[string]*string, error) {
+	if p, ok := processor.(yaml.VariableMapProcessor); ok {
+		return p.GetVariableMap(rawArtifact)
+	}
+
+	variableMap := make(map[string]*string, len(variables))
+	for _, v := range variables {
+		variableMap[v] = nil
+	}
+	return variableMap, nil
+}
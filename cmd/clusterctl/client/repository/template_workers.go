@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+const machineDeploymentKind = "MachineDeployment"
+
+// WorkerGroup defines a group of worker machines of a workload cluster, which is generated as a MachineDeployment.
+type WorkerGroup struct {
+	// Name of the worker group; the MachineDeployment is named <cluster-name>-<name>.
+	Name string
+
+	// Replicas is the number of worker machines in the group.
+	Replicas int64
+
+	// KubernetesVersion of the worker machines in the group. If empty, the version defined in the template is used.
+	KubernetesVersion string
+}
+
+// machineDeploymentRefs are the paths of the references from a MachineDeployment to the templates used for creating its Machines.
+var machineDeploymentRefs = [][]string{
+	{"spec", "template", "spec", "bootstrap", "configRef"},
+	{"spec", "template", "spec", "infrastructureRef"},
+}
+
+// NewTemplateWithWorkerGroups returns a copy of the template where the worker MachineDeployment, together with the
+// bootstrap and infrastructure templates it references, is replaced by one MachineDeployment for each worker group,
+// each one referencing its own copy of the bootstrap and infrastructure templates.
+// The template must define exactly one MachineDeployment.
+func NewTemplateWithWorkerGroups(t Template, clusterName string, groups []WorkerGroup) (Template, error) {
+	objs := t.Objs()
+
+	mdIndex := -1
+	for i := range objs {
+		if isMachineDeployment(objs[i]) {
+			if mdIndex >= 0 {
+				return nil, errors.New("failed to generate worker groups: the template must define exactly one MachineDeployment, found more than one")
+			}
+			mdIndex = i
+		}
+	}
+	if mdIndex < 0 {
+		return nil, errors.New("failed to generate worker groups: the template must define exactly one MachineDeployment, found none")
+	}
+	md := objs[mdIndex]
+
+	// Find the templates referenced by the MachineDeployment; references to objects not defined in the template
+	// are left untouched.
+	refIndexes := map[int]bool{}
+	var refPaths [][]string
+	for _, path := range machineDeploymentRefs {
+		ref, found, err := unstructured.NestedMap(md.Object, path...)
+		if err != nil || !found {
+			continue
+		}
+		for i := range objs {
+			if isReferencedBy(objs[i], ref, md.GetNamespace()) {
+				refIndexes[i] = true
+				refPaths = append(refPaths, path)
+				break
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	for _, g := range groups {
+		if names[g.Name] {
+			return nil, errors.Errorf("failed to generate worker groups: duplicated worker group %q", g.Name)
+		}
+		names[g.Name] = true
+	}
+
+	// Replace the MachineDeployment and the referenced templates with a copy for each worker group, preserving the
+	// order of the objects in the template.
+	newObjs := make([]unstructured.Unstructured, 0, len(objs)+(len(refIndexes)+1)*len(groups))
+	for i := range objs {
+		if i != mdIndex && !refIndexes[i] {
+			newObjs = append(newObjs, objs[i])
+			continue
+		}
+
+		for _, g := range groups {
+			name := fmt.Sprintf("%s-%s", clusterName, g.Name)
+			obj := objs[i].DeepCopy()
+			obj.SetName(name)
+
+			if i == mdIndex {
+				if err := setWorkerGroup(obj, name, g, refPaths); err != nil {
+					return nil, err
+				}
+			}
+			newObjs = append(newObjs, *obj)
+		}
+	}
+
+	return &template{
+		variables:       t.Variables(),
+		variableMap:     t.VariableMap(),
+		targetNamespace: t.TargetNamespace(),
+		objs:            newObjs,
+	}, nil
+}
+
+// setWorkerGroup sets the replicas and the Kubernetes version of the worker group on the MachineDeployment, and
+// updates the given references to the bootstrap and infrastructure templates, which are renamed after the MachineDeployment.
+func setWorkerGroup(md *unstructured.Unstructured, name string, g WorkerGroup, refPaths [][]string) error {
+	if err := unstructured.SetNestedField(md.Object, g.Replicas, "spec", "replicas"); err != nil {
+		return errors.Wrapf(err, "failed to set the replicas of MachineDeployment %q", name)
+	}
+	if g.KubernetesVersion != "" {
+		if err := unstructured.SetNestedField(md.Object, g.KubernetesVersion, "spec", "template", "spec", "version"); err != nil {
+			return errors.Wrapf(err, "failed to set the Kubernetes version of MachineDeployment %q", name)
+		}
+	}
+	for _, path := range refPaths {
+		namePath := append(append([]string{}, path...), "name")
+		if err := unstructured.SetNestedField(md.Object, name, namePath...); err != nil {
+			return errors.Wrapf(err, "failed to set the template references of MachineDeployment %q", name)
+		}
+	}
+	return nil
+}
+
+func isMachineDeployment(obj unstructured.Unstructured) bool {
+	return obj.GetKind() == machineDeploymentKind && obj.GroupVersionKind().Group == clusterv1.GroupVersion.Group
+}
+
+// isReferencedBy returns true if the object is the target of the reference; the version of the reference is ignored.
+func isReferencedBy(obj unstructured.Unstructured, ref map[string]interface{}, namespace string) bool {
+	apiVersion, _ := ref["apiVersion"].(string)
+	kind, _ := ref["kind"].(string)
+	name, _ := ref["name"].(string)
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return false
+	}
+	return obj.GetKind() == kind && obj.GroupVersionKind().Group == gv.Group && obj.GetName() == name && obj.GetNamespace() == namespace
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package repository
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+var workerTemplateYaml = []byte(`apiVersion: cluster.x-k8s.io/v1alpha4
+kind: Cluster
+metadata:
+  name: test
+  namespace: ns1
+---
+apiVersion: bootstrap.cluster.x-k8s.io/v1alpha4
+kind: KubeadmConfigTemplate
+metadata:
+  name: test-md-0
+  namespace: ns1
+---
+apiVersion: infrastructure.cluster.x-k8s.io/v1alpha4
+kind: DockerMachineTemplate
+metadata:
+  name: test-md-0
+  namespace: ns1
+---
+apiVersion: cluster.x-k8s.io/v1alpha4
+kind: MachineDeployment
+metadata:
+  name: test-md-0
+  namespace: ns1
+spec:
+  clusterName: test
+  replicas: 1
+  template:
+    spec:
+      clusterName: test
+      version: v1.19.1
+      bootstrap:
+        configRef:
+          apiVersion: bootstrap.cluster.x-k8s.io/v1alpha3
+          kind: KubeadmConfigTemplate
+          name: test-md-0
+      infrastructureRef:
+        apiVersion: infrastructure.cluster.x-k8s.io/v1alpha4
+        kind: DockerMachineTemplate
+        name: external
+`)
+
+func Test_NewTemplateWithWorkerGroups(t *testing.T) {
+	g := NewWithT(t)
+
+	objs, err := utilyaml.ToUnstructured(workerTemplateYaml)
+	g.Expect(err).NotTo(HaveOccurred())
+	base := &template{targetNamespace: "ns1", objs: objs}
+
+	got, err := NewTemplateWithWorkerGroups(base, "test", []WorkerGroup{
+		{Name: "md-gpu", Replicas: 3},
+		{Name: "md-small", Replicas: 10, KubernetesVersion: "v1.20.2"},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(got.TargetNamespace()).To(Equal("ns1"))
+
+	var names []string
+	for _, o := range got.Objs() {
+		names = append(names, o.GetKind()+"/"+o.GetName())
+	}
+	// The DockerMachineTemplate referenced by the MachineDeployment is not defined in the template, so it is left untouched.
+	g.Expect(names).To(Equal([]string{
+		"Cluster/test",
+		"KubeadmConfigTemplate/test-md-gpu",
+		"KubeadmConfigTemplate/test-md-small",
+		"DockerMachineTemplate/test-md-0",
+		"MachineDeployment/test-md-gpu",
+		"MachineDeployment/test-md-small",
+	}))
+
+	mdGPU, mdSmall := got.Objs()[4], got.Objs()[5]
+	for _, tt := range []struct {
+		md                       unstructured.Unstructured
+		replicas                 int64
+		version, bootstrapConfig string
+	}{
+		{md: mdGPU, replicas: 3, version: "v1.19.1", bootstrapConfig: "test-md-gpu"},
+		{md: mdSmall, replicas: 10, version: "v1.20.2", bootstrapConfig: "test-md-small"},
+	} {
+		replicas, _, _ := unstructured.NestedInt64(tt.md.Object, "spec", "replicas")
+		g.Expect(replicas).To(Equal(tt.replicas))
+		version, _, _ := unstructured.NestedString(tt.md.Object, "spec", "template", "spec", "version")
+		g.Expect(version).To(Equal(tt.version))
+		bootstrapConfig, _, _ := unstructured.NestedString(tt.md.Object, "spec", "template", "spec", "bootstrap", "configRef", "name")
+		g.Expect(bootstrapConfig).To(Equal(tt.bootstrapConfig))
+		infrastructure, _, _ := unstructured.NestedString(tt.md.Object, "spec", "template", "spec", "infrastructureRef", "name")
+		g.Expect(infrastructure).To(Equal("external"))
+	}
+
+	// The base template is not changed.
+	g.Expect(base.Objs()[3].GetName()).To(Equal("test-md-0"))
+}
+
+func Test_NewTemplateWithWorkerGroups_Errors(t *testing.T) {
+	objs, err := utilyaml.ToUnstructured(workerTemplateYaml)
+	NewWithT(t).Expect(err).NotTo(HaveOccurred())
+
+	tests := []struct {
+		name   string
+		objs   []unstructured.Unstructured
+		groups []WorkerGroup
+	}{
+		{
+			name:   "template without MachineDeployments",
+			objs:   objs[:3],
+			groups: []WorkerGroup{{Name: "md-gpu", Replicas: 3}},
+		},
+		{
+			name:   "template with more than one MachineDeployment",
+			objs:   append(append([]unstructured.Unstructured{}, objs...), objs[3]),
+			groups: []WorkerGroup{{Name: "md-gpu", Replicas: 3}},
+		},
+		{
+			name:   "duplicated worker groups",
+			objs:   objs,
+			groups: []WorkerGroup{{Name: "md-gpu", Replicas: 3}, {Name: "md-gpu", Replicas: 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			_, err := NewTemplateWithWorkerGroups(&template{objs: tt.objs}, "test", tt.groups)
+			g.Expect(err).To(HaveOccurred())
+		})
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+)
+
+// RepositoryCacheClient exposes the management of the local repository cache; it is not part of Client,
+// so the implementations of Client are not required to support it.
+type RepositoryCacheClient interface {
+	// SyncRepositoryCache stores the provider repositories in the local repository cache, so they can be
+	// read later without accessing the network.
+	SyncRepositoryCache(options SyncRepositoryCacheOptions) ([]SyncedRepository, error)
+}
+
+// Ensure clusterctlClient implements RepositoryCacheClient.
+var _ RepositoryCacheClient = &clusterctlClient{}
+
+// NewRepositoryCacheClient returns a RepositoryCacheClient.
+func NewRepositoryCacheClient(path string, options ...Option) (RepositoryCacheClient, error) {
+	return newClusterctlClient(path, options...)
+}
+
+// SyncRepositoryCacheOptions carries the options supported by SyncRepositoryCache.
+type SyncRepositoryCacheOptions struct {
+	// Directory is the folder of the local repository cache.
+	Directory string
+
+	// Providers to store in the local repository cache, in the form name[:version]; if a version is not specified,
+	// the default version of the provider repository is used. If empty, all the configured providers are stored.
+	Providers []string
+
+	// Flavors of the cluster templates to store in the cache for infrastructure providers, in addition to the default one.
+	Flavors []string
+}
+
+// SyncedRepository documents a provider repository stored in the local repository cache.
+type SyncedRepository struct {
+	Name    string
+	Type    string
+	Version string
+}
+
+func (c *clusterctlClient) SyncRepositoryCache(options SyncRepositoryCacheOptions) ([]SyncedRepository, error) {
+	providers, err := c.configClient.Providers().List()
+	if err != nil {
+		return nil, err
+	}
+
+	// Identify the providers to store in the cache, and the corresponding versions.
+	versions := map[string]string{}
+	for _, p := range options.Providers {
+		name, version, err := parseProviderName(p)
+		if err != nil {
+			return nil, err
+		}
+		versions[name] = version
+	}
+
+	selected := []config.Provider{}
+	for _, p := range providers {
+		if _, ok := versions[p.Name()]; len(versions) > 0 && !ok {
+			continue
+		}
+		selected = append(selected, p)
+	}
+	for name := range versions {
+		found := false
+		for _, p := range selected {
+			if p.Name() == name {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, errors.Errorf("failed to get configuration for the provider %q", name)
+		}
+	}
+
+	synced := make([]SyncedRepository, 0, len(selected))
+	for _, p := range selected {
+		version, err := repository.SyncLocalRepositoryCache(p, c.configClient.Variables(), repository.SyncLocalRepositoryCacheOptions{
+			Directory: options.Directory,
+			Version:   versions[p.Name()],
+			Flavors:   options.Flavors,
+		})
+		if err != nil {
+			return nil, err
+		}
+		synced = append(synced, SyncedRepository{Name: p.Name(), Type: string(p.Type()), Version: version})
+	}
+	return synced, nil
+}
+
+// withLocalRepositoryCache returns a copy of the client reading the provider repositories from the local repository
+// cache only; the variables of the config client are copied, so the setting doesn't leak to other operations.
+func (c *clusterctlClient) withLocalRepositoryCache(dir string) *clusterctlClient {
+	configClient := &localRepositoryCacheConfigClient{
+		Client: c.configClient,
+		variables: &localRepositoryCacheVariablesClient{
+			VariablesClient: c.configClient.Variables(),
+			dir:             dir,
+		},
+	}
+
+	cc := *c
+	cc.configClient = configClient
+	cc.repositoryClientFactory = defaultRepositoryFactory(configClient)
+	return &cc
+}
+
+// localRepositoryCacheConfigClient is a config.Client overriding the local repository cache variable.
+type localRepositoryCacheConfigClient struct {
+	config.Client
+	variables config.VariablesClient
+}
+
+func (c *localRepositoryCacheConfigClient) Variables() config.VariablesClient {
+	return c.variables
+}
+
+// localRepositoryCacheVariablesClient is a config.VariablesClient overriding the local repository cache variable;
+// all the other variables are read from and written to the wrapped VariablesClient.
+type localRepositoryCacheVariablesClient struct {
+	config.VariablesClient
+	dir string
+}
+
+func (v *localRepositoryCacheVariablesClient) Get(key string) (string, error) {
+	if key == repository.LocalRepositoryCacheKey {
+		return v.dir, nil
+	}
+	return v.VariablesClient.Get(key)
+}
@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/repository"
+)
+
+func Test_clusterctlClient_withLocalRepositoryCache(t *testing.T) {
+	g := NewWithT(t)
+
+	config := newFakeConfig().WithVar("foo", "bar")
+	client := newFakeClient(config)
+
+	cc := client.internalClient.withLocalRepositoryCache("/tmp/cache")
+
+	// The copy reads the local repository cache from the option, and all the other variables from the config.
+	got, err := cc.configClient.Variables().Get(repository.LocalRepositoryCacheKey)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(Equal("/tmp/cache"))
+
+	got, err = cc.configClient.Variables().Get("foo")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(Equal("bar"))
+
+	// The variables of the original config are not changed.
+	_, err = config.Variables().Get(repository.LocalRepositoryCacheKey)
+	g.Expect(err).To(HaveOccurred())
+	_, err = client.internalClient.configClient.Variables().Get(repository.LocalRepositoryCacheKey)
+	g.Expect(err).To(HaveOccurred())
+}
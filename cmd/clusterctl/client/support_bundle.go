@@ -0,0 +1,76 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/alpha"
+)
+
+// SupportBundleOptions carries the options supported by SupportBundle.
+type SupportBundleOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace where the Cluster API objects and events are collected from. If unspecified, the current namespace will be used.
+	Namespace string
+
+	// AllNamespaces collects the Cluster API objects and events from all the namespaces. If set, Namespace is ignored.
+	AllNamespaces bool
+
+	// LogTailLines defines the number of lines to collect from the end of each provider controller log.
+	// If not positive, the entire log is collected.
+	LogTailLines int64
+
+	// Output is where the support bundle, a gzipped tarball, is written to.
+	Output io.Writer
+}
+
+// SupportBundle collects the Cluster API objects, the provider controller logs, the webhook configurations, the CRD versions
+// and the events existing in a management cluster into a gzipped tarball, with secret values, kubeconfigs and bootstrap
+// data redacted.
+func (c *clusterctlClient) SupportBundle(options SupportBundleOptions) error {
+	if options.Output == nil {
+		return errors.New("output must be set")
+	}
+
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return err
+	}
+
+	var namespaces []string
+	if !options.AllNamespaces {
+		// If the option specifying the Namespace is empty, try to detect it.
+		if options.Namespace == "" {
+			currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+			if err != nil {
+				return err
+			}
+			options.Namespace = currentNamespace
+		}
+		namespaces = []string{options.Namespace}
+	}
+
+	return c.alphaClient.SupportBundle().Collect(clusterClient.Proxy(), options.Output, alpha.SupportBundleOptions{
+		Namespaces:   namespaces,
+		LogTailLines: options.LogTailLines,
+	})
+}
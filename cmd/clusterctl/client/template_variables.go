@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TemplateVariableTypeString is the type of a template variable whose value is a string.
+	TemplateVariableTypeString = "string"
+	// TemplateVariableTypeInteger is the type of a template variable whose value is an integer.
+	TemplateVariableTypeInteger = "integer"
+	// TemplateVariableTypeBoolean is the type of a template variable whose value is a boolean.
+	TemplateVariableTypeBoolean = "boolean"
+)
+
+// TemplateVariable describes a variable expected by a template, e.g. for building a form for
+// collecting the variable values.
+type TemplateVariable struct {
+	// Name of the variable.
+	Name string `json:"name"`
+
+	// Type of the variable, inferred from the default value; variables without a default value are of type string.
+	Type string `json:"type"`
+
+	// Default value of the variable, if any.
+	Default *string `json:"default,omitempty"`
+
+	// Required is true if the variable has no default value, and thus a value must be provided.
+	Required bool `json:"required"`
+}
+
+// GetTemplateVariables returns the list of the variables expected by a template, sorted by name.
+// The variableMap is the map of the variables with their default values, as returned by the
+// VariableMap func of a Template or of a YamlPrinter.
+func GetTemplateVariables(variableMap map[string]*string) []TemplateVariable {
+	variables := make([]TemplateVariable, 0, len(variableMap))
+	for name, defaultValue := range variableMap {
+		variables = append(variables, TemplateVariable{
+			Name:     name,
+			Type:     inferTemplateVariableType(defaultValue),
+			Default:  defaultValue,
+			Required: defaultValue == nil,
+		})
+	}
+	sort.Slice(variables, func(i, j int) bool {
+		return variables[i].Name < variables[j].Name
+	})
+	return variables
+}
+
+// inferTemplateVariableType infers the type of a template variable from its default value.
+func inferTemplateVariableType(defaultValue *string) string {
+	if defaultValue == nil {
+		return TemplateVariableTypeString
+	}
+	if _, err := strconv.ParseInt(*defaultValue, 10, 64); err == nil {
+		return TemplateVariableTypeInteger
+	}
+	switch strings.ToLower(*defaultValue) {
+	case "true", "false":
+		return TemplateVariableTypeBoolean
+	}
+	return TemplateVariableTypeString
+}
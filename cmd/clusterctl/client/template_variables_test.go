@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
+)
+
+func TestGetTemplateVariables(t *testing.T) {
+	g := NewWithT(t)
+
+	got := GetTemplateVariables(map[string]*string{
+		"WORKER_MACHINE_COUNT": pointer.StringPtr("3"),
+		"CLUSTER_NAME":         nil,
+		"ENABLE_FOO":           pointer.StringPtr("true"),
+		"IMAGE":                pointer.StringPtr("ubuntu-20.04"),
+		"EMPTY":                pointer.StringPtr(""),
+	})
+
+	g.Expect(got).To(Equal([]TemplateVariable{
+		{Name: "CLUSTER_NAME", Type: TemplateVariableTypeString, Required: true},
+		{Name: "EMPTY", Type: TemplateVariableTypeString, Default: pointer.StringPtr("")},
+		{Name: "ENABLE_FOO", Type: TemplateVariableTypeBoolean, Default: pointer.StringPtr("true")},
+		{Name: "IMAGE", Type: TemplateVariableTypeString, Default: pointer.StringPtr("ubuntu-20.04")},
+		{Name: "WORKER_MACHINE_COUNT", Type: TemplateVariableTypeInteger, Default: pointer.StringPtr("3")},
+	}))
+}
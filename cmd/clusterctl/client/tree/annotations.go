@@ -18,6 +18,7 @@ package tree
 
 import (
 	"strconv"
+	"strings"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -26,6 +27,10 @@ const (
 	// ShowObjectConditionsAnnotation documents that the presentation layer should show all the conditions for the object.
 	ShowObjectConditionsAnnotation = "tree.cluster.x-k8s.io.io/show-conditions"
 
+	// ShowConditionTypesAnnotation contains the list of comma separated condition types that the presentation layer
+	// should show for the object; if not set, all the conditions are shown.
+	ShowConditionTypesAnnotation = "tree.cluster.x-k8s.io.io/show-condition-types"
+
 	// ObjectMetaNameAnnotation contains the meta name that should be used for the object in the presentation layer,
 	// e.g. control plane for KCP.
 	ObjectMetaNameAnnotation = "tree.cluster.x-k8s.io.io/meta-name"
@@ -103,6 +108,22 @@ func IsShowConditionsObject(obj client.Object) bool {
 	return false
 }
 
+// GetShowConditionTypes returns the condition types the presentation layer should show for the object,
+// or nil if all the conditions should be shown.
+func GetShowConditionTypes(obj client.Object) []string {
+	val, ok := getAnnotation(obj, ShowConditionTypesAnnotation)
+	if !ok {
+		return nil
+	}
+	var types []string
+	for _, t := range strings.Split(val, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
 func getAnnotation(obj client.Object, annotation string) (string, bool) {
 	if obj == nil {
 		return "", false
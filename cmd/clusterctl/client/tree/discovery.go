@@ -38,6 +38,13 @@ type DiscoverOptions struct {
 	// DisableGrouping disable grouping machines objects in case the ready condition
 	// has the same Status, Severity and Reason
 	DisableGrouping bool
+
+	// ShowConditionTypes is a list of comma separated condition types; if set, only the conditions of the given types
+	// are shown for the objects selected by ShowOtherConditions, or for all the objects if ShowOtherConditions is empty.
+	ShowConditionTypes string
+
+	// CollapseReady hides the children of the objects whose ready condition is true.
+	CollapseReady bool
 }
 
 func (d DiscoverOptions) toObjectTreeOptions() ObjectTreeOptions {
@@ -45,6 +52,8 @@ func (d DiscoverOptions) toObjectTreeOptions() ObjectTreeOptions {
 		ShowOtherConditions: d.ShowOtherConditions,
 		DisableNoEcho:       d.DisableNoEcho,
 		DisableGrouping:     d.DisableGrouping,
+		ShowConditionTypes:  d.ShowConditionTypes,
+		CollapseReady:       d.CollapseReady,
 	}
 }
 
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"sort"
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ObjectNode is a machine readable representation of an object in the ObjectTree, and of its children.
+type ObjectNode struct {
+	// Kind of the object; for virtual objects this is the kind of the objects they are grouping, e.g. MachineDeployment.
+	Kind string `json:"kind"`
+
+	// Namespace of the object.
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object; for virtual objects this is a descriptive name, e.g. Workers.
+	Name string `json:"name"`
+
+	// MetaName is the name used to make the tree more consistent for the users, e.g. ClusterInfrastructure.
+	MetaName string `json:"metaName,omitempty"`
+
+	// Virtual is true if the object does not exist in the cluster but was added to organize the tree.
+	Virtual bool `json:"virtual,omitempty"`
+
+	// GroupItems are the names of the objects represented by a group object, if any.
+	GroupItems []string `json:"groupItems,omitempty"`
+
+	// Deleting is true if the object is being deleted.
+	Deleting bool `json:"deleting,omitempty"`
+
+	// Ready is the Ready condition of the object, if any.
+	Ready *clusterv1.Condition `json:"ready,omitempty"`
+
+	// Conditions are the other conditions shown for the object, i.e. the conditions selected by the
+	// ShowOtherConditions and ShowConditionTypes options or the DeletionBlocked condition.
+	Conditions []clusterv1.Condition `json:"conditions,omitempty"`
+
+	// Children of the object, sorted by kind and name.
+	Children []ObjectNode `json:"children,omitempty"`
+}
+
+// ToObjectNode returns the machine readable representation of the tree, starting from the root object.
+func (od ObjectTree) ToObjectNode() ObjectNode {
+	return od.toObjectNode(od.root)
+}
+
+func (od ObjectTree) toObjectNode(obj client.Object) ObjectNode {
+	node := ObjectNode{
+		Kind:      obj.GetObjectKind().GroupVersionKind().Kind,
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		MetaName:  GetMetaName(obj),
+		Virtual:   IsVirtualObject(obj),
+		Deleting:  !obj.GetDeletionTimestamp().IsZero(),
+		Ready:     GetReadyCondition(obj),
+	}
+
+	if IsGroupObject(obj) {
+		node.Kind = strings.TrimSuffix(node.Kind, "Group")
+		node.GroupItems = strings.Split(GetGroupItems(obj), GroupItemsSeparator)
+	}
+
+	var otherConditions []*clusterv1.Condition
+	if IsShowConditionsObject(obj) {
+		otherConditions = FilterConditionsByType(GetOtherConditions(obj), GetShowConditionTypes(obj))
+	} else if deletionBlocked := GetDeletionBlockedCondition(obj); deletionBlocked != nil {
+		otherConditions = []*clusterv1.Condition{deletionBlocked}
+	}
+	for _, c := range otherConditions {
+		node.Conditions = append(node.Conditions, *c)
+	}
+
+	children := od.GetObjectsByParent(obj.GetUID())
+	sort.Slice(children, func(i, j int) bool {
+		ki, kj := children[i].GetObjectKind().GroupVersionKind().Kind, children[j].GetObjectKind().GroupVersionKind().Kind
+		return ki < kj || (ki == kj && children[i].GetName() < children[j].GetName())
+	})
+	for _, child := range children {
+		node.Children = append(node.Children, od.toObjectNode(child))
+	}
+
+	return node
+}
+
+// FilterConditionsByType returns the conditions of the given types, or all the conditions if no type is given.
+func FilterConditionsByType(conditions []*clusterv1.Condition, types []string) []*clusterv1.Condition {
+	if len(types) == 0 {
+		return conditions
+	}
+	var filtered []*clusterv1.Condition
+	for _, c := range conditions {
+		for _, t := range types {
+			if string(c.Type) == t {
+				filtered = append(filtered, c)
+				break
+			}
+		}
+	}
+	return filtered
+}
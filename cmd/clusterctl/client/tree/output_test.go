@@ -0,0 +1,95 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tree
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func Test_ToObjectNode(t *testing.T) {
+	g := NewWithT(t)
+
+	ready := conditions.TrueCondition(clusterv1.ReadyCondition)
+	foo := conditions.TrueCondition("Foo")
+	bar := conditions.TrueCondition("Bar")
+
+	root := fakeCluster("root", withClusterCondition(ready))
+	tree := NewObjectTree(root, ObjectTreeOptions{ShowOtherConditions: "Machine/m1", ShowConditionTypes: "Foo"})
+
+	workers := VirtualObject("ns", "WorkerGroup", "Workers")
+	tree.Add(root, workers)
+	tree.Add(workers, fakeMachine("m2", withMachineCondition(ready)))
+	tree.Add(workers, fakeMachine("m1", withMachineCondition(ready), withMachineCondition(foo), withMachineCondition(bar)))
+
+	node := tree.ToObjectNode()
+	g.Expect(node.Kind).To(Equal("Cluster"))
+	g.Expect(node.Name).To(Equal("root"))
+	g.Expect(node.Ready).ToNot(BeNil())
+	g.Expect(node.Ready.Type).To(Equal(clusterv1.ReadyCondition))
+	g.Expect(node.Children).To(HaveLen(1))
+
+	workersNode := node.Children[0]
+	g.Expect(workersNode.Name).To(Equal("Workers"))
+	g.Expect(workersNode.Virtual).To(BeTrue())
+	g.Expect(workersNode.Ready).To(BeNil())
+
+	// Children are sorted by kind and name, and only the selected conditions are reported.
+	g.Expect(workersNode.Children).To(HaveLen(2))
+	g.Expect(workersNode.Children[0].Name).To(Equal("m1"))
+	g.Expect(workersNode.Children[0].Conditions).To(HaveLen(1))
+	g.Expect(workersNode.Children[0].Conditions[0].Type).To(Equal(clusterv1.ConditionType("Foo")))
+	g.Expect(workersNode.Children[1].Name).To(Equal("m2"))
+	g.Expect(workersNode.Children[1].Conditions).To(BeEmpty())
+}
+
+func Test_FilterConditionsByType(t *testing.T) {
+	foo := conditions.TrueCondition("Foo")
+	bar := conditions.TrueCondition("Bar")
+
+	tests := []struct {
+		name  string
+		types []string
+		want  []*clusterv1.Condition
+	}{
+		{
+			name:  "returns all the conditions without types",
+			types: nil,
+			want:  []*clusterv1.Condition{foo, bar},
+		},
+		{
+			name:  "returns the conditions of the given types",
+			types: []string{"Bar", "Baz"},
+			want:  []*clusterv1.Condition{bar},
+		},
+		{
+			name:  "returns no conditions if none matches",
+			types: []string{"Baz"},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(FilterConditionsByType([]*clusterv1.Condition{foo, bar}, tt.types)).To(Equal(tt.want))
+		})
+	}
+}
@@ -42,6 +42,15 @@ type ObjectTreeOptions struct {
 	// DisableGrouping disables grouping sibling objects in case the ready condition
 	// has the same Status, Severity and Reason
 	DisableGrouping bool
+
+	// ShowConditionTypes is a list of comma separated condition types; if set, the presentation layer shows only
+	// the conditions of the given types for the objects selected by ShowOtherConditions, or for all the objects
+	// if ShowOtherConditions is empty.
+	ShowConditionTypes string
+
+	// CollapseReady hides the children of the objects whose ready condition is true, so only
+	// the branches of the tree which are not ready are expanded.
+	CollapseReady bool
 }
 
 // ObjectTree defines an object tree representing the status of a Cluster API cluster.
@@ -72,10 +81,24 @@ func (od ObjectTree) Add(parent, obj client.Object, opts ...AddObjectOption) (ad
 	objReady := GetReadyCondition(obj)
 	parentReady := GetReadyCondition(parent)
 
+	// If it is requested to expand only the branches which are not ready, and the parent's ready
+	// condition is true, return early; the root object is always expanded.
+	if od.options.CollapseReady && parent.GetUID() != od.root.GetUID() && parentReady != nil && parentReady.Status == corev1.ConditionTrue {
+		return false, false
+	}
+
 	// If it is requested to show all the conditions for the object, add
 	// the ShowObjectConditionsAnnotation to signal this to the presentation layer.
-	if isObjDebug(obj, od.options.ShowOtherConditions) {
+	// NOTE: If only the condition types to show are provided, the conditions are shown for all the objects.
+	showOtherConditions := od.options.ShowOtherConditions
+	if showOtherConditions == "" && od.options.ShowConditionTypes != "" {
+		showOtherConditions = "all"
+	}
+	if isObjDebug(obj, showOtherConditions) {
 		addAnnotation(obj, ShowObjectConditionsAnnotation, "True")
+		if od.options.ShowConditionTypes != "" {
+			addAnnotation(obj, ShowConditionTypesAnnotation, od.options.ShowConditionTypes)
+		}
 	}
 
 	// If the object should be hidden if the object's ready condition is true ot it has the
@@ -529,6 +529,100 @@ func Test_Add_setsObjectMetaNameAnnotation(t *testing.T) {
 	}
 }
 
+func Test_Add_setsShowConditionTypesAnnotation(t *testing.T) {
+	parent := fakeCluster("parent")
+	obj := fakeMachine("my-machine")
+
+	tests := []struct {
+		name        string
+		treeOptions ObjectTreeOptions
+		wantShow    bool
+		wantTypes   []string
+	}{
+		{
+			name:        "condition types without a filter select all the objects",
+			treeOptions: ObjectTreeOptions{ShowConditionTypes: "Foo, Bar"},
+			wantShow:    true,
+			wantTypes:   []string{"Foo", "Bar"},
+		},
+		{
+			name:        "condition types with a filter selecting my machine",
+			treeOptions: ObjectTreeOptions{ShowOtherConditions: "Machine", ShowConditionTypes: "Foo"},
+			wantShow:    true,
+			wantTypes:   []string{"Foo"},
+		},
+		{
+			name:        "condition types with a filter not selecting my machine",
+			treeOptions: ObjectTreeOptions{ShowOtherConditions: "Cluster", ShowConditionTypes: "Foo"},
+			wantShow:    false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := parent.DeepCopy()
+			tree := NewObjectTree(root, tt.treeOptions)
+
+			g := NewWithT(t)
+			getAdded, gotVisible := tree.Add(root, obj.DeepCopy())
+			g.Expect(getAdded).To(BeTrue())
+			g.Expect(gotVisible).To(BeTrue())
+
+			gotObj := tree.GetObject("my-machine")
+			g.Expect(gotObj).ToNot(BeNil())
+			g.Expect(IsShowConditionsObject(gotObj)).To(Equal(tt.wantShow))
+			g.Expect(GetShowConditionTypes(gotObj)).To(Equal(tt.wantTypes))
+		})
+	}
+}
+
+func Test_Add_CollapseReady(t *testing.T) {
+	root := fakeCluster("root",
+		withClusterCondition(conditions.TrueCondition(clusterv1.ReadyCondition)),
+	)
+
+	tests := []struct {
+		name        string
+		treeOptions ObjectTreeOptions
+		parentReady *clusterv1.Condition
+		wantNode    bool
+	}{
+		{
+			name:        "should add the children of ready objects if CollapseReady is not set",
+			treeOptions: ObjectTreeOptions{},
+			parentReady: conditions.TrueCondition(clusterv1.ReadyCondition),
+			wantNode:    true,
+		},
+		{
+			name:        "should not add the children of ready objects if CollapseReady is set",
+			treeOptions: ObjectTreeOptions{CollapseReady: true},
+			parentReady: conditions.TrueCondition(clusterv1.ReadyCondition),
+			wantNode:    false,
+		},
+		{
+			name:        "should add the children of not ready objects if CollapseReady is set",
+			treeOptions: ObjectTreeOptions{CollapseReady: true},
+			parentReady: conditions.FalseCondition(clusterv1.ReadyCondition, "", clusterv1.ConditionSeverityInfo, ""),
+			wantNode:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := NewObjectTree(root.DeepCopy(), tt.treeOptions)
+
+			g := NewWithT(t)
+			// The children of the root object are always added.
+			parent := fakeMachine("my-parent", withMachineCondition(tt.parentReady))
+			getAdded, gotVisible := tree.Add(tree.GetRoot(), parent)
+			g.Expect(getAdded).To(BeTrue())
+			g.Expect(gotVisible).To(BeTrue())
+
+			getAdded, gotVisible = tree.Add(parent, fakeMachine("my-machine"))
+			g.Expect(getAdded).To(Equal(tt.wantNode))
+			g.Expect(gotVisible).To(Equal(tt.wantNode))
+		})
+	}
+}
+
 func Test_Add_NoEcho(t *testing.T) {
 	parent := fakeCluster("parent",
 		withClusterCondition(conditions.TrueCondition(clusterv1.ReadyCondition)),
@@ -733,6 +827,54 @@ func withClusterAnnotation(name, value string) func(*clusterv1.Cluster) {
 	}
 }
 
+func Test_GetDeletionBlockedCondition(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+	withDeletionTimestamp := func(m *clusterv1.Machine) {
+		m.DeletionTimestamp = &deletionTimestamp
+	}
+	blocked := conditions.TrueCondition(clusterv1.DeletionBlockedCondition)
+
+	tests := []struct {
+		name string
+		obj  *clusterv1.Machine
+		want bool
+	}{
+		{
+			name: "Object not being deleted",
+			obj:  fakeMachine("my-machine", withMachineCondition(blocked)),
+			want: false,
+		},
+		{
+			name: "Object being deleted without the DeletionBlocked condition",
+			obj:  fakeMachine("my-machine", withDeletionTimestamp),
+			want: false,
+		},
+		{
+			name: "Object being deleted with the DeletionBlocked condition false",
+			obj:  fakeMachine("my-machine", withDeletionTimestamp, withMachineCondition(conditions.FalseCondition(clusterv1.DeletionBlockedCondition, "", clusterv1.ConditionSeverityInfo, ""))),
+			want: false,
+		},
+		{
+			name: "Object being deleted with the DeletionBlocked condition true",
+			obj:  fakeMachine("my-machine", withDeletionTimestamp, withMachineCondition(blocked)),
+			want: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got := GetDeletionBlockedCondition(tt.obj)
+			if !tt.want {
+				g.Expect(got).To(BeNil())
+				return
+			}
+			g.Expect(got).ToNot(BeNil())
+			g.Expect(got.Type).To(Equal(clusterv1.DeletionBlockedCondition))
+		})
+	}
+}
+
 func withClusterCondition(c *clusterv1.Condition) func(*clusterv1.Cluster) {
 	return func(m *clusterv1.Cluster) {
 		conditions.Set(m, c)
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"sort"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -35,6 +36,21 @@ func GetReadyCondition(obj client.Object) *clusterv1.Condition {
 	return conditions.Get(getter, clusterv1.ReadyCondition)
 }
 
+// GetDeletionBlockedCondition returns the DeletionBlocked condition for an object being deleted, if it is true.
+func GetDeletionBlockedCondition(obj client.Object) *clusterv1.Condition {
+	if obj.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+	getter := objToGetter(obj)
+	if getter == nil {
+		return nil
+	}
+	if c := conditions.Get(getter, clusterv1.DeletionBlockedCondition); c != nil && c.Status == corev1.ConditionTrue {
+		return c
+	}
+	return nil
+}
+
 // GetOtherConditions returns the other conditions (all the conditions except ready) for an object, if defined.
 func GetOtherConditions(obj client.Object) []*clusterv1.Condition {
 	getter := objToGetter(obj)
@@ -18,6 +18,7 @@ package client
 
 import (
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -67,9 +68,10 @@ func (c *clusterctlClient) PlanUpgrade(options PlanUpgradeOptions) ([]UpgradePla
 	aliasUpgradePlan := make([]UpgradePlan, len(upgradePlans))
 	for i, plan := range upgradePlans {
 		aliasUpgradePlan[i] = UpgradePlan{
-			Contract:     plan.Contract,
-			CoreProvider: plan.CoreProvider,
-			Providers:    plan.Providers,
+			Contract:              plan.Contract,
+			CoreProvider:          plan.CoreProvider,
+			Providers:             plan.Providers,
+			VersionSkewViolations: plan.VersionSkewViolations,
 		}
 	}
 
@@ -99,7 +101,23 @@ type ApplyUpgradeOptions struct {
 	ControlPlaneProviders []string
 
 	// InfrastructureProviders instance and versions (e.g. capa-system/aws:v0.5.0) to upgrade to. This field can be used as alternative to Contract.
+	// For all the provider types, the namespace of the instance (e.g. aws:v0.5.0) can be omitted if the management group
+	// has a single instance of the provider; only the given instances are upgraded.
 	InfrastructureProviders []string
+
+	// IgnoreVersionSkew applies the upgrade even if it violates the Kubernetes version skew policy of Cluster API,
+	// e.g. if the target API Version of Cluster API (contract) does not support the Kubernetes version of the management cluster.
+	IgnoreVersionSkew bool
+
+	// NoRollback leaves the providers as they are when the upgrade fails, instead of rolling back the providers already
+	// upgraded to the versions installed before the upgrade. A provider upgrade fails if its Deployments do not become
+	// Available, or if its webhooks have no ready endpoints. Rollbacks are supported only for upgrades within the same
+	// API Version of Cluster API (contract).
+	NoRollback bool
+
+	// WaitProviderTimeout is the time to wait for each upgraded provider to become available before considering the
+	// upgrade failed. Defaults to 5 minutes.
+	WaitProviderTimeout time.Duration
 }
 
 func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
@@ -135,6 +153,12 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 		return err
 	}
 
+	upgradeOptions := cluster.UpgradeOptions{
+		IgnoreVersionSkew:   options.IgnoreVersionSkew,
+		NoRollback:          options.NoRollback,
+		WaitProviderTimeout: options.WaitProviderTimeout,
+	}
+
 	// Check if the user want a custom upgrade
 	isCustomUpgrade := options.CoreProvider != "" ||
 		len(options.BootstrapProviders) > 0 ||
@@ -166,7 +190,7 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 		}
 
 		// Execute the upgrade using the custom upgrade items
-		if err := clusterClient.ProviderUpgrader().ApplyCustomPlan(coreProvider, upgradeItems...); err != nil {
+		if err := clusterClient.ProviderUpgrader().ApplyCustomPlan(upgradeOptions, coreProvider, upgradeItems...); err != nil {
 			return err
 		}
 
@@ -174,21 +198,43 @@ func (c *clusterctlClient) ApplyUpgrade(options ApplyUpgradeOptions) error {
 	}
 
 	// Otherwise we are upgrading a whole management group according to a clusterctl generated upgrade plan.
-	if err := clusterClient.ProviderUpgrader().ApplyPlan(coreProvider, options.Contract); err != nil {
+	if err := clusterClient.ProviderUpgrader().ApplyPlan(upgradeOptions, coreProvider, options.Contract); err != nil {
 		return err
 	}
 
 	return nil
 }
 
+// addUpgradeItems converts upgrade references in the form [namespace/]name:version into upgrade items; the namespace
+// of the references without one is resolved when creating the upgrade plan, if the management group has a single
+// instance of the provider.
 func addUpgradeItems(upgradeItems []cluster.UpgradeItem, providerType clusterctlv1.ProviderType, providers ...string) ([]cluster.UpgradeItem, error) {
 	for _, upgradeReference := range providers {
-		providerUpgradeItem, err := parseUpgradeItem(upgradeReference, providerType)
-		if err != nil {
-			return nil, err
+		var providerUpgradeItem *cluster.UpgradeItem
+		if strings.Contains(upgradeReference, "/") {
+			var err error
+			providerUpgradeItem, err = parseUpgradeItem(upgradeReference, providerType)
+			if err != nil {
+				return nil, err
+			}
+		} else {
+			name, version, err := parseProviderName(upgradeReference)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid provider name %q. Provider name should be in the form [namespace/]name:version", upgradeReference)
+			}
+			providerUpgradeItem = &cluster.UpgradeItem{
+				Provider: clusterctlv1.Provider{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: clusterctlv1.ManifestLabel(name, providerType),
+					},
+					ProviderName: name,
+					Type:         string(providerType),
+				},
+				NextVersion: version,
+			}
 		}
 		if providerUpgradeItem.NextVersion == "" {
-			return nil, errors.Errorf("invalid provider name %q. Provider name should be in the form namespace/name:version and version cannot be empty", upgradeReference)
+			return nil, errors.Errorf("invalid provider name %q. Provider name should be in the form [namespace/]name:version and version cannot be empty", upgradeReference)
 		}
 		upgradeItems = append(upgradeItems, *providerUpgradeItem)
 	}
@@ -451,3 +451,72 @@ func Test_parseUpgradeItem(t *testing.T) {
 		})
 	}
 }
+
+func Test_addUpgradeItems(t *testing.T) {
+	tests := []struct {
+		name      string
+		providers []string
+		want      []cluster.UpgradeItem
+		wantErr   bool
+	}{
+		{
+			name:      "namespace/provider:version",
+			providers: []string{"capa-system/aws:v0.5.0"},
+			want: []cluster.UpgradeItem{
+				{
+					Provider: clusterctlv1.Provider{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "capa-system",
+							Name:      clusterctlv1.ManifestLabel("aws", clusterctlv1.InfrastructureProviderType),
+						},
+						ProviderName: "aws",
+						Type:         string(clusterctlv1.InfrastructureProviderType),
+					},
+					NextVersion: "v0.5.0",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "provider:version, the namespace is resolved in the upgrade plan",
+			providers: []string{"aws:v0.5.0"},
+			want: []cluster.UpgradeItem{
+				{
+					Provider: clusterctlv1.Provider{
+						ObjectMeta: metav1.ObjectMeta{
+							Name: clusterctlv1.ManifestLabel("aws", clusterctlv1.InfrastructureProviderType),
+						},
+						ProviderName: "aws",
+						Type:         string(clusterctlv1.InfrastructureProviderType),
+					},
+					NextVersion: "v0.5.0",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:      "version missing",
+			providers: []string{"aws"},
+			wantErr:   true,
+		},
+		{
+			name:      "namespace empty",
+			providers: []string{"/aws:v0.5.0"},
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := addUpgradeItems(nil, clusterctlv1.InfrastructureProviderType, tt.providers...)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
@@ -0,0 +1,272 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/utils/pointer"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/cluster"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+// workloadFieldManager is the field manager used when applying objects to a workload cluster.
+const workloadFieldManager = "clusterctl"
+
+// WorkloadClusterOptions identifies the workload cluster to operate on.
+type WorkloadClusterOptions struct {
+	// Kubeconfig defines the kubeconfig to use for accessing the management cluster. If empty,
+	// default rules for kubeconfig discovery will be used.
+	Kubeconfig Kubeconfig
+
+	// Namespace is the namespace of the workload cluster in the management cluster.
+	Namespace string
+
+	// WorkloadClusterName is the name of the workload cluster.
+	WorkloadClusterName string
+}
+
+// WorkloadGetOptions carries all the options supported by WorkloadGet.
+type WorkloadGetOptions struct {
+	WorkloadClusterOptions
+
+	// Resource is the resource to get, e.g. nodes, pods, or deployments.apps; short names like po are supported.
+	Resource string
+
+	// Name is the name of the object to get; if empty, all the objects of the resource are listed.
+	Name string
+
+	// ResourceNamespace is the namespace of the objects to get in the workload cluster, if the resource is namespaced.
+	// If empty, the default namespace is used.
+	ResourceNamespace string
+
+	// AllNamespaces lists the objects of a namespaced resource across all the namespaces.
+	AllNamespaces bool
+}
+
+// WorkloadApplyOptions carries all the options supported by WorkloadApply.
+type WorkloadApplyOptions struct {
+	WorkloadClusterOptions
+
+	// Yaml defines the objects to apply, using server-side apply.
+	Yaml []byte
+
+	// ResourceNamespace is the namespace used for the namespaced objects without a namespace.
+	// If empty, the default namespace is used.
+	ResourceNamespace string
+}
+
+// WorkloadClient exposes the access to the resources of workload clusters; it is not part of Client,
+// so the implementations of Client are not required to support it.
+type WorkloadClient interface {
+	// WorkloadGet returns the objects of a resource in the workload cluster, using its kubeconfig secret.
+	WorkloadGet(options WorkloadGetOptions) ([]unstructured.Unstructured, error)
+
+	// WorkloadApply applies objects to the workload cluster using server-side apply, and returns the applied objects.
+	WorkloadApply(options WorkloadApplyOptions) ([]unstructured.Unstructured, error)
+}
+
+// Ensure clusterctlClient implements WorkloadClient.
+var _ WorkloadClient = &clusterctlClient{}
+
+// NewWorkloadClient returns a WorkloadClient.
+func NewWorkloadClient(path string, options ...Option) (WorkloadClient, error) {
+	return newClusterctlClient(path, options...)
+}
+
+func (c *clusterctlClient) WorkloadGet(options WorkloadGetOptions) ([]unstructured.Unstructured, error) {
+	w, err := c.workloadResourcesClient(options.WorkloadClusterOptions)
+	if err != nil {
+		return nil, err
+	}
+	return w.get(options)
+}
+
+func (c *clusterctlClient) WorkloadApply(options WorkloadApplyOptions) ([]unstructured.Unstructured, error) {
+	objs, err := utilyaml.ToUnstructured(options.Yaml)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to parse the objects to apply")
+	}
+
+	w, err := c.workloadResourcesClient(options.WorkloadClusterOptions)
+	if err != nil {
+		return nil, err
+	}
+	return w.apply(objs, options.ResourceNamespace)
+}
+
+// workloadResourcesClient returns a client for the resources of the given workload cluster, using the kubeconfig
+// secret in the management cluster.
+func (c *clusterctlClient) workloadResourcesClient(options WorkloadClusterOptions) (*workloadResources, error) {
+	clusterClient, err := c.clusterClientFactory(ClusterClientFactoryInput{Kubeconfig: options.Kubeconfig})
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Namespace == "" {
+		currentNamespace, err := clusterClient.Proxy().CurrentNamespace()
+		if err != nil {
+			return nil, err
+		}
+		if currentNamespace == "" {
+			return nil, errors.New("failed to identify the current namespace. Please specify the namespace where the workload cluster exists")
+		}
+		options.Namespace = currentNamespace
+	}
+
+	workloadCluster, ok := clusterClient.WorkloadCluster().(cluster.WorkloadClusterRESTConfigGetter)
+	if !ok {
+		return nil, errors.New("the management cluster client does not support accessing workload clusters")
+	}
+	config, err := workloadCluster.GetRESTConfig(options.WorkloadClusterName, options.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	return newWorkloadResources(config)
+}
+
+// workloadResources gets and applies objects of any resource in a workload cluster.
+type workloadResources struct {
+	client dynamic.Interface
+	mapper meta.RESTMapper
+}
+
+func newWorkloadResources(config *rest.Config) (*workloadResources, error) {
+	client, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a client for the workload cluster")
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create a discovery client for the workload cluster")
+	}
+	cachedDiscoveryClient := memory.NewMemCacheClient(discoveryClient)
+	mapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscoveryClient), cachedDiscoveryClient)
+
+	return &workloadResources{
+		client: client,
+		mapper: mapper,
+	}, nil
+}
+
+func (w *workloadResources) get(options WorkloadGetOptions) ([]unstructured.Unstructured, error) {
+	mapping, err := w.resourceMapping(options.Resource)
+	if err != nil {
+		return nil, err
+	}
+
+	var ri dynamic.ResourceInterface = w.client.Resource(mapping.Resource)
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		switch {
+		case options.AllNamespaces && options.Name == "":
+			// Namespaceable resource interface, listing across all the namespaces.
+		case options.ResourceNamespace != "":
+			ri = w.client.Resource(mapping.Resource).Namespace(options.ResourceNamespace)
+		default:
+			ri = w.client.Resource(mapping.Resource).Namespace(metav1.NamespaceDefault)
+		}
+	}
+
+	if options.Name != "" {
+		obj, err := ri.Get(context.TODO(), options.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get %s %q", mapping.Resource.Resource, options.Name)
+		}
+		return []unstructured.Unstructured{*obj}, nil
+	}
+
+	list, err := ri.List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s", mapping.Resource.Resource)
+	}
+	return list.Items, nil
+}
+
+func (w *workloadResources) apply(objs []unstructured.Unstructured, namespace string) ([]unstructured.Unstructured, error) {
+	if namespace == "" {
+		namespace = metav1.NamespaceDefault
+	}
+
+	applied := make([]unstructured.Unstructured, 0, len(objs))
+	for i := range objs {
+		obj := objs[i]
+		gvk := obj.GroupVersionKind()
+		mapping, err := w.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return applied, errors.Wrapf(err, "failed to get the resource for %s", gvk)
+		}
+
+		var ri dynamic.ResourceInterface = w.client.Resource(mapping.Resource)
+		if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			if obj.GetNamespace() == "" {
+				obj.SetNamespace(namespace)
+			}
+			ri = w.client.Resource(mapping.Resource).Namespace(obj.GetNamespace())
+		}
+
+		data, err := json.Marshal(obj.Object)
+		if err != nil {
+			return applied, errors.Wrapf(err, "failed to marshal %s %q", gvk.Kind, obj.GetName())
+		}
+		result, err := ri.Patch(context.TODO(), obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{
+			FieldManager: workloadFieldManager,
+			Force:        pointer.BoolPtr(true),
+		})
+		if err != nil {
+			return applied, errors.Wrapf(err, "failed to apply %s %q", gvk.Kind, obj.GetName())
+		}
+		applied = append(applied, *result)
+	}
+	return applied, nil
+}
+
+// resourceMapping returns the REST mapping for a resource in the form resource[.version][.group], e.g. deployments.apps.
+func (w *workloadResources) resourceMapping(resource string) (*meta.RESTMapping, error) {
+	resource = strings.ToLower(resource)
+	gvr, gr := schema.ParseResourceArg(resource)
+
+	var gvk schema.GroupVersionKind
+	var err error
+	if gvr != nil {
+		gvk, err = w.mapper.KindFor(*gvr)
+	}
+	if gvr == nil || gvk.Empty() {
+		gvk, err = w.mapper.KindFor(gr.WithVersion(""))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the resource type %q", resource)
+	}
+
+	mapping, err := w.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the resource type %q", resource)
+	}
+	return mapping, nil
+}
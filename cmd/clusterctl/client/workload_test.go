@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package client
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newFakeWorkloadResources(objs ...runtime.Object) *workloadResources {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Node"}, meta.RESTScopeRoot)
+	mapper.Add(schema.GroupVersionKind{Version: "v1", Kind: "Pod"}, meta.RESTScopeNamespace)
+	mapper.Add(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"}, meta.RESTScopeNamespace)
+
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "nodes"}:                      "NodeList",
+		{Version: "v1", Resource: "pods"}:                       "PodList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+	}
+	return &workloadResources{
+		client: dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objs...),
+		mapper: mapper,
+	}
+}
+
+func newWorkloadObject(apiVersion, kind, namespace, name string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion(apiVersion)
+	u.SetKind(kind)
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	return u
+}
+
+func Test_workloadResources_resourceMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource string
+		want     schema.GroupVersionResource
+		wantErr  bool
+	}{
+		{
+			name:     "core resource",
+			resource: "nodes",
+			want:     schema.GroupVersionResource{Version: "v1", Resource: "nodes"},
+		},
+		{
+			name:     "singular and mixed case",
+			resource: "Pod",
+			want:     schema.GroupVersionResource{Version: "v1", Resource: "pods"},
+		},
+		{
+			name:     "resource with group",
+			resource: "deployments.apps",
+			want:     schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:     "resource with version and group",
+			resource: "deployments.v1.apps",
+			want:     schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		},
+		{
+			name:     "unknown resource",
+			resource: "foos",
+			wantErr:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := newFakeWorkloadResources().resourceMapping(tt.resource)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got.Resource).To(Equal(tt.want))
+		})
+	}
+}
+
+func Test_workloadResources_get(t *testing.T) {
+	objs := []runtime.Object{
+		newWorkloadObject("v1", "Node", "", "node-1"),
+		newWorkloadObject("v1", "Node", "", "node-2"),
+		newWorkloadObject("v1", "Pod", "default", "pod-1"),
+		newWorkloadObject("v1", "Pod", "kube-system", "pod-2"),
+	}
+
+	tests := []struct {
+		name      string
+		options   WorkloadGetOptions
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name:      "list cluster scoped resource",
+			options:   WorkloadGetOptions{Resource: "nodes"},
+			wantNames: []string{"node-1", "node-2"},
+		},
+		{
+			name:      "get cluster scoped object",
+			options:   WorkloadGetOptions{Resource: "nodes", Name: "node-2"},
+			wantNames: []string{"node-2"},
+		},
+		{
+			name:      "list namespaced resource in the default namespace",
+			options:   WorkloadGetOptions{Resource: "pods"},
+			wantNames: []string{"pod-1"},
+		},
+		{
+			name:      "list namespaced resource in a namespace",
+			options:   WorkloadGetOptions{Resource: "pods", ResourceNamespace: "kube-system"},
+			wantNames: []string{"pod-2"},
+		},
+		{
+			name:      "list namespaced resource in all the namespaces",
+			options:   WorkloadGetOptions{Resource: "pods", AllNamespaces: true},
+			wantNames: []string{"pod-1", "pod-2"},
+		},
+		{
+			name:    "get object not existing",
+			options: WorkloadGetOptions{Resource: "pods", Name: "pod-2"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := newFakeWorkloadResources(objs...).get(tt.options)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			names := []string{}
+			for _, o := range got {
+				names = append(names, o.GetName())
+			}
+			g.Expect(names).To(ConsistOf(tt.wantNames))
+		})
+	}
+}
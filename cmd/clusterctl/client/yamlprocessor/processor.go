@@ -30,3 +30,12 @@ type Processor interface {
 	// yaml with values retrieved from the values getter
 	Process([]byte, func(string) (string, error)) ([]byte, error)
 }
+
+// VariableMapProcessor is an optional interface a Processor can implement to
+// provide the default values of the variables that a template requires.
+type VariableMapProcessor interface {
+	// GetVariableMap parses the template blob of bytes and provides a map of
+	// the variables that the template requires, with their default values;
+	// variables without a default value are mapped to nil.
+	GetVariableMap([]byte) (map[string]*string, error)
+}
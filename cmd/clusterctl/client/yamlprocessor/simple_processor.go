@@ -68,6 +68,20 @@ func (tp *SimpleProcessor) GetVariables(rawArtifact []byte) ([]string, error) {
 	return varNames, nil
 }
 
+// GetVariableMap returns a map of the variables specified in the yaml, with
+// their default values if specified in the format ${var:=default}.
+func (tp *SimpleProcessor) GetVariableMap(rawArtifact []byte) (map[string]*string, error) {
+	strArtifact := convertLegacyVars(string(rawArtifact))
+
+	t, err := parse.Parse(strArtifact)
+	if err != nil {
+		return nil, err
+	}
+	variables := make(map[string]*string)
+	traverseDefaults(t.Root, variables)
+	return variables, nil
+}
+
 // Process returns the final yaml with all the variables replaced with their
 // respective values. If there are variables without corresponding values, it
 // will return the raw yaml along with an error.
@@ -148,6 +162,49 @@ func traverse(root parse.Node, variables map[string]bool) {
 	}
 }
 
+// traverseDefaults recursively walks down the root node and tracks the variables
+// which are FuncNodes and their default values, if any.
+func traverseDefaults(root parse.Node, variables map[string]*string) {
+	switch v := root.(type) {
+	case *parse.ListNode:
+		// iterate through the list node
+		for _, ln := range v.Nodes {
+			traverseDefaults(ln, variables)
+		}
+	case *parse.FuncNode:
+		// a variable could be used more times, with a default value only in some of them
+		if variables[v.Param] == nil {
+			variables[v.Param] = defaultValue(v)
+		}
+		// default values can reference other variables, e.g. ${A:=${B}}
+		for _, arg := range v.Args {
+			traverseDefaults(arg, variables)
+		}
+	}
+}
+
+// defaultValue returns the default value of a variable in the format ${var:=default},
+// ${var=default}, ${var:-default} or ${var-default}, or nil if a default value is not specified.
+func defaultValue(f *parse.FuncNode) *string {
+	switch f.Name {
+	case "=", ":=", "-", ":-":
+	default:
+		return nil
+	}
+
+	var b strings.Builder
+	for _, arg := range f.Args {
+		switch a := arg.(type) {
+		case *parse.TextNode:
+			b.WriteString(a.Value)
+		case *parse.FuncNode:
+			fmt.Fprintf(&b, "${%s}", a.Param)
+		}
+	}
+	value := b.String()
+	return &value
+}
+
 // legacyVariableRegEx defines the regexp used for searching variables inside a YAML.
 // It searches for variables with the format ${ VAR}, ${ VAR }, ${VAR }
 var legacyVariableRegEx = regexp.MustCompile(`(\${(\s+([A-Za-z0-9_$]+)\s+)})|(\${(\s+([A-Za-z0-9_$]+))})|(\${(([A-Za-z0-9_$]+)\s+)})`)
@@ -19,6 +19,7 @@ import (
 	"testing"
 
 	. "github.com/onsi/gomega"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client/config"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/internal/test"
 )
@@ -99,6 +100,81 @@ func TestSimpleProcessor_GetVariables(t *testing.T) {
 	}
 }
 
+func TestSimpleProcessor_GetVariableMap(t *testing.T) {
+	type args struct {
+		data string
+	}
+	tests := []struct {
+		name    string
+		args    args
+		want    map[string]*string
+		wantErr bool
+	}{
+		{
+			name: "variables without defaults are mapped to nil",
+			args: args{
+				data: "yaml with ${A} ${ B}",
+			},
+			want: map[string]*string{"A": nil, "B": nil},
+		},
+		{
+			name: "variables with defaults are mapped to their default values",
+			args: args{
+				data: "yaml with ${A:=foo} ${B=1} ${C:-true} ${D:=} ${E/a/b}",
+			},
+			want: map[string]*string{
+				"A": pointer.StringPtr("foo"),
+				"B": pointer.StringPtr("1"),
+				"C": pointer.StringPtr("true"),
+				"D": pointer.StringPtr(""),
+				"E": nil,
+			},
+		},
+		{
+			name: "default values referencing other variables are preserved",
+			args: args{
+				data: "yaml with ${A:=prefix-${B}-suffix}",
+			},
+			want: map[string]*string{"A": pointer.StringPtr("prefix-${B}-suffix"), "B": nil},
+		},
+		{
+			name: "variables in default values are mapped to their own default values",
+			args: args{
+				data: "yaml with ${A:=${B:=foo}}",
+			},
+			want: map[string]*string{"A": pointer.StringPtr("${B}"), "B": pointer.StringPtr("foo")},
+		},
+		{
+			name: "variables used in many places take the default value if any",
+			args: args{
+				data: "yaml with ${A} ${A:=foo} ${A}",
+			},
+			want: map[string]*string{"A": pointer.StringPtr("foo")},
+		},
+		{
+			name: "returns error for variables with regex metacharacters",
+			args: args{
+				data: "yaml with ${BA$R}\n${FOO}",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			p := NewSimpleProcessor()
+			actual, err := p.GetVariableMap([]byte(tt.args.data))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(actual).To(Equal(tt.want))
+		})
+	}
+}
+
 func TestSimpleProcessor_Process(t *testing.T) {
 	type args struct {
 		yaml                  []byte
@@ -18,13 +18,30 @@ package cmd
 
 import (
 	"fmt"
+	"io"
 	"os"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
 )
 
+const (
+	// VariablesOutputText is an option used to print the list of template variables in text format.
+	VariablesOutputText = "text"
+	// VariablesOutputJSON is an option used to print the list of template variables in json format.
+	VariablesOutputJSON = "json"
+	// VariablesOutputYaml is an option used to print the list of template variables in yaml format.
+	VariablesOutputYaml = "yaml"
+)
+
+var (
+	// VariablesOutputs is a list of valid template variables outputs.
+	VariablesOutputs = []string{VariablesOutputText, VariablesOutputJSON, VariablesOutputYaml}
+)
+
 type configClusterOptions struct {
 	kubeconfig             string
 	kubeconfigContext      string
@@ -35,13 +52,17 @@ type configClusterOptions struct {
 	kubernetesVersion        string
 	controlPlaneMachineCount int64
 	workerMachineCount       int64
+	workers                  []string
 
 	url                string
 	configMapNamespace string
 	configMapName      string
 	configMapDataKey   string
 
-	listVariables bool
+	listVariables       bool
+	listVariablesOutput string
+
+	localRepositoryCache string
 }
 
 var cc = &configClusterOptions{}
@@ -77,6 +98,10 @@ var configClusterClusterCmd = &cobra.Command{
 		# custom number of nodes (if supported by the provider's templates).
 		clusterctl config cluster my-cluster --control-plane-machine-count=3 --worker-machine-count=10
 
+		# Generates a configuration file for creating workload clusters with a MachineDeployment for each
+		# group of worker machines, with its own number of machines and optionally Kubernetes version.
+		clusterctl config cluster my-cluster --worker md-gpu:3 --worker md-small:10:v1.19.1
+
 		# Generates a configuration file for creating workload clusters using a template stored in a ConfigMap.
 		clusterctl config cluster my-cluster --from-config-map MyTemplates
 
@@ -84,7 +109,14 @@ var configClusterClusterCmd = &cobra.Command{
 		clusterctl config cluster my-cluster --from https://github.com/foo-org/foo-repository/blob/master/cluster-template.yaml
 
 		# Generates a configuration file for creating workload clusters using a template stored locally.
-		clusterctl config cluster my-cluster --from ~/workspace/cluster-template.yaml`),
+		clusterctl config cluster my-cluster --from ~/workspace/cluster-template.yaml
+
+		# Prints the list of variables expected by the template, with their types and default values, in json format.
+		clusterctl config cluster my-cluster --list-variables -o json
+
+		# Generates a configuration file for creating workload clusters without accessing the network, using
+		# the provider repositories previously stored with clusterctl repository sync.
+		clusterctl config cluster my-cluster --infrastructure=aws --target-namespace=foo --local-repository-cache ~/capi-cache`),
 
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -107,6 +139,9 @@ func init() {
 		"The number of control plane machines for the workload cluster.")
 	configClusterClusterCmd.Flags().Int64Var(&cc.workerMachineCount, "worker-machine-count", 0,
 		"The number of worker machines for the workload cluster.")
+	configClusterClusterCmd.Flags().StringArrayVar(&cc.workers, "worker", nil,
+		"A group of worker machines for the workload cluster, in the form name:count[:kubernetes-version], generated as a MachineDeployment named <cluster-name>-<name>. "+
+			"Can be repeated; it requires a template with exactly one MachineDeployment, and can't be used together with --worker-machine-count.")
 
 	// flags for the repository source
 	configClusterClusterCmd.Flags().StringVarP(&cc.infrastructureProvider, "infrastructure", "i", "",
@@ -129,11 +164,19 @@ func init() {
 	// other flags
 	configClusterClusterCmd.Flags().BoolVar(&cc.listVariables, "list-variables", false,
 		"Returns the list of variables expected by the template instead of the template yaml")
+	configClusterClusterCmd.Flags().StringVarP(&cc.listVariablesOutput, "output", "o", VariablesOutputText,
+		fmt.Sprintf("Output format for the list of variables expected by the template. Valid values: %v.", VariablesOutputs))
+	configClusterClusterCmd.Flags().StringVar(&cc.localRepositoryCache, "local-repository-cache", "",
+		"The folder of a local repository cache populated by clusterctl repository sync; if set, provider repositories are read from the cache only")
 
 	configCmd.AddCommand(configClusterClusterCmd)
 }
 
 func runGetClusterTemplate(cmd *cobra.Command, name string) error {
+	if err := validateVariablesOutput(cc.listVariablesOutput); err != nil {
+		return err
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -145,6 +188,8 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 		TargetNamespace:   cc.targetNamespace,
 		KubernetesVersion: cc.kubernetesVersion,
 		ListVariablesOnly: cc.listVariables,
+
+		LocalRepositoryCache: cc.localRepositoryCache,
 	}
 
 	if cmd.Flags().Changed("control-plane-machine-count") {
@@ -153,6 +198,11 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 	if cmd.Flags().Changed("worker-machine-count") {
 		templateOptions.WorkerMachineCount = &cc.workerMachineCount
 	}
+	workerGroups, err := parseWorkerGroups(cc.workers)
+	if err != nil {
+		return err
+	}
+	templateOptions.WorkerGroups = workerGroups
 
 	if cc.url != "" {
 		templateOptions.URLSource = &client.URLSourceOptions{
@@ -181,23 +231,80 @@ func runGetClusterTemplate(cmd *cobra.Command, name string) error {
 	}
 
 	if cc.listVariables {
-		return templateListVariablesOutput(template)
+		return printVariablesOutput(os.Stdout, template.VariableMap(), cc.listVariablesOutput)
 	}
 
 	return templateYAMLOutput(template)
 }
 
-func templateListVariablesOutput(template client.Template) error {
-	if len(template.Variables()) > 0 {
-		fmt.Println("Variables:")
-		for _, v := range template.Variables() {
-			fmt.Printf("  - %s\n", v)
+// parseWorkerGroups parses worker groups in the form name:count[:kubernetes-version].
+func parseWorkerGroups(workers []string) ([]client.WorkerGroup, error) {
+	groups := make([]client.WorkerGroup, 0, len(workers))
+	for _, w := range workers {
+		parts := strings.Split(w, ":")
+		if len(parts) < 2 || len(parts) > 3 || parts[0] == "" {
+			return nil, errors.Errorf("invalid worker group %q. Please use the form name:count[:kubernetes-version]", w)
+		}
+		replicas, err := strconv.ParseInt(parts[1], 10, 64)
+		if err != nil {
+			return nil, errors.Errorf("invalid count for worker group %q. Please use a number greater or equal than 0", w)
+		}
+		group := client.WorkerGroup{
+			Name:     parts[0],
+			Replicas: replicas,
 		}
+		if len(parts) == 3 {
+			group.KubernetesVersion = parts[2]
+		}
+		groups = append(groups, group)
+	}
+	return groups, nil
+}
+
+func validateVariablesOutput(output string) error {
+	if output != VariablesOutputText && output != VariablesOutputJSON && output != VariablesOutputYaml {
+		return errors.Errorf("invalid output format %q. Valid values: %v", output, VariablesOutputs)
 	}
-	fmt.Println()
 	return nil
 }
 
+// printVariablesOutput prints the list of variables expected by a template, with their types
+// and default values, grouping required and optional variables in the text format.
+func printVariablesOutput(w io.Writer, variableMap map[string]*string, output string) error {
+	variables := client.GetTemplateVariables(variableMap)
+
+	if output != VariablesOutputText {
+		return printStructuredOutput(w, variables, output)
+	}
+
+	var required, optional []client.TemplateVariable
+	for _, v := range variables {
+		if v.Required {
+			required = append(required, v)
+			continue
+		}
+		optional = append(optional, v)
+	}
+
+	if len(required) > 0 {
+		fmt.Fprintln(w, "Required Variables:")
+		for _, v := range required {
+			fmt.Fprintf(w, "  - %s (%s)\n", v.Name, v.Type)
+		}
+	}
+	if len(optional) > 0 {
+		if len(required) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "Optional Variables:")
+		for _, v := range optional {
+			fmt.Fprintf(w, "  - %s (%s, defaults to %q)\n", v.Name, v.Type, *v.Default)
+		}
+	}
+	_, err := fmt.Fprintln(w)
+	return err
+}
+
 func templateYAMLOutput(template client.Template) error {
 	yaml, err := template.Yaml()
 	if err != nil {
@@ -19,8 +19,6 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
-	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -33,11 +31,13 @@ const (
 	ComponentsOutputYaml = "yaml"
 	// ComponentsOutputText is an option used to print the components in text format.
 	ComponentsOutputText = "text"
+	// ComponentsOutputJSON is an option used to print the information about the components in json format.
+	ComponentsOutputJSON = "json"
 )
 
 var (
 	// ComponentsOutputs is a list of valid components outputs.
-	ComponentsOutputs = []string{ComponentsOutputText, ComponentsOutputYaml}
+	ComponentsOutputs = []string{ComponentsOutputText, ComponentsOutputYaml, ComponentsOutputJSON}
 )
 
 type configProvidersOptions struct {
@@ -48,6 +48,7 @@ type configProvidersOptions struct {
 	output                 string
 	targetNamespace        string
 	watchingNamespace      string
+	describe               bool
 }
 
 var cpo = &configProvidersOptions{}
@@ -74,7 +75,14 @@ var configProviderCmd = &cobra.Command{
 		clusterctl config provider --infrastructure aws -o yaml
 
 		# Prints out the component file in yaml format for the given infrastructure provider and version.
-		clusterctl config provider --infrastructure aws:v0.4.1 -o yaml`),
+		clusterctl config provider --infrastructure aws:v0.4.1 -o yaml
+
+		# Prints out the information about the given infrastructure provider in json format.
+		clusterctl config provider --infrastructure aws -o json
+
+		# Shows where the components of the given infrastructure provider are read from,
+		# e.g. the repository URL, the version and the override files.
+		clusterctl config provider --infrastructure aws --describe`),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runGetComponents()
@@ -97,12 +105,14 @@ func init() {
 		"The target namespace where the provider should be deployed. If unspecified, the components default namespace is used.")
 	configProviderCmd.Flags().StringVar(&cpo.watchingNamespace, "watching-namespace", "",
 		"Namespace the provider should watch when reconciling objects. If unspecified, all namespaces are watched.")
+	configProviderCmd.Flags().BoolVar(&cpo.describe, "describe", false,
+		"Show where the provider components are read from, including the override files, instead of the components. Supports only text and json output.")
 
 	configCmd.AddCommand(configProviderCmd)
 }
 
 func runGetComponents() error {
-	if cpo.output != ComponentsOutputYaml && cpo.output != ComponentsOutputText {
+	if cpo.output != ComponentsOutputYaml && cpo.output != ComponentsOutputText && cpo.output != ComponentsOutputJSON {
 		return errors.Errorf("Invalid output format %q. Valid values: %v.", cpo.output, ComponentsOutputs)
 	}
 
@@ -133,6 +143,21 @@ func runGetComponents() error {
 		return errors.New("at least one of --core, --bootstrap, --control-plane, --infrastructure should be set")
 	}
 
+	if cpo.describe {
+		if cpo.output == ComponentsOutputYaml {
+			return errors.Errorf("Invalid output format %q for --describe. Valid values: %v.", cpo.output, []string{ComponentsOutputText, ComponentsOutputJSON})
+		}
+		describer, err := client.NewProviderComponentsDescriber(cfgFile)
+		if err != nil {
+			return err
+		}
+		d, err := describer.DescribeProviderComponents(providerName, providerType)
+		if err != nil {
+			return err
+		}
+		return printComponentsDescription(d, cpo.output)
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -153,26 +178,23 @@ func runGetComponents() error {
 func printComponents(c client.Components, output string) error {
 	switch output {
 	case ComponentsOutputText:
-		dir, file := filepath.Split(c.URL())
-		// Remove the version suffix from the URL since we already display it
-		// separately.
-		baseURL, _ := filepath.Split(strings.TrimSuffix(dir, "/"))
-		fmt.Printf("Name:               %s\n", c.Name())
-		fmt.Printf("Type:               %s\n", c.Type())
-		fmt.Printf("URL:                %s\n", baseURL)
-		fmt.Printf("Version:            %s\n", c.Version())
-		fmt.Printf("File:               %s\n", file)
-		fmt.Printf("TargetNamespace:    %s\n", c.TargetNamespace())
-		fmt.Printf("WatchingNamespace:  %s\n", c.WatchingNamespace())
-		if len(c.Variables()) > 0 {
+		info := client.NewProviderComponentsOutput(c)
+		fmt.Printf("Name:               %s\n", info.Name)
+		fmt.Printf("Type:               %s\n", info.Type)
+		fmt.Printf("URL:                %s\n", info.URL)
+		fmt.Printf("Version:            %s\n", info.Version)
+		fmt.Printf("File:               %s\n", info.File)
+		fmt.Printf("TargetNamespace:    %s\n", info.TargetNamespace)
+		fmt.Printf("WatchingNamespace:  %s\n", info.WatchingNamespace)
+		if len(info.Variables) > 0 {
 			fmt.Println("Variables:")
-			for _, v := range c.Variables() {
+			for _, v := range info.Variables {
 				fmt.Printf("  - %s\n", v)
 			}
 		}
-		if len(c.Images()) > 0 {
+		if len(info.Images) > 0 {
 			fmt.Println("Images:")
-			for _, v := range c.Images() {
+			for _, v := range info.Images {
 				fmt.Printf("  - %s\n", v)
 			}
 		}
@@ -188,6 +210,32 @@ func printComponents(c client.Components, output string) error {
 		}
 		os.Stdout.WriteString("\n")
 		return err
+	case ComponentsOutputJSON:
+		return printStructuredOutput(os.Stdout, client.NewProviderComponentsOutput(c), OutputJSON)
+	}
+	return nil
+}
+
+func printComponentsDescription(d client.ProviderComponentsDescription, output string) error {
+	if output == ComponentsOutputJSON {
+		return printStructuredOutput(os.Stdout, d, OutputJSON)
+	}
+
+	fmt.Printf("Name:               %s\n", d.Name)
+	fmt.Printf("Type:               %s\n", d.Type)
+	fmt.Printf("URL:                %s (%s)\n", d.URL, d.URLSource)
+	fmt.Printf("Version:            %s (%s)\n", d.Version, d.VersionResolution)
+	fmt.Printf("OverridesFolder:    %s (%s)\n", d.OverridesFolder, d.OverridesFolderSource)
+	fmt.Println("Files:")
+	for _, f := range d.Files {
+		status := "found"
+		if !f.Found {
+			status = fmt.Sprintf("not found: %s", f.Error)
+		}
+		fmt.Printf("  - %s\n", f.Name)
+		fmt.Printf("    Source:         %s (%s)\n", f.Source, status)
+		fmt.Printf("    OverridePath:   %s\n", f.OverridePath)
 	}
+	fmt.Println()
 	return nil
 }
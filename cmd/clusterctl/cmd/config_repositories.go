@@ -34,11 +34,13 @@ const (
 	RepositoriesOutputYaml = "yaml"
 	// RepositoriesOutputText is an option used to print the repository list in text format.
 	RepositoriesOutputText = "text"
+	// RepositoriesOutputJSON is an option used to print the repository list in json format.
+	RepositoriesOutputJSON = "json"
 )
 
 var (
 	// RepositoriesOutputs is a list of valid repository list outputs.
-	RepositoriesOutputs = []string{RepositoriesOutputYaml, RepositoriesOutputText}
+	RepositoriesOutputs = []string{RepositoriesOutputYaml, RepositoriesOutputText, RepositoriesOutputJSON}
 )
 
 type configRepositoriesOptions struct {
@@ -62,7 +64,10 @@ var configRepositoryCmd = &cobra.Command{
 		clusterctl config repositories
 
 		# Print the list of available providers in yaml format.
-		clusterctl config repositories -o yaml`),
+		clusterctl config repositories -o yaml
+
+		# Print the list of available providers in json format.
+		clusterctl config repositories -o json`),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runGetRepositories(cfgFile, os.Stdout)
@@ -76,7 +81,7 @@ func init() {
 }
 
 func runGetRepositories(cfgFile string, out io.Writer) error {
-	if cro.output != RepositoriesOutputText && cro.output != RepositoriesOutputYaml {
+	if cro.output != RepositoriesOutputText && cro.output != RepositoriesOutputYaml && cro.output != RepositoriesOutputJSON {
 		return errors.Errorf("Invalid output format %q. Valid values: %v.", cro.output, RepositoriesOutputs)
 	}
 
@@ -109,6 +114,10 @@ func runGetRepositories(cfgFile string, out io.Writer) error {
 			return err
 		}
 		fmt.Fprintf(w, string(y))
+	case RepositoriesOutputJSON:
+		if err := printStructuredOutput(w, repositoryList, OutputJSON); err != nil {
+			return err
+		}
 	}
 	w.Flush()
 	return nil
@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"bytes"
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -49,6 +50,16 @@ func Test_runGetRepositories(t *testing.T) {
 				g.Expect(string(out)).To(Equal(expectedOutputText))
 			} else if val == RepositoriesOutputYaml {
 				g.Expect(string(out)).To(Equal(expectedOutputYaml))
+			} else if val == RepositoriesOutputJSON {
+				var repositories []map[string]string
+				g.Expect(json.Unmarshal(out, &repositories)).To(Succeed())
+				g.Expect(repositories).To(HaveLen(19))
+				g.Expect(repositories[0]).To(Equal(map[string]string{
+					"Name":         "cluster-api",
+					"ProviderType": "CoreProvider",
+					"URL":          "https://github.com/myorg/myforkofclusterapi/releases/latest/",
+					"File":         "core_components.yaml",
+				}))
 			}
 		}
 	})
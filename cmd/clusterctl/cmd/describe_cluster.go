@@ -18,6 +18,7 @@ package cmd
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"time"
@@ -58,6 +59,9 @@ type describeClusterOptions struct {
 	showOtherConditions string
 	disableNoEcho       bool
 	disableGrouping     bool
+	showConditionTypes  string
+	collapseReady       bool
+	output              string
 }
 
 var dc = &describeClusterOptions{}
@@ -80,13 +84,22 @@ var describeClusterClusterCmd = &cobra.Command{
 		# Describe the cluster named test-1 showing all the conditions for a specific machine.
 		clusterctl describe cluster test-1 --show-conditions Machine/m1
 
+		# Describe the cluster named test-1 showing only the conditions of type MachinesReady and Available for all the objects.
+		clusterctl describe cluster test-1 --show-condition-types MachinesReady,Available
+
+		# Describe the cluster named test-1 expanding only the objects that are not ready.
+		clusterctl describe cluster test-1 --collapse-ready
+
 		# Describe the cluster named test-1 disabling automatic grouping of objects with the same ready condition 
 		# e.g. un-group all the machines with Ready=true instead of showing a single group node.
 		clusterctl describe cluster test-1 --disable-grouping
 
 		# Describe the cluster named test-1 disabling automatic echo suppression 
         # e.g. show the infrastructure machine objects, no matter if the current state is already reported by the machine's Ready condition.
-		clusterctl describe cluster test-1`),
+		clusterctl describe cluster test-1
+
+		# Describe the cluster named test-1 in json format, e.g. for use in automation.
+		clusterctl describe cluster test-1 -o json`),
 
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -108,11 +121,21 @@ func init() {
 		"Disable hiding of a MachineInfrastructure and BootstrapConfig when ready condition is true or it has the Status, Severity and Reason of the machine's object.")
 	describeClusterClusterCmd.Flags().BoolVar(&dc.disableGrouping, "disable-grouping", false,
 		"Disable grouping machines when ready condition has the same Status, Severity and Reason.")
+	describeClusterClusterCmd.Flags().StringVar(&dc.showConditionTypes, "show-condition-types", "",
+		"list of comma separated condition types to show for the objects selected by --show-conditions (or for all the objects if --show-conditions is not set).")
+	describeClusterClusterCmd.Flags().BoolVar(&dc.collapseReady, "collapse-ready", false,
+		"Hide the children of the objects whose ready condition is true, expanding only the branches that are not ready.")
+	describeClusterClusterCmd.Flags().StringVarP(&dc.output, "output", "o", OutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", Outputs))
 
 	describeCmd.AddCommand(describeClusterClusterCmd)
 }
 
 func runDescribeCluster(name string) error {
+	if err := validateOutput(dc.output); err != nil {
+		return err
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -125,11 +148,17 @@ func runDescribeCluster(name string) error {
 		ShowOtherConditions: dc.showOtherConditions,
 		DisableNoEcho:       dc.disableNoEcho,
 		DisableGrouping:     dc.disableGrouping,
+		ShowConditionTypes:  dc.showConditionTypes,
+		CollapseReady:       dc.collapseReady,
 	})
 	if err != nil {
 		return err
 	}
 
+	if dc.output != OutputText {
+		return printStructuredOutput(os.Stdout, tree.ToObjectNode(), dc.output)
+	}
+
 	printObjectTree(tree)
 	return nil
 }
@@ -182,9 +211,12 @@ func addObjectRow(prefix string, tbl *uitable.Table, objectTree *tree.ObjectTree
 		readyDescriptor.age,
 		readyDescriptor.message)
 
-	// If it is required to show all the conditions for the object, add a row for each object's conditions.
+	// If it is required to show all the conditions for the object, add a row for each object's conditions;
+	// otherwise, if the object deletion is blocked, add a row showing what is blocking it.
 	if tree.IsShowConditionsObject(obj) {
 		addOtherConditions(prefix, tbl, objectTree, obj)
+	} else if deletionBlocked := tree.GetDeletionBlockedCondition(obj); deletionBlocked != nil {
+		addConditionRows(prefix, tbl, objectTree, obj, []*clusterv1.Condition{deletionBlocked})
 	}
 
 	// Add a row for each object's children, taking care of updating the tree view prefix.
@@ -202,6 +234,12 @@ func addObjectRow(prefix string, tbl *uitable.Table, objectTree *tree.ObjectTree
 // addOtherConditions adds a row for each object condition except the ready condition,
 // which is already represented on the object's main row.
 func addOtherConditions(prefix string, tbl *uitable.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object) {
+	otherConditions := tree.FilterConditionsByType(tree.GetOtherConditions(obj), tree.GetShowConditionTypes(obj))
+	addConditionRows(prefix, tbl, objectTree, obj, otherConditions)
+}
+
+// addConditionRows adds a row for each of the given object conditions.
+func addConditionRows(prefix string, tbl *uitable.Table, objectTree *tree.ObjectTree, obj ctrlclient.Object, otherConditions []*clusterv1.Condition) {
 	// Add a row for each other condition, taking care of updating the tree view prefix.
 	// In this case the tree prefix get a filler, to indent conditions from objects, and eventually a
 	// and additional pipe if the object has children that should be presented after the conditions.
@@ -211,7 +249,6 @@ func addOtherConditions(prefix string, tbl *uitable.Table, objectTree *tree.Obje
 		childrenPipe = pipe
 	}
 
-	otherConditions := tree.GetOtherConditions(obj)
 	for i := range otherConditions {
 		otherCondition := otherConditions[i]
 		otherDescriptor := newConditionDescriptor(otherCondition)
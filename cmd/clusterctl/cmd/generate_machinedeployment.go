@@ -0,0 +1,126 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type generateMachineDeploymentOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	from              string
+	replicas          int32
+	variables         []string
+}
+
+var gmd = &generateMachineDeploymentOptions{}
+
+var alphaGenerateCmd = &cobra.Command{
+	Use:   "generate",
+	Short: "Generate yaml for the objects of an existing cluster.",
+	Long:  `Generate yaml for the objects of an existing cluster.`,
+}
+
+var generateMachineDeploymentCmd = &cobra.Command{
+	Use:   "machinedeployment NAME",
+	Short: "Generate a MachineDeployment by cloning an existing one",
+	Long: LongDesc(`
+		Generate a new MachineDeployment, with its bootstrap config and infrastructure machine templates,
+		by cloning an existing MachineDeployment and its templates.
+
+		The generated objects are named after the new MachineDeployment; the replicas and any other field
+		can be overridden, so that a new group of workers can be added to a cluster without copying and
+		editing the existing objects by hand.
+
+		The generated yaml is printed to stdout, and can be applied with kubectl apply.`),
+
+	Example: Examples(`
+		# Generate a MachineDeployment named md-1 by cloning md-0 in the current namespace.
+		clusterctl alpha generate machinedeployment md-1 --from md-0
+
+		# Generate a MachineDeployment with 5 replicas and a different Kubernetes version.
+		clusterctl alpha generate machinedeployment md-1 --from md-0 --replicas 5 \
+			--set machinedeployment.spec.template.spec.version=v1.20.4
+
+		# Generate a MachineDeployment with a different instance type, and apply it.
+		clusterctl alpha generate machinedeployment md-1 --from md-0 \
+			--set infrastructure.spec.template.spec.instanceType=m5.xlarge | kubectl apply -f -`),
+
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runGenerateMachineDeployment(cmd, args[0])
+	},
+}
+
+func init() {
+	generateMachineDeploymentCmd.Flags().StringVar(&gmd.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	generateMachineDeploymentCmd.Flags().StringVar(&gmd.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	generateMachineDeploymentCmd.Flags().StringVarP(&gmd.namespace, "namespace", "n", "",
+		"The namespace of the MachineDeployment to clone. If unspecified, the current namespace will be used.")
+	generateMachineDeploymentCmd.Flags().StringVar(&gmd.from, "from", "",
+		"The name of the MachineDeployment to clone.")
+	generateMachineDeploymentCmd.Flags().Int32Var(&gmd.replicas, "replicas", 0,
+		"The replicas of the generated MachineDeployment. If unspecified, the replicas of the cloned MachineDeployment will be used.")
+	generateMachineDeploymentCmd.Flags().StringArrayVar(&gmd.variables, "set", nil,
+		"A field to override in the generated objects, in the <object>.<field path>=<yaml value> format, where object is one of machinedeployment, bootstrap or infrastructure. Can be repeated.")
+	_ = generateMachineDeploymentCmd.MarkFlagRequired("from")
+
+	alphaGenerateCmd.AddCommand(generateMachineDeploymentCmd)
+}
+
+func runGenerateMachineDeployment(cmd *cobra.Command, name string) error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	variables := map[string]string{}
+	for _, v := range gmd.variables {
+		kv := strings.SplitN(v, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return errors.Errorf("invalid --set value %q: it must be in the <field path>=<value> format", v)
+		}
+		variables[kv[0]] = kv[1]
+	}
+
+	options := client.GenerateMachineDeploymentOptions{
+		Kubeconfig: client.Kubeconfig{Path: gmd.kubeconfig, Context: gmd.kubeconfigContext},
+		Namespace:  gmd.namespace,
+		From:       gmd.from,
+		Name:       name,
+		Variables:  variables,
+	}
+	if cmd.Flags().Changed("replicas") {
+		options.Replicas = &gmd.replicas
+	}
+
+	out, err := c.GenerateMachineDeployment(options)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(out))
+	return nil
+}
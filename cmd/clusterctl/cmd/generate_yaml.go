@@ -26,8 +26,9 @@ import (
 )
 
 type generateYAMLOptions struct {
-	url           string
-	listVariables bool
+	url                 string
+	listVariables       bool
+	listVariablesOutput string
 }
 
 var gyOpts = &generateYAMLOptions{}
@@ -58,6 +59,9 @@ var generateYamlCmd = &cobra.Command{
 
 		# Prints list of variables from template passed in via stdin
 		cat ~/workspace/cluster-template.yaml | clusterctl generate yaml --list-variables
+
+		# Prints list of variables used in the local template, with their types and default values, in json format
+		clusterctl generate yaml --from ~/workspace/cluster-template.yaml --list-variables -o json
 `),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
@@ -73,11 +77,19 @@ func init() {
 	// other flags
 	generateYamlCmd.Flags().BoolVar(&gyOpts.listVariables, "list-variables", false,
 		"Returns the list of variables expected by the template instead of the template yaml")
+	generateYamlCmd.Flags().StringVarP(&gyOpts.listVariablesOutput, "output", "o", VariablesOutputText,
+		fmt.Sprintf("Output format for the list of variables expected by the template. Valid values: %v.", VariablesOutputs))
 
 	generateCmd.AddCommand(generateYamlCmd)
 }
 
 func generateYAML(r io.Reader, w io.Writer) error {
+	if gyOpts.listVariables {
+		if err := validateVariablesOutput(gyOpts.listVariablesOutput); err != nil {
+			return err
+		}
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -101,15 +113,7 @@ func generateYAML(r io.Reader, w io.Writer) error {
 		return err
 	}
 	if gyOpts.listVariables {
-		if len(printer.Variables()) > 0 {
-			fmt.Fprintln(w, "Variables:")
-			for _, v := range printer.Variables() {
-				fmt.Fprintf(w, "  - %s\n", v)
-			}
-		} else {
-			fmt.Fprintln(w)
-		}
-		return nil
+		return printVariablesOutput(w, printer.VariableMap(), gyOpts.listVariablesOutput)
 	}
 	out, err := printer.Yaml()
 	if err != nil {
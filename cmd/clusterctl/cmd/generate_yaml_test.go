@@ -61,17 +61,67 @@ v3: default3
 		},
 		{
 			name:      "prints variables using --list-variables flag",
-			options:   &generateYAMLOptions{url: template, listVariables: true},
+			options:   &generateYAMLOptions{url: template, listVariables: true, listVariablesOutput: VariablesOutputText},
 			expectErr: false,
-			expectedOutput: `Variables:
-  - VAR1
-  - VAR2
-  - VAR3
+			expectedOutput: `Optional Variables:
+  - VAR1 (string, defaults to "default1")
+  - VAR2 (string, defaults to "default2")
+  - VAR3 (string, defaults to "default3")
+
+`,
+		},
+		{
+			name:      "prints variables in json format using --list-variables flag",
+			options:   &generateYAMLOptions{url: template, listVariables: true, listVariablesOutput: VariablesOutputJSON},
+			expectErr: false,
+			expectedOutput: `[
+  {
+    "name": "VAR1",
+    "type": "string",
+    "default": "default1",
+    "required": false
+  },
+  {
+    "name": "VAR2",
+    "type": "string",
+    "default": "default2",
+    "required": false
+  },
+  {
+    "name": "VAR3",
+    "type": "string",
+    "default": "default3",
+    "required": false
+  }
+]
+`,
+		},
+		{
+			name:      "prints variables in yaml format using --list-variables flag",
+			options:   &generateYAMLOptions{url: template, listVariables: true, listVariablesOutput: VariablesOutputYaml},
+			expectErr: false,
+			expectedOutput: `- default: default1
+  name: VAR1
+  required: false
+  type: string
+- default: default2
+  name: VAR2
+  required: false
+  type: string
+- default: default3
+  name: VAR3
+  required: false
+  type: string
 `,
 		},
+		{
+			name:      "returns error for an invalid --output flag",
+			options:   &generateYAMLOptions{url: template, listVariables: true, listVariablesOutput: "xml"},
+			expectErr: true,
+		},
 		{
 			name:      "returns error for bad templateFile path",
-			options:   &generateYAMLOptions{url: "/tmp/do-not-exist", listVariables: true},
+			options:   &generateYAMLOptions{url: "/tmp/do-not-exist", listVariables: true, listVariablesOutput: VariablesOutputText},
 			expectErr: true,
 		},
 		{
@@ -81,7 +131,7 @@ v3: default3
 		},
 		{
 			name:           "prints nothing if there are no variables in the template",
-			options:        &generateYAMLOptions{url: templateWithoutVars, listVariables: true},
+			options:        &generateYAMLOptions{url: templateWithoutVars, listVariables: true, listVariablesOutput: VariablesOutputText},
 			expectErr:      false,
 			expectedOutput: "\n",
 		},
@@ -19,6 +19,7 @@ package cmd
 import (
 	"fmt"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
 )
@@ -32,7 +33,9 @@ type initOptions struct {
 	infrastructureProviders []string
 	targetNamespace         string
 	watchingNamespace       string
+	providersFile           string
 	listImages              bool
+	validateImages          bool
 }
 
 var initOpts = &initOptions{}
@@ -79,10 +82,17 @@ var initCmd = &cobra.Command{
 		# Initialize a management cluster with a custom watching namespace for the given provider.
 		clusterctl init --infrastructure aws --watching-namespace=foo
 
+		# Initialize a management cluster with the providers, versions, namespaces and variables defined in a file.
+		clusterctl init -f providers.yaml
+
 		# Lists the container images required for initializing the management cluster.
 		#
 		# Note: This command is a dry-run; it won't perform any action other than printing to screen.
-		clusterctl init --infrastructure aws --list-images`),
+		clusterctl init --infrastructure aws --list-images
+
+		# Lists the container images required for initializing the management cluster, and checks they can be pulled
+		# from their registries, e.g. after applying the image overrides defined in the clusterctl config file.
+		clusterctl init --infrastructure aws --list-images --validate-images`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runInit()
@@ -97,7 +107,7 @@ func init() {
 	initCmd.Flags().StringVar(&initOpts.coreProvider, "core", "",
 		"Core provider version (e.g. cluster-api:v0.3.0) to add to the management cluster. If unspecified, Cluster API's latest release is used.")
 	initCmd.Flags().StringSliceVarP(&initOpts.infrastructureProviders, "infrastructure", "i", nil,
-		"Infrastructure providers and versions (e.g. aws:v0.5.0) to add to the management cluster.")
+		"Infrastructure providers and versions (e.g. aws:v0.5.0) to add to the management cluster. Multiple instances of the same provider can be added, each one installed in and watching its own namespace, using the name[:version][:namespace] syntax (e.g. aws:ns1,aws:v0.5.0:ns2).")
 	initCmd.Flags().StringSliceVarP(&initOpts.bootstrapProviders, "bootstrap", "b", nil,
 		"Bootstrap providers and versions (e.g. kubeadm:v0.3.0) to add to the management cluster. If unspecified, Kubeadm bootstrap provider's latest release is used.")
 	initCmd.Flags().StringSliceVarP(&initOpts.controlPlaneProviders, "control-plane", "c", nil,
@@ -106,10 +116,14 @@ func init() {
 		"The target namespace where the providers should be deployed. If unspecified, the provider components' default namespace is used.")
 	initCmd.Flags().StringVar(&initOpts.watchingNamespace, "watching-namespace", "",
 		"Namespace the providers should watch when reconciling objects. If unspecified, all namespaces are watched.")
+	initCmd.Flags().StringVarP(&initOpts.providersFile, "providers-file", "f", "",
+		"Path to a file defining the providers to add to the management cluster, with their versions, namespaces and variables. It can't be used together with the --core, --bootstrap, --control-plane and --infrastructure flags.")
 
 	// TODO: Move this to a sub-command or similar, it shouldn't really be a flag.
 	initCmd.Flags().BoolVar(&initOpts.listImages, "list-images", false,
 		"Lists the container images required for initializing the management cluster (without actually installing the providers)")
+	initCmd.Flags().BoolVar(&initOpts.validateImages, "validate-images", false,
+		"Checks the container images listed by --list-images can be pulled anonymously from their registries, and fails if any of them can't.")
 
 	RootCmd.AddCommand(initCmd)
 }
@@ -128,9 +142,14 @@ func runInit() error {
 		InfrastructureProviders: initOpts.infrastructureProviders,
 		TargetNamespace:         initOpts.targetNamespace,
 		WatchingNamespace:       initOpts.watchingNamespace,
+		ProvidersFile:           initOpts.providersFile,
 		LogUsageInstructions:    true,
 	}
 
+	if initOpts.validateImages && !initOpts.listImages {
+		return errors.New("--validate-images can only be used together with --list-images")
+	}
+
 	if initOpts.listImages {
 		images, err := c.InitImages(options)
 		if err != nil {
@@ -140,6 +159,10 @@ func runInit() error {
 		for _, i := range images {
 			fmt.Println(i)
 		}
+
+		if initOpts.validateImages {
+			return c.ValidateImages(images)
+		}
 		return nil
 	}
 
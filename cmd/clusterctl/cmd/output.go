@@ -0,0 +1,67 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// OutputText is an option used to print the result of a command in human readable text format.
+	OutputText = "text"
+	// OutputYaml is an option used to print the result of a command in yaml format.
+	OutputYaml = "yaml"
+	// OutputJSON is an option used to print the result of a command in json format.
+	OutputJSON = "json"
+)
+
+var (
+	// Outputs is a list of valid outputs for the commands supporting structured output.
+	Outputs = []string{OutputText, OutputYaml, OutputJSON}
+)
+
+func validateOutput(output string) error {
+	if output != OutputText && output != OutputYaml && output != OutputJSON {
+		return errors.Errorf("invalid output format %q. Valid values: %v", output, Outputs)
+	}
+	return nil
+}
+
+// printStructuredOutput prints the given object in yaml or json format.
+func printStructuredOutput(w io.Writer, obj interface{}, output string) error {
+	var out []byte
+	var err error
+	switch output {
+	case OutputYaml:
+		out, err = yaml.Marshal(obj)
+	case OutputJSON:
+		out, err = json.MarshalIndent(obj, "", "  ")
+		out = append(out, '\n')
+	default:
+		return errors.Errorf("invalid structured output format %q", output)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to convert the output to %s", output)
+	}
+	_, err = fmt.Fprint(w, string(out))
+	return err
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type repositorySyncOptions struct {
+	localRepositoryCache string
+	flavors              []string
+}
+
+var rso = &repositorySyncOptions{}
+
+var repositorySyncCmd = &cobra.Command{
+	Use:   "sync [provider...]",
+	Short: "Store provider repositories in a local repository cache for offline use.",
+	Long: LongDesc(`
+		Store provider repositories in a local repository cache for offline use.
+
+		The components YAML, the metadata.yaml and the cluster templates of each provider
+		are stored in the cache; providers are in the form name[:version], and if a version
+		is not specified the default version of the provider repository is used. If no
+		provider is specified, all the configured providers are stored.
+
+		The cache can then be used with the --local-repository-cache flag of clusterctl config cluster,
+		or the localRepositoryCache variable, for reading provider repositories without accessing the network.`),
+
+	Example: Examples(`
+		# Stores the default version of the AWS infrastructure provider and of the kubeadm providers.
+		clusterctl repository sync aws kubeadm --local-repository-cache ~/capi-cache
+
+		# Stores a specific version of the AWS infrastructure provider, including the templates of the eks flavor.
+		clusterctl repository sync aws:v0.6.4 --flavor eks --local-repository-cache ~/capi-cache`),
+
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runRepositorySync(args)
+	},
+}
+
+func init() {
+	repositorySyncCmd.Flags().StringVar(&rso.localRepositoryCache, "local-repository-cache", "",
+		"The folder of the local repository cache.")
+	repositorySyncCmd.Flags().StringSliceVar(&rso.flavors, "flavor", nil,
+		"The flavors of the cluster templates to store for infrastructure providers, in addition to the default one.")
+
+	repositoryCmd.AddCommand(repositorySyncCmd)
+}
+
+func runRepositorySync(providers []string) error {
+	if rso.localRepositoryCache == "" {
+		return errors.New("please specify the folder of the local repository cache using the --local-repository-cache flag")
+	}
+
+	c, err := client.NewRepositoryCacheClient(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	synced, err := c.SyncRepositoryCache(client.SyncRepositoryCacheOptions{
+		Directory: rso.localRepositoryCache,
+		Providers: providers,
+		Flavors:   rso.flavors,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, r := range synced {
+		fmt.Fprintf(os.Stdout, "Stored %s %q %s in the local repository cache\n", r.Type, r.Name, r.Version)
+	}
+	return nil
+}
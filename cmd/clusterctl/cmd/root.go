@@ -39,6 +39,7 @@ type stackTracer interface {
 var (
 	cfgFile   string
 	verbosity *int
+	progress  string
 )
 
 var RootCmd = &cobra.Command{
@@ -49,6 +50,10 @@ var RootCmd = &cobra.Command{
 		Get started with Cluster API using clusterctl to create a management cluster,
 		install providers, and create templates for your workload cluster.`),
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		if err := logf.SetProgressFormat(progress); err != nil {
+			return err
+		}
+
 		// Check if Config folder (~/.cluster-api) exist and if not create it
 		configFolderPath := filepath.Join(homedir.HomeDir(), config.ConfigFolder)
 		if _, err := os.Stat(configFolderPath); os.IsNotExist(err) {
@@ -109,6 +114,8 @@ func init() {
 	RootCmd.PersistentFlags().AddGoFlagSet(flag.CommandLine)
 	RootCmd.PersistentFlags().StringVar(&cfgFile, "config", "",
 		"Path to clusterctl configuration (default is `$HOME/.cluster-api/clusterctl.yaml`) or to a remote location (i.e. https://example.com/clusterctl.yaml)")
+	RootCmd.PersistentFlags().StringVar(&progress, "progress", string(logf.PlainProgressFormat),
+		"Format used for reporting the progress of long running operations, i.e. init, move and upgrade apply. Valid values are plain (step durations are logged at verbosity 1), bar and json (one event per line); progress is written to stderr.")
 
 	cobra.OnInitialize(initConfig)
 }
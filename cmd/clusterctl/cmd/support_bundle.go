@@ -0,0 +1,110 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type supportBundleOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	allNamespaces     bool
+	logTailLines      int64
+	output            string
+}
+
+var sb = &supportBundleOptions{}
+
+var supportBundleCmd = &cobra.Command{
+	Use:   "support-bundle",
+	Short: "Collect the information required for troubleshooting a management cluster",
+	Long: LongDesc(`
+		Collect the Cluster API objects, the provider controller logs, the webhook configurations,
+		the CRD versions and the events existing in a management cluster into a gzipped tarball.
+
+		Secret values, and kubeconfigs or bootstrap data stored in other kinds, are redacted,
+		so the support bundle can be attached to bug reports.`),
+
+	Example: Examples(`
+		# Collect a support bundle for the Cluster API objects in the current namespace.
+		clusterctl alpha support-bundle
+
+		# Collect a support bundle for the Cluster API objects in all the namespaces.
+		clusterctl alpha support-bundle --all-namespaces
+
+		# Collect a support bundle including only the last 1000 lines of each controller log.
+		clusterctl alpha support-bundle --log-tail-lines 1000 --output bundle.tar.gz`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSupportBundle()
+	},
+}
+
+func init() {
+	supportBundleCmd.Flags().StringVar(&sb.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	supportBundleCmd.Flags().StringVar(&sb.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	supportBundleCmd.Flags().StringVarP(&sb.namespace, "namespace", "n", "",
+		"The namespace where the Cluster API objects are collected from. If unspecified, the current namespace will be used.")
+	supportBundleCmd.Flags().BoolVarP(&sb.allNamespaces, "all-namespaces", "A", false,
+		"Collect the Cluster API objects from all the namespaces.")
+	supportBundleCmd.Flags().Int64Var(&sb.logTailLines, "log-tail-lines", 0,
+		"The number of lines to collect from the end of each controller log. If unspecified, the entire logs are collected.")
+	supportBundleCmd.Flags().StringVarP(&sb.output, "output", "o", "",
+		"The path of the support bundle to create. If unspecified, clusterctl-support-bundle-<timestamp>.tar.gz will be used.")
+}
+
+func runSupportBundle() error {
+	c, err := client.New(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	output := sb.output
+	if output == "" {
+		output = fmt.Sprintf("clusterctl-support-bundle-%s.tar.gz", time.Now().UTC().Format("20060102-150405"))
+	}
+
+	f, err := os.OpenFile(output, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create %q", output)
+	}
+	defer f.Close()
+
+	if err := c.SupportBundle(client.SupportBundleOptions{
+		Kubeconfig:    client.Kubeconfig{Path: sb.kubeconfig, Context: sb.kubeconfigContext},
+		Namespace:     sb.namespace,
+		AllNamespaces: sb.allNamespaces,
+		LogTailLines:  sb.logTailLines,
+		Output:        f,
+	}); err != nil {
+		return err
+	}
+
+	fmt.Printf("Support bundle written to %s\n", output)
+	return nil
+}
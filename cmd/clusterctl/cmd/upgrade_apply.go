@@ -17,6 +17,8 @@ limitations under the License.
 package cmd
 
 import (
+	"time"
+
 	"github.com/pkg/errors"
 
 	"github.com/spf13/cobra"
@@ -32,6 +34,9 @@ type upgradeApplyOptions struct {
 	bootstrapProviders      []string
 	controlPlaneProviders   []string
 	infrastructureProviders []string
+	ignoreVersionSkew       bool
+	noRollback              bool
+	waitProviderTimeout     time.Duration
 }
 
 var ua = &upgradeApplyOptions{}
@@ -51,7 +56,11 @@ var upgradeApplyCmd = &cobra.Command{
 		clusterctl upgrade apply --management-group capi-system/cluster-api  --contract v1alpha3
 
 		# Upgrades only the capa-system/aws provider instance in the capi-system/cluster-api management group to the v0.5.0 version.
-		clusterctl upgrade apply --management-group capi-system/cluster-api  --infrastructure capa-system/aws:v0.5.0`),
+		clusterctl upgrade apply --management-group capi-system/cluster-api  --infrastructure capa-system/aws:v0.5.0
+
+		# Upgrades only the aws provider instance in the capi-system/cluster-api management group to the v0.5.0 version;
+		# the namespace can be omitted only if the management group has a single instance of the aws provider.
+		clusterctl upgrade apply --management-group capi-system/cluster-api  --infrastructure aws:v0.5.0`),
 	Args: cobra.NoArgs,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runUpgradeApply()
@@ -76,6 +85,12 @@ func init() {
 		"Bootstrap providers instance and versions (e.g. capi-kubeadm-bootstrap-system/kubeadm:v0.3.0) to upgrade to. This flag can be used as alternative to --contract.")
 	upgradeApplyCmd.Flags().StringSliceVarP(&ua.controlPlaneProviders, "control-plane", "c", nil,
 		"ControlPlane providers instance and versions (e.g. capi-kubeadm-control-plane-system/kubeadm:v0.3.0) to upgrade to. This flag can be used as alternative to --contract.")
+	upgradeApplyCmd.Flags().BoolVar(&ua.ignoreVersionSkew, "ignore-version-skew", false,
+		"Apply the upgrade even if it violates the Kubernetes version skew policy of Cluster API, e.g. if the target API Version of Cluster API (contract) does not support the Kubernetes version of the management cluster.")
+	upgradeApplyCmd.Flags().BoolVar(&ua.noRollback, "no-rollback", false,
+		"Do not roll back the upgraded providers to their previous versions if the upgrade fails, e.g. if the Deployments of a provider do not become Available. Rollbacks are supported only for upgrades within the same API Version of Cluster API (contract).")
+	upgradeApplyCmd.Flags().DurationVar(&ua.waitProviderTimeout, "wait-provider-timeout", 5*time.Minute,
+		"The time to wait for each upgraded provider to become available before considering the upgrade failed.")
 }
 
 func runUpgradeApply() error {
@@ -101,6 +116,9 @@ func runUpgradeApply() error {
 		BootstrapProviders:      ua.bootstrapProviders,
 		ControlPlaneProviders:   ua.controlPlaneProviders,
 		InfrastructureProviders: ua.infrastructureProviders,
+		IgnoreVersionSkew:       ua.ignoreVersionSkew,
+		NoRollback:              ua.noRollback,
+		WaitProviderTimeout:     ua.waitProviderTimeout,
 	}); err != nil {
 		return err
 	}
@@ -28,6 +28,7 @@ import (
 type upgradePlanOptions struct {
 	kubeconfig        string
 	kubeconfigContext string
+	output            string
 }
 
 var up = &upgradePlanOptions{}
@@ -43,11 +44,19 @@ var upgradePlanCmd = &cobra.Command{
 
 		Then, for each provider in a management group, the following upgrade options are provided:
 		- The latest patch release for the current API Version of Cluster API (contract).
-		- The latest patch release for the next API Version of Cluster API (contract), if available.`),
+		- The latest patch release for the next API Version of Cluster API (contract), if available.
+
+		Each upgrade plan is checked against the Kubernetes version skew policy of Cluster API, i.e. the Kubernetes
+		versions of the management cluster and of the workload clusters must be supported by the target API Version
+		of Cluster API (contract); plans violating the policy are reported with the details of each violated rule, and
+		can only be applied using the --ignore-version-skew flag.`),
 
 	Example: Examples(`
 		# Gets the recommended target versions for upgrading Cluster API providers.
-		clusterctl upgrade plan`),
+		clusterctl upgrade plan
+
+		# Prints the recommended target versions in json format, e.g. for use in automation.
+		clusterctl upgrade plan -o json`),
 
 	RunE: func(cmd *cobra.Command, args []string) error {
 		return runUpgradePlan()
@@ -59,9 +68,15 @@ func init() {
 		"Path to the kubeconfig file to use for accessing the management cluster. If empty, default discovery rules apply.")
 	upgradePlanCmd.Flags().StringVar(&up.kubeconfigContext, "kubeconfig-context", "",
 		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	upgradePlanCmd.Flags().StringVarP(&up.output, "output", "o", OutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", Outputs))
 }
 
 func runUpgradePlan() error {
+	if err := validateOutput(up.output); err != nil {
+		return err
+	}
+
 	c, err := client.New(cfgFile)
 	if err != nil {
 		return err
@@ -73,20 +88,24 @@ func runUpgradePlan() error {
 	if err != nil {
 		return err
 	}
-	if certManUpgradePlan.ShouldUpgrade {
-		fmt.Printf("Cert-Manager will be upgraded from %q to %q\n\n", certManUpgradePlan.From, certManUpgradePlan.To)
-	} else {
-		fmt.Printf("Cert-Manager is already up to date\n\n")
-	}
 
 	upgradePlans, err := c.PlanUpgrade(client.PlanUpgradeOptions{
 		Kubeconfig: client.Kubeconfig{Path: up.kubeconfig, Context: up.kubeconfigContext},
 	})
-
 	if err != nil {
 		return err
 	}
 
+	if up.output != OutputText {
+		return printStructuredOutput(os.Stdout, client.NewUpgradePlanOutput(certManUpgradePlan, upgradePlans), up.output)
+	}
+
+	if certManUpgradePlan.ShouldUpgrade {
+		fmt.Printf("Cert-Manager will be upgraded from %q to %q\n\n", certManUpgradePlan.From, certManUpgradePlan.To)
+	} else {
+		fmt.Printf("Cert-Manager is already up to date\n\n")
+	}
+
 	if len(upgradePlans) == 0 {
 		fmt.Println("There are no management groups in the cluster. Please use clusterctl init to initialize a Cluster API management cluster.")
 		return nil
@@ -115,11 +134,25 @@ func runUpgradePlan() error {
 		w.Flush()
 		fmt.Println("")
 
-		if upgradeAvailable {
+		if len(plan.VersionSkewViolations) > 0 {
+			fmt.Println("The upgrade violates the Kubernetes version skew policy of Cluster API:")
+			fmt.Println("")
+			for _, v := range plan.VersionSkewViolations {
+				fmt.Printf("   - %s: %s\n", v.Rule, v.Message)
+			}
+			fmt.Println("")
+		}
+
+		switch {
+		case upgradeAvailable && len(plan.VersionSkewViolations) > 0:
+			fmt.Println("You can apply the upgrade anyway by executing the following command:")
+			fmt.Println("")
+			fmt.Printf("   upgrade apply --management-group %s --contract %s --ignore-version-skew\n", plan.CoreProvider.InstanceName(), plan.Contract)
+		case upgradeAvailable:
 			fmt.Println("You can now apply the upgrade by executing the following command:")
 			fmt.Println("")
 			fmt.Printf("   upgrade apply --management-group %s --contract %s\n", plan.CoreProvider.InstanceName(), plan.Contract)
-		} else {
+		default:
 			fmt.Println("You are already up to date!")
 		}
 		fmt.Println("")
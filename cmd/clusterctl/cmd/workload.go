@@ -0,0 +1,33 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var workloadCmd = &cobra.Command{
+	Use:   "workload",
+	Short: "Get or apply objects in a workload cluster",
+	Long: LongDesc(`
+		Get or apply objects in a workload cluster, using the kubeconfig secret stored
+		in the management cluster; no kubeconfig file for the workload cluster is required.`),
+}
+
+func init() {
+	RootCmd.AddCommand(workloadCmd)
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type workloadApplyOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	cluster           string
+
+	filename          string
+	workloadNamespace string
+	output            string
+}
+
+var wa = &workloadApplyOptions{}
+
+var workloadApplyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Apply objects to a workload cluster",
+	Long: LongDesc(`
+		Apply objects to a workload cluster using server-side apply, similarly to kubectl apply --server-side.`),
+
+	Example: Examples(`
+		# Apply the objects defined in a file to the workload cluster named test-1.
+		clusterctl workload apply -f ~/workspace/addons.yaml --cluster test-1
+
+		# Apply the objects read from stdin to the workload cluster named test-1 located in the foo namespace.
+		cat ~/workspace/addons.yaml | clusterctl workload apply -f - --cluster test-1 --namespace foo`),
+
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runWorkloadApply()
+	},
+}
+
+func init() {
+	workloadApplyCmd.Flags().StringVar(&wa.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	workloadApplyCmd.Flags().StringVar(&wa.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	workloadApplyCmd.Flags().StringVarP(&wa.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is located. If unspecified, the current namespace will be used.")
+	workloadApplyCmd.Flags().StringVar(&wa.cluster, "cluster", "",
+		"The name of the workload cluster.")
+	_ = workloadApplyCmd.MarkFlagRequired("cluster")
+
+	workloadApplyCmd.Flags().StringVarP(&wa.filename, "filename", "f", "",
+		"The file containing the objects to apply. Use '-' to read from stdin.")
+	_ = workloadApplyCmd.MarkFlagRequired("filename")
+	workloadApplyCmd.Flags().StringVar(&wa.workloadNamespace, "workload-namespace", "",
+		"The namespace of the objects without a namespace in the workload cluster. If unspecified, the default namespace will be used.")
+	workloadApplyCmd.Flags().StringVarP(&wa.output, "output", "o", OutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", Outputs))
+
+	workloadCmd.AddCommand(workloadApplyCmd)
+}
+
+func runWorkloadApply() error {
+	if err := validateOutput(wa.output); err != nil {
+		return err
+	}
+
+	var yaml []byte
+	var err error
+	if wa.filename == "-" {
+		yaml, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		yaml, err = ioutil.ReadFile(wa.filename)
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %q", wa.filename)
+	}
+
+	c, err := client.NewWorkloadClient(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	objs, err := c.WorkloadApply(client.WorkloadApplyOptions{
+		WorkloadClusterOptions: client.WorkloadClusterOptions{
+			Kubeconfig:          client.Kubeconfig{Path: wa.kubeconfig, Context: wa.kubeconfigContext},
+			Namespace:           wa.namespace,
+			WorkloadClusterName: wa.cluster,
+		},
+		Yaml:              yaml,
+		ResourceNamespace: wa.workloadNamespace,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printWorkloadObjects(objs, wa.output)
+}
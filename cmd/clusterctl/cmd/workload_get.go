@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gosuri/uitable"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"sigs.k8s.io/cluster-api/cmd/clusterctl/client"
+)
+
+type workloadGetOptions struct {
+	kubeconfig        string
+	kubeconfigContext string
+	namespace         string
+	cluster           string
+
+	workloadNamespace string
+	allNamespaces     bool
+	output            string
+}
+
+var wg = &workloadGetOptions{}
+
+var workloadGetCmd = &cobra.Command{
+	Use:   "get RESOURCE [NAME]",
+	Short: "Get objects in a workload cluster",
+	Long: LongDesc(`
+		Get objects in a workload cluster, similarly to kubectl get.`),
+
+	Example: Examples(`
+		# Get the nodes of the workload cluster named test-1.
+		clusterctl workload get nodes --cluster test-1
+
+		# Get the pods in the kube-system namespace of the workload cluster named test-1.
+		clusterctl workload get pods --cluster test-1 --workload-namespace kube-system
+
+		# Get the pods in all the namespaces of the workload cluster named test-1 located in the foo namespace.
+		clusterctl workload get pods --cluster test-1 --namespace foo --all-namespaces
+
+		# Get a deployment of the workload cluster named test-1 in yaml format.
+		clusterctl workload get deployments.apps coredns --cluster test-1 --workload-namespace kube-system -o yaml`),
+
+	Args: cobra.RangeArgs(1, 2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name := ""
+		if len(args) > 1 {
+			name = args[1]
+		}
+		return runWorkloadGet(args[0], name)
+	},
+}
+
+func init() {
+	workloadGetCmd.Flags().StringVar(&wg.kubeconfig, "kubeconfig", "",
+		"Path to the kubeconfig file to use for accessing the management cluster. If unspecified, default discovery rules apply.")
+	workloadGetCmd.Flags().StringVar(&wg.kubeconfigContext, "kubeconfig-context", "",
+		"Context to be used within the kubeconfig file. If empty, current context will be used.")
+	workloadGetCmd.Flags().StringVarP(&wg.namespace, "namespace", "n", "",
+		"The namespace where the workload cluster is located. If unspecified, the current namespace will be used.")
+	workloadGetCmd.Flags().StringVar(&wg.cluster, "cluster", "",
+		"The name of the workload cluster.")
+	_ = workloadGetCmd.MarkFlagRequired("cluster")
+
+	workloadGetCmd.Flags().StringVar(&wg.workloadNamespace, "workload-namespace", "",
+		"The namespace of the objects in the workload cluster. If unspecified, the default namespace will be used.")
+	workloadGetCmd.Flags().BoolVarP(&wg.allNamespaces, "all-namespaces", "A", false,
+		"List the objects across all the namespaces of the workload cluster.")
+	workloadGetCmd.Flags().StringVarP(&wg.output, "output", "o", OutputText,
+		fmt.Sprintf("Output format. Valid values: %v.", Outputs))
+
+	workloadCmd.AddCommand(workloadGetCmd)
+}
+
+func runWorkloadGet(resource, name string) error {
+	if err := validateOutput(wg.output); err != nil {
+		return err
+	}
+
+	c, err := client.NewWorkloadClient(cfgFile)
+	if err != nil {
+		return err
+	}
+
+	objs, err := c.WorkloadGet(client.WorkloadGetOptions{
+		WorkloadClusterOptions: client.WorkloadClusterOptions{
+			Kubeconfig:          client.Kubeconfig{Path: wg.kubeconfig, Context: wg.kubeconfigContext},
+			Namespace:           wg.namespace,
+			WorkloadClusterName: wg.cluster,
+		},
+		Resource:          resource,
+		Name:              name,
+		ResourceNamespace: wg.workloadNamespace,
+		AllNamespaces:     wg.allNamespaces,
+	})
+	if err != nil {
+		return err
+	}
+
+	return printWorkloadObjects(objs, wg.output)
+}
+
+// printWorkloadObjects prints the objects read from or applied to a workload cluster.
+func printWorkloadObjects(objs []unstructured.Unstructured, output string) error {
+	if output != OutputText {
+		if len(objs) == 1 {
+			return printStructuredOutput(os.Stdout, objs[0].Object, output)
+		}
+		items := make([]interface{}, 0, len(objs))
+		for i := range objs {
+			items = append(items, objs[i].Object)
+		}
+		list := map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+		}
+		return printStructuredOutput(os.Stdout, list, output)
+	}
+
+	if len(objs) == 0 {
+		fmt.Println("No resources found")
+		return nil
+	}
+
+	t := uitable.New()
+	t.AddRow("NAMESPACE", "KIND", "NAME", "AGE")
+	for i := range objs {
+		obj := objs[i]
+		t.AddRow(obj.GetNamespace(), obj.GetKind(), obj.GetName(), workloadObjectAge(obj.GetCreationTimestamp()))
+	}
+	fmt.Println(t)
+	return nil
+}
+
+func workloadObjectAge(t metav1.Time) string {
+	if t.IsZero() {
+		return "<unknown>"
+	}
+	return duration.HumanDuration(time.Since(t.Time))
+}
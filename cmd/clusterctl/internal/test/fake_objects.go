@@ -45,6 +45,7 @@ type FakeCluster struct {
 	machineSets           []*FakeMachineSet
 	machines              []*FakeMachine
 	withCloudConfigSecret bool
+	labels                map[string]string
 }
 
 // NewFakeCluster return a FakeCluster that can generate a cluster object, all its own ancillary objects:
@@ -60,6 +61,11 @@ func NewFakeCluster(namespace, name string) *FakeCluster {
 	}
 }
 
+func (f *FakeCluster) WithLabels(labels map[string]string) *FakeCluster {
+	f.labels = labels
+	return f
+}
+
 func (f *FakeCluster) WithControlPlane(fakeControlPlane *FakeControlPlane) *FakeCluster {
 	f.controlPlane = fakeControlPlane
 	return f
@@ -112,6 +118,7 @@ func (f *FakeCluster) Objs() []client.Object {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      f.name,
 			Namespace: f.namespace,
+			Labels:    f.labels,
 			// Labels: cluster.x-k8s.io/cluster-name=cluster MISSING??
 		},
 		Spec: clusterv1.ClusterSpec{
@@ -385,15 +392,8 @@ func (f *FakeMachinePool) Objs(cluster *clusterv1.Cluster) []client.Object {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      f.name,
 			Namespace: cluster.Namespace,
-			OwnerReferences: []metav1.OwnerReference{ // Added by the machinePool controller (mirrors machinePool.spec.ClusterName) -- RECONCILED
-				{
-					APIVersion: clusterv1.GroupVersion.String(),
-					Kind:       "Cluster",
-					Name:       cluster.Name,
-					UID:        cluster.UID,
-				},
-			},
-			// Labels: MISSING
+			// OwnerReferences: machinePool, Added by the machinePool controller (see below) -- RECONCILED
+			// Labels: cluster.x-k8s.io/cluster-name=cluster, Added by the machinePool controller (see below) -- RECONCILED
 		},
 	}
 
@@ -405,15 +405,8 @@ func (f *FakeMachinePool) Objs(cluster *clusterv1.Cluster) []client.Object {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      f.name,
 			Namespace: cluster.Namespace,
-			OwnerReferences: []metav1.OwnerReference{ // Added by the machinePool controller (mirrors machinePool.spec.ClusterName) -- RECONCILED
-				{
-					APIVersion: clusterv1.GroupVersion.String(),
-					Kind:       "Cluster",
-					Name:       cluster.Name,
-					UID:        cluster.UID,
-				},
-			},
-			// Labels: MISSING
+			// OwnerReferences: machinePool, Added by the machinePool controller (see below) -- RECONCILED
+			// Labels: cluster.x-k8s.io/cluster-name=cluster, Added by the machinePool controller (see below) -- RECONCILED
 		},
 	}
 
@@ -463,6 +456,14 @@ func (f *FakeMachinePool) Objs(cluster *clusterv1.Cluster) []client.Object {
 	// Ensure the machinePool gets a UID to be used by dependant objects for creating OwnerReferences.
 	setUID(machinePool)
 
+	// The infrastructure and bootstrap objects are controlled by the machinePool / ownership and cluster label set by the machinePool controller -- RECONCILED
+	for _, obj := range []client.Object{machinePoolInfrastructure, machinePoolBootstrap} {
+		obj.SetOwnerReferences([]metav1.OwnerReference{*metav1.NewControllerRef(machinePool, machinePool.GroupVersionKind())})
+		obj.SetLabels(map[string]string{
+			clusterv1.ClusterLabelName: cluster.Name,
+		})
+	}
+
 	objs := []client.Object{
 		machinePool,
 		machinePoolInfrastructure,
@@ -1201,7 +1202,7 @@ func FakeCustomResourceDefinition(group string, kind string, versions ...string)
 
 	for i, version := range versions {
 		// set the first version as a storage version
-		versionObj := apiextensionslv1.CustomResourceDefinitionVersion{Name: version}
+		versionObj := apiextensionslv1.CustomResourceDefinitionVersion{Name: version, Served: true}
 		if i == 0 {
 			versionObj.Storage = true
 		}
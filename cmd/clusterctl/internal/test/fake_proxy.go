@@ -36,9 +36,10 @@ import (
 )
 
 type FakeProxy struct {
-	cs        client.Client
-	namespace string
-	objs      []client.Object
+	cs            client.Client
+	namespace     string
+	serverVersion string
+	objs          []client.Object
 }
 
 var (
@@ -67,6 +68,10 @@ func (f *FakeProxy) ValidateKubernetesVersion() error {
 	return nil
 }
 
+func (f *FakeProxy) GetServerVersion() (string, error) {
+	return f.serverVersion, nil
+}
+
 func (f *FakeProxy) GetConfig() (*rest.Config, error) {
 	return nil, nil
 }
@@ -124,7 +129,8 @@ func (f *FakeProxy) ListResources(labels map[string]string, namespaces ...string
 
 func NewFakeProxy() *FakeProxy {
 	return &FakeProxy{
-		namespace: "default",
+		namespace:     "default",
+		serverVersion: "v1.19.1",
 	}
 }
 
@@ -138,6 +144,11 @@ func (f *FakeProxy) WithNamespace(n string) *FakeProxy {
 	return f
 }
 
+func (f *FakeProxy) WithServerVersion(v string) *FakeProxy {
+	f.serverVersion = v
+	return f
+}
+
 // WithProviderInventory can be used as a fast track for setting up test scenarios requiring an already initialized management cluster.
 // NB. this method adds an items to the Provider inventory, but it doesn't install the corresponding provider; if the
 // test case requires the actual provider to be installed, use the the fake client to install both the provider
@@ -43,6 +43,7 @@ type configProvider struct {
 type imageMeta struct {
 	Repository string `json:"repository,omitempty"`
 	Tag        string `json:"tag,omitempty"`
+	Digest     string `json:"digest,omitempty"`
 }
 
 func (f *FakeReader) Init(config string) error {
@@ -105,3 +106,14 @@ func (f *FakeReader) WithImageMeta(component, repository, tag string) *FakeReade
 
 	return f
 }
+
+func (f *FakeReader) WithImageMetaDigest(component, digest string) *FakeReader {
+	m := f.imageMetas[component]
+	m.Digest = digest
+	f.imageMetas[component] = m
+
+	yaml, _ := yaml.Marshal(f.imageMetas)
+	f.variables["images"] = string(yaml)
+
+	return f
+}
@@ -101,7 +101,7 @@ func IsSharedResource(o unstructured.Unstructured) bool {
 // FixImages alters images using the give alter func
 // NB. The implemented approach is specific for the provider components YAML & for the cert-manager manifest; it is not
 // intended to cover all the possible objects used to deploy containers existing in Kubernetes.
-func FixImages(objs []unstructured.Unstructured, alterImageFunc func(image string) (string, error)) ([]unstructured.Unstructured, error) {
+func FixImages(objs []unstructured.Unstructured, alterImageFunc func(container, image string) (string, error)) ([]unstructured.Unstructured, error) {
 	// look for resources of kind Deployment and alter the image
 	for i := range objs {
 		o := &objs[i]
@@ -118,7 +118,7 @@ func FixImages(objs []unstructured.Unstructured, alterImageFunc func(image strin
 		// Alter the image
 		for j := range d.Spec.Template.Spec.Containers {
 			container := d.Spec.Template.Spec.Containers[j]
-			image, err := alterImageFunc(container.Image)
+			image, err := alterImageFunc(container.Name, container.Image)
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to fix image for container %s in deployment %s", container.Name, d.Name)
 			}
@@ -128,7 +128,7 @@ func FixImages(objs []unstructured.Unstructured, alterImageFunc func(image strin
 
 		for j := range d.Spec.Template.Spec.InitContainers {
 			container := d.Spec.Template.Spec.InitContainers[j]
-			image, err := alterImageFunc(container.Image)
+			image, err := alterImageFunc(container.Name, container.Image)
 			if err != nil {
 				return nil, errors.Wrapf(err, "failed to fix image for init container %s in deployment %s", container.Name, d.Name)
 			}
@@ -146,7 +146,7 @@ func Test_inspectImages(t *testing.T) {
 func TestFixImages(t *testing.T) {
 	type args struct {
 		objs           []unstructured.Unstructured
-		alterImageFunc func(image string) (string, error)
+		alterImageFunc func(container, image string) (string, error)
 	}
 	tests := []struct {
 		name    string
@@ -167,11 +167,13 @@ func TestFixImages(t *testing.T) {
 									"spec": map[string]interface{}{
 										"containers": []map[string]interface{}{
 											{
+												"name":  "container",
 												"image": "container-image",
 											},
 										},
 										"initContainers": []map[string]interface{}{
 											{
+												"name":  "init-container",
 												"image": "init-container-image",
 											},
 										},
@@ -181,11 +183,11 @@ func TestFixImages(t *testing.T) {
 						},
 					},
 				},
-				alterImageFunc: func(image string) (string, error) {
-					return fmt.Sprintf("foo-%s", image), nil
+				alterImageFunc: func(container, image string) (string, error) {
+					return fmt.Sprintf("%s-%s", container, image), nil
 				},
 			},
-			want:    []string{"foo-container-image", "foo-init-container-image"},
+			want:    []string{"container-container-image", "init-container-init-container-image"},
 			wantErr: false,
 		},
 	}
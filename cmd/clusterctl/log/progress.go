@@ -0,0 +1,225 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ProgressFormat defines how the progress of long running operations is reported.
+type ProgressFormat string
+
+const (
+	// PlainProgressFormat reports the completion of each step, with its duration, using the clusterctl logger at V(1).
+	PlainProgressFormat ProgressFormat = "plain"
+
+	// BarProgressFormat reports each step with a text progress bar.
+	BarProgressFormat ProgressFormat = "bar"
+
+	// JSONProgressFormat reports each step as a ProgressEvent, one JSON object per line.
+	JSONProgressFormat ProgressFormat = "json"
+)
+
+const progressBarWidth = 20
+
+var (
+	progressFormat = PlainProgressFormat
+
+	// progressOutput is the writer used by the bar and the JSON progress formats.
+	progressOutput io.Writer = os.Stderr
+
+	// progressNow returns the current time; it can be replaced by tests.
+	progressNow = time.Now
+)
+
+// SetProgressFormat sets the format used for reporting the progress of long running operations.
+func SetProgressFormat(format string) error {
+	switch f := ProgressFormat(format); f {
+	case PlainProgressFormat, BarProgressFormat, JSONProgressFormat:
+		progressFormat = f
+		return nil
+	default:
+		return errors.Errorf("invalid progress format %q, valid values are %q, %q and %q", format, PlainProgressFormat, BarProgressFormat, JSONProgressFormat)
+	}
+}
+
+// ProgressStatus is the status of a step reported in a ProgressEvent.
+type ProgressStatus string
+
+const (
+	// ProgressStarted documents a step being started.
+	ProgressStarted ProgressStatus = "Started"
+
+	// ProgressSucceeded documents a step, or the whole operation when Step is empty, being completed successfully.
+	ProgressSucceeded ProgressStatus = "Succeeded"
+
+	// ProgressFailed documents a step, or the whole operation when Step is empty, being failed.
+	ProgressFailed ProgressStatus = "Failed"
+)
+
+// ProgressEvent defines a change in the progress of a long running operation, as reported by the JSON progress format.
+type ProgressEvent struct {
+	// Operation is the name of the operation, e.g. init.
+	Operation string `json:"operation"`
+
+	// Step is the name of the step; it is empty for the events reporting the completion of the whole operation.
+	Step string `json:"step,omitempty"`
+
+	// Index is the 1-based index of the step, and Total the number of steps of the operation.
+	Index int `json:"index"`
+	Total int `json:"total"`
+
+	// Status of the step, or of the whole operation.
+	Status ProgressStatus `json:"status"`
+
+	// DurationSeconds is the duration of the step, or of the whole operation, once completed.
+	DurationSeconds float64 `json:"durationSeconds,omitempty"`
+
+	// Error is the error of a failed step or operation.
+	Error string `json:"error,omitempty"`
+}
+
+// Progress reports the progress of a long running operation, composed of a known number of steps.
+type Progress struct {
+	operation string
+	total     int
+
+	index     int
+	step      string
+	start     time.Time
+	stepStart time.Time
+}
+
+// NewProgress returns a Progress for an operation composed of total steps.
+func NewProgress(operation string, total int) *Progress {
+	return &Progress{
+		operation: operation,
+		total:     total,
+		start:     progressNow(),
+	}
+}
+
+// Step completes the current step, if any, and starts the next one.
+func (p *Progress) Step(name string) {
+	p.completeStep(nil)
+
+	p.index++
+	p.step = name
+	p.stepStart = progressNow()
+	p.report(ProgressEvent{Operation: p.operation, Step: p.step, Index: p.index, Total: p.total, Status: ProgressStarted})
+}
+
+// Done completes the current step and the whole operation; if err is not nil, both are reported as failed.
+func (p *Progress) Done(err error) {
+	p.completeStep(err)
+
+	event := ProgressEvent{
+		Operation:       p.operation,
+		Index:           p.index,
+		Total:           p.total,
+		Status:          ProgressSucceeded,
+		DurationSeconds: progressNow().Sub(p.start).Seconds(),
+	}
+	if err != nil {
+		event.Status = ProgressFailed
+		event.Error = err.Error()
+	}
+	p.report(event)
+}
+
+func (p *Progress) completeStep(err error) {
+	if p.step == "" {
+		return
+	}
+
+	event := ProgressEvent{
+		Operation:       p.operation,
+		Step:            p.step,
+		Index:           p.index,
+		Total:           p.total,
+		Status:          ProgressSucceeded,
+		DurationSeconds: progressNow().Sub(p.stepStart).Seconds(),
+	}
+	if err != nil {
+		event.Status = ProgressFailed
+		event.Error = err.Error()
+	}
+	p.step = ""
+	p.report(event)
+}
+
+func (p *Progress) report(event ProgressEvent) {
+	switch progressFormat {
+	case JSONProgressFormat:
+		b, err := json.Marshal(event)
+		if err != nil {
+			// Progress reporting must not break the operation being reported.
+			Log.Error(err, "Failed to report progress", "Operation", event.Operation)
+			return
+		}
+		fmt.Fprintln(progressOutput, string(b))
+	case BarProgressFormat:
+		if line := progressBarLine(event); line != "" {
+			fmt.Fprintln(progressOutput, line)
+		}
+	default:
+		if event.Status == ProgressStarted {
+			return
+		}
+		values := []interface{}{"Operation", event.Operation}
+		if event.Step != "" {
+			values = append(values, "Step", event.Step)
+		}
+		values = append(values, "Duration", time.Duration(event.DurationSeconds*float64(time.Second)).Round(time.Millisecond).String())
+		if event.Status == ProgressFailed {
+			Log.V(1).Info("Failed", values...)
+			return
+		}
+		Log.V(1).Info("Completed", values...)
+	}
+}
+
+// progressBarLine returns the text progress bar for an event, e.g. [##########----------] 2/4 Installing providers.
+func progressBarLine(event ProgressEvent) string {
+	duration := time.Duration(event.DurationSeconds * float64(time.Second)).Round(time.Second)
+	switch {
+	case event.Step == "" && event.Status == ProgressFailed:
+		return fmt.Sprintf("%s failed after %s: %s", event.Operation, duration, event.Error)
+	case event.Step == "":
+		return fmt.Sprintf("%s completed in %s", event.Operation, duration)
+	case event.Status == ProgressStarted:
+		done := 0
+		if event.Total > 0 {
+			done = (event.Index - 1) * progressBarWidth / event.Total
+		}
+		if done > progressBarWidth {
+			done = progressBarWidth
+		}
+		bar := strings.Repeat("#", done) + strings.Repeat("-", progressBarWidth-done)
+		return fmt.Sprintf("[%s] %d/%d %s", bar, event.Index, event.Total, event.Step)
+	default:
+		// Step completions are reported by the bar of the following step, or by the completion of the operation.
+		return ""
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package log
+
+import (
+	"bytes"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/pkg/errors"
+)
+
+func TestSetProgressFormat(t *testing.T) {
+	g := NewWithT(t)
+	defer func() { progressFormat = PlainProgressFormat }()
+
+	g.Expect(SetProgressFormat("json")).To(Succeed())
+	g.Expect(progressFormat).To(Equal(JSONProgressFormat))
+	g.Expect(SetProgressFormat("fancy")).ToNot(Succeed())
+	g.Expect(progressFormat).To(Equal(JSONProgressFormat))
+}
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name   string
+		format ProgressFormat
+		err    error
+		want   []string
+	}{
+		{
+			name:   "json",
+			format: JSONProgressFormat,
+			want: []string{
+				`{"operation":"init","step":"Fetching providers","index":1,"total":2,"status":"Started"}`,
+				`{"operation":"init","step":"Fetching providers","index":1,"total":2,"status":"Succeeded","durationSeconds":1}`,
+				`{"operation":"init","step":"Installing providers","index":2,"total":2,"status":"Started"}`,
+				`{"operation":"init","step":"Installing providers","index":2,"total":2,"status":"Succeeded","durationSeconds":1}`,
+				`{"operation":"init","index":2,"total":2,"status":"Succeeded","durationSeconds":3}`,
+			},
+		},
+		{
+			name:   "json with error",
+			format: JSONProgressFormat,
+			err:    errors.New("boom"),
+			want: []string{
+				`{"operation":"init","step":"Fetching providers","index":1,"total":2,"status":"Started"}`,
+				`{"operation":"init","step":"Fetching providers","index":1,"total":2,"status":"Succeeded","durationSeconds":1}`,
+				`{"operation":"init","step":"Installing providers","index":2,"total":2,"status":"Started"}`,
+				`{"operation":"init","step":"Installing providers","index":2,"total":2,"status":"Failed","durationSeconds":1,"error":"boom"}`,
+				`{"operation":"init","index":2,"total":2,"status":"Failed","durationSeconds":3,"error":"boom"}`,
+			},
+		},
+		{
+			name:   "bar",
+			format: BarProgressFormat,
+			want: []string{
+				"[--------------------] 1/2 Fetching providers",
+				"[##########----------] 2/2 Installing providers",
+				"init completed in 3s",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			out := &bytes.Buffer{}
+			now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+			progressFormat, progressOutput, progressNow = tt.format, out, func() time.Time { return now }
+			defer func() {
+				progressFormat, progressOutput, progressNow = PlainProgressFormat, os.Stderr, time.Now
+			}()
+
+			p := NewProgress("init", 2)
+			now = now.Add(time.Second)
+			p.Step("Fetching providers")
+			now = now.Add(time.Second)
+			p.Step("Installing providers")
+			now = now.Add(time.Second)
+			p.Done(tt.err)
+
+			g.Expect(strings.Split(strings.TrimSpace(out.String()), "\n")).To(Equal(tt.want))
+		})
+	}
+}
@@ -24,19 +24,23 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/defaulting"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -64,11 +68,25 @@ const (
 // ClusterReconciler reconciles a Cluster object
 type ClusterReconciler struct {
 	Client           client.Client
+	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
-	restConfig      *rest.Config
-	recorder        record.EventRecorder
-	externalTracker external.ObjectTracker
+	// WebhooksDisabled applies the defaults and the validation of the admission webhooks to the Clusters inside the
+	// controller, for management clusters where the webhooks are not installed.
+	WebhooksDisabled bool
+
+	// ResyncPeriod is the period after which Clusters not yet provisioned, or failing the workload cluster health
+	// checks, are reconciled again; if zero, Clusters are reconciled again only when the manager's SyncPeriod expires.
+	ResyncPeriod time.Duration
+
+	// WorkloadComponentsHealthCheckInterval is the interval at which the components in the workload cluster listed in
+	// the Clusters' WorkloadComponentHealthChecks are checked; if zero, they are checked every 5 minutes.
+	WorkloadComponentsHealthCheckInterval time.Duration
+
+	restConfig               *rest.Config
+	recorder                 record.EventRecorder
+	externalTracker          external.ObjectTracker
+	workloadComponentsChecks workloadComponentsChecks
 }
 
 func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -94,7 +112,7 @@ func (r *ClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manag
 	return nil
 }
 
-func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, reterr error) {
 	log := ctrl.LoggerFrom(ctx)
 
 	// Fetch the Cluster instance.
@@ -125,6 +143,7 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 	defer func() {
 		// Always reconcile the Status.Phase field.
 		r.reconcilePhase(ctx, cluster)
+		res = withResync(res, reterr, r.ResyncPeriod, r.needsResync(cluster))
 
 		// Always attempt to Patch the Cluster object and status after each reconciliation.
 		// Patch ObservedGeneration only if the reconciliation completed successfully
@@ -137,6 +156,15 @@ func (r *ClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		}
 	}()
 
+	// Apply the defaults and the validation of the webhooks, when they are not installed.
+	if r.WebhooksDisabled {
+		if err := defaulting.DefaultAndValidate(cluster); err != nil {
+			log.Error(err, "Invalid Cluster, waiting for it to be fixed")
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, "InvalidObject", "%v", err)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Add finalizer first if not exist to avoid the race condition between init and delete
 	if !controllerutil.ContainsFinalizer(cluster, clusterv1.ClusterFinalizer) {
 		controllerutil.AddFinalizer(cluster, clusterv1.ClusterFinalizer)
@@ -158,6 +186,8 @@ func patchCluster(ctx context.Context, patchHelper *patch.Helper, cluster *clust
 		conditions.WithConditions(
 			clusterv1.ControlPlaneReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
+			clusterv1.ControlPlaneEndpointTrustedCondition,
+			clusterv1.WorkloadComponentsHealthyCondition,
 		),
 	)
 
@@ -169,6 +199,9 @@ func patchCluster(ctx context.Context, patchHelper *patch.Helper, cluster *clust
 			clusterv1.ReadyCondition,
 			clusterv1.ControlPlaneReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
+			clusterv1.ControlPlaneEndpointTrustedCondition,
+			clusterv1.WorkloadComponentsHealthyCondition,
+			clusterv1.DeletionBlockedCondition,
 		}},
 	)
 	return patchHelper.Patch(ctx, cluster, options...)
@@ -181,6 +214,8 @@ func (r *ClusterReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 		r.reconcileControlPlane,
 		r.reconcileKubeconfig,
 		r.reconcileControlPlaneInitialized,
+		r.reconcileControlPlaneEndpointTrust,
+		r.reconcileWorkloadComponentsHealth,
 	}
 
 	res := ctrl.Result{}
@@ -243,6 +278,7 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	if descendantCount := descendants.length(); descendantCount > 0 {
 		indirect := descendantCount - len(children)
 		log.Info("Cluster still has descendants - need to requeue", "descendants", descendants.descendantNames(), "indirect descendants count", indirect)
+		markDeletionBlocked(cluster, clusterv1.WaitingForDescendantsDeletionReason, descendants.deletionBlockers())
 		// Requeue so we can check the next time to see if there are still any descendants left.
 		return ctrl.Result{RequeueAfter: deleteRequeueAfter}, nil
 	}
@@ -274,6 +310,7 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 
 			// Return here so we don't remove the finalizer yet.
 			log.Info("Cluster still has descendants - need to requeue", "controlPlaneRef", cluster.Spec.ControlPlaneRef.Name)
+			markDeletionBlocked(cluster, clusterv1.WaitingForControlPlaneDeletionReason, []deletionBlocker{{kind: obj.GetKind(), obj: obj}})
 			return ctrl.Result{}, nil
 		}
 	}
@@ -305,14 +342,62 @@ func (r *ClusterReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 
 			// Return here so we don't remove the finalizer yet.
 			log.Info("Cluster still has descendants - need to requeue", "infrastructureRef", cluster.Spec.InfrastructureRef.Name)
+			markDeletionBlocked(cluster, clusterv1.WaitingForInfrastructureDeletionReason, []deletionBlocker{{kind: obj.GetKind(), obj: obj}})
 			return ctrl.Result{}, nil
 		}
 	}
 
+	conditions.Delete(cluster, clusterv1.DeletionBlockedCondition)
+	r.workloadComponentsChecks.forget(cluster)
 	controllerutil.RemoveFinalizer(cluster, clusterv1.ClusterFinalizer)
 	return ctrl.Result{}, nil
 }
 
+// maxDeletionBlockers is the maximum number of objects listed in the DeletionBlocked condition message.
+const maxDeletionBlockers = 10
+
+// deletionBlocker is an object blocking the deletion of a Cluster.
+type deletionBlocker struct {
+	kind string
+	obj  metav1.Object
+}
+
+// markDeletionBlocked sets the DeletionBlocked condition on the cluster, listing the objects blocking its deletion.
+func markDeletionBlocked(cluster *clusterv1.Cluster, reason string, blockers []deletionBlocker) {
+	conditions.Set(cluster, &clusterv1.Condition{
+		Type:    clusterv1.DeletionBlockedCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  reason,
+		Message: deletionBlockedMessage(blockers),
+	})
+}
+
+// deletionBlockedMessage returns a message listing the objects blocking the deletion of a Cluster, e.g.
+// "Machine/m1 deleted at 2021-03-01T10:00:00Z, waiting for finalizers [machine.cluster.x-k8s.io]".
+// The message includes the deletion timestamps instead of the time elapsed since, so that it changes only when
+// the objects blocking the deletion change, instead of at every reconciliation.
+func deletionBlockedMessage(blockers []deletionBlocker) string {
+	msgs := make([]string, 0, len(blockers))
+	for i, b := range blockers {
+		if i == maxDeletionBlockers {
+			msgs = append(msgs, fmt.Sprintf("and %d more", len(blockers)-maxDeletionBlockers))
+			break
+		}
+		name := fmt.Sprintf("%s/%s", b.kind, b.obj.GetName())
+		deletionTimestamp := b.obj.GetDeletionTimestamp()
+		switch {
+		case deletionTimestamp.IsZero():
+			msgs = append(msgs, fmt.Sprintf("%s not yet deleted", name))
+		case len(b.obj.GetFinalizers()) == 0:
+			msgs = append(msgs, fmt.Sprintf("%s deleted at %s", name, deletionTimestamp.UTC().Format(time.RFC3339)))
+		default:
+			msgs = append(msgs, fmt.Sprintf("%s deleted at %s, waiting for finalizers [%s]",
+				name, deletionTimestamp.UTC().Format(time.RFC3339), strings.Join(b.obj.GetFinalizers(), ", ")))
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
 type clusterDescendants struct {
 	machineDeployments   clusterv1.MachineDeploymentList
 	machineSets          clusterv1.MachineSetList
@@ -329,6 +414,27 @@ func (c *clusterDescendants) length() int {
 		len(c.workerMachines.Items)
 }
 
+// deletionBlockers returns the descendants as objects blocking the deletion of the Cluster, workers first.
+func (c *clusterDescendants) deletionBlockers() []deletionBlocker {
+	blockers := []deletionBlocker{}
+	for i := range c.machineDeployments.Items {
+		blockers = append(blockers, deletionBlocker{kind: "MachineDeployment", obj: &c.machineDeployments.Items[i]})
+	}
+	for i := range c.machineSets.Items {
+		blockers = append(blockers, deletionBlocker{kind: "MachineSet", obj: &c.machineSets.Items[i]})
+	}
+	for i := range c.machinePools.Items {
+		blockers = append(blockers, deletionBlocker{kind: "MachinePool", obj: &c.machinePools.Items[i]})
+	}
+	for i := range c.workerMachines.Items {
+		blockers = append(blockers, deletionBlocker{kind: "Machine", obj: &c.workerMachines.Items[i]})
+	}
+	for i := range c.controlPlaneMachines.Items {
+		blockers = append(blockers, deletionBlocker{kind: "Machine", obj: &c.controlPlaneMachines.Items[i]})
+	}
+	return blockers
+}
+
 func (c *clusterDescendants) descendantNames() string {
 	descendants := make([]string, 0)
 	controlPlaneMachineNames := make([]string, len(c.controlPlaneMachines.Items))
@@ -517,3 +623,12 @@ func (r *ClusterReconciler) controlPlaneMachineToCluster(o client.Object) []ctrl
 		NamespacedName: util.ObjectKey(cluster),
 	}}
 }
+
+// needsResync returns true if the Cluster is not provisioned yet, or if the workload cluster is failing
+// the health checks of the ClusterCacheTracker.
+func (r *ClusterReconciler) needsResync(cluster *clusterv1.Cluster) bool {
+	if cluster.Status.GetTypedPhase() != clusterv1.ClusterPhaseProvisioned {
+		return true
+	}
+	return r.Tracker != nil && r.Tracker.IsClusterUnhealthy(util.ObjectKey(cluster))
+}
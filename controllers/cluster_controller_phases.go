@@ -27,6 +27,7 @@ import (
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/remote"
 	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
@@ -174,9 +175,12 @@ func (r *ClusterReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		conditions.WithFallbackValue(ready, clusterv1.WaitingForInfrastructureFallbackReason, clusterv1.ConditionSeverityInfo, ""),
 	)
 
+	// If the infrastructure provider is not ready, rely on the watch on the infrastructure object, unless the provider
+	// reports when it expects the object to be ready.
 	if !ready {
-		log.V(3).Info("Infrastructure provider is not ready yet")
-		return ctrl.Result{}, nil
+		requeueAfter := external.RequeueAfterFrom(log, infraConfig, 0)
+		log.V(3).Info("Infrastructure provider is not ready yet", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	// Get and parse Spec.ControlPlaneEndpoint field from the infrastructure provider.
@@ -242,6 +246,11 @@ func (r *ClusterReconciler) reconcileControlPlane(ctx context.Context, cluster *
 		if err != nil {
 			return ctrl.Result{}, err
 		}
+		// Externally managed control planes (e.g. AKS, EKS, GKE) have no control plane Machines to be initialized,
+		// so the control plane is considered initialized as soon as it is ready.
+		if !initialized && util.IsExternalManagedControlPlane(controlPlaneConfig) {
+			initialized = ready
+		}
 		cluster.Status.ControlPlaneInitialized = initialized
 	}
 
@@ -278,3 +287,54 @@ func (r *ClusterReconciler) reconcileKubeconfig(ctx context.Context, cluster *cl
 
 	return ctrl.Result{}, nil
 }
+
+// reconcileControlPlaneEndpointTrust checks that the certificate served by the workload cluster API server can
+// be verified using the certificate authority in the kubeconfig secret. When this is not the case, e.g. after
+// a CA rotation which has not been reflected in the kubeconfig secret, the ControlPlaneEndpointTrusted condition
+// is set to false, the remote cluster accessor is invalidated and a warning event with remediation hints is emitted.
+func (r *ClusterReconciler) reconcileControlPlaneEndpointTrust(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if !cluster.Status.ControlPlaneInitialized || !cluster.Spec.ControlPlaneEndpoint.IsValid() {
+		return ctrl.Result{}, nil
+	}
+
+	restConfig, err := remote.RESTConfig(ctx, "cluster-controller", r.Client, util.ObjectKey(cluster))
+	if err != nil {
+		// The kubeconfig secret might not have been created yet, there is nothing to check.
+		log.V(4).Info("Unable to get REST config for the workload cluster, skipping certificate authority check", "err", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	err = remote.VerifyCertificateAuthority(ctx, restConfig)
+	switch {
+	case remote.IsCertificateAuthorityMismatch(err):
+		wasTrusted := !conditions.IsFalse(cluster, clusterv1.ControlPlaneEndpointTrustedCondition)
+		conditions.MarkFalse(cluster, clusterv1.ControlPlaneEndpointTrustedCondition, clusterv1.CertificateAuthorityMismatchReason, clusterv1.ConditionSeverityError,
+			"The certificate served by %s is not signed by the certificate authority in Secret %s; "+
+				"update the CA in the Secret %s or regenerate the kubeconfig Secret to match the rotated CA",
+			cluster.Spec.ControlPlaneEndpoint.String(), secret.Name(cluster.Name, secret.Kubeconfig), secret.Name(cluster.Name, secret.ClusterCA))
+
+		if wasTrusted {
+			if r.Tracker != nil {
+				r.Tracker.InvalidateAccessor(util.ObjectKey(cluster))
+			}
+			r.recorder.Eventf(cluster, corev1.EventTypeWarning, clusterv1.CertificateAuthorityMismatchReason,
+				"The workload cluster API server at %s presents a certificate not signed by the CA in Secret %s. "+
+					"If the cluster CA was rotated, update Secret %s and delete Secret %s to get it regenerated",
+				cluster.Spec.ControlPlaneEndpoint.String(), secret.Name(cluster.Name, secret.Kubeconfig),
+				secret.Name(cluster.Name, secret.ClusterCA), secret.Name(cluster.Name, secret.Kubeconfig))
+		}
+		log.Info("Certificate authority mismatch detected for the workload cluster API server", "err", err.Error())
+
+		// Requeue in order to detect when the mismatch is fixed.
+		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	case err != nil:
+		// The API server might be temporarily unreachable, keep the condition as is.
+		log.V(4).Info("Unable to verify the certificate authority of the workload cluster API server", "err", err.Error())
+		return ctrl.Result{}, nil
+	}
+
+	conditions.MarkTrue(cluster, clusterv1.ControlPlaneEndpointTrustedCondition)
+	return ctrl.Result{}, nil
+}
@@ -17,6 +17,12 @@ limitations under the License.
 package controllers
 
 import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
 	"testing"
 	"time"
 
@@ -27,9 +33,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
@@ -382,3 +393,121 @@ func TestClusterReconciler_reconcilePhase(t *testing.T) {
 		})
 	}
 }
+
+func TestClusterReconciler_reconcileControlPlaneEndpointTrust(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	serverURL, err := url.Parse(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, err := strconv.Atoi(serverURL.Port())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rotatedCA := &secret.Certificate{Purpose: secret.ClusterCA}
+	if err := rotatedCA.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	newCluster := func() *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "test-namespace",
+			},
+			Spec: clusterv1.ClusterSpec{
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{
+					Host: serverURL.Hostname(),
+					Port: int32(port),
+				},
+			},
+			Status: clusterv1.ClusterStatus{
+				ControlPlaneInitialized: true,
+			},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		caData          []byte
+		expectTrusted   bool
+		expectEvent     bool
+		expectRequeue   bool
+		expectCondition bool
+	}{
+		{
+			name:            "marks the endpoint as trusted if the served certificate is signed by the kubeconfig CA",
+			caData:          certs.EncodeCertPEM(server.Certificate()),
+			expectTrusted:   true,
+			expectCondition: true,
+		},
+		{
+			name:            "marks the endpoint as not trusted and emits an event if the CA has been rotated",
+			caData:          rotatedCA.KeyPair.Cert,
+			expectTrusted:   false,
+			expectEvent:     true,
+			expectRequeue:   true,
+			expectCondition: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := newCluster()
+			kubeconfigSecret := kubeconfig.GenerateSecret(cluster, []byte(fmt.Sprintf(`
+apiVersion: v1
+kind: Config
+clusters:
+- name: test-cluster
+  cluster:
+    server: %s
+    certificate-authority-data: %s
+contexts:
+- name: admin@test-cluster
+  context:
+    cluster: test-cluster
+    user: admin
+current-context: admin@test-cluster
+users:
+- name: admin
+`, server.URL, base64.StdEncoding.EncodeToString(tt.caData))))
+
+			recorder := record.NewFakeRecorder(10)
+			r := &ClusterReconciler{
+				Client:   fake.NewClientBuilder().WithObjects(kubeconfigSecret).Build(),
+				recorder: recorder,
+			}
+
+			res, err := r.reconcileControlPlaneEndpointTrust(ctx, cluster)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(res.RequeueAfter > 0).To(Equal(tt.expectRequeue))
+			g.Expect(conditions.Has(cluster, clusterv1.ControlPlaneEndpointTrustedCondition)).To(Equal(tt.expectCondition))
+			g.Expect(conditions.IsTrue(cluster, clusterv1.ControlPlaneEndpointTrustedCondition)).To(Equal(tt.expectTrusted))
+			if !tt.expectTrusted {
+				g.Expect(conditions.GetReason(cluster, clusterv1.ControlPlaneEndpointTrustedCondition)).To(Equal(clusterv1.CertificateAuthorityMismatchReason))
+			}
+			g.Expect(recorder.Events).To(HaveLen(map[bool]int{true: 1, false: 0}[tt.expectEvent]))
+		})
+	}
+
+	t.Run("does nothing if the control plane is not initialized", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cluster := newCluster()
+		cluster.Status.ControlPlaneInitialized = false
+
+		r := &ClusterReconciler{
+			Client: fake.NewClientBuilder().Build(),
+		}
+
+		res, err := r.reconcileControlPlaneEndpointTrust(ctx, cluster)
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(res).To(Equal(ctrl.Result{}))
+		g.Expect(conditions.Has(cluster, clusterv1.ControlPlaneEndpointTrustedCondition)).To(BeFalse())
+	})
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package controllers
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -622,6 +624,57 @@ func TestFilterOwnedDescendants(t *testing.T) {
 	g.Expect(actual).To(Equal(expected))
 }
 
+func TestDeletionBlockedMessage(t *testing.T) {
+	deletedAt := metav1.NewTime(time.Date(2021, 3, 1, 10, 0, 0, 0, time.UTC))
+
+	newMachine := func(name string, deletionTimestamp *metav1.Time, finalizers ...string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				DeletionTimestamp: deletionTimestamp,
+				Finalizers:        finalizers,
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		blockers []deletionBlocker
+		want     string
+	}{
+		{
+			name: "lists the objects with their finalizers and deletion timestamp",
+			blockers: []deletionBlocker{
+				{kind: "Machine", obj: newMachine("m1", &deletedAt, clusterv1.MachineFinalizer)},
+				{kind: "Machine", obj: newMachine("m2", &deletedAt)},
+				{kind: "Machine", obj: newMachine("m3", nil)},
+			},
+			want: "Machine/m1 deleted at 2021-03-01T10:00:00Z, waiting for finalizers [machine.cluster.x-k8s.io]; " +
+				"Machine/m2 deleted at 2021-03-01T10:00:00Z; Machine/m3 not yet deleted",
+		},
+		{
+			name: "caps the number of objects listed",
+			blockers: func() []deletionBlocker {
+				blockers := []deletionBlocker{}
+				for i := 0; i < maxDeletionBlockers+2; i++ {
+					blockers = append(blockers, deletionBlocker{kind: "Machine", obj: newMachine(fmt.Sprintf("m%d", i), nil)})
+				}
+				return blockers
+			}(),
+			want: "Machine/m0 not yet deleted; Machine/m1 not yet deleted; Machine/m2 not yet deleted; Machine/m3 not yet deleted; " +
+				"Machine/m4 not yet deleted; Machine/m5 not yet deleted; Machine/m6 not yet deleted; Machine/m7 not yet deleted; " +
+				"Machine/m8 not yet deleted; Machine/m9 not yet deleted; and 2 more",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(deletionBlockedMessage(tt.blockers)).To(Equal(tt.want))
+		})
+	}
+}
+
 func TestReconcileControlPlaneInitializedControlPlaneRef(t *testing.T) {
 	g := NewWithT(t)
 
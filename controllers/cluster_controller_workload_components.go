@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultWorkloadComponentsHealthCheckInterval is the default interval at which the components in the workload
+// cluster listed in the Cluster's WorkloadComponentHealthChecks are checked.
+const defaultWorkloadComponentsHealthCheckInterval = 5 * time.Minute
+
+// workloadComponentsChecks records when the workload cluster components of each Cluster were last checked, so that
+// they are checked at a low frequency no matter how often the Cluster is reconciled.
+type workloadComponentsChecks struct {
+	lock sync.Mutex
+	last map[types.NamespacedName]workloadComponentsCheck
+}
+
+type workloadComponentsCheck struct {
+	time       time.Time
+	generation int64
+}
+
+// due returns the time until the next check of the workload cluster components of a Cluster, or zero if the
+// components have to be checked now, i.e. they have never been checked, or the Cluster changed since the last check.
+func (c *workloadComponentsChecks) due(cluster *clusterv1.Cluster, interval time.Duration, now time.Time) time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	last, ok := c.last[util.ObjectKey(cluster)]
+	if !ok || last.generation != cluster.Generation {
+		return 0
+	}
+	if elapsed := now.Sub(last.time); elapsed < interval {
+		return interval - elapsed
+	}
+	return 0
+}
+
+func (c *workloadComponentsChecks) record(cluster *clusterv1.Cluster, now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.last == nil {
+		c.last = map[types.NamespacedName]workloadComponentsCheck{}
+	}
+	c.last[util.ObjectKey(cluster)] = workloadComponentsCheck{time: now, generation: cluster.Generation}
+}
+
+func (c *workloadComponentsChecks) forget(cluster *clusterv1.Cluster) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.last, util.ObjectKey(cluster))
+}
+
+// reconcileWorkloadComponentsHealth checks the components in the workload cluster listed in the Cluster's
+// WorkloadComponentHealthChecks, e.g. the CNI or the CSI driver, and summarizes their health in the
+// WorkloadComponentsHealthy condition. The components are read through the ClusterCacheTracker, bypassing its cache
+// so that no informer is started in the workload cluster, at the WorkloadComponentsHealthCheckInterval.
+func (r *ClusterReconciler) reconcileWorkloadComponentsHealth(ctx context.Context, cluster *clusterv1.Cluster) (ctrl.Result, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if len(cluster.Spec.WorkloadComponentHealthChecks) == 0 {
+		conditions.Delete(cluster, clusterv1.WorkloadComponentsHealthyCondition)
+		r.workloadComponentsChecks.forget(cluster)
+		return ctrl.Result{}, nil
+	}
+	if !cluster.Status.ControlPlaneInitialized || r.Tracker == nil {
+		return ctrl.Result{}, nil
+	}
+
+	interval := r.WorkloadComponentsHealthCheckInterval
+	if interval <= 0 {
+		interval = defaultWorkloadComponentsHealthCheckInterval
+	}
+	now := time.Now()
+	if wait := r.workloadComponentsChecks.due(cluster, interval, now); wait > 0 && conditions.Has(cluster, clusterv1.WorkloadComponentsHealthyCondition) {
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+	r.workloadComponentsChecks.record(cluster, now)
+
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		log.V(4).Info("Unable to get a client for the workload cluster, skipping the workload components health checks", "err", err.Error())
+		conditions.MarkFalse(cluster, clusterv1.WorkloadComponentsHealthyCondition, clusterv1.WorkloadComponentsCheckFailedReason, clusterv1.ConditionSeverityWarning,
+			"Unable to connect to the workload cluster")
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	var unhealthy []string
+	for _, check := range cluster.Spec.WorkloadComponentHealthChecks {
+		message, err := checkWorkloadComponent(ctx, remoteClient, check)
+		if err != nil {
+			log.V(4).Info("Unable to check a workload component", "component", workloadComponentName(check), "err", err.Error())
+			conditions.MarkFalse(cluster, clusterv1.WorkloadComponentsHealthyCondition, clusterv1.WorkloadComponentsCheckFailedReason, clusterv1.ConditionSeverityWarning,
+				"Unable to check %s", workloadComponentName(check))
+			return ctrl.Result{RequeueAfter: interval}, nil
+		}
+		if message != "" {
+			unhealthy = append(unhealthy, fmt.Sprintf("%s %s", workloadComponentName(check), message))
+		}
+	}
+
+	if len(unhealthy) > 0 {
+		conditions.MarkFalse(cluster, clusterv1.WorkloadComponentsHealthyCondition, clusterv1.WorkloadComponentsUnhealthyReason, clusterv1.ConditionSeverityWarning,
+			"%s", strings.Join(unhealthy, "; "))
+		return ctrl.Result{RequeueAfter: interval}, nil
+	}
+
+	conditions.MarkTrue(cluster, clusterv1.WorkloadComponentsHealthyCondition)
+	return ctrl.Result{RequeueAfter: interval}, nil
+}
+
+// checkWorkloadComponent reads a component in the workload cluster, and returns a message describing why it is not
+// healthy, or an empty message if it is healthy. The component is read as unstructured, so that the read bypasses
+// the cache of the ClusterCacheTracker.
+func checkWorkloadComponent(ctx context.Context, c client.Client, check clusterv1.WorkloadComponentHealthCheck) (string, error) {
+	gv, err := schema.ParseGroupVersion(check.APIVersion)
+	if err != nil {
+		return "", err
+	}
+
+	obj := &unstructured.Unstructured{}
+	obj.SetGroupVersionKind(gv.WithKind(check.Kind))
+	if err := c.Get(ctx, client.ObjectKey{Namespace: check.Namespace, Name: check.Name}, obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "not found", nil
+		}
+		return "", err
+	}
+	return workloadComponentUnhealthyMessage(obj), nil
+}
+
+// workloadComponentUnhealthyMessage returns a message describing why a component is not healthy, or an empty
+// message if it is healthy.
+// DaemonSets are healthy when all their scheduled Pods are ready, Deployments and StatefulSets when all their replicas
+// are ready; objects of other kinds are healthy when their Ready or Available condition, if any, is true.
+func workloadComponentUnhealthyMessage(obj *unstructured.Unstructured) string {
+	if obj.GroupVersionKind().Group == "apps" {
+		switch obj.GetKind() {
+		case "DaemonSet":
+			desired, _, _ := unstructured.NestedInt64(obj.Object, "status", "desiredNumberScheduled")
+			ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "numberReady")
+			if ready < desired {
+				return fmt.Sprintf("has %d of %d Pods ready", ready, desired)
+			}
+			return ""
+		case "Deployment", "StatefulSet":
+			replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+			if !found {
+				replicas = 1
+			}
+			ready, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+			if ready < replicas {
+				return fmt.Sprintf("has %d of %d replicas ready", ready, replicas)
+			}
+			return ""
+		}
+	}
+
+	conds, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, t := range []string{"Ready", "Available"} {
+		for _, c := range conds {
+			cond, ok := c.(map[string]interface{})
+			if !ok || cond["type"] != t {
+				continue
+			}
+			if cond["status"] != "True" {
+				return fmt.Sprintf("has condition %s=%v: %v", t, cond["status"], cond["message"])
+			}
+			return ""
+		}
+	}
+	return ""
+}
+
+// workloadComponentName returns the name of a component in the workload cluster, as reported in the condition message.
+func workloadComponentName(check clusterv1.WorkloadComponentHealthCheck) string {
+	if check.Namespace == "" {
+		return fmt.Sprintf("%s %s", check.Kind, check.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", check.Kind, check.Namespace, check.Name)
+}
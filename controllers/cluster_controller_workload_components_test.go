@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWorkloadComponentUnhealthyMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		obj  map[string]interface{}
+		want string
+	}{
+		{
+			name: "DaemonSet with all the scheduled Pods ready is healthy",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"status":     map[string]interface{}{"desiredNumberScheduled": int64(3), "numberReady": int64(3)},
+			},
+			want: "",
+		},
+		{
+			name: "DaemonSet with scheduled Pods not ready is unhealthy",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "DaemonSet",
+				"status":     map[string]interface{}{"desiredNumberScheduled": int64(3), "numberReady": int64(1)},
+			},
+			want: "has 1 of 3 Pods ready",
+		},
+		{
+			name: "Deployment with replicas not ready is unhealthy",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"spec":       map[string]interface{}{"replicas": int64(2)},
+				"status":     map[string]interface{}{"readyReplicas": int64(1)},
+			},
+			want: "has 1 of 2 replicas ready",
+		},
+		{
+			name: "StatefulSet without replicas defaults to one replica",
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "StatefulSet",
+				"status":     map[string]interface{}{"readyReplicas": int64(1)},
+			},
+			want: "",
+		},
+		{
+			name: "object with a false Ready condition is unhealthy",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "CSIDriverInstance",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Ready", "status": "False", "message": "controller crash looping"},
+					},
+				},
+			},
+			want: "has condition Ready=False: controller crash looping",
+		},
+		{
+			name: "object with a true Available condition is healthy",
+			obj: map[string]interface{}{
+				"apiVersion": "example.com/v1",
+				"kind":       "CSIDriverInstance",
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": "True"},
+					},
+				},
+			},
+			want: "",
+		},
+		{
+			name: "object without conditions is healthy",
+			obj: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+			},
+			want: "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(workloadComponentUnhealthyMessage(&unstructured.Unstructured{Object: tt.obj})).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestCheckWorkloadComponent(t *testing.T) {
+	g := NewWithT(t)
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "calico-node"},
+		Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberReady: 2},
+	}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "kube-system", Name: "coredns"},
+		Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32Ptr(2)},
+		Status:     appsv1.DeploymentStatus{ReadyReplicas: 2},
+	}
+	c := fake.NewClientBuilder().WithObjects(ds, deployment).Build()
+
+	message, err := checkWorkloadComponent(ctx, c, clusterv1.WorkloadComponentHealthCheck{APIVersion: "apps/v1", Kind: "DaemonSet", Namespace: "kube-system", Name: "calico-node"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("has 2 of 3 Pods ready"))
+
+	message, err = checkWorkloadComponent(ctx, c, clusterv1.WorkloadComponentHealthCheck{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "kube-system", Name: "coredns"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(BeEmpty())
+
+	message, err = checkWorkloadComponent(ctx, c, clusterv1.WorkloadComponentHealthCheck{APIVersion: "apps/v1", Kind: "DaemonSet", Namespace: "kube-system", Name: "csi-node"})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(message).To(Equal("not found"))
+}
+
+func TestWorkloadComponentsChecksDue(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test", Generation: 1}}
+	checks := workloadComponentsChecks{}
+	now := time.Now()
+
+	// Never checked.
+	g.Expect(checks.due(cluster, 5*time.Minute, now)).To(BeZero())
+
+	// Checked recently.
+	checks.record(cluster, now)
+	g.Expect(checks.due(cluster, 5*time.Minute, now.Add(time.Minute))).To(Equal(4 * time.Minute))
+
+	// Checked before the interval.
+	g.Expect(checks.due(cluster, 5*time.Minute, now.Add(6*time.Minute))).To(BeZero())
+
+	// Changed since the last check.
+	cluster.Generation = 2
+	g.Expect(checks.due(cluster, 5*time.Minute, now.Add(time.Minute))).To(BeZero())
+
+	// Forgotten.
+	cluster.Generation = 1
+	checks.forget(cluster)
+	g.Expect(checks.due(cluster, 5*time.Minute, now.Add(time.Minute))).To(BeZero())
+}
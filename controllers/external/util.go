@@ -19,7 +19,9 @@ package external
 import (
 	"context"
 	"strings"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -73,6 +75,10 @@ type CloneTemplateInput struct {
 	// Labels is an optional map of labels to be added to the object.
 	// +optional
 	Labels map[string]string
+
+	// MetadataPolicy is an optional policy controlling the labels and the annotations cloned from the template.
+	// +optional
+	MetadataPolicy *clusterv1.TemplateMetadataPolicy
 }
 
 // CloneTemplate uses the client and the reference to create a new object from the template.
@@ -82,12 +88,13 @@ func CloneTemplate(ctx context.Context, in *CloneTemplateInput) (*corev1.ObjectR
 		return nil, err
 	}
 	generateTemplateInput := &GenerateTemplateInput{
-		Template:    from,
-		TemplateRef: in.TemplateRef,
-		Namespace:   in.Namespace,
-		ClusterName: in.ClusterName,
-		OwnerRef:    in.OwnerRef,
-		Labels:      in.Labels,
+		Template:       from,
+		TemplateRef:    in.TemplateRef,
+		Namespace:      in.Namespace,
+		ClusterName:    in.ClusterName,
+		OwnerRef:       in.OwnerRef,
+		Labels:         in.Labels,
+		MetadataPolicy: in.MetadataPolicy,
 	}
 	to, err := GenerateTemplate(generateTemplateInput)
 	if err != nil {
@@ -127,6 +134,10 @@ type GenerateTemplateInput struct {
 	// Labels is an optional map of labels to be added to the object.
 	// +optional
 	Labels map[string]string
+
+	// MetadataPolicy is an optional policy controlling the labels and the annotations cloned from the template.
+	// +optional
+	MetadataPolicy *clusterv1.TemplateMetadataPolicy
 }
 
 func GenerateTemplate(in *GenerateTemplateInput) (*unstructured.Unstructured, error) {
@@ -146,19 +157,19 @@ func GenerateTemplate(in *GenerateTemplateInput) (*unstructured.Unstructured, er
 	to.SetName(names.SimpleNameGenerator.GenerateName(in.Template.GetName() + "-"))
 	to.SetNamespace(in.Namespace)
 
-	if to.GetAnnotations() == nil {
-		to.SetAnnotations(map[string]string{})
+	policy := in.MetadataPolicy
+	if policy == nil {
+		policy = &clusterv1.TemplateMetadataPolicy{}
 	}
-	annotations := to.GetAnnotations()
+
+	// Set annotations.
+	annotations := templateMetadata(to.GetAnnotations(), in.Template.GetAnnotations(), policy.PropagateAnnotations, policy.StripAnnotations)
 	annotations[clusterv1.TemplateClonedFromNameAnnotation] = in.TemplateRef.Name
 	annotations[clusterv1.TemplateClonedFromGroupKindAnnotation] = in.TemplateRef.GroupVersionKind().GroupKind().String()
 	to.SetAnnotations(annotations)
 
 	// Set labels.
-	labels := to.GetLabels()
-	if labels == nil {
-		labels = map[string]string{}
-	}
+	labels := templateMetadata(to.GetLabels(), in.Template.GetLabels(), policy.PropagateLabels, policy.StripLabels)
 	for key, value := range in.Labels {
 		labels[key] = value
 	}
@@ -182,6 +193,36 @@ func GenerateTemplate(in *GenerateTemplateInput) (*unstructured.Unstructured, er
 	return to, nil
 }
 
+// templateMetadata returns the labels or the annotations cloned from a template: the ones of the template spec, plus
+// the propagated ones of the template object, minus the stripped ones.
+func templateMetadata(specMetadata, templateObjectMetadata map[string]string, propagate, strip []string) map[string]string {
+	metadata := map[string]string{}
+	for key, value := range templateObjectMetadata {
+		if matchesMetadataKey(propagate, key) {
+			metadata[key] = value
+		}
+	}
+	for key, value := range specMetadata {
+		metadata[key] = value
+	}
+	for key := range metadata {
+		if matchesMetadataKey(strip, key) {
+			delete(metadata, key)
+		}
+	}
+	return metadata
+}
+
+// matchesMetadataKey returns true if the key is in the list, or has a prefix in the list ending with "/".
+func matchesMetadataKey(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key || (strings.HasSuffix(k, "/") && strings.HasPrefix(key, k)) {
+			return true
+		}
+	}
+	return false
+}
+
 // GetObjectReference converts an unstructured into object reference.
 func GetObjectReference(obj *unstructured.Unstructured) *corev1.ObjectReference {
 	return &corev1.ObjectReference{
@@ -208,6 +249,67 @@ func FailuresFrom(obj *unstructured.Unstructured) (string, string, error) {
 	return failureReason, failureMessage, nil
 }
 
+// FailureHintFrom returns the Status.FailureHint field from an external object, together with the
+// failure domain the hint applies to, as defined by the Spec.FailureDomain field.
+func FailureHintFrom(obj *unstructured.Unstructured) (string, string, error) {
+	failureHint, _, err := unstructured.NestedString(obj.Object, "status", "failureHint")
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to determine failureHint on %v %q",
+			obj.GroupVersionKind(), obj.GetName())
+	}
+	failureDomain, _, err := unstructured.NestedString(obj.Object, "spec", "failureDomain")
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to determine failureDomain on %v %q",
+			obj.GroupVersionKind(), obj.GetName())
+	}
+	return failureHint, failureDomain, nil
+}
+
+// InstanceStateFrom returns the Status.InstanceState field from an external object.
+func InstanceStateFrom(obj *unstructured.Unstructured) (string, error) {
+	instanceState, _, err := unstructured.NestedString(obj.Object, "status", "instanceState")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to determine instanceState on %v %q",
+			obj.GroupVersionKind(), obj.GetName())
+	}
+	return instanceState, nil
+}
+
+// BootstrapDiagnosticsFrom returns the Status.BootstrapDiagnostics field from an external object, i.e. an excerpt of
+// the logs of the bootstrap of the machine, e.g. its console or cloud-init logs.
+func BootstrapDiagnosticsFrom(obj *unstructured.Unstructured) (string, error) {
+	diagnostics, _, err := unstructured.NestedString(obj.Object, "status", "bootstrapDiagnostics")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to determine bootstrapDiagnostics on %v %q",
+			obj.GroupVersionKind(), obj.GetName())
+	}
+	return diagnostics, nil
+}
+
+// RequeueAfterFrom returns the Status.RequeueAfter field from an external object, i.e. the duration, e.g. "45s", after
+// which the provider expects the object to have progressed, like the ETA of a cloud operation; if the field is not set,
+// or it is not a valid non-negative duration, which is logged, defaultRequeueAfter is returned. Durations shorter than
+// a second are rounded up to a second, so that a provider can't make the controllers reconcile in a hot loop.
+func RequeueAfterFrom(log logr.Logger, obj *unstructured.Unstructured, defaultRequeueAfter time.Duration) time.Duration {
+	value, found, err := unstructured.NestedString(obj.Object, "status", "requeueAfter")
+	if err != nil {
+		log.Error(err, "Ignoring invalid requeueAfter, it must be a string", "kind", obj.GetKind(), "name", obj.GetName())
+		return defaultRequeueAfter
+	}
+	if !found || value == "" {
+		return defaultRequeueAfter
+	}
+	requeueAfter, err := time.ParseDuration(value)
+	if err != nil || requeueAfter < 0 {
+		log.Info("Ignoring invalid requeueAfter, it must be a non-negative duration, e.g. 45s", "kind", obj.GetKind(), "name", obj.GetName(), "requeueAfter", value)
+		return defaultRequeueAfter
+	}
+	if requeueAfter < time.Second {
+		requeueAfter = time.Second
+	}
+	return requeueAfter
+}
+
 // IsReady returns true if the Status.Ready field on an external object is true.
 func IsReady(obj *unstructured.Unstructured) (bool, error) {
 	ready, found, err := unstructured.NestedBool(obj.Object, "status", "ready")
@@ -18,6 +18,7 @@ package external
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -31,6 +32,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 var (
@@ -321,3 +323,141 @@ func TestCloneTemplateMissingSpecTemplate(t *testing.T) {
 	})
 	g.Expect(err).To(HaveOccurred())
 }
+
+func TestGenerateTemplateMetadataPolicy(t *testing.T) {
+	template := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "GreenTemplate",
+			"apiVersion": "green.io/v1",
+			"metadata": map[string]interface{}{
+				"name":      "greenTemplate",
+				"namespace": "test",
+				"labels": map[string]interface{}{
+					"team":                         "green",
+					"app.kubernetes.io/managed-by": "gitops",
+				},
+				"annotations": map[string]interface{}{
+					"cost-center":                  "42",
+					"gitops.example.com/sync-wave": "1",
+				},
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{
+							"tier": "worker",
+						},
+						"annotations": map[string]interface{}{
+							"gitops.example.com/tracking-id": "green",
+							"spec-annotation":                "value",
+						},
+					},
+				},
+			},
+		},
+	}
+	templateRef := &corev1.ObjectReference{
+		Kind:       "GreenTemplate",
+		APIVersion: "green.io/v1",
+		Name:       "greenTemplate",
+		Namespace:  "test",
+	}
+
+	tests := []struct {
+		name                string
+		policy              *clusterv1.TemplateMetadataPolicy
+		expectedLabels      map[string]string
+		expectedAnnotations map[string]string
+	}{
+		{
+			name:           "no policy",
+			expectedLabels: map[string]string{"tier": "worker"},
+			expectedAnnotations: map[string]string{
+				"gitops.example.com/tracking-id": "green",
+				"spec-annotation":                "value",
+			},
+		},
+		{
+			name: "propagate and strip",
+			policy: &clusterv1.TemplateMetadataPolicy{
+				PropagateLabels:      []string{"team", "app.kubernetes.io/managed-by"},
+				PropagateAnnotations: []string{"cost-center", "gitops.example.com/"},
+				StripLabels:          []string{"app.kubernetes.io/"},
+				StripAnnotations:     []string{"gitops.example.com/", clusterv1.TemplateClonedFromNameAnnotation},
+			},
+			expectedLabels: map[string]string{"tier": "worker", "team": "green"},
+			expectedAnnotations: map[string]string{
+				"cost-center":     "42",
+				"spec-annotation": "value",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			to, err := GenerateTemplate(&GenerateTemplateInput{
+				Template:       template.DeepCopy(),
+				TemplateRef:    templateRef,
+				Namespace:      "test",
+				ClusterName:    "test-cluster",
+				MetadataPolicy: tt.policy,
+			})
+			g.Expect(err).NotTo(HaveOccurred())
+
+			// The metadata set by Cluster API is never stripped.
+			tt.expectedLabels[clusterv1.ClusterLabelName] = "test-cluster"
+			tt.expectedAnnotations[clusterv1.TemplateClonedFromNameAnnotation] = templateRef.Name
+			tt.expectedAnnotations[clusterv1.TemplateClonedFromGroupKindAnnotation] = templateRef.GroupVersionKind().GroupKind().String()
+			g.Expect(to.GetLabels()).To(Equal(tt.expectedLabels))
+			g.Expect(to.GetAnnotations()).To(Equal(tt.expectedAnnotations))
+		})
+	}
+}
+
+func TestRequeueAfterFrom(t *testing.T) {
+	tests := []struct {
+		name   string
+		status map[string]interface{}
+		want   time.Duration
+	}{
+		{
+			name:   "returns the default when not set",
+			status: map[string]interface{}{},
+			want:   30 * time.Second,
+		},
+		{
+			name:   "returns the reported duration",
+			status: map[string]interface{}{"requeueAfter": "2m30s"},
+			want:   150 * time.Second,
+		},
+		{
+			name:   "rounds up durations shorter than a second",
+			status: map[string]interface{}{"requeueAfter": "10ms"},
+			want:   time.Second,
+		},
+		{
+			name:   "returns the default with an invalid duration",
+			status: map[string]interface{}{"requeueAfter": "soon"},
+			want:   30 * time.Second,
+		},
+		{
+			name:   "returns the default with a negative duration",
+			status: map[string]interface{}{"requeueAfter": "-1m"},
+			want:   30 * time.Second,
+		},
+		{
+			name:   "returns the default with a value which is not a string",
+			status: map[string]interface{}{"requeueAfter": int64(45)},
+			want:   30 * time.Second,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{"status": tt.status}}
+			g.Expect(RequeueAfterFrom(log.NullLogger{}, obj, 30*time.Second)).To(Equal(tt.want))
+		})
+	}
+}
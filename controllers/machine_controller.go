@@ -19,6 +19,8 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -40,6 +42,8 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/defaulting"
+	"sigs.k8s.io/cluster-api/util/operations"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -54,6 +58,10 @@ import (
 const (
 	// MachineControllerName defines the controller used when creating clients
 	MachineControllerName = "machine-controller"
+
+	// defaultNodeDeletionTimeout is the amount of time spent on deleting the node of a Machine when
+	// the Machine's NodeDeletionTimeout is not set.
+	defaultNodeDeletionTimeout = 10 * time.Second
 )
 
 var (
@@ -66,6 +74,7 @@ var (
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
 // +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.cluster.x-k8s.io;bootstrap.cluster.x-k8s.io,resources=*,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch;create;update;patch;delete
@@ -77,10 +86,19 @@ type MachineReconciler struct {
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
+	// WebhooksDisabled applies the defaults and the validation of the admission webhooks to the Machines inside the
+	// controller, for management clusters where the webhooks are not installed.
+	WebhooksDisabled bool
+
+	// ResyncPeriod is the period after which Machines in a non-terminal phase, i.e. not Running, Failed or Deleted,
+	// are reconciled again; if zero, Machines are reconciled again only when the manager's SyncPeriod expires.
+	ResyncPeriod time.Duration
+
 	controller      controller.Controller
 	restConfig      *rest.Config
 	recorder        record.EventRecorder
 	externalTracker external.ObjectTracker
+	drainBudget     drainBudget
 }
 
 func (r *MachineReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -141,7 +159,7 @@ func (r *MachineReconciler) clusterToActiveMachines(a client.Object) []reconcile
 	return requests
 }
 
-func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (res ctrl.Result, reterr error) {
 	log := ctrl.LoggerFrom(ctx)
 
 	// Fetch the Machine instance
@@ -177,6 +195,7 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 
 	defer func() {
 		r.reconcilePhase(ctx, m)
+		res = withResync(res, reterr, r.ResyncPeriod, !isTerminalMachinePhase(m.Status.GetTypedPhase()))
 
 		// Always attempt to patch the object and status after each reconciliation.
 		// Patch ObservedGeneration only if the reconciliation completed successfully
@@ -189,6 +208,15 @@ func (r *MachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_
 		}
 	}()
 
+	// Apply the defaults and the validation of the webhooks, when they are not installed.
+	if r.WebhooksDisabled {
+		if err := defaulting.DefaultAndValidate(m); err != nil {
+			log.Error(err, "Invalid Machine, waiting for it to be fixed")
+			r.recorder.Eventf(m, corev1.EventTypeWarning, "InvalidObject", "%v", err)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Reconcile labels.
 	if m.Labels == nil {
 		m.Labels = make(map[string]string)
@@ -240,6 +268,7 @@ func patchMachine(ctx context.Context, patchHelper *patch.Helper, machine *clust
 			clusterv1.BootstrapReadyCondition,
 			clusterv1.InfrastructureReadyCondition,
 			clusterv1.DrainingSucceededCondition,
+			clusterv1.NodeShutdownSignaledCondition,
 			clusterv1.MachineHealthCheckSuccededCondition,
 			clusterv1.MachineOwnerRemediatedCondition,
 		}},
@@ -273,6 +302,7 @@ func (r *MachineReconciler) reconcile(ctx context.Context, cluster *clusterv1.Cl
 		r.reconcileInfrastructure,
 		r.reconcileNode,
 		r.reconcileInterruptibleNodeLabel,
+		r.reconcileBootstrapDiagnostics,
 	}
 
 	res := ctrl.Result{}
@@ -305,17 +335,54 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		}
 	}
 
+	// With the RetainNode deletion policy, the node is neither drained nor deleted.
+	if isDeleteNodeAllowed && m.Spec.DeletionPolicy == clusterv1.RetainNodeMachineDeletionPolicy {
+		// Record the event only once, when the node is retained for the first time.
+		if conditions.GetReason(m, clusterv1.DrainingSucceededCondition) != clusterv1.NodeRetainedReason {
+			r.recorder.Eventf(m, corev1.EventTypeNormal, "RetainedNode", "Retained Machine's node %q because of the %s deletion policy", m.Status.NodeRef.Name, clusterv1.RetainNodeMachineDeletionPolicy)
+		}
+		conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.NodeRetainedReason, clusterv1.ConditionSeverityInfo, "Node draining and deletion skipped because of the RetainNode deletion policy")
+		isDeleteNodeAllowed = false
+	}
+
 	if isDeleteNodeAllowed {
 		// pre-drain.delete lifecycle hook
-		// Return early without error, will requeue if/when the hook owner removes the annotation.
-		if annotations.HasWithPrefix(clusterv1.PreDrainDeleteHookAnnotationPrefix, m.ObjectMeta.Annotations) {
-			conditions.MarkFalse(m, clusterv1.PreDrainDeleteHookSucceededCondition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "")
-			return ctrl.Result{}, nil
+		// Return early without error, will requeue if/when the hook owner removes the annotation or the hook timeout expires.
+		if result, waiting := r.reconcileDeleteHook(m, clusterv1.PreDrainDeleteHookAnnotationPrefix, clusterv1.PreDrainDeleteHookSucceededCondition, m.Spec.PreDrainDeleteHookTimeout); waiting {
+			return result, nil
+		}
+
+		if isForceDeleteRequested(m) && r.isNodeDrainAllowed(m) {
+			if conditions.GetReason(m, clusterv1.DrainingSucceededCondition) != clusterv1.ForceDeleteRequestedReason {
+				r.recorder.Eventf(m, corev1.EventTypeWarning, "SkippedDrainNode", "Skipped draining Machine's node %q because the %s annotation is set", m.Status.NodeRef.Name, clusterv1.ForceDeleteAnnotation)
+			}
+			conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.ForceDeleteRequestedReason, clusterv1.ConditionSeverityWarning, "Node draining skipped because force delete has been requested")
+		}
+
+		// Wait for other Machines of the Cluster to complete draining, if the number of concurrent drains is limited.
+		// Return early without error, will requeue to check the drain budget again.
+		if !isForceDeleteRequested(m) && r.isNodeDrainAllowed(m) {
+			budgetAvailable, err := r.isDrainBudgetAvailable(ctx, cluster, m)
+			if err != nil {
+				return ctrl.Result{}, err
+			}
+			if !budgetAvailable {
+				log.Info("Waiting for other Machines of the Cluster to complete draining", "node", m.Status.NodeRef.Name, "annotation", clusterv1.MaxConcurrentDrainsAnnotation)
+				r.recorder.Eventf(m, corev1.EventTypeNormal, "WaitingForDrainBudget", "Waiting for other Machines of Cluster %q to complete draining before draining Machine's node %q", cluster.Name, m.Status.NodeRef.Name)
+				return ctrl.Result{RequeueAfter: drainBudgetRequeueAfter}, nil
+			}
+		}
+
+		// Signal the node shutdown to the applications running on the node, and wait for the grace period to expire before draining.
+		// Return early without error, will requeue when the grace period expires.
+		if !isForceDeleteRequested(m) && r.isNodeDrainAllowed(m) {
+			if result, waiting, err := r.reconcileNodeShutdownSignal(ctx, cluster, m); waiting || err != nil {
+				return result, err
+			}
 		}
-		conditions.MarkTrue(m, clusterv1.PreDrainDeleteHookSucceededCondition)
 
 		// Drain node before deletion and issue a patch in order to make this operation visible to the users.
-		if r.isNodeDrainAllowed(m) {
+		if !isForceDeleteRequested(m) && r.isNodeDrainAllowed(m) {
 			patchHelper, err := patch.NewHelper(m, r.Client)
 			if err != nil {
 				return ctrl.Result{}, err
@@ -327,6 +394,7 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 			// This `if` condition prevents the transition time to be changed more than once.
 			if conditions.Get(m, clusterv1.DrainingSucceededCondition) == nil {
 				conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "Draining the node before deletion")
+				operations.Started(m, "Drain node", "Draining node %q", m.Status.NodeRef.Name)
 			}
 
 			if err := patchMachine(ctx, patchHelper, m); err != nil {
@@ -336,23 +404,24 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 			if result, err := r.drainNode(ctx, cluster, m.Status.NodeRef.Name); !result.IsZero() || err != nil {
 				if err != nil {
 					conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+					operations.Failed(m, "Drain node", "Error draining node %q: %v", m.Status.NodeRef.Name, err)
 					r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDrainNode", "error draining Machine's node %q: %v", m.Status.NodeRef.Name, err)
 				}
 				return result, err
 			}
 
 			conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
+			r.drainBudget.release(util.ObjectKey(cluster), m.Name)
+			operations.Succeeded(m, "Drain node", "Drained node %q", m.Status.NodeRef.Name)
 			r.recorder.Eventf(m, corev1.EventTypeNormal, "SuccessfulDrainNode", "success draining Machine's node %q", m.Status.NodeRef.Name)
 		}
 	}
 
 	// pre-term.delete lifecycle hook
-	// Return early without error, will requeue if/when the hook owner removes the annotation.
-	if annotations.HasWithPrefix(clusterv1.PreTerminateDeleteHookAnnotationPrefix, m.ObjectMeta.Annotations) {
-		conditions.MarkFalse(m, clusterv1.PreTerminateDeleteHookSucceededCondition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "")
-		return ctrl.Result{}, nil
+	// Return early without error, will requeue if/when the hook owner removes the annotation or the hook timeout expires.
+	if result, waiting := r.reconcileDeleteHook(m, clusterv1.PreTerminateDeleteHookAnnotationPrefix, clusterv1.PreTerminateDeleteHookSucceededCondition, m.Spec.PreTerminateDeleteHookTimeout); waiting {
+		return result, nil
 	}
-	conditions.MarkTrue(m, clusterv1.PreTerminateDeleteHookSucceededCondition)
 
 	// Return early and don't remove the finalizer if we got an error or
 	// the external reconciliation deletion isn't ready.
@@ -367,6 +436,12 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		return ctrl.Result{}, errors.Wrap(err, "failed to patch Machine")
 	}
 
+	// Collect the bootstrap diagnostics before the provider references are deleted, given that Machines whose node
+	// did not start up in time are usually deleted by the remediation right after being marked.
+	if _, err := r.reconcileBootstrapDiagnostics(ctx, cluster, m); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	if ok, err := r.reconcileDeleteInfrastructure(ctx, m); !ok || err != nil {
 		return ctrl.Result{}, err
 	}
@@ -375,22 +450,35 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 		return ctrl.Result{}, err
 	}
 
+	// The node lifecycle of the cluster is managed externally, which is responsible for deleting the node.
+	if isDeleteNodeAllowed && isNodeDeletionSkipped(cluster) {
+		log.Info("Skipping node deletion because the node lifecycle is managed externally", "node", m.Status.NodeRef.Name, "annotation", clusterv1.SkipNodeDeletionAnnotation)
+		isDeleteNodeAllowed = false
+	}
+
 	// We only delete the node after the underlying infrastructure is gone.
 	// https://github.com/kubernetes-sigs/cluster-api/issues/2565
 	if isDeleteNodeAllowed {
 		log.Info("Deleting node", "node", m.Status.NodeRef.Name)
 
 		var deleteNodeErr error
-		waitErr := wait.PollImmediate(2*time.Second, 10*time.Second, func() (bool, error) {
+		waitErr := wait.PollImmediate(2*time.Second, nodeDeletionPollTimeout(m), func() (bool, error) {
 			if deleteNodeErr = r.deleteNode(ctx, cluster, m.Status.NodeRef.Name); deleteNodeErr != nil && !apierrors.IsNotFound(errors.Cause(deleteNodeErr)) {
 				return false, nil
 			}
 			return true, nil
 		})
+		if waitErr != nil && !nodeDeletionTimeoutExceeded(m) {
+			log.Error(deleteNodeErr, "Failed to delete node, retrying", "node", m.Status.NodeRef.Name)
+			return ctrl.Result{}, errors.Wrapf(deleteNodeErr, "failed to delete node %q", m.Status.NodeRef.Name)
+		}
 		if waitErr != nil {
 			log.Error(deleteNodeErr, "Timed out deleting node, moving on", "node", m.Status.NodeRef.Name)
 			conditions.MarkFalse(m, clusterv1.MachineNodeHealthyCondition, clusterv1.DeletionFailedReason, clusterv1.ConditionSeverityWarning, "")
+			operations.Failed(m, "Delete node", "Error deleting node %q: %v", m.Status.NodeRef.Name, deleteNodeErr)
 			r.recorder.Eventf(m, corev1.EventTypeWarning, "FailedDeleteNode", "error deleting Machine's node: %v", deleteNodeErr)
+		} else {
+			operations.Succeeded(m, "Delete node", "Deleted node %q", m.Status.NodeRef.Name)
 		}
 	}
 
@@ -398,6 +486,143 @@ func (r *MachineReconciler) reconcileDelete(ctx context.Context, cluster *cluste
 	return ctrl.Result{}, nil
 }
 
+// reconcileDeleteHook checks the lifecycle hooks with the given annotation prefix, and reports them using the given condition.
+// It returns true if deletion must wait for the hooks to be removed; hooks are instead skipped, recording an event, if
+// the Machine has been waiting for them longer than timeout or if force delete has been requested.
+func (r *MachineReconciler) reconcileDeleteHook(m *clusterv1.Machine, prefix string, condition clusterv1.ConditionType, timeout *metav1.Duration) (ctrl.Result, bool) {
+	if !annotations.HasWithPrefix(prefix, m.ObjectMeta.Annotations) {
+		conditions.MarkTrue(m, condition)
+		return ctrl.Result{}, false
+	}
+
+	skipReason := ""
+	switch {
+	case isForceDeleteRequested(m):
+		skipReason = clusterv1.ForceDeleteRequestedReason
+	case deleteHookTimeoutExceeded(m, condition, timeout):
+		skipReason = clusterv1.ExternalHookTimeoutExceededReason
+	}
+
+	if skipReason == "" {
+		conditions.MarkFalse(m, condition, clusterv1.WaitingExternalHookReason, clusterv1.ConditionSeverityInfo, "")
+		// Requeue when the timeout expires, given that no event is expected when hooks are not removed.
+		if timeout != nil && timeout.Seconds() > 0 {
+			waitStart := conditions.GetLastTransitionTime(m, condition)
+			return ctrl.Result{RequeueAfter: time.Until(waitStart.Add(timeout.Duration)) + time.Second}, true
+		}
+		return ctrl.Result{}, true
+	}
+
+	// Record the event only once, when the hooks are skipped for the first time.
+	if conditions.GetReason(m, condition) != skipReason {
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "SkippedDeleteHooks", "Skipped %s lifecycle hooks %v: %s", prefix, deleteHookNames(prefix, m.ObjectMeta.Annotations), skipReason)
+	}
+	conditions.MarkFalse(m, condition, skipReason, clusterv1.ConditionSeverityWarning, "Lifecycle hooks skipped")
+	return ctrl.Result{}, false
+}
+
+// isForceDeleteRequested returns true if the force-delete annotation is set on the Machine.
+func isForceDeleteRequested(m *clusterv1.Machine) bool {
+	_, exists := m.ObjectMeta.Annotations[clusterv1.ForceDeleteAnnotation]
+	return exists
+}
+
+// deleteHookTimeoutExceeded returns true if the Machine has been waiting for the lifecycle hooks
+// reported by the given condition for longer than timeout.
+func deleteHookTimeoutExceeded(m *clusterv1.Machine, condition clusterv1.ConditionType, timeout *metav1.Duration) bool {
+	if timeout == nil || timeout.Seconds() <= 0 {
+		return false
+	}
+
+	// The condition is set to false when the Machine starts waiting for the hooks for the first time,
+	// so its transition time can be used to determine how long the Machine has been waiting.
+	if !conditions.IsFalse(m, condition) {
+		return false
+	}
+	waitStart := conditions.GetLastTransitionTime(m, condition)
+	return time.Since(waitStart.Time) >= timeout.Duration
+}
+
+// deleteHookNames returns the sorted list of the lifecycle hook annotations with the given prefix.
+func deleteHookNames(prefix string, annotations map[string]string) []string {
+	names := []string{}
+	for k := range annotations {
+		if strings.HasPrefix(k, prefix) {
+			names = append(names, k)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// reconcileNodeShutdownSignal signals the upcoming shutdown of the Machine's node to the applications running on it,
+// by setting the shutdown-requested annotation on the node, and reports it using the NodeShutdownSignaled condition.
+// It returns true if draining must wait for the Machine's NodeShutdownGracePeriod to expire.
+func (r *MachineReconciler) reconcileNodeShutdownSignal(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, bool, error) {
+	gracePeriod := m.Spec.NodeShutdownGracePeriod
+	if gracePeriod == nil || gracePeriod.Duration <= 0 || conditions.IsTrue(m, clusterv1.NodeShutdownSignaledCondition) {
+		return ctrl.Result{}, false, nil
+	}
+
+	// The condition is set to false when the node shutdown is signaled, so its transition time can be used to
+	// determine how long the Machine has been waiting.
+	if !conditions.IsFalse(m, clusterv1.NodeShutdownSignaledCondition) {
+		deadline := time.Now().Add(gracePeriod.Duration)
+		signaled, err := r.annotateNodeShutdownRequested(ctx, cluster, m.Status.NodeRef.Name, deadline)
+		if err != nil {
+			return ctrl.Result{}, false, err
+		}
+		if !signaled {
+			conditions.MarkTrue(m, clusterv1.NodeShutdownSignaledCondition)
+			return ctrl.Result{}, false, nil
+		}
+		conditions.MarkFalse(m, clusterv1.NodeShutdownSignaledCondition, clusterv1.WaitingForNodeShutdownGracePeriodReason, clusterv1.ConditionSeverityInfo,
+			"Waiting for the applications on the node to shut down until %s", deadline.UTC().Format(time.RFC3339))
+		r.recorder.Eventf(m, corev1.EventTypeNormal, "SignaledNodeShutdown", "Signaled the shutdown of Machine's node %q, draining in %s", m.Status.NodeRef.Name, gracePeriod.Duration)
+		return ctrl.Result{RequeueAfter: gracePeriod.Duration}, true, nil
+	}
+
+	waitStart := conditions.GetLastTransitionTime(m, clusterv1.NodeShutdownSignaledCondition)
+	if remaining := time.Until(waitStart.Add(gracePeriod.Duration)); remaining > 0 {
+		return ctrl.Result{RequeueAfter: remaining + time.Second}, true, nil
+	}
+
+	conditions.MarkTrue(m, clusterv1.NodeShutdownSignaledCondition)
+	return ctrl.Result{}, false, nil
+}
+
+// annotateNodeShutdownRequested sets the shutdown-requested annotation, with the given deadline, on the node.
+// It returns false if the node can't be signaled, e.g. because it has already been deleted.
+func (r *MachineReconciler) annotateNodeShutdownRequested(ctx context.Context, cluster *clusterv1.Cluster, nodeName string, deadline time.Time) (bool, error) {
+	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name, "node", nodeName)
+
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		log.Error(err, "Error creating a remote client while deleting Machine, skipping node shutdown signal")
+		return false, nil
+	}
+
+	node := &corev1.Node{}
+	if err := remoteClient.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		if apierrors.IsNotFound(err) {
+			// If an admin deletes the node directly, there are no applications to signal.
+			log.Info("Could not find node from noderef, it may have already been deleted")
+			return false, nil
+		}
+		return false, errors.Wrapf(err, "failed to get node %q", nodeName)
+	}
+
+	nodePatch := client.MergeFrom(node.DeepCopy())
+	if node.Annotations == nil {
+		node.Annotations = map[string]string{}
+	}
+	node.Annotations[clusterv1.NodeShutdownRequestedAnnotation] = deadline.UTC().Format(time.RFC3339)
+	if err := remoteClient.Patch(ctx, node, nodePatch); err != nil {
+		return false, errors.Wrapf(err, "failed to set the %s annotation on node %q", clusterv1.NodeShutdownRequestedAnnotation, nodeName)
+	}
+	return true, nil
+}
+
 func (r *MachineReconciler) isNodeDrainAllowed(m *clusterv1.Machine) bool {
 	if _, exists := m.ObjectMeta.Annotations[clusterv1.ExcludeNodeDrainingAnnotation]; exists {
 		return false
@@ -428,6 +653,45 @@ func (r *MachineReconciler) nodeDrainTimeoutExceeded(machine *clusterv1.Machine)
 	return diff.Seconds() >= machine.Spec.NodeDrainTimeout.Seconds()
 }
 
+// nodeDeletionTimeout returns the total amount of time to spend on deleting the node of the Machine;
+// 0 means without any time limitations.
+func nodeDeletionTimeout(m *clusterv1.Machine) time.Duration {
+	if m.Spec.NodeDeletionTimeout == nil {
+		return defaultNodeDeletionTimeout
+	}
+	return m.Spec.NodeDeletionTimeout.Duration
+}
+
+// nodeDeletionPollTimeout returns how long to retry the node deletion within a single reconcile.
+func nodeDeletionPollTimeout(m *clusterv1.Machine) time.Duration {
+	if timeout := nodeDeletionTimeout(m); timeout > 0 && timeout < defaultNodeDeletionTimeout {
+		return timeout
+	}
+	return defaultNodeDeletionTimeout
+}
+
+// nodeDeletionTimeoutExceeded returns true if the controller has been trying to delete the node of the Machine
+// for longer than its NodeDeletionTimeout, measured from when the infrastructure of the Machine has been deleted.
+func nodeDeletionTimeoutExceeded(m *clusterv1.Machine) bool {
+	timeout := nodeDeletionTimeout(m)
+	if timeout <= 0 {
+		return false
+	}
+	// The node is deleted only after the infrastructure is gone, which is when the InfrastructureReadyCondition
+	// transitions to the Deleted reason, so its transition time records the first attempt to delete the node.
+	infraReady := conditions.Get(m, clusterv1.InfrastructureReadyCondition)
+	if infraReady == nil || infraReady.Reason != clusterv1.DeletedReason {
+		return false
+	}
+	return time.Since(infraReady.LastTransitionTime.Time) >= timeout
+}
+
+// isNodeDeletionSkipped returns true if the SkipNodeDeletionAnnotation is set on the Cluster.
+func isNodeDeletionSkipped(cluster *clusterv1.Cluster) bool {
+	_, exists := cluster.Annotations[clusterv1.SkipNodeDeletionAnnotation]
+	return exists
+}
+
 // isDeleteNodeAllowed returns nil only if the Machine's NodeRef is not nil
 // and if the Machine is not the last control plane node in the cluster.
 func (r *MachineReconciler) isDeleteNodeAllowed(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) error {
@@ -608,8 +872,10 @@ func (r *MachineReconciler) reconcileDeleteInfrastructure(ctx context.Context, m
 	if obj == nil {
 		// Marks the infrastructure as deleted
 		conditions.MarkFalse(m, clusterv1.InfrastructureReadyCondition, clusterv1.DeletedReason, clusterv1.ConditionSeverityInfo, "")
+		operations.Succeeded(m, "Delete infrastructure", "Deleted %s %q", m.Spec.InfrastructureRef.Kind, m.Spec.InfrastructureRef.Name)
 		return true, nil
 	}
+	operations.Started(m, "Delete infrastructure", "Deleting %s %q", obj.GetKind(), obj.GetName())
 
 	// Report a summary of current status of the bootstrap object defined for this machine.
 	conditions.SetMirror(m, clusterv1.InfrastructureReadyCondition,
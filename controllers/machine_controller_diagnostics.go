@@ -0,0 +1,212 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// maxBootstrapDiagnosticsLength is the maximum length of the bootstrap diagnostics of each provider reference
+	// stored in the diagnostics ConfigMap of a Machine; longer diagnostics are truncated, keeping their end.
+	maxBootstrapDiagnosticsLength = 64 * 1024
+
+	// bootstrapDiagnosticsInfrastructureKey is the key of the diagnostics ConfigMap holding the bootstrap
+	// diagnostics reported by the infrastructure provider reference.
+	bootstrapDiagnosticsInfrastructureKey = "infrastructure"
+
+	// bootstrapDiagnosticsBootstrapKey is the key of the diagnostics ConfigMap holding the bootstrap
+	// diagnostics reported by the bootstrap provider reference.
+	bootstrapDiagnosticsBootstrapKey = "bootstrap"
+
+	// bootstrapDiagnosticsLabelName is the label set on the diagnostics ConfigMaps, with the name of the Machine
+	// as value.
+	bootstrapDiagnosticsLabelName = "machine.cluster.x-k8s.io/bootstrap-diagnostics"
+
+	// maxBootstrapDiagnosticsConfigMaps is the maximum number of diagnostics ConfigMaps kept for each Cluster; when
+	// a new one is created, the oldest ones whose Machine has been deleted are removed.
+	maxBootstrapDiagnosticsConfigMaps = 10
+)
+
+// reconcileBootstrapDiagnostics collects the bootstrap diagnostics reported by the provider references of a Machine
+// whose node did not start up within the node startup timeout of a MachineHealthCheck into a ConfigMap, so that join
+// failures can be debugged from the management cluster. The ConfigMap is owned by the Cluster, so it outlives the
+// remediation of the Machine; it is called also while the Machine is being deleted, before the provider references
+// are deleted, given that remediation usually deletes the Machine right after it is marked.
+func (r *MachineReconciler) reconcileBootstrapDiagnostics(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (ctrl.Result, error) {
+	if m.Status.NodeRef != nil ||
+		conditions.GetReason(m, clusterv1.MachineHealthCheckSuccededCondition) != clusterv1.NodeStartupTimeoutReason {
+		return ctrl.Result{}, nil
+	}
+
+	refs := map[string]*corev1.ObjectReference{
+		bootstrapDiagnosticsInfrastructureKey: &m.Spec.InfrastructureRef,
+		bootstrapDiagnosticsBootstrapKey:      m.Spec.Bootstrap.ConfigRef,
+	}
+	data := map[string]string{}
+	for key, ref := range refs {
+		if ref == nil {
+			continue
+		}
+		obj, err := external.Get(ctx, r.Client, ref, m.Namespace)
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			continue
+		}
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		diagnostics, err := external.BootstrapDiagnosticsFrom(obj)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if diagnostics != "" {
+			data[key] = truncateBootstrapDiagnostics(diagnostics)
+		}
+	}
+	if len(data) == 0 {
+		return ctrl.Result{}, nil
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-bootstrap-diagnostics", m.Name),
+			Namespace: m.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Labels == nil {
+			configMap.Labels = map[string]string{}
+		}
+		configMap.Labels[clusterv1.ClusterLabelName] = cluster.Name
+		configMap.Labels[bootstrapDiagnosticsLabelName] = m.Name
+		configMap.OwnerReferences = util.EnsureOwnerRef(configMap.OwnerReferences, metav1.OwnerReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "Cluster",
+			Name:       cluster.Name,
+			UID:        cluster.UID,
+		})
+		// The diagnostics are merged, so the ones collected before a provider reference was deleted are kept.
+		if configMap.Data == nil {
+			configMap.Data = map[string]string{}
+		}
+		for key, value := range data {
+			configMap.Data[key] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to write bootstrap diagnostics ConfigMap %q for Machine %q in namespace %q", configMap.Name, m.Name, m.Namespace)
+	}
+	if result == controllerutil.OperationResultCreated {
+		r.recorder.Eventf(m, corev1.EventTypeWarning, "BootstrapDiagnosticsCollected",
+			"Node did not start up in time, collected the bootstrap diagnostics in ConfigMap %q", configMap.Name)
+
+		if err := r.deleteOldBootstrapDiagnostics(ctx, cluster); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	m.Status.DiagnosticsRef = &corev1.ObjectReference{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Name:       configMap.Name,
+		Namespace:  configMap.Namespace,
+	}
+	return ctrl.Result{}, nil
+}
+
+// deleteOldBootstrapDiagnostics deletes the oldest diagnostics ConfigMaps of the Cluster whose Machine has been
+// deleted, keeping at most maxBootstrapDiagnosticsConfigMaps of them; otherwise the ConfigMaps of all the Machines
+// ever remediated would be kept until the Cluster is deleted.
+func (r *MachineReconciler) deleteOldBootstrapDiagnostics(ctx context.Context, cluster *clusterv1.Cluster) error {
+	selector := labels.SelectorFromSet(labels.Set{clusterv1.ClusterLabelName: cluster.Name})
+	requirement, err := labels.NewRequirement(bootstrapDiagnosticsLabelName, selection.Exists, nil)
+	if err != nil {
+		return err
+	}
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.Client.List(ctx, configMaps, client.InNamespace(cluster.Namespace), client.MatchingLabelsSelector{Selector: selector.Add(*requirement)}); err != nil {
+		return errors.Wrapf(err, "failed to list bootstrap diagnostics ConfigMaps for Cluster %q in namespace %q", cluster.Name, cluster.Namespace)
+	}
+	if len(configMaps.Items) <= maxBootstrapDiagnosticsConfigMaps {
+		return nil
+	}
+
+	// Sort the ConfigMaps from the newest to the oldest.
+	sort.Slice(configMaps.Items, func(i, j int) bool {
+		ti, tj := configMaps.Items[i].CreationTimestamp, configMaps.Items[j].CreationTimestamp
+		if !ti.Equal(&tj) {
+			return tj.Before(&ti)
+		}
+		return configMaps.Items[i].Name > configMaps.Items[j].Name
+	})
+
+	var errs []error
+	kept := 0
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		if kept < maxBootstrapDiagnosticsConfigMaps {
+			kept++
+			continue
+		}
+
+		machine := &clusterv1.Machine{}
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: configMap.Namespace, Name: configMap.Labels[bootstrapDiagnosticsLabelName]}, machine)
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			errs = append(errs, err)
+			continue
+		}
+		if err := r.Client.Delete(ctx, configMap); err != nil && !apierrors.IsNotFound(err) {
+			errs = append(errs, errors.Wrapf(err, "failed to delete bootstrap diagnostics ConfigMap %q in namespace %q", configMap.Name, configMap.Namespace))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// truncateBootstrapDiagnostics returns the end of the diagnostics, up to maxBootstrapDiagnosticsLength bytes,
+// without splitting a UTF-8 character.
+func truncateBootstrapDiagnostics(diagnostics string) string {
+	if len(diagnostics) <= maxBootstrapDiagnosticsLength {
+		return diagnostics
+	}
+	start := len(diagnostics) - maxBootstrapDiagnosticsLength
+	for start < len(diagnostics) && !utf8.RuneStart(diagnostics[start]) {
+		start++
+	}
+	return diagnostics[start:]
+}
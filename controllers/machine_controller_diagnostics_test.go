@@ -0,0 +1,258 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/test/helpers"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileBootstrapDiagnostics(t *testing.T) {
+	newInfraMachine := func(diagnostics string) *unstructured.Unstructured {
+		infraMachine := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-machine",
+					"namespace": "default",
+				},
+				"status": map[string]interface{}{
+					"ready": true,
+				},
+			},
+		}
+		if diagnostics != "" {
+			g := NewWithT(t)
+			g.Expect(unstructured.SetNestedField(infraMachine.Object, diagnostics, "status", "bootstrapDiagnostics")).To(Succeed())
+		}
+		return infraMachine
+	}
+	newMachine := func(reason string) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: "test-cluster",
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+					Kind:       "InfrastructureMachine",
+					Name:       "infra-machine",
+				},
+			},
+		}
+		if reason != "" {
+			conditions.MarkFalse(m, clusterv1.MachineHealthCheckSuccededCondition, reason, clusterv1.ConditionSeverityWarning, "")
+		}
+		return m
+	}
+
+	tests := []struct {
+		name        string
+		machine     *clusterv1.Machine
+		diagnostics string
+		want        string
+	}{
+		{
+			name:        "does not collect the diagnostics of healthy machines",
+			machine:     newMachine(""),
+			diagnostics: "cloud-init: running",
+		},
+		{
+			name:        "does not collect the diagnostics of machines unhealthy for other reasons",
+			machine:     newMachine(clusterv1.UnhealthyNodeConditionReason),
+			diagnostics: "cloud-init: running",
+		},
+		{
+			name:    "does not collect the diagnostics if the provider does not report them",
+			machine: newMachine(clusterv1.NodeStartupTimeoutReason),
+		},
+		{
+			name:        "collects the diagnostics of machines whose node did not start up in time",
+			machine:     newMachine(clusterv1.NodeStartupTimeoutReason),
+			diagnostics: "cloud-init: kubeadm join failed",
+			want:        "cloud-init: kubeadm join failed",
+		},
+		{
+			name: "collects the diagnostics of machines being deleted",
+			machine: func() *clusterv1.Machine {
+				m := newMachine(clusterv1.NodeStartupTimeoutReason)
+				m.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+				return m
+			}(),
+			diagnostics: "cloud-init: kubeadm join failed",
+			want:        "cloud-init: kubeadm join failed",
+		},
+		{
+			name:        "truncates the diagnostics, keeping their end",
+			machine:     newMachine(clusterv1.NodeStartupTimeoutReason),
+			diagnostics: strings.Repeat("a", maxBootstrapDiagnosticsLength) + "kubeadm join failed",
+			want:        strings.Repeat("a", maxBootstrapDiagnosticsLength-len("kubeadm join failed")) + "kubeadm join failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", UID: "uid"}}
+			c := helpers.NewFakeClientWithScheme(scheme.Scheme,
+				cluster,
+				tt.machine,
+				external.TestGenericInfrastructureCRD.DeepCopy(),
+				newInfraMachine(tt.diagnostics),
+			)
+			r := &MachineReconciler{Client: c, recorder: record.NewFakeRecorder(32)}
+
+			_, err := r.reconcileBootstrapDiagnostics(ctx, cluster, tt.machine)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			configMap := &corev1.ConfigMap{}
+			err = c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "machine-bootstrap-diagnostics"}, configMap)
+			if tt.want == "" {
+				g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				g.Expect(tt.machine.Status.DiagnosticsRef).To(BeNil())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(configMap.Data).To(Equal(map[string]string{bootstrapDiagnosticsInfrastructureKey: tt.want}))
+			g.Expect(configMap.OwnerReferences).To(HaveLen(1))
+			g.Expect(configMap.OwnerReferences[0].Name).To(Equal(cluster.Name))
+			g.Expect(tt.machine.Status.DiagnosticsRef).To(Equal(&corev1.ObjectReference{
+				APIVersion: "v1",
+				Kind:       "ConfigMap",
+				Name:       configMap.Name,
+				Namespace:  configMap.Namespace,
+			}))
+		})
+	}
+}
+
+func TestTruncateBootstrapDiagnostics(t *testing.T) {
+	g := NewWithT(t)
+
+	// A multi-byte character across the truncation boundary is dropped instead of being split.
+	diagnostics := "é" + strings.Repeat("a", maxBootstrapDiagnosticsLength-1)
+	g.Expect(truncateBootstrapDiagnostics(diagnostics)).To(Equal(strings.Repeat("a", maxBootstrapDiagnosticsLength-1)))
+	g.Expect(truncateBootstrapDiagnostics("short")).To(Equal("short"))
+}
+
+func TestReconcileBootstrapDiagnosticsKeepsDeletedReferences(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", UID: "uid"}}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine", Namespace: "default"},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfrastructureMachine",
+				Name:       "infra-machine",
+			},
+		},
+	}
+	conditions.MarkFalse(machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeStartupTimeoutReason, clusterv1.ConditionSeverityWarning, "")
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-bootstrap-diagnostics", Namespace: "default"},
+		Data:       map[string]string{bootstrapDiagnosticsInfrastructureKey: "cloud-init: kubeadm join failed"},
+	}
+
+	// The infrastructure machine has already been deleted.
+	c := helpers.NewFakeClientWithScheme(scheme.Scheme, cluster, machine, configMap)
+	r := &MachineReconciler{Client: c, recorder: record.NewFakeRecorder(32)}
+
+	_, err := r.reconcileBootstrapDiagnostics(ctx, cluster, machine)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "machine-bootstrap-diagnostics"}, configMap)).To(Succeed())
+	g.Expect(configMap.Data).To(Equal(map[string]string{bootstrapDiagnosticsInfrastructureKey: "cloud-init: kubeadm join failed"}))
+}
+
+func TestDeleteOldBootstrapDiagnostics(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", UID: "uid"}}
+	objs := []client.Object{cluster}
+	now := time.Now()
+	for i := 0; i < maxBootstrapDiagnosticsConfigMaps+3; i++ {
+		machineName := fmt.Sprintf("machine-%d", i)
+		objs = append(objs, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              fmt.Sprintf("%s-bootstrap-diagnostics", machineName),
+				Namespace:         "default",
+				CreationTimestamp: metav1.NewTime(now.Add(time.Duration(i) * time.Minute)),
+				Labels: map[string]string{
+					clusterv1.ClusterLabelName:    cluster.Name,
+					bootstrapDiagnosticsLabelName: machineName,
+				},
+			},
+		})
+	}
+	// The Machine of the oldest ConfigMap still exists.
+	objs = append(objs, &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-0", Namespace: "default"}})
+	// ConfigMaps of other Clusters are ignored.
+	objs = append(objs, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "other-bootstrap-diagnostics",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:    "other-cluster",
+				bootstrapDiagnosticsLabelName: "other",
+			},
+		},
+	})
+
+	c := helpers.NewFakeClientWithScheme(scheme.Scheme, objs...)
+	r := &MachineReconciler{Client: c, recorder: record.NewFakeRecorder(32)}
+	g.Expect(r.deleteOldBootstrapDiagnostics(ctx, cluster)).To(Succeed())
+
+	configMaps := &corev1.ConfigMapList{}
+	g.Expect(c.List(ctx, configMaps)).To(Succeed())
+	var names []string
+	for _, configMap := range configMaps.Items {
+		names = append(names, configMap.Name)
+	}
+	g.Expect(names).To(ConsistOf(
+		"machine-0-bootstrap-diagnostics",
+		"machine-3-bootstrap-diagnostics",
+		"machine-4-bootstrap-diagnostics",
+		"machine-5-bootstrap-diagnostics",
+		"machine-6-bootstrap-diagnostics",
+		"machine-7-bootstrap-diagnostics",
+		"machine-8-bootstrap-diagnostics",
+		"machine-9-bootstrap-diagnostics",
+		"machine-10-bootstrap-diagnostics",
+		"machine-11-bootstrap-diagnostics",
+		"machine-12-bootstrap-diagnostics",
+		"other-bootstrap-diagnostics",
+	))
+}
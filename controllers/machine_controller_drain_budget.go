@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// drainBudgetRequeueAfter is the interval at which Machines waiting for the drain budget of their Cluster are
+// reconciled again.
+const drainBudgetRequeueAfter = 20 * time.Second
+
+// drainBudget coordinates the Machines whose Node is drained concurrently in each Cluster.
+type drainBudget struct {
+	lock sync.Mutex
+
+	// draining stores the names of the Machines of each Cluster which acquired the budget; it includes the Machines
+	// which started draining, but that are not yet observed as draining in the cache.
+	draining map[types.NamespacedName]sets.String
+}
+
+// acquire returns true if a Machine can drain its Node, i.e. it is already draining, or the number of draining
+// Machines of its Cluster is lower than max.
+func (b *drainBudget) acquire(cluster types.NamespacedName, machine string, machines []clusterv1.Machine, isDraining func(*clusterv1.Machine) bool, max int) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.draining == nil {
+		b.draining = map[types.NamespacedName]sets.String{}
+	}
+	draining, ok := b.draining[cluster]
+	if !ok {
+		draining = sets.NewString()
+		b.draining[cluster] = draining
+	}
+
+	// Forget the Machines which are gone, or which are observed as completed or stopped draining; the ones which
+	// acquired the budget but are not yet observed as draining are kept.
+	observed := sets.NewString()
+	existing := map[string]*clusterv1.Machine{}
+	for i := range machines {
+		m := &machines[i]
+		existing[m.Name] = m
+		if isDraining(m) {
+			observed.Insert(m.Name)
+		}
+	}
+	for _, name := range draining.List() {
+		m, ok := existing[name]
+		if !ok || m.DeletionTimestamp.IsZero() || (conditions.Has(m, clusterv1.DrainingSucceededCondition) && !observed.Has(name)) {
+			draining.Delete(name)
+		}
+	}
+
+	if !draining.Has(machine) && !observed.Has(machine) && draining.Union(observed).Len() >= max {
+		return false
+	}
+	draining.Insert(machine)
+	return true
+}
+
+// release forgets a Machine which completed draining.
+func (b *drainBudget) release(cluster types.NamespacedName, machine string) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if draining, ok := b.draining[cluster]; ok {
+		draining.Delete(machine)
+	}
+}
+
+// maxConcurrentDrains returns the maximum number of Machines of a Cluster draining concurrently, as defined by the
+// MaxConcurrentDrainsAnnotation, or 0 if the number is not limited.
+func maxConcurrentDrains(cluster *clusterv1.Cluster) (int, error) {
+	value, ok := cluster.Annotations[clusterv1.MaxConcurrentDrainsAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	max, err := strconv.Atoi(value)
+	if err != nil || max <= 0 {
+		return 0, errors.Errorf("invalid %s annotation %q: must be a positive integer", clusterv1.MaxConcurrentDrainsAnnotation, value)
+	}
+	return max, nil
+}
+
+// isDrainBudgetAvailable returns true if a Machine can drain its Node without exceeding the number of Machines
+// draining concurrently allowed in its Cluster.
+func (r *MachineReconciler) isDrainBudgetAvailable(ctx context.Context, cluster *clusterv1.Cluster, m *clusterv1.Machine) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	max, err := maxConcurrentDrains(cluster)
+	if err != nil {
+		// The annotation is validated by the webhooks, so an invalid value disables the budget instead of blocking the deletion.
+		log.Error(err, "Ignoring the drain budget of the Cluster")
+		return true, nil
+	}
+	if max == 0 {
+		return true, nil
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(cluster.Namespace), client.MatchingLabels{clusterv1.ClusterLabelName: cluster.Name}); err != nil {
+		return false, errors.Wrapf(err, "failed to list Machines of Cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	return r.drainBudget.acquire(util.ObjectKey(cluster), m.Name, machines.Items, r.isNodeDraining, max), nil
+}
+
+// isNodeDraining returns true if a Machine is draining its Node, i.e. it signaled the Node shutdown or it started
+// draining, and it did not complete, time out nor skip draining.
+func (r *MachineReconciler) isNodeDraining(m *clusterv1.Machine) bool {
+	if m.DeletionTimestamp.IsZero() || m.Status.NodeRef == nil || isForceDeleteRequested(m) || !r.isNodeDrainAllowed(m) {
+		return false
+	}
+	if conditions.IsFalse(m, clusterv1.NodeShutdownSignaledCondition) {
+		return true
+	}
+	if !conditions.IsFalse(m, clusterv1.DrainingSucceededCondition) {
+		return false
+	}
+	reason := conditions.GetReason(m, clusterv1.DrainingSucceededCondition)
+	return reason == clusterv1.DrainingReason || reason == clusterv1.DrainingFailedReason
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestMaxConcurrentDrains(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        int
+		wantErr     bool
+	}{
+		{
+			name: "not limited without the annotation",
+			want: 0,
+		},
+		{
+			name:        "limited by the annotation",
+			annotations: map[string]string{clusterv1.MaxConcurrentDrainsAnnotation: "2"},
+			want:        2,
+		},
+		{
+			name:        "fails when the annotation is not positive",
+			annotations: map[string]string{clusterv1.MaxConcurrentDrainsAnnotation: "0"},
+			wantErr:     true,
+		},
+		{
+			name:        "fails when the annotation is not an integer",
+			annotations: map[string]string{clusterv1.MaxConcurrentDrainsAnnotation: "two"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := maxConcurrentDrains(&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations}})
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestIsNodeDraining(t *testing.T) {
+	deleting := func(name string, setters ...func(m *clusterv1.Machine)) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: name},
+			},
+		}
+		for _, s := range setters {
+			s(m)
+		}
+		return m
+	}
+
+	tests := []struct {
+		name    string
+		machine *clusterv1.Machine
+		want    bool
+	}{
+		{
+			name: "not deleting",
+			machine: &clusterv1.Machine{
+				Status: clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: "node"}},
+			},
+			want: false,
+		},
+		{
+			name:    "deleting, not yet draining",
+			machine: deleting("m"),
+			want:    false,
+		},
+		{
+			name: "waiting for the node shutdown grace period",
+			machine: deleting("m", func(m *clusterv1.Machine) {
+				conditions.MarkFalse(m, clusterv1.NodeShutdownSignaledCondition, clusterv1.WaitingForNodeShutdownGracePeriodReason, clusterv1.ConditionSeverityInfo, "")
+			}),
+			want: true,
+		},
+		{
+			name: "draining",
+			machine: deleting("m", func(m *clusterv1.Machine) {
+				conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "")
+			}),
+			want: true,
+		},
+		{
+			name: "retrying a failed drain",
+			machine: deleting("m", func(m *clusterv1.Machine) {
+				conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingFailedReason, clusterv1.ConditionSeverityWarning, "")
+			}),
+			want: true,
+		},
+		{
+			name: "drained",
+			machine: deleting("m", func(m *clusterv1.Machine) {
+				conditions.MarkTrue(m, clusterv1.DrainingSucceededCondition)
+			}),
+			want: false,
+		},
+		{
+			name: "draining excluded",
+			machine: deleting("m", func(m *clusterv1.Machine) {
+				m.Annotations = map[string]string{clusterv1.ExcludeNodeDrainingAnnotation: ""}
+				conditions.MarkFalse(m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "")
+			}),
+			want: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			r := &MachineReconciler{}
+			g.Expect(r.isNodeDraining(tt.machine)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestDrainBudget(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := types.NamespacedName{Namespace: "default", Name: "cluster"}
+	machine := func(name string, draining bool) clusterv1.Machine {
+		m := clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				DeletionTimestamp: &metav1.Time{Time: time.Now()},
+			},
+		}
+		if draining {
+			conditions.MarkFalse(&m, clusterv1.DrainingSucceededCondition, clusterv1.DrainingReason, clusterv1.ConditionSeverityInfo, "")
+		}
+		return m
+	}
+	isDraining := func(m *clusterv1.Machine) bool {
+		return conditions.IsFalse(m, clusterv1.DrainingSucceededCondition)
+	}
+
+	b := &drainBudget{}
+
+	// m1 is observed as draining, m2 acquires the last slot.
+	machines := []clusterv1.Machine{machine("m1", true), machine("m2", false), machine("m3", false)}
+	g.Expect(b.acquire(cluster, "m1", machines, isDraining, 2)).To(BeTrue())
+	g.Expect(b.acquire(cluster, "m2", machines, isDraining, 2)).To(BeTrue())
+
+	// m3 must wait, even if m2 is not yet observed as draining.
+	g.Expect(b.acquire(cluster, "m3", machines, isDraining, 2)).To(BeFalse())
+
+	// Machines which acquired the budget can keep draining.
+	g.Expect(b.acquire(cluster, "m2", machines, isDraining, 2)).To(BeTrue())
+
+	// Other Clusters have their own budget.
+	g.Expect(b.acquire(types.NamespacedName{Namespace: "default", Name: "other"}, "m3", machines, isDraining, 2)).To(BeTrue())
+
+	// Once m1 is drained, m3 can drain.
+	conditions.MarkTrue(&machines[0], clusterv1.DrainingSucceededCondition)
+	g.Expect(b.acquire(cluster, "m3", machines, isDraining, 2)).To(BeTrue())
+
+	// Once m2 is released, m4 can drain.
+	machines = append(machines[1:], machine("m4", false))
+	g.Expect(b.acquire(cluster, "m4", machines, isDraining, 2)).To(BeFalse())
+	b.release(cluster, "m2")
+	g.Expect(b.acquire(cluster, "m4", machines, isDraining, 2)).To(BeTrue())
+}
@@ -25,10 +25,13 @@ import (
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/operations"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -41,16 +44,21 @@ func (r *MachineReconciler) reconcileNode(ctx context.Context, cluster *clusterv
 	log := ctrl.LoggerFrom(ctx, "machine", machine.Name, "namespace", machine.Namespace)
 	log = log.WithValues("cluster", cluster.Name)
 
-	// Check that the Machine has a valid ProviderID.
-	if machine.Spec.ProviderID == nil || *machine.Spec.ProviderID == "" {
+	// Check that the Machine has a valid ProviderID, unless the Node can be matched without it.
+	hasProviderID := machine.Spec.ProviderID != nil && *machine.Spec.ProviderID != ""
+	if !hasProviderID && !feature.Gates.Enabled(feature.NodeMatchingFallback) {
 		log.Info("Cannot reconcile Machine's Node, no valid ProviderID yet")
 		conditions.MarkFalse(machine, clusterv1.MachineNodeHealthyCondition, clusterv1.WaitingForNodeRefReason, clusterv1.ConditionSeverityInfo, "")
 		return ctrl.Result{}, nil
 	}
 
-	providerID, err := noderefutil.NewProviderID(*machine.Spec.ProviderID)
-	if err != nil {
-		return ctrl.Result{}, err
+	var providerID *noderefutil.ProviderID
+	if hasProviderID {
+		var err error
+		providerID, err = noderefutil.NewProviderID(*machine.Spec.ProviderID)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
 	}
 
 	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
@@ -59,7 +67,13 @@ func (r *MachineReconciler) reconcileNode(ctx context.Context, cluster *clusterv
 	}
 
 	// Even if Status.NodeRef exists, continue to do the following checks to make sure Node is healthy
-	node, err := r.getNode(ctx, remoteClient, providerID)
+	var node *corev1.Node
+	if providerID != nil {
+		node, err = r.getNode(ctx, remoteClient, providerID)
+	} else {
+		// The infrastructure provider doesn't report a ProviderID (yet), fallback to matching the Node by name or addresses.
+		node, err = r.getNodeWithoutProviderID(ctx, remoteClient, machine)
+	}
 	if err != nil {
 		if err == ErrNodeNotFound {
 			// While a NodeRef is set in the status, failing to get that node means the node is deleted.
@@ -86,8 +100,13 @@ func (r *MachineReconciler) reconcileNode(ctx context.Context, cluster *clusterv
 		}
 		log.Info("Set Machine's NodeRef", "noderef", machine.Status.NodeRef.Name)
 		r.recorder.Event(machine, corev1.EventTypeNormal, "SuccessfulSetNodeRef", machine.Status.NodeRef.Name)
+		operations.Succeeded(machine, "Set node reference", "Machine's node is %q", machine.Status.NodeRef.Name)
 	}
 
+	// Mirror the Node capacity and system information on the Machine.
+	machine.Status.Capacity = node.Status.Capacity.DeepCopy()
+	machine.Status.NodeInfo = node.Status.NodeInfo.DeepCopy()
+
 	// Reconcile node annotations.
 	patchHelper, err := patch.NewHelper(node, remoteClient)
 	if err != nil {
@@ -190,3 +209,91 @@ func (r *MachineReconciler) getNode(ctx context.Context, c client.Reader, provid
 
 	return nil, ErrNodeNotFound
 }
+
+// getNodeWithoutProviderID returns the Node for a Machine without a ProviderID, matching, in order:
+// - the Node already referenced by the Machine's NodeRef;
+// - the Node named after the Machine's node-name annotation, if set by the bootstrap provider;
+// - the only Node having a name or an address matching the Machine's addresses.
+// Nodes already claimed by other Machines are never matched.
+func (r *MachineReconciler) getNodeWithoutProviderID(ctx context.Context, c client.Reader, machine *clusterv1.Machine) (*corev1.Node, error) {
+	nodeName := ""
+	switch {
+	case machine.Status.NodeRef != nil:
+		nodeName = machine.Status.NodeRef.Name
+	case machine.Annotations[clusterv1.NodeNameAnnotation] != "":
+		nodeName = machine.Annotations[clusterv1.NodeNameAnnotation]
+	}
+
+	if nodeName != "" {
+		node := &corev1.Node{}
+		if err := c.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, ErrNodeNotFound
+			}
+			return nil, err
+		}
+		if isNodeClaimedByOtherMachine(node, machine) {
+			return nil, ErrNodeNotFound
+		}
+		return node, nil
+	}
+
+	if len(machine.Status.Addresses) == 0 {
+		return nil, ErrNodeNotFound
+	}
+
+	var matches []corev1.Node
+	nodeList := corev1.NodeList{}
+	for {
+		if err := c.List(ctx, &nodeList, client.Continue(nodeList.Continue)); err != nil {
+			return nil, err
+		}
+
+		for _, node := range nodeList.Items {
+			if !isNodeClaimedByOtherMachine(&node, machine) && nodeMatchesMachineAddresses(&node, machine.Status.Addresses) {
+				matches = append(matches, node)
+			}
+		}
+
+		if nodeList.Continue == "" {
+			break
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, ErrNodeNotFound
+	case 1:
+		return &matches[0], nil
+	default:
+		return nil, errors.Errorf("found %d Nodes matching the addresses of Machine %q in namespace %q", len(matches), machine.Name, machine.Namespace)
+	}
+}
+
+// isNodeClaimedByOtherMachine returns true if the Node is annotated as belonging to a different Machine.
+func isNodeClaimedByOtherMachine(node *corev1.Node, machine *clusterv1.Machine) bool {
+	name, ok := node.Annotations[clusterv1.MachineAnnotation]
+	if !ok {
+		return false
+	}
+	return name != machine.Name || node.Annotations[clusterv1.ClusterNamespaceAnnotation] != machine.Namespace
+}
+
+// nodeMatchesMachineAddresses returns true if the Node name is one of the Machine's host names,
+// or if the Node has an address of the same type and value of one of the Machine's addresses.
+func nodeMatchesMachineAddresses(node *corev1.Node, addresses clusterv1.MachineAddresses) bool {
+	for _, address := range addresses {
+		switch address.Type {
+		case clusterv1.MachineHostName, clusterv1.MachineInternalDNS, clusterv1.MachineExternalDNS:
+			if node.Name == address.Address {
+				return true
+			}
+		}
+		for _, nodeAddress := range node.Status.Addresses {
+			if string(nodeAddress.Type) == string(address.Type) && nodeAddress.Address == address.Address {
+				return true
+			}
+		}
+	}
+	return false
+}
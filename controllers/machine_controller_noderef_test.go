@@ -22,13 +22,18 @@ import (
 	. "github.com/onsi/gomega"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 func TestGetNodeReference(t *testing.T) {
@@ -124,6 +129,191 @@ func TestGetNodeReference(t *testing.T) {
 	}
 }
 
+func TestGetNodeWithoutProviderID(t *testing.T) {
+	r := &MachineReconciler{
+		Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).Build(),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	nodeList := []client.Object{
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-1",
+			},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: "10.0.0.1"},
+				},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-2",
+			},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+				},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-3",
+				Annotations: map[string]string{
+					clusterv1.MachineAnnotation:          "other-machine",
+					clusterv1.ClusterNamespaceAnnotation: "default",
+				},
+			},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: "10.0.0.3"},
+				},
+			},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "node-4",
+			},
+			Status: corev1.NodeStatus{
+				Addresses: []corev1.NodeAddress{
+					{Type: corev1.NodeInternalIP, Address: "10.0.0.2"},
+				},
+			},
+		},
+	}
+
+	client := fake.NewClientBuilder().WithObjects(nodeList...).Build()
+
+	testCases := []struct {
+		name        string
+		annotations map[string]string
+		nodeRef     *corev1.ObjectReference
+		addresses   clusterv1.MachineAddresses
+		expected    string
+		err         error
+		wantErr     bool
+	}{
+		{
+			name:     "node referenced by the machine",
+			nodeRef:  &corev1.ObjectReference{Name: "node-1"},
+			expected: "node-1",
+		},
+		{
+			name:        "node named after the node-name annotation",
+			annotations: map[string]string{clusterv1.NodeNameAnnotation: "node-1"},
+			expected:    "node-1",
+		},
+		{
+			name:        "node named after the node-name annotation not found",
+			annotations: map[string]string{clusterv1.NodeNameAnnotation: "node-100"},
+			err:         ErrNodeNotFound,
+		},
+		{
+			name:      "node matching the machine hostname",
+			addresses: clusterv1.MachineAddresses{{Type: clusterv1.MachineHostName, Address: "node-1"}},
+			expected:  "node-1",
+		},
+		{
+			name:      "node matching the machine internal IP",
+			addresses: clusterv1.MachineAddresses{{Type: clusterv1.MachineInternalIP, Address: "10.0.0.1"}},
+			expected:  "node-1",
+		},
+		{
+			name:      "addresses of a different type are not matched",
+			addresses: clusterv1.MachineAddresses{{Type: clusterv1.MachineExternalIP, Address: "10.0.0.1"}},
+			err:       ErrNodeNotFound,
+		},
+		{
+			name:        "node claimed by another machine is not matched",
+			annotations: map[string]string{clusterv1.NodeNameAnnotation: "node-3"},
+			err:         ErrNodeNotFound,
+		},
+		{
+			name:      "node claimed by another machine is not matched by address",
+			addresses: clusterv1.MachineAddresses{{Type: clusterv1.MachineInternalIP, Address: "10.0.0.3"}},
+			err:       ErrNodeNotFound,
+		},
+		{
+			name:      "fails if more than one node matches",
+			addresses: clusterv1.MachineAddresses{{Type: clusterv1.MachineInternalIP, Address: "10.0.0.2"}},
+			wantErr:   true,
+		},
+		{
+			name: "no node found without addresses",
+			err:  ErrNodeNotFound,
+		},
+	}
+
+	for _, test := range testCases {
+		t.Run(test.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "machine",
+					Namespace:   "default",
+					Annotations: test.annotations,
+				},
+				Status: clusterv1.MachineStatus{
+					NodeRef:   test.nodeRef,
+					Addresses: test.addresses,
+				},
+			}
+
+			node, err := r.getNodeWithoutProviderID(ctx, client, machine)
+			switch {
+			case test.err != nil:
+				g.Expect(err).To(Equal(test.err))
+			case test.wantErr:
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(err).NotTo(Equal(ErrNodeNotFound))
+			default:
+				g.Expect(err).NotTo(HaveOccurred())
+				g.Expect(node.Name).To(Equal(test.expected))
+			}
+		})
+	}
+}
+
+func TestReconcileNodeMirrorsCapacityAndNodeInfo(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault}}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "machine-test", Namespace: metav1.NamespaceDefault},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			ProviderID:  pointer.StringPtr("test://id-1"),
+		},
+	}
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+		Spec:       corev1.NodeSpec{ProviderID: "test://id-1"},
+		Status: corev1.NodeStatus{
+			Capacity: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("8Gi"),
+			},
+			NodeInfo: corev1.NodeSystemInfo{Architecture: "amd64", OSImage: "Ubuntu 20.04"},
+		},
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(node).Build()
+	r := &MachineReconciler{
+		Client:   c,
+		Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, c, scheme.Scheme, util.ObjectKey(cluster)),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	_, err := r.reconcileNode(ctx, cluster, machine)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(machine.Status.NodeRef).NotTo(BeNil())
+	g.Expect(machine.Status.Capacity).To(Equal(node.Status.Capacity))
+	g.Expect(machine.Status.NodeInfo).To(Equal(&node.Status.NodeInfo))
+}
+
 func TestSummarizeNodeConditions(t *testing.T) {
 	testCases := []struct {
 		name       string
@@ -31,6 +31,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
@@ -215,10 +216,11 @@ func (r *MachineReconciler) reconcileBootstrap(ctx context.Context, cluster *clu
 		conditions.WithFallbackValue(ready, clusterv1.WaitingForDataSecretFallbackReason, clusterv1.ConditionSeverityInfo, ""),
 	)
 
-	// If the bootstrap provider is not ready, requeue.
+	// If the bootstrap provider is not ready, requeue when the provider expects it to be, if reported.
 	if !ready {
-		log.Info("Bootstrap provider is not ready, requeuing")
-		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
+		requeueAfter := external.RequeueAfterFrom(log, bootstrapConfig, externalReadyWait)
+		log.Info("Bootstrap provider is not ready, requeuing", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	// Get and set the name of the secret containing the bootstrap data.
@@ -246,10 +248,14 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 	if infraReconcileResult.RequeueAfter > 0 {
 		// Infra object went missing after the machine was up and running
 		if m.Status.InfrastructureReady {
-			log.Error(err, "Machine infrastructure reference has been deleted after being ready, setting failure state")
-			m.Status.FailureReason = capierrors.MachineStatusErrorPtr(capierrors.InvalidConfigurationMachineError)
-			m.Status.FailureMessage = pointer.StringPtr(fmt.Sprintf("Machine infrastructure resource %v with name %q has been deleted after being ready",
-				m.Spec.InfrastructureRef.GroupVersionKind(), m.Spec.InfrastructureRef.Name))
+			if m.Status.FailureReason == nil {
+				log.Error(err, "Machine infrastructure reference has been deleted after being ready, setting failure state")
+				r.recorder.Eventf(m, corev1.EventTypeWarning, "InfrastructureDeleted", "Machine infrastructure resource %v with name %q has been deleted after being ready",
+					m.Spec.InfrastructureRef.GroupVersionKind(), m.Spec.InfrastructureRef.Name)
+				m.Status.FailureReason = capierrors.MachineStatusErrorPtr(capierrors.InfrastructureDeletedMachineError)
+				m.Status.FailureMessage = pointer.StringPtr(fmt.Sprintf("Machine infrastructure resource %v with name %q has been deleted after being ready",
+					m.Spec.InfrastructureRef.GroupVersionKind(), m.Spec.InfrastructureRef.Name))
+			}
 			return ctrl.Result{}, errors.Errorf("could not find %v %q for Machine %q in namespace %q, requeueing", m.Spec.InfrastructureRef.GroupVersionKind().String(), m.Spec.InfrastructureRef.Name, m.Name, m.Namespace)
 		}
 		return ctrl.Result{RequeueAfter: infraReconcileResult.RequeueAfter}, nil
@@ -264,6 +270,22 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		return ctrl.Result{}, nil
 	}
 
+	// Get and set Status.InstanceState from the infrastructure provider, if reported; instances terminated
+	// outside of Cluster API mark the Machine as failed, so it can be remediated without waiting for the Node
+	// to become unhealthy.
+	instanceState, err := external.InstanceStateFrom(infraConfig)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	m.Status.InstanceState = clusterv1.MachineInstanceState(instanceState)
+	if m.Status.InstanceState == clusterv1.InstanceStateTerminatedExternally && m.Status.FailureReason == nil {
+		log.Info("Machine instance has been terminated externally, setting failure state")
+		m.Status.FailureReason = capierrors.MachineStatusErrorPtr(capierrors.InstanceTerminatedMachineError)
+		m.Status.FailureMessage = pointer.StringPtr(fmt.Sprintf("Machine instance backing %v with name %q has been terminated externally",
+			m.Spec.InfrastructureRef.GroupVersionKind(), m.Spec.InfrastructureRef.Name))
+		r.recorder.Event(m, corev1.EventTypeWarning, "InstanceTerminated", *m.Status.FailureMessage)
+	}
+
 	// Determine if the infrastructure provider is ready.
 	ready, err := external.IsReady(infraConfig)
 	if err != nil {
@@ -277,17 +299,35 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		conditions.WithFallbackValue(ready, clusterv1.WaitingForInfrastructureFallbackReason, clusterv1.ConditionSeverityInfo, ""),
 	)
 
-	// If the infrastructure provider is not ready, return early.
+	// Report infrastructure lacking capacity in the InfrastructureReady condition, so MachineSets can retry the
+	// Machine in a different failure domain without reading the infrastructure object of every Machine.
 	if !ready {
-		log.Info("Infrastructure provider is not ready, requeuing")
-		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
+		failureHint, _, err := external.FailureHintFrom(infraConfig)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if clusterv1.MachineFailureHint(failureHint) == clusterv1.InsufficientCapacityFailureHint {
+			conditions.MarkFalse(m, clusterv1.InfrastructureReadyCondition, clusterv1.InsufficientCapacityReason, clusterv1.ConditionSeverityWarning,
+				"%s %q reports insufficient capacity", m.Spec.InfrastructureRef.Kind, m.Spec.InfrastructureRef.Name)
+		}
+	}
+
+	// If the infrastructure provider is not ready, requeue when the provider expects it to be, if reported.
+	if !ready {
+		requeueAfter := external.RequeueAfterFrom(log, infraConfig, externalReadyWait)
+		log.Info("Infrastructure provider is not ready, requeuing", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	// Get Spec.ProviderID from the infrastructure provider.
+	// NB. When the NodeMatchingFallback feature is enabled, the infrastructure provider is allowed to not report it (yet).
 	var providerID string
-	if err := util.UnstructuredUnmarshalField(infraConfig, &providerID, "spec", "providerID"); err != nil {
+	err = util.UnstructuredUnmarshalField(infraConfig, &providerID, "spec", "providerID")
+	switch {
+	case err == util.ErrUnstructuredFieldNotFound && feature.Gates.Enabled(feature.NodeMatchingFallback): // no-op
+	case err != nil:
 		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve Spec.ProviderID from infrastructure provider for Machine %q in namespace %q", m.Name, m.Namespace)
-	} else if providerID == "" {
+	case providerID == "" && !feature.Gates.Enabled(feature.NodeMatchingFallback):
 		return ctrl.Result{}, errors.Errorf("retrieved empty Spec.ProviderID from infrastructure provider for Machine %q in namespace %q", m.Name, m.Namespace)
 	}
 
@@ -297,6 +337,28 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve addresses from infrastructure provider for Machine %q in namespace %q", m.Name, m.Namespace)
 	}
 
+	// Get and set Status.Capacity and Status.NodeInfo from the infrastructure provider, if reported;
+	// once the Node exists, they are overridden with the values reported by the Node.
+	if m.Status.NodeRef == nil {
+		err = util.UnstructuredUnmarshalField(infraConfig, &m.Status.Capacity, "status", "capacity")
+		if err != nil && err != util.ErrUnstructuredFieldNotFound {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve capacity from infrastructure provider for Machine %q in namespace %q", m.Name, m.Namespace)
+		}
+		err = util.UnstructuredUnmarshalField(infraConfig, &m.Status.NodeInfo, "status", "nodeInfo")
+		if err != nil && err != util.ErrUnstructuredFieldNotFound {
+			return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve node info from infrastructure provider for Machine %q in namespace %q", m.Name, m.Namespace)
+		}
+	}
+
+	// Get and set Status.InstanceMetadata from the infrastructure provider; the value is decoded into a fresh object and
+	// cleared when no longer reported, so metadata dropped by the provider doesn't linger on the Machine.
+	var instanceMetadata *clusterv1.MachineInstanceMetadata
+	err = util.UnstructuredUnmarshalField(infraConfig, &instanceMetadata, "status", "instanceMetadata")
+	if err != nil && err != util.ErrUnstructuredFieldNotFound {
+		return ctrl.Result{}, errors.Wrapf(err, "failed to retrieve instance metadata from infrastructure provider for Machine %q in namespace %q", m.Name, m.Namespace)
+	}
+	m.Status.InstanceMetadata = instanceMetadata
+
 	// Get and set the failure domain from the infrastructure provider.
 	var failureDomain string
 	err = util.UnstructuredUnmarshalField(infraConfig, &failureDomain, "spec", "failureDomain")
@@ -308,6 +370,19 @@ func (r *MachineReconciler) reconcileInfrastructure(ctx context.Context, cluster
 		m.Spec.FailureDomain = pointer.StringPtr(failureDomain)
 	}
 
-	m.Spec.ProviderID = pointer.StringPtr(providerID)
+	if providerID != "" {
+		m.Spec.ProviderID = pointer.StringPtr(providerID)
+	}
 	return ctrl.Result{}, nil
 }
+
+// isTerminalMachinePhase returns true if a Machine in the given phase is not expected to change phase without
+// an event being triggered, so it doesn't require to be reconciled periodically.
+func isTerminalMachinePhase(phase clusterv1.MachinePhase) bool {
+	switch phase {
+	case clusterv1.MachinePhaseRunning, clusterv1.MachinePhaseFailed, clusterv1.MachinePhaseDeleted:
+		return true
+	default:
+		return false
+	}
+}
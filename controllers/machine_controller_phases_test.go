@@ -34,6 +34,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	capierrors "sigs.k8s.io/cluster-api/errors"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -653,6 +654,26 @@ func TestReconcileBootstrap(t *testing.T) {
 				g.Expect(m.Status.BootstrapReady).To(BeFalse())
 			},
 		},
+		{
+			name: "new machine, bootstrap config not ready with a requeue hint",
+			bootstrapConfig: map[string]interface{}{
+				"kind":       "BootstrapMachine",
+				"apiVersion": "bootstrap.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "bootstrap-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{},
+				"status": map[string]interface{}{
+					"requeueAfter": "5s",
+				},
+			},
+			expectResult: ctrl.Result{RequeueAfter: 5 * time.Second},
+			expectError:  false,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.BootstrapReady).To(BeFalse())
+			},
+		},
 		{
 			name: "new machine, bootstrap config is not found",
 			bootstrapConfig: map[string]interface{}{
@@ -899,6 +920,7 @@ func TestReconcileInfrastructure(t *testing.T) {
 		expectResult    ctrl.Result
 		expectError     bool
 		expectChanged   bool
+		expectEvent     string
 		expected        func(g *WithT, m *clusterv1.Machine)
 	}{
 		{
@@ -944,6 +966,181 @@ func TestReconcileInfrastructure(t *testing.T) {
 				g.Expect(m.GetOwnerReferences()).NotTo(ContainRefOfGroupKind("cluster.x-k8s.io", "MachineSet"))
 			},
 		},
+		{
+			name: "new machine, infrastructure config ready with capacity and node info",
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"providerID": "test://id-1",
+				},
+				"status": map[string]interface{}{
+					"ready": true,
+					"capacity": map[string]interface{}{
+						"cpu":    "4",
+						"memory": "16Gi",
+					},
+					"nodeInfo": map[string]interface{}{
+						"architecture": "arm64",
+						"osImage":      "Ubuntu 20.04",
+					},
+				},
+			},
+			expectResult:  ctrl.Result{},
+			expectError:   false,
+			expectChanged: true,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.InfrastructureReady).To(BeTrue())
+				g.Expect(m.Status.Capacity.Cpu().String()).To(Equal("4"))
+				g.Expect(m.Status.Capacity.Memory().String()).To(Equal("16Gi"))
+				g.Expect(m.Status.NodeInfo).ToNot(BeNil())
+				g.Expect(m.Status.NodeInfo.Architecture).To(Equal("arm64"))
+				g.Expect(m.Status.NodeInfo.OSImage).To(Equal("Ubuntu 20.04"))
+			},
+		},
+		{
+			name: "infrastructure config reports the instance has been terminated externally, expect failed",
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"providerID": "test://id-1",
+				},
+				"status": map[string]interface{}{
+					"ready":         true,
+					"instanceState": "TerminatedExternally",
+				},
+			},
+			expectResult:  ctrl.Result{},
+			expectError:   false,
+			expectChanged: true,
+			expectEvent:   "Warning InstanceTerminated",
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.InstanceState).To(Equal(clusterv1.InstanceStateTerminatedExternally))
+				g.Expect(m.Status.FailureReason).ToNot(BeNil())
+				g.Expect(*m.Status.FailureReason).To(Equal(capierrors.InstanceTerminatedMachineError))
+				g.Expect(m.Status.FailureMessage).ToNot(BeNil())
+			},
+		},
+		{
+			name: "new machine, infrastructure config ready with instance metadata",
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"providerID": "test://id-1",
+				},
+				"status": map[string]interface{}{
+					"ready": true,
+					"instanceMetadata": map[string]interface{}{
+						"instanceType": "m5.large",
+						"zone":         "us-east-1a",
+						"image":        "ami-123456",
+						"priceTier":    "Spot",
+					},
+				},
+			},
+			expectResult:  ctrl.Result{},
+			expectError:   false,
+			expectChanged: true,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.InfrastructureReady).To(BeTrue())
+				g.Expect(m.Status.InstanceMetadata).To(Equal(&clusterv1.MachineInstanceMetadata{
+					InstanceType: "m5.large",
+					Zone:         "us-east-1a",
+					Image:        "ami-123456",
+					PriceTier:    "Spot",
+				}))
+			},
+		},
+		{
+			name: "infrastructure config no longer reports instance metadata, expect it to be cleared",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "machine-test",
+					Namespace: "default",
+				},
+				Spec: clusterv1.MachineSpec{
+					Bootstrap: clusterv1.Bootstrap{
+						ConfigRef: &corev1.ObjectReference{
+							APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4",
+							Kind:       "BootstrapMachine",
+							Name:       "bootstrap-config1",
+						},
+					},
+					InfrastructureRef: corev1.ObjectReference{
+						APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+						Kind:       "InfrastructureMachine",
+						Name:       "infra-config1",
+					},
+				},
+				Status: clusterv1.MachineStatus{
+					InfrastructureReady: true,
+					InstanceMetadata: &clusterv1.MachineInstanceMetadata{
+						InstanceType: "m5.large",
+						Zone:         "us-east-1a",
+					},
+				},
+			},
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"providerID": "test://id-1",
+				},
+				"status": map[string]interface{}{
+					"ready": true,
+				},
+			},
+			expectResult:  ctrl.Result{},
+			expectError:   false,
+			expectChanged: false,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.InstanceMetadata).To(BeNil())
+			},
+		},
+		{
+			name: "infrastructure config reports insufficient capacity, expect InfrastructureReady condition reason",
+			infraConfig: map[string]interface{}{
+				"kind":       "InfrastructureMachine",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"failureDomain": "fd1",
+				},
+				"status": map[string]interface{}{
+					"ready":       false,
+					"failureHint": "InsufficientCapacity",
+				},
+			},
+			expectResult:  ctrl.Result{RequeueAfter: externalReadyWait},
+			expectError:   false,
+			expectChanged: true,
+			expected: func(g *WithT, m *clusterv1.Machine) {
+				g.Expect(m.Status.InfrastructureReady).To(BeFalse())
+				g.Expect(conditions.GetReason(m, clusterv1.InfrastructureReadyCondition)).To(Equal(clusterv1.InsufficientCapacityReason))
+				g.Expect(conditions.Get(m, clusterv1.InfrastructureReadyCondition).Severity).To(Equal(clusterv1.ConditionSeverityWarning))
+			},
+		},
 		{
 			name: "ready bootstrap, infra, and nodeRef, machine is running, infra object is deleted, expect failed",
 			machine: &clusterv1.Machine{
@@ -994,7 +1191,7 @@ func TestReconcileInfrastructure(t *testing.T) {
 			expected: func(g *WithT, m *clusterv1.Machine) {
 				g.Expect(m.Status.InfrastructureReady).To(BeTrue())
 				g.Expect(m.Status.FailureMessage).ToNot(BeNil())
-				g.Expect(m.Status.FailureReason).ToNot(BeNil())
+				g.Expect(m.Status.FailureReason).To(Equal(capierrors.MachineStatusErrorPtr(capierrors.InfrastructureDeletedMachineError)))
 				g.Expect(m.Status.GetTypedPhase()).To(Equal(clusterv1.MachinePhaseFailed))
 			},
 		},
@@ -1047,6 +1244,7 @@ func TestReconcileInfrastructure(t *testing.T) {
 			}
 
 			infraConfig := &unstructured.Unstructured{Object: tc.infraConfig}
+			recorder := record.NewFakeRecorder(32)
 			r := &MachineReconciler{
 				Client: fake.NewClientBuilder().
 					WithScheme(scheme.Scheme).
@@ -1055,6 +1253,7 @@ func TestReconcileInfrastructure(t *testing.T) {
 						external.TestGenericInfrastructureCRD.DeepCopy(),
 						infraConfig,
 					).Build(),
+				recorder: recorder,
 			}
 
 			result, err := r.reconcileInfrastructure(ctx, defaultCluster, tc.machine)
@@ -1069,6 +1268,10 @@ func TestReconcileInfrastructure(t *testing.T) {
 			if tc.expected != nil {
 				tc.expected(g, tc.machine)
 			}
+
+			if tc.expectEvent != "" {
+				g.Expect(recorder.Events).To(Receive(HavePrefix(tc.expectEvent)))
+			}
 		})
 	}
 }
@@ -27,6 +27,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
@@ -1142,6 +1143,126 @@ func TestRemoveMachineFinalizerAfterDeleteReconcile(t *testing.T) {
 	g.Expect(actual.ObjectMeta.Finalizers).To(BeEmpty())
 }
 
+func TestReconcileDeleteSkipNodeDeletion(t *testing.T) {
+	g := NewWithT(t)
+
+	dt := metav1.Now()
+
+	testCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:   "default",
+			Name:        "test-cluster",
+			Annotations: map[string]string{clusterv1.SkipNodeDeletionAnnotation: ""},
+		},
+	}
+
+	controlPlaneMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cp1",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:             "test-cluster",
+				clusterv1.MachineControlPlaneLabelName: "",
+			},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+	}
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "delete123",
+			Namespace:         "default",
+			Labels:            map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+			Annotations:       map[string]string{clusterv1.ExcludeNodeDrainingAnnotation: ""},
+			Finalizers:        []string{clusterv1.MachineFinalizer},
+			DeletionTimestamp: &dt,
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfrastructureMachine",
+				Name:       "infra-config1",
+			},
+			Bootstrap: clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("data")},
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "test-node"},
+		},
+	}
+	key := client.ObjectKey{Namespace: m.Namespace, Name: m.Name}
+	// The reconciler has no Tracker, so any attempt to delete the node would fail.
+	mr := &MachineReconciler{
+		Client: helpers.NewFakeClientWithScheme(scheme.Scheme, testCluster, controlPlaneMachine, m),
+	}
+	_, err := mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var actual clusterv1.Machine
+	g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+	g.Expect(actual.ObjectMeta.Finalizers).To(BeEmpty())
+}
+
+func TestReconcileDeleteRetainNode(t *testing.T) {
+	g := NewWithT(t)
+
+	dt := metav1.Now()
+
+	testCluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"},
+	}
+
+	controlPlaneMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "cp1",
+			Namespace: "default",
+			Labels: map[string]string{
+				clusterv1.ClusterLabelName:             "test-cluster",
+				clusterv1.MachineControlPlaneLabelName: "",
+			},
+		},
+		Spec: clusterv1.MachineSpec{ClusterName: "test-cluster"},
+	}
+
+	m := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              "delete123",
+			Namespace:         "default",
+			Labels:            map[string]string{clusterv1.ClusterLabelName: "test-cluster"},
+			Finalizers:        []string{clusterv1.MachineFinalizer},
+			DeletionTimestamp: &dt,
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: "test-cluster",
+			InfrastructureRef: corev1.ObjectReference{
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Kind:       "InfrastructureMachine",
+				Name:       "infra-config1",
+			},
+			Bootstrap:      clusterv1.Bootstrap{DataSecretName: pointer.StringPtr("data")},
+			DeletionPolicy: clusterv1.RetainNodeMachineDeletionPolicy,
+		},
+		Status: clusterv1.MachineStatus{
+			NodeRef: &corev1.ObjectReference{Name: "test-node"},
+		},
+	}
+	key := client.ObjectKey{Namespace: m.Namespace, Name: m.Name}
+	// The reconciler has no Tracker, so any attempt to drain or delete the node would fail.
+	recorder := record.NewFakeRecorder(10)
+	mr := &MachineReconciler{
+		Client:   helpers.NewFakeClientWithScheme(scheme.Scheme, testCluster, controlPlaneMachine, m),
+		recorder: recorder,
+	}
+	_, err := mr.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+	g.Expect(err).ToNot(HaveOccurred())
+
+	var actual clusterv1.Machine
+	g.Expect(mr.Client.Get(ctx, key, &actual)).To(Succeed())
+	g.Expect(actual.ObjectMeta.Finalizers).To(BeEmpty())
+	g.Expect(conditions.GetReason(&actual, clusterv1.DrainingSucceededCondition)).To(Equal(clusterv1.NodeRetainedReason))
+	g.Expect(recorder.Events).To(Receive(ContainSubstring("RetainedNode")))
+}
+
 func Test_clusterToActiveMachines(t *testing.T) {
 	testCluster2Machines := &clusterv1.Cluster{
 		TypeMeta:   metav1.TypeMeta{Kind: "Cluster", APIVersion: clusterv1.GroupVersion.String()},
@@ -1349,6 +1470,347 @@ func TestIsNodeDrainedAllowed(t *testing.T) {
 	}
 }
 
+func TestNodeDeletionTimeoutExceeded(t *testing.T) {
+	infrastructureDeleted := func(ago time.Duration) clusterv1.Conditions {
+		return clusterv1.Conditions{{
+			Type:               clusterv1.InfrastructureReadyCondition,
+			Status:             corev1.ConditionFalse,
+			Reason:             clusterv1.DeletedReason,
+			LastTransitionTime: metav1.NewTime(time.Now().Add(-ago)),
+		}}
+	}
+
+	tests := []struct {
+		name                string
+		nodeDeletionTimeout *metav1.Duration
+		conditions          clusterv1.Conditions
+		expectedPollTimeout time.Duration
+		expected            bool
+	}{
+		{
+			name:                "NodeDeletionTimeout is not set, default timeout is not yet over",
+			conditions:          infrastructureDeleted(5 * time.Second),
+			expectedPollTimeout: 10 * time.Second,
+			expected:            false,
+		},
+		{
+			name:                "NodeDeletionTimeout is not set, default timeout is over",
+			conditions:          infrastructureDeleted(20 * time.Second),
+			expectedPollTimeout: 10 * time.Second,
+			expected:            true,
+		},
+		{
+			name:                "NodeDeletionTimeout is not yet over",
+			nodeDeletionTimeout: &metav1.Duration{Duration: time.Minute},
+			conditions:          infrastructureDeleted(30 * time.Second),
+			expectedPollTimeout: 10 * time.Second,
+			expected:            false,
+		},
+		{
+			name:                "NodeDeletionTimeout is over",
+			nodeDeletionTimeout: &metav1.Duration{Duration: 5 * time.Second},
+			conditions:          infrastructureDeleted(30 * time.Second),
+			expectedPollTimeout: 5 * time.Second,
+			expected:            true,
+		},
+		{
+			name: "NodeDeletionTimeout does not start before the infrastructure is deleted",
+			conditions: clusterv1.Conditions{{
+				Type:               clusterv1.InfrastructureReadyCondition,
+				Status:             corev1.ConditionFalse,
+				Reason:             clusterv1.DeletingReason,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}},
+			expectedPollTimeout: 10 * time.Second,
+			expected:            false,
+		},
+		{
+			name:                "NodeDeletionTimeout is set to 0",
+			nodeDeletionTimeout: &metav1.Duration{},
+			conditions:          infrastructureDeleted(time.Hour),
+			expectedPollTimeout: 10 * time.Second,
+			expected:            false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-time.Hour)}},
+				Spec:       clusterv1.MachineSpec{NodeDeletionTimeout: tt.nodeDeletionTimeout},
+				Status:     clusterv1.MachineStatus{Conditions: tt.conditions},
+			}
+			g.Expect(nodeDeletionPollTimeout(m)).To(Equal(tt.expectedPollTimeout))
+			g.Expect(nodeDeletionTimeoutExceeded(m)).To(Equal(tt.expected))
+		})
+	}
+}
+
+func TestReconcileDeleteHook(t *testing.T) {
+	hook := clusterv1.PreDrainDeleteHookAnnotationPrefix + "/test"
+
+	tests := []struct {
+		name             string
+		annotations      map[string]string
+		timeout          *metav1.Duration
+		conditions       clusterv1.Conditions
+		expectWaiting    bool
+		expectRequeue    bool
+		expectEvent      bool
+		expectCondition  corev1.ConditionStatus
+		expectReason     string
+		expectedSeverity clusterv1.ConditionSeverity
+	}{
+		{
+			name:            "no hooks",
+			expectWaiting:   false,
+			expectCondition: corev1.ConditionTrue,
+		},
+		{
+			name:             "hook without timeout",
+			annotations:      map[string]string{hook: "owner"},
+			expectWaiting:    true,
+			expectCondition:  corev1.ConditionFalse,
+			expectReason:     clusterv1.WaitingExternalHookReason,
+			expectedSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name:        "hook with timeout not yet expired",
+			annotations: map[string]string{hook: "owner"},
+			timeout:     &metav1.Duration{Duration: 60 * time.Second},
+			conditions: clusterv1.Conditions{
+				{
+					Type:               clusterv1.PreDrainDeleteHookSucceededCondition,
+					Status:             corev1.ConditionFalse,
+					Reason:             clusterv1.WaitingExternalHookReason,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-30 * time.Second)},
+				},
+			},
+			expectWaiting:    true,
+			expectRequeue:    true,
+			expectCondition:  corev1.ConditionFalse,
+			expectReason:     clusterv1.WaitingExternalHookReason,
+			expectedSeverity: clusterv1.ConditionSeverityInfo,
+		},
+		{
+			name:        "hook with timeout expired",
+			annotations: map[string]string{hook: "owner"},
+			timeout:     &metav1.Duration{Duration: 60 * time.Second},
+			conditions: clusterv1.Conditions{
+				{
+					Type:               clusterv1.PreDrainDeleteHookSucceededCondition,
+					Status:             corev1.ConditionFalse,
+					Reason:             clusterv1.WaitingExternalHookReason,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-70 * time.Second)},
+				},
+			},
+			expectWaiting:    false,
+			expectEvent:      true,
+			expectCondition:  corev1.ConditionFalse,
+			expectReason:     clusterv1.ExternalHookTimeoutExceededReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			name:             "hook with force delete",
+			annotations:      map[string]string{hook: "owner", clusterv1.ForceDeleteAnnotation: ""},
+			expectWaiting:    false,
+			expectEvent:      true,
+			expectCondition:  corev1.ConditionFalse,
+			expectReason:     clusterv1.ForceDeleteRequestedReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+		{
+			name:        "hook already skipped does not record the event again",
+			annotations: map[string]string{hook: "owner", clusterv1.ForceDeleteAnnotation: ""},
+			conditions: clusterv1.Conditions{
+				{
+					Type:               clusterv1.PreDrainDeleteHookSucceededCondition,
+					Status:             corev1.ConditionFalse,
+					Reason:             clusterv1.ForceDeleteRequestedReason,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-10 * time.Second)},
+				},
+			},
+			expectWaiting:    false,
+			expectEvent:      false,
+			expectCondition:  corev1.ConditionFalse,
+			expectReason:     clusterv1.ForceDeleteRequestedReason,
+			expectedSeverity: clusterv1.ConditionSeverityWarning,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test-machine",
+					Namespace:   "default",
+					Annotations: tt.annotations,
+				},
+				Status: clusterv1.MachineStatus{
+					Conditions: tt.conditions,
+				},
+			}
+			recorder := record.NewFakeRecorder(10)
+			r := &MachineReconciler{
+				recorder: recorder,
+			}
+
+			result, waiting := r.reconcileDeleteHook(m, clusterv1.PreDrainDeleteHookAnnotationPrefix, clusterv1.PreDrainDeleteHookSucceededCondition, tt.timeout)
+			g.Expect(waiting).To(Equal(tt.expectWaiting))
+			if tt.expectRequeue {
+				g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			} else {
+				g.Expect(result.RequeueAfter).To(BeZero())
+			}
+			if tt.expectEvent {
+				g.Expect(recorder.Events).To(Receive(ContainSubstring(hook)))
+			} else {
+				g.Expect(recorder.Events).NotTo(Receive())
+			}
+
+			c := conditions.Get(m, clusterv1.PreDrainDeleteHookSucceededCondition)
+			g.Expect(c).ToNot(BeNil())
+			g.Expect(c.Status).To(Equal(tt.expectCondition))
+			g.Expect(c.Reason).To(Equal(tt.expectReason))
+			g.Expect(c.Severity).To(Equal(tt.expectedSeverity))
+		})
+	}
+}
+
+func TestReconcileNodeShutdownSignal(t *testing.T) {
+	gracePeriod := &metav1.Duration{Duration: 60 * time.Second}
+
+	tests := []struct {
+		name            string
+		gracePeriod     *metav1.Duration
+		nodeExists      bool
+		conditions      clusterv1.Conditions
+		expectWaiting   bool
+		expectRequeue   bool
+		expectAnnotated bool
+		expectCondition *corev1.ConditionStatus
+	}{
+		{
+			name:          "no grace period",
+			nodeExists:    true,
+			expectWaiting: false,
+		},
+		{
+			name:            "grace period, node shutdown not yet signaled",
+			gracePeriod:     gracePeriod,
+			nodeExists:      true,
+			expectWaiting:   true,
+			expectRequeue:   true,
+			expectAnnotated: true,
+			expectCondition: conditionStatusPtr(corev1.ConditionFalse),
+		},
+		{
+			name:            "grace period, node does not exist",
+			gracePeriod:     gracePeriod,
+			expectWaiting:   false,
+			expectCondition: conditionStatusPtr(corev1.ConditionTrue),
+		},
+		{
+			name:        "grace period not yet expired",
+			gracePeriod: gracePeriod,
+			nodeExists:  true,
+			conditions: clusterv1.Conditions{
+				{
+					Type:               clusterv1.NodeShutdownSignaledCondition,
+					Status:             corev1.ConditionFalse,
+					Reason:             clusterv1.WaitingForNodeShutdownGracePeriodReason,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-30 * time.Second)},
+				},
+			},
+			expectWaiting:   true,
+			expectRequeue:   true,
+			expectCondition: conditionStatusPtr(corev1.ConditionFalse),
+		},
+		{
+			name:        "grace period expired",
+			gracePeriod: gracePeriod,
+			nodeExists:  true,
+			conditions: clusterv1.Conditions{
+				{
+					Type:               clusterv1.NodeShutdownSignaledCondition,
+					Status:             corev1.ConditionFalse,
+					Reason:             clusterv1.WaitingForNodeShutdownGracePeriodReason,
+					LastTransitionTime: metav1.Time{Time: time.Now().Add(-70 * time.Second)},
+				},
+			},
+			expectWaiting:   false,
+			expectCondition: conditionStatusPtr(corev1.ConditionTrue),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-cluster",
+					Namespace: "default",
+				},
+			}
+			m := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "test-machine",
+					Namespace: "default",
+				},
+				Spec: clusterv1.MachineSpec{
+					NodeShutdownGracePeriod: tt.gracePeriod,
+				},
+				Status: clusterv1.MachineStatus{
+					NodeRef:    &corev1.ObjectReference{Name: "test-node"},
+					Conditions: tt.conditions,
+				},
+			}
+			objs := []client.Object{cluster}
+			if tt.nodeExists {
+				objs = append(objs, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "test-node"}})
+			}
+			c := helpers.NewFakeClientWithScheme(scheme.Scheme, objs...)
+			r := &MachineReconciler{
+				Client:   c,
+				Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, c, scheme.Scheme, util.ObjectKey(cluster)),
+				recorder: record.NewFakeRecorder(10),
+			}
+
+			result, waiting, err := r.reconcileNodeShutdownSignal(ctx, cluster, m)
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(waiting).To(Equal(tt.expectWaiting))
+			if tt.expectRequeue {
+				g.Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			} else {
+				g.Expect(result.RequeueAfter).To(BeZero())
+			}
+
+			if tt.nodeExists {
+				node := &corev1.Node{}
+				g.Expect(c.Get(ctx, client.ObjectKey{Name: "test-node"}, node)).To(Succeed())
+				if tt.expectAnnotated {
+					g.Expect(node.Annotations).To(HaveKey(clusterv1.NodeShutdownRequestedAnnotation))
+				} else {
+					g.Expect(node.Annotations).ToNot(HaveKey(clusterv1.NodeShutdownRequestedAnnotation))
+				}
+			}
+
+			if tt.expectCondition == nil {
+				g.Expect(conditions.Get(m, clusterv1.NodeShutdownSignaledCondition)).To(BeNil())
+				return
+			}
+			g.Expect(conditions.Get(m, clusterv1.NodeShutdownSignaledCondition)).ToNot(BeNil())
+			g.Expect(conditions.Get(m, clusterv1.NodeShutdownSignaledCondition).Status).To(Equal(*tt.expectCondition))
+		})
+	}
+}
+
+func conditionStatusPtr(s corev1.ConditionStatus) *corev1.ConditionStatus {
+	return &s
+}
+
 func TestIsDeleteNodeAllowed(t *testing.T) {
 	deletionts := metav1.Now()
 
@@ -20,9 +20,12 @@ import (
 	"context"
 
 	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -50,6 +53,24 @@ func getActiveMachinesInCluster(ctx context.Context, c client.Client, namespace,
 	return machines, nil
 }
 
+// isExternalManagedControlPlane returns true if the control plane referenced by the Cluster is externally managed
+// (e.g. AKS, EKS, GKE); in this case there are no control plane Machines nor Nodes for the Cluster.
+// If the Cluster has no control plane reference, or the referenced object does not exist, false is returned.
+func isExternalManagedControlPlane(ctx context.Context, c client.Client, cluster *clusterv1.Cluster) (bool, error) {
+	if cluster.Spec.ControlPlaneRef == nil {
+		return false, nil
+	}
+
+	controlPlane, err := external.Get(ctx, c, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			return false, nil
+		}
+		return false, err
+	}
+	return util.IsExternalManagedControlPlane(controlPlane), nil
+}
+
 // hasMatchingLabels verifies that the Label Selector matches the given Labels
 func hasMatchingLabels(matchSelector metav1.LabelSelector, matchLabels map[string]string) bool {
 	// This should never fail, validating webhook should catch this first
@@ -21,7 +21,9 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -128,6 +130,76 @@ func Test_getActiveMachinesInCluster(t *testing.T) {
 	}
 }
 
+func Test_isExternalManagedControlPlane(t *testing.T) {
+	newControlPlane := func(name string, status map[string]interface{}) *unstructured.Unstructured {
+		cp := &unstructured.Unstructured{Object: map[string]interface{}{}}
+		if status != nil {
+			cp.Object["status"] = status
+		}
+		cp.SetAPIVersion("controlplane.cluster.x-k8s.io/v1alpha4")
+		cp.SetKind("AWSManagedControlPlane")
+		cp.SetName(name)
+		cp.SetNamespace("test-ns")
+		return cp
+	}
+	newCluster := func(controlPlaneName string) *clusterv1.Cluster {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster",
+				Namespace: "test-ns",
+			},
+		}
+		if controlPlaneName != "" {
+			cluster.Spec.ControlPlaneRef = &corev1.ObjectReference{
+				APIVersion: "controlplane.cluster.x-k8s.io/v1alpha4",
+				Kind:       "AWSManagedControlPlane",
+				Name:       controlPlaneName,
+			}
+		}
+		return cluster
+	}
+
+	managed := newControlPlane("managed", map[string]interface{}{"externalManagedControlPlane": true})
+	notManaged := newControlPlane("not-managed", nil)
+
+	tests := []struct {
+		name    string
+		cluster *clusterv1.Cluster
+		want    bool
+	}{
+		{
+			name:    "cluster without control plane ref",
+			cluster: newCluster(""),
+			want:    false,
+		},
+		{
+			name:    "control plane does not exist",
+			cluster: newCluster("does-not-exist"),
+			want:    false,
+		},
+		{
+			name:    "control plane not externally managed",
+			cluster: newCluster("not-managed"),
+			want:    false,
+		},
+		{
+			name:    "control plane externally managed",
+			cluster: newCluster("managed"),
+			want:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			c := fake.NewClientBuilder().WithObjects(managed, notManaged).Build()
+			got, err := isExternalManagedControlPlane(ctx, c, tt.cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
 func TestMachineHealthCheckHasMatchingLabels(t *testing.T) {
 	testCases := []struct {
 		name     string
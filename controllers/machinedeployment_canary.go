@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	intstrutil "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/utils/integer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileCanary reconciles the canary of the rollout creating the new MachineSet, and returns the number of
+// machines the new MachineSet is limited to while the canary has not been promoted, or nil if there is no limit.
+// It also returns the time left in the soak period, if any, so that the rollout is reconciled at its end.
+func (r *MachineDeploymentReconciler) reconcileCanary(ctx context.Context, d *clusterv1.MachineDeployment, newMS *clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet) (*int32, time.Duration, error) {
+	canary := canaryConfig(d)
+	if canary == nil || d.Spec.Replicas == nil {
+		d.Status.Canary = nil
+		return nil, 0, nil
+	}
+
+	revision := newMS.Annotations[clusterv1.RevisionAnnotation]
+	status := d.Status.Canary
+	if status == nil || status.Revision != revision {
+		// A canary applies only to rollouts replacing existing machines, not to the creation of the first MachineSet.
+		if mdutil.GetReplicaCountForMachineSets(oldMSs) == 0 {
+			d.Status.Canary = nil
+			return nil, 0, nil
+		}
+		status = &clusterv1.MachineDeploymentCanaryStatus{Revision: revision, Phase: clusterv1.MachineDeploymentCanaryPhaseProgressing}
+		d.Status.Canary = status
+	}
+	if status.Phase == clusterv1.MachineDeploymentCanaryPhasePromoted {
+		return nil, 0, nil
+	}
+
+	replicas, err := canaryReplicas(canary, *d.Spec.Replicas)
+	if err != nil {
+		return nil, 0, err
+	}
+	status.Replicas = replicas
+
+	if d.Annotations[clusterv1.PromoteCanaryAnnotation] == revision {
+		r.promoteCanary(d, status)
+		return nil, 0, nil
+	}
+
+	healthy, err := r.getCanaryHealthyReplicas(ctx, newMS)
+	if err != nil {
+		return nil, 0, err
+	}
+	status.HealthyReplicas = healthy
+	if healthy < replicas {
+		// The soak period restarts every time a canary machine becomes unhealthy.
+		status.Phase = clusterv1.MachineDeploymentCanaryPhaseProgressing
+		status.HealthySince = nil
+		return &replicas, 0, nil
+	}
+
+	if status.HealthySince == nil {
+		now := metav1.Now()
+		status.HealthySince = &now
+	}
+	soak := time.Duration(0)
+	if canary.SoakSeconds != nil {
+		soak = time.Duration(*canary.SoakSeconds) * time.Second
+	}
+	if remaining := time.Until(status.HealthySince.Add(soak)); remaining > 0 {
+		status.Phase = clusterv1.MachineDeploymentCanaryPhaseSoaking
+		return &replicas, remaining, nil
+	}
+
+	if canary.ManualPromotion {
+		if status.Phase != clusterv1.MachineDeploymentCanaryPhaseWaitingForPromotion {
+			// Record the event only once, when the MachineDeployment starts waiting for the promotion.
+			r.recorder.Eventf(d, corev1.EventTypeNormal, "WaitingForCanaryPromotion", "Canary of revision %s has soaked, waiting for %s to be set to %s", revision, clusterv1.PromoteCanaryAnnotation, revision)
+		}
+		status.Phase = clusterv1.MachineDeploymentCanaryPhaseWaitingForPromotion
+		return &replicas, 0, nil
+	}
+
+	r.promoteCanary(d, status)
+	return nil, 0, nil
+}
+
+func (r *MachineDeploymentReconciler) promoteCanary(d *clusterv1.MachineDeployment, status *clusterv1.MachineDeploymentCanaryStatus) {
+	status.Phase = clusterv1.MachineDeploymentCanaryPhasePromoted
+	r.recorder.Eventf(d, corev1.EventTypeNormal, "CanaryPromoted", "Promoted canary of revision %s, continuing the rollout", status.Revision)
+}
+
+// getCanaryHealthyReplicas returns the number of healthy machines of the new MachineSet, i.e. the machines which
+// are available and have not failed a MachineHealthCheck.
+func (r *MachineDeploymentReconciler) getCanaryHealthyReplicas(ctx context.Context, newMS *clusterv1.MachineSet) (int32, error) {
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(newMS.Namespace), client.MatchingLabels(newMS.Spec.Selector.MatchLabels)); err != nil {
+		return 0, errors.Wrap(err, "failed to list machines")
+	}
+
+	healthy := int32(0)
+	for i := range machines.Items {
+		m := &machines.Items[i]
+		if owner := metav1.GetControllerOf(m); owner == nil || owner.Kind != "MachineSet" || owner.Name != newMS.Name {
+			continue
+		}
+		if !m.DeletionTimestamp.IsZero() || !conditions.IsTrue(m, clusterv1.ReadyCondition) || conditions.IsFalse(m, clusterv1.MachineHealthCheckSuccededCondition) {
+			continue
+		}
+		healthy++
+	}
+	// Machines are available only once they have been ready for the deployment's MinReadySeconds.
+	return integer.Int32Min(healthy, newMS.Status.AvailableReplicas), nil
+}
+
+// canaryConfig returns the canary of the deployment's rolling update, if any.
+func canaryConfig(d *clusterv1.MachineDeployment) *clusterv1.MachineDeploymentCanary {
+	if !mdutil.IsRollingUpdate(d) || d.Spec.Strategy.RollingUpdate == nil {
+		return nil
+	}
+	return d.Spec.Strategy.RollingUpdate.Canary
+}
+
+// canaryReplicas returns the number of canary machines for the given desired replicas, rounding percentages up;
+// it is at least 1 and at most the desired replicas.
+func canaryReplicas(canary *clusterv1.MachineDeploymentCanary, desired int32) (int32, error) {
+	replicas, err := intstrutil.GetValueFromIntOrPercent(&canary.Replicas, int(desired), true)
+	if err != nil {
+		return 0, errors.Wrap(err, "invalid canary replicas")
+	}
+	return integer.Int32Max(1, integer.Int32Min(int32(replicas), desired)), nil
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCanaryReplicas(t *testing.T) {
+	tests := []struct {
+		replicas intstr.IntOrString
+		desired  int32
+		want     int32
+	}{
+		{replicas: intstr.FromInt(2), desired: 10, want: 2},
+		{replicas: intstr.FromString("15%"), desired: 10, want: 2},
+		{replicas: intstr.FromString("1%"), desired: 10, want: 1},
+		{replicas: intstr.FromInt(5), desired: 3, want: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.replicas.String(), func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := canaryReplicas(&clusterv1.MachineDeploymentCanary{Replicas: tt.replicas}, tt.desired)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestMachineDeploymentReconcileCanary(t *testing.T) {
+	newMachine := func(name string, ready bool, healthCheckFailed bool) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"revision": "2"},
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "MachineSet", Name: "ms-2", Controller: pointer.BoolPtr(true)},
+				},
+			},
+		}
+		if ready {
+			conditions.MarkTrue(m, clusterv1.ReadyCondition)
+		}
+		if healthCheckFailed {
+			conditions.MarkFalse(m, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "")
+		}
+		return m
+	}
+	healthySince := metav1.NewTime(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name              string
+		canary            *clusterv1.MachineDeploymentCanary
+		annotations       map[string]string
+		status            *clusterv1.MachineDeploymentCanaryStatus
+		oldReplicas       int32
+		machines          []client.Object
+		expectLimit       *int32
+		expectPhase       clusterv1.MachineDeploymentCanaryPhase
+		expectSoakRequeue bool
+	}{
+		{
+			name:        "no canary",
+			oldReplicas: 3,
+		},
+		{
+			name:        "first MachineSet",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1)},
+			oldReplicas: 0,
+		},
+		{
+			name:        "canary machines not ready",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1)},
+			oldReplicas: 3,
+			machines:    []client.Object{newMachine("m-1", false, false)},
+			expectLimit: pointer.Int32Ptr(1),
+			expectPhase: clusterv1.MachineDeploymentCanaryPhaseProgressing,
+		},
+		{
+			name:        "canary machines failing a MachineHealthCheck",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1)},
+			status:      &clusterv1.MachineDeploymentCanaryStatus{Revision: "2", Phase: clusterv1.MachineDeploymentCanaryPhaseSoaking, HealthySince: &healthySince},
+			oldReplicas: 3,
+			machines:    []client.Object{newMachine("m-1", true, true)},
+			expectLimit: pointer.Int32Ptr(1),
+			expectPhase: clusterv1.MachineDeploymentCanaryPhaseProgressing,
+		},
+		{
+			name:              "canary machines soaking",
+			canary:            &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1), SoakSeconds: pointer.Int32Ptr(600)},
+			oldReplicas:       3,
+			machines:          []client.Object{newMachine("m-1", true, false)},
+			expectLimit:       pointer.Int32Ptr(1),
+			expectPhase:       clusterv1.MachineDeploymentCanaryPhaseSoaking,
+			expectSoakRequeue: true,
+		},
+		{
+			name:        "canary soaked, waiting for promotion",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1), SoakSeconds: pointer.Int32Ptr(600), ManualPromotion: true},
+			status:      &clusterv1.MachineDeploymentCanaryStatus{Revision: "2", Phase: clusterv1.MachineDeploymentCanaryPhaseSoaking, HealthySince: &healthySince},
+			oldReplicas: 3,
+			machines:    []client.Object{newMachine("m-1", true, false)},
+			expectLimit: pointer.Int32Ptr(1),
+			expectPhase: clusterv1.MachineDeploymentCanaryPhaseWaitingForPromotion,
+		},
+		{
+			name:        "canary promoted with the annotation",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1), ManualPromotion: true},
+			annotations: map[string]string{clusterv1.PromoteCanaryAnnotation: "2"},
+			status:      &clusterv1.MachineDeploymentCanaryStatus{Revision: "2", Phase: clusterv1.MachineDeploymentCanaryPhaseWaitingForPromotion},
+			oldReplicas: 3,
+			expectPhase: clusterv1.MachineDeploymentCanaryPhasePromoted,
+		},
+		{
+			name:        "annotation promoting a previous revision",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1), ManualPromotion: true},
+			annotations: map[string]string{clusterv1.PromoteCanaryAnnotation: "1"},
+			oldReplicas: 3,
+			machines:    []client.Object{newMachine("m-1", true, false)},
+			expectLimit: pointer.Int32Ptr(1),
+			expectPhase: clusterv1.MachineDeploymentCanaryPhaseWaitingForPromotion,
+		},
+		{
+			name:        "canary promoted automatically",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1)},
+			oldReplicas: 3,
+			machines:    []client.Object{newMachine("m-1", true, false)},
+			expectPhase: clusterv1.MachineDeploymentCanaryPhasePromoted,
+		},
+		{
+			name:        "canary already promoted",
+			canary:      &clusterv1.MachineDeploymentCanary{Replicas: intstr.FromInt(1)},
+			status:      &clusterv1.MachineDeploymentCanaryStatus{Revision: "2", Phase: clusterv1.MachineDeploymentCanaryPhasePromoted},
+			oldReplicas: 0,
+			expectPhase: clusterv1.MachineDeploymentCanaryPhasePromoted,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+			d := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "md", Namespace: "default", Annotations: tt.annotations},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas: pointer.Int32Ptr(3),
+					Strategy: &clusterv1.MachineDeploymentStrategy{
+						Type:          clusterv1.RollingUpdateMachineDeploymentStrategyType,
+						RollingUpdate: &clusterv1.MachineRollingUpdateDeployment{Canary: tt.canary},
+					},
+				},
+				Status: clusterv1.MachineDeploymentStatus{Canary: tt.status},
+			}
+			newMS := &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "ms-2", Namespace: "default", Annotations: map[string]string{clusterv1.RevisionAnnotation: "2"}},
+				Spec: clusterv1.MachineSetSpec{
+					Replicas: pointer.Int32Ptr(int32(len(tt.machines))),
+					Selector: metav1.LabelSelector{MatchLabels: map[string]string{"revision": "2"}},
+				},
+				Status: clusterv1.MachineSetStatus{AvailableReplicas: int32(len(tt.machines))},
+			}
+			oldMS := &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "ms-1", Namespace: "default", Annotations: map[string]string{clusterv1.RevisionAnnotation: "1"}},
+				Spec:       clusterv1.MachineSetSpec{Replicas: pointer.Int32Ptr(tt.oldReplicas)},
+			}
+
+			r := &MachineDeploymentReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.machines...).Build(),
+				recorder: record.NewFakeRecorder(32),
+			}
+			limit, soakRemaining, err := r.reconcileCanary(ctx, d, newMS, []*clusterv1.MachineSet{oldMS})
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(limit).To(Equal(tt.expectLimit))
+			g.Expect(soakRemaining > 0).To(Equal(tt.expectSoakRequeue))
+			if tt.expectPhase == "" {
+				g.Expect(d.Status.Canary).To(BeNil())
+				return
+			}
+			g.Expect(d.Status.Canary).NotTo(BeNil())
+			g.Expect(d.Status.Canary.Revision).To(Equal("2"))
+			g.Expect(d.Status.Canary.Phase).To(Equal(tt.expectPhase))
+		})
+	}
+}
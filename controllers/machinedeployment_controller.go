@@ -31,6 +31,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/defaulting"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -56,6 +57,10 @@ type MachineDeploymentReconciler struct {
 	Client           client.Client
 	WatchFilterValue string
 
+	// WebhooksDisabled applies the defaults and the validation of the admission webhooks to the MachineDeployments inside the
+	// controller, for management clusters where the webhooks are not installed.
+	WebhooksDisabled bool
+
 	recorder   record.EventRecorder
 	restConfig *rest.Config
 }
@@ -104,6 +109,7 @@ func (r *MachineDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		if apierrors.IsNotFound(err) {
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			deleteMachineDeploymentMachinesByPhase(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -134,6 +140,15 @@ func (r *MachineDeploymentReconciler) Reconcile(ctx context.Context, req ctrl.Re
 		}
 	}()
 
+	// Apply the defaults and the validation of the webhooks, when they are not installed.
+	if r.WebhooksDisabled {
+		if err := defaulting.DefaultAndValidate(deployment); err != nil {
+			log.Error(err, "Invalid MachineDeployment, waiting for it to be fixed")
+			r.recorder.Eventf(deployment, corev1.EventTypeWarning, "InvalidObject", "%v", err)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Ignore deleted MachineDeployments, this can happen when foregroundDeletion
 	// is enabled
 	if !deployment.DeletionTimestamp.IsZero() {
@@ -176,12 +191,14 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, cluster *cl
 	}
 
 	// Make sure to reconcile the external infrastructure reference.
-	if err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, &d.Spec.Template.Spec.InfrastructureRef); err != nil {
+	// Templates deleted and recreated with a different name are resolved by their template hash; updating the reference
+	// changes the MachineDeployment template, thus triggering a rollout.
+	if err := r.reconcileTemplateReference(ctx, cluster, d, &d.Spec.Template.Spec.InfrastructureRef, clusterv1.InfrastructureTemplateHashAnnotation); err != nil {
 		return ctrl.Result{}, err
 	}
 	// Make sure to reconcile the external bootstrap reference, if any.
 	if d.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
-		if err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, d.Spec.Template.Spec.Bootstrap.ConfigRef); err != nil {
+		if err := r.reconcileTemplateReference(ctx, cluster, d, d.Spec.Template.Spec.Bootstrap.ConfigRef, clusterv1.BootstrapTemplateHashAnnotation); err != nil {
 			return ctrl.Result{}, err
 		}
 	}
@@ -195,13 +212,35 @@ func (r *MachineDeploymentReconciler) reconcile(ctx context.Context, cluster *cl
 		return ctrl.Result{}, r.sync(ctx, d, msList)
 	}
 
+	// Wait for the rollout hooks before replacing existing machines; existing MachineSets are still scaled.
+	if r.reconcilePreRolloutHooks(ctx, d, msList) {
+		return ctrl.Result{}, r.sync(ctx, d, msList)
+	}
+
 	if d.Spec.Strategy.Type == clusterv1.RollingUpdateMachineDeploymentStrategyType {
-		return ctrl.Result{}, r.rolloutRolling(ctx, d, msList)
+		return r.rolloutRolling(ctx, d, msList)
 	}
 
 	return ctrl.Result{}, errors.Errorf("unexpected deployment strategy type: %s", d.Spec.Strategy.Type)
 }
 
+// reconcileTemplateReference reconciles a template reference of a MachineDeployment, rotating it if the template
+// has been recreated with a different name.
+func (r *MachineDeploymentReconciler) reconcileTemplateReference(ctx context.Context, cluster *clusterv1.Cluster, d *clusterv1.MachineDeployment, ref *corev1.ObjectReference, hashAnnotation string) error {
+	oldName := ref.Name
+	rotated, err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, d, ref, hashAnnotation, true)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			r.recorder.Eventf(d, corev1.EventTypeWarning, clusterv1.FailedTemplateResolutionReason, "Failed to resolve %s %q: %v", ref.Kind, ref.Name, err)
+		}
+		return err
+	}
+	if rotated {
+		r.recorder.Eventf(d, corev1.EventTypeNormal, "TemplateRotated", "Rotated %s reference from %q to %q", ref.Kind, oldName, ref.Name)
+	}
+	return nil
+}
+
 // getMachineSetsForDeployment returns a list of MachineSets associated with a MachineDeployment.
 func (r *MachineDeploymentReconciler) getMachineSetsForDeployment(ctx context.Context, d *clusterv1.MachineDeployment) ([]*clusterv1.MachineSet, error) {
 	log := ctrl.LoggerFrom(ctx)
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	preRolloutHookPhase  = "pre-rollout"
+	postRolloutHookPhase = "post-rollout"
+)
+
+// reconcilePreRolloutHooks returns true if the creation of a new MachineSet must wait, either for the pre-rollout hooks
+// of the new rollout or for the post-rollout hooks of the previous one to be acknowledged.
+func (r *MachineDeploymentReconciler) reconcilePreRolloutHooks(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) bool {
+	if phase, revision := pendingRolloutHook(d); phase == postRolloutHookPhase {
+		if !rolloutHooksAcknowledged(d, clusterv1.PostRolloutHookAnnotationPrefix, revision) {
+			return true
+		}
+		delete(d.Annotations, clusterv1.RolloutHookPendingAnnotation)
+	}
+
+	// Hooks apply only to rollouts replacing existing machines, not to the creation of the first MachineSet.
+	if len(msList) == 0 || mdutil.FindNewMachineSet(d, msList) != nil {
+		clearPendingRolloutHook(d, preRolloutHookPhase)
+		return false
+	}
+
+	revision := strconv.FormatInt(mdutil.MaxRevision(msList, ctrl.LoggerFrom(ctx))+1, 10)
+	if rolloutHooksAcknowledged(d, clusterv1.PreRolloutHookAnnotationPrefix, revision) {
+		clearPendingRolloutHook(d, preRolloutHookPhase)
+		return false
+	}
+	r.setPendingRolloutHook(d, preRolloutHookPhase, clusterv1.PreRolloutHookAnnotationPrefix, revision)
+	return true
+}
+
+// reconcilePostRolloutHooks returns true if the cleanup of the old MachineSets of a completed rollout must wait for
+// the post-rollout hooks to be acknowledged.
+func (r *MachineDeploymentReconciler) reconcilePostRolloutHooks(d *clusterv1.MachineDeployment, oldMSs []*clusterv1.MachineSet) bool {
+	// Hooks apply only to rollouts replacing existing machines, not to the creation of the first MachineSet.
+	if len(oldMSs) == 0 {
+		return false
+	}
+
+	revision := d.Annotations[clusterv1.RevisionAnnotation]
+	if rolloutHooksAcknowledged(d, clusterv1.PostRolloutHookAnnotationPrefix, revision) {
+		clearPendingRolloutHook(d, postRolloutHookPhase)
+		return false
+	}
+	r.setPendingRolloutHook(d, postRolloutHookPhase, clusterv1.PostRolloutHookAnnotationPrefix, revision)
+	return true
+}
+
+// rolloutHooksAcknowledged returns true if all the hooks with the given annotation prefix have been acknowledged
+// for the given revision, i.e. their value is the revision.
+func rolloutHooksAcknowledged(d *clusterv1.MachineDeployment, prefix string, revision string) bool {
+	for k, v := range d.Annotations {
+		if strings.HasPrefix(k, prefix) && v != revision {
+			return false
+		}
+	}
+	return true
+}
+
+// pendingRolloutHook returns the phase and the revision documented by the rollout-hook-pending annotation, if any.
+func pendingRolloutHook(d *clusterv1.MachineDeployment) (string, string) {
+	s := strings.SplitN(d.Annotations[clusterv1.RolloutHookPendingAnnotation], "/", 2)
+	if len(s) != 2 {
+		return "", ""
+	}
+	return s[0], s[1]
+}
+
+func (r *MachineDeploymentReconciler) setPendingRolloutHook(d *clusterv1.MachineDeployment, phase, prefix, revision string) {
+	value := fmt.Sprintf("%s/%s", phase, revision)
+	if d.Annotations[clusterv1.RolloutHookPendingAnnotation] == value {
+		return
+	}
+	if d.Annotations == nil {
+		d.Annotations = map[string]string{}
+	}
+	d.Annotations[clusterv1.RolloutHookPendingAnnotation] = value
+
+	// Record the event only once, when the MachineDeployment starts waiting for the hooks.
+	r.recorder.Eventf(d, corev1.EventTypeNormal, "WaitingForRolloutHooks", "Waiting for %s hooks %v to be acknowledged for revision %s", phase, deleteHookNames(prefix, d.Annotations), revision)
+}
+
+func clearPendingRolloutHook(d *clusterv1.MachineDeployment, phase string) {
+	if p, _ := pendingRolloutHook(d); p == phase {
+		delete(d.Annotations, clusterv1.RolloutHookPendingAnnotation)
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestMachineDeploymentReconcilePreRolloutHooks(t *testing.T) {
+	newMachineSet := func(version string) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations: map[string]string{clusterv1.RevisionAnnotation: "1"},
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{Version: pointer.StringPtr(version)},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		annotations        map[string]string
+		machineSets        []*clusterv1.MachineSet
+		expectWait         bool
+		expectPendingValue string
+	}{
+		{
+			name:        "first MachineSet",
+			annotations: map[string]string{clusterv1.PreRolloutHookAnnotationPrefix + "/smoke-test": ""},
+			expectWait:  false,
+		},
+		{
+			name:        "no rollout",
+			annotations: map[string]string{clusterv1.PreRolloutHookAnnotationPrefix + "/smoke-test": ""},
+			machineSets: []*clusterv1.MachineSet{newMachineSet("v1.20.0")},
+			expectWait:  false,
+		},
+		{
+			name:        "rollout without hooks",
+			machineSets: []*clusterv1.MachineSet{newMachineSet("v1.19.0")},
+			expectWait:  false,
+		},
+		{
+			name:               "rollout waiting for hooks",
+			annotations:        map[string]string{clusterv1.PreRolloutHookAnnotationPrefix + "/smoke-test": "1"},
+			machineSets:        []*clusterv1.MachineSet{newMachineSet("v1.19.0")},
+			expectWait:         true,
+			expectPendingValue: "pre-rollout/2",
+		},
+		{
+			name: "rollout with acknowledged hooks",
+			annotations: map[string]string{
+				clusterv1.PreRolloutHookAnnotationPrefix + "/smoke-test": "2",
+				clusterv1.RolloutHookPendingAnnotation:                   "pre-rollout/2",
+			},
+			machineSets: []*clusterv1.MachineSet{newMachineSet("v1.19.0")},
+			expectWait:  false,
+		},
+		{
+			name: "rollout waiting for the post-rollout hooks of the previous one",
+			annotations: map[string]string{
+				clusterv1.PostRolloutHookAnnotationPrefix + "/smoke-test": "",
+				clusterv1.RolloutHookPendingAnnotation:                    "post-rollout/1",
+			},
+			machineSets:        []*clusterv1.MachineSet{newMachineSet("v1.19.0")},
+			expectWait:         true,
+			expectPendingValue: "post-rollout/1",
+		},
+		{
+			name: "rollout after the post-rollout hooks of the previous one are acknowledged",
+			annotations: map[string]string{
+				clusterv1.PostRolloutHookAnnotationPrefix + "/smoke-test": "1",
+				clusterv1.RolloutHookPendingAnnotation:                    "post-rollout/1",
+			},
+			machineSets: []*clusterv1.MachineSet{newMachineSet("v1.19.0")},
+			expectWait:  false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			d := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Template: clusterv1.MachineTemplateSpec{
+						Spec: clusterv1.MachineSpec{Version: pointer.StringPtr("v1.20.0")},
+					},
+				},
+			}
+			r := &MachineDeploymentReconciler{recorder: record.NewFakeRecorder(32)}
+
+			g.Expect(r.reconcilePreRolloutHooks(context.Background(), d, tt.machineSets)).To(Equal(tt.expectWait))
+			g.Expect(d.Annotations[clusterv1.RolloutHookPendingAnnotation]).To(Equal(tt.expectPendingValue))
+		})
+	}
+}
+
+func TestMachineDeploymentReconcilePostRolloutHooks(t *testing.T) {
+	tests := []struct {
+		name               string
+		annotations        map[string]string
+		oldMachineSets     []*clusterv1.MachineSet
+		expectWait         bool
+		expectPendingValue string
+	}{
+		{
+			name:        "first MachineSet",
+			annotations: map[string]string{clusterv1.PostRolloutHookAnnotationPrefix + "/smoke-test": ""},
+			expectWait:  false,
+		},
+		{
+			name:               "rollout waiting for hooks",
+			annotations:        map[string]string{clusterv1.PostRolloutHookAnnotationPrefix + "/smoke-test": "1"},
+			oldMachineSets:     []*clusterv1.MachineSet{{}},
+			expectWait:         true,
+			expectPendingValue: "post-rollout/2",
+		},
+		{
+			name: "rollout with acknowledged hooks",
+			annotations: map[string]string{
+				clusterv1.PostRolloutHookAnnotationPrefix + "/smoke-test": "2",
+				clusterv1.RolloutHookPendingAnnotation:                    "post-rollout/2",
+			},
+			oldMachineSets: []*clusterv1.MachineSet{{}},
+			expectWait:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			d := &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{Annotations: tt.annotations},
+			}
+			d.Annotations[clusterv1.RevisionAnnotation] = "2"
+			r := &MachineDeploymentReconciler{recorder: record.NewFakeRecorder(32)}
+
+			g.Expect(r.reconcilePostRolloutHooks(d, tt.oldMachineSets)).To(Equal(tt.expectWait))
+			g.Expect(d.Annotations[clusterv1.RolloutHookPendingAnnotation]).To(Equal(tt.expectPendingValue))
+		})
+	}
+}
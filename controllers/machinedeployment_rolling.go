@@ -21,56 +21,65 @@ import (
 	"sort"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/integer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // rolloutRolling implements the logic for rolling a new machine set.
-func (r *MachineDeploymentReconciler) rolloutRolling(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) error {
+func (r *MachineDeploymentReconciler) rolloutRolling(ctx context.Context, d *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) (ctrl.Result, error) {
 	newMS, oldMSs, err := r.getAllMachineSetsAndSyncRevision(ctx, d, msList, true)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
 	// newMS can be nil in case there is already a MachineSet associated with this deployment,
 	// but there are only either changes in annotations or MinReadySeconds. Or in other words,
 	// this can be nil if there are changes, but no replacement of existing machines is needed.
 	if newMS == nil {
-		return nil
+		return ctrl.Result{}, nil
+	}
+
+	// The new MachineSet is limited to the canary machines until the canary is promoted.
+	canaryReplicas, soakRemaining, err := r.reconcileCanary(ctx, d, newMS, oldMSs)
+	if err != nil {
+		return ctrl.Result{}, err
 	}
 
 	allMSs := append(oldMSs, newMS)
 
 	// Scale up, if we can.
-	if err := r.reconcileNewMachineSet(ctx, allMSs, newMS, d); err != nil {
-		return err
+	if err := r.reconcileNewMachineSet(ctx, allMSs, newMS, d, canaryReplicas); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
 	// Scale down, if we can.
-	if err := r.reconcileOldMachineSets(ctx, allMSs, oldMSs, newMS, d); err != nil {
-		return err
+	if err := r.reconcileOldMachineSets(ctx, allMSs, oldMSs, newMS, d, canaryReplicas); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	if err := r.syncDeploymentStatus(allMSs, newMS, d); err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
-	if mdutil.DeploymentComplete(d, &d.Status) {
+	// Old MachineSets are kept until the post-rollout hooks are acknowledged, e.g. to allow rolling back.
+	if mdutil.DeploymentComplete(d, &d.Status) && !r.reconcilePostRolloutHooks(d, oldMSs) {
 		if err := r.cleanupDeployment(ctx, oldMSs, d); err != nil {
-			return err
+			return ctrl.Result{}, err
 		}
 	}
 
-	return nil
+	return ctrl.Result{RequeueAfter: soakRemaining}, nil
 }
 
-func (r *MachineDeploymentReconciler) reconcileNewMachineSet(ctx context.Context, allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+func (r *MachineDeploymentReconciler) reconcileNewMachineSet(ctx context.Context, allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment, canaryReplicas *int32) error {
 	if deployment.Spec.Replicas == nil {
 		return errors.Errorf("spec replicas for deployment set %v is nil, this is unexpected", deployment.Name)
 	}
@@ -94,11 +103,15 @@ func (r *MachineDeploymentReconciler) reconcileNewMachineSet(ctx context.Context
 	if err != nil {
 		return err
 	}
+	if canaryReplicas != nil {
+		// Do not scale up beyond the canary, but do not scale down a MachineSet already larger than the canary either.
+		newReplicasCount = integer.Int32Max(*(newMS.Spec.Replicas), integer.Int32Min(newReplicasCount, *canaryReplicas))
+	}
 	err = r.scaleMachineSet(ctx, newMS, newReplicasCount, deployment)
 	return err
 }
 
-func (r *MachineDeploymentReconciler) reconcileOldMachineSets(ctx context.Context, allMSs []*clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) error {
+func (r *MachineDeploymentReconciler) reconcileOldMachineSets(ctx context.Context, allMSs []*clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, deployment *clusterv1.MachineDeployment, canaryReplicas *int32) error {
 	log := ctrl.LoggerFrom(ctx)
 
 	if deployment.Spec.Replicas == nil {
@@ -155,6 +168,13 @@ func (r *MachineDeploymentReconciler) reconcileOldMachineSets(ctx context.Contex
 	minAvailable := *(deployment.Spec.Replicas) - maxUnavailable
 	newMSUnavailableMachineCount := *(newMS.Spec.Replicas) - newMS.Status.AvailableReplicas
 	maxScaledDown := allMachinesCount - minAvailable - newMSUnavailableMachineCount
+
+	// While the canary has not been promoted, old machine sets are scaled down only to make room for the canary.
+	minOldMachinesCount := int32(0)
+	if canaryReplicas != nil {
+		minOldMachinesCount = integer.Int32Max(0, *(deployment.Spec.Replicas)-*canaryReplicas)
+		maxScaledDown = integer.Int32Min(maxScaledDown, oldMachinesCount-minOldMachinesCount)
+	}
 	if maxScaledDown <= 0 {
 		return nil
 	}
@@ -171,7 +191,7 @@ func (r *MachineDeploymentReconciler) reconcileOldMachineSets(ctx context.Contex
 	// Scale down old machine sets, need check maxUnavailable to ensure we can scale down
 	allMSs = oldMSs
 	allMSs = append(allMSs, newMS)
-	scaledDownCount, err := r.scaleDownOldMachineSetsForRollingUpdate(ctx, allMSs, oldMSs, deployment)
+	scaledDownCount, err := r.scaleDownOldMachineSetsForRollingUpdate(ctx, allMSs, oldMSs, deployment, minOldMachinesCount)
 	if err != nil {
 		return err
 	}
@@ -232,9 +252,10 @@ func (r *MachineDeploymentReconciler) cleanupUnhealthyReplicas(ctx context.Conte
 	return oldMSs, totalScaledDown, nil
 }
 
-// scaleDownOldMachineSetsForRollingUpdate scales down old machine sets when deployment strategy is "RollingUpdate".
+// scaleDownOldMachineSetsForRollingUpdate scales down old machine sets when deployment strategy is "RollingUpdate",
+// keeping at least minOldMachinesCount machines in the old machine sets.
 // Need check maxUnavailable to ensure availability
-func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(ctx context.Context, allMSs []*clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment) (int32, error) {
+func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(ctx context.Context, allMSs []*clusterv1.MachineSet, oldMSs []*clusterv1.MachineSet, deployment *clusterv1.MachineDeployment, minOldMachinesCount int32) (int32, error) {
 	log := ctrl.LoggerFrom(ctx)
 
 	if deployment.Spec.Replicas == nil {
@@ -257,8 +278,16 @@ func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(ct
 
 	sort.Sort(mdutil.MachineSetsByCreationTimestamp(oldMSs))
 
+	// Scale down first the MachineSets owning Machines marked for deletion, so marked Machines are removed first
+	// no matter which rollout created them.
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(deployment.Namespace), client.MatchingLabels{clusterv1.MachineDeploymentLabelName: deployment.Name}); err != nil {
+		return 0, errors.Wrap(err, "failed to list machines")
+	}
+	sortMachineSetsWithMarkedMachinesFirst(oldMSs, machines.Items)
+
 	totalScaledDown := int32(0)
-	totalScaleDownCount := availableMachineCount - minAvailable
+	totalScaleDownCount := integer.Int32Min(availableMachineCount-minAvailable, mdutil.GetReplicaCountForMachineSets(oldMSs)-minOldMachinesCount)
 	for _, targetMS := range oldMSs {
 		if targetMS.Spec.Replicas == nil {
 			return 0, errors.Errorf("spec replicas for machine set %v is nil, this is unexpected", targetMS.Name)
@@ -290,3 +319,21 @@ func (r *MachineDeploymentReconciler) scaleDownOldMachineSetsForRollingUpdate(ct
 
 	return totalScaledDown, nil
 }
+
+// sortMachineSetsWithMarkedMachinesFirst sorts the MachineSets owning Machines marked for deletion with the
+// DeleteMachineAnnotation first, preserving the existing order otherwise.
+func sortMachineSetsWithMarkedMachinesFirst(msList []*clusterv1.MachineSet, machines []clusterv1.Machine) {
+	marked := map[string]bool{}
+	for i := range machines {
+		m := &machines[i]
+		if _, ok := m.Annotations[clusterv1.DeleteMachineAnnotation]; !ok || !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if owner := metav1.GetControllerOf(m); owner != nil && owner.Kind == "MachineSet" {
+			marked[owner.Name] = true
+		}
+	}
+	sort.SliceStable(msList, func(i, j int) bool {
+		return marked[msList[i].Name] && !marked[msList[j].Name]
+	})
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestSortMachineSetsWithMarkedMachinesFirst(t *testing.T) {
+	now := metav1.Now()
+	newMachine := func(msName string, deletionTimestamp *metav1.Time, annotations map[string]string) clusterv1.Machine {
+		return clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Annotations:       annotations,
+				DeletionTimestamp: deletionTimestamp,
+				OwnerReferences: []metav1.OwnerReference{
+					{Kind: "MachineSet", Name: msName, Controller: pointer.BoolPtr(true)},
+				},
+			},
+		}
+	}
+	marked := map[string]string{clusterv1.DeleteMachineAnnotation: ""}
+
+	tests := []struct {
+		name     string
+		machines []clusterv1.Machine
+		want     []string
+	}{
+		{
+			name:     "no marked machines",
+			machines: []clusterv1.Machine{newMachine("ms-1", nil, nil), newMachine("ms-3", nil, nil)},
+			want:     []string{"ms-1", "ms-2", "ms-3"},
+		},
+		{
+			name:     "marked machines in newer MachineSets",
+			machines: []clusterv1.Machine{newMachine("ms-1", nil, nil), newMachine("ms-2", nil, marked), newMachine("ms-3", nil, marked)},
+			want:     []string{"ms-2", "ms-3", "ms-1"},
+		},
+		{
+			name:     "marked machines already deleting are ignored",
+			machines: []clusterv1.Machine{newMachine("ms-3", &now, marked)},
+			want:     []string{"ms-1", "ms-2", "ms-3"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			msList := []*clusterv1.MachineSet{
+				{ObjectMeta: metav1.ObjectMeta{Name: "ms-1"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "ms-2"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "ms-3"}},
+			}
+			sortMachineSetsWithMarkedMachinesFirst(msList, tt.machines)
+
+			got := []string{}
+			for _, ms := range msList {
+				got = append(got, ms.Name)
+			}
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
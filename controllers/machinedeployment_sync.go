@@ -19,11 +19,13 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sort"
 	"strconv"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	apirand "k8s.io/apimachinery/pkg/util/rand"
@@ -32,6 +34,7 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/operations"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -114,8 +117,24 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 
 		minReadySecondsNeedsUpdate := msCopy.Spec.MinReadySeconds != *d.Spec.MinReadySeconds
 		deletePolicyNeedsUpdate := d.Spec.Strategy.RollingUpdate.DeletePolicy != nil && msCopy.Spec.DeletePolicy != *d.Spec.Strategy.RollingUpdate.DeletePolicy
-		if annotationsUpdated || minReadySecondsNeedsUpdate || deletePolicyNeedsUpdate {
+		provisioningConcurrencyNeedsUpdate := !reflect.DeepEqual(msCopy.Spec.ProvisioningConcurrency, d.Spec.ProvisioningConcurrency)
+		namingTemplateNeedsUpdate := msCopy.Spec.NamingTemplate != d.Spec.NamingTemplate
+		templateMetadataPolicyNeedsUpdate := !reflect.DeepEqual(msCopy.Spec.TemplateMetadataPolicy, d.Spec.TemplateMetadataPolicy)
+		infrastructureDeletedPolicyNeedsUpdate := msCopy.Spec.InfrastructureDeletedPolicy != d.Spec.InfrastructureDeletedPolicy
+
+		// Propagate the in-place mutable fields of the machine template, which do not trigger a rollout;
+		// the MachineSet propagates them to its Machines.
+		template := msCopy.Spec.Template.DeepCopy()
+		mdutil.CopyInPlaceMutableFields(template, &d.Spec.Template)
+		templateNeedsUpdate := !apiequality.Semantic.DeepEqual(template, &msCopy.Spec.Template)
+
+		if annotationsUpdated || minReadySecondsNeedsUpdate || deletePolicyNeedsUpdate || provisioningConcurrencyNeedsUpdate || namingTemplateNeedsUpdate || templateMetadataPolicyNeedsUpdate || infrastructureDeletedPolicyNeedsUpdate || templateNeedsUpdate {
 			msCopy.Spec.MinReadySeconds = *d.Spec.MinReadySeconds
+			msCopy.Spec.ProvisioningConcurrency = d.Spec.ProvisioningConcurrency
+			msCopy.Spec.NamingTemplate = d.Spec.NamingTemplate
+			msCopy.Spec.TemplateMetadataPolicy = d.Spec.TemplateMetadataPolicy
+			msCopy.Spec.InfrastructureDeletedPolicy = d.Spec.InfrastructureDeletedPolicy
+			msCopy.Spec.Template = *template
 
 			if deletePolicyNeedsUpdate {
 				msCopy.Spec.DeletePolicy = *d.Spec.Strategy.RollingUpdate.DeletePolicy
@@ -160,11 +179,15 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 			OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(d, machineDeploymentKind)},
 		},
 		Spec: clusterv1.MachineSetSpec{
-			ClusterName:     d.Spec.ClusterName,
-			Replicas:        new(int32),
-			MinReadySeconds: minReadySeconds,
-			Selector:        *newMSSelector,
-			Template:        newMSTemplate,
+			ClusterName:                 d.Spec.ClusterName,
+			Replicas:                    new(int32),
+			MinReadySeconds:             minReadySeconds,
+			ProvisioningConcurrency:     d.Spec.ProvisioningConcurrency,
+			NamingTemplate:              d.Spec.NamingTemplate,
+			TemplateMetadataPolicy:      d.Spec.TemplateMetadataPolicy,
+			InfrastructureDeletedPolicy: d.Spec.InfrastructureDeletedPolicy,
+			Selector:                    *newMSSelector,
+			Template:                    newMSTemplate,
 		},
 	}
 
@@ -218,6 +241,7 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 	case err != nil:
 		log.Error(err, "Failed to create new machine set", "machineset", newMS.Name)
 		r.recorder.Eventf(d, corev1.EventTypeWarning, "FailedCreate", "Failed to create MachineSet %q: %v", newMS.Name, err)
+		operations.Failed(d, "Create MachineSet", "Failed to create MachineSet %q: %v", newMS.Name, err)
 		return nil, err
 	}
 
@@ -230,6 +254,11 @@ func (r *MachineDeploymentReconciler) getNewMachineSet(ctx context.Context, d *c
 		mdutil.SetDeploymentRevision(d, newRevision)
 	})
 
+	// NB. The operation is recorded after updating the MachineDeployment, which reads it again from the API server.
+	if !alreadyExists {
+		operations.Succeeded(d, "Create MachineSet", "Created MachineSet %q", newMS.Name)
+	}
+
 	return createdMS, err
 }
 
@@ -353,6 +382,7 @@ func (r *MachineDeploymentReconciler) scale(ctx context.Context, deployment *clu
 // syncDeploymentStatus checks if the status is up-to-date and sync it if necessary
 func (r *MachineDeploymentReconciler) syncDeploymentStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet, d *clusterv1.MachineDeployment) error {
 	d.Status = calculateStatus(allMSs, newMS, d)
+	recordMachineDeploymentMachinesByPhase(d, d.Status.MachinesByPhase)
 	return nil
 }
 
@@ -380,6 +410,13 @@ func calculateStatus(allMSs []*clusterv1.MachineSet, newMS *clusterv1.MachineSet
 		ReadyReplicas:       mdutil.GetReadyReplicaCountForMachineSets(allMSs),
 		AvailableReplicas:   availableReplicas,
 		UnavailableReplicas: unavailableReplicas,
+		OperationHistory:    deployment.Status.OperationHistory,
+		Canary:              deployment.Status.Canary,
+	}
+	for _, ms := range allMSs {
+		if ms != nil {
+			status.MachinesByPhase.Add(ms.Status.MachinesByPhase)
+		}
 	}
 
 	if *deployment.Spec.Replicas == status.ReadyReplicas {
@@ -443,14 +480,17 @@ func (r *MachineDeploymentReconciler) scaleMachineSetOperation(ctx context.Conte
 			return err
 		}
 
+		oldScale := *(ms.Spec.Replicas)
 		*(ms.Spec.Replicas) = newScale
 		mdutil.SetReplicasAnnotations(ms, *(deployment.Spec.Replicas), *(deployment.Spec.Replicas)+mdutil.MaxSurge(*deployment))
 
 		err = patchHelper.Patch(ctx, ms)
 		if err != nil {
 			r.recorder.Eventf(deployment, corev1.EventTypeWarning, "FailedScale", "Failed to scale MachineSet %q: %v", ms.Name, err)
+			operations.Failed(deployment, "Scale MachineSet", "Failed to scale %s MachineSet %q %d→%d: %v", scaleOperation, ms.Name, oldScale, newScale, err)
 		} else if sizeNeedsUpdate {
 			r.recorder.Eventf(deployment, corev1.EventTypeNormal, "SuccessfulScale", "Scaled %s MachineSet %q to %d", scaleOperation, ms.Name, newScale)
+			operations.Succeeded(deployment, "Scale MachineSet", "Scaled %s MachineSet %q %d→%d", scaleOperation, ms.Name, oldScale, newScale)
 		}
 		return err
 	}
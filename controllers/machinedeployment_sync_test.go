@@ -209,6 +209,61 @@ func TestMachineDeploymentSyncStatus(t *testing.T) {
 				Phase:               "Failed",
 			},
 		},
+		"counts the machines by phase of all the machine sets": {
+			machineSets: []*clusterv1.MachineSet{
+				{
+					Spec: clusterv1.MachineSetSpec{
+						Replicas: pointer.Int32Ptr(1),
+					},
+					Status: clusterv1.MachineSetStatus{
+						AvailableReplicas: 1,
+						ReadyReplicas:     1,
+						Replicas:          2,
+						MachinesByPhase:   clusterv1.MachinesByPhase{Running: 1, Deleting: 1},
+					},
+				},
+				{
+					Spec: clusterv1.MachineSetSpec{
+						Replicas: pointer.Int32Ptr(2),
+					},
+					Status: clusterv1.MachineSetStatus{
+						AvailableReplicas: 1,
+						ReadyReplicas:     1,
+						Replicas:          2,
+						MachinesByPhase:   clusterv1.MachinesByPhase{Provisioning: 1, Running: 1},
+					},
+				},
+			},
+			newMachineSet: &clusterv1.MachineSet{
+				Spec: clusterv1.MachineSetSpec{
+					Replicas: pointer.Int32Ptr(2),
+				},
+				Status: clusterv1.MachineSetStatus{
+					AvailableReplicas: 1,
+					ReadyReplicas:     1,
+					Replicas:          2,
+					MachinesByPhase:   clusterv1.MachinesByPhase{Provisioning: 1, Running: 1},
+				},
+			},
+			deployment: &clusterv1.MachineDeployment{
+				ObjectMeta: metav1.ObjectMeta{
+					Generation: 2,
+				},
+				Spec: clusterv1.MachineDeploymentSpec{
+					Replicas: pointer.Int32Ptr(2),
+				},
+			},
+			expectedStatus: clusterv1.MachineDeploymentStatus{
+				ObservedGeneration:  2,
+				Replicas:            4,
+				UpdatedReplicas:     2,
+				ReadyReplicas:       2,
+				AvailableReplicas:   2,
+				UnavailableReplicas: 1,
+				Phase:               "Running",
+				MachinesByPhase:     clusterv1.MachinesByPhase{Provisioning: 1, Running: 2, Deleting: 1},
+			},
+		},
 	}
 
 	for name, test := range tests {
@@ -40,6 +40,7 @@ import (
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/defaulting"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -56,6 +57,10 @@ const (
 	// EventRemediationRestricted is emitted in case when machine remediation
 	// is restricted by remediation circuit shorting logic
 	EventRemediationRestricted string = "RemediationRestricted"
+
+	// EventMachineWouldBeRemediated is emitted when an unhealthy machine is not remediated because the
+	// MachineHealthCheck is in the Observe remediation mode.
+	EventMachineWouldBeRemediated string = "MachineWouldBeRemediated"
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
@@ -69,6 +74,10 @@ type MachineHealthCheckReconciler struct {
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
+	// WebhooksDisabled applies the defaults and the validation of the admission webhooks to the MachineHealthChecks inside the
+	// controller, for management clusters where the webhooks are not installed.
+	WebhooksDisabled bool
+
 	controller controller.Controller
 	recorder   record.EventRecorder
 }
@@ -89,8 +98,12 @@ func (r *MachineHealthCheckReconciler) SetupWithManager(ctx context.Context, mgr
 	err = controller.Watch(
 		&source.Kind{Type: &clusterv1.Cluster{}},
 		handler.EnqueueRequestsFromMapFunc(r.clusterToMachineHealthCheck),
-		// TODO: should this wait for Cluster.Status.InfrastructureReady similar to Infra Machine resources?
-		predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
+		// Resume the health checks skipped while the Cluster was paused or short-circuited while it was not ready.
+		predicates.Any(ctrl.LoggerFrom(ctx),
+			predicates.ClusterUnpaused(ctrl.LoggerFrom(ctx)),
+			predicates.ClusterUpdateInfraReady(ctrl.LoggerFrom(ctx)),
+			predicates.ClusterUpdateControlPlaneInitialized(ctrl.LoggerFrom(ctx)),
+		),
 	)
 	if err != nil {
 		return errors.Wrap(err, "failed to add Watch for Clusters to controller manager")
@@ -109,6 +122,7 @@ func (r *MachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.R
 	m := &clusterv1.MachineHealthCheck{}
 	if err := r.Client.Get(ctx, req.NamespacedName, m); err != nil {
 		if apierrors.IsNotFound(err) {
+			deleteMachineHealthCheckObservedUnhealthy(req.NamespacedName)
 			// Object not found, return.  Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
 			return ctrl.Result{}, nil
@@ -128,7 +142,8 @@ func (r *MachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return ctrl.Result{}, err
 	}
 
-	// Return early if the object or Cluster is paused.
+	// Return early if the object or Cluster is paused; the status is not written either, given that a paused
+	// Cluster could be being moved to another management cluster.
 	if annotations.IsPaused(cluster, m) {
 		log.Info("Reconciliation is paused for this object")
 		return ctrl.Result{}, nil
@@ -153,6 +168,15 @@ func (r *MachineHealthCheckReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}()
 
+	// Apply the defaults and the validation of the webhooks, when they are not installed.
+	if r.WebhooksDisabled {
+		if err := defaulting.DefaultAndValidate(m); err != nil {
+			log.Error(err, "Invalid MachineHealthCheck, waiting for it to be fixed")
+			r.recorder.Eventf(m, corev1.EventTypeWarning, "InvalidObject", "%v", err)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Reconcile labels.
 	if m.Labels == nil {
 		m.Labels = make(map[string]string)
@@ -180,6 +204,15 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		UID:        cluster.UID,
 	})
 
+	// Do not check the health of the Machines while the Cluster is not able to run them, e.g. during the cluster
+	// creation, to avoid remediating Machines that could not become healthy yet.
+	if reason, message := remediationShortCircuit(cluster); reason != "" {
+		logger.V(3).Info("Short-circuiting the health checks", "reason", reason)
+		m.Status.RemediationsAllowed = 0
+		conditions.MarkFalse(m, clusterv1.RemediationAllowedCondition, reason, clusterv1.ConditionSeverityInfo, message)
+		return ctrl.Result{}, nil
+	}
+
 	// Get the remote cluster cache to use as a client.Reader.
 	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
 	if err != nil {
@@ -192,9 +225,17 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 		return ctrl.Result{}, err
 	}
 
+	// Control plane Machines are not expected when the control plane is externally managed (e.g. AKS, EKS, GKE),
+	// so they should never be considered as targets.
+	externalManagedControlPlane, err := isExternalManagedControlPlane(ctx, r.Client, cluster)
+	if err != nil {
+		logger.Error(err, "error checking if the control plane is externally managed")
+		return ctrl.Result{}, err
+	}
+
 	// fetch all targets
 	logger.V(3).Info("Finding targets")
-	targets, err := r.getTargetsFromMHC(ctx, remoteClient, m)
+	targets, err := r.getTargetsFromMHC(ctx, remoteClient, m, externalManagedControlPlane)
 	if err != nil {
 		logger.Error(err, "Failed to fetch targets from MachineHealthCheck")
 		return ctrl.Result{}, err
@@ -207,6 +248,10 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	}
 	// do sort to avoid keep changing m.Status as the returned machines are not in order
 	sort.Strings(m.Status.Targets)
+	m.Status.ObservedUnhealthyTargets = nil
+	if !m.IsObserveOnly() {
+		deleteMachineHealthCheckObservedUnhealthy(util.ObjectKey(m))
+	}
 
 	// health check all targets and reconcile mhc status
 	healthy, unhealthy, nextCheckTimes := r.healthCheckTargets(targets, logger, m.Spec.NodeStartupTimeout.Duration)
@@ -228,6 +273,9 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 
 		// Remediation not allowed, the number of not started or unhealthy machines exceeds maxUnhealthy
 		m.Status.RemediationsAllowed = 0
+		if m.IsObserveOnly() {
+			recordMachineHealthCheckObservedUnhealthy(m, 0)
+		}
 		conditions.Set(m, &clusterv1.Condition{
 			Type:     clusterv1.RemediationAllowedCondition,
 			Status:   corev1.ConditionFalse,
@@ -271,7 +319,25 @@ func (r *MachineHealthCheckReconciler) reconcile(ctx context.Context, logger log
 	m.Status.RemediationsAllowed = int32(maxUnhealthy - unhealthyMachineCount(m))
 	conditions.MarkTrue(m, clusterv1.RemediationAllowedCondition)
 
-	errList := r.PatchUnhealthyTargets(ctx, logger, unhealthy, cluster, m)
+	var errList []error
+	if m.IsObserveOnly() {
+		// Report the unhealthy machines without remediating them.
+		errList = r.patchObservedUnhealthyTargets(ctx, logger, unhealthy, m)
+	} else {
+		// Ensure unhealthy control plane machines are remediated one at a time, and only when the control plane allows it.
+		var deferred []healthCheckTarget
+		unhealthy, deferred, err = r.reconcileControlPlaneRemediation(ctx, logger, cluster, unhealthy)
+		if err != nil {
+			logger.Error(err, "Failed to reconcile control plane remediation")
+			return ctrl.Result{}, err
+		}
+		if len(deferred) > 0 {
+			nextCheckTimes = append(nextCheckTimes, controlPlaneRemediationRequeueAfter)
+		}
+
+		errList = r.PatchUnhealthyTargets(ctx, logger, unhealthy, cluster, m)
+		errList = append(errList, r.patchDeferredTargets(ctx, deferred, m)...)
+	}
 	errList = append(errList, r.PatchHealthyTargets(ctx, logger, healthy, cluster, m)...)
 
 	// handle update errors
@@ -405,6 +471,34 @@ func (r *MachineHealthCheckReconciler) PatchUnhealthyTargets(ctx context.Context
 	return errList
 }
 
+// patchObservedUnhealthyTargets patches the unhealthy machines of a MachineHealthCheck in the Observe remediation mode,
+// reporting them as machines which would be remediated without marking them for remediation.
+func (r *MachineHealthCheckReconciler) patchObservedUnhealthyTargets(ctx context.Context, logger logr.Logger, unhealthy []healthCheckTarget, m *clusterv1.MachineHealthCheck) []error {
+	errList := []error{}
+	for _, t := range unhealthy {
+		condition := conditions.Get(t.Machine, clusterv1.MachineHealthCheckSuccededCondition)
+		logger.Info("Target has failed health check, but remediation is in Observe mode so skipping remediation", "target", t.string(), "reason", condition.Reason, "message", condition.Message)
+
+		m.Status.ObservedUnhealthyTargets = append(m.Status.ObservedUnhealthyTargets, t.Machine.Name)
+		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch unhealthy machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
+			continue
+		}
+		r.recorder.Eventf(
+			t.Machine,
+			corev1.EventTypeWarning,
+			EventMachineWouldBeRemediated,
+			"Machine %v would be remediated by MachineHealthCheck %s: %s",
+			t.string(),
+			m.Name,
+			condition.Message,
+		)
+	}
+	sort.Strings(m.Status.ObservedUnhealthyTargets)
+	recordMachineHealthCheckObservedUnhealthy(m, len(m.Status.ObservedUnhealthyTargets))
+	return errList
+}
+
 // clusterToMachineHealthCheck maps events from Cluster objects to
 // MachineHealthCheck objects that belong to the Cluster
 func (r *MachineHealthCheckReconciler) clusterToMachineHealthCheck(o client.Object) []reconcile.Request {
@@ -535,6 +629,18 @@ func isAllowedRemediation(mhc *clusterv1.MachineHealthCheck) bool {
 	return unhealthyMachineCount(mhc) <= maxUnhealthy
 }
 
+// remediationShortCircuit returns the reason and the message of the RemediationAllowed condition if the health of
+// the Machines of the Cluster must not be checked, or an empty reason otherwise.
+func remediationShortCircuit(cluster *clusterv1.Cluster) (string, string) {
+	switch {
+	case !cluster.Status.InfrastructureReady:
+		return clusterv1.ClusterInfrastructureNotReadyReason, "Remediation is not allowed until the Cluster infrastructure is ready"
+	case !cluster.Status.ControlPlaneInitialized:
+		return clusterv1.ControlPlaneNotInitializedReason, "Remediation is not allowed until the Cluster control plane is initialized"
+	}
+	return "", ""
+}
+
 func getMaxUnhealthy(mhc *clusterv1.MachineHealthCheck) (int, error) {
 	if mhc.Spec.MaxUnhealthy == nil {
 		return 0, errors.New("spec.maxUnhealthy must be set")
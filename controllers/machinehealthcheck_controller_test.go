@@ -26,6 +26,7 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -1751,6 +1752,95 @@ func TestIsAllowedRemediation(t *testing.T) {
 	}
 }
 
+func TestMachineHealthCheckRemediationShortCircuit(t *testing.T) {
+	testCases := []struct {
+		name          string
+		status        clusterv1.ClusterStatus
+		expectedState *clusterv1.Condition
+	}{
+		{
+			name:          "when the Cluster infrastructure is not ready",
+			status:        clusterv1.ClusterStatus{},
+			expectedState: conditions.FalseCondition(clusterv1.RemediationAllowedCondition, clusterv1.ClusterInfrastructureNotReadyReason, clusterv1.ConditionSeverityInfo, ""),
+		},
+		{
+			name:          "when the Cluster control plane is not initialized",
+			status:        clusterv1.ClusterStatus{InfrastructureReady: true},
+			expectedState: conditions.FalseCondition(clusterv1.RemediationAllowedCondition, clusterv1.ControlPlaneNotInitializedReason, clusterv1.ConditionSeverityInfo, ""),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault},
+				Status:     tc.status,
+			}
+			mhc := &clusterv1.MachineHealthCheck{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-mhc", Namespace: metav1.NamespaceDefault},
+				Spec: clusterv1.MachineHealthCheckSpec{
+					ClusterName:  cluster.Name,
+					MaxUnhealthy: &intstr.IntOrString{Type: intstr.Int, IntVal: int32(1)},
+				},
+				Status: clusterv1.MachineHealthCheckStatus{RemediationsAllowed: 1},
+			}
+
+			// The health checks are short-circuited before accessing the workload cluster, so no Tracker is needed.
+			r := &MachineHealthCheckReconciler{
+				Client:   fake.NewClientBuilder().WithObjects(cluster, mhc).Build(),
+				recorder: record.NewFakeRecorder(32),
+			}
+
+			result, err := r.reconcile(ctx, log.Log, cluster, mhc)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(result).To(Equal(reconcile.Result{}))
+			g.Expect(mhc.Status.RemediationsAllowed).To(Equal(int32(0)))
+
+			c := conditions.Get(mhc, clusterv1.RemediationAllowedCondition)
+			g.Expect(c).NotTo(BeNil())
+			g.Expect(c.Status).To(Equal(tc.expectedState.Status))
+			g.Expect(c.Reason).To(Equal(tc.expectedState.Reason))
+			g.Expect(c.Severity).To(Equal(tc.expectedState.Severity))
+		})
+	}
+}
+
+func TestMachineHealthCheckClusterPaused(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: metav1.NamespaceDefault},
+		Spec:       clusterv1.ClusterSpec{Paused: true},
+		Status:     clusterv1.ClusterStatus{InfrastructureReady: true, ControlPlaneInitialized: true},
+	}
+	mhc := &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-mhc", Namespace: metav1.NamespaceDefault},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			ClusterName:  cluster.Name,
+			MaxUnhealthy: &intstr.IntOrString{Type: intstr.Int, IntVal: int32(1)},
+		},
+		Status: clusterv1.MachineHealthCheckStatus{RemediationsAllowed: 1},
+	}
+
+	r := &MachineHealthCheckReconciler{
+		Client:   fake.NewClientBuilder().WithObjects(cluster, mhc).Build(),
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: util.ObjectKey(mhc)})
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(result).To(Equal(reconcile.Result{}))
+
+	// Neither the MachineHealthCheck nor its status are written while the Cluster is paused.
+	got := &clusterv1.MachineHealthCheck{}
+	g.Expect(r.Client.Get(ctx, util.ObjectKey(mhc), got)).To(Succeed())
+	g.Expect(got.Labels).NotTo(HaveKey(clusterv1.ClusterLabelName))
+	g.Expect(got.Status.RemediationsAllowed).To(Equal(int32(1)))
+	g.Expect(got.Status.Conditions).To(BeEmpty())
+}
+
 func TestGetMaxUnhealthy(t *testing.T) {
 	testCases := []struct {
 		name                 string
@@ -1851,6 +1941,12 @@ func createNamespaceAndCluster(g *WithT) *clusterv1.Cluster {
 		return testEnv.Get(ctx, util.ObjectKey(cluster), &cl)
 	}, timeout, 100*time.Millisecond).Should(Succeed())
 
+	// The health checks are short-circuited until the Cluster infrastructure is ready and the control plane is initialized.
+	patch := client.MergeFrom(cluster.DeepCopy())
+	cluster.Status.InfrastructureReady = true
+	cluster.Status.ControlPlaneInitialized = true
+	g.Expect(testEnv.Status().Patch(ctx, cluster, patch)).To(Succeed())
+
 	g.Expect(testEnv.CreateKubeconfigSecret(ctx, cluster)).To(Succeed())
 
 	return cluster
@@ -2163,3 +2259,58 @@ func TestPatchTargets(t *testing.T) {
 	// Target with wrong patch helper will fail but the other one will be patched.
 	g.Expect(len(r.PatchHealthyTargets(context.TODO(), log.NullLogger{}, []healthCheckTarget{target1, target3}, defaultCluster, mhc))).To(BeNumerically(">", 0))
 }
+
+func TestPatchObservedUnhealthyTargets(t *testing.T) {
+	_ = clusterv1.AddToScheme(scheme.Scheme)
+	g := NewWithT(t)
+
+	namespace := defaultNamespaceName
+	clusterName := "test-cluster"
+	labels := map[string]string{"cluster": "foo", "nodepool": "bar"}
+
+	mhc := newMachineHealthCheckWithLabels("mhc-observe", namespace, clusterName, labels)
+	mhc.Spec.Remediation = &clusterv1.MachineHealthCheckRemediation{Mode: clusterv1.MachineHealthCheckRemediationModeObserve}
+	machine1 := newTestMachine("machine1", namespace, clusterName, "nodeName", labels)
+	machine1.ResourceVersion = "1"
+	conditions.MarkFalse(machine1, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
+	machine2 := machine1.DeepCopy()
+	machine2.Name = "machine2"
+
+	cl := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		machine1,
+		machine2,
+		mhc,
+	).Build()
+	recorder := record.NewFakeRecorder(32)
+	r := &MachineHealthCheckReconciler{
+		Client:   cl,
+		recorder: recorder,
+	}
+
+	var targets []healthCheckTarget
+	for _, m := range []*clusterv1.Machine{machine2, machine1} {
+		patchHelper, err := patch.NewHelper(m, cl)
+		g.Expect(err).NotTo(HaveOccurred())
+		targets = append(targets, healthCheckTarget{
+			MHC:         mhc,
+			Machine:     m,
+			patchHelper: patchHelper,
+		})
+	}
+
+	g.Expect(r.patchObservedUnhealthyTargets(context.TODO(), log.NullLogger{}, targets, mhc)).To(BeEmpty())
+	g.Expect(mhc.Status.ObservedUnhealthyTargets).To(Equal([]string{"machine1", "machine2"}))
+	g.Expect(testutil.ToFloat64(machineHealthCheckObservedUnhealthy.WithLabelValues(mhc.Namespace, mhc.Name))).To(Equal(float64(2)))
+	g.Expect(recorder.Events).To(HaveLen(2))
+
+	// The machines are not marked for remediation.
+	for _, m := range []*clusterv1.Machine{machine1, machine2} {
+		got := &clusterv1.Machine{}
+		g.Expect(cl.Get(ctx, util.ObjectKey(m), got)).To(Succeed())
+		g.Expect(conditions.IsFalse(got, clusterv1.MachineHealthCheckSuccededCondition)).To(BeTrue())
+		g.Expect(conditions.Has(got, clusterv1.MachineOwnerRemediatedCondition)).To(BeFalse())
+	}
+
+	deleteMachineHealthCheckObservedUnhealthy(util.ObjectKey(mhc))
+	g.Expect(testutil.CollectAndCount(machineHealthCheckObservedUnhealthy)).To(Equal(0))
+}
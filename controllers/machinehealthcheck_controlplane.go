@@ -0,0 +1,213 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// EventRemediationDeferred is emitted when an unhealthy control plane machine is not marked for remediation
+	// because the control plane is not in a state that allows remediation.
+	EventRemediationDeferred string = "RemediationDeferred"
+
+	// controlPlaneRemediationRequeueAfter is how long to wait before checking again if the remediation of
+	// a control plane machine can proceed.
+	controlPlaneRemediationRequeueAfter = 30 * time.Second
+)
+
+// controlPlaneRemediation contains the information required to decide which unhealthy control plane Machines
+// can be marked for remediation without putting the control plane quorum at risk.
+type controlPlaneRemediation struct {
+	// machines are all the control plane Machines of the Cluster, including the ones not targeted by the MachineHealthCheck.
+	machines []*clusterv1.Machine
+
+	// blockedReason is set by the control plane provider while it is not safe to remediate control plane Machines.
+	blockedReason string
+
+	// request is the name of the control plane Machine already marked for remediation, if any.
+	request string
+}
+
+// isRemediationPending returns true if the Machine has been marked for remediation and the remediation is not yet completed.
+func isRemediationPending(m *clusterv1.Machine) bool {
+	return !m.DeletionTimestamp.IsZero() || conditions.IsFalse(m, clusterv1.MachineOwnerRemediatedCondition)
+}
+
+// selectTargets splits the unhealthy control plane targets into the ones that can be marked for remediation and the
+// ones whose remediation is deferred, ensuring that at most one control plane Machine at a time is remediated and none
+// while the control plane is provisioning, upgrading or scaling. It returns the name of the control plane Machine
+// remediation is requested for, and the reason why remediation is deferred, if any.
+func (c *controlPlaneRemediation) selectTargets(unhealthy []healthCheckTarget) (allowed, deferred []healthCheckTarget, request, reason string) {
+	// Forget the previous request once the Machine has been remediated, or it is no longer marked for remediation.
+	request = c.request
+	if request != "" {
+		pending := false
+		for _, m := range c.machines {
+			if m.Name == request && isRemediationPending(m) {
+				pending = true
+			}
+		}
+		if !pending {
+			request = ""
+		}
+	}
+
+	switch {
+	case request != "":
+		reason = fmt.Sprintf("remediation of control plane machine %s is in progress", request)
+	case c.blockedReason != "":
+		reason = fmt.Sprintf("the control plane provider is blocking remediation: %s", c.blockedReason)
+	}
+	for _, m := range c.machines {
+		if reason != "" {
+			break
+		}
+		switch {
+		case !m.DeletionTimestamp.IsZero():
+			reason = fmt.Sprintf("control plane machine %s is being deleted", m.Name)
+		case conditions.IsFalse(m, clusterv1.MachineOwnerRemediatedCondition):
+			reason = fmt.Sprintf("remediation of control plane machine %s is in progress", m.Name)
+		case m.Status.NodeRef == nil:
+			reason = fmt.Sprintf("control plane machine %s is provisioning", m.Name)
+		}
+	}
+
+	// Targets already marked for remediation are left to the remediation owner.
+	var candidates []healthCheckTarget
+	for _, t := range unhealthy {
+		if conditions.IsFalse(t.Machine, clusterv1.MachineOwnerRemediatedCondition) {
+			allowed = append(allowed, t)
+			continue
+		}
+		candidates = append(candidates, t)
+	}
+	if len(candidates) == 0 {
+		return allowed, nil, request, ""
+	}
+	if reason != "" {
+		return allowed, candidates, request, reason
+	}
+
+	// Remediate the oldest unhealthy control plane Machine first, and defer the others.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Machine.CreationTimestamp.Before(&candidates[j].Machine.CreationTimestamp)
+	})
+	return append(allowed, candidates[0]), candidates[1:], candidates[0].Machine.Name, "only one control plane machine is remediated at a time"
+}
+
+// reconcileControlPlaneRemediation returns the unhealthy targets that can be marked for remediation and the unhealthy control
+// plane targets whose remediation is deferred; the control plane object is kept in sync with the control plane Machine
+// remediation is requested for using the ControlPlaneRemediationRequestAnnotation.
+func (r *MachineHealthCheckReconciler) reconcileControlPlaneRemediation(ctx context.Context, logger logr.Logger, cluster *clusterv1.Cluster, unhealthy []healthCheckTarget) ([]healthCheckTarget, []healthCheckTarget, error) {
+	var allowed, controlPlaneTargets []healthCheckTarget
+	for _, t := range unhealthy {
+		if util.IsControlPlaneMachine(t.Machine) {
+			controlPlaneTargets = append(controlPlaneTargets, t)
+			continue
+		}
+		allowed = append(allowed, t)
+	}
+	if len(controlPlaneTargets) == 0 && cluster.Spec.ControlPlaneRef == nil {
+		return allowed, nil, nil
+	}
+
+	var controlPlane *unstructured.Unstructured
+	if cluster.Spec.ControlPlaneRef != nil {
+		obj, err := external.Get(ctx, r.Client, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+		if err != nil && !apierrors.IsNotFound(errors.Cause(err)) {
+			return nil, nil, err
+		}
+		if err == nil {
+			controlPlane = obj
+		}
+	}
+
+	machineList := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machineList, client.InNamespace(cluster.Namespace), client.MatchingLabels{
+		clusterv1.ClusterLabelName:             cluster.Name,
+		clusterv1.MachineControlPlaneLabelName: "",
+	}); err != nil {
+		return nil, nil, errors.Wrap(err, "failed to list control plane machines")
+	}
+
+	remediation := &controlPlaneRemediation{}
+	for i := range machineList.Items {
+		remediation.machines = append(remediation.machines, &machineList.Items[i])
+	}
+	if controlPlane != nil {
+		remediation.blockedReason = controlPlane.GetAnnotations()[clusterv1.ControlPlaneRemediationBlockedAnnotation]
+		remediation.request = controlPlane.GetAnnotations()[clusterv1.ControlPlaneRemediationRequestAnnotation]
+	}
+
+	allowedControlPlaneTargets, deferred, request, reason := remediation.selectTargets(controlPlaneTargets)
+	if controlPlane != nil && request != remediation.request {
+		patchBase := client.MergeFrom(controlPlane.DeepCopy())
+		annotations := controlPlane.GetAnnotations()
+		if request == "" {
+			delete(annotations, clusterv1.ControlPlaneRemediationRequestAnnotation)
+		} else {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[clusterv1.ControlPlaneRemediationRequestAnnotation] = request
+		}
+		controlPlane.SetAnnotations(annotations)
+		if err := r.Client.Patch(ctx, controlPlane, patchBase); err != nil {
+			return nil, nil, errors.Wrapf(err, "failed to patch control plane %s with the remediation request", controlPlane.GetName())
+		}
+	}
+
+	if len(deferred) > 0 {
+		logger.Info("Deferring remediation of unhealthy control plane machines", "reason", reason, "machines", len(deferred))
+	}
+	return append(allowed, allowedControlPlaneTargets...), deferred, nil
+}
+
+// patchDeferredTargets patches the unhealthy control plane machines whose remediation is deferred, so their health check
+// conditions are up to date, without marking them for remediation.
+func (r *MachineHealthCheckReconciler) patchDeferredTargets(ctx context.Context, deferred []healthCheckTarget, m *clusterv1.MachineHealthCheck) []error {
+	errList := []error{}
+	for _, t := range deferred {
+		if err := t.patchHelper.Patch(ctx, t.Machine); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to patch unhealthy machine status for machine: %s/%s", t.Machine.Namespace, t.Machine.Name))
+			continue
+		}
+		r.recorder.Eventf(
+			m,
+			corev1.EventTypeNormal,
+			EventRemediationDeferred,
+			"Remediation of unhealthy control plane machine %v has been deferred",
+			t.string(),
+		)
+	}
+	return errList
+}
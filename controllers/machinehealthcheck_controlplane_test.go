@@ -0,0 +1,147 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestControlPlaneRemediationSelectTargets(t *testing.T) {
+	now := time.Now()
+	controlPlaneMachine := func(name string, age time.Duration, opts ...func(*clusterv1.Machine)) *clusterv1.Machine {
+		m := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels:            map[string]string{clusterv1.MachineControlPlaneLabelName: ""},
+			},
+			Status: clusterv1.MachineStatus{
+				NodeRef: &corev1.ObjectReference{Name: name},
+			},
+		}
+		for _, opt := range opts {
+			opt(m)
+		}
+		return m
+	}
+	marked := func(m *clusterv1.Machine) {
+		conditions.MarkFalse(m, clusterv1.MachineOwnerRemediatedCondition, clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+	}
+	deleting := func(m *clusterv1.Machine) {
+		m.DeletionTimestamp = &metav1.Time{Time: now}
+	}
+	provisioning := func(m *clusterv1.Machine) {
+		m.Status.NodeRef = nil
+	}
+
+	m1 := controlPlaneMachine("m1", 3*time.Hour)
+	m2 := controlPlaneMachine("m2", 2*time.Hour)
+	m3 := controlPlaneMachine("m3", time.Hour)
+	m1Marked := controlPlaneMachine("m1", 3*time.Hour, marked)
+
+	tests := []struct {
+		name         string
+		remediation  controlPlaneRemediation
+		unhealthy    []*clusterv1.Machine
+		wantAllowed  []string
+		wantDeferred []string
+		wantRequest  string
+	}{
+		{
+			name:        "no unhealthy machines",
+			remediation: controlPlaneRemediation{machines: []*clusterv1.Machine{m1, m2, m3}},
+		},
+		{
+			name:         "only the oldest unhealthy machine is remediated",
+			remediation:  controlPlaneRemediation{machines: []*clusterv1.Machine{m1, m2, m3}},
+			unhealthy:    []*clusterv1.Machine{m3, m2},
+			wantAllowed:  []string{"m2"},
+			wantDeferred: []string{"m3"},
+			wantRequest:  "m2",
+		},
+		{
+			name:         "remediation is deferred while another machine is being remediated",
+			remediation:  controlPlaneRemediation{machines: []*clusterv1.Machine{m1Marked, m2, m3}, request: "m1"},
+			unhealthy:    []*clusterv1.Machine{m1Marked, m2},
+			wantAllowed:  []string{"m1"},
+			wantDeferred: []string{"m2"},
+			wantRequest:  "m1",
+		},
+		{
+			name:         "remediation is deferred while a machine marked by another MachineHealthCheck is being remediated",
+			remediation:  controlPlaneRemediation{machines: []*clusterv1.Machine{m1Marked, m2, m3}},
+			unhealthy:    []*clusterv1.Machine{m2},
+			wantDeferred: []string{"m2"},
+		},
+		{
+			name:         "remediation is deferred while a machine is being deleted",
+			remediation:  controlPlaneRemediation{machines: []*clusterv1.Machine{controlPlaneMachine("m1", 3*time.Hour, deleting), m2, m3}},
+			unhealthy:    []*clusterv1.Machine{m2},
+			wantDeferred: []string{"m2"},
+		},
+		{
+			name:         "remediation is deferred while a machine is provisioning",
+			remediation:  controlPlaneRemediation{machines: []*clusterv1.Machine{m2, m3, controlPlaneMachine("m4", time.Minute, provisioning)}},
+			unhealthy:    []*clusterv1.Machine{m2},
+			wantDeferred: []string{"m2"},
+		},
+		{
+			name:         "remediation is deferred while the control plane provider blocks it",
+			remediation:  controlPlaneRemediation{machines: []*clusterv1.Machine{m1, m2, m3}, blockedReason: "rolling out"},
+			unhealthy:    []*clusterv1.Machine{m2},
+			wantDeferred: []string{"m2"},
+		},
+		{
+			name:        "the request is removed once the machine is remediated",
+			remediation: controlPlaneRemediation{machines: []*clusterv1.Machine{m2, m3}, request: "m1"},
+			unhealthy:   []*clusterv1.Machine{m3},
+			wantAllowed: []string{"m3"},
+			wantRequest: "m3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			unhealthy := make([]healthCheckTarget, 0, len(tt.unhealthy))
+			for _, m := range tt.unhealthy {
+				unhealthy = append(unhealthy, healthCheckTarget{Machine: m})
+			}
+
+			allowed, deferred, request, _ := tt.remediation.selectTargets(unhealthy)
+			g.Expect(targetNames(allowed)).To(ConsistOf(tt.wantAllowed))
+			g.Expect(targetNames(deferred)).To(ConsistOf(tt.wantDeferred))
+			g.Expect(request).To(Equal(tt.wantRequest))
+		})
+	}
+}
+
+func targetNames(targets []healthCheckTarget) []string {
+	names := []string{}
+	for _, t := range targets {
+		names = append(names, t.Machine.Name)
+	}
+	return names
+}
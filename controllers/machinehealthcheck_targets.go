@@ -23,11 +23,13 @@ import (
 
 	"github.com/go-logr/logr"
 	"github.com/pkg/errors"
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -55,11 +57,17 @@ const (
 	EventDetectedUnhealthy string = "DetectedUnhealthy"
 )
 
+const (
+	// nodeLeaseNamespace is the namespace where the kubelet Leases are stored.
+	nodeLeaseNamespace = "kube-node-lease"
+)
+
 // healthCheckTarget contains the information required to perform a health check
 // on the node to determine if any remediation is required.
 type healthCheckTarget struct {
 	Machine     *clusterv1.Machine
 	Node        *corev1.Node
+	NodeLease   *coordinationv1.Lease
 	MHC         *clusterv1.MachineHealthCheck
 	patchHelper *patch.Helper
 	nodeMissing bool
@@ -87,47 +95,86 @@ func (t *healthCheckTarget) nodeName() string {
 // - The Machine has failed for some reason
 // - The Machine did not get a node before `timeoutForMachineToHaveNode` elapses
 // - The Node has gone away
+// - The Node has the unreachable taint for longer than the unreachable taint timeout, if any
+// - The kubelet Lease of the Node has not been renewed within the lease staleness threshold, if any
 // - Any condition on the node is matched for the given timeout
 // If the target doesn't currently need rememdiation, provide a duration after
 // which the target should next be checked.
 // The target should be requeued after this duration.
-func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachineToHaveNode time.Duration) (bool, time.Duration) {
+// The returned bool is true if the target is likely to go unhealthy by then, e.g. because a node condition
+// is reporting an unhealthy status, as opposed to a node lease that is checked again when it expires.
+func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachineToHaveNode time.Duration) (bool, time.Duration, bool) {
 	var nextCheckTimes []time.Duration
+	likelyUnhealthy := false
 	now := time.Now()
 
 	if t.Machine.Status.FailureReason != nil {
 		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.MachineHasFailureReason, clusterv1.ConditionSeverityWarning, "FailureReason: %v", t.Machine.Status.FailureReason)
 		logger.V(3).Info("Target is unhealthy", "failureReason", t.Machine.Status.FailureReason)
-		return true, time.Duration(0)
+		return true, time.Duration(0), false
 	}
 
 	if t.Machine.Status.FailureMessage != nil {
 		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.MachineHasFailureReason, clusterv1.ConditionSeverityWarning, "FailureMessage: %v", t.Machine.Status.FailureMessage)
 		logger.V(3).Info("Target is unhealthy", "failureMessage", t.Machine.Status.FailureMessage)
-		return true, time.Duration(0)
+		return true, time.Duration(0), false
 	}
 
 	// the node does not exist
 	if t.nodeMissing {
 		logger.V(3).Info("Target is unhealthy: node is missing")
 		conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeNotFoundReason, clusterv1.ConditionSeverityWarning, "")
-		return true, time.Duration(0)
+		return true, time.Duration(0), false
 	}
 
 	// the node has not been set yet
 	if t.Node == nil {
 		// status not updated yet
 		if t.Machine.Status.LastUpdated == nil {
-			return false, timeoutForMachineToHaveNode
+			return false, timeoutForMachineToHaveNode, true
 		}
 		if t.Machine.Status.LastUpdated.Add(timeoutForMachineToHaveNode).Before(now) {
 			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeStartupTimeoutReason, clusterv1.ConditionSeverityWarning, "Node failed to report startup in %s", timeoutForMachineToHaveNode.String())
 			logger.V(3).Info("Target is unhealthy: machine has no node", "duration", timeoutForMachineToHaveNode.String())
-			return true, time.Duration(0)
+			return true, time.Duration(0), false
 		}
 		durationUnhealthy := now.Sub(t.Machine.Status.LastUpdated.Time)
 		nextCheck := timeoutForMachineToHaveNode - durationUnhealthy + time.Second
-		return false, nextCheck
+		return false, nextCheck, true
+	}
+
+	// check the unreachable taint, if an unreachable taint timeout is defined
+	if timeout := t.MHC.Spec.UnreachableTaintTimeout; timeout != nil {
+		if taint := getNodeTaint(t.Node, corev1.TaintNodeUnreachable); taint != nil && taint.TimeAdded != nil {
+			if taint.TimeAdded.Add(timeout.Duration).Before(now) {
+				conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeUnreachableReason, clusterv1.ConditionSeverityWarning, "Node has been unreachable for more than %s", timeout.Duration.String())
+				logger.V(3).Info("Target is unhealthy: node has the unreachable taint longer than allowed timeout", "timeout", timeout.Duration.String())
+				return true, time.Duration(0), false
+			}
+
+			durationUnhealthy := now.Sub(taint.TimeAdded.Time)
+			nextCheck := timeout.Duration - durationUnhealthy + time.Second
+			if nextCheck > 0 {
+				nextCheckTimes = append(nextCheckTimes, nextCheck)
+				likelyUnhealthy = true
+			}
+		}
+	}
+
+	// check the kubelet Lease, if a lease staleness threshold is defined
+	if threshold := t.MHC.Spec.NodeLeaseStalenessThreshold; threshold != nil && t.NodeLease != nil && t.NodeLease.Spec.RenewTime != nil {
+		lastRenewal := t.NodeLease.Spec.RenewTime.Time
+		if lastRenewal.Add(threshold.Duration).Before(now) {
+			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.NodeLeaseStaleReason, clusterv1.ConditionSeverityWarning, "Node lease has not been renewed for more than %s", threshold.Duration.String())
+			logger.V(3).Info("Target is unhealthy: node lease has not been renewed within the staleness threshold", "threshold", threshold.Duration.String())
+			return true, time.Duration(0), false
+		}
+
+		// Check again when the lease expires, in case it is not renewed.
+		nextCheck := threshold.Duration - now.Sub(lastRenewal) + time.Second
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
 	}
 
 	// check conditions
@@ -145,21 +192,24 @@ func (t *healthCheckTarget) needsRemediation(logger logr.Logger, timeoutForMachi
 		if nodeCondition.LastTransitionTime.Add(c.Timeout.Duration).Before(now) {
 			conditions.MarkFalse(t.Machine, clusterv1.MachineHealthCheckSuccededCondition, clusterv1.UnhealthyNodeConditionReason, clusterv1.ConditionSeverityWarning, "Condition %s on node is reporting status %s for more than %s", c.Type, c.Status, c.Timeout.Duration.String())
 			logger.V(3).Info("Target is unhealthy: condition is in state longer than allowed timeout", "condition", c.Type, "state", c.Status, "timeout", c.Timeout.Duration.String())
-			return true, time.Duration(0)
+			return true, time.Duration(0), false
 		}
 
 		durationUnhealthy := now.Sub(nodeCondition.LastTransitionTime.Time)
 		nextCheck := c.Timeout.Duration - durationUnhealthy + time.Second
 		if nextCheck > 0 {
 			nextCheckTimes = append(nextCheckTimes, nextCheck)
+			likelyUnhealthy = true
 		}
 	}
-	return false, minDuration(nextCheckTimes)
+	return false, minDuration(nextCheckTimes), likelyUnhealthy
 }
 
 // getTargetsFromMHC uses the MachineHealthCheck's selector to fetch machines
 // and their nodes targeted by the health check, ready for health checking.
-func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, clusterClient client.Reader, mhc *clusterv1.MachineHealthCheck) ([]healthCheckTarget, error) {
+// If skipControlPlaneMachines is true, e.g. because the control plane is externally managed and there are
+// no Nodes for control plane Machines, control plane Machines are not considered as targets.
+func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, clusterClient client.Reader, mhc *clusterv1.MachineHealthCheck, skipControlPlaneMachines bool) ([]healthCheckTarget, error) {
 	machines, err := r.getMachinesFromMHC(ctx, mhc)
 	if err != nil {
 		return nil, errors.Wrap(err, "error getting machines from MachineHealthCheck")
@@ -170,6 +220,9 @@ func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, cl
 
 	targets := []healthCheckTarget{}
 	for k := range machines {
+		if skipControlPlaneMachines && util.IsControlPlaneMachine(&machines[k]) {
+			continue
+		}
 		patchHelper, err := patch.NewHelper(&machines[k], r.Client)
 		if err != nil {
 			return nil, errors.Wrap(err, "unable to initialize patch helper")
@@ -189,6 +242,13 @@ func (r *MachineHealthCheckReconciler) getTargetsFromMHC(ctx context.Context, cl
 			target.nodeMissing = true
 		}
 		target.Node = node
+		if node != nil && mhc.Spec.NodeLeaseStalenessThreshold != nil {
+			lease, err := r.getNodeLease(ctx, clusterClient, node)
+			if err != nil {
+				return nil, errors.Wrap(err, "error getting node lease")
+			}
+			target.NodeLease = lease
+		}
 		targets = append(targets, target)
 	}
 	return targets, nil
@@ -235,6 +295,23 @@ func (r *MachineHealthCheckReconciler) getNodeFromMachine(ctx context.Context, c
 	return node, nil
 }
 
+// getNodeLease fetches the kubelet Lease of a node from a local or remote cluster.
+// If the Lease does not exist, e.g. because the NodeLease feature is disabled, nil is returned.
+func (r *MachineHealthCheckReconciler) getNodeLease(ctx context.Context, clusterClient client.Reader, node *corev1.Node) (*coordinationv1.Lease, error) {
+	lease := &coordinationv1.Lease{}
+	leaseKey := types.NamespacedName{
+		Namespace: nodeLeaseNamespace,
+		Name:      node.Name,
+	}
+	if err := clusterClient.Get(ctx, leaseKey, lease); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return lease, nil
+}
+
 // healthCheckTargets health checks a slice of targets
 // and gives a data to measure the average health
 func (r *MachineHealthCheckReconciler) healthCheckTargets(targets []healthCheckTarget, logger logr.Logger, timeoutForMachineToHaveNode time.Duration) ([]healthCheckTarget, []healthCheckTarget, []time.Duration) {
@@ -245,14 +322,14 @@ func (r *MachineHealthCheckReconciler) healthCheckTargets(targets []healthCheckT
 	for _, t := range targets {
 		logger = logger.WithValues("Target", t.string())
 		logger.V(3).Info("Health checking target")
-		needsRemediation, nextCheck := t.needsRemediation(logger, timeoutForMachineToHaveNode)
+		needsRemediation, nextCheck, likelyUnhealthy := t.needsRemediation(logger, timeoutForMachineToHaveNode)
 
 		if needsRemediation {
 			unhealthy = append(unhealthy, t)
 			continue
 		}
 
-		if nextCheck > 0 {
+		if nextCheck > 0 && likelyUnhealthy {
 			logger.V(3).Info("Target is likely to go unhealthy", "timeUntilUnhealthy", nextCheck.Truncate(time.Second).String())
 			r.recorder.Eventf(
 				t.Machine,
@@ -266,6 +343,9 @@ func (r *MachineHealthCheckReconciler) healthCheckTargets(targets []healthCheckT
 			continue
 		}
 
+		if nextCheck > 0 {
+			nextCheckTimes = append(nextCheckTimes, nextCheck)
+		}
 		if t.Machine.DeletionTimestamp.IsZero() {
 			conditions.MarkTrue(t.Machine, clusterv1.MachineHealthCheckSuccededCondition)
 			healthy = append(healthy, t)
@@ -284,6 +364,16 @@ func getNodeCondition(node *corev1.Node, conditionType corev1.NodeConditionType)
 	return nil
 }
 
+// getNodeTaint returns node taint by key
+func getNodeTaint(node *corev1.Node, key string) *corev1.Taint {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == key {
+			return &taint
+		}
+	}
+	return nil
+}
+
 func minDuration(durations []time.Duration) time.Duration {
 	if len(durations) == 0 {
 		return time.Duration(0)
@@ -22,11 +22,13 @@ import (
 
 	. "github.com/onsi/gomega"
 
+	coordinationv1 "k8s.io/api/coordination/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -74,11 +76,14 @@ func TestGetTargetsFromMHC(t *testing.T) {
 	testMachine3 := newTestMachine("machine3", namespace, clusterName, testNode3.Name, mhcSelector)
 	testNode4 := newTestNode("node4")
 	testMachine4 := newTestMachine("machine4", namespace, "other-cluster", testNode4.Name, mhcSelector)
+	testControlPlaneMachine := newTestMachine("machine5", namespace, clusterName, "node5", mhcSelector)
+	testControlPlaneMachine.Labels[clusterv1.MachineControlPlaneLabelName] = ""
 
 	testCases := []struct {
-		desc            string
-		toCreate        []client.Object
-		expectedTargets []healthCheckTarget
+		desc                     string
+		toCreate                 []client.Object
+		skipControlPlaneMachines bool
+		expectedTargets          []healthCheckTarget
 	}{
 		{
 			desc:            "with no matching machines",
@@ -124,6 +129,18 @@ func TestGetTargetsFromMHC(t *testing.T) {
 				},
 			},
 		},
+		{
+			desc:                     "when control plane machines should be skipped",
+			toCreate:                 append(baseObjects, testNode1, testMachine1, testControlPlaneMachine),
+			skipControlPlaneMachines: true,
+			expectedTargets: []healthCheckTarget{
+				{
+					Machine: testMachine1,
+					MHC:     testMHC,
+					Node:    testNode1,
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -143,7 +160,7 @@ func TestGetTargetsFromMHC(t *testing.T) {
 				t.patchHelper = patchHelper
 			}
 
-			targets, err := reconciler.getTargetsFromMHC(ctx, k8sClient, testMHC)
+			targets, err := reconciler.getTargetsFromMHC(ctx, k8sClient, testMHC, tc.skipControlPlaneMachines)
 			gs.Expect(err).ToNot(HaveOccurred())
 
 			gs.Expect(len(targets)).To(Equal(len(tc.expectedTargets)))
@@ -330,6 +347,192 @@ func TestHealthCheckTargets(t *testing.T) {
 	}
 }
 
+func TestHealthCheckTargetsWithUnreachableTaintAndNodeLease(t *testing.T) {
+	namespace := "test-mhc"
+	clusterName := "test-cluster"
+	mhcSelector := map[string]string{"cluster": clusterName, "machine-group": "foo"}
+
+	// Create a test MHC considering the unreachable taint and the node lease
+	testMHC := &clusterv1.MachineHealthCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-mhc",
+			Namespace: namespace,
+		},
+		Spec: clusterv1.MachineHealthCheckSpec{
+			Selector: metav1.LabelSelector{
+				MatchLabels: mhcSelector,
+			},
+			ClusterName: clusterName,
+			UnhealthyConditions: []clusterv1.UnhealthyCondition{
+				{
+					Type:    corev1.NodeReady,
+					Status:  corev1.ConditionUnknown,
+					Timeout: metav1.Duration{Duration: 5 * time.Minute},
+				},
+			},
+			UnreachableTaintTimeout:     &metav1.Duration{Duration: 1 * time.Minute},
+			NodeLeaseStalenessThreshold: &metav1.Duration{Duration: 2 * time.Minute},
+		},
+	}
+
+	testMachine := newTestMachine("machine1", namespace, clusterName, "node1", mhcSelector)
+
+	newUnreachableNode := func(unreachableDuration time.Duration) *corev1.Node {
+		node := newTestNode("node1")
+		timeAdded := metav1.NewTime(time.Now().Add(-unreachableDuration))
+		node.Spec.Taints = []corev1.Taint{
+			{
+				Key:       corev1.TaintNodeUnreachable,
+				Effect:    corev1.TaintEffectNoExecute,
+				TimeAdded: &timeAdded,
+			},
+		}
+		return node
+	}
+	newNodeLease := func(lastRenewal time.Duration) *coordinationv1.Lease {
+		renewTime := metav1.NewMicroTime(time.Now().Add(-lastRenewal))
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "node1",
+				Namespace: nodeLeaseNamespace,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				RenewTime: &renewTime,
+			},
+		}
+	}
+
+	// Target for when the node has been unreachable for shorter than the timeout
+	nodeUnreachable30s := healthCheckTarget{
+		MHC:     testMHC,
+		Machine: testMachine,
+		Node:    newUnreachableNode(30 * time.Second),
+	}
+
+	// Target for when the node has been unreachable for longer than the timeout
+	nodeUnreachable90s := healthCheckTarget{
+		MHC:     testMHC,
+		Machine: testMachine,
+		Node:    newUnreachableNode(90 * time.Second),
+	}
+
+	// Target for when the node lease has been renewed recently
+	nodeLeaseFresh := healthCheckTarget{
+		MHC:       testMHC,
+		Machine:   testMachine,
+		Node:      newTestNode("node1"),
+		NodeLease: newNodeLease(10 * time.Second),
+	}
+
+	// Target for when the node lease has not been renewed within the staleness threshold
+	nodeLeaseStale := healthCheckTarget{
+		MHC:       testMHC,
+		Machine:   testMachine,
+		Node:      newTestNode("node1"),
+		NodeLease: newNodeLease(3 * time.Minute),
+	}
+
+	testCases := []struct {
+		desc                     string
+		targets                  []healthCheckTarget
+		expectedHealthy          []healthCheckTarget
+		expectedNeedsRemediation []healthCheckTarget
+		expectedNextCheckTimes   []time.Duration
+		expectedReason           string
+	}{
+		{
+			desc:                     "when the node has been unreachable for shorter than the timeout",
+			targets:                  []healthCheckTarget{nodeUnreachable30s},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{30 * time.Second},
+		},
+		{
+			desc:                     "when the node has been unreachable for longer than the timeout",
+			targets:                  []healthCheckTarget{nodeUnreachable90s},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{nodeUnreachable90s},
+			expectedNextCheckTimes:   []time.Duration{},
+			expectedReason:           clusterv1.NodeUnreachableReason,
+		},
+		{
+			desc:                     "when the node lease has been renewed within the staleness threshold",
+			targets:                  []healthCheckTarget{nodeLeaseFresh},
+			expectedHealthy:          []healthCheckTarget{nodeLeaseFresh},
+			expectedNeedsRemediation: []healthCheckTarget{},
+			expectedNextCheckTimes:   []time.Duration{110 * time.Second},
+		},
+		{
+			desc:                     "when the node lease is stale",
+			targets:                  []healthCheckTarget{nodeLeaseStale},
+			expectedHealthy:          []healthCheckTarget{},
+			expectedNeedsRemediation: []healthCheckTarget{nodeLeaseStale},
+			expectedNextCheckTimes:   []time.Duration{},
+			expectedReason:           clusterv1.NodeLeaseStaleReason,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			gs := NewGomegaWithT(t)
+
+			gs.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+			k8sClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+			// Create a test reconciler
+			reconciler := &MachineHealthCheckReconciler{
+				Client:   k8sClient,
+				recorder: record.NewFakeRecorder(5),
+			}
+
+			timeoutForMachineToHaveNode := 10 * time.Minute
+			healthy, unhealthy, nextCheckTimes := reconciler.healthCheckTargets(tc.targets, ctrl.LoggerFrom(ctx), timeoutForMachineToHaveNode)
+
+			// Round durations down to nearest second account for minute differences
+			// in timing when running tests
+			roundDurations := func(in []time.Duration) []time.Duration {
+				out := []time.Duration{}
+				for _, d := range in {
+					out = append(out, d.Truncate(time.Second))
+				}
+				return out
+			}
+
+			gs.Expect(healthy).To(ConsistOf(tc.expectedHealthy))
+			gs.Expect(unhealthy).To(ConsistOf(tc.expectedNeedsRemediation))
+			gs.Expect(nextCheckTimes).To(WithTransform(roundDurations, ConsistOf(tc.expectedNextCheckTimes)))
+			if tc.expectedReason != "" {
+				gs.Expect(conditions.GetReason(tc.targets[0].Machine, clusterv1.MachineHealthCheckSuccededCondition)).To(Equal(tc.expectedReason))
+			}
+		})
+	}
+}
+
+func TestGetNodeLease(t *testing.T) {
+	g := NewWithT(t)
+
+	lease := &coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "node1",
+			Namespace: nodeLeaseNamespace,
+		},
+	}
+	k8sClient := fake.NewClientBuilder().WithObjects(lease).Build()
+	reconciler := &MachineHealthCheckReconciler{
+		Client: k8sClient,
+	}
+
+	got, err := reconciler.getNodeLease(ctx, k8sClient, newTestNode("node1"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).ToNot(BeNil())
+	g.Expect(got.Name).To(Equal("node1"))
+
+	// A missing lease is not an error, e.g. when the NodeLease feature is disabled.
+	got, err = reconciler.getNodeLease(ctx, k8sClient, newTestNode("node2"))
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(got).To(BeNil())
+}
+
 func newTestMachine(name, namespace, clusterName, nodeName string, labels map[string]string) *clusterv1.Machine {
 	// Copy the labels so that the map is unique to each test Machine
 	l := make(map[string]string)
@@ -19,6 +19,7 @@ package controllers
 import (
 	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -32,12 +33,14 @@ import (
 	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	"sigs.k8s.io/cluster-api/controllers/noderefutil"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
-	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	"sigs.k8s.io/cluster-api/util/defaulting"
+	"sigs.k8s.io/cluster-api/util/naming"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -50,13 +53,6 @@ import (
 var (
 	// machineSetKind contains the schema.GroupVersionKind for the MachineSet type.
 	machineSetKind = clusterv1.GroupVersion.WithKind("MachineSet")
-
-	// stateConfirmationTimeout is the amount of time allowed to wait for desired state.
-	stateConfirmationTimeout = 10 * time.Second
-
-	// stateConfirmationInterval is the amount of time between polling for the desired state.
-	// The polling is against a local memory cache.
-	stateConfirmationInterval = 100 * time.Millisecond
 )
 
 // +kubebuilder:rbac:groups=core,resources=events,verbs=get;list;watch;create;patch
@@ -71,8 +67,13 @@ type MachineSetReconciler struct {
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
 
-	recorder   record.EventRecorder
-	restConfig *rest.Config
+	// WebhooksDisabled applies the defaults and the validation of the admission webhooks to the MachineSets inside the
+	// controller, for management clusters where the webhooks are not installed.
+	WebhooksDisabled bool
+
+	recorder     record.EventRecorder
+	restConfig   *rest.Config
+	expectations machineSetExpectations
 }
 
 func (r *MachineSetReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -118,6 +119,9 @@ func (r *MachineSetReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		if apierrors.IsNotFound(err) {
 			// Object not found, return. Created objects are automatically garbage collected.
 			// For additional cleanup logic use finalizers.
+			deleteMachineSetMachinesByPhase(req.NamespacedName)
+			deleteMachineSetOwnerMachines(req.NamespacedName)
+			r.expectations.delete(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		// Error reading the object - requeue the request.
@@ -148,6 +152,15 @@ func (r *MachineSetReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}()
 
+	// Apply the defaults and the validation of the webhooks, when they are not installed.
+	if r.WebhooksDisabled {
+		if err := defaulting.DefaultAndValidate(machineSet); err != nil {
+			log.Error(err, "Invalid MachineSet, waiting for it to be fixed")
+			r.recorder.Eventf(machineSet, corev1.EventTypeWarning, "InvalidObject", "%v", err)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Ignore deleted MachineSets, this can happen when foregroundDeletion
 	// is enabled
 	if !machineSet.DeletionTimestamp.IsZero() {
@@ -181,16 +194,10 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		})
 	}
 
-	// Make sure to reconcile the external infrastructure reference.
-	if err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, &machineSet.Spec.Template.Spec.InfrastructureRef); err != nil {
+	// Make sure to reconcile the external infrastructure and bootstrap template references.
+	if err := r.reconcileTemplateReferences(ctx, cluster, machineSet); err != nil {
 		return ctrl.Result{}, err
 	}
-	// Make sure to reconcile the external bootstrap reference, if any.
-	if machineSet.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
-		if err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, machineSet.Spec.Template.Spec.Bootstrap.ConfigRef); err != nil {
-			return ctrl.Result{}, err
-		}
-	}
 
 	// Make sure selector and template to be in the same cluster.
 	machineSet.Spec.Selector.MatchLabels[clusterv1.ClusterLabelName] = machineSet.Spec.ClusterName
@@ -212,6 +219,9 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, errors.Wrap(err, "failed to list machines")
 	}
 
+	// Don't sync the replicas until the Machines created and deleted by the previous syncs have been observed.
+	expectationsSatisfied := r.expectations.satisfied(util.ObjectKey(machineSet), allMachines.Items, time.Now())
+
 	// Filter out irrelevant machines (deleting/mismatch labels) and claim orphaned machines.
 	filteredMachines := make([]*clusterv1.Machine, 0, len(allMachines.Items))
 	for idx := range allMachines.Items {
@@ -261,7 +271,30 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, errors.Wrap(err, "failed to remediate machines")
 	}
 
-	syncErr := r.syncReplicas(ctx, machineSet, filteredMachines)
+	if err := r.syncInPlaceMutableFields(ctx, machineSet, filteredMachines); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Retry the Machines which can't be provisioned because of insufficient capacity in a different failure domain.
+	capacityFailedMachines, err := r.reconcileUnavailableFailureDomains(ctx, machineSet, filteredMachines)
+	if err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to reconcile unavailable failure domains")
+	}
+	if err := r.retryCapacityFailedMachines(ctx, cluster, machineSet, filteredMachines, capacityFailedMachines); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Replace the Machines whose infrastructure has been deleted outside of Cluster API, if requested.
+	if err := r.replaceInfrastructureDeletedMachines(ctx, machineSet, filteredMachines); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	var syncErr error
+	if expectationsSatisfied {
+		syncErr = r.syncReplicas(ctx, cluster, machineSet, filteredMachines)
+	} else {
+		log.V(4).Info("Waiting for the created and deleted Machines to be observed, skipping replicas sync")
+	}
 
 	// Always updates status as machines come up or die.
 	if err := r.updateStatus(ctx, cluster, machineSet, filteredMachines); err != nil {
@@ -272,6 +305,16 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{}, errors.Wrapf(syncErr, "failed to sync MachineSet replicas")
 	}
 
+	// Templates are not watched, so periodically check if the missing ones have been recreated.
+	if conditions.IsFalse(machineSet, clusterv1.TemplatesResolvedCondition) {
+		return ctrl.Result{RequeueAfter: 1 * time.Minute}, nil
+	}
+
+	// Pods are not watched, so periodically check if the scale down is still blocked by protected Pods.
+	if conditions.IsFalse(machineSet, clusterv1.ScaleDownUnblockedCondition) {
+		return ctrl.Result{RequeueAfter: scaleDownBlockedRequeueAfter}, nil
+	}
+
 	var replicas int32
 	if machineSet.Spec.Replicas != nil {
 		replicas = *machineSet.Spec.Replicas
@@ -297,20 +340,47 @@ func (r *MachineSetReconciler) reconcile(ctx context.Context, cluster *clusterv1
 		return ctrl.Result{RequeueAfter: 15 * time.Second}, nil
 	}
 
+	// The Machine events trigger a reconcile once the pending expectations are observed, requeue in case one is missed.
+	if !expectationsSatisfied {
+		return ctrl.Result{RequeueAfter: machineSetExpectationsTimeout}, nil
+	}
+
 	return ctrl.Result{}, nil
 }
 
 // syncReplicas scales Machine resources up or down.
-func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+func (r *MachineSetReconciler) syncReplicas(ctx context.Context, cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
 	log := ctrl.LoggerFrom(ctx)
 	if ms.Spec.Replicas == nil {
 		return errors.Errorf("the Replicas field in Spec for machineset %v is nil, this should not be allowed", ms.Name)
 	}
 
+	if _, ok := ms.Annotations[clusterv1.ScaleDownProtectedPodSelectorAnnotation]; ok {
+		conditions.MarkTrue(ms, clusterv1.ScaleDownUnblockedCondition)
+	} else {
+		conditions.Delete(ms, clusterv1.ScaleDownUnblockedCondition)
+	}
+
 	diff := len(machines) - int(*(ms.Spec.Replicas))
 	switch {
 	case diff < 0:
 		diff *= -1
+		if conditions.IsFalse(ms, clusterv1.TemplatesResolvedCondition) {
+			log.Info("Too few replicas, but the referenced templates can't be resolved; skipping machine creation", "need", *(ms.Spec.Replicas), "missing", diff)
+			return nil
+		}
+		if _, ok := failureDomainForNewMachine(cluster, ms, machines); !ok {
+			log.Info("Too few replicas, but the failure domains for new machines are unavailable; backing off", "need", *(ms.Spec.Replicas), "missing", diff)
+			return nil
+		}
+		if toCreate := machinesToCreate(ms, machines, diff); toCreate < diff {
+			log.Info("Too few replicas, but the provisioning concurrency limit has been reached; throttling machine creation",
+				"need", *(ms.Spec.Replicas), "missing", diff, "provisioning-concurrency", *ms.Spec.ProvisioningConcurrency, "creating", toCreate)
+			diff = toCreate
+			if diff == 0 {
+				return nil
+			}
+		}
 		log.Info("Too few replicas", "need", *(ms.Spec.Replicas), "creating", diff)
 
 		var (
@@ -323,6 +393,15 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 				i+1, diff, *(ms.Spec.Replicas), len(machines)))
 
 			machine := r.getNewMachine(ms)
+			machine.Spec.FailureDomain, _ = failureDomainForNewMachine(cluster, ms, append(machineList, machines...))
+			if ms.Spec.NamingTemplate != "" {
+				name, err := r.generateMachineName(ctx, ms, len(machines)+len(machineList), machineList)
+				if err != nil {
+					return errors.Wrapf(err, "failed to generate machine name for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
+				}
+				machine.GenerateName = ""
+				machine.Name = name
+			}
 
 			// Clone and set the infrastructure and bootstrap references.
 			var (
@@ -332,11 +411,12 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 
 			if machine.Spec.Bootstrap.ConfigRef != nil {
 				bootstrapRef, err = external.CloneTemplate(ctx, &external.CloneTemplateInput{
-					Client:      r.Client,
-					TemplateRef: machine.Spec.Bootstrap.ConfigRef,
-					Namespace:   machine.Namespace,
-					ClusterName: machine.Spec.ClusterName,
-					Labels:      machine.Labels,
+					Client:         r.Client,
+					TemplateRef:    machine.Spec.Bootstrap.ConfigRef,
+					Namespace:      machine.Namespace,
+					ClusterName:    machine.Spec.ClusterName,
+					Labels:         machine.Labels,
+					MetadataPolicy: ms.Spec.TemplateMetadataPolicy,
 				})
 				if err != nil {
 					return errors.Wrapf(err, "failed to clone bootstrap configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
@@ -345,11 +425,12 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 			}
 
 			infraRef, err = external.CloneTemplate(ctx, &external.CloneTemplateInput{
-				Client:      r.Client,
-				TemplateRef: &machine.Spec.InfrastructureRef,
-				Namespace:   machine.Namespace,
-				ClusterName: machine.Spec.ClusterName,
-				Labels:      machine.Labels,
+				Client:         r.Client,
+				TemplateRef:    &machine.Spec.InfrastructureRef,
+				Namespace:      machine.Namespace,
+				ClusterName:    machine.Spec.ClusterName,
+				Labels:         machine.Labels,
+				MetadataPolicy: ms.Spec.TemplateMetadataPolicy,
 			})
 			if err != nil {
 				return errors.Wrapf(err, "failed to clone infrastructure configuration for MachineSet %q in namespace %q", ms.Name, ms.Namespace)
@@ -373,15 +454,14 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 				continue
 			}
 
+			// Record the expectation right away, so that it is not lost if a later iteration returns early.
+			r.expectations.expect(util.ObjectKey(ms), []string{machine.Name}, nil, time.Now())
 			log.Info(fmt.Sprintf("Created machine %d of %d with name %q", i+1, diff, machine.Name))
 			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulCreate", "Created machine %q", machine.Name)
 			machineList = append(machineList, machine)
 		}
 
-		if len(errs) > 0 {
-			return kerrors.NewAggregate(errs)
-		}
-		return r.waitForMachineCreation(ctx, machineList)
+		return kerrors.NewAggregate(errs)
 	case diff > 0:
 		log.Info("Too many replicas", "need", *(ms.Spec.Replicas), "deleting", diff)
 
@@ -391,8 +471,47 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 		}
 		log.Info("Found delete policy", "delete-policy", ms.Spec.DeletePolicy)
 
+		protectedSelector, err := getScaleDownProtectedPodSelector(ms)
+		if err != nil {
+			return err
+		}
+		var protectedMachines map[string]string
+		if protectedSelector != nil {
+			protectedMachines, err = r.getScaleDownProtectedMachines(ctx, cluster, protectedSelector, machines)
+			if err != nil {
+				return err
+			}
+			deletePriorityFunc = scaleDownProtectedDeletePriority(deletePriorityFunc, protectedMachines)
+		}
+
 		var errs []error
+		var blockedMachines []string
 		machinesToDelete := getMachinesToDeletePrioritized(machines, diff, deletePriorityFunc)
+		if len(protectedMachines) > 0 {
+			var unprotectedMachines []*clusterv1.Machine
+			for _, machine := range machinesToDelete {
+				if deletePriorityFunc(machine) != scaleDownProtectedPriority {
+					unprotectedMachines = append(unprotectedMachines, machine)
+					continue
+				}
+				log.Info("Machine protected from scale down not deleted", "machine", machine.Name, "pod", protectedMachines[machine.Name])
+				r.recorder.Eventf(ms, corev1.EventTypeWarning, "ScaleDownProtected",
+					"Machine %q was not deleted: its Node runs Pod %s, which matches the scale down protection selector %q",
+					machine.Name, protectedMachines[machine.Name], protectedSelector.String())
+				blockedMachines = append(blockedMachines, machine.Name)
+			}
+			machinesToDelete = unprotectedMachines
+		}
+		if len(blockedMachines) > 0 {
+			conditions.MarkFalse(ms, clusterv1.ScaleDownUnblockedCondition, clusterv1.ScaleDownProtectedPodsReason, clusterv1.ConditionSeverityWarning,
+				"Scale down blocked by %d Machines whose Node runs protected Pods: %s", len(blockedMachines), strings.Join(blockedMachines, ", "))
+		}
+		for _, machine := range getMarkedMachinesNotSelected(machines, machinesToDelete) {
+			log.Info("Machine marked for deletion not selected for scale down", "machine", machine.Name)
+			r.recorder.Eventf(ms, corev1.EventTypeNormal, "MarkedMachineNotSelected",
+				"Machine %q is marked for deletion but was not selected: scaling down by %d, and more machines are already being deleted or marked for deletion",
+				machine.Name, diff)
+		}
 		for _, machine := range machinesToDelete {
 			if err := r.Client.Delete(ctx, machine); err != nil {
 				log.Error(err, "Unable to delete Machine", "machine", machine.Name)
@@ -400,19 +519,41 @@ func (r *MachineSetReconciler) syncReplicas(ctx context.Context, ms *clusterv1.M
 				errs = append(errs, err)
 				continue
 			}
+			r.expectations.expect(util.ObjectKey(ms), nil, []string{machine.Name}, time.Now())
 			log.Info("Deleted machine", "machine", machine.Name)
 			r.recorder.Eventf(ms, corev1.EventTypeNormal, "SuccessfulDelete", "Deleted machine %q", machine.Name)
 		}
 
-		if len(errs) > 0 {
-			return kerrors.NewAggregate(errs)
-		}
-		return r.waitForMachineDeletion(ctx, machinesToDelete)
+		return kerrors.NewAggregate(errs)
 	}
 
 	return nil
 }
 
+// syncInPlaceMutableFields propagates the in-place mutable fields of the machine template, e.g. labels, annotations
+// and timeouts, to the existing Machines without replacing them.
+func (r *MachineSetReconciler) syncInPlaceMutableFields(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+	var errs []error
+	for _, machine := range machines {
+		if !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+
+		patchHelper, err := patch.NewHelper(machine, r.Client)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !mdutil.UpdateMachineInPlaceMutableFields(machine, &ms.Spec.Template) {
+			continue
+		}
+		if err := patchHelper.Patch(ctx, machine); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to propagate in-place changes to Machine %q", machine.Name))
+		}
+	}
+	return kerrors.NewAggregate(errs)
+}
+
 // getNewMachine creates a new Machine object. The name of the newly created resource is going
 // to be created by the API server, we set the generateName field.
 func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *clusterv1.Machine {
@@ -438,6 +579,33 @@ func (r *MachineSetReconciler) getNewMachine(machineSet *clusterv1.MachineSet) *
 	return machine
 }
 
+// generateMachineName returns a name for a new machine rendered from the MachineSet naming template,
+// skipping the names of the existing machines and of the machines created in the current reconcile.
+func (r *MachineSetReconciler) generateMachineName(ctx context.Context, ms *clusterv1.MachineSet, index int, created []*clusterv1.Machine) (string, error) {
+	ownerName := ms.Name
+	if mdName, ok := ms.Labels[clusterv1.MachineDeploymentLabelName]; ok {
+		ownerName = mdName
+	}
+
+	data := naming.MachineNameData{
+		ClusterName: ms.Spec.ClusterName,
+		OwnerName:   ownerName,
+		Index:       index,
+	}
+	return naming.GenerateUnique(ms.Spec.NamingTemplate, data, func(name string) (bool, error) {
+		for _, m := range created {
+			if m.Name == name {
+				return true, nil
+			}
+		}
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: ms.Namespace, Name: name}, &clusterv1.Machine{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
 // shouldExcludeMachine returns true if the machine should be filtered out, false otherwise.
 func shouldExcludeMachine(machineSet *clusterv1.MachineSet, machine *clusterv1.Machine) bool {
 	if metav1.GetControllerOf(machine) != nil && !metav1.IsControlledBy(machine, machineSet) {
@@ -455,53 +623,43 @@ func (r *MachineSetReconciler) adoptOrphan(ctx context.Context, machineSet *clus
 	return r.Client.Patch(ctx, machine, patch)
 }
 
-func (r *MachineSetReconciler) waitForMachineCreation(ctx context.Context, machineList []*clusterv1.Machine) error {
-	log := ctrl.LoggerFrom(ctx)
-
-	for i := 0; i < len(machineList); i++ {
-		machine := machineList[i]
-		pollErr := util.PollImmediate(stateConfirmationInterval, stateConfirmationTimeout, func() (bool, error) {
-			key := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Name}
-			if err := r.Client.Get(ctx, key, &clusterv1.Machine{}); err != nil {
-				if apierrors.IsNotFound(err) {
-					return false, nil
-				}
-				return false, err
-			}
-
-			return true, nil
-		})
+// machinesToCreate returns how many of the missing machines can be created, given that the number
+// of machines provisioning at the same time must not exceed the MachineSet's ProvisioningConcurrency.
+func machinesToCreate(ms *clusterv1.MachineSet, machines []*clusterv1.Machine, missing int) int {
+	if ms.Spec.ProvisioningConcurrency == nil {
+		return missing
+	}
 
-		if pollErr != nil {
-			log.Error(pollErr, "Failed waiting for machine object to be created")
-			return errors.Wrap(pollErr, "failed waiting for machine object to be created")
+	provisioning := 0
+	for _, m := range machines {
+		if isMachineProvisioning(m) {
+			provisioning++
 		}
 	}
 
-	return nil
+	available := int(*ms.Spec.ProvisioningConcurrency) - provisioning
+	switch {
+	case available <= 0:
+		return 0
+	case available < missing:
+		return available
+	default:
+		return missing
+	}
 }
 
-func (r *MachineSetReconciler) waitForMachineDeletion(ctx context.Context, machineList []*clusterv1.Machine) error {
-	log := ctrl.LoggerFrom(ctx)
-
-	for i := 0; i < len(machineList); i++ {
-		machine := machineList[i]
-		pollErr := util.PollImmediate(stateConfirmationInterval, stateConfirmationTimeout, func() (bool, error) {
-			m := &clusterv1.Machine{}
-			key := client.ObjectKey{Namespace: machine.Namespace, Name: machine.Name}
-			err := r.Client.Get(ctx, key, m)
-			if apierrors.IsNotFound(err) || !m.DeletionTimestamp.IsZero() {
-				return true, nil
-			}
-			return false, err
-		})
-
-		if pollErr != nil {
-			log.Error(pollErr, "Failed waiting for machine object to be deleted")
-			return errors.Wrap(pollErr, "failed waiting for machine object to be deleted")
-		}
+// isMachineProvisioning returns true if the Machine has been created but is not yet Running;
+// deleting and failed Machines are not considered provisioning.
+func isMachineProvisioning(m *clusterv1.Machine) bool {
+	if !m.DeletionTimestamp.IsZero() || m.Status.FailureReason != nil || m.Status.FailureMessage != nil {
+		return false
+	}
+	switch m.Status.GetTypedPhase() {
+	case clusterv1.MachinePhaseRunning, clusterv1.MachinePhaseFailed, clusterv1.MachinePhaseDeleting, clusterv1.MachinePhaseDeleted:
+		return false
+	default:
+		return true
 	}
-	return nil
 }
 
 // MachineToMachineSets is a handler.ToRequestsFunc to be used to enqeue requests for reconciliation
@@ -610,12 +768,14 @@ func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *cluste
 	fullyLabeledReplicasCount := 0
 	readyReplicasCount := 0
 	availableReplicasCount := 0
+	machinesByPhase := clusterv1.MachinesByPhase{}
 	templateLabel := labels.Set(ms.Spec.Template.Labels).AsSelectorPreValidated()
 
 	for _, machine := range filteredMachines {
 		if templateLabel.Matches(labels.Set(machine.Labels)) {
 			fullyLabeledReplicasCount++
 		}
+		machinesByPhase.Count(machine.Status.GetTypedPhase())
 
 		if machine.Status.NodeRef == nil {
 			log.V(2).Info("Unable to retrieve Node status, missing NodeRef", "machine", machine.Name)
@@ -640,12 +800,16 @@ func (r *MachineSetReconciler) updateStatus(ctx context.Context, cluster *cluste
 	newStatus.FullyLabeledReplicas = int32(fullyLabeledReplicasCount)
 	newStatus.ReadyReplicas = int32(readyReplicasCount)
 	newStatus.AvailableReplicas = int32(availableReplicasCount)
+	newStatus.MachinesByPhase = machinesByPhase
+	recordMachineSetMachinesByPhase(ms, machinesByPhase)
+	recordMachineSetOwnerMachines(ms, newStatus.Replicas, time.Now())
 
 	// Copy the newly calculated status into the machineset
 	if ms.Status.Replicas != newStatus.Replicas ||
 		ms.Status.FullyLabeledReplicas != newStatus.FullyLabeledReplicas ||
 		ms.Status.ReadyReplicas != newStatus.ReadyReplicas ||
 		ms.Status.AvailableReplicas != newStatus.AvailableReplicas ||
+		ms.Status.MachinesByPhase != newStatus.MachinesByPhase ||
 		ms.Generation != ms.Status.ObservedGeneration {
 		// Save the generation number we acted on, otherwise we might wrongfully indicate
 		// that we've seen a spec update when we retry.
@@ -675,34 +839,50 @@ func (r *MachineSetReconciler) getMachineNode(ctx context.Context, cluster *clus
 	return node, nil
 }
 
-func reconcileExternalTemplateReference(ctx context.Context, c client.Client, restConfig *rest.Config, cluster *clusterv1.Cluster, ref *corev1.ObjectReference) error {
-	if !strings.HasSuffix(ref.Kind, external.TemplateSuffix) {
-		return nil
-	}
+// reconcileTemplateReferences reconciles the infrastructure and bootstrap template references of a MachineSet
+// and sets the TemplatesResolved condition accordingly.
+// If the MachineSet is not managed by a MachineDeployment, templates deleted and recreated with a different name are resolved
+// by their template hash; otherwise rotation is left to the MachineDeployment, so it can roll out a new MachineSet.
+func (r *MachineSetReconciler) reconcileTemplateReferences(ctx context.Context, cluster *clusterv1.Cluster, ms *clusterv1.MachineSet) error {
+	allowRotation := !isControlledByMachineDeployment(ms)
 
-	if err := utilconversion.ConvertReferenceAPIContract(ctx, c, restConfig, ref); err != nil {
-		return err
+	refs := map[string]*corev1.ObjectReference{
+		clusterv1.InfrastructureTemplateHashAnnotation: &ms.Spec.Template.Spec.InfrastructureRef,
 	}
-
-	obj, err := external.Get(ctx, c, ref, cluster.Namespace)
-	if err != nil {
-		return err
+	if ms.Spec.Template.Spec.Bootstrap.ConfigRef != nil {
+		refs[clusterv1.BootstrapTemplateHashAnnotation] = ms.Spec.Template.Spec.Bootstrap.ConfigRef
 	}
 
-	patchHelper, err := patch.NewHelper(obj, c)
-	if err != nil {
-		return err
+	var messages []string
+	for hashAnnotation, ref := range refs {
+		oldName := ref.Name
+		rotated, err := reconcileExternalTemplateReference(ctx, r.Client, r.restConfig, cluster, ms, ref, hashAnnotation, allowRotation)
+		if err != nil {
+			if !apierrors.IsNotFound(errors.Cause(err)) {
+				return err
+			}
+			r.recorder.Eventf(ms, corev1.EventTypeWarning, clusterv1.FailedTemplateResolutionReason, "Failed to resolve %s %q: %v", ref.Kind, ref.Name, err)
+			messages = append(messages, fmt.Sprintf("%s %q not found", ref.Kind, ref.Name))
+			continue
+		}
+		if rotated {
+			r.recorder.Eventf(ms, corev1.EventTypeNormal, "TemplateRotated", "Rotated %s reference from %q to %q", ref.Kind, oldName, ref.Name)
+		}
 	}
 
-	obj.SetOwnerReferences(util.EnsureOwnerRef(obj.GetOwnerReferences(), metav1.OwnerReference{
-		APIVersion: clusterv1.GroupVersion.String(),
-		Kind:       "Cluster",
-		Name:       cluster.Name,
-		UID:        cluster.UID,
-	}))
-
-	if err := patchHelper.Patch(ctx, obj); err != nil {
-		return err
+	if len(messages) > 0 {
+		sort.Strings(messages)
+		conditions.MarkFalse(ms, clusterv1.TemplatesResolvedCondition, clusterv1.FailedTemplateResolutionReason, clusterv1.ConditionSeverityError,
+			"%s; fix the template references or recreate the templates to resume creating Machines", strings.Join(messages, ", "))
+		return nil
 	}
+
+	conditions.MarkTrue(ms, clusterv1.TemplatesResolvedCondition)
 	return nil
 }
+
+// isControlledByMachineDeployment returns true if the MachineSet is controlled by a MachineDeployment.
+func isControlledByMachineDeployment(ms *clusterv1.MachineSet) bool {
+	owner := metav1.GetControllerOf(ms)
+	return owner != nil && owner.Kind == "MachineDeployment" && owner.APIVersion == clusterv1.GroupVersion.String()
+}
@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/util"
@@ -742,6 +743,75 @@ func TestHasMatchingLabels(t *testing.T) {
 	}
 }
 
+func TestMachinesToCreate(t *testing.T) {
+	newMachine := func(phase clusterv1.MachinePhase) *clusterv1.Machine {
+		m := &clusterv1.Machine{}
+		m.Status.SetTypedPhase(phase)
+		return m
+	}
+	deletingMachine := newMachine(clusterv1.MachinePhaseProvisioning)
+	deletingMachine.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	tests := []struct {
+		name        string
+		concurrency *int32
+		machines    []*clusterv1.Machine
+		missing     int
+		want        int
+	}{
+		{
+			name:    "creates all the missing machines without a limit",
+			missing: 490,
+			want:    490,
+		},
+		{
+			name:        "creates up to the limit",
+			concurrency: pointer.Int32Ptr(10),
+			machines:    []*clusterv1.Machine{newMachine(clusterv1.MachinePhaseRunning)},
+			missing:     490,
+			want:        10,
+		},
+		{
+			name:        "does not count running, failed and deleting machines as provisioning",
+			concurrency: pointer.Int32Ptr(10),
+			machines: []*clusterv1.Machine{
+				newMachine(clusterv1.MachinePhaseRunning),
+				newMachine(clusterv1.MachinePhaseFailed),
+				deletingMachine,
+				newMachine(clusterv1.MachinePhasePending),
+				newMachine(clusterv1.MachinePhaseProvisioned),
+			},
+			missing: 490,
+			want:    8,
+		},
+		{
+			name:        "creates nothing when the limit is reached",
+			concurrency: pointer.Int32Ptr(2),
+			machines: []*clusterv1.Machine{
+				newMachine(clusterv1.MachinePhaseProvisioning),
+				newMachine(clusterv1.MachinePhaseProvisioning),
+				newMachine(clusterv1.MachinePhaseProvisioning),
+			},
+			missing: 5,
+			want:    0,
+		},
+		{
+			name:        "creates the missing machines when below the limit",
+			concurrency: pointer.Int32Ptr(10),
+			missing:     3,
+			want:        3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := &clusterv1.MachineSet{Spec: clusterv1.MachineSetSpec{ProvisioningConcurrency: tt.concurrency}}
+			g.Expect(machinesToCreate(ms, tt.machines, tt.missing)).To(Equal(tt.want))
+		})
+	}
+}
+
 func newMachineSet(name, cluster string) *clusterv1.MachineSet {
 	var replicas int32
 	return &clusterv1.MachineSet{
@@ -770,3 +840,93 @@ func newMachineSet(name, cluster string) *clusterv1.MachineSet {
 		},
 	}
 }
+
+func TestGenerateMachineName(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "md-0-abcde",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.MachineDeploymentLabelName: "md-0"},
+		},
+		Spec: clusterv1.MachineSetSpec{
+			ClusterName:    "test-cluster",
+			NamingTemplate: "{{ .ClusterName }}-{{ .OwnerName }}-{{ .Index }}",
+		},
+	}
+	existing := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-md-0-1", Namespace: "default"}}
+	created := []*clusterv1.Machine{{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-md-0-2", Namespace: "default"}}}
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	msr := &MachineSetReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(existing).Build(),
+	}
+
+	// The names of the existing machines and of the machines created in the same reconcile are skipped.
+	name, err := msr.generateMachineName(ctx, ms, 1, created)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(name).To(Equal("test-cluster-md-0-3"))
+
+	// The MachineSet name is used when the MachineSet is not owned by a MachineDeployment.
+	ms.Labels = nil
+	name, err = msr.generateMachineName(ctx, ms, 0, nil)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(name).To(Equal("test-cluster-md-0-abcde-0"))
+}
+
+func TestMachineSetReconcileSkipsSyncWhileExpectationsPending(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-cluster"}}
+	infraTemplate := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachineTemplate",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      "infra-template",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"template": map[string]interface{}{},
+			},
+		},
+	}
+	ms := newMachineSet("ms", cluster.Name)
+	ms.Spec.Replicas = pointer.Int32Ptr(1)
+	ms.Spec.Template.Spec.ClusterName = cluster.Name
+	ms.Spec.Template.Spec.InfrastructureRef = corev1.ObjectReference{
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+		Kind:       "InfrastructureMachineTemplate",
+		Name:       "infra-template",
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+		external.TestGenericInfrastructureTemplateCRD.DeepCopy(),
+		cluster,
+		ms,
+		infraTemplate,
+	).Build()
+	r := &MachineSetReconciler{
+		Client:   c,
+		recorder: record.NewFakeRecorder(32),
+	}
+
+	// A Machine created by a previous sync hasn't been observed yet, so no Machine is created.
+	r.expectations.expect(util.ObjectKey(ms), []string{"not-observed-yet"}, nil, time.Now())
+	_, err := r.reconcile(ctx, cluster, ms)
+	g.Expect(err).NotTo(HaveOccurred())
+	machines := &clusterv1.MachineList{}
+	g.Expect(c.List(ctx, machines)).To(Succeed())
+	g.Expect(machines.Items).To(BeEmpty())
+
+	// Once the expectations are dropped, the replicas are synced, and the created Machine is expected.
+	r.expectations.delete(util.ObjectKey(ms))
+	_, err = r.reconcile(ctx, cluster, ms)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(c.List(ctx, machines)).To(Succeed())
+	g.Expect(machines.Items).To(HaveLen(1))
+	g.Expect(r.expectations.satisfied(util.ObjectKey(ms), nil, time.Now())).To(BeFalse())
+}
@@ -32,6 +32,7 @@ type (
 
 const (
 	mustDelete    deletePriority = 100.0
+	shouldDelete  deletePriority = 75.0
 	betterDelete  deletePriority = 50.0
 	couldDelete   deletePriority = 20.0
 	mustNotDelete deletePriority = 0.0
@@ -39,19 +40,19 @@ const (
 	secondsPerTenDays float64 = 864000
 )
 
-// maps the creation timestamp onto the 0-100 priority range
+// maps the creation timestamp onto the 0-50 priority range
 func oldestDeletePriority(machine *clusterv1.Machine) deletePriority {
 	if !machine.DeletionTimestamp.IsZero() {
 		return mustDelete
 	}
 	if _, ok := machine.ObjectMeta.Annotations[clusterv1.DeleteMachineAnnotation]; ok {
-		return mustDelete
+		return shouldDelete
 	}
 	if machine.Status.NodeRef == nil {
-		return mustDelete
+		return betterDelete
 	}
 	if machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil {
-		return mustDelete
+		return betterDelete
 	}
 	if machine.ObjectMeta.CreationTimestamp.Time.IsZero() {
 		return mustNotDelete
@@ -60,7 +61,7 @@ func oldestDeletePriority(machine *clusterv1.Machine) deletePriority {
 	if d.Seconds() < 0 {
 		return mustNotDelete
 	}
-	return deletePriority(float64(mustDelete) * (1.0 - math.Exp(-d.Seconds()/secondsPerTenDays)))
+	return deletePriority(float64(betterDelete) * (1.0 - math.Exp(-d.Seconds()/secondsPerTenDays)))
 }
 
 func newestDeletePriority(machine *clusterv1.Machine) deletePriority {
@@ -68,15 +69,15 @@ func newestDeletePriority(machine *clusterv1.Machine) deletePriority {
 		return mustDelete
 	}
 	if _, ok := machine.ObjectMeta.Annotations[clusterv1.DeleteMachineAnnotation]; ok {
-		return mustDelete
+		return shouldDelete
 	}
 	if machine.Status.NodeRef == nil {
-		return mustDelete
+		return betterDelete
 	}
 	if machine.Status.FailureReason != nil || machine.Status.FailureMessage != nil {
-		return mustDelete
+		return betterDelete
 	}
-	return mustDelete - oldestDeletePriority(machine)
+	return betterDelete - oldestDeletePriority(machine)
 }
 
 func randomDeletePolicy(machine *clusterv1.Machine) deletePriority {
@@ -84,7 +85,7 @@ func randomDeletePolicy(machine *clusterv1.Machine) deletePriority {
 		return mustDelete
 	}
 	if _, ok := machine.ObjectMeta.Annotations[clusterv1.DeleteMachineAnnotation]; ok {
-		return betterDelete
+		return shouldDelete
 	}
 	if machine.Status.NodeRef == nil {
 		return betterDelete
@@ -122,6 +123,24 @@ func getMachinesToDeletePrioritized(filteredMachines []*clusterv1.Machine, diff
 	return sortable.machines[:diff]
 }
 
+// getMarkedMachinesNotSelected returns the machines marked for deletion with the DeleteMachineAnnotation
+// which are not part of the machines selected for deletion.
+func getMarkedMachinesNotSelected(machines, selected []*clusterv1.Machine) []*clusterv1.Machine {
+	selectedNames := make(map[string]bool, len(selected))
+	for _, m := range selected {
+		selectedNames[m.Name] = true
+	}
+
+	var res []*clusterv1.Machine
+	for _, m := range machines {
+		if _, ok := m.Annotations[clusterv1.DeleteMachineAnnotation]; !ok || selectedNames[m.Name] || !m.DeletionTimestamp.IsZero() {
+			continue
+		}
+		res = append(res, m)
+	}
+	return res
+}
+
 func getDeletePriorityFunc(ms *clusterv1.MachineSet) (deletePriorityFunc, error) {
 	// Map the Spec.DeletePolicy value to the appropriate delete priority function
 	switch msdp := clusterv1.MachineSetDeletePolicy(ms.Spec.DeletePolicy); msdp {
@@ -162,6 +162,19 @@ func TestMachineToDelete(t *testing.T) {
 				deleteMachineWithMachineAnnotation,
 			},
 		},
+		{
+			desc: "func=randomDeletePolicy, DeleteMachineAnnotation over unhealthy machines, diff=1",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				betterDeleteMachine,
+				deleteMachineWithoutNodeRef,
+				deleteMachineWithMachineAnnotation,
+				betterDeleteMachine,
+			},
+			expect: []*clusterv1.Machine{
+				deleteMachineWithMachineAnnotation,
+			},
+		},
 		{
 			desc: "func=randomDeletePolicy, MachineWithNoNodeRef, diff=1",
 			diff: 1,
@@ -276,6 +289,14 @@ func TestMachineNewestDelete(t *testing.T) {
 			},
 			expect: []*clusterv1.Machine{unhealthyMachine},
 		},
+		{
+			desc: "func=newestDeletePriority, diff=2 (DeleteMachineAnnotation over unhealthy)",
+			diff: 2,
+			machines: []*clusterv1.Machine{
+				new, unhealthyMachine, deleteMachineWithoutNodeRef, deleteMachineWithMachineAnnotation, mustDeleteMachine,
+			},
+			expect: []*clusterv1.Machine{mustDeleteMachine, deleteMachineWithMachineAnnotation},
+		},
 	}
 
 	for _, test := range tests {
@@ -385,6 +406,14 @@ func TestMachineOldestDelete(t *testing.T) {
 			},
 			expect: []*clusterv1.Machine{unhealthyMachine},
 		},
+		{
+			desc: "func=oldestDeletePriority, diff=1 (DeleteMachineAnnotation over unhealthy)",
+			diff: 1,
+			machines: []*clusterv1.Machine{
+				oldest, unhealthyMachine, deleteMachineWithoutNodeRef, deleteMachineWithMachineAnnotation,
+			},
+			expect: []*clusterv1.Machine{deleteMachineWithMachineAnnotation},
+		},
 	}
 
 	for _, test := range tests {
@@ -396,3 +425,45 @@ func TestMachineOldestDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestGetMarkedMachinesNotSelected(t *testing.T) {
+	now := metav1.Now()
+	marked1 := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "marked-1", Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: ""}}}
+	marked2 := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "marked-2", Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: ""}}}
+	markedDeleting := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "marked-deleting", DeletionTimestamp: &now, Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: ""}}}
+	unmarked := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "unmarked"}}
+
+	tests := []struct {
+		desc     string
+		machines []*clusterv1.Machine
+		selected []*clusterv1.Machine
+		expect   []*clusterv1.Machine
+	}{
+		{
+			desc:     "all marked machines selected",
+			machines: []*clusterv1.Machine{marked1, unmarked},
+			selected: []*clusterv1.Machine{marked1},
+			expect:   nil,
+		},
+		{
+			desc:     "marked machine not selected",
+			machines: []*clusterv1.Machine{marked1, marked2, unmarked},
+			selected: []*clusterv1.Machine{marked1},
+			expect:   []*clusterv1.Machine{marked2},
+		},
+		{
+			desc:     "marked machines already deleting are ignored",
+			machines: []*clusterv1.Machine{markedDeleting, marked1, unmarked},
+			selected: []*clusterv1.Machine{marked1},
+			expect:   nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.desc, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(getMarkedMachinesNotSelected(test.machines, test.selected)).To(Equal(test.expect))
+		})
+	}
+}
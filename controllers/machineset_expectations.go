@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+// machineSetExpectationsTimeout is the amount of time after which the pending expectations of a MachineSet are
+// dropped, e.g. because a watch event has been missed, so the replicas are synced again.
+const machineSetExpectationsTimeout = 5 * time.Minute
+
+// machineSetExpectations tracks the Machines created and deleted by the MachineSet controller which haven't been
+// observed in the cache yet, so the replicas are not synced again on a stale view of the Machines, which would
+// create or delete duplicate Machines when the informers lag behind.
+type machineSetExpectations struct {
+	sync.Mutex
+	m map[types.NamespacedName]*machineExpectations
+}
+
+type machineExpectations struct {
+	creations sets.String
+	deletions sets.String
+	time      time.Time
+}
+
+// expect records the names of the Machines created and deleted for a MachineSet at the given time.
+func (e *machineSetExpectations) expect(key types.NamespacedName, creations, deletions []string, now time.Time) {
+	if len(creations) == 0 && len(deletions) == 0 {
+		return
+	}
+
+	e.Lock()
+	defer e.Unlock()
+
+	if e.m == nil {
+		e.m = map[types.NamespacedName]*machineExpectations{}
+	}
+	exp, ok := e.m[key]
+	if !ok {
+		exp = &machineExpectations{creations: sets.NewString(), deletions: sets.NewString()}
+		e.m[key] = exp
+	}
+	exp.creations.Insert(creations...)
+	exp.deletions.Insert(deletions...)
+	exp.time = now
+}
+
+// satisfied observes the Machines of a MachineSet listed from the cache, and returns true if all the expected
+// creations and deletions have been observed, or if the expectations have expired.
+// Created Machines are observed once they are listed; deleted Machines once they aren't listed anymore or have
+// a deletion timestamp.
+func (e *machineSetExpectations) satisfied(key types.NamespacedName, machines []clusterv1.Machine, now time.Time) bool {
+	e.Lock()
+	defer e.Unlock()
+
+	exp, ok := e.m[key]
+	if !ok {
+		return true
+	}
+
+	listed := sets.NewString()
+	for i := range machines {
+		if machines[i].DeletionTimestamp.IsZero() {
+			listed.Insert(machines[i].Name)
+		}
+		exp.creations.Delete(machines[i].Name)
+	}
+	for _, name := range exp.deletions.UnsortedList() {
+		if !listed.Has(name) {
+			exp.deletions.Delete(name)
+		}
+	}
+
+	if exp.creations.Len() == 0 && exp.deletions.Len() == 0 || now.Sub(exp.time) > machineSetExpectationsTimeout {
+		delete(e.m, key)
+		return true
+	}
+	return false
+}
+
+// delete drops the expectations of a MachineSet.
+func (e *machineSetExpectations) delete(key types.NamespacedName) {
+	e.Lock()
+	defer e.Unlock()
+
+	delete(e.m, key)
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestMachineSetExpectations(t *testing.T) {
+	now := time.Now()
+	deletionTimestamp := metav1.NewTime(now)
+	machine := func(name string, deleting bool) clusterv1.Machine {
+		m := clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if deleting {
+			m.DeletionTimestamp = &deletionTimestamp
+		}
+		return m
+	}
+
+	tests := []struct {
+		name      string
+		creations []string
+		deletions []string
+		machines  []clusterv1.Machine
+		now       time.Time
+		want      bool
+	}{
+		{
+			name: "satisfied without expectations",
+			want: true,
+		},
+		{
+			name:      "not satisfied until the created Machines are listed",
+			creations: []string{"machine-1", "machine-2"},
+			machines:  []clusterv1.Machine{machine("machine-1", false)},
+			now:       now,
+			want:      false,
+		},
+		{
+			name:      "satisfied when the created Machines are listed",
+			creations: []string{"machine-1", "machine-2"},
+			machines:  []clusterv1.Machine{machine("machine-1", false), machine("machine-2", false)},
+			now:       now,
+			want:      true,
+		},
+		{
+			name:      "not satisfied until the deleted Machines are observed",
+			deletions: []string{"machine-1"},
+			machines:  []clusterv1.Machine{machine("machine-1", false)},
+			now:       now,
+			want:      false,
+		},
+		{
+			name:      "satisfied when the deleted Machines have a deletion timestamp",
+			deletions: []string{"machine-1"},
+			machines:  []clusterv1.Machine{machine("machine-1", true)},
+			now:       now,
+			want:      true,
+		},
+		{
+			name:      "satisfied when the deleted Machines are not listed",
+			deletions: []string{"machine-1"},
+			now:       now,
+			want:      true,
+		},
+		{
+			name:      "satisfied when the expectations have expired",
+			creations: []string{"machine-1"},
+			now:       now.Add(machineSetExpectationsTimeout + time.Second),
+			want:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			key := types.NamespacedName{Namespace: "default", Name: "ms"}
+			e := &machineSetExpectations{}
+			e.expect(key, tt.creations, tt.deletions, now)
+
+			g.Expect(e.satisfied(key, tt.machines, tt.now)).To(Equal(tt.want))
+			// Satisfied expectations are dropped.
+			if tt.want {
+				g.Expect(e.m).NotTo(HaveKey(key))
+			}
+		})
+	}
+}
+
+func TestMachineSetExpectationsObservedIncrementally(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	key := types.NamespacedName{Namespace: "default", Name: "ms"}
+	e := &machineSetExpectations{}
+	e.expect(key, []string{"machine-1", "machine-2"}, nil, now)
+
+	machine1 := clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-1", Namespace: "default"}}
+	machine2 := clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "machine-2", Namespace: "default"}}
+	g.Expect(e.satisfied(key, []clusterv1.Machine{machine1}, now)).To(BeFalse())
+	// machine-1 has been observed already, so it isn't required in the following lists.
+	g.Expect(e.satisfied(key, []clusterv1.Machine{machine2}, now)).To(BeTrue())
+
+	e.expect(key, nil, []string{"machine-1"}, now)
+	e.delete(key)
+	g.Expect(e.satisfied(key, []clusterv1.Machine{machine1}, now)).To(BeTrue())
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+const (
+	// unavailableFailureDomainTTL is the time a failure domain is considered unavailable for a MachineSet
+	// after a Machine last reported a failure hint for it.
+	unavailableFailureDomainTTL = 10 * time.Minute
+)
+
+// reconcileUnavailableFailureDomains records in the MachineSet status the failure domains where Machines
+// report insufficient capacity, and drops the entries which expired.
+// It returns the Machines reporting insufficient capacity in a known failure domain.
+func (r *MachineSetReconciler) reconcileUnavailableFailureDomains(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) ([]*clusterv1.Machine, error) {
+	now := metav1.Now()
+
+	var capacityFailedMachines []*clusterv1.Machine
+	for _, machine := range machines {
+		if !machine.DeletionTimestamp.IsZero() || machine.Status.InfrastructureReady {
+			continue
+		}
+		// Only the infrastructure of Machines flagged by the Machine controller is read, to get the failure domain
+		// the hint applies to, so the MachineSet doesn't read the infrastructure of every provisioning Machine.
+		if conditions.GetReason(machine, clusterv1.InfrastructureReadyCondition) != clusterv1.InsufficientCapacityReason {
+			continue
+		}
+
+		infraConfig, err := external.Get(ctx, r.Client, &machine.Spec.InfrastructureRef, machine.Namespace)
+		if err != nil {
+			if apierrors.IsNotFound(errors.Cause(err)) {
+				continue
+			}
+			return nil, err
+		}
+
+		failureHint, failureDomain, err := external.FailureHintFrom(infraConfig)
+		if err != nil {
+			return nil, err
+		}
+		if clusterv1.MachineFailureHint(failureHint) != clusterv1.InsufficientCapacityFailureHint {
+			continue
+		}
+		if failureDomain == "" && machine.Spec.FailureDomain != nil {
+			failureDomain = *machine.Spec.FailureDomain
+		}
+		if failureDomain == "" {
+			continue
+		}
+
+		setUnavailableFailureDomain(ms, failureDomain, clusterv1.InsufficientCapacityFailureHint, now)
+		capacityFailedMachines = append(capacityFailedMachines, machine)
+	}
+
+	unavailable := ms.Status.UnavailableFailureDomains[:0]
+	for _, fd := range ms.Status.UnavailableFailureDomains {
+		if now.Sub(fd.LastReportedTime.Time) < unavailableFailureDomainTTL {
+			unavailable = append(unavailable, fd)
+		}
+	}
+	if len(unavailable) == 0 {
+		unavailable = nil
+	}
+	ms.Status.UnavailableFailureDomains = unavailable
+
+	return capacityFailedMachines, nil
+}
+
+// setUnavailableFailureDomain adds or refreshes a failure domain in the MachineSet unavailable failure domains.
+func setUnavailableFailureDomain(ms *clusterv1.MachineSet, name string, hint clusterv1.MachineFailureHint, now metav1.Time) {
+	for i := range ms.Status.UnavailableFailureDomains {
+		fd := &ms.Status.UnavailableFailureDomains[i]
+		if fd.Name == name {
+			fd.FailureHint = hint
+			fd.LastReportedTime = now
+			return
+		}
+	}
+	ms.Status.UnavailableFailureDomains = append(ms.Status.UnavailableFailureDomains, clusterv1.UnavailableFailureDomain{
+		Name:             name,
+		FailureHint:      hint,
+		LastReportedTime: now,
+	})
+}
+
+// isFailureDomainUnavailable returns true if the failure domain is listed in the MachineSet unavailable failure domains.
+func isFailureDomainUnavailable(ms *clusterv1.MachineSet, name string) bool {
+	for _, fd := range ms.Status.UnavailableFailureDomains {
+		if fd.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pickAvailableFailureDomain returns the failure domain of the Cluster that is not unavailable for the MachineSet
+// and hosts the fewest of its Machines, or nil if there is none.
+func pickAvailableFailureDomain(cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) *string {
+	counts := map[string]int{}
+	for name := range cluster.Status.FailureDomains {
+		if !isFailureDomainUnavailable(ms, name) {
+			counts[name] = 0
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+	for _, machine := range machines {
+		if machine.Spec.FailureDomain == nil {
+			continue
+		}
+		if _, ok := counts[*machine.Spec.FailureDomain]; ok {
+			counts[*machine.Spec.FailureDomain]++
+		}
+	}
+
+	names := make([]string, 0, len(counts))
+	for name := range counts {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if counts[names[i]] != counts[names[j]] {
+			return counts[names[i]] < counts[names[j]]
+		}
+		return names[i] < names[j]
+	})
+	return pointer.StringPtr(names[0])
+}
+
+// failureDomainForNewMachine returns the failure domain new Machines of the MachineSet should be created in.
+// It returns false if Machines should not be created because all the candidate failure domains are unavailable,
+// so the MachineSet backs off until the unavailable failure domains expire.
+func failureDomainForNewMachine(cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) (*string, bool) {
+	// Failure domains set in the Machine template are honored, but Machines are not created there while unavailable.
+	if fd := ms.Spec.Template.Spec.FailureDomain; fd != nil {
+		return fd, !isFailureDomainUnavailable(ms, *fd)
+	}
+
+	// If no failure domain is unavailable, leave the choice to the infrastructure provider.
+	if len(ms.Status.UnavailableFailureDomains) == 0 {
+		return nil, true
+	}
+
+	// Without failure domains defined on the Cluster, there is no alternative to pick from.
+	if len(cluster.Status.FailureDomains) == 0 {
+		return nil, false
+	}
+
+	fd := pickAvailableFailureDomain(cluster, ms, machines)
+	return fd, fd != nil
+}
+
+// retryCapacityFailedMachines deletes the Machines reporting insufficient capacity when an alternative failure domain
+// is available, so they get replaced by Machines in a different failure domain.
+func (r *MachineSetReconciler) retryCapacityFailedMachines(ctx context.Context, cluster *clusterv1.Cluster, ms *clusterv1.MachineSet, machines, capacityFailedMachines []*clusterv1.Machine) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if len(capacityFailedMachines) == 0 {
+		return nil
+	}
+	if ms.Spec.Template.Spec.FailureDomain != nil || pickAvailableFailureDomain(cluster, ms, machines) == nil {
+		log.Info("Machines can't be provisioned because of insufficient capacity and no alternative failure domain is available, backing off")
+		return nil
+	}
+
+	for _, machine := range capacityFailedMachines {
+		log.Info("Deleting Machine reporting insufficient capacity to retry in a different failure domain", "machine", machine.Name)
+		if err := r.Client.Delete(ctx, machine); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete Machine %q", machine.Name)
+		}
+		r.recorder.Eventf(ms, corev1.EventTypeNormal, string(clusterv1.InsufficientCapacityFailureHint),
+			"Deleted machine %q reporting insufficient capacity to retry in a different failure domain", machine.Name)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newCapacityTestInfraMachine(name, failureDomain, failureHint string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachine",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"failureDomain": failureDomain,
+			},
+			"status": map[string]interface{}{
+				"failureHint": failureHint,
+			},
+		},
+	}
+}
+
+func newCapacityTestMachine(name string, failureDomain *string) *clusterv1.Machine {
+	return &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName:   "test-cluster",
+			FailureDomain: failureDomain,
+			InfrastructureRef: corev1.ObjectReference{
+				Kind:       "InfrastructureMachine",
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Name:       name,
+			},
+		},
+	}
+}
+
+func TestMachineSetReconciler_reconcileUnavailableFailureDomains(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+	machines := []*clusterv1.Machine{
+		newCapacityTestMachine("capacity-failed", nil),
+		newCapacityTestMachine("capacity-failed-machine-fd", pointer.StringPtr("fd2")),
+		newCapacityTestMachine("provisioning", nil),
+		newCapacityTestMachine("missing-infra", nil),
+		newCapacityTestMachine("not-flagged", nil),
+	}
+	for _, m := range machines[:4] {
+		conditions.MarkFalse(m, clusterv1.InfrastructureReadyCondition, clusterv1.InsufficientCapacityReason, clusterv1.ConditionSeverityWarning, "")
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newCapacityTestInfraMachine("capacity-failed", "fd1", string(clusterv1.InsufficientCapacityFailureHint)),
+		newCapacityTestInfraMachine("capacity-failed-machine-fd", "", string(clusterv1.InsufficientCapacityFailureHint)),
+		newCapacityTestInfraMachine("provisioning", "fd3", ""),
+		// The infrastructure of Machines not flagged by the Machine controller yet is not read.
+		newCapacityTestInfraMachine("not-flagged", "fd4", string(clusterv1.InsufficientCapacityFailureHint)),
+	).Build()
+	r := &MachineSetReconciler{Client: c}
+
+	ms := &clusterv1.MachineSet{
+		Status: clusterv1.MachineSetStatus{
+			UnavailableFailureDomains: []clusterv1.UnavailableFailureDomain{
+				{
+					Name:             "fd1",
+					FailureHint:      clusterv1.InsufficientCapacityFailureHint,
+					LastReportedTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+				},
+				{
+					Name:             "expired",
+					FailureHint:      clusterv1.InsufficientCapacityFailureHint,
+					LastReportedTime: metav1.NewTime(time.Now().Add(-2 * unavailableFailureDomainTTL)),
+				},
+			},
+		},
+	}
+
+	capacityFailedMachines, err := r.reconcileUnavailableFailureDomains(ctx, ms, machines)
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(capacityFailedMachines).To(ConsistOf(machines[0], machines[1]))
+
+	g.Expect(ms.Status.UnavailableFailureDomains).To(HaveLen(2))
+	g.Expect(ms.Status.UnavailableFailureDomains[0].Name).To(Equal("fd1"))
+	g.Expect(ms.Status.UnavailableFailureDomains[0].LastReportedTime.Time).To(BeTemporally("~", time.Now(), 10*time.Second))
+	g.Expect(ms.Status.UnavailableFailureDomains[1].Name).To(Equal("fd2"))
+}
+
+func TestFailureDomainForNewMachine(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		Status: clusterv1.ClusterStatus{
+			FailureDomains: clusterv1.FailureDomains{
+				"fd1": clusterv1.FailureDomainSpec{},
+				"fd2": clusterv1.FailureDomainSpec{},
+				"fd3": clusterv1.FailureDomainSpec{},
+			},
+		},
+	}
+	unavailable := func(names ...string) []clusterv1.UnavailableFailureDomain {
+		var fds []clusterv1.UnavailableFailureDomain
+		for _, name := range names {
+			fds = append(fds, clusterv1.UnavailableFailureDomain{Name: name, FailureHint: clusterv1.InsufficientCapacityFailureHint})
+		}
+		return fds
+	}
+	machines := []*clusterv1.Machine{
+		newCapacityTestMachine("m1", pointer.StringPtr("fd2")),
+	}
+
+	tests := []struct {
+		name               string
+		cluster            *clusterv1.Cluster
+		templateFD         *string
+		unavailable        []clusterv1.UnavailableFailureDomain
+		wantFailureDomain  *string
+		wantCreateMachines bool
+	}{
+		{
+			name:               "leaves the choice to the provider if no failure domain is unavailable",
+			cluster:            cluster,
+			wantCreateMachines: true,
+		},
+		{
+			name:               "honors the failure domain of the template",
+			cluster:            cluster,
+			templateFD:         pointer.StringPtr("fd1"),
+			unavailable:        unavailable("fd2"),
+			wantFailureDomain:  pointer.StringPtr("fd1"),
+			wantCreateMachines: true,
+		},
+		{
+			name:               "backs off if the failure domain of the template is unavailable",
+			cluster:            cluster,
+			templateFD:         pointer.StringPtr("fd1"),
+			unavailable:        unavailable("fd1"),
+			wantFailureDomain:  pointer.StringPtr("fd1"),
+			wantCreateMachines: false,
+		},
+		{
+			name:               "picks the available failure domain with the fewest machines",
+			cluster:            cluster,
+			unavailable:        unavailable("fd1"),
+			wantFailureDomain:  pointer.StringPtr("fd3"),
+			wantCreateMachines: true,
+		},
+		{
+			name:               "backs off if all the failure domains are unavailable",
+			cluster:            cluster,
+			unavailable:        unavailable("fd1", "fd2", "fd3"),
+			wantCreateMachines: false,
+		},
+		{
+			name:               "backs off if the Cluster has no failure domains to pick from",
+			cluster:            &clusterv1.Cluster{},
+			unavailable:        unavailable("fd1"),
+			wantCreateMachines: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			ms := &clusterv1.MachineSet{
+				Spec: clusterv1.MachineSetSpec{
+					Template: clusterv1.MachineTemplateSpec{
+						Spec: clusterv1.MachineSpec{FailureDomain: tt.templateFD},
+					},
+				},
+				Status: clusterv1.MachineSetStatus{UnavailableFailureDomains: tt.unavailable},
+			}
+			fd, ok := failureDomainForNewMachine(tt.cluster, ms, machines)
+			g.Expect(ok).To(Equal(tt.wantCreateMachines))
+			g.Expect(fd).To(Equal(tt.wantFailureDomain))
+		})
+	}
+}
+
+func TestMachineSetReconciler_retryCapacityFailedMachines(t *testing.T) {
+	cluster := &clusterv1.Cluster{
+		Status: clusterv1.ClusterStatus{
+			FailureDomains: clusterv1.FailureDomains{
+				"fd1": clusterv1.FailureDomainSpec{},
+				"fd2": clusterv1.FailureDomainSpec{},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		templateFD  *string
+		unavailable []string
+		wantDeleted bool
+	}{
+		{
+			name:        "deletes the machine if another failure domain is available",
+			unavailable: []string{"fd1"},
+			wantDeleted: true,
+		},
+		{
+			name:        "backs off if no other failure domain is available",
+			unavailable: []string{"fd1", "fd2"},
+			wantDeleted: false,
+		},
+		{
+			name:        "backs off if the template defines the failure domain",
+			templateFD:  pointer.StringPtr("fd1"),
+			unavailable: []string{"fd1"},
+			wantDeleted: false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+			machine := newCapacityTestMachine("capacity-failed", pointer.StringPtr("fd1"))
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine.DeepCopy()).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &MachineSetReconciler{Client: c, recorder: recorder}
+
+			ms := &clusterv1.MachineSet{
+				Spec: clusterv1.MachineSetSpec{
+					Template: clusterv1.MachineTemplateSpec{
+						Spec: clusterv1.MachineSpec{FailureDomain: tt.templateFD},
+					},
+				},
+			}
+			for _, name := range tt.unavailable {
+				setUnavailableFailureDomain(ms, name, clusterv1.InsufficientCapacityFailureHint, metav1.Now())
+			}
+
+			machines := []*clusterv1.Machine{machine}
+			g.Expect(r.retryCapacityFailedMachines(ctx, cluster, ms, machines, machines)).To(Succeed())
+
+			err := c.Get(ctx, client.ObjectKeyFromObject(machine), &clusterv1.Machine{})
+			if tt.wantDeleted {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(recorder.Events).To(Receive(ContainSubstring(string(clusterv1.InsufficientCapacityFailureHint))))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+		})
+	}
+}
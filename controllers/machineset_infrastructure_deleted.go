@@ -0,0 +1,58 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// isInfrastructureDeleted returns true if the Machine has been marked as failed because its infrastructure machine
+// has been deleted outside of Cluster API.
+func isInfrastructureDeleted(m *clusterv1.Machine) bool {
+	return m.Status.FailureReason != nil && *m.Status.FailureReason == capierrors.InfrastructureDeletedMachineError
+}
+
+// replaceInfrastructureDeletedMachines deletes the Machines whose infrastructure machine has been deleted outside of
+// Cluster API, so they get replaced by new Machines, if the MachineSet's InfrastructureDeletedPolicy is Replace.
+func (r *MachineSetReconciler) replaceInfrastructureDeletedMachines(ctx context.Context, ms *clusterv1.MachineSet, machines []*clusterv1.Machine) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if ms.Spec.InfrastructureDeletedPolicy != clusterv1.ReplaceMachineSetInfrastructureDeletedPolicy {
+		return nil
+	}
+
+	for _, machine := range machines {
+		if !machine.DeletionTimestamp.IsZero() || !isInfrastructureDeleted(machine) {
+			continue
+		}
+
+		log.Info("Deleting Machine whose infrastructure has been deleted to replace it", "machine", machine.Name)
+		if err := r.Client.Delete(ctx, machine); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete Machine %q", machine.Name)
+		}
+		r.recorder.Eventf(ms, corev1.EventTypeNormal, string(capierrors.InfrastructureDeletedMachineError),
+			"Deleted machine %q whose infrastructure has been deleted to replace it", machine.Name)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestMachineSetReconciler_replaceInfrastructureDeletedMachines(t *testing.T) {
+	tests := []struct {
+		name          string
+		policy        clusterv1.MachineSetInfrastructureDeletedPolicy
+		failureReason capierrors.MachineStatusError
+		wantDeleted   bool
+	}{
+		{
+			name:          "deletes the machine with the Replace policy",
+			policy:        clusterv1.ReplaceMachineSetInfrastructureDeletedPolicy,
+			failureReason: capierrors.InfrastructureDeletedMachineError,
+			wantDeleted:   true,
+		},
+		{
+			name:          "keeps the machine with the Fail policy",
+			policy:        clusterv1.FailMachineSetInfrastructureDeletedPolicy,
+			failureReason: capierrors.InfrastructureDeletedMachineError,
+			wantDeleted:   false,
+		},
+		{
+			name:          "keeps the machine with the default policy",
+			failureReason: capierrors.InfrastructureDeletedMachineError,
+			wantDeleted:   false,
+		},
+		{
+			name:          "keeps the machine failed for other reasons",
+			policy:        clusterv1.ReplaceMachineSetInfrastructureDeletedPolicy,
+			failureReason: capierrors.InvalidConfigurationMachineError,
+			wantDeleted:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+
+			machine := &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "infra-deleted",
+					Namespace: "default",
+				},
+				Status: clusterv1.MachineStatus{
+					FailureReason: capierrors.MachineStatusErrorPtr(tt.failureReason),
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine.DeepCopy()).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &MachineSetReconciler{Client: c, recorder: recorder}
+
+			ms := &clusterv1.MachineSet{
+				Spec: clusterv1.MachineSetSpec{
+					InfrastructureDeletedPolicy: tt.policy,
+				},
+			}
+			g.Expect(r.replaceInfrastructureDeletedMachines(ctx, ms, []*clusterv1.Machine{machine})).To(Succeed())
+
+			err := c.Get(ctx, client.ObjectKeyFromObject(machine), &clusterv1.Machine{})
+			if tt.wantDeleted {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(recorder.Events).To(Receive(ContainSubstring(string(capierrors.InfrastructureDeletedMachineError))))
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			g.Expect(recorder.Events).NotTo(Receive())
+		})
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// scaleDownProtectedPriority is the delete priority of the Machines protected from scale down; it is lower than the
+// priority of any other Machine, so that protected Machines are selected only if there are no other Machines to delete.
+const scaleDownProtectedPriority deletePriority = mustNotDelete - 1
+
+// scaleDownBlockedRequeueAfter is the interval at which a scale down blocked by protected Pods is retried; Pods in
+// the workload clusters are not watched, so their deletion doesn't trigger a reconcile.
+const scaleDownBlockedRequeueAfter = 30 * time.Second
+
+// getScaleDownProtectedPodSelector returns the selector of the Pods protecting their Node's Machine from scale down,
+// or nil if the MachineSet does not have the ScaleDownProtectedPodSelectorAnnotation.
+func getScaleDownProtectedPodSelector(ms *clusterv1.MachineSet) (labels.Selector, error) {
+	value, ok := ms.Annotations[clusterv1.ScaleDownProtectedPodSelectorAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	selector, err := labels.Parse(value)
+	if err != nil || value == "" {
+		return nil, errors.Errorf("invalid %s annotation %q: must be a non-empty label selector", clusterv1.ScaleDownProtectedPodSelectorAnnotation, value)
+	}
+	return selector, nil
+}
+
+// getScaleDownProtectedMachines returns the Machines whose Node runs Pods matching the selector, mapped to the name
+// of one of these Pods. The Pods are listed as unstructured, so that the list bypasses the cache of the
+// ClusterCacheTracker and no Pod informer is started in the workload cluster.
+func (r *MachineSetReconciler) getScaleDownProtectedMachines(ctx context.Context, cluster *clusterv1.Cluster, selector labels.Selector, machines []*clusterv1.Machine) (map[string]string, error) {
+	machineByNode := map[string]string{}
+	for _, m := range machines {
+		if m.Status.NodeRef != nil {
+			machineByNode[m.Status.NodeRef.Name] = m.Name
+		}
+	}
+	if len(machineByNode) == 0 {
+		return nil, nil
+	}
+	if r.Tracker == nil {
+		return nil, errors.New("unable to check the scale down protected Pods: no workload cluster client available")
+	}
+
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get a client for the workload cluster to check the scale down protected Pods")
+	}
+	pods := &unstructured.UnstructuredList{}
+	pods.SetGroupVersionKind(corev1.SchemeGroupVersion.WithKind("PodList"))
+	if err := remoteClient.List(ctx, pods, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, errors.Wrap(err, "failed to list the scale down protected Pods")
+	}
+	return scaleDownProtectedMachines(machineByNode, pods.Items), nil
+}
+
+// scaleDownProtectedMachines returns the Machines, mapped by their Node's name, running any of the given Pods which
+// has not completed yet.
+func scaleDownProtectedMachines(machineByNode map[string]string, pods []unstructured.Unstructured) map[string]string {
+	protected := map[string]string{}
+	for i := range pods {
+		pod := &pods[i]
+		nodeName, _, _ := unstructured.NestedString(pod.Object, "spec", "nodeName")
+		phase, _, _ := unstructured.NestedString(pod.Object, "status", "phase")
+		if phase == string(corev1.PodSucceeded) || phase == string(corev1.PodFailed) {
+			continue
+		}
+		machineName, ok := machineByNode[nodeName]
+		if !ok {
+			continue
+		}
+		if _, ok := protected[machineName]; !ok {
+			protected[machineName] = pod.GetNamespace() + "/" + pod.GetName()
+		}
+	}
+	return protected
+}
+
+// scaleDownProtectedDeletePriority wraps a delete priority function, assigning the lowest priority to the protected
+// Machines, unless they are already being deleted or marked for deletion with the DeleteMachineAnnotation.
+func scaleDownProtectedDeletePriority(fun deletePriorityFunc, protected map[string]string) deletePriorityFunc {
+	return func(machine *clusterv1.Machine) deletePriority {
+		priority := fun(machine)
+		if _, ok := protected[machine.Name]; ok && priority < shouldDelete {
+			return scaleDownProtectedPriority
+		}
+		return priority
+	}
+}
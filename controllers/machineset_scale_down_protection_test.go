@@ -0,0 +1,172 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestGetScaleDownProtectedPodSelector(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{}
+	selector, err := getScaleDownProtectedPodSelector(ms)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(selector).To(BeNil())
+
+	ms.Annotations = map[string]string{clusterv1.ScaleDownProtectedPodSelectorAnnotation: "cluster.x-k8s.io/scale-down-protected"}
+	selector, err = getScaleDownProtectedPodSelector(ms)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(selector.String()).To(Equal("cluster.x-k8s.io/scale-down-protected"))
+
+	ms.Annotations[clusterv1.ScaleDownProtectedPodSelectorAnnotation] = "app in (kafka"
+	_, err = getScaleDownProtectedPodSelector(ms)
+	g.Expect(err).To(HaveOccurred())
+}
+
+func TestScaleDownProtectedDeletePriority(t *testing.T) {
+	g := NewWithT(t)
+
+	nodeRef := &corev1.ObjectReference{Name: "node"}
+	protected := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "protected"}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+	protectedMarked := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "protected-marked", Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: ""}},
+		Status:     clusterv1.MachineStatus{NodeRef: nodeRef},
+	}
+	unprotected := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "unprotected"}, Status: clusterv1.MachineStatus{NodeRef: nodeRef}}
+
+	fun := scaleDownProtectedDeletePriority(randomDeletePolicy, map[string]string{
+		"protected":        "default/kafka-0",
+		"protected-marked": "default/kafka-1",
+	})
+	g.Expect(fun(protected)).To(Equal(scaleDownProtectedPriority))
+	g.Expect(fun(protectedMarked)).To(Equal(shouldDelete))
+	g.Expect(fun(unprotected)).To(Equal(couldDelete))
+
+	g.Expect(getMachinesToDeletePrioritized([]*clusterv1.Machine{protected, unprotected}, 1, fun)).To(Equal([]*clusterv1.Machine{unprotected}))
+}
+
+func TestMachineSetReconciler_syncReplicas_scaleDownProtection(t *testing.T) {
+	newMachine := func(name, nodeName string) *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Status:     clusterv1.MachineStatus{NodeRef: &corev1.ObjectReference{Name: nodeName}},
+		}
+	}
+	newPod := func(name, nodeName string, phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: map[string]string{"cluster.x-k8s.io/scale-down-protected": ""}},
+			Spec:       corev1.PodSpec{NodeName: nodeName},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	tests := []struct {
+		name            string
+		replicas        int32
+		pods            []client.Object
+		wantRemaining   []string
+		wantUnblocked   bool
+		wantEventReason string
+	}{
+		{
+			name:          "deletes the unprotected Machines",
+			replicas:      1,
+			pods:          []client.Object{newPod("kafka-0", "node-1", corev1.PodRunning)},
+			wantRemaining: []string{"machine-1"},
+			wantUnblocked: true,
+		},
+		{
+			name:          "ignores completed Pods",
+			replicas:      1,
+			pods:          []client.Object{newPod("backup", "node-1", corev1.PodSucceeded), newPod("kafka-0", "node-2", corev1.PodRunning)},
+			wantRemaining: []string{"machine-2"},
+			wantUnblocked: true,
+		},
+		{
+			name:            "blocks the scale down when only protected Machines are left",
+			replicas:        1,
+			pods:            []client.Object{newPod("kafka-0", "node-1", corev1.PodRunning), newPod("kafka-1", "node-2", corev1.PodRunning)},
+			wantRemaining:   []string{"machine-1", "machine-2"},
+			wantUnblocked:   false,
+			wantEventReason: "ScaleDownProtected",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+			ms := &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ms",
+					Namespace:   "default",
+					Annotations: map[string]string{clusterv1.ScaleDownProtectedPodSelectorAnnotation: "cluster.x-k8s.io/scale-down-protected"},
+				},
+				Spec: clusterv1.MachineSetSpec{ClusterName: cluster.Name, Replicas: pointer.Int32Ptr(tt.replicas)},
+			}
+			machines := []*clusterv1.Machine{
+				newMachine("machine-1", "node-1"),
+				newMachine("machine-2", "node-2"),
+				newMachine("machine-3", "node-3"),
+			}
+			objs := []client.Object{cluster, ms}
+			for _, m := range machines {
+				objs = append(objs, m)
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(objs...).Build()
+			remoteClient := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(tt.pods...).Build()
+
+			rec := record.NewFakeRecorder(32)
+			r := &MachineSetReconciler{
+				Client:   c,
+				Tracker:  remote.NewTestClusterCacheTracker(log.NullLogger{}, remoteClient, scheme.Scheme, util.ObjectKey(cluster)),
+				recorder: rec,
+			}
+			g.Expect(r.syncReplicas(ctx, cluster, ms, machines)).To(Succeed())
+
+			remaining := &clusterv1.MachineList{}
+			g.Expect(c.List(ctx, remaining, client.InNamespace("default"))).To(Succeed())
+			var names []string
+			for _, m := range remaining.Items {
+				names = append(names, m.Name)
+			}
+			g.Expect(names).To(ConsistOf(tt.wantRemaining))
+			g.Expect(conditions.IsTrue(ms, clusterv1.ScaleDownUnblockedCondition)).To(Equal(tt.wantUnblocked))
+			if tt.wantEventReason != "" {
+				g.Expect(rec.Events).To(Receive(ContainSubstring(tt.wantEventReason)))
+			}
+		})
+	}
+}
@@ -146,6 +146,12 @@ var annotationsToSkip = map[string]bool{
 	clusterv1.DesiredReplicasAnnotation: true,
 	clusterv1.MaxReplicasAnnotation:     true,
 
+	clusterv1.RolloutHookPendingAnnotation: true,
+
+	// Exclude the template hash annotations, given that each MachineSet computes them from its own template references.
+	clusterv1.InfrastructureTemplateHashAnnotation: true,
+	clusterv1.BootstrapTemplateHashAnnotation:      true,
+
 	// Exclude the conversion annotation, to avoid infinite loops between the conversion webhook
 	// and the MachineDeployment controller syncing the annotations between a MachineDeployment
 	// and its linked MachineSets.
@@ -158,6 +164,10 @@ var annotationsToSkip = map[string]bool{
 // TODO(tbd): How to decide which annotations should / should not be copied?
 //       See https://github.com/kubernetes/kubernetes/pull/20035#issuecomment-179558615
 func skipCopyAnnotation(key string) bool {
+	// Rollout hooks apply to the machine deployment only.
+	if strings.HasPrefix(key, clusterv1.PreRolloutHookAnnotationPrefix) || strings.HasPrefix(key, clusterv1.PostRolloutHookAnnotationPrefix) {
+		return true
+	}
 	return annotationsToSkip[key]
 }
 
@@ -386,18 +396,87 @@ func getMachineSetFraction(ms clusterv1.MachineSet, d clusterv1.MachineDeploymen
 	return integer.RoundToInt32(newMSsize) - *(ms.Spec.Replicas)
 }
 
+// The fields of a machine template are classified as follows:
+//   - in-place mutable fields: labels, annotations, nodeDrainTimeout, nodeDeletionTimeout, preDrainDeleteHookTimeout
+//     and preTerminateDeleteHookTimeout. Changes to these fields are propagated to the existing MachineSets and
+//     Machines without replacing them.
+//   - all the other fields: changes to these fields trigger a rollout, i.e. the Machines are replaced.
+
+// CopyInPlaceMutableFields copies the in-place mutable fields of the src machine template to the dst machine template,
+// preserving the machine-template-hash label of dst, if any.
+func CopyInPlaceMutableFields(dst, src *clusterv1.MachineTemplateSpec) {
+	hash, hasHash := dst.Labels[DefaultMachineDeploymentUniqueLabelKey]
+	dst.Labels = nil
+	if src.Labels != nil || hasHash {
+		dst.Labels = make(map[string]string, len(src.Labels)+1)
+		for k, v := range src.Labels {
+			dst.Labels[k] = v
+		}
+		if hasHash {
+			dst.Labels[DefaultMachineDeploymentUniqueLabelKey] = hash
+		}
+	}
+
+	dst.Annotations = nil
+	if src.Annotations != nil {
+		dst.Annotations = make(map[string]string, len(src.Annotations))
+		for k, v := range src.Annotations {
+			dst.Annotations[k] = v
+		}
+	}
+
+	CopyInPlaceMutableMachineSpecFields(&dst.Spec, &src.Spec)
+}
+
+// CopyInPlaceMutableMachineSpecFields copies the in-place mutable fields of the src machine spec to the dst machine spec.
+func CopyInPlaceMutableMachineSpecFields(dst, src *clusterv1.MachineSpec) {
+	dst.NodeDrainTimeout = src.NodeDrainTimeout.DeepCopy()
+	dst.NodeDeletionTimeout = src.NodeDeletionTimeout.DeepCopy()
+	dst.PreDrainDeleteHookTimeout = src.PreDrainDeleteHookTimeout.DeepCopy()
+	dst.PreTerminateDeleteHookTimeout = src.PreTerminateDeleteHookTimeout.DeepCopy()
+	dst.NodeShutdownGracePeriod = src.NodeShutdownGracePeriod.DeepCopy()
+	dst.DeletionPolicy = src.DeletionPolicy
+}
+
+// UpdateMachineInPlaceMutableFields updates the in-place mutable fields of an existing Machine from the given machine
+// template. Labels and annotations are added or updated, but never removed, given that they could have been set on the
+// Machine by other controllers or users. It returns true if the Machine has been changed.
+func UpdateMachineInPlaceMutableFields(machine *clusterv1.Machine, template *clusterv1.MachineTemplateSpec) bool {
+	original := machine.DeepCopy()
+
+	if len(template.Labels) > 0 && machine.Labels == nil {
+		machine.Labels = make(map[string]string, len(template.Labels))
+	}
+	for k, v := range template.Labels {
+		machine.Labels[k] = v
+	}
+	if len(template.Annotations) > 0 && machine.Annotations == nil {
+		machine.Annotations = make(map[string]string, len(template.Annotations))
+	}
+	for k, v := range template.Annotations {
+		// The delete-machine annotation marks individual Machines; never add, nor overwrite it from the template.
+		if k == clusterv1.DeleteMachineAnnotation {
+			continue
+		}
+		machine.Annotations[k] = v
+	}
+	CopyInPlaceMutableMachineSpecFields(&machine.Spec, &template.Spec)
+
+	return !apiequality.Semantic.DeepEqual(original, machine)
+}
+
 // EqualMachineTemplate returns true if two given machineTemplateSpec are equal,
-// ignoring the diff in value of Labels["machine-template-hash"], and the version from external references.
+// ignoring the in-place mutable fields, and the version from external references.
 func EqualMachineTemplate(template1, template2 *clusterv1.MachineTemplateSpec) bool {
 	t1Copy := template1.DeepCopy()
 	t2Copy := template2.DeepCopy()
 
-	// Remove `machine-template-hash` from the comparison:
-	// 1. The hash result would be different upon machineTemplateSpec API changes
-	//    (e.g. the addition of a new field will cause the hash code to change)
-	// 2. The deployment template won't have hash labels
+	// Remove the in-place mutable fields from the comparison, given that changes to these fields
+	// are propagated to the existing MachineSets and Machines instead of triggering a rollout.
+	// This includes the `machine-template-hash` label, given that the deployment template won't have it.
 	delete(t1Copy.Labels, DefaultMachineDeploymentUniqueLabelKey)
 	delete(t2Copy.Labels, DefaultMachineDeploymentUniqueLabelKey)
+	CopyInPlaceMutableFields(t1Copy, t2Copy)
 
 	// Remove the version part from the references APIVersion field,
 	// for more details see issue #2183 and #2140.
@@ -413,15 +492,24 @@ func EqualMachineTemplate(template1, template2 *clusterv1.MachineTemplateSpec) b
 	return apiequality.Semantic.DeepEqual(t1Copy, t2Copy)
 }
 
+// equalSelector returns true if the MachineSet selector is equal to the MachineDeployment selector, ignoring the
+// machine-template-hash label. Changes to the selector trigger a rollout, given that the labels of the machine template,
+// which are otherwise in-place mutable, must match the selector.
+func equalSelector(msSelector, deploymentSelector *metav1.LabelSelector) bool {
+	msCopy := msSelector.DeepCopy()
+	delete(msCopy.MatchLabels, DefaultMachineDeploymentUniqueLabelKey)
+	return apiequality.Semantic.DeepEqual(msCopy, deploymentSelector)
+}
+
 // FindNewMachineSet returns the new MS this given deployment targets (the one with the same machine template).
 func FindNewMachineSet(deployment *clusterv1.MachineDeployment, msList []*clusterv1.MachineSet) *clusterv1.MachineSet {
 	sort.Sort(MachineSetsByCreationTimestamp(msList))
-	for i := range msList {
-		if EqualMachineTemplate(&msList[i].Spec.Template, &deployment.Spec.Template) {
-			// In rare cases, such as after cluster upgrades, Deployment may end up with
-			// having more than one new MachineSets that have the same template,
-			// see https://github.com/kubernetes/kubernetes/issues/40415
-			// We deterministically choose the oldest new MachineSet with matching template hash.
+	for i := len(msList) - 1; i >= 0; i-- {
+		if EqualMachineTemplate(&msList[i].Spec.Template, &deployment.Spec.Template) && equalSelector(&msList[i].Spec.Selector, &deployment.Spec.Selector) {
+			// Deployment may end up with having more than one new MachineSets that have the same template,
+			// either in rare cases such as after cluster upgrades, see https://github.com/kubernetes/kubernetes/issues/40415,
+			// or because the MachineSets differ only in the in-place mutable fields, which are ignored.
+			// We deterministically choose the newest new MachineSet, which is the most recent rollout.
 			return msList[i]
 		}
 	}
@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apiserver/pkg/storage/names"
 	"k8s.io/klog/klogr"
+	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 )
 
@@ -133,25 +134,25 @@ func TestEqualMachineTemplate(t *testing.T) {
 			Name:     "Same spec, the label is different, the former doesn't have machine-template-hash label, same number of labels",
 			Former:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{"something": "else"}),
 			Latter:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-2"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
 			Name:     "Same spec, the label is different, the latter doesn't have machine-template-hash label, same number of labels",
 			Former:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1"}),
 			Latter:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{"something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
 			Name:     "Same spec, the label is different, and the machine-template-hash label value is the same",
 			Former:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1"}),
 			Latter:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
-			Name:     "Different spec, same labels",
+			Name:     "Same spec, different annotations",
 			Former:   generateMachineTemplateSpec("foo", map[string]string{"former": "value"}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
 			Latter:   generateMachineTemplateSpec("foo", map[string]string{"latter": "value"}, map[string]string{DefaultMachineDeploymentUniqueLabelKey: "value-1", "something": "else"}),
-			Expected: false,
+			Expected: true,
 		},
 		{
 			Name:     "Different spec, different machine-template-hash label value",
@@ -166,9 +167,51 @@ func TestEqualMachineTemplate(t *testing.T) {
 			Expected: false,
 		},
 		{
-			Name:     "Different spec, different labels",
+			Name:     "Same spec, different labels",
 			Former:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{"something": "else"}),
 			Latter:   generateMachineTemplateSpec("foo", map[string]string{}, map[string]string{"nothing": "else"}),
+			Expected: true,
+		},
+		{
+			Name: "Same spec, different in-place mutable timeouts",
+			Former: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					NodeDrainTimeout:    &metav1.Duration{Duration: time.Minute},
+					NodeDeletionTimeout: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			Latter: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					PreDrainDeleteHookTimeout:     &metav1.Duration{Duration: time.Minute},
+					PreTerminateDeleteHookTimeout: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			Expected: true,
+		},
+		{
+			Name: "Different spec, different version",
+			Former: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version: pointer.StringPtr("v1.19.1"),
+				},
+			},
+			Latter: clusterv1.MachineTemplateSpec{
+				ObjectMeta: clusterv1.ObjectMeta{
+					Labels: map[string]string{},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version: pointer.StringPtr("v1.20.2"),
+				},
+			},
 			Expected: false,
 		},
 		{
@@ -268,6 +311,122 @@ func TestEqualMachineTemplate(t *testing.T) {
 	}
 }
 
+func TestCopyInPlaceMutableFields(t *testing.T) {
+	g := NewWithT(t)
+
+	src := &clusterv1.MachineTemplateSpec{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels:      map[string]string{"foo": "bar"},
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: clusterv1.MachineSpec{
+			Version:             pointer.StringPtr("v1.20.2"),
+			NodeDrainTimeout:    &metav1.Duration{Duration: time.Minute},
+			NodeDeletionTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+	dst := &clusterv1.MachineTemplateSpec{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels:      map[string]string{DefaultMachineDeploymentUniqueLabelKey: "hash", "old": "label"},
+			Annotations: map[string]string{"old": "annotation"},
+		},
+		Spec: clusterv1.MachineSpec{
+			Version:          pointer.StringPtr("v1.19.1"),
+			NodeDrainTimeout: &metav1.Duration{Duration: time.Second},
+		},
+	}
+
+	CopyInPlaceMutableFields(dst, src)
+	g.Expect(dst.Labels).To(Equal(map[string]string{DefaultMachineDeploymentUniqueLabelKey: "hash", "foo": "bar"}))
+	g.Expect(dst.Annotations).To(Equal(map[string]string{"foo": "bar"}))
+	g.Expect(dst.Spec.NodeDrainTimeout).To(Equal(src.Spec.NodeDrainTimeout))
+	g.Expect(dst.Spec.NodeDeletionTimeout).To(Equal(src.Spec.NodeDeletionTimeout))
+	// Fields triggering a rollout are not copied.
+	g.Expect(*dst.Spec.Version).To(Equal("v1.19.1"))
+}
+
+func TestUpdateMachineInPlaceMutableFields(t *testing.T) {
+	template := &clusterv1.MachineTemplateSpec{
+		ObjectMeta: clusterv1.ObjectMeta{
+			Labels:      map[string]string{"foo": "bar"},
+			Annotations: map[string]string{"foo": "bar", clusterv1.DeleteMachineAnnotation: "template"},
+		},
+		Spec: clusterv1.MachineSpec{
+			Version:          pointer.StringPtr("v1.20.2"),
+			NodeDrainTimeout: &metav1.Duration{Duration: time.Minute},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		machine         *clusterv1.Machine
+		wantChanged     bool
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+	}{
+		{
+			name: "machine without labels and annotations",
+			machine: &clusterv1.Machine{
+				Spec: clusterv1.MachineSpec{Version: pointer.StringPtr("v1.19.1")},
+			},
+			wantChanged:     true,
+			wantLabels:      map[string]string{"foo": "bar"},
+			wantAnnotations: map[string]string{"foo": "bar"},
+		},
+		{
+			name: "labels and annotations are updated but not removed",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"foo": "baz", "other": "label"},
+					Annotations: map[string]string{"other": "annotation"},
+				},
+				Spec: clusterv1.MachineSpec{Version: pointer.StringPtr("v1.19.1")},
+			},
+			wantChanged:     true,
+			wantLabels:      map[string]string{"foo": "bar", "other": "label"},
+			wantAnnotations: map[string]string{"foo": "bar", "other": "annotation"},
+		},
+		{
+			name: "delete-machine annotation is preserved",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Annotations: map[string]string{clusterv1.DeleteMachineAnnotation: "user"},
+				},
+				Spec: clusterv1.MachineSpec{Version: pointer.StringPtr("v1.19.1")},
+			},
+			wantChanged:     true,
+			wantLabels:      map[string]string{"foo": "bar"},
+			wantAnnotations: map[string]string{"foo": "bar", clusterv1.DeleteMachineAnnotation: "user"},
+		},
+		{
+			name: "machine up to date",
+			machine: &clusterv1.Machine{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{"foo": "bar"},
+					Annotations: map[string]string{"foo": "bar"},
+				},
+				Spec: clusterv1.MachineSpec{
+					Version:          pointer.StringPtr("v1.19.1"),
+					NodeDrainTimeout: &metav1.Duration{Duration: time.Minute},
+				},
+			},
+			wantLabels:      map[string]string{"foo": "bar"},
+			wantAnnotations: map[string]string{"foo": "bar"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(UpdateMachineInPlaceMutableFields(tt.machine, template)).To(Equal(tt.wantChanged))
+			g.Expect(tt.machine.Labels).To(Equal(tt.wantLabels))
+			g.Expect(tt.machine.Annotations).To(Equal(tt.wantAnnotations))
+			g.Expect(tt.machine.Spec.NodeDrainTimeout).To(Equal(template.Spec.NodeDrainTimeout))
+			g.Expect(*tt.machine.Spec.Version).To(Equal("v1.19.1"))
+		})
+	}
+}
+
 func TestFindNewMachineSet(t *testing.T) {
 	now := metav1.Now()
 	later := metav1.Time{Time: now.Add(time.Minute)}
@@ -299,10 +458,10 @@ func TestFindNewMachineSet(t *testing.T) {
 			expected:   &newMS,
 		},
 		{
-			Name:       "Get the oldest new MachineSet when there are more than one MachineSet with the same template",
+			Name:       "Get the newest new MachineSet when there are more than one MachineSet with the same template",
 			deployment: deployment,
 			msList:     []*clusterv1.MachineSet{&newMS, &oldMS, &newMSDup},
-			expected:   &newMSDup,
+			expected:   &newMS,
 		},
 		{
 			Name:       "Get nil new MachineSet",
@@ -334,6 +493,7 @@ func TestFindOldMachineSets(t *testing.T) {
 	newMS.CreationTimestamp = later
 
 	newMSDup := generateMS(deployment)
+	*(newMSDup.Spec.Replicas) = 1
 	newMSDup.Labels[DefaultMachineDeploymentUniqueLabelKey] = "different-hash"
 	newMSDup.CreationTimestamp = now
 
@@ -372,11 +532,11 @@ func TestFindOldMachineSets(t *testing.T) {
 			expectedRequire: nil,
 		},
 		{
-			Name:            "Get old MachineSets with two new MachineSets, only the oldest new MachineSet is seen as new MachineSet",
+			Name:            "Get old MachineSets with two new MachineSets, only the newest new MachineSet is seen as new MachineSet",
 			deployment:      deployment,
 			msList:          []*clusterv1.MachineSet{&oldMS, &newMS, &newMSDup},
-			expected:        []*clusterv1.MachineSet{&oldMS, &newMS},
-			expectedRequire: []*clusterv1.MachineSet{&newMS},
+			expected:        []*clusterv1.MachineSet{&oldMS, &newMSDup},
+			expectedRequire: []*clusterv1.MachineSet{&newMSDup},
 		},
 		{
 			Name:            "Get empty old MachineSets",
@@ -0,0 +1,191 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	machineSetMachinesByPhase = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_machineset_machines",
+			Help: "Number of Machines of a MachineSet in each phase.",
+		},
+		[]string{"namespace", "name", "phase"},
+	)
+
+	machineDeploymentMachinesByPhase = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_machinedeployment_machines",
+			Help: "Number of Machines of a MachineDeployment in each phase.",
+		},
+		[]string{"namespace", "name", "phase"},
+	)
+
+	machineSetOwnerMachines = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_machineset_owner_machines",
+			Help: "Number of Machines of a MachineSet, labeled by the owning Cluster and MachineDeployment.",
+		},
+		machineSetOwnerLabels,
+	)
+
+	machineSetOwnerMachineSeconds = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capi_machineset_owner_machine_seconds_total",
+			Help: "Cumulative seconds of existence of the Machines of a MachineSet, labeled by the owning Cluster and MachineDeployment.",
+		},
+		machineSetOwnerLabels,
+	)
+
+	machineHealthCheckObservedUnhealthy = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capi_machinehealthcheck_observed_unhealthy_machines",
+			Help: "Number of unhealthy Machines which would be remediated by a MachineHealthCheck in the Observe remediation mode.",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// machineSetOwnerObservations stores the last observation of the Machines of each MachineSet, for accumulating
+	// the machine seconds and for deleting the series when the owner labels change or the MachineSet is deleted.
+	machineSetOwnerObservations = struct {
+		sync.Mutex
+		m map[types.NamespacedName]machineSetOwnerObservation
+	}{m: map[types.NamespacedName]machineSetOwnerObservation{}}
+)
+
+// machineSetOwnerLabels are the labels of the MachineSet owner metrics; the values are stable for the lifetime
+// of a MachineSet, so the metrics can be aggregated by tenant without joining them with other metrics.
+var machineSetOwnerLabels = []string{"namespace", "cluster", "machinedeployment", "machineset"}
+
+type machineSetOwnerObservation struct {
+	labels   []string
+	machines int32
+	time     time.Time
+}
+
+func init() {
+	metrics.Registry.MustRegister(machineSetMachinesByPhase, machineDeploymentMachinesByPhase, machineSetOwnerMachines, machineSetOwnerMachineSeconds, machineHealthCheckObservedUnhealthy)
+}
+
+// machinesByPhaseValues returns the counts of Machines indexed by the phase label value.
+func machinesByPhaseValues(m clusterv1.MachinesByPhase) map[string]int32 {
+	return map[string]int32{
+		string(clusterv1.MachinePhasePending):      m.Pending,
+		string(clusterv1.MachinePhaseProvisioning): m.Provisioning,
+		string(clusterv1.MachinePhaseRunning):      m.Running,
+		string(clusterv1.MachinePhaseDeleting):     m.Deleting,
+		string(clusterv1.MachinePhaseFailed):       m.Failed,
+	}
+}
+
+func recordMachineSetMachinesByPhase(ms *clusterv1.MachineSet, m clusterv1.MachinesByPhase) {
+	for phase, count := range machinesByPhaseValues(m) {
+		machineSetMachinesByPhase.WithLabelValues(ms.Namespace, ms.Name, phase).Set(float64(count))
+	}
+}
+
+func deleteMachineSetMachinesByPhase(key types.NamespacedName) {
+	for phase := range machinesByPhaseValues(clusterv1.MachinesByPhase{}) {
+		machineSetMachinesByPhase.DeleteLabelValues(key.Namespace, key.Name, phase)
+	}
+}
+
+func recordMachineDeploymentMachinesByPhase(md *clusterv1.MachineDeployment, m clusterv1.MachinesByPhase) {
+	for phase, count := range machinesByPhaseValues(m) {
+		machineDeploymentMachinesByPhase.WithLabelValues(md.Namespace, md.Name, phase).Set(float64(count))
+	}
+}
+
+func deleteMachineDeploymentMachinesByPhase(key types.NamespacedName) {
+	for phase := range machinesByPhaseValues(clusterv1.MachinesByPhase{}) {
+		machineDeploymentMachinesByPhase.DeleteLabelValues(key.Namespace, key.Name, phase)
+	}
+}
+
+func recordMachineHealthCheckObservedUnhealthy(m *clusterv1.MachineHealthCheck, unhealthy int) {
+	machineHealthCheckObservedUnhealthy.WithLabelValues(m.Namespace, m.Name).Set(float64(unhealthy))
+}
+
+func deleteMachineHealthCheckObservedUnhealthy(key types.NamespacedName) {
+	machineHealthCheckObservedUnhealthy.DeleteLabelValues(key.Namespace, key.Name)
+}
+
+// machineSetOwnerLabelValues returns the values of the owner labels for a MachineSet; the machinedeployment label
+// is empty for MachineSets not controlled by a MachineDeployment.
+func machineSetOwnerLabelValues(ms *clusterv1.MachineSet) []string {
+	var machineDeployment string
+	if ref := metav1.GetControllerOf(ms); ref != nil && ref.Kind == "MachineDeployment" {
+		machineDeployment = ref.Name
+	}
+	return []string{ms.Namespace, ms.Spec.ClusterName, machineDeployment, ms.Name}
+}
+
+// recordMachineSetOwnerMachines records the number of Machines of a MachineSet observed at the given time, and adds
+// the machine seconds elapsed since the previous observation, computed on the number of Machines observed then.
+func recordMachineSetOwnerMachines(ms *clusterv1.MachineSet, machines int32, now time.Time) {
+	key := types.NamespacedName{Namespace: ms.Namespace, Name: ms.Name}
+	labels := machineSetOwnerLabelValues(ms)
+
+	machineSetOwnerObservations.Lock()
+	defer machineSetOwnerObservations.Unlock()
+
+	if prev, ok := machineSetOwnerObservations.m[key]; ok {
+		if !equalLabelValues(prev.labels, labels) {
+			machineSetOwnerMachines.DeleteLabelValues(prev.labels...)
+			machineSetOwnerMachineSeconds.DeleteLabelValues(prev.labels...)
+		} else if now.After(prev.time) {
+			machineSetOwnerMachineSeconds.WithLabelValues(labels...).Add(float64(prev.machines) * now.Sub(prev.time).Seconds())
+		}
+	}
+
+	machineSetOwnerMachines.WithLabelValues(labels...).Set(float64(machines))
+	// Initialize the counter, so the series exists since the first observation.
+	machineSetOwnerMachineSeconds.WithLabelValues(labels...)
+	machineSetOwnerObservations.m[key] = machineSetOwnerObservation{labels: labels, machines: machines, time: now}
+}
+
+func deleteMachineSetOwnerMachines(key types.NamespacedName) {
+	machineSetOwnerObservations.Lock()
+	defer machineSetOwnerObservations.Unlock()
+
+	if prev, ok := machineSetOwnerObservations.m[key]; ok {
+		machineSetOwnerMachines.DeleteLabelValues(prev.labels...)
+		machineSetOwnerMachineSeconds.DeleteLabelValues(prev.labels...)
+		delete(machineSetOwnerObservations.m, key)
+	}
+}
+
+func equalLabelValues(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
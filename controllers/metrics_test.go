@@ -0,0 +1,71 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestRecordMachineSetOwnerMachines(t *testing.T) {
+	g := NewWithT(t)
+
+	ms := &clusterv1.MachineSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: "tenant-a",
+			Name:      "md-0-abcde",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: clusterv1.GroupVersion.String(), Kind: "MachineDeployment", Name: "md-0", Controller: pointer.BoolPtr(true)},
+			},
+		},
+		Spec: clusterv1.MachineSetSpec{ClusterName: "cluster-a"},
+	}
+	key := types.NamespacedName{Namespace: ms.Namespace, Name: ms.Name}
+	defer deleteMachineSetOwnerMachines(key)
+
+	machines := func() float64 {
+		return testutil.ToFloat64(machineSetOwnerMachines.WithLabelValues("tenant-a", "cluster-a", "md-0", "md-0-abcde"))
+	}
+	machineSeconds := func() float64 {
+		return testutil.ToFloat64(machineSetOwnerMachineSeconds.WithLabelValues("tenant-a", "cluster-a", "md-0", "md-0-abcde"))
+	}
+
+	now := time.Now()
+	recordMachineSetOwnerMachines(ms, 3, now)
+	g.Expect(machines()).To(Equal(3.0))
+	g.Expect(machineSeconds()).To(Equal(0.0))
+
+	// Machine seconds are accumulated on the number of Machines observed at the beginning of the interval.
+	recordMachineSetOwnerMachines(ms, 1, now.Add(10*time.Second))
+	g.Expect(machines()).To(Equal(1.0))
+	g.Expect(machineSeconds()).To(Equal(30.0))
+
+	recordMachineSetOwnerMachines(ms, 1, now.Add(20*time.Second))
+	g.Expect(machineSeconds()).To(Equal(40.0))
+
+	// Series are deleted with the MachineSet.
+	before := testutil.CollectAndCount(machineSetOwnerMachines)
+	deleteMachineSetOwnerMachines(key)
+	g.Expect(testutil.CollectAndCount(machineSetOwnerMachines)).To(Equal(before - 1))
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	certificateAuthorityCheckTimeout = 5 * time.Second
+)
+
+// ErrCertificateAuthorityMismatch is returned when the certificate served by a workload cluster API server
+// can't be verified using the certificate authority stored in the Cluster's kubeconfig secret.
+var ErrCertificateAuthorityMismatch = errors.New("the API server certificate is not signed by the certificate authority in the kubeconfig")
+
+// VerifyCertificateAuthority dials the API server defined in the given REST config and checks if the certificate it serves
+// can be verified using the certificate authority of the REST config.
+// An error wrapping ErrCertificateAuthorityMismatch is returned if the verification fails because of an unknown authority;
+// any other error (e.g. the API server not being reachable) is returned as is.
+func VerifyCertificateAuthority(ctx context.Context, cfg *rest.Config) error {
+	tlsConfig, err := rest.TLSConfigFor(cfg)
+	if err != nil {
+		return errors.Wrap(err, "failed to get TLS config from REST config")
+	}
+	// Nothing to verify if the API server is not served over TLS or if verification is disabled.
+	if tlsConfig == nil || tlsConfig.InsecureSkipVerify {
+		return nil
+	}
+
+	hostURL, _, err := rest.DefaultServerURL(cfg.Host, "", schema.GroupVersion{}, true)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse API server host %q", cfg.Host)
+	}
+	address := hostURL.Host
+	if hostURL.Port() == "" {
+		address = net.JoinHostPort(hostURL.Hostname(), "443")
+	}
+
+	dialer := &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: certificateAuthorityCheckTimeout},
+		Config:    tlsConfig,
+	}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		if IsCertificateAuthorityMismatch(err) {
+			return errors.Wrapf(ErrCertificateAuthorityMismatch, "failed to verify certificate served by %s: %v", address, err)
+		}
+		return errors.Wrapf(err, "failed to connect to %s", address)
+	}
+	return conn.Close()
+}
+
+// IsCertificateAuthorityMismatch returns true if the error is caused by a certificate signed by an unknown authority.
+func IsCertificateAuthorityMismatch(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrCertificateAuthorityMismatch) {
+		return true
+	}
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	return errors.As(err, &unknownAuthorityErr)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/cluster-api/util/certs"
+	"sigs.k8s.io/cluster-api/util/secret"
+)
+
+func TestVerifyCertificateAuthority(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	otherCA := &secret.Certificate{Purpose: secret.ClusterCA}
+	if err := otherCA.Generate(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("succeeds when the served certificate is signed by the kubeconfig CA", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cfg := &rest.Config{
+			Host: server.URL,
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData: certs.EncodeCertPEM(server.Certificate()),
+			},
+		}
+		g.Expect(VerifyCertificateAuthority(context.Background(), cfg)).To(Succeed())
+	})
+
+	t.Run("returns ErrCertificateAuthorityMismatch when the CA has been rotated", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cfg := &rest.Config{
+			Host: server.URL,
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData: otherCA.KeyPair.Cert,
+			},
+		}
+		err := VerifyCertificateAuthority(context.Background(), cfg)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(errors.Is(err, ErrCertificateAuthorityMismatch)).To(BeTrue())
+		g.Expect(IsCertificateAuthorityMismatch(err)).To(BeTrue())
+	})
+
+	t.Run("skips the check when TLS verification is disabled", func(t *testing.T) {
+		g := NewWithT(t)
+
+		cfg := &rest.Config{
+			Host: server.URL,
+			TLSClientConfig: rest.TLSClientConfig{
+				Insecure: true,
+			},
+		}
+		g.Expect(VerifyCertificateAuthority(context.Background(), cfg)).To(Succeed())
+	})
+
+	t.Run("returns a generic error when the API server is not reachable", func(t *testing.T) {
+		g := NewWithT(t)
+
+		unreachable := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		unreachable.Close()
+
+		cfg := &rest.Config{
+			Host: unreachable.URL,
+			TLSClientConfig: rest.TLSClientConfig{
+				CAData: certs.EncodeCertPEM(unreachable.Certificate()),
+			},
+		}
+		err := VerifyCertificateAuthority(context.Background(), cfg)
+		g.Expect(err).To(HaveOccurred())
+		g.Expect(IsCertificateAuthorityMismatch(err)).To(BeFalse())
+	})
+}
+
+func TestIsCertificateAuthorityMismatch(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(IsCertificateAuthorityMismatch(nil)).To(BeFalse())
+	g.Expect(IsCertificateAuthorityMismatch(errors.New("connection refused"))).To(BeFalse())
+	g.Expect(IsCertificateAuthorityMismatch(errors.Wrap(x509.UnknownAuthorityError{}, "Get \"https://foo\""))).To(BeTrue())
+	g.Expect(IsCertificateAuthorityMismatch(errors.Wrap(ErrCertificateAuthorityMismatch, "failed"))).To(BeTrue())
+}
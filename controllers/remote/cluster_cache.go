@@ -29,6 +29,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
@@ -37,6 +38,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -54,21 +56,93 @@ type ClusterCacheTracker struct {
 	client client.Client
 	scheme *runtime.Scheme
 
+	// serviceAccountTokens, if set, configures the tracker to access workload clusters using
+	// short-lived service account tokens.
+	serviceAccountTokens *ServiceAccountTokenOptions
+
+	// idleTimeout, if set, is the time after which the clusterAccessor of a cluster which has not been accessed
+	// is torn down; it is created again on the next access.
+	idleTimeout time.Duration
+
+	// qps and burst, if set, override the client-side rate limits of the clients of the workload clusters.
+	qps   float32
+	burst int
+
 	lock             sync.RWMutex
 	clusterAccessors map[client.ObjectKey]*clusterAccessor
 }
 
+// ClusterCacheTrackerOption configures a ClusterCacheTracker.
+type ClusterCacheTrackerOption func(*ClusterCacheTracker)
+
+// WithServiceAccountTokens configures the ClusterCacheTracker to exchange the credentials in the kubeconfig secret
+// of a Cluster for short-lived tokens of a service account with the given permissions, created in the workload
+// cluster on first access; the credentials in the kubeconfig secret are not retained by the clusterAccessor.
+func WithServiceAccountTokens(o ServiceAccountTokenOptions) ClusterCacheTrackerOption {
+	return func(t *ClusterCacheTracker) {
+		t.serviceAccountTokens = &o
+	}
+}
+
+// WithIdleTimeout configures the ClusterCacheTracker to tear down the client, the cache and the watches of a
+// cluster when they have not been accessed for the given time, reducing the memory used for clusters that are
+// rarely reconciled; they are created again on the next access. Watches are established again only when the
+// consumers call Watch, so the timeout should be longer than the interval at which the consumers reconcile.
+func WithIdleTimeout(d time.Duration) ClusterCacheTrackerOption {
+	return func(t *ClusterCacheTracker) {
+		t.idleTimeout = d
+	}
+}
+
+// ValidateIdleTimeout returns an error if an idle timeout set with WithIdleTimeout is shorter than the sync period of
+// the consumers; in that case the client, the cache and the watches of all the clusters would be torn down and
+// created again at each periodic reconciliation.
+func ValidateIdleTimeout(idleTimeout, syncPeriod time.Duration) error {
+	if idleTimeout > 0 && idleTimeout < syncPeriod {
+		return errors.Errorf("the idle timeout %s must be longer than the sync period %s", idleTimeout, syncPeriod)
+	}
+	return nil
+}
+
+// WithRateLimits configures the ClusterCacheTracker to create the clients of the workload clusters with the given
+// client-side rate limits, instead of the client-go defaults; zero values keep the defaults.
+func WithRateLimits(qps float32, burst int) ClusterCacheTrackerOption {
+	return func(t *ClusterCacheTracker) {
+		t.qps = qps
+		t.burst = burst
+	}
+}
+
 // NewClusterCacheTracker creates a new ClusterCacheTracker.
-func NewClusterCacheTracker(log logr.Logger, manager ctrl.Manager) (*ClusterCacheTracker, error) {
-	return &ClusterCacheTracker{
+func NewClusterCacheTracker(log logr.Logger, mgr ctrl.Manager, options ...ClusterCacheTrackerOption) (*ClusterCacheTracker, error) {
+	t := &ClusterCacheTracker{
 		log:              log,
-		client:           manager.GetClient(),
-		scheme:           manager.GetScheme(),
+		client:           mgr.GetClient(),
+		scheme:           mgr.GetScheme(),
 		clusterAccessors: make(map[client.ObjectKey]*clusterAccessor),
-	}, nil
+	}
+	for _, o := range options {
+		o(t)
+	}
+
+	if t.serviceAccountTokens != nil {
+		t.serviceAccountTokens.setDefaults()
+		if err := t.serviceAccountTokens.validate(); err != nil {
+			return nil, errors.Wrap(err, "invalid service account token options")
+		}
+	}
+
+	if t.idleTimeout > 0 {
+		if err := mgr.Add(manager.RunnableFunc(t.evictIdleAccessors)); err != nil {
+			return nil, errors.Wrap(err, "failed to add the idle clusterAccessor eviction to the manager")
+		}
+	}
+
+	return t, nil
 }
 
 // GetClient returns a cached client for the given cluster.
+// Leases are read from a cache scoped to the kube-node-lease namespace, i.e. only the kubelet Leases can be read.
 func (t *ClusterCacheTracker) GetClient(ctx context.Context, cluster client.ObjectKey) (client.Client, error) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
@@ -86,6 +160,10 @@ type clusterAccessor struct {
 	cache   *stoppableCache
 	client  client.Client
 	watches sets.String
+	info    *clusterInfoCache
+
+	// lastAccess is the last time the clusterAccessor has been accessed by a consumer; it is protected by the tracker lock.
+	lastAccess time.Time
 }
 
 // clusterAccessorExists returns true if a clusterAccessor exists for cluster.
@@ -102,6 +180,7 @@ func (t *ClusterCacheTracker) clusterAccessorExists(cluster client.ObjectKey) bo
 func (t *ClusterCacheTracker) getClusterAccessorLH(ctx context.Context, cluster client.ObjectKey) (*clusterAccessor, error) {
 	a := t.clusterAccessors[cluster]
 	if a != nil {
+		a.lastAccess = time.Now()
 		return a, nil
 	}
 
@@ -110,17 +189,46 @@ func (t *ClusterCacheTracker) getClusterAccessorLH(ctx context.Context, cluster
 		return nil, errors.Wrap(err, "error creating client and cache for remote cluster")
 	}
 
+	a.lastAccess = time.Now()
 	t.clusterAccessors[cluster] = a
 
 	return a, nil
 }
 
+// getRESTConfig returns the REST config the clients of the given cluster are created with, read from the kubeconfig
+// secret of the cluster and with the rate limits of the ClusterCacheTracker applied.
+func (t *ClusterCacheTracker) getRESTConfig(ctx context.Context, cluster client.ObjectKey) (*rest.Config, error) {
+	config, err := RESTConfig(ctx, ClusterCacheControllerName, t.client, cluster)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error fetching REST client config for remote cluster %q", cluster.String())
+	}
+	if t.qps > 0 {
+		config.QPS = t.qps
+	}
+	if t.burst > 0 {
+		config.Burst = t.burst
+	}
+	return config, nil
+}
+
 // newClusterAccessor creates a new clusterAccessor.
 func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster client.ObjectKey) (*clusterAccessor, error) {
 	// Get a rest config for the remote cluster
-	config, err := RESTConfig(ctx, ClusterCacheControllerName, t.client, cluster)
+	config, err := t.getRESTConfig(ctx, cluster)
 	if err != nil {
-		return nil, errors.Wrapf(err, "error fetching REST client config for remote cluster %q", cluster.String())
+		return nil, err
+	}
+
+	// Exchange the credentials in the kubeconfig secret for short-lived service account tokens, if required.
+	if t.serviceAccountTokens != nil {
+		adminClient, err := client.New(config, client.Options{Scheme: t.scheme})
+		if err != nil {
+			return nil, errors.Wrapf(err, "error creating client for remote cluster %q", cluster.String())
+		}
+		config, err = newServiceAccountTokenConfig(ctx, config, adminClient, *t.serviceAccountTokens)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error setting up service account tokens for remote cluster %q", cluster.String())
+		}
 	}
 
 	// Create a mapper for it
@@ -135,6 +243,12 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 		return nil, errors.Wrapf(err, "error creating client for remote cluster %q", cluster.String())
 	}
 
+	// Create a discovery client for the remote cluster
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating discovery client for remote cluster %q", cluster.String())
+	}
+
 	// Create the cache for the remote cluster
 	cacheOptions := cache.Options{
 		Scheme: t.scheme,
@@ -145,9 +259,17 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 		return nil, errors.Wrapf(err, "error creating cache for remote cluster %q", cluster.String())
 	}
 
+	// Create the cache of the kubelet Leases, which are read only from the kube-node-lease namespace.
+	cacheOptions.Namespace = nodeLeaseNamespace
+	nodeLeaseCache, err := cache.New(config, cacheOptions)
+	if err != nil {
+		return nil, errors.Wrapf(err, "error creating node lease cache for remote cluster %q", cluster.String())
+	}
+
 	cacheCtx, cacheCtxCancel := context.WithCancel(ctx)
 
 	// We need to be able to stop the cache's shared informers, so wrap this in a stoppableCache.
+	// Cancelling the context stops the node lease cache as well.
 	cache := &stoppableCache{
 		Cache:      remoteCache,
 		cancelFunc: cacheCtxCancel,
@@ -155,6 +277,7 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 
 	// Start the cache!!!
 	go cache.Start(cacheCtx)
+	go nodeLeaseCache.Start(cacheCtx)
 
 	// Start cluster healthcheck!!!
 	go t.healthCheckCluster(cacheCtx, &healthCheckInput{
@@ -163,7 +286,7 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 	})
 
 	delegatingClient, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
-		CacheReader: cache,
+		CacheReader: &nodeLeaseCacheReader{Reader: cache, leases: nodeLeaseCache},
 		Client:      c,
 		UncachedObjects: []client.Object{
 			&corev1.ConfigMap{},
@@ -178,6 +301,7 @@ func (t *ClusterCacheTracker) newClusterAccessor(ctx context.Context, cluster cl
 		cache:   cache,
 		client:  delegatingClient,
 		watches: sets.NewString(),
+		info:    &clusterInfoCache{discovery: discoveryClient},
 	}, nil
 }
 
@@ -186,6 +310,12 @@ func (t *ClusterCacheTracker) deleteAccessor(cluster client.ObjectKey) {
 	t.lock.Lock()
 	defer t.lock.Unlock()
 
+	t.deleteAccessorLH(cluster)
+}
+
+// deleteAccessorLH stops a clusterAccessor's cache and removes the clusterAccessor from the tracker.
+// Note, this method requires t.lock to already be held (LH=lock held).
+func (t *ClusterCacheTracker) deleteAccessorLH(cluster client.ObjectKey) {
 	a, exists := t.clusterAccessors[cluster]
 	if !exists {
 		return
@@ -200,6 +330,39 @@ func (t *ClusterCacheTracker) deleteAccessor(cluster client.ObjectKey) {
 	delete(t.clusterAccessors, cluster)
 }
 
+// evictIdleAccessors periodically deletes the clusterAccessors which have not been accessed for longer than the
+// idle timeout, until the context is done.
+func (t *ClusterCacheTracker) evictIdleAccessors(ctx context.Context) error {
+	interval := t.idleTimeout / 4
+	if interval < healthCheckPollInterval {
+		interval = healthCheckPollInterval
+	}
+	wait.Until(func() {
+		t.evictIdleAccessorsOnce(time.Now())
+	}, interval, ctx.Done())
+	return nil
+}
+
+// evictIdleAccessorsOnce deletes the clusterAccessors which have not been accessed for longer than the idle timeout.
+func (t *ClusterCacheTracker) evictIdleAccessorsOnce(now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for cluster, a := range t.clusterAccessors {
+		if now.Sub(a.lastAccess) < t.idleTimeout {
+			continue
+		}
+		t.log.V(2).Info("Evicting idle clusterAccessor", "cluster", cluster.String(), "lastAccess", a.lastAccess)
+		t.deleteAccessorLH(cluster)
+	}
+}
+
+// InvalidateAccessor stops the cache and removes the clusterAccessor for the given cluster, if any, so that
+// a new one is created using the current kubeconfig secret the next time the cluster is accessed.
+func (t *ClusterCacheTracker) InvalidateAccessor(cluster client.ObjectKey) {
+	t.deleteAccessor(cluster)
+}
+
 // Watcher is a scoped-down interface from Controller that only knows how to watch.
 type Watcher interface {
 	// Watch watches src for changes, sending events to eventHandler if they pass predicates.
@@ -327,11 +490,17 @@ func (t *ClusterCacheTracker) healthCheckCluster(ctx context.Context, in *health
 		// An error here means there was either an issue connecting or the API returned an error.
 		// If no error occurs, reset the unhealthy counter.
 		_, err := restClient.Get().AbsPath(in.path).Timeout(in.requestTimeout).DoRaw(ctx)
+		if IsCertificateAuthorityMismatch(err) {
+			// The API server is serving a certificate that is not signed by the CA in the kubeconfig secret,
+			// e.g. because the CA has been rotated; there is no point in retrying with the current accessor.
+			return false, errors.Wrap(err, "the certificate authority of the workload cluster does not match the one in the kubeconfig secret")
+		}
 		if err != nil {
 			unhealthyCount++
 		} else {
 			unhealthyCount = 0
 		}
+		t.setClusterHealthy(in.cluster, err == nil)
 
 		if unhealthyCount >= in.unhealthyThreshold {
 			// Cluster is now considered unhealthy.
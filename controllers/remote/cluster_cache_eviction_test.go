@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestEvictIdleAccessorsOnce(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	idle := client.ObjectKey{Namespace: "default", Name: "idle"}
+	active := client.ObjectKey{Namespace: "default", Name: "active"}
+
+	newAccessor := func(lastAccess time.Time) (*clusterAccessor, context.Context) {
+		ctx, cancel := context.WithCancel(context.Background())
+		return &clusterAccessor{
+			cache:      &stoppableCache{cancelFunc: cancel},
+			watches:    sets.NewString(),
+			lastAccess: lastAccess,
+		}, ctx
+	}
+	idleAccessor, idleCtx := newAccessor(now.Add(-time.Hour))
+	activeAccessor, activeCtx := newAccessor(now.Add(-time.Minute))
+
+	tracker := &ClusterCacheTracker{
+		log:         log.Log,
+		idleTimeout: 30 * time.Minute,
+		clusterAccessors: map[client.ObjectKey]*clusterAccessor{
+			idle:   idleAccessor,
+			active: activeAccessor,
+		},
+	}
+
+	tracker.evictIdleAccessorsOnce(now)
+
+	g.Expect(tracker.clusterAccessorExists(idle)).To(BeFalse())
+	g.Expect(idleCtx.Err()).To(HaveOccurred())
+	g.Expect(tracker.clusterAccessorExists(active)).To(BeTrue())
+	g.Expect(activeCtx.Err()).ToNot(HaveOccurred())
+
+	tracker.evictIdleAccessorsOnce(now.Add(time.Hour))
+	g.Expect(tracker.clusterAccessorExists(active)).To(BeFalse())
+	g.Expect(activeCtx.Err()).To(HaveOccurred())
+}
+
+func TestValidateIdleTimeout(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ValidateIdleTimeout(0, 10*time.Minute)).To(Succeed())
+	g.Expect(ValidateIdleTimeout(10*time.Minute, 10*time.Minute)).To(Succeed())
+	g.Expect(ValidateIdleTimeout(30*time.Minute, 10*time.Minute)).To(Succeed())
+	g.Expect(ValidateIdleTimeout(5*time.Minute, 10*time.Minute)).NotTo(Succeed())
+}
@@ -20,6 +20,9 @@ import (
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/sets"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/utils/pointer"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -45,6 +48,7 @@ func NewTestClusterCacheTracker(log logr.Logger, cl client.Client, scheme *runti
 		cache:   nil,
 		client:  delegatingClient,
 		watches: sets.NewString(watchObjects...),
+		info:    &clusterInfoCache{discovery: &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{}}, healthy: pointer.BoolPtr(true)},
 	}
 	return testCacheTracker
 }
@@ -0,0 +1,245 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/oauth2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// defaultServiceAccountTokenTTL is the default lifetime of the tokens requested for the service account.
+	defaultServiceAccountTokenTTL = time.Hour
+
+	// minServiceAccountTokenTTL is the minimum lifetime of a token accepted by the TokenRequest API.
+	minServiceAccountTokenTTL = 10 * time.Minute
+)
+
+// ServiceAccountTokenOptions configures the ClusterCacheTracker to access workload clusters using short-lived
+// tokens of a service account, instead of the credentials in the kubeconfig secret of the Cluster.
+type ServiceAccountTokenOptions struct {
+	// Name of the service account, and of the ClusterRole and ClusterRoleBinding granting its permissions,
+	// created in the workload clusters. It should be unique for each controller manager, so actions can be
+	// attributed to it in the audit logs of the workload clusters.
+	Name string
+
+	// Namespace of the service account; defaults to kube-system.
+	Namespace string
+
+	// Rules returns the permissions granted to the service account in the workload clusters; they should be limited
+	// to what the controllers using the ClusterCacheTracker actually need. It is called each time the tracker
+	// creates the accessor of a workload cluster, so the rules can depend on state changing at runtime, e.g.
+	// feature gates.
+	Rules func() []rbacv1.PolicyRule
+
+	// TokenTTL is the requested lifetime of the tokens; tokens are refreshed once 80% of their lifetime has passed.
+	// Defaults to one hour, and can't be less than 10 minutes.
+	TokenTTL time.Duration
+}
+
+func (o *ServiceAccountTokenOptions) setDefaults() {
+	if o.Namespace == "" {
+		o.Namespace = metav1.NamespaceSystem
+	}
+	if o.TokenTTL == 0 {
+		o.TokenTTL = defaultServiceAccountTokenTTL
+	}
+}
+
+func (o *ServiceAccountTokenOptions) validate() error {
+	if o.Name == "" {
+		return errors.New("service account name is required")
+	}
+	if o.Rules == nil {
+		return errors.New("rules are required for the service account")
+	}
+	if o.TokenTTL < minServiceAccountTokenTTL {
+		return errors.Errorf("service account token TTL must be at least %s", minServiceAccountTokenTTL)
+	}
+	return nil
+}
+
+// clusterRole returns the ClusterRole granting the permissions of the service account; in addition to the
+// configured rules, the service account is allowed to request new tokens for itself, so the tokens can be
+// refreshed without using the credentials in the kubeconfig secret, and to perform health checks.
+func (o *ServiceAccountTokenOptions) clusterRole() *rbacv1.ClusterRole {
+	rules := append([]rbacv1.PolicyRule{}, o.Rules()...)
+	rules = append(rules,
+		rbacv1.PolicyRule{
+			APIGroups:     []string{""},
+			Resources:     []string{"serviceaccounts/token"},
+			ResourceNames: []string{o.Name},
+			Verbs:         []string{"create"},
+		},
+		rbacv1.PolicyRule{
+			NonResourceURLs: []string{"/"},
+			Verbs:           []string{"get"},
+		},
+	)
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: o.Name},
+		Rules:      rules,
+	}
+}
+
+// ensureServiceAccount creates the service account and grants it the configured permissions in the workload
+// cluster, using a client with the credentials in the kubeconfig secret of the Cluster.
+func ensureServiceAccount(ctx context.Context, c client.Client, o ServiceAccountTokenOptions) error {
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: o.Name, Namespace: o.Namespace},
+	}
+	if err := c.Create(ctx, sa); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create service account %s/%s", o.Namespace, o.Name)
+	}
+
+	role := o.clusterRole()
+	if err := c.Create(ctx, role); err != nil {
+		if !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create ClusterRole %s", o.Name)
+		}
+		// The rules may have changed, e.g. after an upgrade of the controller manager.
+		existing := &rbacv1.ClusterRole{}
+		if err := c.Get(ctx, client.ObjectKey{Name: o.Name}, existing); err != nil {
+			return errors.Wrapf(err, "failed to get ClusterRole %s", o.Name)
+		}
+		existing.Rules = role.Rules
+		if err := c.Update(ctx, existing); err != nil {
+			return errors.Wrapf(err, "failed to update ClusterRole %s", o.Name)
+		}
+	}
+
+	binding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: o.Name},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     o.Name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      o.Name,
+				Namespace: o.Namespace,
+			},
+		},
+	}
+	if err := c.Create(ctx, binding); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create ClusterRoleBinding %s", o.Name)
+	}
+
+	return nil
+}
+
+// serviceAccountTokenSource is an oauth2.TokenSource caching a short-lived token of a service account; once 80% of
+// the lifetime of the token has passed, a new token is requested authenticating with the current one.
+type serviceAccountTokenSource struct {
+	config    *rest.Config
+	namespace string
+	name      string
+	ttl       time.Duration
+
+	lock      sync.Mutex
+	token     *oauth2.Token
+	refreshAt time.Time
+	now       func() time.Time
+}
+
+// newServiceAccountTokenConfig ensures the service account exists in the workload cluster, and returns a copy of
+// the config authenticating with its short-lived tokens; the credentials in the given config are used only to
+// set up the service account and request the first token.
+func newServiceAccountTokenConfig(ctx context.Context, config *rest.Config, c client.Client, o ServiceAccountTokenOptions) (*rest.Config, error) {
+	if err := ensureServiceAccount(ctx, c, o); err != nil {
+		return nil, err
+	}
+
+	ts := &serviceAccountTokenSource{
+		config:    rest.AnonymousClientConfig(config),
+		namespace: o.Namespace,
+		name:      o.Name,
+		ttl:       o.TokenTTL,
+		now:       time.Now,
+	}
+	if err := ts.refresh(ctx, config); err != nil {
+		return nil, err
+	}
+
+	tokenConfig := rest.AnonymousClientConfig(config)
+	tokenConfig.WrapTransport = transport.TokenSourceWrapTransport(ts)
+	return tokenConfig, nil
+}
+
+// Token returns the cached token, refreshing it if required.
+func (s *serviceAccountTokenSource) Token() (*oauth2.Token, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.token != nil && s.now().Before(s.refreshAt) {
+		return s.token, nil
+	}
+
+	config := rest.CopyConfig(s.config)
+	if s.token != nil {
+		config.BearerToken = s.token.AccessToken
+	}
+	if err := s.refresh(context.Background(), config); err != nil {
+		return nil, err
+	}
+	return s.token, nil
+}
+
+// refresh requests a new token for the service account using the given config. Note, this method requires s.lock
+// to already be held, unless the token source is not yet in use.
+func (s *serviceAccountTokenSource) refresh(ctx context.Context, config *rest.Config) error {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return errors.Wrap(err, "failed to create client for requesting service account tokens")
+	}
+
+	expirationSeconds := int64(s.ttl.Seconds())
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	now := s.now()
+	tokenRequest, err = clientset.CoreV1().ServiceAccounts(s.namespace).CreateToken(ctx, s.name, tokenRequest, metav1.CreateOptions{})
+	if err != nil {
+		return errors.Wrapf(err, "failed to request a token for service account %s/%s", s.namespace, s.name)
+	}
+
+	expiry := tokenRequest.Status.ExpirationTimestamp.Time
+	s.token = &oauth2.Token{
+		AccessToken: tokenRequest.Status.Token,
+		TokenType:   "Bearer",
+		Expiry:      expiry,
+	}
+	s.refreshAt = now.Add(expiry.Sub(now) * 4 / 5)
+	return nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var nodeRule = rbacv1.PolicyRule{
+	APIGroups: []string{""},
+	Resources: []string{"nodes"},
+	Verbs:     []string{"get", "list", "watch"},
+}
+
+func nodeRules() []rbacv1.PolicyRule {
+	return []rbacv1.PolicyRule{nodeRule}
+}
+
+func TestServiceAccountTokenOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		options ServiceAccountTokenOptions
+		wantErr bool
+	}{
+		{
+			name:    "valid options",
+			options: ServiceAccountTokenOptions{Name: "capi", Rules: nodeRules},
+		},
+		{
+			name:    "name is required",
+			options: ServiceAccountTokenOptions{Rules: nodeRules},
+			wantErr: true,
+		},
+		{
+			name:    "rules are required",
+			options: ServiceAccountTokenOptions{Name: "capi"},
+			wantErr: true,
+		},
+		{
+			name:    "token TTL too short",
+			options: ServiceAccountTokenOptions{Name: "capi", Rules: nodeRules, TokenTTL: time.Minute},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			tt.options.setDefaults()
+			g.Expect(tt.options.Namespace).To(Equal(metav1.NamespaceSystem))
+
+			err := tt.options.validate()
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(tt.options.TokenTTL).To(Equal(defaultServiceAccountTokenTTL))
+		})
+	}
+}
+
+func TestEnsureServiceAccount(t *testing.T) {
+	g := NewWithT(t)
+
+	options := ServiceAccountTokenOptions{Name: "capi", Rules: nodeRules}
+	options.setDefaults()
+
+	// A ClusterRole with outdated rules is updated.
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(&rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: "capi"},
+	}).Build()
+
+	g.Expect(ensureServiceAccount(context.Background(), c, options)).To(Succeed())
+	// Ensuring the service account is idempotent.
+	g.Expect(ensureServiceAccount(context.Background(), c, options)).To(Succeed())
+
+	sa := &corev1.ServiceAccount{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Namespace: metav1.NamespaceSystem, Name: "capi"}, sa)).To(Succeed())
+
+	role := &rbacv1.ClusterRole{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "capi"}, role)).To(Succeed())
+	g.Expect(role.Rules).To(Equal(options.clusterRole().Rules))
+	g.Expect(role.Rules).To(ContainElement(rbacv1.PolicyRule{
+		APIGroups:     []string{""},
+		Resources:     []string{"serviceaccounts/token"},
+		ResourceNames: []string{"capi"},
+		Verbs:         []string{"create"},
+	}))
+
+	binding := &rbacv1.ClusterRoleBinding{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "capi"}, binding)).To(Succeed())
+	g.Expect(binding.RoleRef.Name).To(Equal("capi"))
+	g.Expect(binding.Subjects).To(ConsistOf(rbacv1.Subject{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      "capi",
+		Namespace: metav1.NamespaceSystem,
+	}))
+}
+
+func TestEnsureServiceAccountEvaluatesRules(t *testing.T) {
+	g := NewWithT(t)
+
+	podRule := rbacv1.PolicyRule{
+		APIGroups: []string{""},
+		Resources: []string{"pods"},
+		Verbs:     []string{"list"},
+	}
+	rules := []rbacv1.PolicyRule{nodeRule}
+	options := ServiceAccountTokenOptions{Name: "capi", Rules: func() []rbacv1.PolicyRule { return rules }}
+	options.setDefaults()
+
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+	g.Expect(ensureServiceAccount(context.Background(), c, options)).To(Succeed())
+
+	role := &rbacv1.ClusterRole{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "capi"}, role)).To(Succeed())
+	g.Expect(role.Rules).To(ContainElement(nodeRule))
+	g.Expect(role.Rules).NotTo(ContainElement(podRule))
+
+	// Rules changed at runtime are granted the next time the service account is ensured.
+	rules = append(rules, podRule)
+	g.Expect(ensureServiceAccount(context.Background(), c, options)).To(Succeed())
+
+	role = &rbacv1.ClusterRole{}
+	g.Expect(c.Get(context.Background(), client.ObjectKey{Name: "capi"}, role)).To(Succeed())
+	g.Expect(role.Rules).To(ContainElement(podRule))
+}
+
+func TestServiceAccountTokenSource(t *testing.T) {
+	g := NewWithT(t)
+
+	// The fake API server issues tokens named after the number of the request, and records the credentials used
+	// for each request.
+	var (
+		lock        sync.Mutex
+		issued      int
+		credentials []string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+
+		if r.URL.Path != "/api/v1/namespaces/kube-system/serviceaccounts/capi/token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		credentials = append(credentials, r.Header.Get("Authorization"))
+		issued++
+		tokenRequest := &authenticationv1.TokenRequest{
+			TypeMeta: metav1.TypeMeta{APIVersion: "authentication.k8s.io/v1", Kind: "TokenRequest"},
+			Status: authenticationv1.TokenRequestStatus{
+				Token:               fmt.Sprintf("token-%d", issued),
+				ExpirationTimestamp: metav1.NewTime(time.Now().Add(time.Hour)),
+			},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(tokenRequest)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	ts := &serviceAccountTokenSource{
+		config:    rest.AnonymousClientConfig(&rest.Config{Host: server.URL}),
+		namespace: metav1.NamespaceSystem,
+		name:      "capi",
+		ttl:       time.Hour,
+		now:       func() time.Time { return now },
+	}
+	g.Expect(ts.refresh(context.Background(), &rest.Config{Host: server.URL, BearerToken: "admin"})).To(Succeed())
+
+	// The token is cached until 80% of its lifetime has passed.
+	token, err := ts.Token()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(token.AccessToken).To(Equal("token-1"))
+
+	now = now.Add(45 * time.Minute)
+	token, err = ts.Token()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(token.AccessToken).To(Equal("token-1"))
+
+	// Then a new token is requested using the current one.
+	now = now.Add(5 * time.Minute)
+	token, err = ts.Token()
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(token.AccessToken).To(Equal("token-2"))
+
+	g.Expect(credentials).To(Equal([]string{"Bearer admin", "Bearer token-1"}))
+}
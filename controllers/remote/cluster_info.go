@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// clusterInfoTTL is the time after which the discovery information of a workload cluster is refreshed.
+const clusterInfoTTL = 10 * time.Minute
+
+// ClusterInfo contains the discovery information of a workload cluster.
+type ClusterInfo struct {
+	// Version is the Kubernetes version of the workload cluster.
+	Version version.Info
+
+	// GroupVersions are the API group versions served by the workload cluster, e.g. apps/v1.
+	GroupVersions sets.String
+
+	// Healthy is the result of the last health check of the workload cluster.
+	// It is nil until the workload cluster is probed by the first health check, i.e. its health is unknown.
+	Healthy *bool
+
+	// LastRefreshed is the time the version and the group versions were read from the workload cluster.
+	LastRefreshed time.Time
+}
+
+// HasGroupVersion returns true if the workload cluster serves the given API group version, e.g. apps/v1.
+func (i *ClusterInfo) HasGroupVersion(groupVersion string) bool {
+	return i.GroupVersions.Has(groupVersion)
+}
+
+// clusterInfoCache caches the discovery information of a workload cluster.
+type clusterInfoCache struct {
+	lock      sync.Mutex
+	discovery discovery.DiscoveryInterface
+	info      *ClusterInfo
+
+	// healthy is guarded by a separate lock, so it can be read while discovery calls are in progress.
+	// It is nil until the workload cluster is probed by the first health check.
+	healthLock sync.Mutex
+	healthy    *bool
+}
+
+// get returns the cached discovery information, refreshing it if it is older than clusterInfoTTL.
+func (c *clusterInfoCache) get(now time.Time) (*ClusterInfo, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.info == nil || now.Sub(c.info.LastRefreshed) >= clusterInfoTTL {
+		serverVersion, err := c.discovery.ServerVersion()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the server version")
+		}
+		groups, err := c.discovery.ServerGroups()
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the server API groups")
+		}
+
+		groupVersions := sets.NewString()
+		for _, g := range groups.Groups {
+			for _, v := range g.Versions {
+				groupVersions.Insert(v.GroupVersion)
+			}
+		}
+		c.info = &ClusterInfo{
+			Version:       *serverVersion,
+			GroupVersions: groupVersions,
+			LastRefreshed: now,
+		}
+	}
+
+	info := *c.info
+	info.GroupVersions = sets.NewString(c.info.GroupVersions.UnsortedList()...)
+	info.Healthy = c.getHealthy()
+	return &info, nil
+}
+
+// setHealthy records the result of the last health check.
+func (c *clusterInfoCache) setHealthy(healthy bool) {
+	c.healthLock.Lock()
+	defer c.healthLock.Unlock()
+
+	c.healthy = &healthy
+}
+
+// getHealthy returns a copy of the result of the last health check, or nil if the workload cluster has not been probed yet.
+func (c *clusterInfoCache) getHealthy() *bool {
+	c.healthLock.Lock()
+	defer c.healthLock.Unlock()
+
+	if c.healthy == nil {
+		return nil
+	}
+	healthy := *c.healthy
+	return &healthy
+}
+
+// isUnhealthy returns true if the last health check failed; workload clusters that have not been probed yet are
+// neither healthy nor unhealthy.
+func (c *clusterInfoCache) isUnhealthy() bool {
+	c.healthLock.Lock()
+	defer c.healthLock.Unlock()
+
+	return c.healthy != nil && !*c.healthy
+}
+
+// GetClusterInfo returns the Kubernetes version, the API group versions and the health of the given cluster.
+// The version and the group versions are cached, and refreshed lazily once they get older than 10 minutes,
+// so controllers can call this on every reconcile without issuing discovery calls to the workload cluster.
+func (t *ClusterCacheTracker) GetClusterInfo(ctx context.Context, cluster client.ObjectKey) (*ClusterInfo, error) {
+	t.lock.Lock()
+	accessor, err := t.getClusterAccessorLH(ctx, cluster)
+	t.lock.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	// NB. The cluster info is read without holding the tracker lock, so slow workload clusters
+	// do not block access to the other clusters.
+	return accessor.info.get(time.Now())
+}
+
+// setClusterHealthy records the result of the last health check for the given cluster, if tracked.
+func (t *ClusterCacheTracker) setClusterHealthy(cluster client.ObjectKey, healthy bool) {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	if a, ok := t.clusterAccessors[cluster]; ok {
+		a.info.setHealthy(healthy)
+	}
+}
+
+// IsClusterUnhealthy returns true if the last health check for the given cluster failed; clusters that are not
+// tracked or not probed yet are not considered unhealthy, and no clusterAccessor is created for them.
+func (t *ClusterCacheTracker) IsClusterUnhealthy(cluster client.ObjectKey) bool {
+	t.lock.RLock()
+	defer t.lock.RUnlock()
+
+	a, ok := t.clusterAccessors[cluster]
+	if !ok {
+		return false
+	}
+	return a.info.isUnhealthy()
+}
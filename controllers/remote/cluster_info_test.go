@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestClusterInfoCache(t *testing.T) {
+	g := NewWithT(t)
+
+	fakeDiscovery := &fakediscovery.FakeDiscovery{
+		Fake:               &clienttesting.Fake{},
+		FakedServerVersion: &version.Info{GitVersion: "v1.20.2"},
+	}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "v1"},
+		{GroupVersion: "apps/v1"},
+	}
+	c := &clusterInfoCache{discovery: fakeDiscovery}
+	now := time.Now()
+
+	info, err := c.get(now)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(info.Version.GitVersion).To(Equal("v1.20.2"))
+	g.Expect(info.HasGroupVersion("apps/v1")).To(BeTrue())
+	g.Expect(info.HasGroupVersion("batch/v1")).To(BeFalse())
+	g.Expect(info.Healthy).To(BeNil())
+	g.Expect(fakeDiscovery.Actions()).To(HaveLen(2))
+
+	// The cached info is returned until the TTL expires.
+	fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: "v1.21.0"}
+	c.setHealthy(true)
+	info, err = c.get(now.Add(clusterInfoTTL - time.Second))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(info.Version.GitVersion).To(Equal("v1.20.2"))
+	g.Expect(info.Healthy).NotTo(BeNil())
+	g.Expect(*info.Healthy).To(BeTrue())
+	g.Expect(fakeDiscovery.Actions()).To(HaveLen(2))
+
+	// The info is refreshed once the TTL is expired.
+	info, err = c.get(now.Add(clusterInfoTTL))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(info.Version.GitVersion).To(Equal("v1.21.0"))
+	g.Expect(fakeDiscovery.Actions()).To(HaveLen(4))
+
+	// A failed health check is reported as unhealthy.
+	c.setHealthy(false)
+	info, err = c.get(now.Add(clusterInfoTTL))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(info.Healthy).NotTo(BeNil())
+	g.Expect(*info.Healthy).To(BeFalse())
+}
+
+func TestIsClusterUnhealthy(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := client.ObjectKey{Namespace: "default", Name: "test"}
+	tracker := &ClusterCacheTracker{
+		clusterAccessors: map[client.ObjectKey]*clusterAccessor{
+			cluster: {info: &clusterInfoCache{}},
+		},
+	}
+
+	// Clusters that are not tracked are not considered unhealthy.
+	g.Expect(tracker.IsClusterUnhealthy(client.ObjectKey{Namespace: "default", Name: "other"})).To(BeFalse())
+
+	// Clusters that are not probed yet are not considered unhealthy.
+	g.Expect(tracker.IsClusterUnhealthy(cluster)).To(BeFalse())
+
+	tracker.setClusterHealthy(cluster, false)
+	g.Expect(tracker.IsClusterUnhealthy(cluster)).To(BeTrue())
+	tracker.setClusterHealthy(cluster, true)
+	g.Expect(tracker.IsClusterUnhealthy(cluster)).To(BeFalse())
+}
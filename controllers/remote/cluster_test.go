@@ -125,3 +125,41 @@ func TestNewClusterClient(t *testing.T) {
 		gs.Expect(apierrors.IsNotFound(err)).To(BeFalse())
 	})
 }
+
+func TestClusterCacheTrackerGetRESTConfig(t *testing.T) {
+	g := NewWithT(t)
+
+	testScheme := runtime.NewScheme()
+	g.Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+	c := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(validSecret).Build()
+
+	t.Run("without rate limits", func(t *testing.T) {
+		gs := NewWithT(t)
+
+		tracker := &ClusterCacheTracker{client: c}
+		restConfig, err := tracker.getRESTConfig(ctx, clusterWithValidKubeConfig)
+		gs.Expect(err).NotTo(HaveOccurred())
+		gs.Expect(restConfig.Host).To(Equal("https://test-cluster-api.nodomain.example.com:6443"))
+		gs.Expect(restConfig.QPS).To(BeZero())
+		gs.Expect(restConfig.Burst).To(BeZero())
+	})
+
+	t.Run("with rate limits", func(t *testing.T) {
+		gs := NewWithT(t)
+
+		tracker := &ClusterCacheTracker{client: c}
+		WithRateLimits(50, 100)(tracker)
+		restConfig, err := tracker.getRESTConfig(ctx, clusterWithValidKubeConfig)
+		gs.Expect(err).NotTo(HaveOccurred())
+		gs.Expect(restConfig.QPS).To(Equal(float32(50)))
+		gs.Expect(restConfig.Burst).To(Equal(100))
+	})
+
+	t.Run("cluster with no kubeconfig", func(t *testing.T) {
+		gs := NewWithT(t)
+
+		tracker := &ClusterCacheTracker{client: c}
+		_, err := tracker.getRESTConfig(ctx, clusterWithNoKubeConfig)
+		gs.Expect(err).To(MatchError(ContainSubstring("not found")))
+	})
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"context"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// nodeLeaseNamespace is the namespace where the kubelet Leases are stored.
+const nodeLeaseNamespace = "kube-node-lease"
+
+// nodeLeaseCacheReader reads Leases from a cache scoped to the kube-node-lease namespace, and all the other objects
+// from the cache of the whole workload cluster, so that reading the kubelet Leases does not require informers on
+// the Leases of all the namespaces, e.g. the frequently renewed leader election Leases.
+type nodeLeaseCacheReader struct {
+	client.Reader
+	leases client.Reader
+}
+
+// Get retrieves an obj for the given object key from the cache, reading Leases from the node Lease cache.
+func (r *nodeLeaseCacheReader) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if _, ok := obj.(*coordinationv1.Lease); ok {
+		return r.leases.Get(ctx, key, obj)
+	}
+	return r.Reader.Get(ctx, key, obj)
+}
+
+// List retrieves a list of objects from the cache, reading Leases from the node Lease cache.
+func (r *nodeLeaseCacheReader) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if _, ok := list.(*coordinationv1.LeaseList); ok {
+		return r.leases.List(ctx, list, opts...)
+	}
+	return r.Reader.List(ctx, list, opts...)
+}
@@ -0,0 +1,54 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remote
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestNodeLeaseCacheReader(t *testing.T) {
+	g := NewWithT(t)
+
+	testScheme := runtime.NewScheme()
+	g.Expect(scheme.AddToScheme(testScheme)).To(Succeed())
+
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}}
+	lease := &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{Namespace: nodeLeaseNamespace, Name: "node-1"}}
+	r := &nodeLeaseCacheReader{
+		Reader: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(node).Build(),
+		leases: fake.NewClientBuilder().WithScheme(testScheme).WithObjects(lease).Build(),
+	}
+
+	g.Expect(r.Get(ctx, client.ObjectKey{Name: "node-1"}, &corev1.Node{})).To(Succeed())
+	g.Expect(r.Get(ctx, client.ObjectKey{Namespace: nodeLeaseNamespace, Name: "node-1"}, &coordinationv1.Lease{})).To(Succeed())
+
+	nodes := &corev1.NodeList{}
+	g.Expect(r.List(ctx, nodes)).To(Succeed())
+	g.Expect(nodes.Items).To(HaveLen(1))
+	leases := &coordinationv1.LeaseList{}
+	g.Expect(r.List(ctx, leases)).To(Succeed())
+	g.Expect(leases.Items).To(HaveLen(1))
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"time"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// withResync requeues an object after the given resync period if the object requires it and the reconciliation
+// succeeded without requeuing it sooner. This allows controllers to periodically re-reconcile only the objects
+// that need it, e.g. objects waiting for external state, instead of relying on the manager's SyncPeriod,
+// which re-reconciles every object of every kind.
+func withResync(res ctrl.Result, err error, period time.Duration, resync bool) ctrl.Result {
+	if err != nil || !resync || period <= 0 || res.Requeue {
+		return res
+	}
+	if res.RequeueAfter == 0 || res.RequeueAfter > period {
+		res.RequeueAfter = period
+	}
+	return res
+}
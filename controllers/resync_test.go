@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func TestWithResync(t *testing.T) {
+	tests := []struct {
+		name   string
+		res    ctrl.Result
+		err    error
+		period time.Duration
+		resync bool
+		want   ctrl.Result
+	}{
+		{
+			name:   "requeues after the resync period",
+			period: time.Minute,
+			resync: true,
+			want:   ctrl.Result{RequeueAfter: time.Minute},
+		},
+		{
+			name:   "does not requeue objects not requiring it",
+			period: time.Minute,
+			want:   ctrl.Result{},
+		},
+		{
+			name:   "does not requeue if the resync period is zero",
+			resync: true,
+			want:   ctrl.Result{},
+		},
+		{
+			name:   "does not change the result on errors",
+			err:    errors.New("failed"),
+			period: time.Minute,
+			resync: true,
+			want:   ctrl.Result{},
+		},
+		{
+			name:   "does not change the result if already requeuing",
+			res:    ctrl.Result{Requeue: true},
+			period: time.Minute,
+			resync: true,
+			want:   ctrl.Result{Requeue: true},
+		},
+		{
+			name:   "keeps a shorter requeue",
+			res:    ctrl.Result{RequeueAfter: time.Second},
+			period: time.Minute,
+			resync: true,
+			want:   ctrl.Result{RequeueAfter: time.Second},
+		},
+		{
+			name:   "shortens a longer requeue",
+			res:    ctrl.Result{RequeueAfter: time.Hour},
+			period: time.Minute,
+			resync: true,
+			want:   ctrl.Result{RequeueAfter: time.Minute},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(withResync(tt.res, tt.err, tt.period, tt.resync)).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestIsTerminalMachinePhase(t *testing.T) {
+	g := NewWithT(t)
+
+	for _, phase := range []clusterv1.MachinePhase{clusterv1.MachinePhaseRunning, clusterv1.MachinePhaseFailed, clusterv1.MachinePhaseDeleted} {
+		g.Expect(isTerminalMachinePhase(phase)).To(BeTrue(), string(phase))
+	}
+	for _, phase := range []clusterv1.MachinePhase{clusterv1.MachinePhasePending, clusterv1.MachinePhaseProvisioning, clusterv1.MachinePhaseProvisioned, clusterv1.MachinePhaseDeleting, clusterv1.MachinePhaseUnknown} {
+		g.Expect(isTerminalMachinePhase(phase)).To(BeFalse(), string(phase))
+	}
+}
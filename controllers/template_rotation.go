@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
+	"sigs.k8s.io/cluster-api/util"
+	utilconversion "sigs.k8s.io/cluster-api/util/conversion"
+	"sigs.k8s.io/cluster-api/util/patch"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileExternalTemplateReference makes sure the template referenced by ref exists and is owned by the Cluster,
+// and records the hash of its spec in the hashAnnotation of obj (the MachineSet or MachineDeployment holding the reference).
+//
+// If the template does not exist anymore, e.g. because it has been deleted and recreated with a different name by a GitOps
+// workflow, and allowRotation is true, the template is resolved by looking for a template of the same kind with the same
+// spec hash; in this case ref is updated to point to the new template and true is returned.
+func reconcileExternalTemplateReference(ctx context.Context, c client.Client, restConfig *rest.Config, cluster *clusterv1.Cluster, obj metav1.Object, ref *corev1.ObjectReference, hashAnnotation string, allowRotation bool) (bool, error) {
+	log := ctrl.LoggerFrom(ctx)
+
+	if !strings.HasSuffix(ref.Kind, external.TemplateSuffix) {
+		return false, nil
+	}
+
+	if err := utilconversion.ConvertReferenceAPIContract(ctx, c, restConfig, ref); err != nil {
+		return false, err
+	}
+
+	rotated := false
+	template, err := external.Get(ctx, c, ref, cluster.Namespace)
+	if err != nil {
+		hash, ok := obj.GetAnnotations()[hashAnnotation]
+		if !apierrors.IsNotFound(errors.Cause(err)) || !allowRotation || !ok {
+			return false, err
+		}
+
+		notFoundErr := err
+		template, err = getTemplateByHash(ctx, c, ref, cluster.Namespace, hash)
+		if err != nil {
+			return false, err
+		}
+		if template == nil {
+			return false, errors.Wrapf(notFoundErr, "failed to find a replacement with template hash %q", hash)
+		}
+
+		log.Info("Template not found, rotating reference to a template with the same hash",
+			"kind", ref.Kind, "old", ref.Name, "new", template.GetName(), "hash", hash)
+		ref.Name = template.GetName()
+		rotated = true
+	}
+
+	patchHelper, err := patch.NewHelper(template, c)
+	if err != nil {
+		return false, err
+	}
+
+	template.SetOwnerReferences(util.EnsureOwnerRef(template.GetOwnerReferences(), metav1.OwnerReference{
+		APIVersion: clusterv1.GroupVersion.String(),
+		Kind:       "Cluster",
+		Name:       cluster.Name,
+		UID:        cluster.UID,
+	}))
+
+	if err := patchHelper.Patch(ctx, template); err != nil {
+		return false, err
+	}
+
+	hash, err := computeTemplateHash(template)
+	if err != nil {
+		return false, err
+	}
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[hashAnnotation] = hash
+	obj.SetAnnotations(annotations)
+
+	return rotated, nil
+}
+
+// getTemplateByHash returns the first template, sorted by name, of the same kind of ref and whose spec hash is equal to hash.
+// If no template matches, nil is returned.
+func getTemplateByHash(ctx context.Context, c client.Client, ref *corev1.ObjectReference, namespace, hash string) (*unstructured.Unstructured, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(ref.GroupVersionKind().GroupVersion().WithKind(ref.Kind + "List"))
+	if err := c.List(ctx, list, client.InNamespace(namespace)); err != nil {
+		return nil, errors.Wrapf(err, "failed to list %s in namespace %q", ref.Kind, namespace)
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		return list.Items[i].GetName() < list.Items[j].GetName()
+	})
+
+	for i := range list.Items {
+		template := &list.Items[i]
+		if !template.GetDeletionTimestamp().IsZero() {
+			continue
+		}
+		templateHash, err := computeTemplateHash(template)
+		if err != nil {
+			return nil, err
+		}
+		if templateHash == hash {
+			return template, nil
+		}
+	}
+	return nil, nil
+}
+
+// computeTemplateHash returns the hash of the spec of a template.
+func computeTemplateHash(template *unstructured.Unstructured) (string, error) {
+	spec, _, err := unstructured.NestedFieldNoCopy(template.Object, "spec")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get spec from %s %q", template.GetKind(), template.GetName())
+	}
+
+	hasher := fnv.New32a()
+	mdutil.DeepHashObject(hasher, spec)
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32())), nil
+}
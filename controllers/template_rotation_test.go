@@ -0,0 +1,287 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newInfrastructureMachineTemplate(name string, spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"kind":       "InfrastructureMachineTemplate",
+			"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+			},
+			"spec": spec,
+		},
+	}
+}
+
+// newTemplateRotationScheme returns a scheme allowing the fake client to list the generic infrastructure templates.
+func newTemplateRotationScheme(g *WithT) *runtime.Scheme {
+	s := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(s)).To(Succeed())
+	g.Expect(apiextensionsv1.AddToScheme(s)).To(Succeed())
+	s.AddKnownTypeWithName(schema.GroupVersionKind{
+		Group:   "infrastructure.cluster.x-k8s.io",
+		Version: "v1alpha4",
+		Kind:    "InfrastructureMachineTemplateList",
+	}, &unstructured.UnstructuredList{})
+	return s
+}
+
+func TestComputeTemplateHash(t *testing.T) {
+	g := NewWithT(t)
+
+	specA := map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"size": "large", "zone": "a"}}}
+	specB := map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"size": "small", "zone": "a"}}}
+
+	hashA, err := computeTemplateHash(newInfrastructureMachineTemplate("template-a", specA))
+	g.Expect(err).NotTo(HaveOccurred())
+	hashRecreated, err := computeTemplateHash(newInfrastructureMachineTemplate("template-a-recreated", specA))
+	g.Expect(err).NotTo(HaveOccurred())
+	hashB, err := computeTemplateHash(newInfrastructureMachineTemplate("template-b", specB))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(hashA).To(Equal(hashRecreated), "templates with the same spec should have the same hash regardless of their name")
+	g.Expect(hashA).NotTo(Equal(hashB))
+}
+
+func TestReconcileExternalTemplateReference(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newTemplateRotationScheme(g)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+			UID:       "cluster-uid",
+		},
+	}
+
+	spec := map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"size": "large"}}}
+	otherSpec := map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"size": "small"}}}
+	hash, err := computeTemplateHash(newInfrastructureMachineTemplate("template", spec))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newRef := func(name string) *corev1.ObjectReference {
+		return &corev1.ObjectReference{
+			Kind:       "InfrastructureMachineTemplate",
+			APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+			Name:       name,
+			Namespace:  "default",
+		}
+	}
+
+	tests := []struct {
+		name           string
+		templates      []client.Object
+		annotations    map[string]string
+		ref            *corev1.ObjectReference
+		allowRotation  bool
+		expectRotated  bool
+		expectRefName  string
+		expectNotFound bool
+	}{
+		{
+			name:          "records the template hash when the template exists",
+			templates:     []client.Object{newInfrastructureMachineTemplate("template", spec)},
+			ref:           newRef("template"),
+			allowRotation: true,
+			expectRefName: "template",
+		},
+		{
+			name: "rotates the reference to a template with the same hash",
+			templates: []client.Object{
+				newInfrastructureMachineTemplate("template-other", otherSpec),
+				newInfrastructureMachineTemplate("template-recreated", spec),
+			},
+			annotations:   map[string]string{clusterv1.InfrastructureTemplateHashAnnotation: hash},
+			ref:           newRef("template"),
+			allowRotation: true,
+			expectRotated: true,
+			expectRefName: "template-recreated",
+		},
+		{
+			name:           "fails if rotation is not allowed",
+			templates:      []client.Object{newInfrastructureMachineTemplate("template-recreated", spec)},
+			annotations:    map[string]string{clusterv1.InfrastructureTemplateHashAnnotation: hash},
+			ref:            newRef("template"),
+			allowRotation:  false,
+			expectRefName:  "template",
+			expectNotFound: true,
+		},
+		{
+			name:           "fails if no template has the same hash",
+			templates:      []client.Object{newInfrastructureMachineTemplate("template-other", otherSpec)},
+			annotations:    map[string]string{clusterv1.InfrastructureTemplateHashAnnotation: hash},
+			ref:            newRef("template"),
+			allowRotation:  true,
+			expectRefName:  "template",
+			expectNotFound: true,
+		},
+		{
+			name:           "fails if the template hash has never been recorded",
+			templates:      []client.Object{newInfrastructureMachineTemplate("template-recreated", spec)},
+			ref:            newRef("template"),
+			allowRotation:  true,
+			expectRefName:  "template",
+			expectNotFound: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs := append([]client.Object{external.TestGenericInfrastructureTemplateCRD.DeepCopy(), cluster.DeepCopy()}, tt.templates...)
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+			ms := &clusterv1.MachineSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "ms",
+					Namespace:   "default",
+					Annotations: tt.annotations,
+				},
+			}
+
+			rotated, err := reconcileExternalTemplateReference(ctx, c, nil, cluster, ms, tt.ref, clusterv1.InfrastructureTemplateHashAnnotation, tt.allowRotation)
+			g.Expect(tt.ref.Name).To(Equal(tt.expectRefName))
+			if tt.expectNotFound {
+				g.Expect(err).To(HaveOccurred())
+				g.Expect(apierrors.IsNotFound(errors.Cause(err))).To(BeTrue())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(rotated).To(Equal(tt.expectRotated))
+			g.Expect(ms.Annotations).To(HaveKeyWithValue(clusterv1.InfrastructureTemplateHashAnnotation, hash))
+
+			template, err := external.Get(ctx, c, tt.ref, "default")
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(template.GetOwnerReferences()).To(ContainElement(metav1.OwnerReference{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+			}))
+		})
+	}
+}
+
+func TestMachineSetReconciler_reconcileTemplateReferences(t *testing.T) {
+	g := NewWithT(t)
+	scheme := newTemplateRotationScheme(g)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "default",
+		},
+	}
+
+	spec := map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{"size": "large"}}}
+	hash, err := computeTemplateHash(newInfrastructureMachineTemplate("template", spec))
+	g.Expect(err).NotTo(HaveOccurred())
+
+	newMachineSet := func(owners ...metav1.OwnerReference) *clusterv1.MachineSet {
+		return &clusterv1.MachineSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "ms",
+				Namespace:       "default",
+				OwnerReferences: owners,
+				Annotations:     map[string]string{clusterv1.InfrastructureTemplateHashAnnotation: hash},
+			},
+			Spec: clusterv1.MachineSetSpec{
+				Template: clusterv1.MachineTemplateSpec{
+					Spec: clusterv1.MachineSpec{
+						InfrastructureRef: corev1.ObjectReference{
+							Kind:       "InfrastructureMachineTemplate",
+							APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+							Name:       "template",
+						},
+					},
+				},
+			},
+		}
+	}
+
+	t.Run("rotates the references of a standalone MachineSet", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(external.TestGenericInfrastructureTemplateCRD.DeepCopy(), cluster.DeepCopy(), newInfrastructureMachineTemplate("template-recreated", spec)).
+			Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &MachineSetReconciler{Client: c, recorder: recorder}
+
+		ms := newMachineSet()
+		g.Expect(r.reconcileTemplateReferences(ctx, cluster, ms)).To(Succeed())
+		g.Expect(ms.Spec.Template.Spec.InfrastructureRef.Name).To(Equal("template-recreated"))
+		g.Expect(conditions.IsTrue(ms, clusterv1.TemplatesResolvedCondition)).To(BeTrue())
+		g.Expect(recorder.Events).To(Receive(ContainSubstring("TemplateRotated")))
+	})
+
+	t.Run("surfaces FailedTemplateResolution for MachineSets controlled by a MachineDeployment", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(external.TestGenericInfrastructureTemplateCRD.DeepCopy(), cluster.DeepCopy(), newInfrastructureMachineTemplate("template-recreated", spec)).
+			Build()
+		recorder := record.NewFakeRecorder(10)
+		r := &MachineSetReconciler{Client: c, recorder: recorder}
+
+		ms := newMachineSet(metav1.OwnerReference{
+			APIVersion: clusterv1.GroupVersion.String(),
+			Kind:       "MachineDeployment",
+			Name:       "md",
+			Controller: pointer.BoolPtr(true),
+		})
+		g.Expect(r.reconcileTemplateReferences(ctx, cluster, ms)).To(Succeed())
+		g.Expect(ms.Spec.Template.Spec.InfrastructureRef.Name).To(Equal("template"))
+		g.Expect(conditions.IsFalse(ms, clusterv1.TemplatesResolvedCondition)).To(BeTrue())
+		g.Expect(conditions.GetReason(ms, clusterv1.TemplatesResolvedCondition)).To(Equal(clusterv1.FailedTemplateResolutionReason))
+		g.Expect(*conditions.GetSeverity(ms, clusterv1.TemplatesResolvedCondition)).To(Equal(clusterv1.ConditionSeverityError))
+		g.Expect(recorder.Events).To(Receive(ContainSubstring(clusterv1.FailedTemplateResolutionReason)))
+
+		// Machines are not created while templates can't be resolved.
+		ms.Spec.Replicas = pointer.Int32Ptr(1)
+		g.Expect(r.syncReplicas(ctx, cluster, ms, nil)).To(Succeed())
+		machines := &clusterv1.MachineList{}
+		g.Expect(c.List(ctx, machines)).To(Succeed())
+		g.Expect(machines.Items).To(BeEmpty())
+	})
+}
@@ -69,6 +69,32 @@ type KubeadmControlPlaneSpec struct {
 	// NOTE: NodeDrainTimeout is different from `kubectl drain --timeout`
 	// +optional
 	NodeDrainTimeout *metav1.Duration `json:"nodeDrainTimeout,omitempty"`
+
+	// NodeDeletionTimeout is the total amount of time that the controller will spend on deleting the node of a
+	// controlplane machine once its infrastructure has been deleted.
+	// Defaults to 10 seconds; a value of 0 means that the node deletion is retried without any time limitations.
+	// +optional
+	NodeDeletionTimeout *metav1.Duration `json:"nodeDeletionTimeout,omitempty"`
+
+	// MachineMetadata contains the labels and annotations applied to the controlplane machines.
+	// Changes to MachineMetadata are propagated to the existing machines without triggering a rollout;
+	// labels and annotations removed from MachineMetadata are not removed from the existing machines.
+	// +optional
+	MachineMetadata clusterv1.ObjectMeta `json:"machineMetadata,omitempty"`
+
+	// NamingTemplate is a Go template used to generate the names of the controlplane machines.
+	// The template can reference .ClusterName, .OwnerName (the name of the KubeadmControlPlane) and .Index,
+	// which is incremented when a generated name collides with an existing machine; the functions "random N"
+	// and "trunc N STRING" are also available.
+	// Defaults to an empty string, meaning that the names are generated from the KubeadmControlPlane name with a random suffix.
+	// +optional
+	NamingTemplate string `json:"namingTemplate,omitempty"`
+
+	// TemplateMetadataPolicy controls the labels and the annotations cloned from the infrastructure template
+	// onto the infrastructure machines of the controlplane.
+	// Defaults to nil, meaning that only the metadata of the template spec is cloned.
+	// +optional
+	TemplateMetadataPolicy *clusterv1.TemplateMetadataPolicy `json:"templateMetadataPolicy,omitempty"`
 }
 
 // KubeadmControlPlaneStatus defines the observed state of KubeadmControlPlane.
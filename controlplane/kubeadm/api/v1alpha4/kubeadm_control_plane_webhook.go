@@ -29,7 +29,9 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	kubeadmv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
 	"sigs.k8s.io/cluster-api/util/container"
+	"sigs.k8s.io/cluster-api/util/naming"
 	"sigs.k8s.io/cluster-api/util/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -67,6 +69,9 @@ func (in *KubeadmControlPlane) Default() {
 func (in *KubeadmControlPlane) ValidateCreate() error {
 	allErrs := in.validateCommon()
 	allErrs = append(allErrs, in.validateEtcd(nil)...)
+	if err := kubernetesversion.Validate(field.NewPath("spec", "version"), &in.Spec.Version, nil); err != nil {
+		allErrs = append(allErrs, err)
+	}
 	if len(allErrs) > 0 {
 		return apierrors.NewInvalid(GroupVersion.WithKind("KubeadmControlPlane").GroupKind(), in.Name, allErrs)
 	}
@@ -111,11 +116,19 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 		{spec, kubeadmConfigSpec, files},
 		{spec, kubeadmConfigSpec, "verbosity"},
 		{spec, kubeadmConfigSpec, users},
+		{spec, kubeadmConfigSpec, "proxy"},
+		{spec, kubeadmConfigSpec, "proxy", "*"},
+		{spec, kubeadmConfigSpec, "registryMirrors"},
 		{spec, "infrastructureTemplate", "name"},
 		{spec, "replicas"},
 		{spec, "version"},
 		{spec, "upgradeAfter"},
 		{spec, "nodeDrainTimeout"},
+		{spec, "nodeDeletionTimeout"},
+		{spec, "machineMetadata", "*"},
+		{spec, "namingTemplate"},
+		{spec, "templateMetadataPolicy"},
+		{spec, "templateMetadataPolicy", "*"},
 	}
 
 	allErrs := in.validateCommon()
@@ -157,6 +170,9 @@ func (in *KubeadmControlPlane) ValidateUpdate(old runtime.Object) error {
 	}
 
 	allErrs = append(allErrs, in.validateVersion(prev.Spec.Version)...)
+	if err := kubernetesversion.Validate(field.NewPath("spec", "version"), &in.Spec.Version, &prev.Spec.Version); err != nil {
+		allErrs = append(allErrs, err)
+	}
 	allErrs = append(allErrs, in.validateEtcd(prev)...)
 	allErrs = append(allErrs, in.validateCoreDNSVersion(prev)...)
 
@@ -269,6 +285,12 @@ func (in *KubeadmControlPlane) validateCommon() (allErrs field.ErrorList) {
 		allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "version"), in.Spec.Version, "must be a valid semantic version"))
 	}
 
+	if in.Spec.NamingTemplate != "" {
+		if err := naming.Validate(in.Spec.NamingTemplate); err != nil {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "namingTemplate"), in.Spec.NamingTemplate, err.Error()))
+		}
+	}
+
 	allErrs = append(allErrs, in.validateCoreDNSImage()...)
 
 	return allErrs
@@ -25,8 +25,10 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	kubeadmv1beta1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/types/v1beta1"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
 )
 
 func TestKubeadmControlPlaneDefault(t *testing.T) {
@@ -92,6 +94,12 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 	invalidVersion2 := valid.DeepCopy()
 	invalidVersion2.Spec.Version = "1.16.6"
 
+	validNamingTemplate := valid.DeepCopy()
+	validNamingTemplate.Spec.NamingTemplate = "{{ .ClusterName }}-cp-{{ .Index }}"
+
+	invalidNamingTemplate := valid.DeepCopy()
+	invalidNamingTemplate.Spec.NamingTemplate = "{{ .ClusterName }}_cp"
+
 	tests := []struct {
 		name      string
 		expectErr bool
@@ -142,6 +150,16 @@ func TestKubeadmControlPlaneValidateCreate(t *testing.T) {
 			expectErr: true,
 			kcp:       invalidVersion1,
 		},
+		{
+			name:      "should succeed when given a valid naming template",
+			expectErr: false,
+			kcp:       validNamingTemplate,
+		},
+		{
+			name:      "should return error when given a naming template rendering invalid names",
+			expectErr: true,
+			kcp:       invalidNamingTemplate,
+		},
 	}
 
 	for _, tt := range tests {
@@ -263,6 +281,18 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	validUpdate.Spec.Replicas = pointer.Int32Ptr(5)
 	now := metav1.NewTime(time.Now())
 	validUpdate.Spec.UpgradeAfter = &now
+	validUpdate.Spec.NodeDrainTimeout = &metav1.Duration{Duration: time.Minute}
+	validUpdate.Spec.NodeDeletionTimeout = &metav1.Duration{Duration: time.Minute}
+	validUpdate.Spec.MachineMetadata.Labels = map[string]string{"foo": "bar"}
+	validUpdate.Spec.MachineMetadata.Annotations = map[string]string{"foo": "bar"}
+	validUpdate.Spec.NamingTemplate = "{{ .ClusterName }}-cp-{{ random 5 }}"
+	validUpdate.Spec.KubeadmConfigSpec.Proxy = &bootstrapv1.ProxyConfiguration{HTTPSProxy: "http://proxy.example.com:3128"}
+	validUpdate.Spec.KubeadmConfigSpec.RegistryMirrors = []bootstrapv1.RegistryMirror{
+		{Registry: "docker.io", Endpoints: []string{"https://mirror.example.com"}},
+	}
+	validUpdate.Spec.TemplateMetadataPolicy = &clusterv1.TemplateMetadataPolicy{
+		StripLabels: []string{"argocd.argoproj.io/"},
+	}
 
 	scaleToZero := before.DeepCopy()
 	scaleToZero.Spec.Replicas = pointer.Int32Ptr(0)
@@ -752,6 +782,32 @@ func TestKubeadmControlPlaneValidateUpdate(t *testing.T) {
 	}
 }
 
+func TestKubeadmControlPlaneValidateSupportedKubernetesVersion(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(kubernetesversion.Set(kubernetesversion.Window{Min: "v1.18", Max: "v1.20"})).To(Succeed())
+	defer func() { _ = kubernetesversion.Set(kubernetesversion.Window{}) }()
+
+	newKCP := func(version string) *KubeadmControlPlane {
+		return &KubeadmControlPlane{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "foo"},
+			Spec: KubeadmControlPlaneSpec{
+				InfrastructureTemplate: corev1.ObjectReference{Namespace: "foo", Name: "infraTemplate"},
+				Replicas:               pointer.Int32Ptr(1),
+				Version:                version,
+			},
+		}
+	}
+
+	g.Expect(newKCP("v1.19.7").ValidateCreate()).To(Succeed())
+	g.Expect(newKCP("v1.17.9").ValidateCreate()).NotTo(Succeed())
+	g.Expect(newKCP("v1.21.0").ValidateCreate()).NotTo(Succeed())
+
+	// Control planes already using an unsupported version can be updated as long as the version doesn't change.
+	g.Expect(newKCP("v1.17.9").ValidateUpdate(newKCP("v1.17.9"))).To(Succeed())
+	g.Expect(newKCP("v1.20.2").ValidateUpdate(newKCP("v1.19.7"))).To(Succeed())
+	g.Expect(newKCP("v1.21.0").ValidateUpdate(newKCP("v1.20.2"))).NotTo(Succeed())
+}
+
 func TestPathsMatch(t *testing.T) {
 	tests := []struct {
 		name          string
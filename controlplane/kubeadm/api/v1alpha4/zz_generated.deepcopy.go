@@ -104,6 +104,17 @@ func (in *KubeadmControlPlaneSpec) DeepCopyInto(out *KubeadmControlPlaneSpec) {
 		*out = new(v1.Duration)
 		**out = **in
 	}
+	if in.NodeDeletionTimeout != nil {
+		in, out := &in.NodeDeletionTimeout, &out.NodeDeletionTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	in.MachineMetadata.DeepCopyInto(&out.MachineMetadata)
+	if in.TemplateMetadataPolicy != nil {
+		in, out := &in.TemplateMetadataPolicy, &out.TemplateMetadataPolicy
+		*out = new(apiv1alpha4.TemplateMetadataPolicy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubeadmControlPlaneSpec.
@@ -295,6 +295,12 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return ctrl.Result{}, err
 	}
 
+	// Propagate the changes to the machine metadata and the node drain and deletion timeouts to the existing machines,
+	// given that they do not trigger a rollout.
+	if err := syncMachinesInPlace(ctx, controlPlane); err != nil {
+		return ctrl.Result{}, errors.Wrap(err, "failed to propagate in-place changes to machines")
+	}
+
 	// Aggregate the operational state of all the machines; while aggregating we are adding the
 	// source ref (reason@machine/name) so the problem can be easily tracked down to its source machine.
 	conditions.SetAggregate(controlPlane.KCP, controlplanev1.MachinesReadyCondition, ownedMachines.ConditionGetters(), conditions.AddSourceRef(), conditions.WithStepCounterIf(false))
@@ -311,6 +317,9 @@ func (r *KubeadmControlPlaneReconciler) reconcile(ctx context.Context, cluster *
 		return result, err
 	}
 
+	// Report to MachineHealthChecks whether it is safe to mark control plane machines for remediation.
+	reconcileRemediationBlockedAnnotation(controlPlane)
+
 	// Reconcile unhealthy machines by triggering deletion and requeue if it is considered safe to remediate,
 	// otherwise continue with the other KCP operations.
 	if result, err := r.reconcileUnhealthyMachines(ctx, controlPlane); err != nil || !result.IsZero() {
@@ -31,15 +31,18 @@ import (
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	bootstrapv1 "sigs.k8s.io/cluster-api/bootstrap/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
+	"sigs.k8s.io/cluster-api/controllers/mdutil"
 	controlplanev1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controlplane/kubeadm/internal"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/certs"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/kubeconfig"
+	"sigs.k8s.io/cluster-api/util/naming"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/secret"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func (r *KubeadmControlPlaneReconciler) reconcileKubeconfig(ctx context.Context, cluster *clusterv1.Cluster, kcp *controlplanev1.KubeadmControlPlane) (ctrl.Result, error) {
@@ -161,12 +164,13 @@ func (r *KubeadmControlPlaneReconciler) cloneConfigsAndGenerateMachine(ctx conte
 
 	// Clone the infrastructure template
 	infraRef, err := external.CloneTemplate(ctx, &external.CloneTemplateInput{
-		Client:      r.Client,
-		TemplateRef: &kcp.Spec.InfrastructureTemplate,
-		Namespace:   kcp.Namespace,
-		OwnerRef:    infraCloneOwner,
-		ClusterName: cluster.Name,
-		Labels:      internal.ControlPlaneLabelsForCluster(cluster.Name),
+		Client:         r.Client,
+		TemplateRef:    &kcp.Spec.InfrastructureTemplate,
+		Namespace:      kcp.Namespace,
+		OwnerRef:       infraCloneOwner,
+		ClusterName:    cluster.Name,
+		Labels:         internal.ControlPlaneLabelsForCluster(cluster.Name),
+		MetadataPolicy: kcp.Spec.TemplateMetadataPolicy,
 	})
 	if err != nil {
 		// Safe to return early here since no resources have been created yet.
@@ -259,11 +263,20 @@ func (r *KubeadmControlPlaneReconciler) generateKubeadmConfig(ctx context.Contex
 }
 
 func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, cluster *clusterv1.Cluster, infraRef, bootstrapRef *corev1.ObjectReference, failureDomain *string) error {
+	name := names.SimpleNameGenerator.GenerateName(kcp.Name + "-")
+	if kcp.Spec.NamingTemplate != "" {
+		var err error
+		if name, err = r.generateMachineName(ctx, kcp, cluster); err != nil {
+			return errors.Wrap(err, "failed to generate machine name")
+		}
+	}
+
+	template := inPlaceMutableMachineTemplate(kcp, cluster.Name)
 	machine := &clusterv1.Machine{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      names.SimpleNameGenerator.GenerateName(kcp.Name + "-"),
+			Name:      name,
 			Namespace: kcp.Namespace,
-			Labels:    internal.ControlPlaneLabelsForCluster(cluster.Name),
+			Labels:    template.Labels,
 			OwnerReferences: []metav1.OwnerReference{
 				*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane")),
 			},
@@ -275,8 +288,9 @@ func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp
 			Bootstrap: clusterv1.Bootstrap{
 				ConfigRef: bootstrapRef,
 			},
-			FailureDomain:    failureDomain,
-			NodeDrainTimeout: kcp.Spec.NodeDrainTimeout,
+			FailureDomain:       failureDomain,
+			NodeDrainTimeout:    template.Spec.NodeDrainTimeout,
+			NodeDeletionTimeout: template.Spec.NodeDeletionTimeout,
 		},
 	}
 
@@ -286,10 +300,74 @@ func (r *KubeadmControlPlaneReconciler) generateMachine(ctx context.Context, kcp
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal cluster configuration")
 	}
-	machine.SetAnnotations(map[string]string{controlplanev1.KubeadmClusterConfigurationAnnotation: string(clusterConfig)})
+	annotations := map[string]string{}
+	for k, v := range template.Annotations {
+		annotations[k] = v
+	}
+	annotations[controlplanev1.KubeadmClusterConfigurationAnnotation] = string(clusterConfig)
+	machine.SetAnnotations(annotations)
 
 	if err := r.Client.Create(ctx, machine); err != nil {
 		return errors.Wrap(err, "failed to create machine")
 	}
 	return nil
 }
+
+// generateMachineName returns a name for a new controlplane machine rendered from the KubeadmControlPlane
+// naming template, skipping the names of the existing machines.
+func (r *KubeadmControlPlaneReconciler) generateMachineName(ctx context.Context, kcp *controlplanev1.KubeadmControlPlane, cluster *clusterv1.Cluster) (string, error) {
+	data := naming.MachineNameData{
+		ClusterName: cluster.Name,
+		OwnerName:   kcp.Name,
+	}
+	return naming.GenerateUnique(kcp.Spec.NamingTemplate, data, func(name string) (bool, error) {
+		err := r.Client.Get(ctx, client.ObjectKey{Namespace: kcp.Namespace, Name: name}, &clusterv1.Machine{})
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return err == nil, err
+	})
+}
+
+// inPlaceMutableMachineTemplate returns the fields of the machines generated by the KubeadmControlPlane which are
+// propagated to the existing machines without triggering a rollout, i.e. the machine metadata and the node drain
+// and deletion timeouts; all the other fields trigger a rollout when changed.
+func inPlaceMutableMachineTemplate(kcp *controlplanev1.KubeadmControlPlane, clusterName string) *clusterv1.MachineTemplateSpec {
+	template := &clusterv1.MachineTemplateSpec{
+		ObjectMeta: *kcp.Spec.MachineMetadata.DeepCopy(),
+		Spec: clusterv1.MachineSpec{
+			NodeDrainTimeout:    kcp.Spec.NodeDrainTimeout,
+			NodeDeletionTimeout: kcp.Spec.NodeDeletionTimeout,
+		},
+	}
+
+	// The labels and annotations managed by the KubeadmControlPlane take precedence over the machine metadata.
+	if template.Labels == nil {
+		template.Labels = map[string]string{}
+	}
+	for k, v := range internal.ControlPlaneLabelsForCluster(clusterName) {
+		template.Labels[k] = v
+	}
+	delete(template.Annotations, controlplanev1.KubeadmClusterConfigurationAnnotation)
+	return template
+}
+
+// syncMachinesInPlace propagates the in-place mutable fields of the KubeadmControlPlane to the existing machines.
+// Labels and annotations removed from the machine metadata are not removed from the machines.
+func syncMachinesInPlace(ctx context.Context, controlPlane *internal.ControlPlane) error {
+	template := inPlaceMutableMachineTemplate(controlPlane.KCP, controlPlane.Cluster.Name)
+
+	changed := false
+	for _, machine := range controlPlane.Machines {
+		if !machine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		if mdutil.UpdateMachineInPlaceMutableFields(machine, template) {
+			changed = true
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return controlPlane.PatchMachines(ctx)
+}
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
 
@@ -491,7 +492,12 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 			Namespace: cluster.Namespace,
 		},
 		Spec: controlplanev1.KubeadmControlPlaneSpec{
-			Version: "v1.16.6",
+			Version:             "v1.16.6",
+			NodeDeletionTimeout: &metav1.Duration{Duration: time.Minute},
+			MachineMetadata: clusterv1.ObjectMeta{
+				Labels:      map[string]string{"foo": "bar", clusterv1.ClusterLabelName: "other"},
+				Annotations: map[string]string{"foo": "bar"},
+			},
 		},
 	}
 
@@ -513,7 +519,8 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 		Bootstrap: clusterv1.Bootstrap{
 			ConfigRef: bootstrapRef.DeepCopy(),
 		},
-		InfrastructureRef: *infraRef.DeepCopy(),
+		InfrastructureRef:   *infraRef.DeepCopy(),
+		NodeDeletionTimeout: kcp.Spec.NodeDeletionTimeout,
 	}
 	r := &KubeadmControlPlaneReconciler{
 		Client:            fakeClient,
@@ -531,6 +538,119 @@ func TestKubeadmControlPlaneReconciler_generateMachine(t *testing.T) {
 	g.Expect(machine.OwnerReferences).To(HaveLen(1))
 	g.Expect(machine.OwnerReferences).To(ContainElement(*metav1.NewControllerRef(kcp, controlplanev1.GroupVersion.WithKind("KubeadmControlPlane"))))
 	g.Expect(machine.Spec).To(Equal(expectedMachineSpec))
+	// The labels managed by KCP take precedence over the machine metadata.
+	g.Expect(machine.Labels).To(Equal(map[string]string{
+		"foo":                                  "bar",
+		clusterv1.ClusterLabelName:             cluster.Name,
+		clusterv1.MachineControlPlaneLabelName: "",
+	}))
+	g.Expect(machine.Annotations).To(HaveKeyWithValue("foo", "bar"))
+	g.Expect(machine.Annotations).To(HaveKey(controlplanev1.KubeadmClusterConfigurationAnnotation))
+}
+
+func TestKubeadmControlPlaneReconciler_generateMachineWithNamingTemplate(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster",
+			Namespace: "test",
+		},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-control-plane",
+			Namespace: cluster.Namespace,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version:        "v1.16.6",
+			NamingTemplate: "{{ .ClusterName }}-cp-{{ .Index }}",
+		},
+	}
+	existingMachine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-cluster-cp-0",
+			Namespace: cluster.Namespace,
+		},
+	}
+
+	infraRef := &corev1.ObjectReference{
+		Kind:       "InfraKind",
+		APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+		Name:       "infra",
+		Namespace:  cluster.Namespace,
+	}
+	fakeClient := newFakeClient(g, existingMachine)
+	r := &KubeadmControlPlaneReconciler{
+		Client:            fakeClient,
+		managementCluster: &internal.Management{Client: fakeClient},
+		recorder:          record.NewFakeRecorder(32),
+	}
+	g.Expect(r.generateMachine(ctx, kcp, cluster, infraRef, nil, nil)).To(Succeed())
+
+	// The name of the existing machine is skipped.
+	machine := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: "test-cluster-cp-1"}, machine)).To(Succeed())
+
+	kcp.Spec.NamingTemplate = "{{ .ClusterName }}_cp"
+	g.Expect(r.generateMachine(ctx, kcp, cluster, infraRef, nil, nil)).NotTo(Succeed())
+}
+
+func TestSyncMachinesInPlace(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testCluster",
+			Namespace: "test",
+		},
+	}
+	kcp := &controlplanev1.KubeadmControlPlane{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "testControlPlane",
+			Namespace: cluster.Namespace,
+		},
+		Spec: controlplanev1.KubeadmControlPlaneSpec{
+			Version:          "v1.16.6",
+			NodeDrainTimeout: &metav1.Duration{Duration: time.Minute},
+			MachineMetadata: clusterv1.ObjectMeta{
+				Labels:      map[string]string{"foo": "bar"},
+				Annotations: map[string]string{"foo": "bar"},
+			},
+		},
+	}
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "machine",
+			Namespace:   cluster.Namespace,
+			Labels:      internal.ControlPlaneLabelsForCluster(cluster.Name),
+			Annotations: map[string]string{"other": "annotation"},
+		},
+		Spec: clusterv1.MachineSpec{
+			ClusterName: cluster.Name,
+			Version:     utilpointer.StringPtr("v1.16.6"),
+			InfrastructureRef: corev1.ObjectReference{
+				Kind:       "InfraKind",
+				APIVersion: "infrastructure.cluster.x-k8s.io/v1alpha4",
+				Name:       "infra",
+				Namespace:  cluster.Namespace,
+			},
+		},
+	}
+	fakeClient := newFakeClient(g, machine.DeepCopy())
+
+	controlPlane, err := internal.NewControlPlane(ctx, fakeClient, cluster, kcp, internal.NewFilterableMachineCollection(machine))
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(syncMachinesInPlace(ctx, controlPlane)).To(Succeed())
+
+	updated := &clusterv1.Machine{}
+	g.Expect(fakeClient.Get(ctx, client.ObjectKeyFromObject(machine), updated)).To(Succeed())
+	g.Expect(updated.Labels).To(HaveKeyWithValue("foo", "bar"))
+	g.Expect(updated.Labels).To(HaveKeyWithValue(clusterv1.ClusterLabelName, cluster.Name))
+	g.Expect(updated.Annotations).To(Equal(map[string]string{"foo": "bar", "other": "annotation"}))
+	g.Expect(updated.Spec.NodeDrainTimeout).To(Equal(kcp.Spec.NodeDrainTimeout))
+	// Fields triggering a rollout are left untouched.
+	g.Expect(updated.Spec.Version).To(Equal(utilpointer.StringPtr("v1.16.6")))
 }
 
 func TestKubeadmControlPlaneReconciler_generateKubeadmConfig(t *testing.T) {
@@ -52,6 +52,12 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 	// by considering which machine has lower impact on etcd quorum.
 	machineToBeRemediated := unhealthyMachines.Oldest()
 
+	// If a MachineHealthCheck requested the remediation of a specific machine, remediate it first, so
+	// the MachineHealthCheck and KCP agree on the machine being remediated.
+	if requested, ok := unhealthyMachines[controlPlane.KCP.Annotations[clusterv1.ControlPlaneRemediationRequestAnnotation]]; ok {
+		machineToBeRemediated = requested
+	}
+
 	// Returns if the machine is in the process of being deleted.
 	if !machineToBeRemediated.ObjectMeta.DeletionTimestamp.IsZero() {
 		return ctrl.Result{}, nil
@@ -150,6 +156,33 @@ func (r *KubeadmControlPlaneReconciler) reconcileUnhealthyMachines(ctx context.C
 	return ctrl.Result{Requeue: true}, nil
 }
 
+// reconcileRemediationBlockedAnnotation sets the ControlPlaneRemediationBlockedAnnotation on the KubeadmControlPlane while
+// it is not safe for MachineHealthChecks to mark control plane machines for remediation, i.e. while the control plane
+// is provisioning, rolling out or scaling, and removes it otherwise.
+func reconcileRemediationBlockedAnnotation(controlPlane *internal.ControlPlane) {
+	var reason string
+	switch {
+	case !controlPlane.KCP.Status.Initialized:
+		reason = "the control plane is provisioning"
+	case len(controlPlane.MachinesNeedingRollout()) > 0:
+		reason = "the control plane is rolling out"
+	case controlPlane.Machines.Len() != int(*controlPlane.KCP.Spec.Replicas):
+		reason = "the control plane is scaling"
+	}
+
+	annotations := controlPlane.KCP.GetAnnotations()
+	if reason == "" {
+		delete(annotations, clusterv1.ControlPlaneRemediationBlockedAnnotation)
+		controlPlane.KCP.SetAnnotations(annotations)
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[clusterv1.ControlPlaneRemediationBlockedAnnotation] = reason
+	controlPlane.KCP.SetAnnotations(annotations)
+}
+
 // canSafelyRemoveEtcdMember assess if it is possible to remove the member hosted on the machine to be remediated
 // without loosing etcd quorum.
 //
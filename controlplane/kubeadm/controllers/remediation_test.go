@@ -252,6 +252,59 @@ func TestReconcileUnhealthyMachines(t *testing.T) {
 	g.Expect(testEnv.Cleanup(ctx, ns)).To(Succeed())
 }
 
+func TestReconcileRemediationBlockedAnnotation(t *testing.T) {
+	tests := []struct {
+		name        string
+		initialized bool
+		replicas    int32
+		wantReason  string
+	}{
+		{
+			name:       "remediation is blocked while the control plane is provisioning",
+			replicas:   3,
+			wantReason: "the control plane is provisioning",
+		},
+		{
+			name:        "remediation is blocked while the control plane is scaling",
+			initialized: true,
+			replicas:    3,
+			wantReason:  "the control plane is scaling",
+		},
+		{
+			name:        "remediation is not blocked otherwise",
+			initialized: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			controlPlane := &internal.ControlPlane{
+				KCP: &controlplanev1.KubeadmControlPlane{
+					ObjectMeta: metav1.ObjectMeta{
+						Annotations: map[string]string{clusterv1.ControlPlaneRemediationBlockedAnnotation: "stale"},
+					},
+					Spec: controlplanev1.KubeadmControlPlaneSpec{
+						Replicas: utilpointer.Int32Ptr(tt.replicas),
+					},
+					Status: controlplanev1.KubeadmControlPlaneStatus{
+						Initialized: tt.initialized,
+					},
+				},
+				Cluster:  &clusterv1.Cluster{},
+				Machines: internal.NewFilterableMachineCollection(),
+			}
+			reconcileRemediationBlockedAnnotation(controlPlane)
+
+			if tt.wantReason == "" {
+				g.Expect(controlPlane.KCP.Annotations).ToNot(HaveKey(clusterv1.ControlPlaneRemediationBlockedAnnotation))
+				return
+			}
+			g.Expect(controlPlane.KCP.Annotations).To(HaveKeyWithValue(clusterv1.ControlPlaneRemediationBlockedAnnotation, tt.wantReason))
+		})
+	}
+}
+
 func TestCanSafelyRemoveEtcdMember(t *testing.T) {
 	g := NewWithT(t)
 	ctx := context.TODO()
@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"flag"
+	"fmt"
 	"math/rand"
 	"net/http"
 	_ "net/http/pprof"
@@ -29,6 +30,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog"
 	"k8s.io/klog/klogr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
@@ -36,6 +38,10 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	kcpv1 "sigs.k8s.io/cluster-api/controlplane/kubeadm/api/v1alpha4"
 	kubeadmcontrolplanecontrollers "sigs.k8s.io/cluster-api/controlplane/kubeadm/controllers"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
+	"sigs.k8s.io/cluster-api/internal/managercache"
+	"sigs.k8s.io/cluster-api/util/metrics"
+	"sigs.k8s.io/cluster-api/util/uncached"
 	"sigs.k8s.io/cluster-api/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -60,15 +66,24 @@ func init() {
 
 var (
 	metricsBindAddr                string
+	metricsSecure                  bool
+	metricsCertDir                 string
 	enableLeaderElection           bool
 	leaderElectionLeaseDuration    time.Duration
 	leaderElectionRenewDeadline    time.Duration
 	leaderElectionRetryPeriod      time.Duration
-	watchNamespace                 string
+	watchNamespaces                []string
 	profilerAddress                string
 	kubeadmControlPlaneConcurrency int
 	syncPeriod                     time.Duration
+	secretReadCacheTTL             time.Duration
+	remoteCacheIdleTimeout         time.Duration
+	kubeAPIQPS                     float32
+	kubeAPIBurst                   int
 	webhookPort                    int
+	minKubernetesVersion           string
+	maxKubernetesVersion           string
+	kubernetesVersionPolicy        string
 )
 
 // InitFlags initializes the flags.
@@ -76,6 +91,12 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&metricsBindAddr, "metrics-bind-addr", ":8080",
 		"The address the metric endpoint binds to.")
 
+	fs.BoolVar(&metricsSecure, "metrics-secure", false,
+		"Serve the metric endpoint over HTTPS, authenticating and authorizing requests with TokenReviews and SubjectAccessReviews.")
+
+	fs.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+		"Directory containing the tls.crt and tls.key files used for serving the metric endpoint when --metrics-secure is set. If unspecified, a self-signed certificate is generated.")
+
 	fs.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 
@@ -88,8 +109,8 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&leaderElectionRetryPeriod, "leader-elect-retry-period", 5*time.Second,
 		"Duration the LeaderElector clients should wait between tries of actions (duration string)")
 
-	fs.StringVar(&watchNamespace, "namespace", "",
-		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
+	fs.StringSliceVar(&watchNamespaces, "namespace", nil,
+		"Comma-separated list of namespaces that the controller watches to reconcile cluster-api objects, with one cache per namespace. If unspecified, the controller watches for cluster-api objects across all namespaces.")
 
 	fs.StringVar(&profilerAddress, "profiler-address", "",
 		"Bind address to expose the pprof profiler (e.g. localhost:6060)")
@@ -100,8 +121,29 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
 		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
 
+	fs.DurationVar(&secretReadCacheTTL, "secret-read-cache-ttl", 0,
+		"The time bootstrap data and kubeconfig secrets are served from a read-through cache after being read, reducing the reads of secrets from the API server; secrets written by other clients can be stale up to this time. 0 disables the cache")
+
+	fs.DurationVar(&remoteCacheIdleTimeout, "remote-cache-idle-timeout", 0,
+		"The time after which the client, the cache and the watches of a workload cluster which has not been accessed are torn down, to reduce the memory used for clusters that are rarely reconciled; they are created again on the next access. It must be longer than --sync-period; 0 disables the eviction")
+
+	fs.Float32Var(&kubeAPIQPS, "kube-api-qps", 20,
+		"Maximum queries per second from the controller manager to the Kubernetes API server of the management cluster and of each workload cluster")
+
+	fs.IntVar(&kubeAPIBurst, "kube-api-burst", 30,
+		"Maximum burst of queries from the controller manager to the Kubernetes API server of the management cluster and of each workload cluster")
+
 	fs.IntVar(&webhookPort, "webhook-port", 9443,
 		"Webhook Server port")
+
+	fs.StringVar(&minKubernetesVersion, "min-kubernetes-version", "",
+		"The minimum Kubernetes minor version of workload clusters accepted by the KubeadmControlPlane webhook (e.g. v1.18). If unspecified, there is no minimum version.")
+
+	fs.StringVar(&maxKubernetesVersion, "max-kubernetes-version", "",
+		"The maximum Kubernetes minor version of workload clusters accepted by the KubeadmControlPlane webhook (e.g. v1.21). If unspecified, there is no maximum version.")
+
+	fs.StringVar(&kubernetesVersionPolicy, "kubernetes-version-policy", string(kubernetesversion.PolicyEnforce),
+		fmt.Sprintf("How the webhook handles Kubernetes versions outside of --min-kubernetes-version and --max-kubernetes-version: %q rejects them, %q accepts them logging a warning.", kubernetesversion.PolicyEnforce, kubernetesversion.PolicyWarn))
 }
 func main() {
 	rand.Seed(time.Now().UnixNano())
@@ -112,6 +154,11 @@ func main() {
 
 	ctrl.SetLogger(klogr.New())
 
+	if err := remote.ValidateIdleTimeout(remoteCacheIdleTimeout, syncPeriod); err != nil {
+		setupLog.Error(err, "invalid --remote-cache-idle-timeout")
+		os.Exit(1)
+	}
+
 	if profilerAddress != "" {
 		klog.Infof("Profiler listening for requests at %s", profilerAddress)
 		go func() {
@@ -119,16 +166,27 @@ func main() {
 		}()
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	// When serving the metrics securely, the controller-runtime metrics server is disabled.
+	managerMetricsBindAddr := metricsBindAddr
+	if metricsSecure {
+		managerMetricsBindAddr = "0"
+	}
+
+	watchNamespace, newCache := managercache.ForNamespaces(watchNamespaces)
+
+	restConfig := newRESTConfig(ctrl.GetConfigOrDie())
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:             scheme,
-		MetricsBindAddress: metricsBindAddr,
+		MetricsBindAddress: managerMetricsBindAddr,
 		LeaderElection:     enableLeaderElection,
 		LeaderElectionID:   "kubeadm-control-plane-manager-leader-election-capi",
 		LeaseDuration:      &leaderElectionLeaseDuration,
 		RenewDeadline:      &leaderElectionRenewDeadline,
 		RetryPeriod:        &leaderElectionRetryPeriod,
 		Namespace:          watchNamespace,
+		NewCache:           newCache,
 		SyncPeriod:         &syncPeriod,
+		ClientBuilder:      uncached.NewClientBuilder(uncached.Options{SecretTTL: secretReadCacheTTL}),
 		ClientDisableCacheFor: []client.Object{
 			&corev1.ConfigMap{},
 			&corev1.Secret{},
@@ -140,6 +198,22 @@ func main() {
 		os.Exit(1)
 	}
 
+	if metricsSecure {
+		metricsServer, err := metrics.NewSecureServer(metrics.SecureServerOptions{
+			BindAddress: metricsBindAddr,
+			CertDir:     metricsCertDir,
+			Config:      mgr.GetConfig(),
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create secure metrics server")
+			os.Exit(1)
+		}
+		if err := mgr.Add(metricsServer); err != nil {
+			setupLog.Error(err, "unable to add secure metrics server to the manager")
+			os.Exit(1)
+		}
+	}
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
@@ -154,13 +228,28 @@ func main() {
 	}
 }
 
-func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
-	// Set up a ClusterCacheTracker to provide to controllers
-	// requiring a connection to a remote cluster
-	tracker, err := remote.NewClusterCacheTracker(
+// newRESTConfig returns config with the rate limits set with --kube-api-qps and --kube-api-burst.
+func newRESTConfig(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.QPS = kubeAPIQPS
+	config.Burst = kubeAPIBurst
+	return config
+}
+
+// newClusterCacheTracker creates the ClusterCacheTracker configured with the flags.
+func newClusterCacheTracker(mgr ctrl.Manager) (*remote.ClusterCacheTracker, error) {
+	return remote.NewClusterCacheTracker(
 		ctrl.Log.WithName("remote").WithName("ClusterCacheTracker"),
 		mgr,
+		remote.WithIdleTimeout(remoteCacheIdleTimeout),
+		remote.WithRateLimits(kubeAPIQPS, kubeAPIBurst),
 	)
+}
+
+func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
+	// Set up a ClusterCacheTracker to provide to controllers
+	// requiring a connection to a remote cluster
+	tracker, err := newClusterCacheTracker(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create cluster cache tracker")
 		os.Exit(1)
@@ -184,6 +273,15 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
+	if err := kubernetesversion.Set(kubernetesversion.Window{
+		Min:    minKubernetesVersion,
+		Max:    maxKubernetesVersion,
+		Policy: kubernetesversion.Policy(kubernetesVersionPolicy),
+	}); err != nil {
+		setupLog.Error(err, "invalid supported Kubernetes versions")
+		os.Exit(1)
+	}
+
 	if err := (&kcpv1.KubeadmControlPlane{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "KubeadmControlPlane")
 		os.Exit(1)
@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+	"k8s.io/client-go/rest"
+)
+
+func parseFlags(g *WithT, args ...string) {
+	fs := pflag.NewFlagSet("manager", pflag.ContinueOnError)
+	InitFlags(fs)
+	g.Expect(fs.Parse(args)).To(Succeed())
+}
+
+func TestKubeAPIRateLimits(t *testing.T) {
+	g := NewWithT(t)
+
+	parseFlags(g, "--kube-api-qps=50", "--kube-api-burst=100")
+
+	base := &rest.Config{Host: "https://management.example.com"}
+	config := newRESTConfig(base)
+	g.Expect(config.Host).To(Equal(base.Host))
+	g.Expect(config.QPS).To(Equal(float32(50)))
+	g.Expect(config.Burst).To(Equal(100))
+	g.Expect(base.QPS).To(BeZero())
+}
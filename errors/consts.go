@@ -74,6 +74,19 @@ const (
 	// not result in a Node joining the cluster within a given timeout
 	// and that are managed by a MachineSet
 	JoinClusterTimeoutMachineError = "JoinClusterTimeoutError"
+
+	// This error indicates that the instance backing the machine has been
+	// terminated outside of Cluster API, as reported by the infrastructure
+	// provider.
+	//
+	// Example: the instance has been deleted from the cloud provider console.
+	InstanceTerminatedMachineError MachineStatusError = "InstanceTerminated"
+
+	// InfrastructureDeletedMachineError indicates that the infrastructure machine referenced by the Machine
+	// has been deleted outside of Cluster API after being ready.
+	//
+	// Example: the infrastructure machine has been deleted with kubectl.
+	InfrastructureDeletedMachineError MachineStatusError = "InfrastructureDeleted"
 )
 
 type ClusterStatusError string
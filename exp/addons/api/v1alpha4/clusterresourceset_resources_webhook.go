@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api/internal/remoteapply"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ClusterResourceSetResourcesWebhookPath is the path of the webhook validating the resources of ClusterResourceSets.
+const ClusterResourceSetResourcesWebhookPath = "/validate-addons-cluster-x-k8s-io-v1alpha4-clusterresourceset-resources"
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-addons-cluster-x-k8s-io-v1alpha4-clusterresourceset-resources,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=addons.cluster.x-k8s.io,resources=clusterresourcesets,versions=v1alpha4,name=resources.clusterresourceset.addons.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
+
+// ClusterResourceSetResourcesValidator is an admission handler rejecting the ClusterResourceSets with the
+// ValidateResourcesAnnotation whose resources can't be applied, so that broken payloads are rejected when authored
+// instead of failing to apply on every matching Cluster. Changes to the resources after the ClusterResourceSet is
+// created or updated are not validated.
+// +kubebuilder:object:generate=false
+type ClusterResourceSetResourcesValidator struct {
+	Client client.Reader
+}
+
+var _ admission.Handler = &ClusterResourceSetResourcesValidator{}
+
+// Handle implements admission.Handler.
+func (v *ClusterResourceSetResourcesValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	crs := &ClusterResourceSet{}
+	if err := json.Unmarshal(req.Object.Raw, crs); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	if crs.Annotations[ValidateResourcesAnnotation] != "true" {
+		return admission.Allowed("")
+	}
+
+	var allErrs field.ErrorList
+	for i, resource := range crs.Spec.Resources {
+		fldPath := field.NewPath("spec", "resources").Index(i)
+		dataByKey, err := v.getResourceData(ctx, req.Namespace, resource)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				allErrs = append(allErrs, field.NotFound(fldPath, fmt.Sprintf("%s %s", resource.Kind, resource.Name)))
+				continue
+			}
+			if invalid, ok := err.(*field.Error); ok {
+				invalid.Field = fldPath.String()
+				allErrs = append(allErrs, invalid)
+				continue
+			}
+			return admission.Errored(http.StatusInternalServerError, err)
+		}
+
+		keys := make([]string, 0, len(dataByKey))
+		for key := range dataByKey {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			if err := validateResourceData(dataByKey[key]); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath, fmt.Sprintf("%s %s", resource.Kind, resource.Name),
+					fmt.Sprintf("key %q can't be applied: %v", key, err)))
+			}
+		}
+	}
+	if len(allErrs) > 0 {
+		return admission.Denied(apierrors.NewInvalid(GroupVersion.WithKind("ClusterResourceSet").GroupKind(), crs.Name, allErrs).Error())
+	}
+	return admission.Allowed("")
+}
+
+// getResourceData returns the data of a Secret or ConfigMap referenced by a ClusterResourceSet, by key.
+func (v *ClusterResourceSetResourcesValidator) getResourceData(ctx context.Context, namespace string, resource ResourceRef) (map[string][]byte, error) {
+	key := client.ObjectKey{Namespace: namespace, Name: resource.Name}
+	switch resource.Kind {
+	case string(ConfigMapClusterResourceSetResourceKind):
+		configMap := &corev1.ConfigMap{}
+		if err := v.Client.Get(ctx, key, configMap); err != nil {
+			return nil, errors.Wrapf(err, "failed to get ConfigMap %s", key)
+		}
+		data := make(map[string][]byte, len(configMap.Data))
+		for k, value := range configMap.Data {
+			data[k] = []byte(value)
+		}
+		return data, nil
+	case string(SecretClusterResourceSetResourceKind):
+		secret := &corev1.Secret{}
+		if err := v.Client.Get(ctx, key, secret); err != nil {
+			return nil, errors.Wrapf(err, "failed to get Secret %s", key)
+		}
+		if secret.Type != ClusterResourceSetSecretType {
+			return nil, field.Invalid(nil, fmt.Sprintf("Secret %s", resource.Name), fmt.Sprintf("must be of type %s", ClusterResourceSetSecretType))
+		}
+		return secret.Data, nil
+	}
+	return nil, field.NotSupported(nil, resource.Kind, []string{string(ConfigMapClusterResourceSetResourceKind), string(SecretClusterResourceSetResourceKind)})
+}
+
+// validateResourceData checks that the value of a key of a ClusterResourceSet resource parses into objects that can
+// be applied, the same way the ClusterResourceSet controller parses it.
+func validateResourceData(data []byte) error {
+	objs, err := remoteapply.ParseObjects(data)
+	if err != nil {
+		return err
+	}
+	return remoteapply.ValidateObjects(objs)
+}
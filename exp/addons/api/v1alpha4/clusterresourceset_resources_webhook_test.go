@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestClusterResourceSetResourcesValidator(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(corev1.AddToScheme(scheme)).To(Succeed())
+
+	validYAML := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: calico-system
+---
+apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: calico-node
+  namespace: calico-system
+`
+	existing := []client.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "valid"},
+			Data:       map[string]string{"calico.yaml": validYAML},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "invalid-yaml"},
+			Data:       map[string]string{"calico.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: [calico-system"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "missing-name"},
+			Data:       map[string]string{"calico.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  labels: {}\n"},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "valid"},
+			Type:       ClusterResourceSetSecretType,
+			Data:       map[string][]byte{"calico.yaml": []byte(validYAML)},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "opaque"},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{"calico.yaml": []byte(validYAML)},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		resources   []ResourceRef
+		allowed     bool
+	}{
+		{
+			name:      "resources are not validated without the annotation",
+			resources: []ResourceRef{{Name: "not-found", Kind: "ConfigMap"}},
+			allowed:   true,
+		},
+		{
+			name:        "valid resources are allowed",
+			annotations: map[string]string{ValidateResourcesAnnotation: "true"},
+			resources:   []ResourceRef{{Name: "valid", Kind: "ConfigMap"}, {Name: "valid", Kind: "Secret"}},
+			allowed:     true,
+		},
+		{
+			name:        "missing resources are denied",
+			annotations: map[string]string{ValidateResourcesAnnotation: "true"},
+			resources:   []ResourceRef{{Name: "not-found", Kind: "ConfigMap"}},
+			allowed:     false,
+		},
+		{
+			name:        "Secrets of the wrong type are denied",
+			annotations: map[string]string{ValidateResourcesAnnotation: "true"},
+			resources:   []ResourceRef{{Name: "opaque", Kind: "Secret"}},
+			allowed:     false,
+		},
+		{
+			name:        "invalid YAML is denied",
+			annotations: map[string]string{ValidateResourcesAnnotation: "true"},
+			resources:   []ResourceRef{{Name: "invalid-yaml", Kind: "ConfigMap"}},
+			allowed:     false,
+		},
+		{
+			name:        "objects without a name are denied",
+			annotations: map[string]string{ValidateResourcesAnnotation: "true"},
+			resources:   []ResourceRef{{Name: "missing-name", Kind: "ConfigMap"}},
+			allowed:     false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			validator := &ClusterResourceSetResourcesValidator{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(existing...).Build()}
+
+			crs := &ClusterResourceSet{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "crs", Annotations: tt.annotations},
+				Spec:       ClusterResourceSetSpec{Resources: tt.resources},
+			}
+			raw, err := json.Marshal(crs)
+			g.Expect(err).NotTo(HaveOccurred())
+			resp := validator.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: GroupVersion.Group, Version: GroupVersion.Version, Kind: "ClusterResourceSet"},
+				Namespace: "ns1",
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: raw},
+			}})
+			g.Expect(resp.Allowed).To(Equal(tt.allowed), resp.Result.String())
+		})
+	}
+}
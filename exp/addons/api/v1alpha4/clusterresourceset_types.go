@@ -28,6 +28,12 @@ const (
 
 	// ClusterResourceSetFinalizer is added to the ClusterResourceSet object for additional cleanup logic on deletion.
 	ClusterResourceSetFinalizer = "addons.cluster.x-k8s.io"
+
+	// ValidateResourcesAnnotation is the annotation that can be set to "true" on a ClusterResourceSet to have its
+	// resources checked when the ClusterResourceSet is created or updated: the referenced Secrets and ConfigMaps must
+	// exist, Secrets must be of the ClusterResourceSetSecretType, and the contents must parse as YAML or JSON objects
+	// that can be applied.
+	ValidateResourcesAnnotation = "addons.cluster.x-k8s.io/validate-resources"
 )
 
 // ANCHOR: ClusterResourceSetSpec
@@ -36,9 +42,16 @@ const (
 type ClusterResourceSetSpec struct {
 	// Label selector for Clusters. The Clusters that are
 	// selected by this will be the ones affected by this ClusterResourceSet.
-	// It must match the Cluster labels. This field is immutable.
+	// It must match the Cluster labels. Both matchLabels and matchExpressions are supported.
+	// An empty selector matches all the Clusters if ClusterFieldSelector is set, no Clusters otherwise.
+	// This field is immutable.
 	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
 
+	// ClusterFieldSelector selects Clusters by their fields; if set, the Clusters affected by this ClusterResourceSet
+	// must match both ClusterSelector and ClusterFieldSelector. This field is immutable.
+	// +optional
+	ClusterFieldSelector *ClusterFieldSelector `json:"clusterFieldSelector,omitempty"`
+
 	// Resources is a list of Secrets/ConfigMaps where each contains 1 or more resources to be applied to remote clusters.
 	Resources []ResourceRef `json:"resources,omitempty"`
 
@@ -46,10 +59,68 @@ type ClusterResourceSetSpec struct {
 	// +kubebuilder:validation:Enum=ApplyOnce
 	// +optional
 	Strategy string `json:"strategy,omitempty"`
+
+	// ApplyMode is the mode used for applying the resources to the Clusters. Defaults to Direct, where resources are
+	// applied from the management cluster through the API server of the workload cluster. In Job mode, the resources
+	// are shipped into the workload cluster and applied by a Job running there, which is better suited for very large
+	// resources, e.g. CRDs with huge schemas or hundreds of objects.
+	// +kubebuilder:validation:Enum=Direct;Job
+	// +optional
+	ApplyMode string `json:"applyMode,omitempty"`
+
+	// ApplyJob configures the Jobs applying the resources in the workload clusters; it is required in Job mode.
+	// +optional
+	ApplyJob *ApplyJobSpec `json:"applyJob,omitempty"`
 }
 
 // ANCHOR_END: ClusterResourceSetSpec
 
+// ANCHOR: ClusterFieldSelector
+
+// ClusterFieldSelector selects Clusters by their fields. All the requirements that are set must be met.
+type ClusterFieldSelector struct {
+	// NamePrefix selects the Clusters whose name starts with the given prefix.
+	// +optional
+	NamePrefix string `json:"namePrefix,omitempty"`
+
+	// KubernetesVersion selects the Clusters whose Kubernetes version is in the given range, e.g. ">=1.23.0" or
+	// ">=1.21.0 <1.23.0"; the version is read from the workload cluster API server, ignoring pre-release and build
+	// metadata, so Clusters whose control plane is not initialized yet are not selected until it is.
+	// +optional
+	KubernetesVersion string `json:"kubernetesVersion,omitempty"`
+}
+
+// ANCHOR_END: ClusterFieldSelector
+
+// ANCHOR: ApplyJobSpec
+
+// ApplyJobSpec configures the Jobs applying the resources of a ClusterResourceSet in the workload clusters.
+type ApplyJobSpec struct {
+	// Image is the container image of the Jobs; it must provide kubectl, which is used for applying the resources
+	// with server-side apply.
+	// +kubebuilder:validation:MinLength=1
+	Image string `json:"image"`
+
+	// Namespace is the namespace of the workload clusters where the Jobs run. Defaults to kube-system.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// ClusterRoleName is the name of a ClusterRole of the workload clusters, e.g. cluster-admin, which is bound to
+	// the ServiceAccount running the Jobs; it must allow to apply all the resources of the ClusterResourceSet.
+	// The Jobs run in the host network and tolerate all the taints, so the permissions of the ClusterRole are
+	// available to any workload able to run on the nodes. If unset, no permission is granted to the ServiceAccount,
+	// which must be granted by other means.
+	// +optional
+	ClusterRoleName string `json:"clusterRoleName,omitempty"`
+}
+
+// ANCHOR_END: ApplyJobSpec
+
+// IsEmpty returns true if no requirements are set.
+func (s *ClusterFieldSelector) IsEmpty() bool {
+	return s == nil || (s.NamePrefix == "" && s.KubernetesVersion == "")
+}
+
 // ClusterResourceSetResourceKind is a string representation of a ClusterResourceSet resource kind.
 type ClusterResourceSetResourceKind string
 
@@ -78,6 +149,22 @@ const (
 	ClusterResourceSetStrategyApplyOnce ClusterResourceSetStrategy = "ApplyOnce"
 )
 
+// ClusterResourceSetApplyMode is a string representation of a ClusterResourceSet ApplyMode.
+type ClusterResourceSetApplyMode string
+
+const (
+	// ClusterResourceSetApplyModeDirect applies the resources from the management cluster through the API server
+	// of the workload cluster.
+	ClusterResourceSetApplyModeDirect ClusterResourceSetApplyMode = "Direct"
+
+	// ClusterResourceSetApplyModeJob ships the resources into the workload cluster and applies them with a Job
+	// running there.
+	ClusterResourceSetApplyModeJob ClusterResourceSetApplyMode = "Job"
+)
+
+// DefaultApplyJobNamespace is the namespace of the workload clusters where the Jobs applying resources run by default.
+const DefaultApplyJobNamespace = "kube-system"
+
 // SetTypedStrategy sets the Strategy field to the string representation of ClusterResourceSetStrategy.
 func (c *ClusterResourceSetSpec) SetTypedStrategy(p ClusterResourceSetStrategy) {
 	c.Strategy = string(p)
@@ -20,9 +20,12 @@ import (
 	"fmt"
 	"reflect"
 
+	"github.com/blang/semver"
+
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -46,6 +49,15 @@ func (m *ClusterResourceSet) Default() {
 	if m.Spec.Strategy == "" {
 		m.Spec.Strategy = string(ClusterResourceSetStrategyApplyOnce)
 	}
+
+	// ClusterResourceSet ApplyMode defaults to Direct.
+	if m.Spec.ApplyMode == "" {
+		m.Spec.ApplyMode = string(ClusterResourceSetApplyModeDirect)
+	}
+
+	if m.Spec.ApplyJob != nil && m.Spec.ApplyJob.Namespace == "" {
+		m.Spec.ApplyJob.Namespace = DefaultApplyJobNamespace
+	}
 }
 
 // ValidateCreate implements webhook.Validator so a webhook will be registered for the type
@@ -79,14 +91,55 @@ func (m *ClusterResourceSet) validate(old *ClusterResourceSet) error {
 		)
 	}
 
-	// Validate that the selector isn't empty as null selectors do not select any objects.
-	if selector != nil && selector.Empty() {
+	// Validate that the selectors aren't both empty as null selectors do not select any objects.
+	if selector != nil && selector.Empty() && m.Spec.ClusterFieldSelector.IsEmpty() {
 		allErrs = append(
 			allErrs,
 			field.Invalid(field.NewPath("spec", "clusterSelector"), m.Spec.ClusterSelector, "selector must not be empty"),
 		)
 	}
 
+	// Validate the Kubernetes version range parses.
+	if m.Spec.ClusterFieldSelector != nil && m.Spec.ClusterFieldSelector.KubernetesVersion != "" {
+		if _, err := semver.ParseRange(m.Spec.ClusterFieldSelector.KubernetesVersion); err != nil {
+			allErrs = append(
+				allErrs,
+				field.Invalid(field.NewPath("spec", "clusterFieldSelector", "kubernetesVersion"), m.Spec.ClusterFieldSelector.KubernetesVersion, err.Error()),
+			)
+		}
+	}
+
+	// Validate the Jobs are configured in Job mode, and only in Job mode.
+	if m.Spec.ApplyMode == string(ClusterResourceSetApplyModeJob) && (m.Spec.ApplyJob == nil || m.Spec.ApplyJob.Image == "") {
+		allErrs = append(
+			allErrs,
+			field.Required(field.NewPath("spec", "applyJob", "image"), "must be set when applyMode is Job"),
+		)
+	}
+	if m.Spec.ApplyMode != string(ClusterResourceSetApplyModeJob) && m.Spec.ApplyJob != nil {
+		allErrs = append(
+			allErrs,
+			field.Forbidden(field.NewPath("spec", "applyJob"), "must not be set when applyMode is not Job"),
+		)
+	}
+
+	// Validate the resource references are well formed, and not duplicated; existing ClusterResourceSets are validated
+	// only if their resources change, so that the ones created before this validation can still be updated, e.g. to
+	// remove their finalizer.
+	if old == nil || !reflect.DeepEqual(old.Spec.Resources, m.Spec.Resources) {
+		seen := map[ResourceRef]bool{}
+		for i, resource := range m.Spec.Resources {
+			fldPath := field.NewPath("spec", "resources").Index(i)
+			for _, msg := range validation.IsDNS1123Subdomain(resource.Name) {
+				allErrs = append(allErrs, field.Invalid(fldPath.Child("name"), resource.Name, msg))
+			}
+			if seen[resource] {
+				allErrs = append(allErrs, field.Duplicate(fldPath, resource))
+			}
+			seen[resource] = true
+		}
+	}
+
 	if old != nil && old.Spec.Strategy != m.Spec.Strategy {
 		allErrs = append(
 			allErrs,
@@ -101,6 +154,13 @@ func (m *ClusterResourceSet) validate(old *ClusterResourceSet) error {
 		)
 	}
 
+	if old != nil && !reflect.DeepEqual(old.Spec.ClusterFieldSelector, m.Spec.ClusterFieldSelector) {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "clusterFieldSelector"), m.Spec.ClusterFieldSelector, "field is immutable"),
+		)
+	}
+
 	if len(allErrs) == 0 {
 		return nil
 	}
@@ -31,6 +31,20 @@ func TestClusterResourcesetDefault(t *testing.T) {
 	clusterResourceSet.Default()
 
 	g.Expect(clusterResourceSet.Spec.Strategy).To(Equal(string(ClusterResourceSetStrategyApplyOnce)))
+	g.Expect(clusterResourceSet.Spec.ApplyMode).To(Equal(string(ClusterResourceSetApplyModeDirect)))
+	g.Expect(clusterResourceSet.Spec.ApplyJob).To(BeNil())
+
+	clusterResourceSet = &ClusterResourceSet{
+		Spec: ClusterResourceSetSpec{
+			ApplyMode: string(ClusterResourceSetApplyModeJob),
+			ApplyJob:  &ApplyJobSpec{Image: "kubectl"},
+		},
+	}
+
+	clusterResourceSet.Default()
+
+	g.Expect(clusterResourceSet.Spec.ApplyMode).To(Equal(string(ClusterResourceSetApplyModeJob)))
+	g.Expect(clusterResourceSet.Spec.ApplyJob.Namespace).To(Equal(DefaultApplyJobNamespace))
 }
 
 func TestClusterResourceSetLabelSelectorAsSelectorValidation(t *testing.T) {
@@ -185,3 +199,199 @@ func TestClusterResourceSetSelectorNotEmptyValidation(t *testing.T) {
 	g.Expect(err).ToNot(BeNil())
 	g.Expect(err.Error()).To(ContainSubstring("selector must not be empty"))
 }
+
+func TestClusterResourceSetClusterFieldSelectorValidation(t *testing.T) {
+	tests := []struct {
+		name          string
+		selector      metav1.LabelSelector
+		fieldSelector *ClusterFieldSelector
+		expectErr     bool
+	}{
+		{
+			name: "should not return error for matchExpressions",
+			selector: metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "env", Operator: metav1.LabelSelectorOpIn, Values: []string{"dev", "test"}},
+				},
+			},
+		},
+		{
+			name:          "should not return error for an empty label selector with a field selector",
+			fieldSelector: &ClusterFieldSelector{NamePrefix: "dev-"},
+		},
+		{
+			name:          "should not return error for a valid Kubernetes version range",
+			selector:      metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			fieldSelector: &ClusterFieldSelector{KubernetesVersion: ">=1.21.0 <1.23.0"},
+		},
+		{
+			name:          "should return error for an empty label selector with an empty field selector",
+			fieldSelector: &ClusterFieldSelector{},
+			expectErr:     true,
+		},
+		{
+			name:          "should return error for an invalid Kubernetes version range",
+			fieldSelector: &ClusterFieldSelector{KubernetesVersion: ">=v1.23"},
+			expectErr:     true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterResourceSet := &ClusterResourceSet{
+				Spec: ClusterResourceSetSpec{
+					ClusterSelector:      tt.selector,
+					ClusterFieldSelector: tt.fieldSelector,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(clusterResourceSet.ValidateCreate()).NotTo(Succeed())
+				return
+			}
+			g.Expect(clusterResourceSet.ValidateCreate()).To(Succeed())
+		})
+	}
+}
+
+func TestClusterResourceSetClusterFieldSelectorImmutable(t *testing.T) {
+	g := NewWithT(t)
+
+	oldClusterResourceSet := &ClusterResourceSet{
+		Spec: ClusterResourceSetSpec{
+			ClusterFieldSelector: &ClusterFieldSelector{NamePrefix: "dev-"},
+		},
+	}
+	newClusterResourceSet := oldClusterResourceSet.DeepCopy()
+	g.Expect(newClusterResourceSet.ValidateUpdate(oldClusterResourceSet)).To(Succeed())
+
+	newClusterResourceSet.Spec.ClusterFieldSelector.NamePrefix = "prod-"
+	g.Expect(newClusterResourceSet.ValidateUpdate(oldClusterResourceSet)).NotTo(Succeed())
+}
+
+func TestClusterResourceSetApplyJobValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		applyMode ClusterResourceSetApplyMode
+		applyJob  *ApplyJobSpec
+		expectErr bool
+	}{
+		{
+			name:      "should not return error for Direct mode without apply job",
+			applyMode: ClusterResourceSetApplyModeDirect,
+		},
+		{
+			name:      "should not return error for Job mode with an apply job image",
+			applyMode: ClusterResourceSetApplyModeJob,
+			applyJob:  &ApplyJobSpec{Image: "kubectl"},
+		},
+		{
+			name:      "should return error for Job mode without apply job",
+			applyMode: ClusterResourceSetApplyModeJob,
+			expectErr: true,
+		},
+		{
+			name:      "should return error for Job mode without apply job image",
+			applyMode: ClusterResourceSetApplyModeJob,
+			applyJob:  &ApplyJobSpec{Namespace: "default"},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for Direct mode with apply job",
+			applyMode: ClusterResourceSetApplyModeDirect,
+			applyJob:  &ApplyJobSpec{Image: "kubectl"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterResourceSet := &ClusterResourceSet{
+				Spec: ClusterResourceSetSpec{
+					ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+					ApplyMode:       string(tt.applyMode),
+					ApplyJob:        tt.applyJob,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(clusterResourceSet.ValidateCreate()).NotTo(Succeed())
+				return
+			}
+			g.Expect(clusterResourceSet.ValidateCreate()).To(Succeed())
+		})
+	}
+}
+
+func TestClusterResourceSetResourcesValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources []ResourceRef
+		expectErr bool
+	}{
+		{
+			name: "should not return error for distinct resources",
+			resources: []ResourceRef{
+				{Name: "calico", Kind: "ConfigMap"},
+				{Name: "calico", Kind: "Secret"},
+			},
+		},
+		{
+			name:      "should return error for an invalid name",
+			resources: []ResourceRef{{Name: "Calico_CNI", Kind: "ConfigMap"}},
+			expectErr: true,
+		},
+		{
+			name: "should return error for duplicated resources",
+			resources: []ResourceRef{
+				{Name: "calico", Kind: "ConfigMap"},
+				{Name: "calico", Kind: "ConfigMap"},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			clusterResourceSet := &ClusterResourceSet{
+				Spec: ClusterResourceSetSpec{
+					ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+					Resources:       tt.resources,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(clusterResourceSet.ValidateCreate()).NotTo(Succeed())
+				return
+			}
+			g.Expect(clusterResourceSet.ValidateCreate()).To(Succeed())
+		})
+	}
+}
+
+func TestClusterResourceSetResourcesValidationOnUpdate(t *testing.T) {
+	g := NewWithT(t)
+
+	old := &ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Finalizers: []string{ClusterResourceSetFinalizer}},
+		Spec: ClusterResourceSetSpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+			Resources: []ResourceRef{
+				{Name: "calico", Kind: "ConfigMap"},
+				{Name: "calico", Kind: "ConfigMap"},
+			},
+		},
+	}
+
+	// ClusterResourceSets created before the validation can still be updated, e.g. to remove their finalizer.
+	updated := old.DeepCopy()
+	updated.Finalizers = nil
+	g.Expect(updated.ValidateUpdate(old)).To(Succeed())
+
+	// Changes to the resources are validated.
+	updated.Spec.Resources = append(updated.Spec.Resources, ResourceRef{Name: "Calico_CNI", Kind: "ConfigMap"})
+	g.Expect(updated.ValidateUpdate(old)).NotTo(Succeed())
+
+	updated.Spec.Resources = []ResourceRef{{Name: "calico", Kind: "ConfigMap"}}
+	g.Expect(updated.ValidateUpdate(old)).To(Succeed())
+}
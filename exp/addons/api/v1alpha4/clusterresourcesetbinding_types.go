@@ -39,10 +39,46 @@ type ResourceBinding struct {
 
 	// Applied is to track if a resource is applied to the cluster or not.
 	Applied bool `json:"applied"`
+
+	// ApplyJob is the status of the Job applying the resource in the cluster, when the ClusterResourceSet
+	// applyMode is Job.
+	// +optional
+	ApplyJob *ApplyJobStatus `json:"applyJob,omitempty"`
 }
 
 // ANCHOR_END: ResourceBinding
 
+// ApplyJobPhase is a string representation of the phase of a Job applying a resource.
+type ApplyJobPhase string
+
+const (
+	// ApplyJobPhaseRunning is the phase of a Job which is applying the resource.
+	ApplyJobPhaseRunning ApplyJobPhase = "Running"
+
+	// ApplyJobPhaseSucceeded is the phase of a Job which applied the resource.
+	ApplyJobPhaseSucceeded ApplyJobPhase = "Succeeded"
+
+	// ApplyJobPhaseFailed is the phase of a Job which failed applying the resource; the Job is created again
+	// at the next reconciliation.
+	ApplyJobPhaseFailed ApplyJobPhase = "Failed"
+)
+
+// ApplyJobStatus is the status of a Job applying a resource in a workload cluster.
+type ApplyJobStatus struct {
+	// Name of the Job.
+	Name string `json:"name"`
+
+	// Namespace of the Job.
+	Namespace string `json:"namespace"`
+
+	// Phase of the Job.
+	Phase ApplyJobPhase `json:"phase"`
+
+	// Message describing the failure of the Job, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
 // ResourceSetBinding keeps info on all of the resources in a ClusterResourceSet.
 type ResourceSetBinding struct {
 	// ClusterResourceSetName is the name of the ClusterResourceSet that is applied to the owner cluster of the binding.
@@ -34,6 +34,10 @@ const (
 	// ApplyFailedReason (Severity=Warning) documents applying at least one of the resources to one of the matching clusters is failed.
 	ApplyFailedReason = "ApplyFailed"
 
+	// ApplyJobRunningReason (Severity=Info) documents at least one of the resources is being applied to one of the
+	// matching clusters by a Job running in the cluster.
+	ApplyJobRunningReason = "ApplyJobRunning"
+
 	// RetrievingResourceFailedReason (Severity=Warning) documents at least one of the resources are not successfully retrieved.
 	RetrievingResourceFailedReason = "RetrievingResourceFailed"
 
@@ -25,6 +25,51 @@ import (
 	apiv1alpha4 "sigs.k8s.io/cluster-api/api/v1alpha4"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplyJobSpec) DeepCopyInto(out *ApplyJobSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplyJobSpec.
+func (in *ApplyJobSpec) DeepCopy() *ApplyJobSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplyJobSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ApplyJobStatus) DeepCopyInto(out *ApplyJobStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ApplyJobStatus.
+func (in *ApplyJobStatus) DeepCopy() *ApplyJobStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ApplyJobStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterFieldSelector) DeepCopyInto(out *ClusterFieldSelector) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterFieldSelector.
+func (in *ClusterFieldSelector) DeepCopy() *ClusterFieldSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterFieldSelector)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterResourceSet) DeepCopyInto(out *ClusterResourceSet) {
 	*out = *in
@@ -172,11 +217,21 @@ func (in *ClusterResourceSetList) DeepCopyObject() runtime.Object {
 func (in *ClusterResourceSetSpec) DeepCopyInto(out *ClusterResourceSetSpec) {
 	*out = *in
 	in.ClusterSelector.DeepCopyInto(&out.ClusterSelector)
+	if in.ClusterFieldSelector != nil {
+		in, out := &in.ClusterFieldSelector, &out.ClusterFieldSelector
+		*out = new(ClusterFieldSelector)
+		**out = **in
+	}
 	if in.Resources != nil {
 		in, out := &in.Resources, &out.Resources
 		*out = make([]ResourceRef, len(*in))
 		copy(*out, *in)
 	}
+	if in.ApplyJob != nil {
+		in, out := &in.ApplyJob, &out.ApplyJob
+		*out = new(ApplyJobSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterResourceSetSpec.
@@ -219,6 +274,11 @@ func (in *ResourceBinding) DeepCopyInto(out *ResourceBinding) {
 		in, out := &in.LastAppliedTime, &out.LastAppliedTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ApplyJob != nil {
+		in, out := &in.ApplyJob, &out.ApplyJob
+		*out = new(ApplyJobStatus)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceBinding.
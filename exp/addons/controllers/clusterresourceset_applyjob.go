@@ -0,0 +1,447 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+	"unicode"
+
+	"github.com/pkg/errors"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	apiyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/internal/remoteapply"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// applyJobServiceAccountName is the name of the ServiceAccount which runs the Jobs applying resources in the
+	// workload clusters.
+	applyJobServiceAccountName = "cluster-resource-set-apply"
+
+	// applyJobLabelName is set on the Jobs applying resources, with the name of the ClusterResourceSet as value.
+	applyJobLabelName = "addons.cluster.x-k8s.io/cluster-resource-set"
+
+	// applyJobPayloadPath is where the payload Secrets are mounted in the Jobs applying resources.
+	applyJobPayloadPath = "/payload"
+
+	// applyJobBackoffLimit is the number of retries of the Jobs applying resources before they are failed.
+	applyJobBackoffLimit = 3
+
+	// applyJobActiveDeadline is the time the Jobs applying resources can run, including retries, before they are
+	// failed; this prevents a Job whose Pods can't be scheduled, or hang, from blocking the ClusterResourceSet forever.
+	applyJobActiveDeadline = 10 * time.Minute
+
+	// applyJobRequeueAfter is the interval at which the Jobs applying resources are checked while they are running.
+	applyJobRequeueAfter = 10 * time.Second
+)
+
+// applyWithJob applies the data of a resource to a workload cluster by shipping it into Secrets, and by running
+// a Job applying it there; data are split into documents, each shipped as it is into a file of the payload, so that
+// they are applied in order.
+// The returned status reports the phase of the Job; the Job is deleted once it is completed, so that it is created
+// again at the next call if it failed.
+func (r *ClusterResourceSetReconciler) applyWithJob(ctx context.Context, remoteClient client.Client, clusterResourceSet *addonsv1.ClusterResourceSet, resource addonsv1.ResourceRef, dataList [][]byte) (*addonsv1.ApplyJobStatus, error) {
+	payload, err := applyJobPayload(dataList)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &addonsv1.ApplyJobStatus{
+		Name:      applyJobName(clusterResourceSet, resource, dataList),
+		Namespace: applyJobNamespace(clusterResourceSet),
+		Phase:     addonsv1.ApplyJobPhaseRunning,
+	}
+
+	job := &batchv1.Job{}
+	if err := remoteClient.Get(ctx, client.ObjectKey{Namespace: status.Namespace, Name: status.Name}, job); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, errors.Wrapf(err, "failed to get apply Job %s/%s", status.Namespace, status.Name)
+		}
+		if err := ensureApplyJobServiceAccount(ctx, remoteClient, status.Namespace, clusterResourceSet.Spec.ApplyJob.ClusterRoleName); err != nil {
+			return nil, err
+		}
+		job = newApplyJob(clusterResourceSet, status, len(payload))
+		if err := remoteClient.Create(ctx, job); err != nil {
+			return nil, errors.Wrapf(err, "failed to create apply Job %s/%s", status.Namespace, status.Name)
+		}
+	}
+
+	switch {
+	case job.Status.Succeeded > 0:
+		status.Phase = addonsv1.ApplyJobPhaseSucceeded
+	case isApplyJobFailed(job):
+		status.Phase = addonsv1.ApplyJobPhaseFailed
+		status.Message = applyJobFailureMessage(job)
+	}
+
+	if status.Phase == addonsv1.ApplyJobPhaseRunning {
+		if err := ensureApplyJobPayload(ctx, remoteClient, clusterResourceSet, job, payload); err != nil {
+			return nil, err
+		}
+		return status, nil
+	}
+
+	// The payload Secrets are owned by the Job, so they are garbage collected together with the Job's Pods.
+	if err := remoteClient.Delete(ctx, job, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !apierrors.IsNotFound(err) {
+		return nil, errors.Wrapf(err, "failed to delete apply Job %s/%s", status.Namespace, status.Name)
+	}
+	return status, nil
+}
+
+// applyJobNamespace returns the namespace of the workload clusters where the Jobs of a ClusterResourceSet run.
+func applyJobNamespace(clusterResourceSet *addonsv1.ClusterResourceSet) string {
+	if clusterResourceSet.Spec.ApplyJob != nil && clusterResourceSet.Spec.ApplyJob.Namespace != "" {
+		return clusterResourceSet.Spec.ApplyJob.Namespace
+	}
+	return addonsv1.DefaultApplyJobNamespace
+}
+
+// applyJobClusterRoleName returns the name of the ClusterRole bound to the ServiceAccount running the Jobs of a
+// ClusterResourceSet, if any.
+func applyJobClusterRoleName(clusterResourceSet *addonsv1.ClusterResourceSet) string {
+	if clusterResourceSet.Spec.ApplyJob == nil {
+		return ""
+	}
+	return clusterResourceSet.Spec.ApplyJob.ClusterRoleName
+}
+
+// applyJobName returns the name of the Job applying a resource; the name depends on the data of the resource, so that
+// a new Job is created if the resource is changed.
+func applyJobName(clusterResourceSet *addonsv1.ClusterResourceSet, resource addonsv1.ResourceRef, dataList [][]byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s/%s/%s/%s", clusterResourceSet.Name, resource.Kind, resource.Name, computeHash(dataList))
+	return fmt.Sprintf("crs-apply-%x", hash.Sum(nil))[:26]
+}
+
+// applyJobPayload returns the data of the payload Secrets of a Job. Each document of the values of the resource is
+// shipped as it is into a file of the payload, and the files are split across as many Secrets as needed to fit in
+// the maximum size of a Secret; all the Secrets are mounted in the same directory.
+func applyJobPayload(dataList [][]byte) ([]map[string][]byte, error) {
+	var payload []map[string][]byte
+	size := 0
+	for i, data := range dataList {
+		documents, err := applyJobDocuments(data)
+		if err != nil {
+			return nil, err
+		}
+		for j, document := range documents {
+			if len(document) > corev1.MaxSecretSize {
+				return nil, errors.Errorf("document %d of value %d of %d bytes exceeds the maximum size of a Secret of %d bytes", j, i, len(document), corev1.MaxSecretSize)
+			}
+			if len(payload) == 0 || size+len(document) > corev1.MaxSecretSize {
+				payload = append(payload, map[string][]byte{})
+				size = 0
+			}
+
+			// kubectl applies the files in a directory in lexical order.
+			payload[len(payload)-1][fmt.Sprintf("%04d-%05d.yaml", i, j)] = document
+			size += len(document)
+		}
+	}
+	return payload, nil
+}
+
+// applyJobDocuments splits data in YAML, JSON or JSON list format into documents, each with a single object,
+// without encoding them again.
+func applyJobDocuments(data []byte) ([][]byte, error) {
+	// Data are parsed upfront, so that invalid data are reported as in Direct mode.
+	if _, err := remoteapply.ParseObjects(data); err != nil {
+		return nil, err
+	}
+
+	if bytes.HasPrefix(bytes.TrimLeftFunc(data, unicode.IsSpace), []byte("[")) {
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, errors.Wrap(err, "failed to split the JSON list into documents")
+		}
+		documents := make([][]byte, 0, len(items))
+		for _, item := range items {
+			documents = append(documents, item)
+		}
+		return documents, nil
+	}
+
+	var documents [][]byte
+	reader := apiyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(data)))
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return documents, nil
+		}
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to split the YAML into documents")
+		}
+
+		// Skip the empty documents, e.g. generated by two --- in a row, which kubectl rejects.
+		var obj map[string]interface{}
+		if err := yaml.Unmarshal(document, &obj); err != nil {
+			return nil, errors.Wrap(err, "failed to split the YAML into documents")
+		}
+		if obj == nil {
+			continue
+		}
+		documents = append(documents, document)
+	}
+}
+
+// ensureApplyJobServiceAccount creates the ServiceAccount running the Jobs applying resources, and, if clusterRoleName
+// is set, binds it to the given ClusterRole; otherwise, the permissions must be granted to the ServiceAccount by other
+// means.
+func ensureApplyJobServiceAccount(ctx context.Context, remoteClient client.Client, namespace, clusterRoleName string) error {
+	serviceAccount := &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      applyJobServiceAccountName,
+			Namespace: namespace,
+		},
+	}
+	if err := remoteClient.Create(ctx, serviceAccount); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create apply Job ServiceAccount %s/%s", namespace, applyJobServiceAccountName)
+	}
+
+	if clusterRoleName == "" {
+		return nil
+	}
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: applyJobClusterRoleBindingName(namespace, clusterRoleName),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     clusterRoleName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      applyJobServiceAccountName,
+				Namespace: namespace,
+			},
+		},
+	}
+	if err := remoteClient.Create(ctx, clusterRoleBinding); err != nil && !apierrors.IsAlreadyExists(err) {
+		return errors.Wrapf(err, "failed to create apply Job ClusterRoleBinding %s", clusterRoleBinding.Name)
+	}
+	return nil
+}
+
+// applyJobClusterRoleBindingName returns the name of the ClusterRoleBinding granting a ClusterRole to the ServiceAccount
+// running the Jobs in a namespace.
+func applyJobClusterRoleBindingName(namespace, clusterRoleName string) string {
+	return fmt.Sprintf("%s-%s-%s", applyJobServiceAccountName, namespace, clusterRoleName)
+}
+
+// newApplyJob returns the Job applying a resource.
+// The Job runs in the host network and tolerates all the taints, so that it can apply resources like e.g. the CNI
+// before the nodes are ready.
+func newApplyJob(clusterResourceSet *addonsv1.ClusterResourceSet, status *addonsv1.ApplyJobStatus, payloadSecrets int) *batchv1.Job {
+	sources := make([]corev1.VolumeProjection, 0, payloadSecrets)
+	for i := 0; i < payloadSecrets; i++ {
+		sources = append(sources, corev1.VolumeProjection{
+			Secret: &corev1.SecretProjection{
+				LocalObjectReference: corev1.LocalObjectReference{Name: applyJobPayloadSecretName(status.Name, i)},
+			},
+		})
+	}
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      status.Name,
+			Namespace: status.Namespace,
+			Labels:    map[string]string{applyJobLabelName: clusterResourceSet.Name},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:          pointer.Int32Ptr(applyJobBackoffLimit),
+			ActiveDeadlineSeconds: pointer.Int64Ptr(int64(applyJobActiveDeadline.Seconds())),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{applyJobLabelName: clusterResourceSet.Name},
+				},
+				Spec: corev1.PodSpec{
+					ServiceAccountName: applyJobServiceAccountName,
+					RestartPolicy:      corev1.RestartPolicyNever,
+					HostNetwork:        true,
+					Tolerations:        []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					Containers: []corev1.Container{
+						{
+							Name:  "apply",
+							Image: clusterResourceSet.Spec.ApplyJob.Image,
+							Command: []string{
+								"kubectl", "apply",
+								"--server-side",
+								"--field-manager", remoteapply.DefaultFieldManager,
+								"--filename", applyJobPayloadPath,
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "payload",
+									MountPath: applyJobPayloadPath,
+									ReadOnly:  true,
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "payload",
+							VolumeSource: corev1.VolumeSource{
+								Projected: &corev1.ProjectedVolumeSource{Sources: sources},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// applyJobPayloadSecretName returns the name of the i-th payload Secret of a Job.
+func applyJobPayloadSecretName(jobName string, i int) string {
+	return fmt.Sprintf("%s-%d", jobName, i)
+}
+
+// ensureApplyJobPayload creates the payload Secrets of a Job, if missing; the Secrets are created after the Job, so
+// that they can be owned by it, and the Job's Pods wait for the Secrets to be mounted.
+func ensureApplyJobPayload(ctx context.Context, remoteClient client.Client, clusterResourceSet *addonsv1.ClusterResourceSet, job *batchv1.Job, payload []map[string][]byte) error {
+	for i, data := range payload {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      applyJobPayloadSecretName(job.Name, i),
+				Namespace: job.Namespace,
+				Labels:    map[string]string{applyJobLabelName: clusterResourceSet.Name},
+				OwnerReferences: []metav1.OwnerReference{
+					*metav1.NewControllerRef(job, batchv1.SchemeGroupVersion.WithKind("Job")),
+				},
+			},
+			Data: data,
+		}
+		if err := remoteClient.Create(ctx, secret); err != nil && !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create apply Job payload Secret %s/%s", secret.Namespace, secret.Name)
+		}
+	}
+	return nil
+}
+
+// isApplyJobFailed returns true if a Job has the Failed condition set to true.
+func isApplyJobFailed(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// applyJobFailureMessage returns the message of the Failed condition of a Job.
+func applyJobFailureMessage(job *batchv1.Job) string {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed {
+			return fmt.Sprintf("%s: %s", c.Reason, c.Message)
+		}
+	}
+	return ""
+}
+
+// cleanupApplyJobs deletes the Jobs of a deleted ClusterResourceSet from a workload cluster; binding is the
+// ClusterResourceSetBinding of the cluster, without the deleted ClusterResourceSet.
+func (r *ClusterResourceSetReconciler) cleanupApplyJobs(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet, binding *addonsv1.ClusterResourceSetBinding) error {
+	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return err
+	}
+
+	others := make([]*addonsv1.ClusterResourceSet, 0, len(binding.Spec.Bindings))
+	for _, b := range binding.Spec.Bindings {
+		other := &addonsv1.ClusterResourceSet{}
+		if err := r.Client.Get(ctx, client.ObjectKey{Namespace: clusterResourceSet.Namespace, Name: b.ClusterResourceSetName}, other); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return errors.Wrapf(err, "failed to get ClusterResourceSet %s/%s", clusterResourceSet.Namespace, b.ClusterResourceSetName)
+		}
+		others = append(others, other)
+	}
+
+	return deleteApplyJobs(ctx, remoteClient, clusterResourceSet, others)
+}
+
+// deleteApplyJobs deletes the Jobs of a deleted ClusterResourceSet from a workload cluster, together with their payload
+// Secrets; the ServiceAccount running the Jobs, and its ClusterRoleBinding, are shared by all the ClusterResourceSets
+// running Jobs in the same namespace, so they are deleted only if none of the other ClusterResourceSets bound to the
+// cluster uses them.
+func deleteApplyJobs(ctx context.Context, remoteClient client.Client, clusterResourceSet *addonsv1.ClusterResourceSet, others []*addonsv1.ClusterResourceSet) error {
+	namespace := applyJobNamespace(clusterResourceSet)
+	clusterRoleName := applyJobClusterRoleName(clusterResourceSet)
+	labels := client.MatchingLabels{applyJobLabelName: clusterResourceSet.Name}
+
+	if err := remoteClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace(namespace), labels, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil {
+		return errors.Wrapf(err, "failed to delete apply Jobs in namespace %s", namespace)
+	}
+	if err := remoteClient.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace(namespace), labels); err != nil {
+		return errors.Wrapf(err, "failed to delete apply Job payload Secrets in namespace %s", namespace)
+	}
+
+	serviceAccountInUse, clusterRoleBindingInUse := false, false
+	for _, other := range others {
+		if other.Spec.ApplyMode != string(addonsv1.ClusterResourceSetApplyModeJob) || applyJobNamespace(other) != namespace {
+			continue
+		}
+		serviceAccountInUse = true
+		if applyJobClusterRoleName(other) == clusterRoleName {
+			clusterRoleBindingInUse = true
+		}
+	}
+
+	if clusterRoleName != "" && !clusterRoleBindingInUse {
+		clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: applyJobClusterRoleBindingName(namespace, clusterRoleName),
+			},
+		}
+		if err := remoteClient.Delete(ctx, clusterRoleBinding); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete apply Job ClusterRoleBinding %s", clusterRoleBinding.Name)
+		}
+	}
+
+	if !serviceAccountInUse {
+		serviceAccount := &corev1.ServiceAccount{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      applyJobServiceAccountName,
+				Namespace: namespace,
+			},
+		}
+		if err := remoteClient.Delete(ctx, serviceAccount); err != nil && !apierrors.IsNotFound(err) {
+			return errors.Wrapf(err, "failed to delete apply Job ServiceAccount %s/%s", namespace, applyJobServiceAccountName)
+		}
+	}
+	return nil
+}
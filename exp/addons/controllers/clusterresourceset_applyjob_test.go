@@ -0,0 +1,322 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestApplyWithJob(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	clusterResourceSet := &addonsv1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "crs", Namespace: "default"},
+		Spec: addonsv1.ClusterResourceSetSpec{
+			ApplyMode: string(addonsv1.ClusterResourceSetApplyModeJob),
+			ApplyJob:  &addonsv1.ApplyJobSpec{Image: "kubectl:v1.20.2"},
+		},
+	}
+	resource := addonsv1.ResourceRef{Name: "cni", Kind: string(addonsv1.ConfigMapClusterResourceSetResourceKind)}
+	dataList := [][]byte{
+		[]byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  namespace: default\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  namespace: default\n"),
+		[]byte(`[{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "c"}}]`),
+	}
+
+	completeJob := func(g *WithT, remoteClient client.Client, status *addonsv1.ApplyJobStatus, update func(*batchv1.Job)) {
+		job := &batchv1.Job{}
+		g.Expect(remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: status.Name}, job)).To(Succeed())
+		update(job)
+		g.Expect(remoteClient.Status().Update(context.TODO(), job)).To(Succeed())
+	}
+
+	t.Run("creates the Job and its payload, and reports it running", func(t *testing.T) {
+		g := NewWithT(t)
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &ClusterResourceSetReconciler{}
+
+		status, err := r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.Phase).To(Equal(addonsv1.ApplyJobPhaseRunning))
+		g.Expect(status.Namespace).To(Equal(addonsv1.DefaultApplyJobNamespace))
+		g.Expect(status.Name).To(Equal(applyJobName(clusterResourceSet, resource, dataList)))
+
+		job := &batchv1.Job{}
+		g.Expect(remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: status.Name}, job)).To(Succeed())
+		g.Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+		g.Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("kubectl:v1.20.2"))
+		g.Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal(applyJobServiceAccountName))
+		g.Expect(job.Spec.ActiveDeadlineSeconds).To(Equal(pointer.Int64Ptr(int64(applyJobActiveDeadline.Seconds()))))
+
+		g.Expect(job.Spec.Template.Spec.Volumes).To(HaveLen(1))
+		g.Expect(job.Spec.Template.Spec.Volumes[0].Projected.Sources).To(HaveLen(1))
+		g.Expect(job.Spec.Template.Spec.Volumes[0].Projected.Sources[0].Secret.Name).To(Equal(status.Name + "-0"))
+
+		secret := &corev1.Secret{}
+		g.Expect(remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: status.Name + "-0"}, secret)).To(Succeed())
+		g.Expect(secret.Data).To(Equal(map[string][]byte{
+			"0000-00000.yaml": []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\n  namespace: default\n"),
+			"0000-00001.yaml": []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: b\n  namespace: default\n"),
+			"0001-00000.yaml": []byte(`{"apiVersion": "v1", "kind": "Namespace", "metadata": {"name": "c"}}`),
+		}))
+
+		// No permission is granted to the ServiceAccount, unless a ClusterRole is set.
+		g.Expect(remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: applyJobServiceAccountName}, &corev1.ServiceAccount{})).To(Succeed())
+		clusterRoleBindings := &rbacv1.ClusterRoleBindingList{}
+		g.Expect(remoteClient.List(context.TODO(), clusterRoleBindings)).To(Succeed())
+		g.Expect(clusterRoleBindings.Items).To(BeEmpty())
+
+		// Applying again while the Job is running does not change anything.
+		status, err = r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.Phase).To(Equal(addonsv1.ApplyJobPhaseRunning))
+	})
+
+	t.Run("binds the ServiceAccount to the ClusterRole, if set", func(t *testing.T) {
+		g := NewWithT(t)
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &ClusterResourceSetReconciler{}
+
+		clusterResourceSet := clusterResourceSet.DeepCopy()
+		clusterResourceSet.Spec.ApplyJob.ClusterRoleName = "cluster-admin"
+		status, err := r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		clusterRoleBinding := &rbacv1.ClusterRoleBinding{}
+		g.Expect(remoteClient.Get(context.TODO(), client.ObjectKey{Name: applyJobServiceAccountName + "-" + status.Namespace + "-cluster-admin"}, clusterRoleBinding)).To(Succeed())
+		g.Expect(clusterRoleBinding.RoleRef.Name).To(Equal("cluster-admin"))
+		g.Expect(clusterRoleBinding.Subjects).To(ConsistOf(rbacv1.Subject{
+			Kind:      rbacv1.ServiceAccountKind,
+			Name:      applyJobServiceAccountName,
+			Namespace: status.Namespace,
+		}))
+	})
+
+	t.Run("splits a payload larger than a Secret across several Secrets", func(t *testing.T) {
+		g := NewWithT(t)
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &ClusterResourceSetReconciler{}
+
+		// Three ConfigMaps of 400KiB each, in a single value, fit in two Secrets.
+		var documents []string
+		for _, name := range []string{"a", "b", "c"} {
+			documents = append(documents, fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\n  namespace: default\ndata:\n  key: %s\n", name, strings.Repeat("x", 400*1024)))
+		}
+		largeDataList := [][]byte{[]byte(strings.Join(documents, "---\n"))}
+
+		status, err := r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, largeDataList)
+		g.Expect(err).NotTo(HaveOccurred())
+
+		job := &batchv1.Job{}
+		g.Expect(remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: status.Name}, job)).To(Succeed())
+		g.Expect(job.Spec.Template.Spec.Volumes[0].Projected.Sources).To(HaveLen(2))
+
+		keys := []string{}
+		for i := 0; i < 2; i++ {
+			secret := &corev1.Secret{}
+			g.Expect(remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: fmt.Sprintf("%s-%d", status.Name, i)}, secret)).To(Succeed())
+			size := 0
+			for key, value := range secret.Data {
+				keys = append(keys, key)
+				size += len(value)
+			}
+			g.Expect(size).To(BeNumerically("<=", corev1.MaxSecretSize))
+		}
+		g.Expect(keys).To(ConsistOf("0000-00000.yaml", "0000-00001.yaml", "0000-00002.yaml"))
+	})
+
+	t.Run("fails for a single object larger than a Secret", func(t *testing.T) {
+		g := NewWithT(t)
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &ClusterResourceSetReconciler{}
+
+		data := fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: a\ndata:\n  key: %s\n", strings.Repeat("x", corev1.MaxSecretSize))
+		_, err := r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, [][]byte{[]byte(data)})
+		g.Expect(err).To(MatchError(ContainSubstring("exceeds the maximum size of a Secret")))
+	})
+
+	t.Run("reports the Job succeeded, and deletes it", func(t *testing.T) {
+		g := NewWithT(t)
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &ClusterResourceSetReconciler{}
+
+		status, err := r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+		completeJob(g, remoteClient, status, func(job *batchv1.Job) {
+			job.Status.Succeeded = 1
+		})
+
+		status, err = r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.Phase).To(Equal(addonsv1.ApplyJobPhaseSucceeded))
+
+		err = remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: status.Name}, &batchv1.Job{})
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	t.Run("reports the Job failed, and deletes it so that it is created again", func(t *testing.T) {
+		g := NewWithT(t)
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &ClusterResourceSetReconciler{}
+
+		status, err := r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+		completeJob(g, remoteClient, status, func(job *batchv1.Job) {
+			job.Status.Conditions = []batchv1.JobCondition{
+				{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "BackoffLimitExceeded", Message: "Job has reached the specified backoff limit"},
+			}
+		})
+
+		status, err = r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.Phase).To(Equal(addonsv1.ApplyJobPhaseFailed))
+		g.Expect(status.Message).To(Equal("BackoffLimitExceeded: Job has reached the specified backoff limit"))
+
+		err = remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: status.Namespace, Name: status.Name}, &batchv1.Job{})
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		status, err = r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, dataList)
+		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(status.Phase).To(Equal(addonsv1.ApplyJobPhaseRunning))
+	})
+
+	t.Run("fails for invalid data", func(t *testing.T) {
+		g := NewWithT(t)
+		remoteClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		r := &ClusterResourceSetReconciler{}
+
+		_, err := r.applyWithJob(context.TODO(), remoteClient, clusterResourceSet, resource, [][]byte{[]byte("[")})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
+
+func TestApplyJobName(t *testing.T) {
+	g := NewWithT(t)
+
+	clusterResourceSet := &addonsv1.ClusterResourceSet{ObjectMeta: metav1.ObjectMeta{Name: "crs"}}
+	resource := addonsv1.ResourceRef{Name: "cni", Kind: string(addonsv1.ConfigMapClusterResourceSetResourceKind)}
+
+	name := applyJobName(clusterResourceSet, resource, [][]byte{[]byte("a")})
+	g.Expect(name).To(HavePrefix("crs-apply-"))
+	g.Expect(len(name)).To(BeNumerically("<=", 63))
+	g.Expect(applyJobName(clusterResourceSet, resource, [][]byte{[]byte("a")})).To(Equal(name))
+	g.Expect(applyJobName(clusterResourceSet, resource, [][]byte{[]byte("b")})).NotTo(Equal(name))
+}
+
+func TestDeleteApplyJobs(t *testing.T) {
+	scheme := runtime.NewScheme()
+	g := NewWithT(t)
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	newClusterResourceSet := func(name, applyMode, clusterRoleName string) *addonsv1.ClusterResourceSet {
+		crs := &addonsv1.ClusterResourceSet{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       addonsv1.ClusterResourceSetSpec{ApplyMode: applyMode},
+		}
+		if applyMode == string(addonsv1.ClusterResourceSetApplyModeJob) {
+			crs.Spec.ApplyJob = &addonsv1.ApplyJobSpec{Image: "kubectl:v1.20.2", ClusterRoleName: clusterRoleName}
+		}
+		return crs
+	}
+	labeled := func(obj client.Object, crsName string) client.Object {
+		obj.SetNamespace(addonsv1.DefaultApplyJobNamespace)
+		obj.SetLabels(map[string]string{applyJobLabelName: crsName})
+		return obj
+	}
+	jobMode := string(addonsv1.ClusterResourceSetApplyModeJob)
+	clusterRoleBindingName := applyJobClusterRoleBindingName(addonsv1.DefaultApplyJobNamespace, "cluster-admin")
+
+	tests := []struct {
+		name                   string
+		others                 []*addonsv1.ClusterResourceSet
+		wantServiceAccount     bool
+		wantClusterRoleBinding bool
+	}{
+		{
+			name: "deletes the ServiceAccount and the ClusterRoleBinding if no other ClusterResourceSet uses them",
+			others: []*addonsv1.ClusterResourceSet{
+				newClusterResourceSet("direct", string(addonsv1.ClusterResourceSetApplyModeDirect), ""),
+			},
+		},
+		{
+			name: "keeps the ServiceAccount and the ClusterRoleBinding if another ClusterResourceSet uses them",
+			others: []*addonsv1.ClusterResourceSet{
+				newClusterResourceSet("other", jobMode, "cluster-admin"),
+			},
+			wantServiceAccount:     true,
+			wantClusterRoleBinding: true,
+		},
+		{
+			name: "keeps only the ServiceAccount if another ClusterResourceSet uses it with another ClusterRole",
+			others: []*addonsv1.ClusterResourceSet{
+				newClusterResourceSet("other", jobMode, ""),
+			},
+			wantServiceAccount: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			clusterResourceSet := newClusterResourceSet("crs", jobMode, "cluster-admin")
+			remoteClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				labeled(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "crs-job"}}, "crs"),
+				labeled(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "crs-job-0"}}, "crs"),
+				labeled(&batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "other-job"}}, "other"),
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: applyJobServiceAccountName, Namespace: addonsv1.DefaultApplyJobNamespace}},
+				&rbacv1.ClusterRoleBinding{ObjectMeta: metav1.ObjectMeta{Name: clusterRoleBindingName}},
+			).Build()
+
+			g.Expect(deleteApplyJobs(context.TODO(), remoteClient, clusterResourceSet, tt.others)).To(Succeed())
+
+			jobs := &batchv1.JobList{}
+			g.Expect(remoteClient.List(context.TODO(), jobs)).To(Succeed())
+			g.Expect(jobs.Items).To(HaveLen(1))
+			g.Expect(jobs.Items[0].Name).To(Equal("other-job"))
+
+			secrets := &corev1.SecretList{}
+			g.Expect(remoteClient.List(context.TODO(), secrets)).To(Succeed())
+			g.Expect(secrets.Items).To(BeEmpty())
+
+			err := remoteClient.Get(context.TODO(), client.ObjectKey{Namespace: addonsv1.DefaultApplyJobNamespace, Name: applyJobServiceAccountName}, &corev1.ServiceAccount{})
+			g.Expect(apierrors.IsNotFound(err)).To(Equal(!tt.wantServiceAccount))
+
+			err = remoteClient.Get(context.TODO(), client.ObjectKey{Name: clusterRoleBindingName}, &rbacv1.ClusterRoleBinding{})
+			g.Expect(apierrors.IsNotFound(err)).To(Equal(!tt.wantClusterRoleBinding))
+		})
+	}
+}
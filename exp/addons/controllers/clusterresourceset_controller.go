@@ -35,14 +35,16 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
 	resourcepredicates "sigs.k8s.io/cluster-api/exp/addons/controllers/predicates"
+	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/internal/remoteapply"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/defaulting"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
@@ -63,6 +65,10 @@ type ClusterResourceSetReconciler struct {
 	Client           client.Client
 	Tracker          *remote.ClusterCacheTracker
 	WatchFilterValue string
+
+	// WebhooksDisabled applies the defaults and the validation of the admission webhooks to the ClusterResourceSets
+	// inside the controller, for management clusters where the webhooks are not installed.
+	WebhooksDisabled bool
 }
 
 func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
@@ -72,9 +78,11 @@ func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr
 			&source.Kind{Type: &clusterv1.Cluster{}},
 			handler.EnqueueRequestsFromMapFunc(r.clusterToClusterResourceSet),
 		).
+		// ConfigMaps and Secrets are watched as metadata only, so their data is not cached; the Secret type is not part
+		// of the metadata, so all the Secrets are mapped and the type is checked when the Secrets are read.
 		Watches(
 			&source.Kind{Type: &corev1.ConfigMap{}},
-			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet),
+			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet(addonsv1.ConfigMapClusterResourceSetResourceKind)),
 			builder.OnlyMetadata,
 			builder.WithPredicates(
 				resourcepredicates.ResourceCreate(ctrl.LoggerFrom(ctx)),
@@ -82,15 +90,15 @@ func (r *ClusterResourceSetReconciler) SetupWithManager(ctx context.Context, mgr
 		).
 		Watches(
 			&source.Kind{Type: &corev1.Secret{}},
-			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet),
+			handler.EnqueueRequestsFromMapFunc(r.resourceToClusterResourceSet(addonsv1.SecretClusterResourceSetResourceKind)),
 			builder.OnlyMetadata,
 			builder.WithPredicates(
-				resourcepredicates.AddonsSecretCreate(ctrl.LoggerFrom(ctx)),
+				resourcepredicates.ResourceCreate(ctrl.LoggerFrom(ctx)),
 			),
 		).
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
-		Complete(r)
+		Complete(feature.GatedReconciler(feature.ClusterResourceSet, r))
 
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
@@ -127,6 +135,14 @@ func (r *ClusterResourceSetReconciler) Reconcile(ctx context.Context, req ctrl.R
 		}
 	}()
 
+	// Apply the defaults and the validation of the webhooks, when they are not installed.
+	if r.WebhooksDisabled {
+		if err := defaulting.DefaultAndValidate(clusterResourceSet); err != nil {
+			log.Error(err, "Invalid ClusterResourceSet, waiting for it to be fixed")
+			return ctrl.Result{}, nil
+		}
+	}
+
 	clusters, err := r.getClustersByClusterResourceSetSelector(ctx, clusterResourceSet)
 	if err != nil {
 		log.Error(err, "Failed fetching clusters that matches ClusterResourceSet labels", "ClusterResourceSet", clusterResourceSet.Name)
@@ -145,13 +161,40 @@ func (r *ClusterResourceSetReconciler) Reconcile(ctx context.Context, req ctrl.R
 		return r.reconcileDelete(ctx, clusters, clusterResourceSet)
 	}
 
+	// Clusters not matching the Kubernetes version range may be upgraded later, so they are checked again periodically.
+	versionMismatch := false
+	applyJobRunning := false
+	result := ctrl.Result{}
 	for _, cluster := range clusters {
-		if err := r.ApplyClusterResourceSet(ctx, cluster, clusterResourceSet); err != nil {
+		matches, err := r.matchesKubernetesVersion(ctx, cluster, clusterResourceSet)
+		if err != nil {
+			conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ClusterMatchFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
 			return ctrl.Result{}, err
 		}
+		if !matches {
+			versionMismatch = true
+			continue
+		}
+
+		applyResult, err := r.ApplyClusterResourceSet(ctx, cluster, clusterResourceSet)
+		if err != nil {
+			return ctrl.Result{}, err
+		}
+		if !applyResult.IsZero() {
+			applyJobRunning = true
+		}
+		result = util.LowestNonZeroResult(result, applyResult)
 	}
 
-	return ctrl.Result{}, nil
+	// Clusters processed after the ones with running apply Jobs could have marked the resources as applied.
+	if applyJobRunning {
+		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyJobRunningReason, clusterv1.ConditionSeverityInfo, "")
+	}
+
+	if versionMismatch {
+		result = util.LowestNonZeroResult(result, ctrl.Result{RequeueAfter: kubernetesVersionRecheckInterval})
+	}
+	return result, nil
 }
 
 // reconcileDelete removes the deleted ClusterResourceSet from all the ClusterResourceSetBindings it is added to.
@@ -180,6 +223,14 @@ func (r *ClusterResourceSetReconciler) reconcileDelete(ctx context.Context, clus
 
 		clusterResourceSetBinding.DeleteBinding(crs)
 
+		// The Jobs applying resources are deleted on a best effort basis, so that an unreachable workload cluster
+		// doesn't block the deletion of the ClusterResourceSet.
+		if crs.Spec.ApplyMode == string(addonsv1.ClusterResourceSetApplyModeJob) {
+			if err := r.cleanupApplyJobs(ctx, cluster, crs, clusterResourceSetBinding); err != nil {
+				log.Error(err, "failed to delete the apply Jobs from the workload cluster", "cluster", cluster.Name)
+			}
+		}
+
 		// If CRS list is empty in the binding, delete the binding else
 		// attempt to Patch the ClusterResourceSetBinding object after delete reconciliation if there is at least 1 binding left.
 		if len(clusterResourceSetBinding.Spec.Bindings) == 0 {
@@ -196,7 +247,8 @@ func (r *ClusterResourceSetReconciler) reconcileDelete(ctx context.Context, clus
 	return ctrl.Result{}, nil
 }
 
-// getClustersByClusterResourceSetSelector fetches Clusters matched by the ClusterResourceSet's label selector that are in the same namespace as the ClusterResourceSet object.
+// getClustersByClusterResourceSetSelector fetches Clusters matched by the ClusterResourceSet's label selector and cluster name prefix
+// that are in the same namespace as the ClusterResourceSet object. The Kubernetes version of the Clusters is not checked.
 func (r *ClusterResourceSetReconciler) getClustersByClusterResourceSetSelector(ctx context.Context, clusterResourceSet *addonsv1.ClusterResourceSet) ([]*clusterv1.Cluster, error) {
 	log := ctrl.LoggerFrom(ctx)
 
@@ -206,8 +258,8 @@ func (r *ClusterResourceSetReconciler) getClustersByClusterResourceSetSelector(c
 		return nil, errors.Wrap(err, "unable to convert selector")
 	}
 
-	// If a ClusterResourceSet has a nil or empty selector, it should match nothing, not everything.
-	if selector.Empty() {
+	// If a ClusterResourceSet has nil or empty selectors, it should match nothing, not everything.
+	if selector.Empty() && clusterResourceSet.Spec.ClusterFieldSelector.IsEmpty() {
 		log.Info("Empty ClusterResourceSet selector: No clusters are selected.")
 		return nil, nil
 	}
@@ -219,7 +271,7 @@ func (r *ClusterResourceSetReconciler) getClustersByClusterResourceSetSelector(c
 	clusters := []*clusterv1.Cluster{}
 	for i := range clusterList.Items {
 		c := &clusterList.Items[i]
-		if c.DeletionTimestamp.IsZero() {
+		if c.DeletionTimestamp.IsZero() && matchesClusterName(clusterResourceSet, c) {
 			clusters = append(clusters, c)
 		}
 	}
@@ -230,26 +282,27 @@ func (r *ClusterResourceSetReconciler) getClustersByClusterResourceSetSelector(c
 // cluster's ClusterResourceSetBinding.
 // In ApplyOnce strategy, resources are applied only once to a particular cluster. ClusterResourceSetBinding is used to check if a resource is applied before.
 // It applies resources best effort and continue on scenarios like: unsupported resource types, failure during creation, missing resources.
+// In Job mode, resources are applied by Jobs running in the cluster, and a requeue is requested until the Jobs are completed.
 // TODO: If a resource already exists in the cluster but not applied by ClusterResourceSet, the resource will be updated ?
-func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) error {
+func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) (ctrl.Result, error) {
 	log := ctrl.LoggerFrom(ctx, "cluster", cluster.Name)
 
 	remoteClient, err := r.Tracker.GetClient(ctx, util.ObjectKey(cluster))
 	if err != nil {
 		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.RemoteClusterClientFailedReason, clusterv1.ConditionSeverityError, err.Error())
-		return err
+		return ctrl.Result{}, err
 	}
 
 	// Get ClusterResourceSetBinding object for the cluster.
 	clusterResourceSetBinding, err := r.getOrCreateClusterResourceSetBinding(ctx, cluster, clusterResourceSet)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
 	// Initialize the patch helper.
 	patchHelper, err := patch.NewHelper(clusterResourceSetBinding, r.Client)
 	if err != nil {
-		return err
+		return ctrl.Result{}, err
 	}
 
 	defer func() {
@@ -260,6 +313,7 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 	}()
 
 	errList := []error{}
+	applyJobRunning := false
 	resourceSetBinding := clusterResourceSetBinding.GetOrCreateBinding(clusterResourceSet)
 
 	// Iterate all resources and apply them to the cluster and update the resource status in the ClusterResourceSetBinding object.
@@ -331,13 +385,42 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 			dataList = append(dataList, byteArr)
 		}
 
+		// In Job mode, ship all the values of the resource to the cluster, and apply them with a Job running there.
+		if clusterResourceSet.Spec.ApplyMode == string(addonsv1.ClusterResourceSetApplyModeJob) {
+			jobStatus, err := r.applyWithJob(ctx, remoteClient, clusterResourceSet, resource, dataList)
+			if err != nil {
+				log.Error(err, "failed to apply ClusterResourceSet resource with a Job", "Resource kind", resource.Kind, "Resource name", resource.Name)
+				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+				errList = append(errList, err)
+				continue
+			}
+
+			switch jobStatus.Phase {
+			case addonsv1.ApplyJobPhaseRunning:
+				applyJobRunning = true
+			case addonsv1.ApplyJobPhaseFailed:
+				err := errors.Errorf("apply Job %s/%s failed: %s", jobStatus.Namespace, jobStatus.Name, jobStatus.Message)
+				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
+				errList = append(errList, err)
+			}
+
+			resourceSetBinding.SetBinding(addonsv1.ResourceBinding{
+				ResourceRef:     resource,
+				Hash:            computeHash(dataList),
+				Applied:         jobStatus.Phase == addonsv1.ApplyJobPhaseSucceeded,
+				LastAppliedTime: &metav1.Time{Time: time.Now().UTC()},
+				ApplyJob:        jobStatus,
+			})
+			continue
+		}
+
 		// Apply all values in the key-value pair of the resource to the cluster.
 		// As there can be multiple key-value pairs in a resource, each value may have multiple objects in it.
 		isSuccessful := true
 		for i := range dataList {
 			data := dataList[i]
 
-			if err := apply(ctx, remoteClient, data); err != nil {
+			if err := remoteapply.ApplyData(ctx, remoteClient, data, remoteapply.Options{}); err != nil {
 				isSuccessful = false
 				log.Error(err, "failed to apply ClusterResourceSet resource", "Resource kind", resource.Kind, "Resource name", resource.Name)
 				conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyFailedReason, clusterv1.ConditionSeverityWarning, err.Error())
@@ -353,12 +436,17 @@ func (r *ClusterResourceSetReconciler) ApplyClusterResourceSet(ctx context.Conte
 		})
 	}
 	if len(errList) > 0 {
-		return kerrors.NewAggregate(errList)
+		return ctrl.Result{}, kerrors.NewAggregate(errList)
+	}
+
+	if applyJobRunning {
+		conditions.MarkFalse(clusterResourceSet, addonsv1.ResourcesAppliedCondition, addonsv1.ApplyJobRunningReason, clusterv1.ConditionSeverityInfo, "")
+		return ctrl.Result{RequeueAfter: applyJobRequeueAfter}, nil
 	}
 
 	conditions.MarkTrue(clusterResourceSet, addonsv1.ResourcesAppliedCondition)
 
-	return nil
+	return ctrl.Result{}, nil
 }
 
 // getResource retrieves the requested resource and convert it to unstructured type.
@@ -436,15 +524,16 @@ func (r *ClusterResourceSetReconciler) clusterToClusterResourceSet(o client.Obje
 
 		selector, err := metav1.LabelSelectorAsSelector(&rs.Spec.ClusterSelector)
 		if err != nil {
-			return nil
+			continue
 		}
 
-		// If a ClusterResourceSet has a nil or empty selector, it should match nothing, not everything.
-		if selector.Empty() {
-			return nil
+		// If a ClusterResourceSet has nil or empty selectors, it should match nothing, not everything.
+		if selector.Empty() && rs.Spec.ClusterFieldSelector.IsEmpty() {
+			continue
 		}
 
-		if !selector.Matches(labels) {
+		// The Kubernetes version is checked when reconciling the ClusterResourceSet.
+		if !selector.Matches(labels) || !matchesClusterName(rs, cluster) {
 			continue
 		}
 
@@ -454,46 +543,41 @@ func (r *ClusterResourceSetReconciler) clusterToClusterResourceSet(o client.Obje
 	return result
 }
 
-// resourceToClusterResourceSet is mapper function that maps resources to ClusterResourceSet
-func (r *ClusterResourceSetReconciler) resourceToClusterResourceSet(o client.Object) []ctrl.Request {
-	result := []ctrl.Request{}
-
-	// Add all ClusterResourceSet owners.
-	for _, owner := range o.GetOwnerReferences() {
-		if owner.Kind == "ClusterResourceSet" {
-			name := client.ObjectKey{Namespace: o.GetNamespace(), Name: owner.Name}
-			result = append(result, ctrl.Request{NamespacedName: name})
+// resourceToClusterResourceSet returns a mapper function that maps the resources of the given kind to the
+// ClusterResourceSets referencing them. Only the object metadata is used, because the resources are watched as
+// PartialObjectMetadata, whose type meta does not identify the resource kind.
+func (r *ClusterResourceSetReconciler) resourceToClusterResourceSet(kind addonsv1.ClusterResourceSetResourceKind) handler.MapFunc {
+	return func(o client.Object) []ctrl.Request {
+		result := []ctrl.Request{}
+
+		// Add all ClusterResourceSet owners.
+		for _, owner := range o.GetOwnerReferences() {
+			if owner.Kind == "ClusterResourceSet" {
+				name := client.ObjectKey{Namespace: o.GetNamespace(), Name: owner.Name}
+				result = append(result, ctrl.Request{NamespacedName: name})
+			}
 		}
-	}
-
-	// If there is any ClusterResourceSet owner, that means the resource is reconciled before,
-	// and existing owners are the only matching ClusterResourceSets to this resource, so no need to return all ClusterResourceSets.
-	if len(result) > 0 {
-		return result
-	}
 
-	// Only core group is accepted as resources group
-	if o.GetObjectKind().GroupVersionKind().Group != "" {
-		return result
-	}
+		// If there is any ClusterResourceSet owner, that means the resource is reconciled before,
+		// and existing owners are the only matching ClusterResourceSets to this resource, so no need to return all ClusterResourceSets.
+		if len(result) > 0 {
+			return result
+		}
 
-	crsList := &addonsv1.ClusterResourceSetList{}
-	if err := r.Client.List(context.TODO(), crsList, client.InNamespace(o.GetNamespace())); err != nil {
-		return nil
-	}
-	objKind, err := apiutil.GVKForObject(o, r.Client.Scheme())
-	if err != nil {
-		return nil
-	}
-	for _, crs := range crsList.Items {
-		for _, resource := range crs.Spec.Resources {
-			if resource.Kind == objKind.Kind && resource.Name == o.GetName() {
-				name := client.ObjectKey{Namespace: o.GetNamespace(), Name: crs.Name}
-				result = append(result, ctrl.Request{NamespacedName: name})
-				break
+		crsList := &addonsv1.ClusterResourceSetList{}
+		if err := r.Client.List(context.TODO(), crsList, client.InNamespace(o.GetNamespace())); err != nil {
+			return nil
+		}
+		for _, crs := range crsList.Items {
+			for _, resource := range crs.Spec.Resources {
+				if resource.Kind == string(kind) && resource.Name == o.GetName() {
+					name := client.ObjectKey{Namespace: o.GetNamespace(), Name: crs.Name}
+					result = append(result, ctrl.Request{NamespacedName: name})
+					break
+				}
 			}
 		}
-	}
 
-	return result
+		return result
+	}
 }
@@ -18,6 +18,7 @@ package controllers
 
 import (
 	"fmt"
+	"testing"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -26,10 +27,13 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
@@ -436,3 +440,66 @@ metadata:
 		}, timeout).Should(BeTrue())
 	})
 })
+
+func TestResourceToClusterResourceSet(t *testing.T) {
+	crs := &addonsv1.ClusterResourceSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "crs", Namespace: defaultNamespaceName},
+		Spec: addonsv1.ClusterResourceSetSpec{
+			Resources: []addonsv1.ResourceRef{
+				{Name: "resource", Kind: string(addonsv1.ConfigMapClusterResourceSetResourceKind)},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		kind     addonsv1.ClusterResourceSetResourceKind
+		object   *metav1.PartialObjectMetadata
+		expected []reconcile.Request
+	}{
+		{
+			name: "resource referenced by a ClusterResourceSet",
+			kind: addonsv1.ConfigMapClusterResourceSetResourceKind,
+			object: &metav1.PartialObjectMetadata{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "meta.k8s.io/v1", Kind: "PartialObjectMetadata"},
+				ObjectMeta: metav1.ObjectMeta{Name: "resource", Namespace: defaultNamespaceName},
+			},
+			expected: []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: defaultNamespaceName, Name: "crs"}}},
+		},
+		{
+			name: "resource of another kind with the same name",
+			kind: addonsv1.SecretClusterResourceSetResourceKind,
+			object: &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{Name: "resource", Namespace: defaultNamespaceName},
+			},
+			expected: []reconcile.Request{},
+		},
+		{
+			name: "resource owned by a ClusterResourceSet",
+			kind: addonsv1.SecretClusterResourceSetResourceKind,
+			object: &metav1.PartialObjectMetadata{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "other",
+					Namespace: defaultNamespaceName,
+					OwnerReferences: []metav1.OwnerReference{
+						{APIVersion: addonsv1.GroupVersion.String(), Kind: "ClusterResourceSet", Name: "owner"},
+					},
+				},
+			},
+			expected: []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: defaultNamespaceName, Name: "owner"}}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			scheme := runtime.NewScheme()
+			g.Expect(addonsv1.AddToScheme(scheme)).To(Succeed())
+
+			r := &ClusterResourceSetReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(crs).Build(),
+			}
+			g.Expect(r.resourceToClusterResourceSet(tt.kind)(tt.object)).To(Equal(tt.expected))
+		})
+	}
+}
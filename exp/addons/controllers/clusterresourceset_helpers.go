@@ -17,95 +17,28 @@ limitations under the License.
 package controllers
 
 import (
-	"bufio"
-	"bytes"
 	"context"
 	"crypto/sha256"
-	"encoding/json"
 	"fmt"
-	"unicode"
+	"strings"
+	"time"
 
+	"github.com/blang/semver"
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/types"
-	kerrors "k8s.io/apimachinery/pkg/util/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	addonsv1 "sigs.k8s.io/cluster-api/exp/addons/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/util"
-	utilresource "sigs.k8s.io/cluster-api/util/resource"
-	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/cluster-api/util/version"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-var jsonListPrefix = []byte("[")
-
-// isJSONList returns whether the data is in JSON list format.
-func isJSONList(data []byte) (bool, error) {
-	const peekSize = 32
-	buffer := bufio.NewReaderSize(bytes.NewReader(data), peekSize)
-	b, err := buffer.Peek(peekSize)
-	if err != nil {
-		return false, err
-	}
-	trim := bytes.TrimLeftFunc(b, unicode.IsSpace)
-	return bytes.HasPrefix(trim, jsonListPrefix), nil
-}
-
-func apply(ctx context.Context, c client.Client, data []byte) error {
-	isJSONList, err := isJSONList(data)
-	if err != nil {
-		return err
-	}
-	objs := []unstructured.Unstructured{}
-	// If it is a json list, convert each list element to an unstructured object.
-	if isJSONList {
-		var results []map[string]interface{}
-		// Unmarshal the JSON to the interface.
-		if err = json.Unmarshal(data, &results); err == nil {
-			for i := range results {
-				var u unstructured.Unstructured
-				u.SetUnstructuredContent(results[i])
-				objs = append(objs, u)
-			}
-		}
-	} else {
-		// If it is not a json list, data is either json or yaml format.
-		objs, err = utilyaml.ToUnstructured(data)
-		if err != nil {
-			return errors.Wrapf(err, "failed converting data to unstructured objects")
-		}
-	}
-
-	errList := []error{}
-	sortedObjs := utilresource.SortForCreate(objs)
-	for i := range sortedObjs {
-		if err := applyUnstructured(ctx, c, &objs[i]); err != nil {
-			errList = append(errList, err)
-		}
-	}
-	return kerrors.NewAggregate(errList)
-}
-
-func applyUnstructured(ctx context.Context, c client.Client, obj *unstructured.Unstructured) error {
-	// Create the object on the API server.
-	// TODO: Errors are only logged. If needed, exponential backoff or requeuing could be used here for remedying connection glitches etc.
-	if err := c.Create(ctx, obj); err != nil {
-		// The create call is idempotent, so if the object already exists
-		// then do not consider it to be an error.
-		if !apierrors.IsAlreadyExists(err) {
-			return errors.Wrapf(
-				err,
-				"failed to create object %s %s/%s",
-				obj.GroupVersionKind(),
-				obj.GetNamespace(),
-				obj.GetName())
-		}
-	}
-	return nil
-}
+// kubernetesVersionRecheckInterval is the interval at which Clusters not matching the Kubernetes version range of a
+// ClusterResourceSet are checked again, given that upgrading a workload cluster doesn't necessarily change the Cluster object.
+const kubernetesVersionRecheckInterval = 5 * time.Minute
 
 // getOrCreateClusterResourceSetBinding retrieves ClusterResourceSetBinding resource owned by the cluster or create a new one if not found.
 func (r *ClusterResourceSetReconciler) getOrCreateClusterResourceSetBinding(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) (*addonsv1.ClusterResourceSetBinding, error) {
@@ -183,3 +116,41 @@ func computeHash(dataArr [][]byte) string {
 	}
 	return fmt.Sprintf("sha256:%x", hash.Sum(nil))
 }
+
+// matchesClusterName returns true if the name of the cluster matches the name prefix of the ClusterResourceSet's field selector, if any.
+func matchesClusterName(clusterResourceSet *addonsv1.ClusterResourceSet, cluster *clusterv1.Cluster) bool {
+	fieldSelector := clusterResourceSet.Spec.ClusterFieldSelector
+	return fieldSelector == nil || strings.HasPrefix(cluster.Name, fieldSelector.NamePrefix)
+}
+
+// matchesKubernetesVersion returns true if the Kubernetes version of the workload cluster is in the range of the
+// ClusterResourceSet's field selector, if any. Clusters whose control plane is not initialized yet don't match.
+func (r *ClusterResourceSetReconciler) matchesKubernetesVersion(ctx context.Context, cluster *clusterv1.Cluster, clusterResourceSet *addonsv1.ClusterResourceSet) (bool, error) {
+	fieldSelector := clusterResourceSet.Spec.ClusterFieldSelector
+	if fieldSelector == nil || fieldSelector.KubernetesVersion == "" {
+		return true, nil
+	}
+	if !cluster.Status.ControlPlaneInitialized {
+		return false, nil
+	}
+
+	info, err := r.Tracker.GetClusterInfo(ctx, util.ObjectKey(cluster))
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get the Kubernetes version of cluster %s/%s", cluster.Namespace, cluster.Name)
+	}
+	return kubernetesVersionInRange(info.Version.GitVersion, fieldSelector.KubernetesVersion)
+}
+
+// kubernetesVersionInRange returns true if the given Kubernetes version is in the given semver range;
+// pre-release and build metadata of the version are ignored.
+func kubernetesVersionInRange(kubernetesVersion, versionRange string) (bool, error) {
+	r, err := semver.ParseRange(versionRange)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid Kubernetes version range %q", versionRange)
+	}
+	v, err := version.ParseMajorMinorPatchTolerant(kubernetesVersion)
+	if err != nil {
+		return false, errors.Wrapf(err, "invalid Kubernetes version %q", kubernetesVersion)
+	}
+	return r(v), nil
+}
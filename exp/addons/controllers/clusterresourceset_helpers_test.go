@@ -227,3 +227,77 @@ func TestGetConfigMapFromNamespacedName(t *testing.T) {
 		})
 	}
 }
+
+func TestMatchesClusterName(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "dev-cluster", Namespace: "default"}}
+
+	g.Expect(matchesClusterName(&addonsv1.ClusterResourceSet{}, cluster)).To(BeTrue())
+	g.Expect(matchesClusterName(&addonsv1.ClusterResourceSet{Spec: addonsv1.ClusterResourceSetSpec{
+		ClusterFieldSelector: &addonsv1.ClusterFieldSelector{NamePrefix: "dev-"},
+	}}, cluster)).To(BeTrue())
+	g.Expect(matchesClusterName(&addonsv1.ClusterResourceSet{Spec: addonsv1.ClusterResourceSetSpec{
+		ClusterFieldSelector: &addonsv1.ClusterFieldSelector{NamePrefix: "prod-"},
+	}}, cluster)).To(BeFalse())
+}
+
+func TestKubernetesVersionInRange(t *testing.T) {
+	tests := []struct {
+		name              string
+		kubernetesVersion string
+		versionRange      string
+		want              bool
+		wantErr           bool
+	}{
+		{
+			name:              "version in range",
+			kubernetesVersion: "v1.23.1",
+			versionRange:      ">=1.23.0",
+			want:              true,
+		},
+		{
+			name:              "version out of range",
+			kubernetesVersion: "v1.22.5",
+			versionRange:      ">=1.23.0",
+			want:              false,
+		},
+		{
+			name:              "pre-release and build metadata are ignored",
+			kubernetesVersion: "v1.23.0-rc.1+a1b2c3",
+			versionRange:      ">=1.23.0",
+			want:              true,
+		},
+		{
+			name:              "bounded range",
+			kubernetesVersion: "v1.23.0",
+			versionRange:      ">=1.21.0 <1.23.0",
+			want:              false,
+		},
+		{
+			name:              "invalid range",
+			kubernetesVersion: "v1.23.0",
+			versionRange:      "1.23+",
+			wantErr:           true,
+		},
+		{
+			name:              "invalid version",
+			kubernetesVersion: "latest",
+			versionRange:      ">=1.23.0",
+			wantErr:           true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := kubernetesVersionInRange(tt.kubernetesVersion, tt.versionRange)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
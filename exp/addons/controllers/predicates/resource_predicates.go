@@ -35,6 +35,8 @@ func ResourceCreate(logger logr.Logger) predicate.Funcs {
 }
 
 // AddonsSecretCreate returns a predicate that returns true for a Secret create event if in addons Secret type
+// NOTE: the Secret type is not part of the object metadata, so the predicate filters out all the events of Secrets
+// watched as metadata only, e.g. with builder.OnlyMetadata.
 func AddonsSecretCreate(logger logr.Logger) predicate.Funcs {
 	log := logger.WithValues("predicate", "SecretCreateOrUpdate")
 
@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	"sigs.k8s.io/cluster-api/controllers/remote"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/test/helpers"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -49,6 +50,8 @@ func TestAPIs(t *testing.T) {
 
 var _ = BeforeSuite(func() {
 	By("bootstrapping test environment")
+	// The controllers ignore the requests while their feature gate is disabled.
+	Expect(feature.MutableGates.SetFromMap(map[string]bool{string(feature.ClusterResourceSet): true})).To(Succeed())
 	testEnv = helpers.NewTestEnvironment()
 	trckr, err := remote.NewClusterCacheTracker(log.NullLogger{}, testEnv.Manager)
 	Expect(err).NotTo(HaveOccurred())
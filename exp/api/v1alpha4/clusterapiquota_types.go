@@ -0,0 +1,80 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ANCHOR: ClusterAPIQuotaSpec
+
+// ClusterAPIQuotaSpec defines the limits enforced in the namespace of the ClusterAPIQuota.
+// Limits that are not set are not enforced.
+type ClusterAPIQuotaSpec struct {
+	// MaxClusters is the maximum number of Clusters in the namespace.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxClusters *int32 `json:"maxClusters,omitempty"`
+
+	// MaxMachineDeployments is the maximum number of MachineDeployments in the namespace.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxMachineDeployments *int32 `json:"maxMachineDeployments,omitempty"`
+
+	// MaxMachines is the maximum number of Machines in the namespace.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxMachines *int32 `json:"maxMachines,omitempty"`
+
+	// MaxMachinesPerCluster is the maximum number of Machines of each Cluster in the namespace.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxMachinesPerCluster *int32 `json:"maxMachinesPerCluster,omitempty"`
+}
+
+// ANCHOR_END: ClusterAPIQuotaSpec
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clusterapiquotas,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Max Clusters",type="integer",JSONPath=".spec.maxClusters",description="Maximum number of Clusters in the namespace"
+// +kubebuilder:printcolumn:name="Max MachineDeployments",type="integer",JSONPath=".spec.maxMachineDeployments",description="Maximum number of MachineDeployments in the namespace"
+// +kubebuilder:printcolumn:name="Max Machines",type="integer",JSONPath=".spec.maxMachines",description="Maximum number of Machines in the namespace"
+// +k8s:conversion-gen=false
+
+// ClusterAPIQuota is the Schema for the clusterapiquotas API.
+// It limits the number of Cluster API objects that can be created in its namespace;
+// limits are enforced by an admission webhook when the objects are created.
+type ClusterAPIQuota struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterAPIQuotaSpec `json:"spec,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterAPIQuotaList contains a list of ClusterAPIQuota.
+type ClusterAPIQuotaList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterAPIQuota `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterAPIQuota{}, &ClusterAPIQuotaList{})
+}
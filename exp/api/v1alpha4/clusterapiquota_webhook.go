@@ -0,0 +1,171 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/pkg/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+const clusterAPIQuotaWebhookPath = "/validate-cluster-x-k8s-io-v1alpha4-clusterapiquota"
+
+// SetupWebhookWithManager registers the webhook enforcing the ClusterAPIQuotas when Clusters, MachineDeployments
+// and Machines are created.
+func (q *ClusterAPIQuota) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register(clusterAPIQuotaWebhookPath, &webhook.Admission{
+		Handler: &ClusterAPIQuotaEnforcer{Client: mgr.GetClient()},
+	})
+	return nil
+}
+
+// The webhook ignores failures, given that it is served only when the ClusterAPIQuota feature gate is enabled, and
+// it must not block the creation of Clusters, MachineDeployments and Machines otherwise.
+// +kubebuilder:webhook:verbs=create,path=/validate-cluster-x-k8s-io-v1alpha4-clusterapiquota,mutating=false,failurePolicy=ignore,matchPolicy=Equivalent,groups=cluster.x-k8s.io,resources=clusters;machinedeployments;machines,versions=v1alpha4,name=quota.exp.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
+
+// ClusterAPIQuotaEnforcer is an admission handler rejecting the creation of Clusters, MachineDeployments and Machines
+// exceeding the limits of any ClusterAPIQuota in their namespace. Objects being deleted are not counted.
+// Objects are counted from the cache of the manager, which doesn't include the objects admitted but not observed
+// yet, so a burst of creations can exceed a limit.
+// +kubebuilder:object:generate=false
+type ClusterAPIQuotaEnforcer struct {
+	Client client.Reader
+}
+
+var _ admission.Handler = &ClusterAPIQuotaEnforcer{}
+
+// Handle implements admission.Handler.
+func (e *ClusterAPIQuotaEnforcer) Handle(ctx context.Context, req admission.Request) admission.Response {
+	quotas := &ClusterAPIQuotaList{}
+	if err := e.Client.List(ctx, quotas, client.InNamespace(req.Namespace)); err != nil {
+		return admission.Errored(http.StatusInternalServerError, errors.Wrap(err, "failed to list ClusterAPIQuotas"))
+	}
+	if len(quotas.Items) == 0 {
+		return admission.Allowed("")
+	}
+
+	var err error
+	switch req.Kind.Kind {
+	case "Cluster":
+		err = e.checkClusters(ctx, req.Namespace, quotas.Items)
+	case "MachineDeployment":
+		err = e.checkMachineDeployments(ctx, req.Namespace, quotas.Items)
+	case "Machine":
+		machine := &clusterv1.Machine{}
+		if err := json.Unmarshal(req.Object.Raw, machine); err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		err = e.checkMachines(ctx, req.Namespace, machine.Spec.ClusterName, quotas.Items)
+	default:
+		return admission.Allowed("")
+	}
+	if err != nil {
+		if exceeded, ok := err.(*quotaExceededError); ok {
+			return admission.Denied(exceeded.Error())
+		}
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	return admission.Allowed("")
+}
+
+// quotaExceededError is returned when the creation of an object would exceed a limit of a ClusterAPIQuota.
+// +kubebuilder:object:generate=false
+type quotaExceededError struct {
+	quota string
+	kind  string
+	limit int32
+	scope string
+}
+
+func (e *quotaExceededError) Error() string {
+	return fmt.Sprintf("exceeded ClusterAPIQuota %s: the maximum number of %s %s is %d", e.quota, e.kind, e.scope, e.limit)
+}
+
+func (e *ClusterAPIQuotaEnforcer) checkClusters(ctx context.Context, namespace string, quotas []ClusterAPIQuota) error {
+	clusters := &clusterv1.ClusterList{}
+	if err := e.Client.List(ctx, clusters, client.InNamespace(namespace)); err != nil {
+		return errors.Wrap(err, "failed to list Clusters")
+	}
+	count := 0
+	for i := range clusters.Items {
+		if clusters.Items[i].DeletionTimestamp.IsZero() {
+			count++
+		}
+	}
+
+	for _, q := range quotas {
+		if q.Spec.MaxClusters != nil && count >= int(*q.Spec.MaxClusters) {
+			return &quotaExceededError{quota: q.Name, kind: "Clusters", scope: "in the namespace", limit: *q.Spec.MaxClusters}
+		}
+	}
+	return nil
+}
+
+func (e *ClusterAPIQuotaEnforcer) checkMachineDeployments(ctx context.Context, namespace string, quotas []ClusterAPIQuota) error {
+	machineDeployments := &clusterv1.MachineDeploymentList{}
+	if err := e.Client.List(ctx, machineDeployments, client.InNamespace(namespace)); err != nil {
+		return errors.Wrap(err, "failed to list MachineDeployments")
+	}
+	count := 0
+	for i := range machineDeployments.Items {
+		if machineDeployments.Items[i].DeletionTimestamp.IsZero() {
+			count++
+		}
+	}
+
+	for _, q := range quotas {
+		if q.Spec.MaxMachineDeployments != nil && count >= int(*q.Spec.MaxMachineDeployments) {
+			return &quotaExceededError{quota: q.Name, kind: "MachineDeployments", scope: "in the namespace", limit: *q.Spec.MaxMachineDeployments}
+		}
+	}
+	return nil
+}
+
+func (e *ClusterAPIQuotaEnforcer) checkMachines(ctx context.Context, namespace, clusterName string, quotas []ClusterAPIQuota) error {
+	machines := &clusterv1.MachineList{}
+	if err := e.Client.List(ctx, machines, client.InNamespace(namespace)); err != nil {
+		return errors.Wrap(err, "failed to list Machines")
+	}
+	count, clusterCount := 0, 0
+	for i := range machines.Items {
+		if !machines.Items[i].DeletionTimestamp.IsZero() {
+			continue
+		}
+		count++
+		if machines.Items[i].Spec.ClusterName == clusterName {
+			clusterCount++
+		}
+	}
+
+	for _, q := range quotas {
+		if q.Spec.MaxMachines != nil && count >= int(*q.Spec.MaxMachines) {
+			return &quotaExceededError{quota: q.Name, kind: "Machines", scope: "in the namespace", limit: *q.Spec.MaxMachines}
+		}
+		if q.Spec.MaxMachinesPerCluster != nil && clusterCount >= int(*q.Spec.MaxMachinesPerCluster) {
+			return &quotaExceededError{quota: q.Name, kind: "Machines", scope: fmt.Sprintf("of Cluster %s", clusterName), limit: *q.Spec.MaxMachinesPerCluster}
+		}
+	}
+	return nil
+}
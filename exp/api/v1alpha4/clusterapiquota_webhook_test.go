@@ -0,0 +1,152 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestClusterAPIQuotaEnforcer(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	g.Expect(AddToScheme(scheme)).To(Succeed())
+
+	quota := func(name string, spec ClusterAPIQuotaSpec) client.Object {
+		return &ClusterAPIQuota{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1"}, Spec: spec}
+	}
+	machine := func(name, clusterName string) client.Object {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns1"},
+			Spec:       clusterv1.MachineSpec{ClusterName: clusterName},
+		}
+	}
+	deletingMachine := machine("deleting", "cluster1")
+	now := metav1.Now()
+	deletingMachine.SetDeletionTimestamp(&now)
+	deletingMachine.SetFinalizers([]string{"test"})
+
+	existing := []client.Object{
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "cluster1", Namespace: "ns1"}},
+		&clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "md1", Namespace: "ns1"}},
+		machine("m1", "cluster1"),
+		machine("m2", "cluster1"),
+		machine("m3", "cluster2"),
+		deletingMachine,
+	}
+
+	tests := []struct {
+		name    string
+		quotas  []client.Object
+		kind    string
+		obj     runtime.Object
+		allowed bool
+	}{
+		{
+			name:    "no quotas",
+			kind:    "Machine",
+			obj:     machine("new", "cluster1"),
+			allowed: true,
+		},
+		{
+			name:    "Cluster within quota",
+			quotas:  []client.Object{quota("q1", ClusterAPIQuotaSpec{MaxClusters: pointer.Int32Ptr(2)})},
+			kind:    "Cluster",
+			obj:     &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "ns1"}},
+			allowed: true,
+		},
+		{
+			name:   "Cluster exceeding quota",
+			quotas: []client.Object{quota("q1", ClusterAPIQuotaSpec{MaxClusters: pointer.Int32Ptr(1)})},
+			kind:   "Cluster",
+			obj:    &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "ns1"}},
+		},
+		{
+			name:   "MachineDeployment exceeding quota",
+			quotas: []client.Object{quota("q1", ClusterAPIQuotaSpec{MaxMachineDeployments: pointer.Int32Ptr(1)})},
+			kind:   "MachineDeployment",
+			obj:    &clusterv1.MachineDeployment{ObjectMeta: metav1.ObjectMeta{Name: "new", Namespace: "ns1"}},
+		},
+		{
+			name:    "Machine within quota, Machines being deleted are not counted",
+			quotas:  []client.Object{quota("q1", ClusterAPIQuotaSpec{MaxMachines: pointer.Int32Ptr(4)})},
+			kind:    "Machine",
+			obj:     machine("new", "cluster1"),
+			allowed: true,
+		},
+		{
+			name:   "Machine exceeding namespace quota",
+			quotas: []client.Object{quota("q1", ClusterAPIQuotaSpec{MaxMachines: pointer.Int32Ptr(3)})},
+			kind:   "Machine",
+			obj:    machine("new", "cluster2"),
+		},
+		{
+			name:    "Machine within per cluster quota",
+			quotas:  []client.Object{quota("q1", ClusterAPIQuotaSpec{MaxMachinesPerCluster: pointer.Int32Ptr(2)})},
+			kind:    "Machine",
+			obj:     machine("new", "cluster2"),
+			allowed: true,
+		},
+		{
+			name:   "Machine exceeding per cluster quota",
+			quotas: []client.Object{quota("q1", ClusterAPIQuotaSpec{MaxMachinesPerCluster: pointer.Int32Ptr(2)})},
+			kind:   "Machine",
+			obj:    machine("new", "cluster1"),
+		},
+		{
+			name: "all quotas are enforced",
+			quotas: []client.Object{
+				quota("q1", ClusterAPIQuotaSpec{MaxMachines: pointer.Int32Ptr(10)}),
+				quota("q2", ClusterAPIQuotaSpec{MaxMachines: pointer.Int32Ptr(3)}),
+			},
+			kind: "Machine",
+			obj:  machine("new", "cluster2"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs := append(append([]client.Object{}, existing...), tt.quotas...)
+			enforcer := &ClusterAPIQuotaEnforcer{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()}
+
+			raw, err := json.Marshal(tt.obj)
+			g.Expect(err).NotTo(HaveOccurred())
+			resp := enforcer.Handle(context.Background(), admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+				Kind:      metav1.GroupVersionKind{Group: clusterv1.GroupVersion.Group, Version: clusterv1.GroupVersion.Version, Kind: tt.kind},
+				Namespace: "ns1",
+				Operation: admissionv1.Create,
+				Object:    runtime.RawExtension{Raw: raw},
+			}})
+			g.Expect(resp.Allowed).To(Equal(tt.allowed), resp.Result.String())
+		})
+	}
+}
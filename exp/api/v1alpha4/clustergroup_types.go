@@ -0,0 +1,192 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterGroupOperationType is the type of a batch operation executed on the Clusters of a ClusterGroup.
+type ClusterGroupOperationType string
+
+const (
+	// ClusterGroupOperationPause pauses the reconciliation of the Clusters by setting spec.paused.
+	ClusterGroupOperationPause = ClusterGroupOperationType("Pause")
+
+	// ClusterGroupOperationResume resumes the reconciliation of the Clusters by unsetting spec.paused.
+	ClusterGroupOperationResume = ClusterGroupOperationType("Resume")
+
+	// ClusterGroupOperationSetVersion sets the Kubernetes version of the control plane of the Clusters, as defined
+	// by the spec.version field of the control plane contract, and waits for the control plane Machines to be upgraded.
+	ClusterGroupOperationSetVersion = ClusterGroupOperationType("SetVersion")
+)
+
+// ANCHOR: ClusterGroupSpec
+
+// ClusterGroupSpec defines the Clusters of a ClusterGroup and the batch operation executed on them.
+type ClusterGroupSpec struct {
+	// ClusterSelector selects the Clusters, in the namespace of the ClusterGroup, which belong to the group.
+	// It must not be empty.
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector"`
+
+	// Operation is the batch operation executed on the Clusters of the group. Changing the operation starts
+	// a new execution on all the Clusters; removing it stops the execution.
+	// +optional
+	Operation *ClusterGroupOperation `json:"operation,omitempty"`
+}
+
+// ClusterGroupOperation is a batch operation executed on the Clusters of a ClusterGroup.
+type ClusterGroupOperation struct {
+	// Type of the operation.
+	// +kubebuilder:validation:Enum=Pause;Resume;SetVersion
+	Type ClusterGroupOperationType `json:"type"`
+
+	// Version is the Kubernetes version set by a SetVersion operation.
+	// +optional
+	Version string `json:"version,omitempty"`
+
+	// MaxConcurrency is the maximum number of Clusters the operation is in progress on at the same time.
+	// Clusters are processed in the order of their names.
+	// Defaults to 1.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrency *int32 `json:"maxConcurrency,omitempty"`
+
+	// MaxFailures is the maximum number of Clusters the operation can fail on; once it is exceeded, the operation
+	// is not started on any other Cluster.
+	// Defaults to 0.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MaxFailures *int32 `json:"maxFailures,omitempty"`
+}
+
+// ANCHOR_END: ClusterGroupSpec
+
+// ANCHOR: ClusterGroupStatus
+
+// ClusterGroupPhase is the phase of the execution of the operation of a ClusterGroup.
+type ClusterGroupPhase string
+
+const (
+	// ClusterGroupPhaseProgressing indicates the operation is being executed.
+	ClusterGroupPhaseProgressing = ClusterGroupPhase("Progressing")
+
+	// ClusterGroupPhaseCompleted indicates the operation has been executed on all the Clusters.
+	ClusterGroupPhaseCompleted = ClusterGroupPhase("Completed")
+
+	// ClusterGroupPhaseFailed indicates the operation has failed on more Clusters than allowed by MaxFailures.
+	ClusterGroupPhaseFailed = ClusterGroupPhase("Failed")
+)
+
+// ClusterGroupClusterPhase is the phase of the operation of a ClusterGroup on one of its Clusters.
+type ClusterGroupClusterPhase string
+
+const (
+	// ClusterGroupClusterPhasePending indicates the operation has not been started on the Cluster yet.
+	ClusterGroupClusterPhasePending = ClusterGroupClusterPhase("Pending")
+
+	// ClusterGroupClusterPhaseInProgress indicates the operation has been started on the Cluster, and it hasn't completed yet.
+	ClusterGroupClusterPhaseInProgress = ClusterGroupClusterPhase("InProgress")
+
+	// ClusterGroupClusterPhaseSucceeded indicates the operation has completed on the Cluster.
+	ClusterGroupClusterPhaseSucceeded = ClusterGroupClusterPhase("Succeeded")
+
+	// ClusterGroupClusterPhaseFailed indicates the operation has failed on the Cluster.
+	ClusterGroupClusterPhaseFailed = ClusterGroupClusterPhase("Failed")
+)
+
+// ClusterGroupStatus defines the observed state of a ClusterGroup.
+type ClusterGroupStatus struct {
+	// Clusters is the number of Clusters selected by the ClusterGroup.
+	// +optional
+	Clusters int32 `json:"clusters"`
+
+	// Operation is the operation being executed, as observed when its execution started.
+	// +optional
+	Operation *ClusterGroupOperation `json:"operation,omitempty"`
+
+	// Phase is the phase of the execution of the operation.
+	// +optional
+	Phase ClusterGroupPhase `json:"phase,omitempty"`
+
+	// SucceededClusters is the number of Clusters the operation has completed on.
+	// +optional
+	SucceededClusters int32 `json:"succeededClusters"`
+
+	// FailedClusters is the number of Clusters the operation has failed on.
+	// +optional
+	FailedClusters int32 `json:"failedClusters"`
+
+	// ClusterStatuses reports the progress of the operation on each of the selected Clusters, sorted by name.
+	// +optional
+	ClusterStatuses []ClusterGroupClusterStatus `json:"clusterStatuses,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// ClusterGroupClusterStatus is the progress of the operation of a ClusterGroup on one of its Clusters.
+type ClusterGroupClusterStatus struct {
+	// Name of the Cluster.
+	Name string `json:"name"`
+
+	// Phase of the operation on the Cluster.
+	Phase ClusterGroupClusterPhase `json:"phase"`
+
+	// Message describes why the operation is in progress or has failed, if any.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// ANCHOR_END: ClusterGroupStatus
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=clustergroups,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Clusters",type="integer",JSONPath=".status.clusters",description="Number of selected Clusters"
+// +kubebuilder:printcolumn:name="Operation",type="string",JSONPath=".status.operation.type",description="Operation being executed"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Phase of the execution of the operation"
+// +kubebuilder:printcolumn:name="Succeeded",type="integer",JSONPath=".status.succeededClusters",description="Number of Clusters the operation has completed on"
+// +kubebuilder:printcolumn:name="Failed",type="integer",JSONPath=".status.failedClusters",description="Number of Clusters the operation has failed on"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of ClusterGroup"
+// +k8s:conversion-gen=false
+
+// ClusterGroup is the Schema for the clustergroups API.
+// It executes batch operations, e.g. upgrading the control planes, on the Clusters matching a selector,
+// with a bounded concurrency and failure threshold.
+type ClusterGroup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ClusterGroupSpec   `json:"spec,omitempty"`
+	Status ClusterGroupStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// ClusterGroupList contains a list of ClusterGroup.
+type ClusterGroupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ClusterGroup `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&ClusterGroup{}, &ClusterGroupList{})
+}
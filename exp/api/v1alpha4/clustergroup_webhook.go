@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"sigs.k8s.io/cluster-api/util/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+func (g *ClusterGroup) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(g).
+		Complete()
+}
+
+// +kubebuilder:webhook:verbs=create;update,path=/validate-exp-cluster-x-k8s-io-v1alpha4-clustergroup,mutating=false,failurePolicy=fail,matchPolicy=Equivalent,groups=exp.cluster.x-k8s.io,resources=clustergroups,versions=v1alpha4,name=validation.exp.clustergroup.cluster.x-k8s.io,sideEffects=None,admissionReviewVersions=v1beta1
+
+var _ webhook.Validator = &ClusterGroup{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (g *ClusterGroup) ValidateCreate() error {
+	return g.validate()
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type
+func (g *ClusterGroup) ValidateUpdate(old runtime.Object) error {
+	return g.validate()
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (g *ClusterGroup) ValidateDelete() error {
+	return nil
+}
+
+func (g *ClusterGroup) validate() error {
+	var allErrs field.ErrorList
+	selector, err := metav1.LabelSelectorAsSelector(&g.Spec.ClusterSelector)
+	if err != nil {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "clusterSelector"), g.Spec.ClusterSelector, err.Error()),
+		)
+	}
+
+	// Validate that the selector isn't empty, given that it would select all the Clusters in the namespace.
+	if selector != nil && selector.Empty() {
+		allErrs = append(
+			allErrs,
+			field.Invalid(field.NewPath("spec", "clusterSelector"), g.Spec.ClusterSelector, "selector must not be empty"),
+		)
+	}
+
+	if operation := g.Spec.Operation; operation != nil {
+		fldPath := field.NewPath("spec", "operation", "version")
+		switch {
+		case operation.Type == ClusterGroupOperationSetVersion && operation.Version == "":
+			allErrs = append(allErrs, field.Required(fldPath, "must be set for SetVersion operations"))
+		case operation.Type == ClusterGroupOperationSetVersion:
+			if _, err := version.ParseMajorMinorPatch(operation.Version); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath, operation.Version, "must be a valid semantic version, e.g. v1.20.2"))
+			}
+		case operation.Version != "":
+			allErrs = append(allErrs, field.Forbidden(fldPath, "must be set only for SetVersion operations"))
+		}
+	}
+
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("ClusterGroup").GroupKind(), g.Name, allErrs)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestClusterGroupValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		selector  *metav1.LabelSelector
+		operation *ClusterGroupOperation
+		expectErr bool
+	}{
+		{
+			name:      "should not return error without operation",
+			expectErr: false,
+		},
+		{
+			name: "should return error for invalid selector",
+			selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "env", Operator: "Maybe"}},
+			},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for empty selector",
+			selector:  &metav1.LabelSelector{},
+			expectErr: true,
+		},
+		{
+			name:      "should not return error for pause",
+			operation: &ClusterGroupOperation{Type: ClusterGroupOperationPause},
+			expectErr: false,
+		},
+		{
+			name:      "should return error for pause with a version",
+			operation: &ClusterGroupOperation{Type: ClusterGroupOperationPause, Version: "v1.20.2"},
+			expectErr: true,
+		},
+		{
+			name:      "should not return error for set version",
+			operation: &ClusterGroupOperation{Type: ClusterGroupOperationSetVersion, Version: "v1.20.2"},
+			expectErr: false,
+		},
+		{
+			name:      "should return error for set version without a version",
+			operation: &ClusterGroupOperation{Type: ClusterGroupOperationSetVersion},
+			expectErr: true,
+		},
+		{
+			name:      "should return error for set version with an invalid version",
+			operation: &ClusterGroupOperation{Type: ClusterGroupOperationSetVersion, Version: "1.20"},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			selector := metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}
+			if tt.selector != nil {
+				selector = *tt.selector
+			}
+			clusterGroup := &ClusterGroup{
+				Spec: ClusterGroupSpec{
+					ClusterSelector: selector,
+					Operation:       tt.operation,
+				},
+			}
+			if tt.expectErr {
+				g.Expect(clusterGroup.ValidateCreate()).NotTo(Succeed())
+				g.Expect(clusterGroup.ValidateUpdate(clusterGroup)).NotTo(Succeed())
+			} else {
+				g.Expect(clusterGroup.ValidateCreate()).To(Succeed())
+				g.Expect(clusterGroup.ValidateUpdate(clusterGroup)).To(Succeed())
+			}
+		})
+	}
+}
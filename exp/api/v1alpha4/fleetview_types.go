@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha4
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ANCHOR: FleetViewSpec
+
+// FleetViewSpec defines the Clusters aggregated by a FleetView.
+type FleetViewSpec struct {
+	// ClusterSelector selects the Clusters, in the namespace of the FleetView, whose status is aggregated.
+	// An empty selector selects all the Clusters in the namespace.
+	// +optional
+	ClusterSelector metav1.LabelSelector `json:"clusterSelector,omitempty"`
+}
+
+// ANCHOR_END: FleetViewSpec
+
+// ANCHOR: FleetViewStatus
+
+// FleetViewStatus defines the aggregated status of the Clusters selected by a FleetView.
+type FleetViewStatus struct {
+	// Clusters is the number of Clusters selected by the FleetView.
+	// +optional
+	Clusters int32 `json:"clusters"`
+
+	// ReadyClusters is the number of selected Clusters with the Ready condition set to true.
+	// +optional
+	ReadyClusters int32 `json:"readyClusters"`
+
+	// UpgradingClusters is the number of selected Clusters whose Machines are not all at the Kubernetes
+	// version of the control plane.
+	// +optional
+	UpgradingClusters int32 `json:"upgradingClusters"`
+
+	// FailedClusters lists the selected Clusters which are failed, either because of a terminal failure
+	// or because of a Ready condition set to false with severity Error.
+	// +optional
+	FailedClusters []FleetViewFailedCluster `json:"failedClusters,omitempty"`
+
+	// Versions is the distribution of the Kubernetes versions of the control planes of the selected Clusters,
+	// sorted by version. Clusters without a control plane, or whose control plane doesn't report a version, are not counted.
+	// +optional
+	Versions []FleetViewVersion `json:"versions,omitempty"`
+
+	// ObservedGeneration is the latest generation observed by the controller.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+}
+
+// FleetViewFailedCluster is a failed Cluster, as reported by a FleetView.
+type FleetViewFailedCluster struct {
+	// Name of the Cluster.
+	Name string `json:"name"`
+
+	// Reason of the failure.
+	Reason string `json:"reason"`
+
+	// Message describing the failure.
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// FleetViewVersion is the number of Clusters with a given Kubernetes version, as reported by a FleetView.
+type FleetViewVersion struct {
+	// Version is the Kubernetes version.
+	Version string `json:"version"`
+
+	// Clusters is the number of Clusters with the version.
+	Clusters int32 `json:"clusters"`
+}
+
+// ANCHOR_END: FleetViewStatus
+
+// +kubebuilder:object:root=true
+// +kubebuilder:resource:path=fleetviews,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Clusters",type="integer",JSONPath=".status.clusters",description="Number of selected Clusters"
+// +kubebuilder:printcolumn:name="Ready",type="integer",JSONPath=".status.readyClusters",description="Number of selected Clusters which are ready"
+// +kubebuilder:printcolumn:name="Upgrading",type="integer",JSONPath=".status.upgradingClusters",description="Number of selected Clusters which are upgrading"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time duration since creation of FleetView"
+// +k8s:conversion-gen=false
+
+// FleetView is the Schema for the fleetviews API.
+// It aggregates the status of the Clusters matching a selector, so that fleet operators can watch a single object
+// instead of every Cluster.
+type FleetView struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   FleetViewSpec   `json:"spec,omitempty"`
+	Status FleetViewStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// FleetViewList contains a list of FleetView.
+type FleetViewList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []FleetView `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&FleetView{}, &FleetViewList{})
+}
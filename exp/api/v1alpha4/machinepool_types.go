@@ -56,6 +56,7 @@ type MachinePoolSpec struct {
 	ProviderIDList []string `json:"providerIDList,omitempty"`
 
 	// FailureDomains is the list of failure domains this MachinePool should be attached to.
+	// Infrastructure providers are expected to spread the instances of the MachinePool across these failure domains.
 	FailureDomains []string `json:"failureDomains,omitempty"`
 }
 
@@ -119,6 +120,45 @@ type MachinePoolStatus struct {
 	// Conditions define the current service state of the MachinePool.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+
+	// BootstrapDataHash is the hash of the bootstrap data instances of the MachinePool are currently created with.
+	// Changes to the bootstrap data don't require replacing the existing instances: infrastructure providers
+	// use the new bootstrap data only for the instances created afterwards.
+	// +optional
+	BootstrapDataHash string `json:"bootstrapDataHash,omitempty"`
+
+	// InstanceBootstrapData reports the hash of the bootstrap data each instance of the MachinePool has been
+	// created with, as reported by the infrastructure provider.
+	// +optional
+	InstanceBootstrapData []InstanceBootstrapData `json:"instanceBootstrapData,omitempty"`
+
+	// UpToDateBootstrapDataReplicas is the number of instances created with the current bootstrap data,
+	// as reported by the infrastructure provider.
+	// +optional
+	UpToDateBootstrapDataReplicas int32 `json:"upToDateBootstrapDataReplicas,omitempty"`
+
+	// FailureDomains reports the number of instances of the MachinePool running in each failure domain,
+	// as reported by the infrastructure provider.
+	// +optional
+	FailureDomains []FailureDomainReplicas `json:"failureDomains,omitempty"`
+}
+
+// InstanceBootstrapData documents the bootstrap data an instance of a MachinePool has been created with.
+type InstanceBootstrapData struct {
+	// ProviderID of the instance.
+	ProviderID string `json:"providerID"`
+
+	// BootstrapDataHash is the hash of the bootstrap data the instance has been created with.
+	BootstrapDataHash string `json:"bootstrapDataHash"`
+}
+
+// FailureDomainReplicas documents the number of instances of a MachinePool running in a failure domain.
+type FailureDomainReplicas struct {
+	// Name of the failure domain.
+	Name string `json:"name"`
+
+	// Replicas is the number of instances running in the failure domain.
+	Replicas int32 `json:"replicas"`
 }
 
 // ANCHOR_END: MachinePoolStatus
@@ -0,0 +1,390 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch;update;patch
+// +kubebuilder:rbac:groups=exp.cluster.x-k8s.io,resources=clustergroups;clustergroups/status,verbs=get;list;watch;update;patch
+
+// ClusterGroupReconciler reconciles a ClusterGroup object.
+type ClusterGroupReconciler struct {
+	Client           client.Client
+	WatchFilterValue string
+}
+
+func (r *ClusterGroupReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&expv1.ClusterGroup{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	if err := c.Watch(
+		&source.Kind{Type: &clusterv1.Cluster{}},
+		handler.EnqueueRequestsFromMapFunc(r.clusterToClusterGroups),
+	); err != nil {
+		return errors.Wrap(err, "failed adding Watch for Clusters to controller manager")
+	}
+
+	// Machines are watched for detecting the control planes which have been upgraded.
+	if err := c.Watch(
+		&source.Kind{Type: &clusterv1.Machine{}},
+		handler.EnqueueRequestsFromMapFunc(r.machineToClusterGroups),
+	); err != nil {
+		return errors.Wrap(err, "failed adding Watch for Machines to controller manager")
+	}
+	return nil
+}
+
+func (r *ClusterGroupReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	clusterGroup := &expv1.ClusterGroup{}
+	if err := r.Client.Get(ctx, req.NamespacedName, clusterGroup); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	patchHelper, err := patch.NewHelper(clusterGroup, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Patch ObservedGeneration only if the reconciliation completed successfully.
+		patchOpts := []patch.Option{}
+		if reterr == nil {
+			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
+		}
+		if err := patchHelper.Patch(ctx, clusterGroup, patchOpts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	if !clusterGroup.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{}, r.reconcile(ctx, clusterGroup)
+}
+
+func (r *ClusterGroupReconciler) reconcile(ctx context.Context, clusterGroup *expv1.ClusterGroup) error {
+	selector, err := metav1.LabelSelectorAsSelector(&clusterGroup.Spec.ClusterSelector)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the cluster selector of ClusterGroup %s", clusterGroup.Name)
+	}
+
+	clusters := &clusterv1.ClusterList{}
+	if err := r.Client.List(ctx, clusters, client.InNamespace(clusterGroup.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return errors.Wrapf(err, "failed to list Clusters for ClusterGroup %s", clusterGroup.Name)
+	}
+	sort.Slice(clusters.Items, func(i, j int) bool {
+		return clusters.Items[i].Name < clusters.Items[j].Name
+	})
+
+	status := &clusterGroup.Status
+	status.Clusters = int32(len(clusters.Items))
+
+	operation := clusterGroup.Spec.Operation
+	if operation == nil {
+		status.Operation = nil
+		status.Phase = ""
+		status.SucceededClusters = 0
+		status.FailedClusters = 0
+		status.ClusterStatuses = nil
+		return nil
+	}
+
+	// A new operation starts from scratch on all the Clusters.
+	previous := map[string]expv1.ClusterGroupClusterStatus{}
+	if reflect.DeepEqual(status.Operation, operation) {
+		for _, s := range status.ClusterStatuses {
+			previous[s.Name] = s
+		}
+	}
+	status.Operation = operation.DeepCopy()
+
+	maxConcurrency, maxFailures := int32(1), int32(0)
+	if operation.MaxConcurrency != nil {
+		maxConcurrency = *operation.MaxConcurrency
+	}
+	if operation.MaxFailures != nil {
+		maxFailures = *operation.MaxFailures
+	}
+
+	// Clusters which are no longer selected are dropped, Clusters which are newly selected are pending.
+	clusterStatuses := make([]expv1.ClusterGroupClusterStatus, len(clusters.Items))
+	for i := range clusters.Items {
+		s, ok := previous[clusters.Items[i].Name]
+		if !ok {
+			s = expv1.ClusterGroupClusterStatus{Name: clusters.Items[i].Name, Phase: expv1.ClusterGroupClusterPhasePending}
+		}
+		clusterStatuses[i] = s
+	}
+
+	// Check the progress of the Clusters the operation is in progress on first, so that they free up their slot.
+	var errs []error
+	for i := range clusters.Items {
+		if clusterStatuses[i].Phase != expv1.ClusterGroupClusterPhaseInProgress {
+			continue
+		}
+		if err := r.reconcileClusterOperation(ctx, operation, &clusters.Items[i], &clusterStatuses[i], false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	inProgress, failed := countClusterGroupPhase(clusterStatuses, expv1.ClusterGroupClusterPhaseInProgress), countClusterGroupPhase(clusterStatuses, expv1.ClusterGroupClusterPhaseFailed)
+	for i := range clusters.Items {
+		if failed > maxFailures || inProgress >= maxConcurrency {
+			break
+		}
+		if clusterStatuses[i].Phase != expv1.ClusterGroupClusterPhasePending {
+			continue
+		}
+		if err := r.reconcileClusterOperation(ctx, operation, &clusters.Items[i], &clusterStatuses[i], true); err != nil {
+			errs = append(errs, err)
+		}
+		switch clusterStatuses[i].Phase {
+		case expv1.ClusterGroupClusterPhaseInProgress:
+			inProgress++
+		case expv1.ClusterGroupClusterPhaseFailed:
+			failed++
+		}
+	}
+
+	status.ClusterStatuses = clusterStatuses
+	status.SucceededClusters = countClusterGroupPhase(clusterStatuses, expv1.ClusterGroupClusterPhaseSucceeded)
+	status.FailedClusters = failed
+	switch {
+	case failed > maxFailures:
+		status.Phase = expv1.ClusterGroupPhaseFailed
+	case status.SucceededClusters+failed == status.Clusters:
+		status.Phase = expv1.ClusterGroupPhaseCompleted
+	default:
+		status.Phase = expv1.ClusterGroupPhaseProgressing
+	}
+	return kerrors.NewAggregate(errs)
+}
+
+// reconcileClusterOperation starts the operation on a Cluster, or checks its progress, and updates the status of
+// the Cluster accordingly. Errors which can be retried are returned without failing the Cluster.
+func (r *ClusterGroupReconciler) reconcileClusterOperation(ctx context.Context, operation *expv1.ClusterGroupOperation, cluster *clusterv1.Cluster, clusterStatus *expv1.ClusterGroupClusterStatus, start bool) error {
+	if !cluster.DeletionTimestamp.IsZero() {
+		setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, "Cluster is being deleted")
+		return nil
+	}
+
+	switch operation.Type {
+	case expv1.ClusterGroupOperationPause, expv1.ClusterGroupOperationResume:
+		paused := operation.Type == expv1.ClusterGroupOperationPause
+		if cluster.Spec.Paused != paused {
+			patchHelper, err := patch.NewHelper(cluster, r.Client)
+			if err != nil {
+				return err
+			}
+			cluster.Spec.Paused = paused
+			if err := patchHelper.Patch(ctx, cluster); err != nil {
+				if isPermanentPatchError(err) {
+					setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, fmt.Sprintf("failed to patch Cluster: %v", err))
+					return nil
+				}
+				return errors.Wrapf(err, "failed to patch Cluster %s", cluster.Name)
+			}
+		}
+		setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseSucceeded, "")
+		return nil
+	case expv1.ClusterGroupOperationSetVersion:
+		return r.reconcileClusterVersion(ctx, operation.Version, cluster, clusterStatus, start)
+	}
+	setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, fmt.Sprintf("unsupported operation %q", operation.Type))
+	return nil
+}
+
+// reconcileClusterVersion sets the version of the control plane of a Cluster, and checks whether all the control
+// plane Machines have been upgraded.
+func (r *ClusterGroupReconciler) reconcileClusterVersion(ctx context.Context, version string, cluster *clusterv1.Cluster, clusterStatus *expv1.ClusterGroupClusterStatus, start bool) error {
+	if cluster.Spec.ControlPlaneRef == nil {
+		setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, "Cluster does not have a control plane")
+		return nil
+	}
+
+	if failed := fleetViewFailedCluster(cluster); failed != nil {
+		setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, fmt.Sprintf("Cluster is failed: %s %s", failed.Reason, failed.Message))
+		return nil
+	}
+
+	controlPlane, err := external.Get(ctx, r.Client, cluster.Spec.ControlPlaneRef, cluster.Namespace)
+	if err != nil {
+		if apierrors.IsNotFound(errors.Cause(err)) {
+			setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, "control plane not found")
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get the control plane of Cluster %s", cluster.Name)
+	}
+
+	currentVersion, _, err := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the version of the control plane of Cluster %s", cluster.Name)
+	}
+	if currentVersion != version {
+		if !start {
+			// The version has been changed by someone else while the operation was in progress.
+			setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, fmt.Sprintf("control plane version changed to %s", currentVersion))
+			return nil
+		}
+		patchHelper, err := patch.NewHelper(controlPlane, r.Client)
+		if err != nil {
+			return err
+		}
+		if err := unstructured.SetNestedField(controlPlane.Object, version, "spec", "version"); err != nil {
+			return errors.Wrapf(err, "failed to set the version of the control plane of Cluster %s", cluster.Name)
+		}
+		if err := patchHelper.Patch(ctx, controlPlane); err != nil {
+			// The version is rejected by the control plane webhooks, or the controller is not allowed to change it;
+			// retrying would leave the Cluster pending forever.
+			if isPermanentPatchError(err) {
+				setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseFailed, fmt.Sprintf("failed to set the control plane version: %v", err))
+				return nil
+			}
+			return errors.Wrapf(err, "failed to patch the control plane of Cluster %s", cluster.Name)
+		}
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(cluster.Namespace), client.MatchingLabels{
+		clusterv1.ClusterLabelName:             cluster.Name,
+		clusterv1.MachineControlPlaneLabelName: "",
+	}); err != nil {
+		return errors.Wrapf(err, "failed to list the control plane Machines of Cluster %s", cluster.Name)
+	}
+	outdated := 0
+	for _, m := range machines.Items {
+		if m.Spec.Version == nil || *m.Spec.Version != version || !m.DeletionTimestamp.IsZero() {
+			outdated++
+		}
+	}
+	if outdated > 0 || len(machines.Items) == 0 || !cluster.Status.ControlPlaneReady {
+		setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseInProgress, fmt.Sprintf("%d of %d control plane Machines to be upgraded", outdated, len(machines.Items)))
+		return nil
+	}
+	setClusterGroupClusterPhase(clusterStatus, expv1.ClusterGroupClusterPhaseSucceeded, "")
+	return nil
+}
+
+// isPermanentPatchError returns true if the error, or any of the errors it aggregates, is an Invalid or a Forbidden
+// error, i.e. an error which won't be fixed by retrying the patch.
+func isPermanentPatchError(err error) bool {
+	var agg kerrors.Aggregate
+	if errors.As(err, &agg) {
+		for _, e := range agg.Errors() {
+			if isPermanentPatchError(e) {
+				return true
+			}
+		}
+		return false
+	}
+	return apierrors.IsInvalid(err) || apierrors.IsForbidden(err)
+}
+
+func setClusterGroupClusterPhase(clusterStatus *expv1.ClusterGroupClusterStatus, phase expv1.ClusterGroupClusterPhase, message string) {
+	clusterStatus.Phase = phase
+	clusterStatus.Message = message
+}
+
+func countClusterGroupPhase(clusterStatuses []expv1.ClusterGroupClusterStatus, phase expv1.ClusterGroupClusterPhase) int32 {
+	count := int32(0)
+	for _, s := range clusterStatuses {
+		if s.Phase == phase {
+			count++
+		}
+	}
+	return count
+}
+
+// clusterToClusterGroups maps events from Cluster objects to the ClusterGroups selecting the Cluster.
+func (r *ClusterGroupReconciler) clusterToClusterGroups(o client.Object) []reconcile.Request {
+	c, ok := o.(*clusterv1.Cluster)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Cluster, got %T", o))
+	}
+
+	clusterGroups := &expv1.ClusterGroupList{}
+	if err := r.Client.List(context.TODO(), clusterGroups, client.InNamespace(c.Namespace)); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range clusterGroups.Items {
+		clusterGroup := &clusterGroups.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(&clusterGroup.Spec.ClusterSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(c.Labels)) {
+			requests = append(requests, reconcile.Request{NamespacedName: util.ObjectKey(clusterGroup)})
+		}
+	}
+	return requests
+}
+
+// machineToClusterGroups maps events from control plane Machine objects to the ClusterGroups selecting the Cluster
+// of the Machine.
+func (r *ClusterGroupReconciler) machineToClusterGroups(o client.Object) []reconcile.Request {
+	m, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine, got %T", o))
+	}
+	if !util.IsControlPlaneMachine(m) {
+		return nil
+	}
+
+	cluster, err := util.GetClusterByName(context.TODO(), r.Client, m.Namespace, m.Spec.ClusterName)
+	if err != nil {
+		return nil
+	}
+	return r.clusterToClusterGroups(cluster)
+}
@@ -0,0 +1,315 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestClusterGroupReconcilePauseResume(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	clusterGroup := &expv1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "default"},
+		Spec: expv1.ClusterGroupSpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			Operation:       &expv1.ClusterGroupOperation{Type: expv1.ClusterGroupOperationPause},
+		},
+	}
+	clusters := []client.Object{
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Labels: map[string]string{"env": "prod"}}},
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "default", Labels: map[string]string{"env": "prod"}}},
+		&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "default", Labels: map[string]string{"env": "dev"}}},
+	}
+	r := &ClusterGroupReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(clusters, clusterGroup)...).Build(),
+	}
+
+	// Instant operations are executed on all the Clusters, regardless of the concurrency.
+	g.Expect(r.reconcile(ctx, clusterGroup)).To(Succeed())
+	g.Expect(clusterGroup.Status.Clusters).To(Equal(int32(2)))
+	g.Expect(clusterGroup.Status.Phase).To(Equal(expv1.ClusterGroupPhaseCompleted))
+	g.Expect(clusterGroup.Status.SucceededClusters).To(Equal(int32(2)))
+	g.Expect(clusterPaused(g, r.Client, "a")).To(BeTrue())
+	g.Expect(clusterPaused(g, r.Client, "b")).To(BeTrue())
+	g.Expect(clusterPaused(g, r.Client, "c")).To(BeFalse())
+
+	clusterGroup.Spec.Operation = &expv1.ClusterGroupOperation{Type: expv1.ClusterGroupOperationResume}
+	g.Expect(r.reconcile(ctx, clusterGroup)).To(Succeed())
+	g.Expect(clusterGroup.Status.Operation.Type).To(Equal(expv1.ClusterGroupOperationResume))
+	g.Expect(clusterGroup.Status.Phase).To(Equal(expv1.ClusterGroupPhaseCompleted))
+	g.Expect(clusterPaused(g, r.Client, "a")).To(BeFalse())
+	g.Expect(clusterPaused(g, r.Client, "b")).To(BeFalse())
+
+	clusterGroup.Spec.Operation = nil
+	g.Expect(r.reconcile(ctx, clusterGroup)).To(Succeed())
+	g.Expect(clusterGroup.Status).To(Equal(expv1.ClusterGroupStatus{Clusters: 2}))
+}
+
+func TestClusterGroupReconcileSetVersion(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	newCluster := func(name string, withControlPlane bool) []client.Object {
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"}}
+		if !withControlPlane {
+			return []client.Object{cluster}
+		}
+		cluster.Spec.ControlPlaneRef = &corev1.ObjectReference{
+			APIVersion: "controlplane.cluster.x-k8s.io/v1alpha4",
+			Kind:       "GenericControlPlane",
+			Name:       name,
+			Namespace:  "default",
+		}
+		controlPlane := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "controlplane.cluster.x-k8s.io/v1alpha4",
+				"kind":       "GenericControlPlane",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"version": "v1.19.7",
+				},
+			},
+		}
+		return []client.Object{cluster, controlPlane}
+	}
+	newClusterGroup := func(maxFailures int32) *expv1.ClusterGroup {
+		return &expv1.ClusterGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "default"},
+			Spec: expv1.ClusterGroupSpec{
+				Operation: &expv1.ClusterGroupOperation{
+					Type:        expv1.ClusterGroupOperationSetVersion,
+					Version:     "v1.20.2",
+					MaxFailures: pointer.Int32Ptr(maxFailures),
+				},
+			},
+		}
+	}
+
+	t.Run("upgrades the Clusters one at a time", func(t *testing.T) {
+		g := NewWithT(t)
+
+		clusterGroup := newClusterGroup(0)
+		r := &ClusterGroupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(concatObjects(newCluster("a", true), newCluster("b", true)), clusterGroup)...).Build(),
+		}
+
+		g.Expect(r.reconcile(ctx, clusterGroup)).To(Succeed())
+		g.Expect(clusterGroup.Status.Phase).To(Equal(expv1.ClusterGroupPhaseProgressing))
+		g.Expect(clusterGroupPhases(clusterGroup)).To(Equal([]expv1.ClusterGroupClusterPhase{expv1.ClusterGroupClusterPhaseInProgress, expv1.ClusterGroupClusterPhasePending}))
+		g.Expect(controlPlaneVersion(g, r.Client, "a")).To(Equal("v1.20.2"))
+		g.Expect(controlPlaneVersion(g, r.Client, "b")).To(Equal("v1.19.7"))
+
+		// Complete the upgrade of the control plane of the first Cluster.
+		g.Expect(r.Client.Create(ctx, &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "a-1",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: "a", clusterv1.MachineControlPlaneLabelName: ""},
+			},
+			Spec: clusterv1.MachineSpec{ClusterName: "a", Version: pointer.StringPtr("v1.20.2")},
+		})).To(Succeed())
+		cluster := &clusterv1.Cluster{}
+		g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "a"}, cluster)).To(Succeed())
+		cluster.Status.ControlPlaneReady = true
+		g.Expect(r.Client.Update(ctx, cluster)).To(Succeed())
+
+		g.Expect(r.reconcile(ctx, clusterGroup)).To(Succeed())
+		g.Expect(clusterGroupPhases(clusterGroup)).To(Equal([]expv1.ClusterGroupClusterPhase{expv1.ClusterGroupClusterPhaseSucceeded, expv1.ClusterGroupClusterPhaseInProgress}))
+		g.Expect(clusterGroup.Status.SucceededClusters).To(Equal(int32(1)))
+		g.Expect(controlPlaneVersion(g, r.Client, "b")).To(Equal("v1.20.2"))
+	})
+
+	t.Run("stops when the failure threshold is exceeded", func(t *testing.T) {
+		g := NewWithT(t)
+
+		clusterGroup := newClusterGroup(0)
+		r := &ClusterGroupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(concatObjects(newCluster("a", false), newCluster("b", true)), clusterGroup)...).Build(),
+		}
+
+		g.Expect(r.reconcile(ctx, clusterGroup)).To(Succeed())
+		g.Expect(clusterGroup.Status.Phase).To(Equal(expv1.ClusterGroupPhaseFailed))
+		g.Expect(clusterGroup.Status.FailedClusters).To(Equal(int32(1)))
+		g.Expect(clusterGroupPhases(clusterGroup)).To(Equal([]expv1.ClusterGroupClusterPhase{expv1.ClusterGroupClusterPhaseFailed, expv1.ClusterGroupClusterPhasePending}))
+		g.Expect(controlPlaneVersion(g, r.Client, "b")).To(Equal("v1.19.7"))
+	})
+
+	t.Run("continues within the failure threshold", func(t *testing.T) {
+		g := NewWithT(t)
+
+		clusterGroup := newClusterGroup(1)
+		r := &ClusterGroupReconciler{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(concatObjects(newCluster("a", false), newCluster("b", true)), clusterGroup)...).Build(),
+		}
+
+		g.Expect(r.reconcile(ctx, clusterGroup)).To(Succeed())
+		g.Expect(clusterGroup.Status.Phase).To(Equal(expv1.ClusterGroupPhaseProgressing))
+		g.Expect(clusterGroupPhases(clusterGroup)).To(Equal([]expv1.ClusterGroupClusterPhase{expv1.ClusterGroupClusterPhaseFailed, expv1.ClusterGroupClusterPhaseInProgress}))
+		g.Expect(controlPlaneVersion(g, r.Client, "b")).To(Equal("v1.20.2"))
+	})
+}
+
+// rejectControlPlanePatchClient is a client rejecting the patches of control planes, as their webhooks would do
+// for an invalid version.
+type rejectControlPlanePatchClient struct {
+	client.Client
+}
+
+func (c rejectControlPlanePatchClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if _, ok := obj.(*unstructured.Unstructured); ok {
+		return apierrors.NewInvalid(schema.GroupKind{Group: "controlplane.cluster.x-k8s.io", Kind: "GenericControlPlane"}, obj.GetName(), field.ErrorList{
+			field.Invalid(field.NewPath("spec", "version"), "v1.20.2", "unsupported version"),
+		})
+	}
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func TestClusterGroupReconcileSetVersionRejected(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Labels: map[string]string{"env": "prod"}},
+		Spec: clusterv1.ClusterSpec{
+			ControlPlaneRef: &corev1.ObjectReference{
+				APIVersion: "controlplane.cluster.x-k8s.io/v1alpha4",
+				Kind:       "GenericControlPlane",
+				Name:       "a",
+				Namespace:  "default",
+			},
+		},
+	}
+	controlPlane := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "controlplane.cluster.x-k8s.io/v1alpha4",
+			"kind":       "GenericControlPlane",
+			"metadata": map[string]interface{}{
+				"name":      "a",
+				"namespace": "default",
+			},
+			"spec": map[string]interface{}{
+				"version": "v1.19.7",
+			},
+		},
+	}
+	clusterGroup := &expv1.ClusterGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "default", Generation: 2},
+		Spec: expv1.ClusterGroupSpec{
+			ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			Operation: &expv1.ClusterGroupOperation{
+				Type:    expv1.ClusterGroupOperationSetVersion,
+				Version: "v1.20.2",
+			},
+		},
+	}
+	r := &ClusterGroupReconciler{
+		Client: rejectControlPlanePatchClient{
+			Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cluster, controlPlane, clusterGroup).Build(),
+		},
+	}
+
+	_, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "group"}})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	g.Expect(r.Client.Get(ctx, client.ObjectKey{Namespace: "default", Name: "group"}, clusterGroup)).To(Succeed())
+	g.Expect(clusterGroup.Status.Phase).To(Equal(expv1.ClusterGroupPhaseFailed))
+	g.Expect(clusterGroup.Status.ObservedGeneration).To(Equal(int64(2)))
+	g.Expect(clusterGroupPhases(clusterGroup)).To(Equal([]expv1.ClusterGroupClusterPhase{expv1.ClusterGroupClusterPhaseFailed}))
+	g.Expect(clusterGroup.Status.ClusterStatuses[0].Message).To(ContainSubstring("unsupported version"))
+}
+
+func TestClusterGroupMachineToClusterGroups(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	r := &ClusterGroupReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Labels: map[string]string{"env": "prod"}}},
+			&expv1.ClusterGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "default"},
+				Spec:       expv1.ClusterGroupSpec{ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+			},
+			&expv1.ClusterGroup{
+				ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "default"},
+				Spec:       expv1.ClusterGroupSpec{ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}}},
+			},
+		).Build(),
+	}
+
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "a-1", Namespace: "default", Labels: map[string]string{clusterv1.MachineControlPlaneLabelName: ""}},
+		Spec:       clusterv1.MachineSpec{ClusterName: "a"},
+	}
+	var names []string
+	for _, req := range r.machineToClusterGroups(machine) {
+		names = append(names, req.Name)
+	}
+	g.Expect(names).To(ConsistOf("prod"))
+
+	// Worker Machines are ignored.
+	delete(machine.Labels, clusterv1.MachineControlPlaneLabelName)
+	g.Expect(r.machineToClusterGroups(machine)).To(BeEmpty())
+}
+
+func clusterPaused(g *WithT, c client.Client, name string) bool {
+	cluster := &clusterv1.Cluster{}
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, cluster)).To(Succeed())
+	return cluster.Spec.Paused
+}
+
+func controlPlaneVersion(g *WithT, c client.Client, name string) string {
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetAPIVersion("controlplane.cluster.x-k8s.io/v1alpha4")
+	controlPlane.SetKind("GenericControlPlane")
+	g.Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, controlPlane)).To(Succeed())
+	version, _, _ := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	return version
+}
+
+func clusterGroupPhases(clusterGroup *expv1.ClusterGroup) []expv1.ClusterGroupClusterPhase {
+	phases := []expv1.ClusterGroupClusterPhase{}
+	for _, s := range clusterGroup.Status.ClusterStatuses {
+		phases = append(phases, s.Phase)
+	}
+	return phases
+}
@@ -0,0 +1,318 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/controllers/external"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+	"sigs.k8s.io/cluster-api/util/predicates"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// +kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;machines,verbs=get;list;watch
+// +kubebuilder:rbac:groups=controlplane.cluster.x-k8s.io,resources=*,verbs=get;list;watch
+// +kubebuilder:rbac:groups=exp.cluster.x-k8s.io,resources=fleetviews;fleetviews/status,verbs=get;list;watch;update;patch
+
+// FleetViewReconciler reconciles a FleetView object.
+type FleetViewReconciler struct {
+	Client           client.Client
+	WatchFilterValue string
+
+	// controlPlaneReader reads the control planes from the manager cache, which is populated by the watches
+	// added through the externalTracker; the manager client does not cache unstructured objects.
+	controlPlaneReader client.Reader
+	externalTracker    external.ObjectTracker
+}
+
+func (r *FleetViewReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager, options controller.Options) error {
+	c, err := ctrl.NewControllerManagedBy(mgr).
+		For(&expv1.FleetView{}).
+		WithOptions(options).
+		WithEventFilter(predicates.ResourceHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
+		Build(r)
+	if err != nil {
+		return errors.Wrap(err, "failed setting up with a controller manager")
+	}
+
+	if err := c.Watch(
+		&source.Kind{Type: &clusterv1.Cluster{}},
+		handler.EnqueueRequestsFromMapFunc(r.clusterToFleetViews),
+	); err != nil {
+		return errors.Wrap(err, "failed adding Watch for Clusters to controller manager")
+	}
+
+	// Machines are watched for detecting the Clusters being upgraded.
+	if err := c.Watch(
+		&source.Kind{Type: &clusterv1.Machine{}},
+		handler.EnqueueRequestsFromMapFunc(r.machineToFleetViews),
+		machineVersionChanged(),
+	); err != nil {
+		return errors.Wrap(err, "failed adding Watch for Machines to controller manager")
+	}
+
+	r.controlPlaneReader = mgr.GetCache()
+	r.externalTracker = external.ObjectTracker{
+		Controller: c,
+	}
+	return nil
+}
+
+func (r *FleetViewReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, reterr error) {
+	fleetView := &expv1.FleetView{}
+	if err := r.Client.Get(ctx, req.NamespacedName, fleetView); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	patchHelper, err := patch.NewHelper(fleetView, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	defer func() {
+		// Patch ObservedGeneration only if the reconciliation completed successfully.
+		patchOpts := []patch.Option{}
+		if reterr == nil {
+			patchOpts = append(patchOpts, patch.WithStatusObservedGeneration{})
+		}
+		if err := patchHelper.Patch(ctx, fleetView, patchOpts...); err != nil {
+			reterr = kerrors.NewAggregate([]error{reterr, err})
+		}
+	}()
+
+	if !fleetView.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	return ctrl.Result{}, r.reconcile(ctx, fleetView)
+}
+
+func (r *FleetViewReconciler) reconcile(ctx context.Context, fleetView *expv1.FleetView) error {
+	selector, err := metav1.LabelSelectorAsSelector(&fleetView.Spec.ClusterSelector)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse the cluster selector of FleetView %s", fleetView.Name)
+	}
+
+	clusters := &clusterv1.ClusterList{}
+	if err := r.Client.List(ctx, clusters, client.InNamespace(fleetView.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return errors.Wrapf(err, "failed to list Clusters for FleetView %s", fleetView.Name)
+	}
+
+	machines := &clusterv1.MachineList{}
+	if err := r.Client.List(ctx, machines, client.InNamespace(fleetView.Namespace), client.HasLabels{clusterv1.ClusterLabelName}); err != nil {
+		return errors.Wrapf(err, "failed to list Machines for FleetView %s", fleetView.Name)
+	}
+	machinesByCluster := map[string][]clusterv1.Machine{}
+	for _, m := range machines.Items {
+		machinesByCluster[m.Spec.ClusterName] = append(machinesByCluster[m.Spec.ClusterName], m)
+	}
+
+	status := expv1.FleetViewStatus{}
+	versions := map[string]int32{}
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		status.Clusters++
+
+		if conditions.IsTrue(cluster, clusterv1.ReadyCondition) {
+			status.ReadyClusters++
+		}
+
+		if failed := fleetViewFailedCluster(cluster); failed != nil {
+			status.FailedClusters = append(status.FailedClusters, *failed)
+		}
+
+		version, err := r.getControlPlaneVersion(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		if version == "" {
+			continue
+		}
+		versions[version]++
+
+		for _, m := range machinesByCluster[cluster.Name] {
+			if m.Spec.Version != nil && *m.Spec.Version != version {
+				status.UpgradingClusters++
+				break
+			}
+		}
+	}
+
+	for version, count := range versions {
+		status.Versions = append(status.Versions, expv1.FleetViewVersion{Version: version, Clusters: count})
+	}
+	sort.Slice(status.Versions, func(i, j int) bool {
+		return status.Versions[i].Version < status.Versions[j].Version
+	})
+
+	status.ObservedGeneration = fleetView.Status.ObservedGeneration
+	fleetView.Status = status
+	return nil
+}
+
+// fleetViewFailedCluster returns the failure of a Cluster, if any; Clusters are failed when they report a terminal
+// failure, or a Ready condition set to false with severity Error.
+func fleetViewFailedCluster(cluster *clusterv1.Cluster) *expv1.FleetViewFailedCluster {
+	if cluster.Status.FailureReason != nil {
+		failed := &expv1.FleetViewFailedCluster{
+			Name:   cluster.Name,
+			Reason: string(*cluster.Status.FailureReason),
+		}
+		if cluster.Status.FailureMessage != nil {
+			failed.Message = *cluster.Status.FailureMessage
+		}
+		return failed
+	}
+
+	if conditions.IsFalse(cluster, clusterv1.ReadyCondition) {
+		if severity := conditions.GetSeverity(cluster, clusterv1.ReadyCondition); severity != nil && *severity == clusterv1.ConditionSeverityError {
+			return &expv1.FleetViewFailedCluster{
+				Name:    cluster.Name,
+				Reason:  conditions.GetReason(cluster, clusterv1.ReadyCondition),
+				Message: conditions.GetMessage(cluster, clusterv1.ReadyCondition),
+			}
+		}
+	}
+	return nil
+}
+
+// getControlPlaneVersion returns the Kubernetes version of the control plane of a Cluster, as defined by the optional
+// spec.version field of the control plane contract; it returns an empty string if the version is not known.
+func (r *FleetViewReconciler) getControlPlaneVersion(ctx context.Context, cluster *clusterv1.Cluster) (string, error) {
+	if cluster.Spec.ControlPlaneRef == nil {
+		return "", nil
+	}
+
+	controlPlane := &unstructured.Unstructured{}
+	controlPlane.SetAPIVersion(cluster.Spec.ControlPlaneRef.APIVersion)
+	controlPlane.SetKind(cluster.Spec.ControlPlaneRef.Kind)
+
+	// Watch the control plane kind, so that it is read from the cache and version changes are seen.
+	if err := r.externalTracker.Watch(ctrl.LoggerFrom(ctx), controlPlane, handler.EnqueueRequestsFromMapFunc(r.controlPlaneToFleetViews)); err != nil {
+		return "", err
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.Spec.ControlPlaneRef.Name}
+	if err := r.controlPlaneReader.Get(ctx, key, controlPlane); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", errors.Wrapf(err, "failed to get the control plane of Cluster %s", cluster.Name)
+	}
+
+	version, _, err := unstructured.NestedString(controlPlane.Object, "spec", "version")
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to get the version of the control plane of Cluster %s", cluster.Name)
+	}
+	return version, nil
+}
+
+// clusterToFleetViews maps events from Cluster objects to the FleetViews selecting the Cluster.
+func (r *FleetViewReconciler) clusterToFleetViews(o client.Object) []reconcile.Request {
+	c, ok := o.(*clusterv1.Cluster)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Cluster, got %T", o))
+	}
+
+	fleetViews := &expv1.FleetViewList{}
+	if err := r.Client.List(context.TODO(), fleetViews, client.InNamespace(c.Namespace)); err != nil {
+		return nil
+	}
+
+	var requests []reconcile.Request
+	for i := range fleetViews.Items {
+		fleetView := &fleetViews.Items[i]
+		selector, err := metav1.LabelSelectorAsSelector(&fleetView.Spec.ClusterSelector)
+		if err != nil {
+			continue
+		}
+		if selector.Matches(labels.Set(c.Labels)) {
+			requests = append(requests, reconcile.Request{NamespacedName: util.ObjectKey(fleetView)})
+		}
+	}
+	return requests
+}
+
+// controlPlaneToFleetViews maps events from control plane objects to the FleetViews selecting the Cluster owning the
+// control plane.
+func (r *FleetViewReconciler) controlPlaneToFleetViews(o client.Object) []reconcile.Request {
+	cluster, err := util.GetOwnerCluster(context.TODO(), r.Client, metav1.ObjectMeta{
+		Namespace:       o.GetNamespace(),
+		OwnerReferences: o.GetOwnerReferences(),
+	})
+	if err != nil || cluster == nil {
+		return nil
+	}
+	return r.clusterToFleetViews(cluster)
+}
+
+// machineToFleetViews maps events from Machine objects to the FleetViews selecting the Cluster of the Machine.
+func (r *FleetViewReconciler) machineToFleetViews(o client.Object) []reconcile.Request {
+	m, ok := o.(*clusterv1.Machine)
+	if !ok {
+		panic(fmt.Sprintf("Expected a Machine, got %T", o))
+	}
+
+	cluster, err := util.GetClusterByName(context.TODO(), r.Client, m.Namespace, m.Spec.ClusterName)
+	if err != nil {
+		return nil
+	}
+	return r.clusterToFleetViews(cluster)
+}
+
+// machineVersionChanged returns a predicate that returns true for Machine create and delete events, and for update
+// events changing the version or the Cluster of the Machine, which are the only fields used by the FleetViews.
+func machineVersionChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldMachine, ok := e.ObjectOld.(*clusterv1.Machine)
+			if !ok {
+				return false
+			}
+			newMachine, ok := e.ObjectNew.(*clusterv1.Machine)
+			if !ok {
+				return false
+			}
+			return oldMachine.Spec.ClusterName != newMachine.Spec.ClusterName ||
+				!reflect.DeepEqual(oldMachine.Spec.Version, newMachine.Spec.Version)
+		},
+	}
+}
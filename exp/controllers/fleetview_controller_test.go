@@ -0,0 +1,262 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	capierrors "sigs.k8s.io/cluster-api/errors"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestFleetViewReconcile(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(clusterv1.AddToScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	newCluster := func(name, version string, setters ...func(*clusterv1.Cluster)) []client.Object {
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{"env": "prod"},
+			},
+		}
+		for _, set := range setters {
+			set(cluster)
+		}
+		if version == "" {
+			return []client.Object{cluster}
+		}
+
+		cluster.Spec.ControlPlaneRef = &corev1.ObjectReference{
+			APIVersion: "controlplane.cluster.x-k8s.io/v1alpha4",
+			Kind:       "GenericControlPlane",
+			Name:       name,
+			Namespace:  "default",
+		}
+		controlPlane := &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"apiVersion": "controlplane.cluster.x-k8s.io/v1alpha4",
+				"kind":       "GenericControlPlane",
+				"metadata": map[string]interface{}{
+					"name":      name,
+					"namespace": "default",
+				},
+				"spec": map[string]interface{}{
+					"version": version,
+				},
+			},
+		}
+		return []client.Object{cluster, controlPlane}
+	}
+	newMachine := func(name, clusterName, version string) client.Object {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterLabelName: clusterName},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: clusterName,
+				Version:     pointer.StringPtr(version),
+			},
+		}
+	}
+	ready := func(c *clusterv1.Cluster) {
+		conditions.MarkTrue(c, clusterv1.ReadyCondition)
+	}
+
+	tests := []struct {
+		name       string
+		selector   metav1.LabelSelector
+		objs       []client.Object
+		wantStatus expv1.FleetViewStatus
+	}{
+		{
+			name: "no Clusters",
+		},
+		{
+			name: "counts ready Clusters and the control plane versions",
+			objs: concatObjects(
+				newCluster("a", "v1.20.2", ready),
+				newCluster("b", "v1.19.7", ready),
+				newCluster("c", "v1.20.2"),
+				newCluster("d", ""),
+			),
+			wantStatus: expv1.FleetViewStatus{
+				Clusters:      4,
+				ReadyClusters: 2,
+				Versions: []expv1.FleetViewVersion{
+					{Version: "v1.19.7", Clusters: 1},
+					{Version: "v1.20.2", Clusters: 2},
+				},
+			},
+		},
+		{
+			name: "counts Clusters whose Machines are not at the control plane version as upgrading",
+			objs: concatObjects(
+				newCluster("a", "v1.20.2", ready),
+				[]client.Object{newMachine("a-1", "a", "v1.20.2"), newMachine("a-2", "a", "v1.19.7")},
+				newCluster("b", "v1.20.2", ready),
+				[]client.Object{newMachine("b-1", "b", "v1.20.2")},
+			),
+			wantStatus: expv1.FleetViewStatus{
+				Clusters:          2,
+				ReadyClusters:     2,
+				UpgradingClusters: 1,
+				Versions: []expv1.FleetViewVersion{
+					{Version: "v1.20.2", Clusters: 2},
+				},
+			},
+		},
+		{
+			name: "reports failed Clusters with their reasons",
+			objs: concatObjects(
+				newCluster("a", "", func(c *clusterv1.Cluster) {
+					reason := capierrors.InvalidConfigurationClusterError
+					c.Status.FailureReason = &reason
+					c.Status.FailureMessage = pointer.StringPtr("invalid configuration")
+				}),
+				newCluster("b", "", func(c *clusterv1.Cluster) {
+					conditions.MarkFalse(c, clusterv1.ReadyCondition, "InfrastructureFailed", clusterv1.ConditionSeverityError, "infrastructure failed")
+				}),
+				newCluster("c", "", func(c *clusterv1.Cluster) {
+					conditions.MarkFalse(c, clusterv1.ReadyCondition, "WaitingForInfrastructure", clusterv1.ConditionSeverityInfo, "")
+				}),
+			),
+			wantStatus: expv1.FleetViewStatus{
+				Clusters: 3,
+				FailedClusters: []expv1.FleetViewFailedCluster{
+					{Name: "a", Reason: string(capierrors.InvalidConfigurationClusterError), Message: "invalid configuration"},
+					{Name: "b", Reason: "InfrastructureFailed", Message: "infrastructure failed"},
+				},
+			},
+		},
+		{
+			name:     "ignores the Clusters not matching the selector",
+			selector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}},
+			objs: concatObjects(
+				newCluster("a", "v1.20.2", ready),
+				newCluster("b", "v1.20.2", ready, func(c *clusterv1.Cluster) {
+					c.Labels["env"] = "dev"
+				}),
+			),
+			wantStatus: expv1.FleetViewStatus{
+				Clusters:      1,
+				ReadyClusters: 1,
+				Versions: []expv1.FleetViewVersion{
+					{Version: "v1.20.2", Clusters: 1},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			fleetView := &expv1.FleetView{
+				ObjectMeta: metav1.ObjectMeta{Name: "fleet", Namespace: "default"},
+				Spec:       expv1.FleetViewSpec{ClusterSelector: tt.selector},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(append(tt.objs, fleetView)...).Build()
+			r := &FleetViewReconciler{
+				Client:             c,
+				controlPlaneReader: c,
+			}
+
+			g.Expect(r.reconcile(ctx, fleetView)).To(Succeed())
+			g.Expect(fleetView.Status).To(Equal(tt.wantStatus))
+		})
+	}
+}
+
+func TestFleetViewClusterToFleetViews(t *testing.T) {
+	g := NewWithT(t)
+	g.Expect(expv1.AddToScheme(scheme.Scheme)).To(Succeed())
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "default", Labels: map[string]string{"env": "prod"}},
+	}
+	r := &FleetViewReconciler{
+		Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(
+			&expv1.FleetView{ObjectMeta: metav1.ObjectMeta{Name: "all", Namespace: "default"}},
+			&expv1.FleetView{
+				ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "default"},
+				Spec:       expv1.FleetViewSpec{ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "prod"}}},
+			},
+			&expv1.FleetView{
+				ObjectMeta: metav1.ObjectMeta{Name: "dev", Namespace: "default"},
+				Spec:       expv1.FleetViewSpec{ClusterSelector: metav1.LabelSelector{MatchLabels: map[string]string{"env": "dev"}}},
+			},
+			&expv1.FleetView{ObjectMeta: metav1.ObjectMeta{Name: "other-namespace", Namespace: "other"}},
+		).Build(),
+	}
+
+	var names []string
+	for _, req := range r.clusterToFleetViews(cluster) {
+		names = append(names, req.Name)
+	}
+	g.Expect(names).To(ConsistOf("all", "prod"))
+}
+
+func TestFleetViewMachineVersionChanged(t *testing.T) {
+	machine := &clusterv1.Machine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m", Namespace: "default"},
+		Spec:       clusterv1.MachineSpec{ClusterName: "a", Version: pointer.StringPtr("v1.20.2")},
+	}
+	statusChanged := machine.DeepCopy()
+	statusChanged.Status.Phase = string(clusterv1.MachinePhaseRunning)
+	versionChanged := machine.DeepCopy()
+	versionChanged.Spec.Version = pointer.StringPtr("v1.21.0")
+
+	tests := []struct {
+		name      string
+		newObject *clusterv1.Machine
+		want      bool
+	}{
+		{name: "status changed", newObject: statusChanged, want: false},
+		{name: "version changed", newObject: versionChanged, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			g.Expect(machineVersionChanged().Update(event.UpdateEvent{ObjectOld: machine, ObjectNew: tt.newObject})).To(Equal(tt.want))
+		})
+	}
+}
+
+func concatObjects(objs ...[]client.Object) []client.Object {
+	var ret []client.Object
+	for _, o := range objs {
+		ret = append(ret, o...)
+	}
+	return ret
+}
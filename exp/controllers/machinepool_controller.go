@@ -32,9 +32,11 @@ import (
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/controllers/remote"
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/util"
 	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/defaulting"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/cluster-api/util/predicates"
 	ctrl "sigs.k8s.io/controller-runtime"
@@ -61,6 +63,10 @@ type MachinePoolReconciler struct {
 	Client           client.Client
 	WatchFilterValue string
 
+	// WebhooksDisabled applies the defaults and the validation of the admission webhooks to the MachinePools inside the
+	// controller, for management clusters where the webhooks are not installed.
+	WebhooksDisabled bool
+
 	config           *rest.Config
 	controller       controller.Controller
 	recorder         record.EventRecorder
@@ -77,7 +83,7 @@ func (r *MachinePoolReconciler) SetupWithManager(ctx context.Context, mgr ctrl.M
 		For(&expv1.MachinePool{}).
 		WithOptions(options).
 		WithEventFilter(predicates.ResourceNotPausedAndHasFilterLabel(ctrl.LoggerFrom(ctx), r.WatchFilterValue)).
-		Build(r)
+		Build(feature.GatedReconciler(feature.MachinePool, r))
 	if err != nil {
 		return errors.Wrap(err, "failed setting up with a controller manager")
 	}
@@ -162,6 +168,15 @@ func (r *MachinePoolReconciler) Reconcile(ctx context.Context, req ctrl.Request)
 		}
 	}()
 
+	// Apply the defaults and the validation of the webhooks, when they are not installed.
+	if r.WebhooksDisabled {
+		if err := defaulting.DefaultAndValidate(mp); err != nil {
+			log.Error(err, "Invalid MachinePool, waiting for it to be fixed")
+			r.recorder.Eventf(mp, corev1.EventTypeWarning, "InvalidObject", "%v", err)
+			return ctrl.Result{}, nil
+		}
+	}
+
 	// Reconcile labels.
 	if mp.Labels == nil {
 		mp.Labels = make(map[string]string)
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/rand"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reconcileBootstrapDataHash sets the hash of the bootstrap data new instances of the MachinePool are created with.
+// The bootstrap provider might update the bootstrap data in place, e.g. after a change to the bootstrap config,
+// so the hash is computed on the content of the bootstrap data secret.
+func (r *MachinePoolReconciler) reconcileBootstrapDataHash(ctx context.Context, mp *expv1.MachinePool) error {
+	log := ctrl.LoggerFrom(ctx)
+
+	if mp.Spec.Template.Spec.Bootstrap.DataSecretName == nil {
+		return nil
+	}
+
+	s := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: mp.Namespace, Name: *mp.Spec.Template.Spec.Bootstrap.DataSecretName}
+	if err := r.Client.Get(ctx, key, s); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.V(2).Info("Bootstrap data secret not found, skipping bootstrap data hash computation", "secret", key.Name)
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get bootstrap data secret for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+
+	hash := computeBootstrapDataHash(s)
+	if mp.Status.BootstrapDataHash != "" && mp.Status.BootstrapDataHash != hash {
+		log.Info("Bootstrap data changed, new instances will be created with the new bootstrap data", "hash", hash)
+	}
+	mp.Status.BootstrapDataHash = hash
+	return nil
+}
+
+// computeBootstrapDataHash returns the hash of the bootstrap data stored in a secret.
+func computeBootstrapDataHash(s *corev1.Secret) string {
+	hasher := fnv.New32a()
+	_, _ = hasher.Write(s.Data["format"])
+	_, _ = hasher.Write(s.Data["value"])
+	return rand.SafeEncodeString(fmt.Sprint(hasher.Sum32()))
+}
+
+// reconcileInstanceBootstrapData reports which bootstrap data the instances of the MachinePool have been
+// created with, based on the optional status.instances field of the infrastructure object.
+func reconcileInstanceBootstrapData(mp *expv1.MachinePool, infraConfig *unstructured.Unstructured) error {
+	var instances []expv1.InstanceBootstrapData
+	if err := util.UnstructuredUnmarshalField(infraConfig, &instances, "status", "instances"); err != nil {
+		if err == util.ErrUnstructuredFieldNotFound {
+			mp.Status.InstanceBootstrapData = nil
+			mp.Status.UpToDateBootstrapDataReplicas = 0
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve instances from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+
+	var upToDate int32
+	reported := make([]expv1.InstanceBootstrapData, 0, len(instances))
+	for _, instance := range instances {
+		if instance.ProviderID == "" {
+			continue
+		}
+		if mp.Status.BootstrapDataHash != "" && instance.BootstrapDataHash == mp.Status.BootstrapDataHash {
+			upToDate++
+		}
+		reported = append(reported, instance)
+	}
+	if len(reported) == 0 {
+		reported = nil
+	}
+
+	mp.Status.InstanceBootstrapData = reported
+	mp.Status.UpToDateBootstrapDataReplicas = upToDate
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/utils/pointer"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcileBootstrapDataHash(t *testing.T) {
+	g := NewWithT(t)
+
+	newSecret := func(value string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "bootstrap-data",
+				Namespace: "default",
+			},
+			Data: map[string][]byte{
+				"value": []byte(value),
+			},
+		}
+	}
+	mp := &expv1.MachinePool{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machinepool-test",
+			Namespace: "default",
+		},
+		Spec: expv1.MachinePoolSpec{
+			Template: clusterv1.MachineTemplateSpec{
+				Spec: clusterv1.MachineSpec{
+					Bootstrap: clusterv1.Bootstrap{
+						DataSecretName: pointer.StringPtr("bootstrap-data"),
+					},
+				},
+			},
+		},
+	}
+
+	// The hash is not set if the secret doesn't exist.
+	r := &MachinePoolReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()}
+	g.Expect(r.reconcileBootstrapDataHash(ctx, mp)).To(Succeed())
+	g.Expect(mp.Status.BootstrapDataHash).To(BeEmpty())
+
+	r = &MachinePoolReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newSecret("data")).Build()}
+	g.Expect(r.reconcileBootstrapDataHash(ctx, mp)).To(Succeed())
+	hash := mp.Status.BootstrapDataHash
+	g.Expect(hash).ToNot(BeEmpty())
+
+	// The hash changes when the bootstrap data is updated in place.
+	r = &MachinePoolReconciler{Client: fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(newSecret("new data")).Build()}
+	g.Expect(r.reconcileBootstrapDataHash(ctx, mp)).To(Succeed())
+	g.Expect(mp.Status.BootstrapDataHash).ToNot(BeEmpty())
+	g.Expect(mp.Status.BootstrapDataHash).ToNot(Equal(hash))
+}
+
+func TestReconcileInstanceBootstrapData(t *testing.T) {
+	newInfraConfig := func(status map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind":       "InfrastructureMachinePool",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"status": status,
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		infraConfig      *unstructured.Unstructured
+		wantInstances    []expv1.InstanceBootstrapData
+		wantUpToDateReps int32
+	}{
+		{
+			name: "reports the bootstrap data of the instances",
+			infraConfig: newInfraConfig(map[string]interface{}{
+				"instances": []interface{}{
+					map[string]interface{}{"providerID": "test://id-1", "bootstrapDataHash": "current"},
+					map[string]interface{}{"providerID": "test://id-2", "bootstrapDataHash": "previous"},
+					map[string]interface{}{"providerID": "test://id-3", "bootstrapDataHash": "current"},
+				},
+			}),
+			wantInstances: []expv1.InstanceBootstrapData{
+				{ProviderID: "test://id-1", BootstrapDataHash: "current"},
+				{ProviderID: "test://id-2", BootstrapDataHash: "previous"},
+				{ProviderID: "test://id-3", BootstrapDataHash: "current"},
+			},
+			wantUpToDateReps: 2,
+		},
+		{
+			name:        "clears the bootstrap data of the instances if not reported",
+			infraConfig: newInfraConfig(map[string]interface{}{}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mp := &expv1.MachinePool{
+				Status: expv1.MachinePoolStatus{
+					BootstrapDataHash: "current",
+					InstanceBootstrapData: []expv1.InstanceBootstrapData{
+						{ProviderID: "test://stale", BootstrapDataHash: "current"},
+					},
+					UpToDateBootstrapDataReplicas: 1,
+				},
+			}
+			g.Expect(reconcileInstanceBootstrapData(mp, tt.infraConfig)).To(Succeed())
+			g.Expect(mp.Status.InstanceBootstrapData).To(Equal(tt.wantInstances))
+			g.Expect(mp.Status.UpToDateBootstrapDataReplicas).To(Equal(tt.wantUpToDateReps))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"sort"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util"
+)
+
+// instanceFailureDomain documents the failure domain an instance of a MachinePool is running in.
+type instanceFailureDomain struct {
+	ProviderID    string `json:"providerID"`
+	FailureDomain string `json:"failureDomain,omitempty"`
+}
+
+// reconcileFailureDomainReplicas reports the number of instances of the MachinePool running in each failure domain,
+// based on the optional status.instances field of the infrastructure object.
+func reconcileFailureDomainReplicas(mp *expv1.MachinePool, infraConfig *unstructured.Unstructured) error {
+	var instances []instanceFailureDomain
+	if err := util.UnstructuredUnmarshalField(infraConfig, &instances, "status", "instances"); err != nil {
+		if err == util.ErrUnstructuredFieldNotFound {
+			mp.Status.FailureDomains = nil
+			return nil
+		}
+		return errors.Wrapf(err, "failed to retrieve instances from infrastructure provider for MachinePool %q in namespace %q", mp.Name, mp.Namespace)
+	}
+
+	replicas := map[string]int32{}
+	for _, instance := range instances {
+		if instance.ProviderID == "" || instance.FailureDomain == "" {
+			continue
+		}
+		replicas[instance.FailureDomain]++
+	}
+	// Report the failure domains in spec without instances as well, so imbalances are visible.
+	for _, fd := range mp.Spec.FailureDomains {
+		if _, ok := replicas[fd]; !ok && len(replicas) > 0 {
+			replicas[fd] = 0
+		}
+	}
+	if len(replicas) == 0 {
+		mp.Status.FailureDomains = nil
+		return nil
+	}
+
+	failureDomains := make([]expv1.FailureDomainReplicas, 0, len(replicas))
+	for name, count := range replicas {
+		failureDomains = append(failureDomains, expv1.FailureDomainReplicas{Name: name, Replicas: count})
+	}
+	sort.Slice(failureDomains, func(i, j int) bool {
+		return failureDomains[i].Name < failureDomains[j].Name
+	})
+	mp.Status.FailureDomains = failureDomains
+	return nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
+)
+
+func TestReconcileFailureDomainReplicas(t *testing.T) {
+	newInfraConfig := func(status map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{
+			Object: map[string]interface{}{
+				"kind":       "InfrastructureMachinePool",
+				"apiVersion": "infrastructure.cluster.x-k8s.io/v1alpha4",
+				"metadata": map[string]interface{}{
+					"name":      "infra-config1",
+					"namespace": "default",
+				},
+				"status": status,
+			},
+		}
+	}
+
+	tests := []struct {
+		name               string
+		failureDomains     []string
+		infraConfig        *unstructured.Unstructured
+		wantFailureDomains []expv1.FailureDomainReplicas
+	}{
+		{
+			name:           "reports the number of instances in each failure domain",
+			failureDomains: []string{"zone-a", "zone-b", "zone-c"},
+			infraConfig: newInfraConfig(map[string]interface{}{
+				"instances": []interface{}{
+					map[string]interface{}{"providerID": "test://id-1", "failureDomain": "zone-b"},
+					map[string]interface{}{"providerID": "test://id-2", "failureDomain": "zone-a"},
+					map[string]interface{}{"providerID": "test://id-3", "failureDomain": "zone-b"},
+					map[string]interface{}{"providerID": "test://id-4"},
+				},
+			}),
+			wantFailureDomains: []expv1.FailureDomainReplicas{
+				{Name: "zone-a", Replicas: 1},
+				{Name: "zone-b", Replicas: 2},
+				{Name: "zone-c", Replicas: 0},
+			},
+		},
+		{
+			name:           "clears the failure domains if instances don't report one",
+			failureDomains: []string{"zone-a"},
+			infraConfig: newInfraConfig(map[string]interface{}{
+				"instances": []interface{}{
+					map[string]interface{}{"providerID": "test://id-1", "bootstrapDataHash": "current"},
+				},
+			}),
+		},
+		{
+			name:        "clears the failure domains if instances are not reported",
+			infraConfig: newInfraConfig(map[string]interface{}{}),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			mp := &expv1.MachinePool{
+				Spec: expv1.MachinePoolSpec{
+					FailureDomains: tt.failureDomains,
+				},
+				Status: expv1.MachinePoolStatus{
+					FailureDomains: []expv1.FailureDomainReplicas{
+						{Name: "stale", Replicas: 1},
+					},
+				},
+			}
+			g.Expect(reconcileFailureDomainReplicas(mp, tt.infraConfig)).To(Succeed())
+			g.Expect(mp.Status.FailureDomains).To(Equal(tt.wantFailureDomains))
+		})
+	}
+}
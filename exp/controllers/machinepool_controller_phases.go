@@ -187,7 +187,7 @@ func (r *MachinePoolReconciler) reconcileBootstrap(ctx context.Context, cluster
 	if m.Spec.Template.Spec.Bootstrap.DataSecretName != nil {
 		m.Status.BootstrapReady = true
 		conditions.MarkTrue(m, clusterv1.BootstrapReadyCondition)
-		return ctrl.Result{}, nil
+		return ctrl.Result{}, r.reconcileBootstrapDataHash(ctx, m)
 	}
 
 	// If the bootstrap config is being deleted, return early.
@@ -208,8 +208,9 @@ func (r *MachinePoolReconciler) reconcileBootstrap(ctx context.Context, cluster
 	)
 
 	if !ready {
-		log.V(2).Info("Bootstrap provider is not ready, requeuing")
-		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
+		requeueAfter := external.RequeueAfterFrom(log, bootstrapConfig, externalReadyWait)
+		log.V(2).Info("Bootstrap provider is not ready, requeuing", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
 	}
 
 	// Get and set the name of the secret containing the bootstrap data.
@@ -222,7 +223,7 @@ func (r *MachinePoolReconciler) reconcileBootstrap(ctx context.Context, cluster
 
 	m.Spec.Template.Spec.Bootstrap.DataSecretName = pointer.StringPtr(secretName)
 	m.Status.BootstrapReady = true
-	return ctrl.Result{}, nil
+	return ctrl.Result{}, r.reconcileBootstrapDataHash(ctx, m)
 }
 
 // reconcileInfrastructure reconciles the Spec.InfrastructureRef object on a MachinePool.
@@ -265,8 +266,19 @@ func (r *MachinePoolReconciler) reconcileInfrastructure(ctx context.Context, clu
 	)
 
 	if !mp.Status.InfrastructureReady {
-		log.Info("Infrastructure provider is not ready, requeuing")
-		return ctrl.Result{RequeueAfter: externalReadyWait}, nil
+		requeueAfter := external.RequeueAfterFrom(log, infraConfig, externalReadyWait)
+		log.Info("Infrastructure provider is not ready, requeuing", "requeueAfter", requeueAfter)
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// Report which bootstrap data the instances have been created with.
+	if err := reconcileInstanceBootstrapData(mp, infraConfig); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Report how the instances are spread across failure domains.
+	if err := reconcileFailureDomainReplicas(mp, infraConfig); err != nil {
+		return ctrl.Result{}, err
 	}
 
 	var providerIDList []string
@@ -22,6 +22,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	"sigs.k8s.io/cluster-api/feature"
 	"sigs.k8s.io/cluster-api/test/helpers"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -47,6 +48,8 @@ func TestAPIs(t *testing.T) {
 
 var _ = BeforeSuite(func() {
 	By("bootstrapping test environment")
+	// The controllers ignore the requests while their feature gate is disabled.
+	Expect(feature.MutableGates.SetFromMap(map[string]bool{string(feature.MachinePool): true})).To(Succeed())
 	testEnv = helpers.NewTestEnvironment()
 
 	Expect((&MachinePoolReconciler{
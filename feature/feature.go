@@ -33,6 +33,18 @@ const (
 
 	// alpha: v0.3
 	ClusterResourceSet featuregate.Feature = "ClusterResourceSet"
+
+	// alpha: v0.4
+	NodeMatchingFallback featuregate.Feature = "NodeMatchingFallback"
+
+	// alpha: v0.4
+	FleetView featuregate.Feature = "FleetView"
+
+	// alpha: v0.4
+	ClusterGroup featuregate.Feature = "ClusterGroup"
+
+	// alpha: v0.4
+	ClusterAPIQuota featuregate.Feature = "ClusterAPIQuota"
 )
 
 func init() {
@@ -43,6 +55,10 @@ func init() {
 // To add a new feature, define a key for it above and add it here.
 var defaultClusterAPIFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
 	// Every feature should be initiated here:
-	MachinePool:        {Default: false, PreRelease: featuregate.Alpha},
-	ClusterResourceSet: {Default: false, PreRelease: featuregate.Alpha},
+	MachinePool:          {Default: false, PreRelease: featuregate.Alpha},
+	ClusterResourceSet:   {Default: false, PreRelease: featuregate.Alpha},
+	NodeMatchingFallback: {Default: false, PreRelease: featuregate.Alpha},
+	FleetView:            {Default: false, PreRelease: featuregate.Alpha},
+	ClusterGroup:         {Default: false, PreRelease: featuregate.Alpha},
+	ClusterAPIQuota:      {Default: false, PreRelease: featuregate.Alpha},
 }
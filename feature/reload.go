@@ -0,0 +1,207 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/component-base/featuregate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ConfigMapDataKey is the key of the ConfigMap data defining the feature gates, using the same
+// format as the --feature-gates flag, e.g. "MachinePool=true,ClusterResourceSet=false".
+const ConfigMapDataKey = "feature-gates"
+
+// DynamicGates are the feature gates that can be enabled and disabled at runtime, without restarting the manager.
+var DynamicGates = []featuregate.Feature{MachinePool, ClusterResourceSet}
+
+// GatedReconciler returns a reconciler ignoring the requests while the given gate is disabled; given that controllers
+// can't be stopped once started, this is used to pause the controllers of the dynamic gates disabled at runtime.
+func GatedReconciler(gate featuregate.Feature, r reconcile.Reconciler) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+		if !Gates.Enabled(gate) {
+			return reconcile.Result{}, nil
+		}
+		return r.Reconcile(ctx, req)
+	})
+}
+
+// GatedWebhookHandler returns an admission handler allowing the requests, without changes, while the given gate is
+// disabled; given that webhooks can't be unregistered once registered, this is used to pause the webhooks of the dynamic
+// gates disabled at runtime, the same way GatedReconciler does for their controllers.
+func GatedWebhookHandler(gate featuregate.Feature, h admission.Handler) admission.Handler {
+	return &gatedWebhookHandler{gate: gate, handler: h}
+}
+
+type gatedWebhookHandler struct {
+	gate    featuregate.Feature
+	handler admission.Handler
+}
+
+var _ admission.DecoderInjector = &gatedWebhookHandler{}
+var _ inject.Injector = &gatedWebhookHandler{}
+
+// Handle implements admission.Handler.
+func (h *gatedWebhookHandler) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if !Gates.Enabled(h.gate) {
+		return admission.Allowed("feature gate " + string(h.gate) + " is disabled")
+	}
+	return h.handler.Handle(ctx, req)
+}
+
+// InjectDecoder implements admission.DecoderInjector, passing the decoder to the wrapped handler.
+func (h *gatedWebhookHandler) InjectDecoder(d *admission.Decoder) error {
+	_, err := admission.InjectDecoderInto(d, h.handler)
+	return err
+}
+
+// InjectFunc implements inject.Injector, passing the dependencies to the wrapped handler.
+func (h *gatedWebhookHandler) InjectFunc(f inject.Func) error {
+	return f(h.handler)
+}
+
+// ConfigMapReloader periodically reads the dynamic feature gates from a ConfigMap and applies them to MutableGates.
+type ConfigMapReloader struct {
+	// Client is used to read the ConfigMap.
+	Client client.Reader
+
+	// Key identifies the ConfigMap defining the feature gates.
+	Key client.ObjectKey
+
+	// Interval is the time between two reads of the ConfigMap.
+	Interval time.Duration
+
+	// OnEnabled are called the first time the corresponding gates are enabled at runtime, e.g. to set up
+	// their controllers and webhooks; they are not called for the gates already enabled at startup.
+	OnEnabled map[featuregate.Feature]func() error
+
+	Log logr.Logger
+
+	// setUp records the gates whose OnEnabled func has been called, or which were enabled at startup.
+	setUp map[featuregate.Feature]bool
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; the feature gates are reloaded on every replica,
+// given that they affect the webhooks too.
+func (r *ConfigMapReloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable.
+func (r *ConfigMapReloader) Start(ctx context.Context) error {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.reload(ctx); err != nil {
+			r.Log.Error(err, "Failed to reload feature gates", "configmap", r.Key.String())
+		}
+	}, r.Interval)
+	return nil
+}
+
+func (r *ConfigMapReloader) reload(ctx context.Context) error {
+	if r.setUp == nil {
+		r.setUp = map[featuregate.Feature]bool{}
+		for _, gate := range DynamicGates {
+			r.setUp[gate] = Gates.Enabled(gate)
+		}
+	}
+
+	cm := &corev1.ConfigMap{}
+	if err := r.Client.Get(ctx, r.Key, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return errors.Wrapf(err, "failed to get ConfigMap %s", r.Key)
+	}
+
+	gates, err := parseDynamicGates(cm.Data[ConfigMapDataKey])
+	if err != nil {
+		return errors.Wrapf(err, "invalid %q in ConfigMap %s", ConfigMapDataKey, r.Key)
+	}
+
+	changed := map[string]bool{}
+	for gate, enabled := range gates {
+		if Gates.Enabled(gate) != enabled {
+			changed[string(gate)] = enabled
+		}
+	}
+	if len(changed) > 0 {
+		if err := MutableGates.SetFromMap(changed); err != nil {
+			return err
+		}
+		r.Log.Info("Reloaded feature gates", "changed", changed)
+	}
+
+	// Set up the gates enabled for the first time; this is retried at the next reload in case of errors.
+	for _, gate := range DynamicGates {
+		if !Gates.Enabled(gate) || r.setUp[gate] {
+			continue
+		}
+		if fn := r.OnEnabled[gate]; fn != nil {
+			if err := fn(); err != nil {
+				return errors.Wrapf(err, "failed to set up feature gate %s", gate)
+			}
+		}
+		r.setUp[gate] = true
+	}
+	return nil
+}
+
+// parseDynamicGates parses a comma separated list of gate=bool pairs, failing for the gates which are not dynamic.
+func parseDynamicGates(value string) (map[featuregate.Feature]bool, error) {
+	gates := map[featuregate.Feature]bool{}
+	for _, s := range strings.Split(value, ",") {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		kv := strings.SplitN(s, "=", 2)
+		if len(kv) != 2 {
+			return nil, errors.Errorf("missing bool value for %s", s)
+		}
+		gate := featuregate.Feature(strings.TrimSpace(kv[0]))
+		if !isDynamic(gate) {
+			return nil, errors.Errorf("feature gate %s can't be changed at runtime", gate)
+		}
+		enabled, err := strconv.ParseBool(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value of %s", gate)
+		}
+		gates[gate] = enabled
+	}
+	return gates, nil
+}
+
+func isDynamic(gate featuregate.Feature) bool {
+	for _, g := range DynamicGates {
+		if g == gate {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package feature
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/component-base/featuregate"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+func TestParseDynamicGates(t *testing.T) {
+	tests := []struct {
+		name      string
+		value     string
+		want      map[featuregate.Feature]bool
+		expectErr bool
+	}{
+		{
+			name:  "empty value",
+			value: "",
+			want:  map[featuregate.Feature]bool{},
+		},
+		{
+			name:  "dynamic gates",
+			value: "MachinePool=true, ClusterResourceSet=false",
+			want:  map[featuregate.Feature]bool{MachinePool: true, ClusterResourceSet: false},
+		},
+		{
+			name:      "gate which can't be changed at runtime",
+			value:     "NodeMatchingFallback=true",
+			expectErr: true,
+		},
+		{
+			name:      "missing value",
+			value:     "MachinePool",
+			expectErr: true,
+		},
+		{
+			name:      "invalid value",
+			value:     "MachinePool=yes",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := parseDynamicGates(tt.value)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(got).To(Equal(tt.want))
+		})
+	}
+}
+
+func TestConfigMapReloader(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+
+	defer func() {
+		g.Expect(MutableGates.SetFromMap(map[string]bool{string(MachinePool): false, string(ClusterResourceSet): false})).To(Succeed())
+	}()
+
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "capi-system", Name: "feature-gates"},
+		Data:       map[string]string{ConfigMapDataKey: "MachinePool=true"},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(cm).Build()
+
+	setUpCalls := 0
+	var setUpErr error
+	r := &ConfigMapReloader{
+		Client: c,
+		Key:    client.ObjectKeyFromObject(cm),
+		OnEnabled: map[featuregate.Feature]func() error{
+			MachinePool: func() error {
+				setUpCalls++
+				return setUpErr
+			},
+		},
+		Log: log.NullLogger{},
+	}
+
+	reconciled := false
+	gated := GatedReconciler(MachinePool, reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+		reconciled = true
+		return reconcile.Result{}, nil
+	}))
+	gatedWebhook := GatedWebhookHandler(MachinePool, admission.HandlerFunc(func(context.Context, admission.Request) admission.Response {
+		return admission.Denied("invalid")
+	}))
+
+	// The gate is enabled and set up.
+	setUpErr = errors.New("boom")
+	g.Expect(r.reload(ctx)).NotTo(Succeed())
+	g.Expect(Gates.Enabled(MachinePool)).To(BeTrue())
+
+	// Failed set ups are retried.
+	setUpErr = nil
+	g.Expect(r.reload(ctx)).To(Succeed())
+	g.Expect(setUpCalls).To(Equal(2))
+	_, _ = gated.Reconcile(ctx, reconcile.Request{})
+	g.Expect(reconciled).To(BeTrue())
+	g.Expect(gatedWebhook.Handle(ctx, admission.Request{}).Allowed).To(BeFalse())
+
+	// The gate is disabled, and its controllers and webhooks ignore the requests.
+	cm.Data[ConfigMapDataKey] = "MachinePool=false"
+	g.Expect(c.Update(ctx, cm)).To(Succeed())
+	g.Expect(r.reload(ctx)).To(Succeed())
+	g.Expect(Gates.Enabled(MachinePool)).To(BeFalse())
+	reconciled = false
+	_, _ = gated.Reconcile(ctx, reconcile.Request{})
+	g.Expect(reconciled).To(BeFalse())
+	g.Expect(gatedWebhook.Handle(ctx, admission.Request{}).Allowed).To(BeTrue())
+
+	// The gate is enabled again, without setting it up twice.
+	cm.Data[ConfigMapDataKey] = "MachinePool=true"
+	g.Expect(c.Update(ctx, cm)).To(Succeed())
+	g.Expect(r.reload(ctx)).To(Succeed())
+	g.Expect(Gates.Enabled(MachinePool)).To(BeTrue())
+	g.Expect(setUpCalls).To(Equal(2))
+	_, _ = gated.Reconcile(ctx, reconcile.Request{})
+	g.Expect(reconciled).To(BeTrue())
+	g.Expect(gatedWebhook.Handle(ctx, admission.Request{}).Allowed).To(BeFalse())
+
+	// Invalid values are rejected, without changing the gates.
+	cm.Data[ConfigMapDataKey] = "MachinePool=false,NodeMatchingFallback=true"
+	g.Expect(c.Update(ctx, cm)).To(Succeed())
+	g.Expect(r.reload(ctx)).NotTo(Succeed())
+	g.Expect(Gates.Enabled(MachinePool)).To(BeTrue())
+}
+
+type decoderHandler struct {
+	admission.Handler
+	decoder *admission.Decoder
+}
+
+func (h *decoderHandler) InjectDecoder(d *admission.Decoder) error {
+	h.decoder = d
+	return nil
+}
+
+func TestGatedWebhookHandlerInjectsDecoder(t *testing.T) {
+	g := NewWithT(t)
+
+	h := &decoderHandler{}
+	w := &admission.Webhook{Handler: GatedWebhookHandler(MachinePool, h)}
+	g.Expect(w.InjectScheme(scheme.Scheme)).To(Succeed())
+	g.Expect(h.decoder).NotTo(BeNil())
+}
@@ -0,0 +1,65 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// admission-policy-gen generates the ValidatingAdmissionPolicies equivalent to a subset
+// of the validations implemented by the Cluster API webhooks.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"sigs.k8s.io/cluster-api/internal/admissionpolicy"
+)
+
+var (
+	provider = flag.String("provider", "core", "The provider to generate the policies for, one of core, kubeadm-control-plane.")
+	output   = flag.String("output", "", "The file to write the policies to; if empty, the policies are written to stdout.")
+)
+
+func main() {
+	flag.Parse()
+
+	if err := run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	var policies []admissionpolicy.Policy
+	switch *provider {
+	case "core":
+		policies = admissionpolicy.CorePolicies
+	case "kubeadm-control-plane":
+		policies = admissionpolicy.KubeadmControlPlanePolicies
+	default:
+		return fmt.Errorf("unknown provider %q", *provider)
+	}
+
+	data, err := admissionpolicy.Generate(policies)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(*output, data, 0600)
+}
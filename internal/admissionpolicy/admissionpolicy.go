@@ -0,0 +1,130 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package admissionpolicy implements the generation of CEL-based ValidatingAdmissionPolicies
+// equivalent to a subset of the validations implemented by the Cluster API webhooks.
+package admissionpolicy
+
+import (
+	"bytes"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// APIVersion is the API version of the generated ValidatingAdmissionPolicies and bindings.
+	APIVersion = "admissionregistration.k8s.io/v1"
+
+	// Header is prepended to the generated manifests.
+	Header = "# Code generated by admission-policy-gen. DO NOT EDIT.\n"
+
+	operationCreate = "CREATE"
+	operationUpdate = "UPDATE"
+)
+
+// Validation is a CEL expression that must evaluate to true for a request to be admitted.
+type Validation struct {
+	// Expression is the CEL expression; object and oldObject are available as in the webhooks.
+	Expression string
+
+	// Message is returned to the user when the expression evaluates to false.
+	Message string
+}
+
+// Policy defines a ValidatingAdmissionPolicy and the binding enforcing it.
+type Policy struct {
+	// Name is the name of the policy and of its binding.
+	Name string
+
+	// APIGroup is the API group of the validated resources.
+	APIGroup string
+
+	// APIVersions are the API versions of the validated resources.
+	APIVersions []string
+
+	// Resources are the validated resources, e.g. machines.
+	Resources []string
+
+	// Operations are the validated operations, e.g. CREATE and UPDATE.
+	Operations []string
+
+	// Validations are the validations enforced by the policy.
+	Validations []Validation
+}
+
+// Generate returns the YAML manifests of the ValidatingAdmissionPolicies and bindings for the given policies.
+func Generate(policies []Policy) ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteString(Header)
+	for _, p := range policies {
+		for _, obj := range []interface{}{policyObject(p), bindingObject(p)} {
+			data, err := yaml.Marshal(obj)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to marshal ValidatingAdmissionPolicy %q", p.Name)
+			}
+			buf.WriteString("---\n")
+			buf.Write(data)
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func policyObject(p Policy) map[string]interface{} {
+	validations := make([]interface{}, 0, len(p.Validations))
+	for _, v := range p.Validations {
+		validations = append(validations, map[string]interface{}{
+			"expression": v.Expression,
+			"message":    v.Message,
+		})
+	}
+
+	return map[string]interface{}{
+		"apiVersion": APIVersion,
+		"kind":       "ValidatingAdmissionPolicy",
+		"metadata": map[string]interface{}{
+			"name": p.Name,
+		},
+		"spec": map[string]interface{}{
+			"failurePolicy": "Fail",
+			"matchConstraints": map[string]interface{}{
+				"resourceRules": []interface{}{
+					map[string]interface{}{
+						"apiGroups":   []string{p.APIGroup},
+						"apiVersions": p.APIVersions,
+						"operations":  p.Operations,
+						"resources":   p.Resources,
+					},
+				},
+			},
+			"validations": validations,
+		},
+	}
+}
+
+func bindingObject(p Policy) map[string]interface{} {
+	return map[string]interface{}{
+		"apiVersion": APIVersion,
+		"kind":       "ValidatingAdmissionPolicyBinding",
+		"metadata": map[string]interface{}{
+			"name": p.Name,
+		},
+		"spec": map[string]interface{}{
+			"policyName":        p.Name,
+			"validationActions": []string{"Deny"},
+		},
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"io/ioutil"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+)
+
+func TestGenerate(t *testing.T) {
+	g := NewWithT(t)
+
+	data, err := Generate([]Policy{
+		{
+			Name:        "test-policy",
+			APIGroup:    "cluster.x-k8s.io",
+			APIVersions: []string{"v1alpha4"},
+			Resources:   []string{"machines"},
+			Operations:  []string{operationUpdate},
+			Validations: []Validation{{Expression: "object.spec.clusterName == oldObject.spec.clusterName", Message: "immutable"}},
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	objs, err := utilyaml.ToUnstructured(data)
+	g.Expect(err).NotTo(HaveOccurred())
+	g.Expect(objs).To(HaveLen(2))
+
+	g.Expect(objs[0].GetKind()).To(Equal("ValidatingAdmissionPolicy"))
+	g.Expect(objs[0].GetName()).To(Equal("test-policy"))
+	g.Expect(objs[0].Object["spec"]).To(HaveKeyWithValue("validations", ConsistOf(
+		map[string]interface{}{"expression": "object.spec.clusterName == oldObject.spec.clusterName", "message": "immutable"},
+	)))
+
+	g.Expect(objs[1].GetKind()).To(Equal("ValidatingAdmissionPolicyBinding"))
+	g.Expect(objs[1].Object["spec"]).To(HaveKeyWithValue("policyName", "test-policy"))
+}
+
+func TestGeneratedPoliciesUpToDate(t *testing.T) {
+	tests := []struct {
+		file     string
+		policies []Policy
+	}{
+		{file: "../../config/admissionpolicy/policies.yaml", policies: CorePolicies},
+		{file: "../../controlplane/kubeadm/config/admissionpolicy/policies.yaml", policies: KubeadmControlPlanePolicies},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			g := NewWithT(t)
+
+			want, err := Generate(tt.policies)
+			g.Expect(err).NotTo(HaveOccurred())
+			got, err := ioutil.ReadFile(tt.file)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(got)).To(Equal(string(want)), "policies are out of date, run make generate-manifests")
+		})
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package admissionpolicy
+
+import (
+	"fmt"
+
+	"sigs.k8s.io/cluster-api/util/version"
+)
+
+// NOTE: The policies are a safety net for when the webhooks are not available, and they are evaluated
+// before the defaulting webhooks have run; for this reason optional fields, which are usually defaulted,
+// are only validated if set, and versions are accepted with or without the "v" prefix.
+
+// versionExpression returns a CEL expression validating the Kubernetes version at the given path, if set.
+func versionExpression(parent, field string) string {
+	return fmt.Sprintf("!has(%s.%s) || %s.%s.matches(r'%s')", parent, field, parent, field, version.KubeSemverTolerant.String())
+}
+
+// namespaceExpression returns a CEL expression validating that the reference at the given path,
+// if set and with a namespace, is in the namespace of the object.
+func namespaceExpression(parent, ref string) string {
+	return fmt.Sprintf("!has(%s.%s) || !has(%s.%s.namespace) || %s.%s.namespace == object.metadata.namespace",
+		parent, ref, parent, ref, parent, ref)
+}
+
+// CorePolicies are the policies for the core Cluster API types.
+var CorePolicies = []Policy{
+	{
+		Name:        "cluster-api-cluster-references",
+		APIGroup:    "cluster.x-k8s.io",
+		APIVersions: []string{"v1alpha4"},
+		Resources:   []string{"clusters"},
+		Operations:  []string{operationCreate, operationUpdate},
+		Validations: []Validation{
+			{
+				Expression: namespaceExpression("object.spec", "infrastructureRef"),
+				Message:    "spec.infrastructureRef.namespace must match metadata.namespace",
+			},
+			{
+				Expression: namespaceExpression("object.spec", "controlPlaneRef"),
+				Message:    "spec.controlPlaneRef.namespace must match metadata.namespace",
+			},
+		},
+	},
+	{
+		Name:        "cluster-api-machine",
+		APIGroup:    "cluster.x-k8s.io",
+		APIVersions: []string{"v1alpha4"},
+		Resources:   []string{"machines"},
+		Operations:  []string{operationCreate, operationUpdate},
+		Validations: []Validation{
+			{
+				Expression: namespaceExpression("object.spec", "infrastructureRef"),
+				Message:    "spec.infrastructureRef.namespace must match metadata.namespace",
+			},
+			{
+				Expression: namespaceExpression("object.spec.bootstrap", "configRef"),
+				Message:    "spec.bootstrap.configRef.namespace must match metadata.namespace",
+			},
+			{
+				Expression: versionExpression("object.spec", "version"),
+				Message:    "spec.version must be a valid semantic version",
+			},
+		},
+	},
+	{
+		Name:        "cluster-api-immutable-cluster-name",
+		APIGroup:    "cluster.x-k8s.io",
+		APIVersions: []string{"v1alpha4"},
+		Resources:   []string{"machines", "machinesets", "machinedeployments"},
+		Operations:  []string{operationUpdate},
+		Validations: []Validation{
+			{
+				Expression: "object.spec.clusterName == oldObject.spec.clusterName",
+				Message:    "spec.clusterName is immutable",
+			},
+		},
+	},
+}
+
+// KubeadmControlPlanePolicies are the policies for the KubeadmControlPlane types.
+var KubeadmControlPlanePolicies = []Policy{
+	{
+		Name:        "kubeadm-control-plane",
+		APIGroup:    "controlplane.cluster.x-k8s.io",
+		APIVersions: []string{"v1alpha4"},
+		Resources:   []string{"kubeadmcontrolplanes"},
+		Operations:  []string{operationCreate, operationUpdate},
+		Validations: []Validation{
+			{
+				Expression: "!has(object.spec.replicas) || object.spec.replicas > 0",
+				Message:    "spec.replicas cannot be less than or equal to 0",
+			},
+			{
+				Expression: "!has(object.spec.replicas) || object.spec.replicas % 2 == 1 || " +
+					"(has(object.spec.kubeadmConfigSpec.clusterConfiguration) && has(object.spec.kubeadmConfigSpec.clusterConfiguration.etcd) && " +
+					"has(object.spec.kubeadmConfigSpec.clusterConfiguration.etcd.external))",
+				Message: "spec.replicas cannot be an even number when using managed etcd",
+			},
+			{
+				Expression: versionExpression("object.spec", "version"),
+				Message:    "spec.version must be a valid semantic version",
+			},
+		},
+	},
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package kubernetesversion implements the window of the workload cluster Kubernetes versions accepted by the
+// webhooks, configured with the flags of the managers.
+package kubernetesversion
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/version"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+// Policy defines how the webhooks handle Kubernetes versions outside of the supported window.
+type Policy string
+
+const (
+	// PolicyEnforce rejects Kubernetes versions outside of the supported window.
+	PolicyEnforce Policy = "Enforce"
+
+	// PolicyWarn accepts Kubernetes versions outside of the supported window, logging a warning.
+	PolicyWarn Policy = "Warn"
+)
+
+// Window defines the workload cluster Kubernetes versions accepted by the webhooks.
+type Window struct {
+	// Min is the minimum supported Kubernetes minor version, e.g. v1.18; if empty, there is no minimum version.
+	Min string
+
+	// Max is the maximum supported Kubernetes minor version, e.g. v1.21; if empty, there is no maximum version.
+	Max string
+
+	// Policy defines how versions outside of the window are handled; defaults to Enforce.
+	Policy Policy
+}
+
+// window is the parsed Window set by the manager, shared by the webhooks of the API types.
+var window = struct {
+	sync.RWMutex
+	min    *version.Version
+	max    *version.Version
+	policy Policy
+}{policy: PolicyEnforce}
+
+// Set sets the window of the Kubernetes versions accepted by the webhooks.
+func Set(s Window) error {
+	parse := func(v string) (*version.Version, error) {
+		if v == "" {
+			return nil, nil
+		}
+		parsed, err := version.ParseGeneric(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid Kubernetes version %q", v)
+		}
+		return minorVersion(parsed), nil
+	}
+
+	minVersion, err := parse(s.Min)
+	if err != nil {
+		return err
+	}
+	maxVersion, err := parse(s.Max)
+	if err != nil {
+		return err
+	}
+	if minVersion != nil && maxVersion != nil && maxVersion.LessThan(minVersion) {
+		return errors.Errorf("invalid Kubernetes version window: maximum version %q is lower than minimum version %q", s.Max, s.Min)
+	}
+
+	policy := s.Policy
+	switch policy {
+	case "":
+		policy = PolicyEnforce
+	case PolicyEnforce, PolicyWarn:
+	default:
+		return errors.Errorf("invalid Kubernetes version policy %q, must be one of %q or %q", policy, PolicyEnforce, PolicyWarn)
+	}
+
+	window.Lock()
+	defer window.Unlock()
+	window.min, window.max, window.policy = minVersion, maxVersion, policy
+	return nil
+}
+
+// Validate validates a Kubernetes version against the supported window; versions are validated only when they
+// are set or changed, so objects already using an unsupported version can still be updated.
+// Invalid versions are left to the other validations.
+func Validate(fldPath *field.Path, newVersion, oldVersion *string) *field.Error {
+	if newVersion == nil || (oldVersion != nil && *oldVersion == *newVersion) {
+		return nil
+	}
+
+	window.RLock()
+	minVersion, maxVersion, policy := window.min, window.max, window.policy
+	window.RUnlock()
+	if minVersion == nil && maxVersion == nil {
+		return nil
+	}
+
+	v, err := version.ParseSemantic(*newVersion)
+	if err != nil {
+		return nil
+	}
+	minor := minorVersion(v)
+
+	var msg string
+	switch {
+	case minVersion != nil && minor.LessThan(minVersion):
+		msg = fmt.Sprintf("Kubernetes versions older than v%s are not supported", minVersion)
+	case maxVersion != nil && maxVersion.LessThan(minor):
+		msg = fmt.Sprintf("Kubernetes versions newer than v%s are not supported", maxVersion)
+	default:
+		return nil
+	}
+
+	if policy == PolicyWarn {
+		ctrl.Log.WithName("webhooks").Info("Warning: unsupported Kubernetes version", "field", fldPath.String(), "version", *newVersion, "reason", msg)
+		return nil
+	}
+	return field.Forbidden(fldPath, msg)
+}
+
+// minorVersion returns the major.minor version of a version, dropping the patch version and any pre-release or build.
+func minorVersion(v *version.Version) *version.Version {
+	return version.MustParseGeneric(fmt.Sprintf("%d.%d", v.Major(), v.Minor()))
+}
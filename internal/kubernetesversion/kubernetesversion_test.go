@@ -0,0 +1,122 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kubernetesversion
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/pointer"
+)
+
+func TestSet(t *testing.T) {
+	defer func() { _ = Set(Window{}) }()
+
+	tests := []struct {
+		name    string
+		s       Window
+		wantErr bool
+	}{
+		{name: "empty window", s: Window{}},
+		{name: "minimum and maximum versions", s: Window{Min: "v1.18", Max: "v1.21.2", Policy: PolicyWarn}},
+		{name: "invalid version", s: Window{Min: "foo"}, wantErr: true},
+		{name: "maximum lower than minimum", s: Window{Min: "v1.20", Max: "v1.19"}, wantErr: true},
+		{name: "invalid policy", s: Window{Policy: "Ignore"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			err := Set(tt.s)
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	defer func() { _ = Set(Window{}) }()
+
+	fldPath := field.NewPath("spec", "version")
+	tests := []struct {
+		name       string
+		s          Window
+		newVersion *string
+		oldVersion *string
+		wantErr    bool
+	}{
+		{
+			name:       "accepts any version without a window",
+			newVersion: pointer.StringPtr("v1.15.0"),
+		},
+		{
+			name:       "accepts versions inside the window",
+			s:          Window{Min: "v1.18", Max: "v1.20"},
+			newVersion: pointer.StringPtr("v1.20.4"),
+		},
+		{
+			name:       "rejects versions older than the minimum version",
+			s:          Window{Min: "v1.18"},
+			newVersion: pointer.StringPtr("v1.17.9"),
+			wantErr:    true,
+		},
+		{
+			name:       "rejects versions newer than the maximum version",
+			s:          Window{Max: "v1.20"},
+			newVersion: pointer.StringPtr("v1.21.0"),
+			wantErr:    true,
+		},
+		{
+			name:       "accepts versions outside of the window with the Warn policy",
+			s:          Window{Min: "v1.18", Policy: PolicyWarn},
+			newVersion: pointer.StringPtr("v1.17.9"),
+		},
+		{
+			name:       "accepts unchanged versions",
+			s:          Window{Min: "v1.18"},
+			newVersion: pointer.StringPtr("v1.17.9"),
+			oldVersion: pointer.StringPtr("v1.17.9"),
+		},
+		{
+			name: "accepts unset versions",
+			s:    Window{Min: "v1.18"},
+		},
+		{
+			name:       "leaves invalid versions to the other validations",
+			s:          Window{Min: "v1.18"},
+			newVersion: pointer.StringPtr("foo"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+			g.Expect(Set(tt.s)).To(Succeed())
+
+			err := Validate(fldPath, tt.newVersion, tt.oldVersion)
+			if tt.wantErr {
+				g.Expect(err).NotTo(BeNil())
+				g.Expect(err.Field).To(Equal("spec.version"))
+				return
+			}
+			g.Expect(err).To(BeNil())
+		})
+	}
+}
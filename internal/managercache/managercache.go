@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package managercache implements the cache options shared by the managers.
+package managercache
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// ForNamespaces returns the namespace and the cache constructor of a manager watching the given namespaces, as set
+// with --namespace. With several namespaces, the manager runs one cache per namespace, so that it only needs
+// permissions in them.
+func ForNamespaces(namespaces []string) (string, cache.NewCacheFunc) {
+	switch len(namespaces) {
+	case 0:
+		return "", nil
+	case 1:
+		return namespaces[0], nil
+	default:
+		return "", cache.MultiNamespacedCacheBuilder(namespaces)
+	}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package managercache
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestForNamespaces(t *testing.T) {
+	t.Run("all namespaces", func(t *testing.T) {
+		g := NewWithT(t)
+
+		namespace, newCache := ForNamespaces(nil)
+		g.Expect(namespace).To(BeEmpty())
+		g.Expect(newCache).To(BeNil())
+	})
+
+	t.Run("single namespace", func(t *testing.T) {
+		g := NewWithT(t)
+
+		namespace, newCache := ForNamespaces([]string{"tenant-a"})
+		g.Expect(namespace).To(Equal("tenant-a"))
+		g.Expect(newCache).To(BeNil())
+	})
+
+	t.Run("several namespaces", func(t *testing.T) {
+		g := NewWithT(t)
+
+		namespace, newCache := ForNamespaces([]string{"tenant-a", "tenant-b"})
+		g.Expect(namespace).To(BeEmpty())
+		g.Expect(newCache).NotTo(BeNil())
+
+		scheme := runtime.NewScheme()
+		g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+		c, err := newCache(&rest.Config{Host: "https://management.example.com"}, cache.Options{
+			Scheme: scheme,
+			Mapper: meta.NewDefaultRESTMapper(nil),
+		})
+		g.Expect(err).NotTo(HaveOccurred())
+		// Reading from a namespace which is not watched fails before any request to the API server.
+		err = c.Get(context.Background(), client.ObjectKey{Namespace: "tenant-c", Name: "foo"}, &corev1.ConfigMap{})
+		g.Expect(err).To(MatchError(ContainSubstring("unknown namespace for the cache")))
+	})
+}
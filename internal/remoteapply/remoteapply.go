@@ -0,0 +1,279 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package remoteapply implements utils for applying sets of resources to workload clusters.
+package remoteapply
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"sort"
+	"strconv"
+	"unicode"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kerrors "k8s.io/apimachinery/pkg/util/errors"
+	utilresource "sigs.k8s.io/cluster-api/util/resource"
+	utilyaml "sigs.k8s.io/cluster-api/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// DefaultFieldManager is the field manager used for server-side apply when none is specified.
+	DefaultFieldManager = "cluster-api"
+
+	// WaveAnnotation is the annotation that can be set on the resources being applied to define the order
+	// they are applied in; resources are applied in ascending wave order, and resources without the annotation
+	// belong to wave 0.
+	WaveAnnotation = "remoteapply.cluster.x-k8s.io/wave"
+)
+
+var jsonListPrefix = []byte("[")
+
+// Options defines how resources are applied to a cluster.
+type Options struct {
+	// ServerSideApply applies the resources using server-side apply, updating the resources already existing
+	// in the cluster; otherwise resources are only created, and the resources already existing are left untouched.
+	ServerSideApply bool
+
+	// FieldManager is the name of the field manager used for server-side apply; defaults to DefaultFieldManager.
+	FieldManager string
+
+	// PruneLabels are set on all the applied resources; if set, after all the resources have been applied,
+	// the resources with the same labels and of the same kind of the applied resources (or of PruneTypes),
+	// which are not part of the resource set anymore, are deleted.
+	PruneLabels map[string]string
+
+	// PruneTypes are additional types that are considered for pruning, e.g. because all the resources of
+	// this type could have been removed from the resource set.
+	PruneTypes []schema.GroupVersionKind
+}
+
+// ParseObjects converts data in YAML, JSON or JSON list format into a list of unstructured objects.
+func ParseObjects(data []byte) ([]unstructured.Unstructured, error) {
+	isJSONList, err := isJSONList(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// If it is not a json list, data is either json or yaml format.
+	if !isJSONList {
+		objs, err := utilyaml.ToUnstructured(data)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed converting data to unstructured objects")
+		}
+		return objs, nil
+	}
+
+	// If it is a json list, convert each list element to an unstructured object.
+	var results []map[string]interface{}
+	if err := json.Unmarshal(data, &results); err != nil {
+		return nil, errors.Wrapf(err, "failed converting data to unstructured objects")
+	}
+	objs := make([]unstructured.Unstructured, 0, len(results))
+	for i := range results {
+		var u unstructured.Unstructured
+		u.SetUnstructuredContent(results[i])
+		objs = append(objs, u)
+	}
+	return objs, nil
+}
+
+// isJSONList returns whether the data is in JSON list format.
+func isJSONList(data []byte) (bool, error) {
+	const peekSize = 32
+	buffer := bufio.NewReaderSize(bytes.NewReader(data), peekSize)
+	b, err := buffer.Peek(peekSize)
+	if err != nil {
+		return false, err
+	}
+	trim := bytes.TrimLeftFunc(b, unicode.IsSpace)
+	return bytes.HasPrefix(trim, jsonListPrefix), nil
+}
+
+// ValidateObjects checks that the objects can be applied, i.e. that they have an apiVersion, a kind and a name, and
+// that their WaveAnnotation, if any, is an integer; it does not validate the objects against their schema.
+func ValidateObjects(objs []unstructured.Unstructured) error {
+	errList := []error{}
+	for i := range objs {
+		obj := &objs[i]
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			errList = append(errList, errors.Errorf("object %d: apiVersion and kind must be set", i))
+			continue
+		}
+		if obj.GetName() == "" && obj.GetGenerateName() == "" {
+			errList = append(errList, errors.Errorf("%s %d: metadata.name must be set", obj.GetKind(), i))
+		}
+	}
+	if _, err := groupByWave(objs); err != nil {
+		errList = append(errList, err)
+	}
+	return kerrors.NewAggregate(errList)
+}
+
+// ApplyData parses data in YAML, JSON or JSON list format and applies the resulting objects to the cluster.
+func ApplyData(ctx context.Context, c client.Client, data []byte, opts Options) error {
+	objs, err := ParseObjects(data)
+	if err != nil {
+		return err
+	}
+	return Apply(ctx, c, objs, opts)
+}
+
+// Apply applies objects to the cluster, wave by wave; within a wave, objects are sorted by creation priority
+// and all of them are applied even if some fail, while the following waves are applied only if all the
+// objects in the previous waves have been applied successfully.
+func Apply(ctx context.Context, c client.Client, objs []unstructured.Unstructured, opts Options) error {
+	waves, err := groupByWave(objs)
+	if err != nil {
+		return err
+	}
+
+	for _, wave := range waves {
+		errList := []error{}
+		for _, obj := range utilresource.SortForCreate(wave) {
+			obj := obj
+			if err := applyObject(ctx, c, &obj, opts); err != nil {
+				errList = append(errList, err)
+			}
+		}
+		if len(errList) > 0 {
+			return kerrors.NewAggregate(errList)
+		}
+	}
+
+	if len(opts.PruneLabels) == 0 {
+		return nil
+	}
+	return prune(ctx, c, objs, opts)
+}
+
+// groupByWave groups objects by the wave defined in their WaveAnnotation, in ascending wave order.
+func groupByWave(objs []unstructured.Unstructured) ([][]unstructured.Unstructured, error) {
+	byWave := map[int][]unstructured.Unstructured{}
+	for _, obj := range objs {
+		wave := 0
+		if value, ok := obj.GetAnnotations()[WaveAnnotation]; ok {
+			var err error
+			if wave, err = strconv.Atoi(value); err != nil {
+				return nil, errors.Wrapf(err, "invalid %s annotation on %s %s/%s", WaveAnnotation, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+			}
+		}
+		byWave[wave] = append(byWave[wave], obj)
+	}
+
+	waveNumbers := make([]int, 0, len(byWave))
+	for wave := range byWave {
+		waveNumbers = append(waveNumbers, wave)
+	}
+	sort.Ints(waveNumbers)
+
+	waves := make([][]unstructured.Unstructured, 0, len(waveNumbers))
+	for _, wave := range waveNumbers {
+		waves = append(waves, byWave[wave])
+	}
+	return waves, nil
+}
+
+// applyObject applies a single object to the cluster.
+func applyObject(ctx context.Context, c client.Client, obj *unstructured.Unstructured, opts Options) error {
+	if len(opts.PruneLabels) > 0 {
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		for k, v := range opts.PruneLabels {
+			labels[k] = v
+		}
+		obj.SetLabels(labels)
+	}
+
+	if opts.ServerSideApply {
+		fieldManager := opts.FieldManager
+		if fieldManager == "" {
+			fieldManager = DefaultFieldManager
+		}
+		// Managed fields must not be set when using server-side apply.
+		obj.SetManagedFields(nil)
+		obj.SetResourceVersion("")
+		if err := c.Patch(ctx, obj, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership); err != nil {
+			return errors.Wrapf(err, "failed to apply object %s %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		}
+		return nil
+	}
+
+	// Create the object on the API server.
+	if err := c.Create(ctx, obj); err != nil {
+		// The create call is idempotent, so if the object already exists
+		// then do not consider it to be an error.
+		if !apierrors.IsAlreadyExists(err) {
+			return errors.Wrapf(err, "failed to create object %s %s/%s", obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+		}
+	}
+	return nil
+}
+
+// prune deletes the objects with the prune labels which are not part of the applied objects anymore.
+func prune(ctx context.Context, c client.Client, objs []unstructured.Unstructured, opts Options) error {
+	type objKey struct {
+		gvk       schema.GroupVersionKind
+		namespace string
+		name      string
+	}
+
+	applied := map[objKey]bool{}
+	types := []schema.GroupVersionKind{}
+	seenTypes := map[schema.GroupVersionKind]bool{}
+	addType := func(gvk schema.GroupVersionKind) {
+		if !seenTypes[gvk] {
+			seenTypes[gvk] = true
+			types = append(types, gvk)
+		}
+	}
+	for _, obj := range objs {
+		applied[objKey{gvk: obj.GroupVersionKind(), namespace: obj.GetNamespace(), name: obj.GetName()}] = true
+		addType(obj.GroupVersionKind())
+	}
+	for _, gvk := range opts.PruneTypes {
+		addType(gvk)
+	}
+
+	errList := []error{}
+	for _, gvk := range types {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := c.List(ctx, list, client.MatchingLabels(opts.PruneLabels)); err != nil {
+			errList = append(errList, errors.Wrapf(err, "failed to list %s objects to prune", gvk))
+			continue
+		}
+
+		for i := range list.Items {
+			obj := &list.Items[i]
+			if applied[objKey{gvk: gvk, namespace: obj.GetNamespace(), name: obj.GetName()}] {
+				continue
+			}
+			if err := c.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+				errList = append(errList, errors.Wrapf(err, "failed to prune object %s %s/%s", gvk, obj.GetNamespace(), obj.GetName()))
+			}
+		}
+	}
+	return kerrors.NewAggregate(errList)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package remoteapply
+
+import (
+	"context"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var ctx = context.Background()
+
+func newConfigMap(name string, annotations, labels map[string]string) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace(metav1.NamespaceDefault)
+	u.SetName(name)
+	u.SetAnnotations(annotations)
+	u.SetLabels(labels)
+	return u
+}
+
+func TestParseObjects(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		wantNames []string
+		wantErr   bool
+	}{
+		{
+			name: "yaml",
+			data: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm-1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm-2
+`,
+			wantNames: []string{"cm-1", "cm-2"},
+		},
+		{
+			name:      "json list",
+			data:      `[{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm-1"}}, {"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm-2"}}]`,
+			wantNames: []string{"cm-1", "cm-2"},
+		},
+		{
+			name:    "invalid json list",
+			data:    `[{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "cm-1"}`,
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			objs, err := ParseObjects([]byte(tt.data))
+			if tt.wantErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).NotTo(HaveOccurred())
+
+			names := []string{}
+			for _, o := range objs {
+				names = append(names, o.GetName())
+			}
+			g.Expect(names).To(Equal(tt.wantNames))
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	t.Run("creates the objects, leaving existing objects untouched", func(t *testing.T) {
+		g := NewWithT(t)
+
+		existing := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: metav1.NamespaceDefault, Name: "cm-1"},
+			Data:       map[string]string{"key": "existing"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).WithObjects(existing).Build()
+
+		cm1 := newConfigMap("cm-1", nil, nil)
+		g.Expect(unstructured.SetNestedField(cm1.Object, "new", "data", "key")).To(Succeed())
+		g.Expect(Apply(ctx, c, []unstructured.Unstructured{cm1, newConfigMap("cm-2", nil, nil)}, Options{})).To(Succeed())
+
+		got := &corev1.ConfigMap{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "cm-1"}, got)).To(Succeed())
+		g.Expect(got.Data).To(HaveKeyWithValue("key", "existing"))
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "cm-2"}, got)).To(Succeed())
+	})
+
+	t.Run("fails for an invalid wave annotation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		c := fake.NewClientBuilder().WithScheme(scheme.Scheme).Build()
+
+		objs := []unstructured.Unstructured{newConfigMap("cm-1", map[string]string{WaveAnnotation: "first"}, nil)}
+		g.Expect(Apply(ctx, c, objs, Options{})).NotTo(Succeed())
+
+		got := &corev1.ConfigMap{}
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "cm-1"}, got)).NotTo(Succeed())
+	})
+
+	t.Run("prunes the objects not part of the set anymore", func(t *testing.T) {
+		g := NewWithT(t)
+
+		// NB. Using an unstructured type, given that the fake client fails to list
+		// unstructured objects of typed kinds.
+		addonGVK := schema.GroupVersionKind{Group: "addons.test.cluster.x-k8s.io", Version: "v1alpha4", Kind: "Addon"}
+		newAddon := func(name string, labels map[string]string) unstructured.Unstructured {
+			u := newConfigMap(name, nil, labels)
+			u.SetGroupVersionKind(addonGVK)
+			return u
+		}
+		testScheme := runtime.NewScheme()
+		testScheme.AddKnownTypeWithName(addonGVK, &unstructured.Unstructured{})
+		testScheme.AddKnownTypeWithName(addonGVK.GroupVersion().WithKind("AddonList"), &unstructured.UnstructuredList{})
+
+		pruneLabels := map[string]string{"addon": "test"}
+		stale := newAddon("stale", pruneLabels)
+		unmanaged := newAddon("unmanaged", nil)
+		c := fake.NewClientBuilder().WithScheme(testScheme).WithObjects(&stale, &unmanaged).Build()
+
+		g.Expect(Apply(ctx, c, []unstructured.Unstructured{newAddon("addon-1", nil)}, Options{PruneLabels: pruneLabels})).To(Succeed())
+
+		got := newAddon("", nil)
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "addon-1"}, &got)).To(Succeed())
+		g.Expect(got.GetLabels()).To(Equal(pruneLabels))
+		g.Expect(c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "unmanaged"}, &got)).To(Succeed())
+		err := c.Get(ctx, client.ObjectKey{Namespace: metav1.NamespaceDefault, Name: "stale"}, &got)
+		g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+}
+
+func TestGroupByWave(t *testing.T) {
+	g := NewWithT(t)
+
+	objs := []unstructured.Unstructured{
+		newConfigMap("wave-1", map[string]string{WaveAnnotation: "1"}, nil),
+		newConfigMap("wave-0", nil, nil),
+		newConfigMap("wave-minus-1", map[string]string{WaveAnnotation: "-1"}, nil),
+		newConfigMap("wave-0-explicit", map[string]string{WaveAnnotation: "0"}, nil),
+	}
+
+	waves, err := groupByWave(objs)
+	g.Expect(err).NotTo(HaveOccurred())
+
+	names := [][]string{}
+	for _, wave := range waves {
+		waveNames := []string{}
+		for _, o := range wave {
+			waveNames = append(waveNames, o.GetName())
+		}
+		names = append(names, waveNames)
+	}
+	g.Expect(names).To(Equal([][]string{
+		{"wave-minus-1"},
+		{"wave-0", "wave-0-explicit"},
+		{"wave-1"},
+	}))
+}
+
+func TestValidateObjects(t *testing.T) {
+	g := NewWithT(t)
+
+	g.Expect(ValidateObjects([]unstructured.Unstructured{
+		newConfigMap("cm", map[string]string{WaveAnnotation: "1"}, nil),
+	})).To(Succeed())
+
+	noKind := newConfigMap("no-kind", nil, nil)
+	noKind.SetKind("")
+	noName := newConfigMap("", nil, nil)
+	g.Expect(ValidateObjects([]unstructured.Unstructured{noKind})).NotTo(Succeed())
+	g.Expect(ValidateObjects([]unstructured.Unstructured{noName})).NotTo(Succeed())
+	g.Expect(ValidateObjects([]unstructured.Unstructured{
+		newConfigMap("invalid-wave", map[string]string{WaveAnnotation: "first"}, nil),
+	})).NotTo(Succeed())
+}
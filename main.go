@@ -23,13 +23,18 @@ import (
 	"net/http"
 	_ "net/http/pprof"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/spf13/pflag"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/component-base/featuregate"
 	"k8s.io/klog"
 	"k8s.io/klog/klogr"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
@@ -40,11 +45,17 @@ import (
 	expv1 "sigs.k8s.io/cluster-api/exp/api/v1alpha4"
 	expcontrollers "sigs.k8s.io/cluster-api/exp/controllers"
 	"sigs.k8s.io/cluster-api/feature"
+	"sigs.k8s.io/cluster-api/internal/kubernetesversion"
+	"sigs.k8s.io/cluster-api/internal/managercache"
+	"sigs.k8s.io/cluster-api/util/metrics"
+	"sigs.k8s.io/cluster-api/util/uncached"
 	"sigs.k8s.io/cluster-api/version"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -54,11 +65,13 @@ var (
 
 	// flags
 	metricsBindAddr               string
+	metricsSecure                 bool
+	metricsCertDir                string
 	enableLeaderElection          bool
 	leaderElectionLeaseDuration   time.Duration
 	leaderElectionRenewDeadline   time.Duration
 	leaderElectionRetryPeriod     time.Duration
-	watchNamespace                string
+	watchNamespaces               []string
 	watchFilterValue              string
 	profilerAddress               string
 	clusterConcurrency            int
@@ -68,9 +81,25 @@ var (
 	machinePoolConcurrency        int
 	clusterResourceSetConcurrency int
 	machineHealthCheckConcurrency int
+	fleetViewConcurrency          int
+	clusterGroupConcurrency       int
 	syncPeriod                    time.Duration
+	secretReadCacheTTL            time.Duration
+	clusterResyncPeriod           time.Duration
+	workloadHealthCheckInterval   time.Duration
+	machineResyncPeriod           time.Duration
 	webhookPort                   int
+	enableWebhooks                bool
+	minKubernetesVersion          string
+	maxKubernetesVersion          string
+	kubernetesVersionPolicy       string
 	healthAddr                    string
+	remoteServiceAccountTokens    bool
+	remoteCacheIdleTimeout        time.Duration
+	kubeAPIQPS                    float32
+	kubeAPIBurst                  int
+	featureGatesConfigMap         string
+	featureGatesReloadPeriod      time.Duration
 )
 
 func init() {
@@ -89,6 +118,12 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&metricsBindAddr, "metrics-bind-addr", ":8080",
 		"The address the metric endpoint binds to.")
 
+	fs.BoolVar(&metricsSecure, "metrics-secure", false,
+		"Serve the metric endpoint over HTTPS, authenticating and authorizing requests with TokenReviews and SubjectAccessReviews.")
+
+	fs.StringVar(&metricsCertDir, "metrics-cert-dir", "",
+		"Directory containing the tls.crt and tls.key files used for serving the metric endpoint when --metrics-secure is set. If unspecified, a self-signed certificate is generated.")
+
 	fs.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager.")
 
@@ -101,8 +136,8 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.DurationVar(&leaderElectionRetryPeriod, "leader-elect-retry-period", 2*time.Second,
 		"Duration the LeaderElector clients should wait between tries of actions (duration string)")
 
-	fs.StringVar(&watchNamespace, "namespace", "",
-		"Namespace that the controller watches to reconcile cluster-api objects. If unspecified, the controller watches for cluster-api objects across all namespaces.")
+	fs.StringSliceVar(&watchNamespaces, "namespace", nil,
+		"Comma-separated list of namespaces that the controller watches to reconcile cluster-api objects, with one cache per namespace. If unspecified, the controller watches for cluster-api objects across all namespaces.")
 
 	fs.StringVar(&watchFilterValue, "watch-filter", "",
 		fmt.Sprintf("Label value that the controller watches to reconcile cluster-api objects. Label key is always %s. If unspecified, the controller watches for all cluster-api objects.", clusterv1.WatchLabel))
@@ -131,16 +166,64 @@ func InitFlags(fs *pflag.FlagSet) {
 	fs.IntVar(&machineHealthCheckConcurrency, "machinehealthcheck-concurrency", 10,
 		"Number of machine health checks to process simultaneously")
 
+	fs.IntVar(&fleetViewConcurrency, "fleetview-concurrency", 10,
+		"Number of fleet views to process simultaneously")
+
+	fs.IntVar(&clusterGroupConcurrency, "clustergroup-concurrency", 10,
+		"Number of cluster groups to process simultaneously")
+
 	fs.DurationVar(&syncPeriod, "sync-period", 10*time.Minute,
-		"The minimum interval at which watched resources are reconciled (e.g. 15m)")
+		"The minimum interval at which watched resources are reconciled (e.g. 15m). Clusters and machines which need it are reconciled every --cluster-resync-period and --machine-resync-period (10m by default) instead, so it can be increased, e.g. to 1h, to reduce the load of large management clusters")
+
+	fs.DurationVar(&secretReadCacheTTL, "secret-read-cache-ttl", 0,
+		"The time bootstrap data and kubeconfig secrets are served from a read-through cache after being read, reducing the reads of secrets from the API server; secrets written by other clients can be stale up to this time. 0 disables the cache")
+
+	fs.DurationVar(&clusterResyncPeriod, "cluster-resync-period", 10*time.Minute,
+		"The interval at which clusters not yet provisioned, or failing the workload cluster health checks, are reconciled again; it is effective only if shorter than --sync-period, which should be increased accordingly. 0 to rely on --sync-period only")
+
+	fs.DurationVar(&workloadHealthCheckInterval, "workload-components-health-check-interval", 5*time.Minute,
+		"The interval at which the workload cluster components listed in the workloadComponentHealthChecks of the clusters, e.g. the CNI, are checked")
+
+	fs.DurationVar(&machineResyncPeriod, "machine-resync-period", 10*time.Minute,
+		"The interval at which machines that are not Running, Failed or Deleted are reconciled again; it is effective only if shorter than --sync-period, which should be increased accordingly. 0 to rely on --sync-period only")
 
 	fs.IntVar(&webhookPort, "webhook-port", 9443,
 		"Webhook Server port")
 
+	fs.BoolVar(&enableWebhooks, "enable-webhooks", true,
+		"Serve the admission webhooks. If false, the webhooks are not served and the controllers apply the defaulting and the validation of the webhooks to the objects they reconcile; this is meant for management clusters where the webhooks cannot be reached by the API server.")
+
+	fs.StringVar(&minKubernetesVersion, "min-kubernetes-version", "",
+		"The minimum Kubernetes minor version of workload clusters accepted by the Machine, MachineSet and MachineDeployment webhooks (e.g. v1.18). If unspecified, there is no minimum version.")
+
+	fs.StringVar(&maxKubernetesVersion, "max-kubernetes-version", "",
+		"The maximum Kubernetes minor version of workload clusters accepted by the Machine, MachineSet and MachineDeployment webhooks (e.g. v1.21). If unspecified, there is no maximum version.")
+
+	fs.StringVar(&kubernetesVersionPolicy, "kubernetes-version-policy", string(kubernetesversion.PolicyEnforce),
+		fmt.Sprintf("How the webhooks handle Kubernetes versions outside of --min-kubernetes-version and --max-kubernetes-version: %q rejects them, %q accepts them logging a warning.", kubernetesversion.PolicyEnforce, kubernetesversion.PolicyWarn))
+
 	fs.StringVar(&healthAddr, "health-addr", ":9440",
 		"The address the health endpoint binds to.")
 
+	fs.BoolVar(&remoteServiceAccountTokens, "remote-service-account-tokens", false,
+		"Access workload clusters using short-lived tokens of a service account created in each workload cluster, instead of the credentials in the kubeconfig secret of the Cluster. When the ClusterResourceSet feature is enabled, the service account is granted all verbs on all resources, i.e. it is effectively cluster-admin, because ClusterResourceSets can apply any kind of resource.")
+
+	fs.DurationVar(&remoteCacheIdleTimeout, "remote-cache-idle-timeout", 0,
+		"The time after which the client, the cache and the watches of a workload cluster which has not been accessed are torn down, to reduce the memory used for clusters that are rarely reconciled; they are created again on the next access. It must be longer than --sync-period; 0 disables the eviction")
+
+	fs.Float32Var(&kubeAPIQPS, "kube-api-qps", 20,
+		"Maximum queries per second from the controller manager to the Kubernetes API server of the management cluster and of each workload cluster")
+
+	fs.IntVar(&kubeAPIBurst, "kube-api-burst", 30,
+		"Maximum burst of queries from the controller manager to the Kubernetes API server of the management cluster and of each workload cluster")
+
 	feature.MutableGates.AddFlag(fs)
+
+	fs.StringVar(&featureGatesConfigMap, "feature-gates-configmap", "",
+		fmt.Sprintf("Namespace and name of a ConfigMap, in the namespace/name format, whose %q key enables or disables the %v feature gates at runtime, without restarting the manager.", feature.ConfigMapDataKey, feature.DynamicGates))
+
+	fs.DurationVar(&featureGatesReloadPeriod, "feature-gates-reload-period", time.Minute,
+		"The interval at which the ConfigMap set with --feature-gates-configmap is read")
 }
 
 func main() {
@@ -152,6 +235,13 @@ func main() {
 
 	ctrl.SetLogger(klogr.New())
 
+	checkResyncPeriods()
+
+	if err := remote.ValidateIdleTimeout(remoteCacheIdleTimeout, syncPeriod); err != nil {
+		setupLog.Error(err, "invalid --remote-cache-idle-timeout")
+		os.Exit(1)
+	}
+
 	if profilerAddress != "" {
 		klog.Infof("Profiler listening for requests at %s", profilerAddress)
 		go func() {
@@ -159,16 +249,28 @@ func main() {
 		}()
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	// When serving the metrics securely, the controller-runtime metrics server is disabled.
+	managerMetricsBindAddr := metricsBindAddr
+	if metricsSecure {
+		managerMetricsBindAddr = "0"
+	}
+
+	watchNamespace, newCache := managercache.ForNamespaces(watchNamespaces)
+
+	restConfig := newRESTConfig(ctrl.GetConfigOrDie())
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:             scheme,
-		MetricsBindAddress: metricsBindAddr,
+		MetricsBindAddress: managerMetricsBindAddr,
 		LeaderElection:     enableLeaderElection,
 		LeaderElectionID:   "controller-leader-election-capi",
 		LeaseDuration:      &leaderElectionLeaseDuration,
 		RenewDeadline:      &leaderElectionRenewDeadline,
 		RetryPeriod:        &leaderElectionRetryPeriod,
 		Namespace:          watchNamespace,
+		NewCache:           newCache,
 		SyncPeriod:         &syncPeriod,
+		ClientBuilder:      uncached.NewClientBuilder(uncached.Options{SecretTTL: secretReadCacheTTL}),
 		ClientDisableCacheFor: []client.Object{
 			&corev1.ConfigMap{},
 			&corev1.Secret{},
@@ -181,12 +283,36 @@ func main() {
 		os.Exit(1)
 	}
 
+	if metricsSecure {
+		metricsServer, err := metrics.NewSecureServer(metrics.SecureServerOptions{
+			BindAddress: metricsBindAddr,
+			CertDir:     metricsCertDir,
+			Config:      mgr.GetConfig(),
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create secure metrics server")
+			os.Exit(1)
+		}
+		if err := mgr.Add(metricsServer); err != nil {
+			setupLog.Error(err, "unable to add secure metrics server to the manager")
+			os.Exit(1)
+		}
+	}
+
 	// Setup the context that's going to be used in controllers and for the manager.
 	ctx := ctrl.SetupSignalHandler()
 
 	setupChecks(mgr)
-	setupReconcilers(ctx, mgr)
-	setupWebhooks(mgr)
+	tracker := setupReconcilers(ctx, mgr)
+	if enableWebhooks {
+		setupWebhooks(mgr)
+	} else {
+		setupLog.Info("Webhooks are disabled, the defaulting and the validation are applied by the controllers")
+		if minKubernetesVersion != "" || maxKubernetesVersion != "" {
+			setupLog.Info("Webhooks are disabled, the supported Kubernetes versions are not enforced")
+		}
+	}
+	setupFeatureGatesReloader(ctx, mgr, tracker)
 
 	// +kubebuilder:scaffold:builder
 	setupLog.Info("starting manager", "version", version.Get().String())
@@ -196,6 +322,31 @@ func main() {
 	}
 }
 
+// checkResyncPeriods warns about the resync periods which are longer than --sync-period, given that all the
+// objects are reconciled again every --sync-period anyway; with the default values, which are the same, the resync
+// periods only take effect once --sync-period is increased.
+func checkResyncPeriods() {
+	for _, p := range []struct {
+		flag   string
+		period time.Duration
+	}{
+		{flag: "cluster-resync-period", period: clusterResyncPeriod},
+		{flag: "machine-resync-period", period: machineResyncPeriod},
+	} {
+		if p.period > syncPeriod {
+			setupLog.Info(fmt.Sprintf("--%s has no effect, given that it is longer than --sync-period", p.flag), p.flag, p.period, "sync-period", syncPeriod)
+		}
+	}
+}
+
+// newRESTConfig returns config with the rate limits set with --kube-api-qps and --kube-api-burst.
+func newRESTConfig(config *rest.Config) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.QPS = kubeAPIQPS
+	config.Burst = kubeAPIBurst
+	return config
+}
+
 func setupChecks(mgr ctrl.Manager) {
 	if err := mgr.AddReadyzCheck("ping", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to create ready check")
@@ -208,13 +359,70 @@ func setupChecks(mgr ctrl.Manager) {
 	}
 }
 
-func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
-	// Set up a ClusterCacheTracker and ClusterCacheReconciler to provide to controllers
-	// requiring a connection to a remote cluster
-	tracker, err := remote.NewClusterCacheTracker(
+// remoteClusterRules returns the permissions required in workload clusters by the controllers using the ClusterCacheTracker.
+// It is evaluated each time the accessor of a workload cluster is created, so feature gates enabled at runtime are
+// taken into account.
+func remoteClusterRules() []rbacv1.PolicyRule {
+	rules := []rbacv1.PolicyRule{
+		{
+			APIGroups: []string{""},
+			Resources: []string{"nodes"},
+			Verbs:     []string{"get", "list", "watch", "update", "patch", "delete"},
+		},
+		{
+			// The scale down protection of MachineSets.
+			APIGroups: []string{""},
+			Resources: []string{"pods"},
+			Verbs:     []string{"list"},
+		},
+		{
+			// The workload components health checks of the Cluster controller; checks of other kinds require
+			// additional permissions.
+			APIGroups: []string{"apps"},
+			Resources: []string{"daemonsets", "deployments", "statefulsets"},
+			Verbs:     []string{"get"},
+		},
+		{
+			// The node lease checks of MachineHealthChecks.
+			APIGroups: []string{"coordination.k8s.io"},
+			Resources: []string{"leases"},
+			Verbs:     []string{"get", "list", "watch"},
+		},
+	}
+	if feature.Gates.Enabled(feature.ClusterResourceSet) {
+		// ClusterResourceSets can apply any kind of resource to workload clusters.
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{"*"},
+			Resources: []string{"*"},
+			Verbs:     []string{"*"},
+		})
+	}
+	return rules
+}
+
+// newClusterCacheTracker creates the ClusterCacheTracker configured with the flags.
+func newClusterCacheTracker(mgr ctrl.Manager) (*remote.ClusterCacheTracker, error) {
+	trackerOptions := []remote.ClusterCacheTrackerOption{
+		remote.WithIdleTimeout(remoteCacheIdleTimeout),
+		remote.WithRateLimits(kubeAPIQPS, kubeAPIBurst),
+	}
+	if remoteServiceAccountTokens {
+		trackerOptions = append(trackerOptions, remote.WithServiceAccountTokens(remote.ServiceAccountTokenOptions{
+			Name:  "capi-controller-manager",
+			Rules: remoteClusterRules,
+		}))
+	}
+	return remote.NewClusterCacheTracker(
 		ctrl.Log.WithName("remote").WithName("ClusterCacheTracker"),
 		mgr,
+		trackerOptions...,
 	)
+}
+
+func setupReconcilers(ctx context.Context, mgr ctrl.Manager) *remote.ClusterCacheTracker {
+	// Set up a ClusterCacheTracker and ClusterCacheReconciler to provide to controllers
+	// requiring a connection to a remote cluster
+	tracker, err := newClusterCacheTracker(mgr)
 	if err != nil {
 		setupLog.Error(err, "unable to create cluster cache tracker")
 		os.Exit(1)
@@ -229,8 +437,12 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	}
 
 	if err := (&controllers.ClusterReconciler{
-		Client:           mgr.GetClient(),
-		WatchFilterValue: watchFilterValue,
+		Client:                                mgr.GetClient(),
+		Tracker:                               tracker,
+		WatchFilterValue:                      watchFilterValue,
+		WebhooksDisabled:                      !enableWebhooks,
+		ResyncPeriod:                          clusterResyncPeriod,
+		WorkloadComponentsHealthCheckInterval: workloadHealthCheckInterval,
 	}).SetupWithManager(ctx, mgr, concurrency(clusterConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Cluster")
 		os.Exit(1)
@@ -239,6 +451,8 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		Client:           mgr.GetClient(),
 		Tracker:          tracker,
 		WatchFilterValue: watchFilterValue,
+		WebhooksDisabled: !enableWebhooks,
+		ResyncPeriod:     machineResyncPeriod,
 	}).SetupWithManager(ctx, mgr, concurrency(machineConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Machine")
 		os.Exit(1)
@@ -247,6 +461,7 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		Client:           mgr.GetClient(),
 		Tracker:          tracker,
 		WatchFilterValue: watchFilterValue,
+		WebhooksDisabled: !enableWebhooks,
 	}).SetupWithManager(ctx, mgr, concurrency(machineSetConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineSet")
 		os.Exit(1)
@@ -254,35 +469,42 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 	if err := (&controllers.MachineDeploymentReconciler{
 		Client:           mgr.GetClient(),
 		WatchFilterValue: watchFilterValue,
+		WebhooksDisabled: !enableWebhooks,
 	}).SetupWithManager(ctx, mgr, concurrency(machineDeploymentConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineDeployment")
 		os.Exit(1)
 	}
 
 	if feature.Gates.Enabled(feature.MachinePool) {
-		if err := (&expcontrollers.MachinePoolReconciler{
-			Client:           mgr.GetClient(),
-			WatchFilterValue: watchFilterValue,
-		}).SetupWithManager(ctx, mgr, concurrency(machinePoolConcurrency)); err != nil {
+		if err := setupMachinePoolReconcilers(ctx, mgr); err != nil {
 			setupLog.Error(err, "unable to create controller", "controller", "MachinePool")
 			os.Exit(1)
 		}
 	}
 
 	if feature.Gates.Enabled(feature.ClusterResourceSet) {
-		if err := (&addonscontrollers.ClusterResourceSetReconciler{
+		if err := setupClusterResourceSetReconcilers(ctx, mgr, tracker); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterResourceSet")
+			os.Exit(1)
+		}
+	}
+
+	if feature.Gates.Enabled(feature.FleetView) {
+		if err := (&expcontrollers.FleetViewReconciler{
 			Client:           mgr.GetClient(),
-			Tracker:          tracker,
 			WatchFilterValue: watchFilterValue,
-		}).SetupWithManager(ctx, mgr, concurrency(clusterResourceSetConcurrency)); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "ClusterResourceSet")
+		}).SetupWithManager(ctx, mgr, concurrency(fleetViewConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "FleetView")
 			os.Exit(1)
 		}
-		if err := (&addonscontrollers.ClusterResourceSetBindingReconciler{
+	}
+
+	if feature.Gates.Enabled(feature.ClusterGroup) {
+		if err := (&expcontrollers.ClusterGroupReconciler{
 			Client:           mgr.GetClient(),
 			WatchFilterValue: watchFilterValue,
-		}).SetupWithManager(ctx, mgr, concurrency(clusterResourceSetConcurrency)); err != nil {
-			setupLog.Error(err, "unable to create controller", "controller", "ClusterResourceSetBinding")
+		}).SetupWithManager(ctx, mgr, concurrency(clusterGroupConcurrency)); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "ClusterGroup")
 			os.Exit(1)
 		}
 	}
@@ -291,13 +513,48 @@ func setupReconcilers(ctx context.Context, mgr ctrl.Manager) {
 		Client:           mgr.GetClient(),
 		Tracker:          tracker,
 		WatchFilterValue: watchFilterValue,
+		WebhooksDisabled: !enableWebhooks,
 	}).SetupWithManager(ctx, mgr, concurrency(machineHealthCheckConcurrency)); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MachineHealthCheck")
 		os.Exit(1)
 	}
+
+	return tracker
+}
+
+func setupMachinePoolReconcilers(ctx context.Context, mgr ctrl.Manager) error {
+	return (&expcontrollers.MachinePoolReconciler{
+		Client:           mgr.GetClient(),
+		WatchFilterValue: watchFilterValue,
+		WebhooksDisabled: !enableWebhooks,
+	}).SetupWithManager(ctx, mgr, concurrency(machinePoolConcurrency))
+}
+
+func setupClusterResourceSetReconcilers(ctx context.Context, mgr ctrl.Manager, tracker *remote.ClusterCacheTracker) error {
+	if err := (&addonscontrollers.ClusterResourceSetReconciler{
+		Client:           mgr.GetClient(),
+		Tracker:          tracker,
+		WatchFilterValue: watchFilterValue,
+		WebhooksDisabled: !enableWebhooks,
+	}).SetupWithManager(ctx, mgr, concurrency(clusterResourceSetConcurrency)); err != nil {
+		return err
+	}
+	return (&addonscontrollers.ClusterResourceSetBindingReconciler{
+		Client:           mgr.GetClient(),
+		WatchFilterValue: watchFilterValue,
+	}).SetupWithManager(ctx, mgr, concurrency(clusterResourceSetConcurrency))
 }
 
 func setupWebhooks(mgr ctrl.Manager) {
+	if err := kubernetesversion.Set(kubernetesversion.Window{
+		Min:    minKubernetesVersion,
+		Max:    maxKubernetesVersion,
+		Policy: kubernetesversion.Policy(kubernetesVersionPolicy),
+	}); err != nil {
+		setupLog.Error(err, "invalid supported Kubernetes versions")
+		os.Exit(1)
+	}
+
 	if err := (&clusterv1.Cluster{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "Cluster")
 		os.Exit(1)
@@ -319,23 +576,119 @@ func setupWebhooks(mgr ctrl.Manager) {
 	}
 
 	if feature.Gates.Enabled(feature.MachinePool) {
-		if err := (&expv1.MachinePool{}).SetupWebhookWithManager(mgr); err != nil {
+		if err := setupGatedWebhooks(mgr, feature.MachinePool, &expv1.MachinePool{}); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "MachinePool")
 			os.Exit(1)
 		}
 	}
 
 	if feature.Gates.Enabled(feature.ClusterResourceSet) {
-		if err := (&addonsv1.ClusterResourceSet{}).SetupWebhookWithManager(mgr); err != nil {
+		if err := setupClusterResourceSetWebhooks(mgr); err != nil {
 			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterResourceSet")
 			os.Exit(1)
 		}
 	}
 
+	if feature.Gates.Enabled(feature.ClusterGroup) {
+		if err := (&expv1.ClusterGroup{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterGroup")
+			os.Exit(1)
+		}
+	}
+
 	if err := (&clusterv1.MachineHealthCheck{}).SetupWebhookWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create webhook", "webhook", "MachineHealthCheck")
 		os.Exit(1)
 	}
+
+	if feature.Gates.Enabled(feature.ClusterAPIQuota) {
+		if err := (&expv1.ClusterAPIQuota{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterAPIQuota")
+			os.Exit(1)
+		}
+	}
+}
+
+// gatedWebhookType is an API type with both defaulting and validating webhooks.
+type gatedWebhookType interface {
+	admission.Defaulter
+	admission.Validator
+}
+
+// setupGatedWebhooks registers the defaulting and validating webhooks of the given type, at the same paths used by
+// ctrl.NewWebhookManagedBy, with handlers allowing the requests while the given dynamic gate is disabled at runtime.
+func setupGatedWebhooks(mgr ctrl.Manager, gate featuregate.Feature, obj gatedWebhookType) error {
+	gvk, err := apiutil.GVKForObject(obj, mgr.GetScheme())
+	if err != nil {
+		return err
+	}
+	suffix := strings.Replace(gvk.Group, ".", "-", -1) + "-" + gvk.Version + "-" + strings.ToLower(gvk.Kind)
+
+	server := mgr.GetWebhookServer()
+	server.Register("/mutate-"+suffix, &admission.Webhook{
+		Handler: feature.GatedWebhookHandler(gate, admission.DefaultingWebhookFor(obj).Handler),
+	})
+	server.Register("/validate-"+suffix, &admission.Webhook{
+		Handler: feature.GatedWebhookHandler(gate, admission.ValidatingWebhookFor(obj).Handler),
+	})
+	return nil
+}
+
+// setupClusterResourceSetWebhooks registers the ClusterResourceSet webhooks, including the validation of the contents
+// of their resources, with handlers allowing the requests while the ClusterResourceSet gate is disabled at runtime.
+func setupClusterResourceSetWebhooks(mgr ctrl.Manager) error {
+	if err := setupGatedWebhooks(mgr, feature.ClusterResourceSet, &addonsv1.ClusterResourceSet{}); err != nil {
+		return err
+	}
+	mgr.GetWebhookServer().Register(addonsv1.ClusterResourceSetResourcesWebhookPath, &admission.Webhook{
+		Handler: feature.GatedWebhookHandler(feature.ClusterResourceSet, &addonsv1.ClusterResourceSetResourcesValidator{Client: mgr.GetClient()}),
+	})
+	return nil
+}
+
+// setupFeatureGatesReloader sets up the reloading of the dynamic feature gates from the --feature-gates-configmap
+// ConfigMap; the controllers and webhooks of the gates enabled at runtime are set up the first time they are enabled,
+// while the controllers and webhooks of the gates disabled at runtime ignore the requests.
+func setupFeatureGatesReloader(ctx context.Context, mgr ctrl.Manager, tracker *remote.ClusterCacheTracker) {
+	if featureGatesConfigMap == "" {
+		return
+	}
+	parts := strings.Split(featureGatesConfigMap, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		setupLog.Error(errors.New("invalid format, expected namespace/name"), "invalid --feature-gates-configmap", "value", featureGatesConfigMap)
+		os.Exit(1)
+	}
+
+	if err := mgr.Add(&feature.ConfigMapReloader{
+		// ConfigMaps are not cached by the manager.
+		Client:   mgr.GetAPIReader(),
+		Key:      client.ObjectKey{Namespace: parts[0], Name: parts[1]},
+		Interval: featureGatesReloadPeriod,
+		OnEnabled: map[featuregate.Feature]func() error{
+			feature.MachinePool: func() error {
+				if err := setupMachinePoolReconcilers(ctx, mgr); err != nil {
+					return err
+				}
+				if !enableWebhooks {
+					return nil
+				}
+				return setupGatedWebhooks(mgr, feature.MachinePool, &expv1.MachinePool{})
+			},
+			feature.ClusterResourceSet: func() error {
+				if err := setupClusterResourceSetReconcilers(ctx, mgr, tracker); err != nil {
+					return err
+				}
+				if !enableWebhooks {
+					return nil
+				}
+				return setupClusterResourceSetWebhooks(mgr)
+			},
+		},
+		Log: ctrl.Log.WithName("feature-gates"),
+	}); err != nil {
+		setupLog.Error(err, "unable to add the feature gates reloader to the manager")
+		os.Exit(1)
+	}
 }
 
 func concurrency(c int) controller.Options {
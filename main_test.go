@@ -0,0 +1,109 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/spf13/pflag"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/rest"
+	"k8s.io/component-base/featuregate"
+	"sigs.k8s.io/cluster-api/feature"
+	ctrl "sigs.k8s.io/controller-runtime"
+)
+
+func parseFlags(g *WithT, args ...string) {
+	fs := pflag.NewFlagSet("manager", pflag.ContinueOnError)
+	InitFlags(fs)
+	g.Expect(fs.Parse(args)).To(Succeed())
+}
+
+func TestKubeAPIRateLimits(t *testing.T) {
+	g := NewWithT(t)
+
+	parseFlags(g, "--kube-api-qps=50", "--kube-api-burst=100")
+
+	base := &rest.Config{Host: "https://management.example.com"}
+	config := newRESTConfig(base)
+	g.Expect(config.Host).To(Equal(base.Host))
+	g.Expect(config.QPS).To(Equal(float32(50)))
+	g.Expect(config.Burst).To(Equal(100))
+	g.Expect(base.QPS).To(BeZero())
+}
+
+func TestSetupWebhooks(t *testing.T) {
+	g := NewWithT(t)
+
+	gates := map[string]bool{}
+	for _, gate := range []string{string(feature.MachinePool), string(feature.ClusterResourceSet), string(feature.ClusterGroup), string(feature.ClusterAPIQuota)} {
+		gates[gate] = feature.Gates.Enabled(featuregate.Feature(gate))
+	}
+	defer func() {
+		g.Expect(feature.MutableGates.SetFromMap(gates)).To(Succeed())
+	}()
+	enabled := map[string]bool{}
+	for gate := range gates {
+		enabled[gate] = true
+	}
+	g.Expect(feature.MutableGates.SetFromMap(enabled)).To(Succeed())
+
+	parseFlags(g)
+	mgr, err := ctrl.NewManager(&rest.Config{Host: "https://management.example.com"}, ctrl.Options{
+		Scheme:             scheme,
+		MetricsBindAddress: "0",
+		MapperProvider: func(*rest.Config) (meta.RESTMapper, error) {
+			return meta.NewDefaultRESTMapper(nil), nil
+		},
+	})
+	g.Expect(err).NotTo(HaveOccurred())
+	setupWebhooks(mgr)
+
+	// Every webhook in the manifests must be served, otherwise the API server rejects the requests.
+	f, err := os.Open("config/webhook/manifests.yaml")
+	g.Expect(err).NotTo(HaveOccurred())
+	defer f.Close()
+	decoder := utilyaml.NewYAMLOrJSONDecoder(f, 4096)
+	var paths []string
+	for {
+		// Mutating and validating webhook configurations share the same client config.
+		config := &admissionregistrationv1.ValidatingWebhookConfiguration{}
+		if err := decoder.Decode(config); err == io.EOF {
+			break
+		} else {
+			g.Expect(err).NotTo(HaveOccurred())
+		}
+		for _, webhook := range config.Webhooks {
+			g.Expect(webhook.ClientConfig.Service).NotTo(BeNil())
+			g.Expect(webhook.ClientConfig.Service.Path).NotTo(BeNil())
+			paths = append(paths, *webhook.ClientConfig.Service.Path)
+		}
+	}
+	g.Expect(paths).NotTo(BeEmpty())
+
+	for _, path := range paths {
+		_, pattern := mgr.GetWebhookServer().WebhookMux.Handler(&http.Request{URL: &url.URL{Path: path}})
+		g.Expect(pattern).To(Equal(path), "webhook %s is not registered", path)
+	}
+}
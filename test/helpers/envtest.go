@@ -54,6 +54,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/envtest"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
 
 func init() {
@@ -176,6 +177,9 @@ func NewTestEnvironment() *TestEnvironment {
 	if err := (&crs.ClusterResourceSet{}).SetupWebhookWithManager(mgr); err != nil {
 		klog.Fatalf("unable to create webhook for crs: %+v", err)
 	}
+	mgr.GetWebhookServer().Register(crs.ClusterResourceSetResourcesWebhookPath, &webhook.Admission{
+		Handler: &crs.ClusterResourceSetResourcesValidator{Client: mgr.GetClient()},
+	})
 
 	return &TestEnvironment{
 		Manager: mgr,
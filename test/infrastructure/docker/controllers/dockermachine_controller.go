@@ -23,9 +23,9 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
-	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	bsutil "sigs.k8s.io/cluster-api/bootstrap/util"
 	infrav1 "sigs.k8s.io/cluster-api/test/infrastructure/docker/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/test/infrastructure/docker/docker"
 	"sigs.k8s.io/cluster-api/util"
@@ -264,12 +264,12 @@ func (r *DockerMachineReconciler) reconcileNormal(ctx context.Context, cluster *
 		defer cancel()
 		// Run the bootstrap script. Simulates cloud-init.
 		if err := externalMachine.ExecBootstrap(timeoutctx, bootstrapData); err != nil {
-			conditions.MarkFalse(dockerMachine, infrav1.BootstrapExecSucceededCondition, infrav1.BootstrapFailedReason, clusterv1.ConditionSeverityWarning, "Repeating bootstrap")
+			conditions.MarkFalse(dockerMachine, infrav1.BootstrapExecSucceededCondition, infrav1.BootstrapFailedReason, clusterv1.ConditionSeverityWarning, bootstrapFailedMessage(timeoutctx, externalMachine))
 			return ctrl.Result{}, errors.Wrap(err, "failed to exec DockerMachine bootstrap")
 		}
 		// Check for bootstrap success
 		if err := externalMachine.CheckForBootstrapSuccess(timeoutctx); err != nil {
-			conditions.MarkFalse(dockerMachine, infrav1.BootstrapExecSucceededCondition, infrav1.BootstrapFailedReason, clusterv1.ConditionSeverityWarning, "Repeating bootstrap")
+			conditions.MarkFalse(dockerMachine, infrav1.BootstrapExecSucceededCondition, infrav1.BootstrapFailedReason, clusterv1.ConditionSeverityWarning, bootstrapFailedMessage(timeoutctx, externalMachine))
 			return ctrl.Result{}, errors.Wrap(err, "failed to check for existence of bootstrap success file at /run/cluster-api/bootstrap-success.complete")
 		}
 
@@ -419,16 +419,19 @@ func (r *DockerMachineReconciler) getBootstrapData(ctx context.Context, machine
 		return "", errors.New("error retrieving bootstrap data: linked Machine's bootstrap.dataSecretName is nil")
 	}
 
-	s := &corev1.Secret{}
-	key := client.ObjectKey{Namespace: machine.GetNamespace(), Name: *machine.Spec.Bootstrap.DataSecretName}
-	if err := r.Client.Get(ctx, key, s); err != nil {
-		return "", errors.Wrapf(err, "failed to retrieve bootstrap data secret for DockerMachine %s/%s", machine.GetNamespace(), machine.GetName())
-	}
-
-	value, ok := s.Data["value"]
-	if !ok {
-		return "", errors.New("error retrieving bootstrap data: secret value key is missing")
+	value, err := bsutil.GetBootstrapData(ctx, r.Client, machine.GetNamespace(), *machine.Spec.Bootstrap.DataSecretName)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to retrieve bootstrap data for DockerMachine %s/%s", machine.GetNamespace(), machine.GetName())
 	}
 
 	return base64.StdEncoding.EncodeToString(value), nil
 }
+
+// bootstrapFailedMessage returns the message of the BootstrapExecSucceeded condition when bootstrap fails, including
+// the failure reported by the bootstrap provider, if any.
+func bootstrapFailedMessage(ctx context.Context, machine *docker.Machine) string {
+	if message := machine.BootstrapFailureMessage(ctx); message != "" {
+		return fmt.Sprintf("Repeating bootstrap: %s", message)
+	}
+	return "Repeating bootstrap"
+}
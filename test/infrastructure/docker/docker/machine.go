@@ -339,6 +339,22 @@ func (m *Machine) CheckForBootstrapSuccess(ctx context.Context) error {
 	return nil
 }
 
+// BootstrapFailureMessage returns the content of the /run/cluster-api/bootstrap-failure.message file, which bootstrap
+// providers can write with the reason of a bootstrap failure; it returns an empty string if the file doesn't exist.
+func (m *Machine) BootstrapFailureMessage(ctx context.Context) string {
+	if m.container == nil {
+		return ""
+	}
+
+	var outStd bytes.Buffer
+	cmd := m.container.Commander.Command("cat", "/run/cluster-api/bootstrap-failure.message")
+	cmd.SetStdout(&outStd)
+	if err := cmd.Run(ctx); err != nil {
+		return ""
+	}
+	return strings.TrimSpace(outStd.String())
+}
+
 // SetNodeProviderID sets the docker provider ID for the kubernetes node
 func (m *Machine) SetNodeProviderID(ctx context.Context) error {
 	log := ctrl.LoggerFrom(ctx)
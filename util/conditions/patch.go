@@ -49,6 +49,7 @@ const (
 )
 
 // NewPatch returns the list of Patch required to align source conditions to after conditions.
+// NOTE: Changes not altering the state of a condition, e.g. changes to LastTransitionTime only, are ignored.
 func NewPatch(before Getter, after Getter) Patch {
 	var patch Patch
 
@@ -62,7 +63,7 @@ func NewPatch(before Getter, after Getter) Patch {
 			continue
 		}
 
-		if !reflect.DeepEqual(&targetCondition, currentCondition) {
+		if !hasSameState(&targetCondition, currentCondition) {
 			patch = append(patch, PatchOperation{Op: ChangeConditionPatch, After: &targetCondition, Before: currentCondition})
 		}
 	}
@@ -29,6 +29,8 @@ import (
 func TestNewPatch(t *testing.T) {
 	fooTrue := TrueCondition("foo")
 	fooFalse := FalseCondition("foo", "reason foo", clusterv1.ConditionSeverityInfo, "message foo")
+	fooTrueLater := fooTrue.DeepCopy()
+	fooTrueLater.LastTransitionTime = metav1.NewTime(fooTrue.LastTransitionTime.Add(time.Minute))
 
 	tests := []struct {
 		name   string
@@ -72,6 +74,12 @@ func TestNewPatch(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:   "Changes to LastTransitionTime only return empty patch",
+			before: getterWithConditions(fooTrue),
+			after:  getterWithConditions(fooTrueLater),
+			want:   nil,
+		},
 		{
 			name:   "Detects RemoveConditionPatch",
 			before: getterWithConditions(fooTrue),
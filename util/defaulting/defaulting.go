@@ -0,0 +1,45 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package defaulting implements the defaulting and the validation of the Cluster API types inside the controllers,
+// for management clusters where the admission webhooks are not installed.
+package defaulting
+
+import (
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// Object is an object that implements the defaulting and the validation of its admission webhooks.
+type Object interface {
+	client.Object
+	webhook.Defaulter
+	webhook.Validator
+}
+
+// DefaultAndValidate applies the defaults of the mutating webhook of the object in place, then validates the object
+// as the validating webhook would on create; objects being deleted are not validated.
+//
+// NOTE: the validation of the changes to immutable fields requires the previous version of the object, which is
+// not available to the controllers; those changes are only rejected when the webhooks are installed.
+func DefaultAndValidate(obj Object) error {
+	obj.Default()
+
+	if !obj.GetDeletionTimestamp().IsZero() {
+		return nil
+	}
+	return obj.ValidateCreate()
+}
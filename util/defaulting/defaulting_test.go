@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package defaulting
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestDefaultAndValidate(t *testing.T) {
+	deletionTimestamp := metav1.Now()
+
+	tests := []struct {
+		name                          string
+		deletionTimestamp             *metav1.Time
+		infrastructureNamespace       string
+		expectErr                     bool
+		expectInfrastructureNamespace string
+	}{
+		{
+			name:                          "applies the defaults",
+			expectInfrastructureNamespace: "foo",
+		},
+		{
+			name:                          "valid object",
+			infrastructureNamespace:       "foo",
+			expectInfrastructureNamespace: "foo",
+		},
+		{
+			name:                          "invalid object",
+			infrastructureNamespace:       "bar",
+			expectErr:                     true,
+			expectInfrastructureNamespace: "bar",
+		},
+		{
+			name:                          "invalid object being deleted",
+			deletionTimestamp:             &deletionTimestamp,
+			infrastructureNamespace:       "bar",
+			expectInfrastructureNamespace: "bar",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			cluster := &clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "test",
+					Namespace:         "foo",
+					DeletionTimestamp: tt.deletionTimestamp,
+				},
+				Spec: clusterv1.ClusterSpec{
+					InfrastructureRef: &corev1.ObjectReference{
+						Kind:      "GenericInfrastructureCluster",
+						Name:      "test",
+						Namespace: tt.infrastructureNamespace,
+					},
+				},
+			}
+
+			err := DefaultAndValidate(cluster)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+			} else {
+				g.Expect(err).NotTo(HaveOccurred())
+			}
+			g.Expect(cluster.Spec.InfrastructureRef.Namespace).To(Equal(tt.expectInfrastructureNamespace))
+		})
+	}
+}
@@ -0,0 +1,218 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics implements a metrics server protected by Kubernetes authentication and authorization.
+package metrics
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/authenticatorfactory"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	certutil "k8s.io/client-go/util/cert"
+	ctrl "sigs.k8s.io/controller-runtime"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// DefaultMetricsPath is the path the metrics are served at.
+	DefaultMetricsPath = "/metrics"
+
+	// tlsCertName and tlsKeyName are the names of the serving certificate and key in the certificate directory.
+	tlsCertName = "tls.crt"
+	tlsKeyName  = "tls.key"
+
+	authCacheTTL = 2 * time.Minute
+)
+
+// SecureServerOptions are the options for creating a SecureServer.
+type SecureServerOptions struct {
+	// BindAddress is the address the metrics server binds to.
+	BindAddress string
+
+	// CertDir is the directory containing the tls.crt and tls.key files used for serving.
+	// If empty, a self-signed certificate is generated on start.
+	CertDir string
+
+	// Config is used for creating the TokenReviews and SubjectAccessReviews authenticating
+	// and authorizing the requests.
+	Config *rest.Config
+
+	// Gatherer is the source of the metrics; defaults to the controller-runtime metrics registry.
+	Gatherer prometheus.Gatherer
+}
+
+// SecureServer serves metrics over HTTPS, authenticating requests with TokenReviews and authorizing them
+// with SubjectAccessReviews for the get verb on the metrics non-resource URL.
+// It allows deployments to protect the metrics endpoint without the kube-rbac-proxy sidecar.
+type SecureServer struct {
+	bindAddress string
+	certDir     string
+	handler     http.Handler
+}
+
+// NewSecureServer returns a SecureServer to be added to a controller manager.
+func NewSecureServer(opts SecureServerOptions) (*SecureServer, error) {
+	if opts.Config == nil {
+		return nil, errors.New("config is required for creating the secure metrics server")
+	}
+	if opts.Gatherer == nil {
+		opts.Gatherer = ctrlmetrics.Registry
+	}
+
+	clientset, err := kubernetes.NewForConfig(opts.Config)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the client for authenticating and authorizing metrics requests")
+	}
+
+	backoff := &wait.Backoff{
+		Duration: 500 * time.Millisecond,
+		Factor:   1.5,
+		Jitter:   0.2,
+		Steps:    5,
+	}
+	authn, _, err := authenticatorfactory.DelegatingAuthenticatorConfig{
+		Anonymous:               false,
+		TokenAccessReviewClient: clientset.AuthenticationV1().TokenReviews(),
+		WebhookRetryBackoff:     backoff,
+		CacheTTL:                authCacheTTL,
+	}.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the metrics authenticator")
+	}
+	authz, err := authorizerfactory.DelegatingAuthorizerConfig{
+		SubjectAccessReviewClient: clientset.AuthorizationV1().SubjectAccessReviews(),
+		AllowCacheTTL:             authCacheTTL,
+		DenyCacheTTL:              authCacheTTL / 4,
+		WebhookRetryBackoff:       backoff,
+	}.New()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the metrics authorizer")
+	}
+
+	return &SecureServer{
+		bindAddress: opts.BindAddress,
+		certDir:     opts.CertDir,
+		handler:     newSecureHandler(authn, authz, promhttp.HandlerFor(opts.Gatherer, promhttp.HandlerOpts{})),
+	}, nil
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable; metrics are served by all the replicas.
+func (s *SecureServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable; it serves the metrics until the context is done.
+func (s *SecureServer) Start(ctx context.Context) error {
+	log := ctrl.Log.WithName("metrics")
+
+	cert, err := s.servingCertificate()
+	if err != nil {
+		return err
+	}
+
+	listener, err := tls.Listen("tcp", s.bindAddress, &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to listen on %s", s.bindAddress)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(DefaultMetricsPath, s.handler)
+	server := &http.Server{Handler: mux}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error(err, "Failed to shutdown the secure metrics server")
+		}
+	}()
+
+	log.Info("Serving metrics securely", "address", listener.Addr().String(), "path", DefaultMetricsPath)
+	if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
+		return errors.Wrap(err, "failed to serve metrics")
+	}
+	return nil
+}
+
+// servingCertificate loads the serving certificate from the certificate directory, or generates
+// a self-signed one if no directory is configured.
+func (s *SecureServer) servingCertificate() (tls.Certificate, error) {
+	if s.certDir != "" {
+		cert, err := tls.LoadX509KeyPair(filepath.Join(s.certDir, tlsCertName), filepath.Join(s.certDir, tlsKeyName))
+		return cert, errors.Wrapf(err, "failed to load the metrics serving certificate from %s", s.certDir)
+	}
+
+	host, _, err := net.SplitHostPort(s.bindAddress)
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey(host, nil, nil)
+	if err != nil {
+		return tls.Certificate{}, errors.Wrap(err, "failed to generate a self-signed metrics serving certificate")
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	return cert, errors.Wrap(err, "failed to load the self-signed metrics serving certificate")
+}
+
+// newSecureHandler wraps the metrics handler with authentication and authorization.
+func newSecureHandler(authn authenticator.Request, authz authorizer.Authorizer, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		res, ok, err := authn.AuthenticateRequest(req)
+		if err != nil || !ok {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		decision, reason, err := authz.Authorize(req.Context(), authorizer.AttributesRecord{
+			User:            res.User,
+			Verb:            "get",
+			Path:            req.URL.Path,
+			ResourceRequest: false,
+		})
+		if err != nil {
+			http.Error(w, "Authorization error", http.StatusInternalServerError)
+			return
+		}
+		if decision != authorizer.DecisionAllow {
+			msg := "Forbidden"
+			if reason != "" {
+				msg = msg + ": " + reason
+			}
+			http.Error(w, msg, http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+	"k8s.io/apiserver/pkg/authentication/authenticator"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+)
+
+func TestSecureHandler(t *testing.T) {
+	authn := authenticator.RequestFunc(func(req *http.Request) (*authenticator.Response, bool, error) {
+		switch req.Header.Get("Authorization") {
+		case "Bearer allowed", "Bearer denied":
+			return &authenticator.Response{User: &user.DefaultInfo{Name: req.Header.Get("Authorization")[len("Bearer "):]}}, true, nil
+		case "Bearer error":
+			return nil, false, errors.New("token review failed")
+		}
+		return nil, false, nil
+	})
+	authz := authorizer.AuthorizerFunc(func(a authorizer.Attributes) (authorizer.Decision, string, error) {
+		if a.GetUser().GetName() == "allowed" && a.GetVerb() == "get" && a.GetPath() == DefaultMetricsPath && !a.IsResourceRequest() {
+			return authorizer.DecisionAllow, "", nil
+		}
+		return authorizer.DecisionNoOpinion, "not allowed", nil
+	})
+	next := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte("metrics"))
+	})
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "serves metrics to authorized users",
+			token:      "allowed",
+			wantStatus: http.StatusOK,
+			wantBody:   "metrics",
+		},
+		{
+			name:       "rejects unauthorized users",
+			token:      "denied",
+			wantStatus: http.StatusForbidden,
+			wantBody:   "Forbidden: not allowed",
+		},
+		{
+			name:       "rejects anonymous requests",
+			wantStatus: http.StatusUnauthorized,
+		},
+		{
+			name:       "rejects requests failing authentication",
+			token:      "error",
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			req := httptest.NewRequest(http.MethodGet, DefaultMetricsPath, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			newSecureHandler(authn, authz, next).ServeHTTP(rec, req)
+
+			g.Expect(rec.Code).To(Equal(tt.wantStatus))
+			g.Expect(rec.Body.String()).To(ContainSubstring(tt.wantBody))
+		})
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package naming implements the templates used to generate the names of the machines
+// created by MachineSets and control plane providers.
+package naming
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// maxAttempts is the maximum number of names generated while looking for one that is not already in use.
+const maxAttempts = 100
+
+// MachineNameData is the data available to the templates used to generate machine names.
+type MachineNameData struct {
+	// ClusterName is the name of the Cluster the machine belongs to.
+	ClusterName string
+
+	// OwnerName is the name of the object the machine is created for, e.g. the MachineDeployment
+	// or the KubeadmControlPlane.
+	OwnerName string
+
+	// Index is incremented each time a generated name collides with an existing one.
+	Index int
+}
+
+var funcs = template.FuncMap{
+	// random returns a random lowercase alphanumeric string of the given length.
+	"random": rand.String,
+	// trunc returns the first n characters of the given string.
+	"trunc": func(n int, s string) string {
+		if n < 0 || len(s) <= n {
+			return s
+		}
+		return s[:n]
+	},
+}
+
+// Validate returns an error if the template can't be parsed or if it doesn't render to a valid name.
+func Validate(tmpl string) error {
+	_, err := Generate(tmpl, MachineNameData{ClusterName: "cluster", OwnerName: "owner"})
+	return err
+}
+
+// Generate renders the template with the given data and checks the result is a valid object name.
+func Generate(tmpl string, data MachineNameData) (string, error) {
+	t, err := template.New("name").Funcs(funcs).Parse(tmpl)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to parse naming template %q", tmpl)
+	}
+
+	var buf bytes.Buffer
+	if err := t.Execute(&buf, data); err != nil {
+		return "", errors.Wrapf(err, "failed to render naming template %q", tmpl)
+	}
+
+	name := buf.String()
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return "", errors.Errorf("naming template %q rendered an invalid name %q: %s", tmpl, name, strings.Join(errs, "; "))
+	}
+	return name, nil
+}
+
+// GenerateUnique renders the template incrementing data.Index until the resulting name is not
+// reported as already in use by the exists func.
+func GenerateUnique(tmpl string, data MachineNameData, exists func(name string) (bool, error)) (string, error) {
+	for i := 0; i < maxAttempts; i++ {
+		name, err := Generate(tmpl, data)
+		if err != nil {
+			return "", err
+		}
+		found, err := exists(name)
+		if err != nil {
+			return "", errors.Wrapf(err, "failed to check if name %q is already in use", name)
+		}
+		if !found {
+			return name, nil
+		}
+		data.Index++
+	}
+	return "", errors.Errorf("failed to generate a unique name with naming template %q after %d attempts", tmpl, maxAttempts)
+}
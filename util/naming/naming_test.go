@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package naming
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/pkg/errors"
+)
+
+func TestGenerate(t *testing.T) {
+	data := MachineNameData{ClusterName: "my-cluster", OwnerName: "md-0", Index: 3}
+
+	tests := []struct {
+		name      string
+		tmpl      string
+		want      string
+		wantLen   int
+		expectErr bool
+	}{
+		{
+			name: "renders the template with the given data",
+			tmpl: "{{ .ClusterName }}-{{ .OwnerName }}-{{ .Index }}",
+			want: "my-cluster-md-0-3",
+		},
+		{
+			name: "truncates values",
+			tmpl: "{{ trunc 2 .ClusterName }}-{{ trunc 10 .OwnerName }}",
+			want: "my-md-0",
+		},
+		{
+			name:    "appends a random suffix",
+			tmpl:    "{{ .OwnerName }}-{{ random 5 }}",
+			wantLen: len("md-0-") + 5,
+		},
+		{
+			name:      "fails if the template can't be parsed",
+			tmpl:      "{{ .OwnerName ",
+			expectErr: true,
+		},
+		{
+			name:      "fails if the template references unknown values",
+			tmpl:      "{{ .Foo }}",
+			expectErr: true,
+		},
+		{
+			name:      "fails if the rendered name is invalid",
+			tmpl:      "{{ .OwnerName }}_{{ .Index }}",
+			expectErr: true,
+		},
+		{
+			name:      "fails if the rendered name is empty",
+			tmpl:      "",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			got, err := Generate(tt.tmpl, data)
+			if tt.expectErr {
+				g.Expect(err).To(HaveOccurred())
+				return
+			}
+			g.Expect(err).ToNot(HaveOccurred())
+			if tt.want != "" {
+				g.Expect(got).To(Equal(tt.want))
+			}
+			if tt.wantLen != 0 {
+				g.Expect(got).To(HaveLen(tt.wantLen))
+			}
+		})
+	}
+}
+
+func TestGenerateUnique(t *testing.T) {
+	data := MachineNameData{OwnerName: "md-0"}
+
+	t.Run("increments the index until the name is not in use", func(t *testing.T) {
+		g := NewWithT(t)
+
+		inUse := map[string]bool{"md-0-0": true, "md-0-1": true}
+		got, err := GenerateUnique("{{ .OwnerName }}-{{ .Index }}", data, func(name string) (bool, error) {
+			return inUse[name], nil
+		})
+		g.Expect(err).ToNot(HaveOccurred())
+		g.Expect(got).To(Equal("md-0-2"))
+	})
+
+	t.Run("fails if the template always renders a name in use", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := GenerateUnique("{{ .OwnerName }}", data, func(name string) (bool, error) {
+			return true, nil
+		})
+		g.Expect(err).To(HaveOccurred())
+	})
+
+	t.Run("fails if the names can't be checked", func(t *testing.T) {
+		g := NewWithT(t)
+
+		_, err := GenerateUnique("{{ .OwnerName }}", data, func(name string) (bool, error) {
+			return false, errors.New("boom")
+		})
+		g.Expect(err).To(HaveOccurred())
+	})
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package operations implements utils for recording the operation history of Cluster API objects.
+package operations
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+// Setter interface defines methods that a Cluster API object should implement in order to
+// use the operations package for recording operations.
+type Setter interface {
+	GetOperationHistory() []clusterv1.OperationRecord
+	SetOperationHistory([]clusterv1.OperationRecord)
+}
+
+// Record records an operation with the given outcome in the operation history, dropping
+// the oldest operations so that at most clusterv1.MaxOperationHistory operations are kept.
+//
+// NOTE: Only transitions are recorded: if the most recent operation has the same description and outcome,
+// e.g. because the same operation is in progress or is retried and fails at every reconcile, the history
+// is left unchanged, keeping the message and the time of the first occurrence, so that waiting for an
+// operation does not change the status at every reconcile; succeeded operations are skipped only if also
+// the message is the same, so e.g. subsequent scaling operations are all recorded.
+func Record(to Setter, operation string, outcome clusterv1.OperationOutcome, messageFormat string, messageArgs ...interface{}) {
+	if to == nil {
+		return
+	}
+
+	record := clusterv1.OperationRecord{
+		Operation: operation,
+		Outcome:   outcome,
+		Message:   fmt.Sprintf(messageFormat, messageArgs...),
+		Time:      metav1.NewTime(time.Now().UTC().Truncate(time.Second)),
+	}
+
+	history := to.GetOperationHistory()
+	if last := len(history) - 1; last >= 0 && isSameOperation(&history[last], &record) {
+		return
+	}
+
+	history = append(append([]clusterv1.OperationRecord{}, history...), record)
+	if len(history) > clusterv1.MaxOperationHistory {
+		history = history[len(history)-clusterv1.MaxOperationHistory:]
+	}
+
+	to.SetOperationHistory(history)
+}
+
+// isSameOperation returns true if the two records are for the same operation.
+func isSameOperation(a, b *clusterv1.OperationRecord) bool {
+	if a.Operation != b.Operation || a.Outcome != b.Outcome {
+		return false
+	}
+	return a.Outcome != clusterv1.OperationSucceeded || a.Message == b.Message
+}
+
+// Started records an operation that has been started.
+func Started(to Setter, operation string, messageFormat string, messageArgs ...interface{}) {
+	Record(to, operation, clusterv1.OperationStarted, messageFormat, messageArgs...)
+}
+
+// Succeeded records an operation that has been completed successfully.
+func Succeeded(to Setter, operation string, messageFormat string, messageArgs ...interface{}) {
+	Record(to, operation, clusterv1.OperationSucceeded, messageFormat, messageArgs...)
+}
+
+// Failed records an operation that failed.
+func Failed(to Setter, operation string, messageFormat string, messageArgs ...interface{}) {
+	Record(to, operation, clusterv1.OperationFailed, messageFormat, messageArgs...)
+}
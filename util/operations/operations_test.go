@@ -0,0 +1,111 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package operations
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+)
+
+func TestRecord(t *testing.T) {
+	// summary returns the operation, outcome and message of the records in the operation history.
+	summary := func(m *clusterv1.Machine) []string {
+		var s []string
+		for _, r := range m.GetOperationHistory() {
+			s = append(s, fmt.Sprintf("%s/%s/%s", r.Operation, r.Outcome, r.Message))
+		}
+		return s
+	}
+
+	t.Run("records operations", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := &clusterv1.Machine{}
+		Started(m, "Drain node", "Draining node %q", "node-1")
+		Succeeded(m, "Drain node", "Drained node %q", "node-1")
+
+		g.Expect(summary(m)).To(Equal([]string{
+			`Drain node/Started/Draining node "node-1"`,
+			`Drain node/Succeeded/Drained node "node-1"`,
+		}))
+		g.Expect(m.Status.OperationHistory[1].Time.IsZero()).To(BeFalse())
+	})
+
+	t.Run("keeps the most recent operation if retried", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := &clusterv1.Machine{}
+		Started(m, "Drain node", "Draining node %q", "node-1")
+		Failed(m, "Drain node", "error 1")
+		Failed(m, "Drain node", "error 2")
+		Succeeded(m, "Drain node", "Drained node %q", "node-1")
+		Succeeded(m, "Drain node", "Drained node %q", "node-1")
+
+		g.Expect(summary(m)).To(Equal([]string{
+			`Drain node/Started/Draining node "node-1"`,
+			"Drain node/Failed/error 1",
+			`Drain node/Succeeded/Drained node "node-1"`,
+		}))
+	})
+
+	t.Run("leaves the history unchanged while waiting for an operation", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := &clusterv1.Machine{}
+		Started(m, "Delete infrastructure", "Waiting for %q to be deleted", "infra-1")
+		m.Status.OperationHistory[0].Time.Time = m.Status.OperationHistory[0].Time.Add(-time.Hour)
+		before := m.DeepCopy()
+
+		Started(m, "Delete infrastructure", "Waiting for %q to be deleted", "infra-1")
+
+		g.Expect(m.Status).To(Equal(before.Status))
+	})
+
+	t.Run("records subsequent succeeded operations with different messages", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := &clusterv1.Machine{}
+		Succeeded(m, "Scale MachineSet", "%d→%d", 1, 2)
+		Succeeded(m, "Scale MachineSet", "%d→%d", 2, 3)
+
+		g.Expect(summary(m)).To(Equal([]string{
+			"Scale MachineSet/Succeeded/1→2",
+			"Scale MachineSet/Succeeded/2→3",
+		}))
+	})
+
+	t.Run("keeps only the most recent operations", func(t *testing.T) {
+		g := NewWithT(t)
+
+		m := &clusterv1.Machine{}
+		for i := 0; i < clusterv1.MaxOperationHistory+2; i++ {
+			Succeeded(m, "Scale MachineSet", "%d→%d", i, i+1)
+		}
+
+		g.Expect(summary(m)).To(Equal([]string{
+			"Scale MachineSet/Succeeded/2→3",
+			"Scale MachineSet/Succeeded/3→4",
+			"Scale MachineSet/Succeeded/4→5",
+			"Scale MachineSet/Succeeded/5→6",
+			"Scale MachineSet/Succeeded/6→7",
+		}))
+	})
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	conditionTransitions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capi_condition_transitions_total",
+			Help: "Number of status transitions of a condition of a Cluster or Machine, by the status transitioned to.",
+		},
+		[]string{"kind", "namespace", "name", "type", "status"},
+	)
+
+	conditionDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capi_condition_duration_seconds",
+			Help:    "Time a condition of a Cluster or Machine spent in a status before transitioning, by the status transitioned from.",
+			Buckets: []float64{1, 5, 15, 30, 60, 120, 300, 600, 900, 1800, 3600, 7200, 21600, 86400},
+		},
+		[]string{"kind", "type", "status"},
+	)
+
+	// conditionTransitionsLabels tracks the condition type and status label values recorded for each object,
+	// so the corresponding series can be deleted when the object goes away.
+	conditionTransitionsLabels = conditionLabelsTracker{labels: map[conditionMetricsKey]map[conditionLabels]struct{}{}}
+)
+
+func init() {
+	metrics.Registry.MustRegister(conditionTransitions, conditionDuration)
+}
+
+type conditionMetricsKey struct {
+	kind string
+	types.NamespacedName
+}
+
+type conditionLabels struct {
+	conditionType clusterv1.ConditionType
+	status        corev1.ConditionStatus
+}
+
+type conditionLabelsTracker struct {
+	lock   sync.Mutex
+	labels map[conditionMetricsKey]map[conditionLabels]struct{}
+}
+
+func (t *conditionLabelsTracker) add(key conditionMetricsKey, labels conditionLabels) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.labels[key]; !ok {
+		t.labels[key] = map[conditionLabels]struct{}{}
+	}
+	t.labels[key][labels] = struct{}{}
+}
+
+func (t *conditionLabelsTracker) remove(key conditionMetricsKey) map[conditionLabels]struct{} {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	labels := t.labels[key]
+	delete(t.labels, key)
+	return labels
+}
+
+// conditionMetricsKind returns the kind condition metrics are recorded for, if any.
+func conditionMetricsKind(obj client.Object) string {
+	switch obj.(type) {
+	case *clusterv1.Cluster:
+		return "Cluster"
+	case *clusterv1.Machine:
+		return "Machine"
+	default:
+		return ""
+	}
+}
+
+// recordConditionTransitions records the condition status transitions in the given patch for Clusters and Machines.
+// Changes not altering the status of a condition, e.g. changes to the reason only, are not considered transitions.
+func recordConditionTransitions(obj client.Object, diff conditions.Patch) {
+	kind := conditionMetricsKind(obj)
+	if kind == "" {
+		return
+	}
+	key := conditionMetricsKey{kind: kind, NamespacedName: client.ObjectKeyFromObject(obj)}
+
+	for _, op := range diff {
+		if op.Op != conditions.ChangeConditionPatch || op.Before.Status == op.After.Status {
+			continue
+		}
+		labels := conditionLabels{conditionType: op.After.Type, status: op.After.Status}
+		conditionTransitions.WithLabelValues(kind, key.Namespace, key.Name, string(labels.conditionType), string(labels.status)).Inc()
+		conditionTransitionsLabels.add(key, labels)
+
+		if !op.Before.LastTransitionTime.IsZero() && !op.After.LastTransitionTime.IsZero() {
+			duration := op.After.LastTransitionTime.Sub(op.Before.LastTransitionTime.Time)
+			if duration >= 0 {
+				conditionDuration.WithLabelValues(kind, string(op.Before.Type), string(op.Before.Status)).Observe(duration.Seconds())
+			}
+		}
+	}
+}
+
+// deleteConditionTransitions deletes the condition transition series recorded for an object.
+func deleteConditionTransitions(obj client.Object) {
+	kind := conditionMetricsKind(obj)
+	if kind == "" {
+		return
+	}
+	key := conditionMetricsKey{kind: kind, NamespacedName: client.ObjectKeyFromObject(obj)}
+
+	for labels := range conditionTransitionsLabels.remove(key) {
+		conditionTransitions.DeleteLabelValues(kind, key.Namespace, key.Name, string(labels.conditionType), string(labels.status))
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package patch
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/conditions"
+)
+
+func TestRecordConditionTransitions(t *testing.T) {
+	g := NewWithT(t)
+
+	now := time.Now()
+	readyFalse := &clusterv1.Condition{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(now.Add(-10 * time.Minute))}
+	readyTrue := &clusterv1.Condition{Type: clusterv1.ReadyCondition, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(now)}
+	readyFalseAgain := &clusterv1.Condition{Type: clusterv1.ReadyCondition, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(now.Add(5 * time.Minute))}
+	readyFalseReason := readyFalse.DeepCopy()
+	readyFalseReason.Reason = "AnotherReason"
+
+	machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-metrics-machine"}}
+	machineSet := &clusterv1.MachineSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test-metrics-machineset"}}
+
+	transitions := func(kind, name string, status corev1.ConditionStatus) float64 {
+		return testutil.ToFloat64(conditionTransitions.WithLabelValues(kind, "default", name, string(clusterv1.ReadyCondition), string(status)))
+	}
+
+	// Status transitions are recorded.
+	recordConditionTransitions(machine, conditions.Patch{
+		{Op: conditions.ChangeConditionPatch, Before: readyFalse, After: readyTrue},
+		{Op: conditions.ChangeConditionPatch, Before: readyTrue, After: readyFalseAgain},
+	})
+	g.Expect(transitions("Machine", machine.Name, corev1.ConditionTrue)).To(Equal(1.0))
+	g.Expect(transitions("Machine", machine.Name, corev1.ConditionFalse)).To(Equal(1.0))
+
+	// Changes not altering the status and added conditions are not transitions.
+	recordConditionTransitions(machine, conditions.Patch{
+		{Op: conditions.ChangeConditionPatch, Before: readyFalse, After: readyFalseReason},
+		{Op: conditions.AddConditionPatch, After: readyTrue},
+	})
+	g.Expect(transitions("Machine", machine.Name, corev1.ConditionTrue)).To(Equal(1.0))
+	g.Expect(transitions("Machine", machine.Name, corev1.ConditionFalse)).To(Equal(1.0))
+
+	// The time spent in the previous status is observed.
+	g.Expect(testutil.CollectAndCount(conditionDuration)).To(BeNumerically(">=", 2))
+
+	// Transitions are not recorded for kinds other than Cluster and Machine.
+	before := testutil.CollectAndCount(conditionTransitions)
+	recordConditionTransitions(machineSet, conditions.Patch{
+		{Op: conditions.ChangeConditionPatch, Before: readyFalse, After: readyTrue},
+	})
+	g.Expect(testutil.CollectAndCount(conditionTransitions)).To(Equal(before))
+
+	// Series are deleted when the object goes away.
+	deleteConditionTransitions(machine)
+	g.Expect(testutil.CollectAndCount(conditionTransitions)).To(Equal(before - 2))
+}
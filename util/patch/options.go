@@ -28,6 +28,7 @@ type Option interface {
 type HelperOptions struct {
 	// IncludeStatusObservedGeneration sets the status.observedGeneration field
 	// on the incoming object to match metadata.generation, only if there is a change.
+	// If conditions are changed too, the observedGeneration bump is issued as part of the conditions patch.
 	IncludeStatusObservedGeneration bool
 
 	// ForceOverwriteConditions allows the patch helper to overwrite conditions in case of conflicts.
@@ -41,6 +41,10 @@ type Helper struct {
 	after        *unstructured.Unstructured
 	changes      map[string]bool
 
+	// statusChanges stores the status fields changed, excluding status.conditions
+	// for objects satisfying the Cluster API conditions contract.
+	statusChanges map[string]bool
+
 	isConditionsSetter bool
 }
 
@@ -105,24 +109,51 @@ func (h *Helper) Patch(ctx context.Context, obj client.Object, opts ...Option) e
 	}
 
 	// Calculate and store the top-level field changes (e.g. "metadata", "spec", "status") we have before/after.
-	h.changes, err = h.calculateChanges(obj)
+	h.changes, h.statusChanges, err = h.calculateChanges(obj)
 	if err != nil {
 		return err
 	}
 
-	// Issue patches and return errors in an aggregate.
-	return kerrors.NewAggregate([]error{
-		// Patch the conditions first.
-		//
-		// Given that we pass in metadata.resourceVersion to perform a 3-way-merge conflict resolution,
-		// patching conditions first avoids an extra loop if spec or status patch succeeds first
-		// given that causes the resourceVersion to mutate.
-		h.patchStatusConditions(ctx, obj, options.ForceOverwriteConditions, options.OwnedConditions),
+	// Calculate the conditions changes, if any.
+	conditionsPatch, err := h.calculateConditionsPatch(obj)
+	if err != nil {
+		return err
+	}
 
-		// Then proceed to patch the rest of the object.
+	// If status.observedGeneration is the only status field to be changed in addition to conditions,
+	// coalesce the observedGeneration bump into the conditions patch, so a single write is issued.
+	var observedGeneration *int64
+	if options.IncludeStatusObservedGeneration && !conditionsPatch.IsZero() &&
+		len(h.statusChanges) == 1 && h.statusChanges["observedGeneration"] {
+		generation := obj.GetGeneration()
+		observedGeneration = &generation
+		h.changes["status"] = false
+	}
+
+	// Patch the conditions first.
+	//
+	// Given that we pass in metadata.resourceVersion to perform a 3-way-merge conflict resolution,
+	// patching conditions first avoids an extra loop if spec or status patch succeeds first
+	// given that causes the resourceVersion to mutate.
+	conditionsErr := h.patchStatusConditions(ctx, obj, conditionsPatch, observedGeneration, options.ForceOverwriteConditions, options.OwnedConditions)
+	if conditionsErr == nil {
+		recordConditionTransitions(obj, conditionsPatch)
+	}
+
+	// Then proceed to patch the rest of the object.
+	errs := []error{
+		conditionsErr,
 		h.patch(ctx, obj),
 		h.patchStatus(ctx, obj),
-	})
+	}
+
+	// Forget the condition transitions of objects going away once their last finalizer is removed.
+	if !obj.GetDeletionTimestamp().IsZero() && len(obj.GetFinalizers()) == 0 && errs[1] == nil {
+		deleteConditionTransitions(obj)
+	}
+
+	// Return errors in an aggregate.
+	return kerrors.NewAggregate(errs)
 }
 
 // patch issues a patch for metadata and spec.
@@ -158,36 +189,21 @@ func (h *Helper) patchStatus(ctx context.Context, obj client.Object) error {
 //
 // Condition changes are then applied to the latest version of the object, and if there are
 // no unresolvable conflicts, the patch is sent again.
-func (h *Helper) patchStatusConditions(ctx context.Context, obj client.Object, forceOverwrite bool, ownedConditions []clusterv1.ConditionType) error {
-	// Nothing to do if the object isn't a condition patcher.
-	if !h.isConditionsSetter {
+//
+// If observedGeneration is not nil, status.observedGeneration is set as part of the same patch.
+func (h *Helper) patchStatusConditions(ctx context.Context, obj client.Object, diff conditions.Patch, observedGeneration *int64, forceOverwrite bool, ownedConditions []clusterv1.ConditionType) error {
+	// Return early if there are no changes; this is always the case if the object isn't a condition patcher.
+	if diff.IsZero() {
 		return nil
 	}
 
-	// Make sure our before/after objects satisfy the proper interface before continuing.
-	//
-	// NOTE: The checks and error below are done so that we don't panic if any of the objects don't satisfy the
-	// interface any longer, although this shouldn't happen because we already check when creating the patcher.
 	before, ok := h.beforeObject.(conditions.Getter)
 	if !ok {
 		return errors.Errorf("object %s doesn't satisfy conditions.Getter, cannot patch", before.GetObjectKind())
 	}
-	after, ok := obj.(conditions.Getter)
-	if !ok {
-		return errors.Errorf("object %s doesn't satisfy conditions.Getter, cannot patch", after.GetObjectKind())
-	}
-
-	// Store the diff from the before/after object, and return early if there are no changes.
-	diff := conditions.NewPatch(
-		before,
-		after,
-	)
-	if diff.IsZero() {
-		return nil
-	}
 
 	// Make a copy of the object and store the key used if we have conflicts.
-	key := client.ObjectKeyFromObject(after)
+	key := client.ObjectKeyFromObject(obj)
 
 	// Define and start a backoff loop to handle conflicts
 	// between controllers working on the same object.
@@ -219,6 +235,13 @@ func (h *Helper) patchStatusConditions(ctx context.Context, obj client.Object, f
 			return false, err
 		}
 
+		// Set the observedGeneration coalesced into this patch, if any.
+		if observedGeneration != nil {
+			if err := setStatusObservedGeneration(latest, *observedGeneration); err != nil {
+				return false, err
+			}
+		}
+
 		// Issue the patch.
 		err := h.client.Status().Patch(ctx, latest, conditionsPatch)
 		switch {
@@ -233,6 +256,29 @@ func (h *Helper) patchStatusConditions(ctx context.Context, obj client.Object, f
 	})
 }
 
+// calculateConditionsPatch returns the conditions changes from the before/after objects;
+// changes not altering the state of a condition, e.g. changes to LastTransitionTime only, are ignored.
+func (h *Helper) calculateConditionsPatch(obj client.Object) (conditions.Patch, error) {
+	// Nothing to do if the object isn't a condition patcher.
+	if !h.isConditionsSetter {
+		return nil, nil
+	}
+
+	// Make sure our before/after objects satisfy the proper interface before continuing.
+	//
+	// NOTE: The checks and error below are done so that we don't panic if any of the objects don't satisfy the
+	// interface any longer, although this shouldn't happen because we already check when creating the patcher.
+	before, ok := h.beforeObject.(conditions.Getter)
+	if !ok {
+		return nil, errors.Errorf("object %s doesn't satisfy conditions.Getter, cannot patch", before.GetObjectKind())
+	}
+	after, ok := obj.(conditions.Getter)
+	if !ok {
+		return nil, errors.Errorf("object %s doesn't satisfy conditions.Getter, cannot patch", after.GetObjectKind())
+	}
+	return conditions.NewPatch(before, after), nil
+}
+
 // calculatePatch returns the before/after objects to be given in a controller-runtime patch, scoped down to the absolute necessary.
 func (h *Helper) calculatePatch(afterObj client.Object, focus patchType) (client.Object, client.Object, error) {
 	// Get a shallow unsafe copy of the before/after object in unstructured form.
@@ -258,24 +304,38 @@ func (h *Helper) shouldPatch(in string) bool {
 
 // calculate changes tries to build a patch from the before/after objects we have
 // and store in a map which top-level fields (e.g. `metadata`, `spec`, `status`, etc.) have changed.
-func (h *Helper) calculateChanges(after client.Object) (map[string]bool, error) {
+//
+// Status fields changed are returned in a separate map; for objects satisfying the Cluster API conditions contract,
+// status.conditions is excluded given that conditions are patched separately, so a status patch is issued
+// only if other status fields have changed.
+func (h *Helper) calculateChanges(after client.Object) (map[string]bool, map[string]bool, error) {
 	// Calculate patch data.
 	patch := client.MergeFrom(h.beforeObject)
 	diff, err := patch.Data(after)
 	if err != nil {
-		return nil, errors.Wrapf(err, "failed to calculate patch data")
+		return nil, nil, errors.Wrapf(err, "failed to calculate patch data")
 	}
 
 	// Unmarshal patch data into a local map.
 	patchDiff := map[string]interface{}{}
 	if err := json.Unmarshal(diff, &patchDiff); err != nil {
-		return nil, errors.Wrapf(err, "failed to unmarshal patch data into a map")
+		return nil, nil, errors.Wrapf(err, "failed to unmarshal patch data into a map")
 	}
 
-	// Return the map.
+	// Return the maps.
 	res := make(map[string]bool, len(patchDiff))
 	for key := range patchDiff {
 		res[key] = true
 	}
-	return res, nil
+	statusRes := map[string]bool{}
+	if statusDiff, ok := patchDiff["status"].(map[string]interface{}); ok {
+		for key := range statusDiff {
+			if h.isConditionsSetter && key == "conditions" {
+				continue
+			}
+			statusRes[key] = true
+		}
+		res["status"] = len(statusRes) > 0
+	}
+	return res, statusRes, nil
 }
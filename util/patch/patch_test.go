@@ -17,8 +17,10 @@ limitations under the License.
 package patch
 
 import (
+	"context"
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	. "github.com/onsi/ginkgo"
@@ -28,12 +30,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/utils/pointer"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
 	"sigs.k8s.io/cluster-api/controllers/external"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 var _ = Describe("Patch Helper", func() {
@@ -875,3 +879,144 @@ func TestNewHelperNil(t *testing.T) {
 	_, err = NewHelper(nil, nil)
 	g.Expect(err).ToNot(BeNil())
 }
+
+// patchCountingClient counts the patches issued to the object and its status.
+type patchCountingClient struct {
+	client.Client
+	patches       int
+	statusPatches int
+}
+
+func (c *patchCountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.patches++
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *patchCountingClient) Status() client.StatusWriter {
+	return &patchCountingStatusWriter{StatusWriter: c.Client.Status(), c: c}
+}
+
+type patchCountingStatusWriter struct {
+	client.StatusWriter
+	c *patchCountingClient
+}
+
+func (w *patchCountingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.c.statusPatches++
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
+
+func TestPatchHelperWrites(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = clusterv1.AddToScheme(scheme)
+
+	newMachine := func() *clusterv1.Machine {
+		return &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "test-machine",
+				Namespace:  "default",
+				Generation: 2,
+			},
+			Status: clusterv1.MachineStatus{
+				ObservedGeneration: 1,
+				Conditions: clusterv1.Conditions{
+					*conditions.TrueCondition(clusterv1.ReadyCondition),
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name                     string
+		mutate                   func(m *clusterv1.Machine)
+		opts                     []Option
+		wantPatches              int
+		wantStatusPatches        int
+		wantObservedGeneration   int64
+		wantReadyConditionStatus corev1.ConditionStatus
+	}{
+		{
+			name:                     "no changes",
+			mutate:                   func(m *clusterv1.Machine) {},
+			wantObservedGeneration:   1,
+			wantReadyConditionStatus: corev1.ConditionTrue,
+		},
+		{
+			name: "condition refreshed without changing its state",
+			mutate: func(m *clusterv1.Machine) {
+				m.Status.Conditions[0].LastTransitionTime = metav1.NewTime(m.Status.Conditions[0].LastTransitionTime.Add(time.Minute))
+			},
+			wantObservedGeneration:   1,
+			wantReadyConditionStatus: corev1.ConditionTrue,
+		},
+		{
+			name: "condition changed",
+			mutate: func(m *clusterv1.Machine) {
+				conditions.MarkFalse(m, clusterv1.ReadyCondition, "Foo", clusterv1.ConditionSeverityInfo, "")
+			},
+			wantStatusPatches:        1,
+			wantObservedGeneration:   1,
+			wantReadyConditionStatus: corev1.ConditionFalse,
+		},
+		{
+			name: "condition changed, observedGeneration coalesced into the conditions patch",
+			mutate: func(m *clusterv1.Machine) {
+				conditions.MarkFalse(m, clusterv1.ReadyCondition, "Foo", clusterv1.ConditionSeverityInfo, "")
+			},
+			opts:                     []Option{WithStatusObservedGeneration{}},
+			wantStatusPatches:        1,
+			wantObservedGeneration:   2,
+			wantReadyConditionStatus: corev1.ConditionFalse,
+		},
+		{
+			name: "condition and other status fields changed",
+			mutate: func(m *clusterv1.Machine) {
+				conditions.MarkFalse(m, clusterv1.ReadyCondition, "Foo", clusterv1.ConditionSeverityInfo, "")
+				m.Status.Phase = string(clusterv1.MachinePhaseRunning)
+			},
+			opts:                     []Option{WithStatusObservedGeneration{}},
+			wantStatusPatches:        2,
+			wantObservedGeneration:   2,
+			wantReadyConditionStatus: corev1.ConditionFalse,
+		},
+		{
+			name:                     "observedGeneration only",
+			mutate:                   func(m *clusterv1.Machine) {},
+			opts:                     []Option{WithStatusObservedGeneration{}},
+			wantStatusPatches:        1,
+			wantObservedGeneration:   2,
+			wantReadyConditionStatus: corev1.ConditionTrue,
+		},
+		{
+			name: "spec changed",
+			mutate: func(m *clusterv1.Machine) {
+				m.Spec.ClusterName = "foo"
+			},
+			wantPatches:              1,
+			wantObservedGeneration:   1,
+			wantReadyConditionStatus: corev1.ConditionTrue,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := NewWithT(t)
+
+			m := newMachine()
+			c := &patchCountingClient{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(m).Build()}
+			g.Expect(c.Get(ctx, client.ObjectKeyFromObject(m), m)).To(Succeed())
+
+			patcher, err := NewHelper(m, c)
+			g.Expect(err).ToNot(HaveOccurred())
+
+			tt.mutate(m)
+			g.Expect(patcher.Patch(ctx, m, tt.opts...)).To(Succeed())
+			g.Expect(c.patches).To(Equal(tt.wantPatches))
+			g.Expect(c.statusPatches).To(Equal(tt.wantStatusPatches))
+
+			after := &clusterv1.Machine{}
+			g.Expect(c.Get(ctx, client.ObjectKeyFromObject(m), after)).To(Succeed())
+			g.Expect(after.Status.ObservedGeneration).To(Equal(tt.wantObservedGeneration))
+			g.Expect(conditions.Get(after, clusterv1.ReadyCondition).Status).To(Equal(tt.wantReadyConditionStatus))
+		})
+	}
+}
@@ -47,6 +47,18 @@ func unstructuredHasStatus(u *unstructured.Unstructured) bool {
 	return ok
 }
 
+// setStatusObservedGeneration sets status.observedGeneration on the given object.
+func setStatusObservedGeneration(obj runtime.Object, observedGeneration int64) error {
+	u, err := toUnstructured(obj)
+	if err != nil {
+		return err
+	}
+	if err := unstructured.SetNestedField(u.Object, observedGeneration, "status", "observedGeneration"); err != nil {
+		return err
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, obj)
+}
+
 func toUnstructured(obj runtime.Object) (*unstructured.Unstructured, error) {
 	// If the incoming object is already unstructured, perform a deep copy first
 	// otherwise DefaultUnstructuredConverter ends up returning the inner map without
@@ -113,6 +113,36 @@ func ClusterUpdateInfraReady(logger logr.Logger) predicate.Funcs {
 	}
 }
 
+// ClusterUpdateControlPlaneInitialized returns a predicate that returns true for an update event when a cluster has
+// Status.ControlPlaneInitialized changed from false to true.
+func ClusterUpdateControlPlaneInitialized(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			log := logger.WithValues("predicate", "ClusterUpdateControlPlaneInitialized", "eventType", "update")
+
+			oldCluster, ok := e.ObjectOld.(*clusterv1.Cluster)
+			if !ok {
+				log.V(4).Info("Expected Cluster", "type", e.ObjectOld.GetObjectKind().GroupVersionKind().String())
+				return false
+			}
+			log = log.WithValues("namespace", oldCluster.Namespace, "cluster", oldCluster.Name)
+
+			newCluster := e.ObjectNew.(*clusterv1.Cluster)
+
+			if !oldCluster.Status.ControlPlaneInitialized && newCluster.Status.ControlPlaneInitialized {
+				log.V(4).Info("Cluster control plane became initialized, allowing further processing")
+				return true
+			}
+
+			log.V(4).Info("Cluster control plane did not become initialized, blocking further processing")
+			return false
+		},
+		CreateFunc:  func(e event.CreateEvent) bool { return false },
+		DeleteFunc:  func(e event.DeleteEvent) bool { return false },
+		GenericFunc: func(e event.GenericEvent) bool { return false },
+	}
+}
+
 // ClusterUpdateUnpaused returns a predicate that returns true for an update event when a cluster has Spec.Paused changed from true to false
 // it also returns true if the resource provided is not a Cluster to allow for use with controller-runtime NewControllerManagedBy
 func ClusterUpdateUnpaused(logger logr.Logger) predicate.Funcs {
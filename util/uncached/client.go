@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uncached
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/cluster-api/util/secret"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// bootstrapGroup is the API group of the bootstrap providers, owning the bootstrap data Secrets.
+const bootstrapGroup = "bootstrap.cluster.x-k8s.io"
+
+// Options define how the objects bypassing the manager cache are read.
+type Options struct {
+	// SecretTTL is the time bootstrap data and kubeconfig Secrets are served from a read-through cache after being
+	// read; 0 disables the cache. Secrets are evicted from the cache when they are written through the client, but
+	// changes made by other clients are seen only once the TTL expires.
+	SecretTTL time.Duration
+}
+
+// NewClientBuilder returns a manager ClientBuilder building the default delegating client, where the reads of the
+// objects bypassing the manager cache are recorded in the capi_client_direct_reads_total metric, and bootstrap
+// data and kubeconfig Secrets are optionally served from a read-through TTL cache.
+func NewClientBuilder(options Options) manager.ClientBuilder {
+	return &clientBuilder{
+		ClientBuilder: manager.NewClientBuilder(),
+		options:       options,
+	}
+}
+
+type clientBuilder struct {
+	manager.ClientBuilder
+	options  Options
+	uncached []client.Object
+}
+
+func (b *clientBuilder) WithUncached(objs ...client.Object) manager.ClientBuilder {
+	b.ClientBuilder.WithUncached(objs...)
+	b.uncached = append(b.uncached, objs...)
+	return b
+}
+
+func (b *clientBuilder) Build(cache cache.Cache, config *rest.Config, options client.Options) (client.Client, error) {
+	c, err := b.ClientBuilder.Build(cache, config, options)
+	if err != nil {
+		return nil, err
+	}
+	return newClient(c, b.uncached, b.options)
+}
+
+// uncachedClient wraps a client, instrumenting the reads of the objects bypassing the manager cache.
+type uncachedClient struct {
+	client.Client
+	uncachedKinds map[schema.GroupVersionKind]struct{}
+	secrets       *secretCache
+}
+
+func newClient(c client.Client, uncached []client.Object, options Options) (*uncachedClient, error) {
+	uncachedKinds := map[schema.GroupVersionKind]struct{}{}
+	for _, obj := range uncached {
+		gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+		if err != nil {
+			return nil, err
+		}
+		uncachedKinds[gvk] = struct{}{}
+	}
+
+	var secrets *secretCache
+	if options.SecretTTL > 0 {
+		secrets = newSecretCache(options.SecretTTL)
+	}
+	return &uncachedClient{
+		Client:        c,
+		uncachedKinds: uncachedKinds,
+		secrets:       secrets,
+	}, nil
+}
+
+// Get reads an object, serving bootstrap data and kubeconfig Secrets from the read-through cache, if enabled.
+func (c *uncachedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	s, isSecret := obj.(*corev1.Secret)
+	if isSecret && c.secrets != nil && c.secrets.get(key, s) {
+		secretCacheHits.Inc()
+		return nil
+	}
+
+	c.recordRead(obj, "get")
+	if err := c.Client.Get(ctx, key, obj); err != nil {
+		return err
+	}
+
+	if isSecret && c.secrets != nil && isBootstrapOrKubeconfigSecret(s) {
+		c.secrets.set(key, s)
+	}
+	return nil
+}
+
+// List lists objects; lists are never served from the read-through cache.
+func (c *uncachedClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.recordRead(list, "list")
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *uncachedClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	defer c.evict(obj)
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *uncachedClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	defer c.evict(obj)
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *uncachedClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	defer c.evict(obj)
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *uncachedClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if _, ok := obj.(*corev1.Secret); ok && c.secrets != nil {
+		c.secrets.clear()
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+// recordRead records a read of an object, or of a list, bypassing the manager cache.
+func (c *uncachedClient) recordRead(obj runtime.Object, verb string) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return
+	}
+	gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	if _, ok := c.uncachedKinds[gvk]; ok {
+		directReads.WithLabelValues(gvk.Kind, verb).Inc()
+	}
+}
+
+// evict evicts a Secret written through the client from the read-through cache.
+func (c *uncachedClient) evict(obj client.Object) {
+	if _, ok := obj.(*corev1.Secret); ok && c.secrets != nil {
+		c.secrets.delete(client.ObjectKeyFromObject(obj))
+	}
+}
+
+// isBootstrapOrKubeconfigSecret returns true for the Secrets storing the bootstrap data of a Machine, i.e. owned by a
+// bootstrap config, or the kubeconfig of a Cluster.
+func isBootstrapOrKubeconfigSecret(s *corev1.Secret) bool {
+	if s.Type != clusterv1.ClusterSecretType {
+		return false
+	}
+	if strings.HasSuffix(s.Name, "-"+string(secret.Kubeconfig)) {
+		return true
+	}
+	for _, ref := range s.OwnerReferences {
+		if gv, err := schema.ParseGroupVersion(ref.APIVersion); err == nil && gv.Group == bootstrapGroup {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uncached
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1alpha4"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestUncachedClientDirectReads(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	c, err := newClient(fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "cm", Namespace: "default"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "default"}},
+	).Build(), []client.Object{&corev1.ConfigMap{}}, Options{})
+	g.Expect(err).NotTo(HaveOccurred())
+
+	reads := func(kind, verb string) float64 {
+		return testutil.ToFloat64(directReads.WithLabelValues(kind, verb))
+	}
+	getsBefore, listsBefore, podGetsBefore := reads("ConfigMap", "get"), reads("ConfigMap", "list"), reads("Pod", "get")
+
+	g.Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "cm"}, &corev1.ConfigMap{})).To(Succeed())
+	g.Expect(c.List(context.TODO(), &corev1.ConfigMapList{})).To(Succeed())
+	g.Expect(c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "pod"}, &corev1.Pod{})).To(Succeed())
+
+	g.Expect(reads("ConfigMap", "get")).To(Equal(getsBefore + 1))
+	g.Expect(reads("ConfigMap", "list")).To(Equal(listsBefore + 1))
+	// Pods are not bypassing the cache, so their reads are not recorded.
+	g.Expect(reads("Pod", "get")).To(Equal(podGetsBefore))
+}
+
+func TestUncachedClientSecretCache(t *testing.T) {
+	g := NewWithT(t)
+
+	scheme := runtime.NewScheme()
+	g.Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+
+	kubeconfig := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-kubeconfig", Namespace: "default"},
+		Type:       clusterv1.ClusterSecretType,
+		Data:       map[string][]byte{"value": []byte("kubeconfig")},
+	}
+	bootstrapData := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "machine",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{
+				{APIVersion: "bootstrap.cluster.x-k8s.io/v1alpha4", Kind: "KubeadmConfig", Name: "machine"},
+			},
+		},
+		Type: clusterv1.ClusterSecretType,
+		Data: map[string][]byte{"value": []byte("bootstrap")},
+	}
+	ca := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-ca", Namespace: "default"},
+		Type:       clusterv1.ClusterSecretType,
+		Data:       map[string][]byte{"tls.crt": []byte("ca")},
+	}
+	other := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-kubeconfig", Namespace: "default"},
+		Data:       map[string][]byte{"value": []byte("other")},
+	}
+
+	underlying := fake.NewClientBuilder().WithScheme(scheme).WithObjects(kubeconfig, bootstrapData, ca, other).Build()
+	c, err := newClient(underlying, []client.Object{&corev1.Secret{}}, Options{SecretTTL: time.Minute})
+	g.Expect(err).NotTo(HaveOccurred())
+	now := time.Now()
+	c.secrets.now = func() time.Time { return now }
+
+	// getAndChange reads a Secret through the client, then changes it bypassing the client, and returns the value
+	// read again through the client.
+	getAndChange := func(g *WithT, s *corev1.Secret) map[string][]byte {
+		key := client.ObjectKeyFromObject(s)
+		g.Expect(c.Get(context.TODO(), key, &corev1.Secret{})).To(Succeed())
+
+		changed := &corev1.Secret{}
+		g.Expect(underlying.Get(context.TODO(), key, changed)).To(Succeed())
+		changed.Data = map[string][]byte{"value": []byte("changed")}
+		g.Expect(underlying.Update(context.TODO(), changed)).To(Succeed())
+
+		got := &corev1.Secret{}
+		g.Expect(c.Get(context.TODO(), key, got)).To(Succeed())
+		return got.Data
+	}
+
+	t.Run("serves kubeconfig and bootstrap data Secrets from the cache", func(t *testing.T) {
+		g := NewWithT(t)
+		hitsBefore := testutil.ToFloat64(secretCacheHits)
+
+		g.Expect(getAndChange(g, kubeconfig)).To(Equal(kubeconfig.Data))
+		g.Expect(getAndChange(g, bootstrapData)).To(Equal(bootstrapData.Data))
+		g.Expect(testutil.ToFloat64(secretCacheHits)).To(Equal(hitsBefore + 2))
+	})
+
+	t.Run("does not serve other Secrets from the cache", func(t *testing.T) {
+		g := NewWithT(t)
+
+		g.Expect(getAndChange(g, ca)).To(HaveKeyWithValue("value", []byte("changed")))
+		g.Expect(getAndChange(g, other)).To(HaveKeyWithValue("value", []byte("changed")))
+	})
+
+	t.Run("reads Secrets again once the TTL is expired", func(t *testing.T) {
+		g := NewWithT(t)
+		now = now.Add(time.Minute)
+
+		got := &corev1.Secret{}
+		g.Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(kubeconfig), got)).To(Succeed())
+		g.Expect(got.Data).To(HaveKeyWithValue("value", []byte("changed")))
+	})
+
+	t.Run("evicts Secrets written through the client", func(t *testing.T) {
+		g := NewWithT(t)
+
+		got := &corev1.Secret{}
+		g.Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(bootstrapData), got)).To(Succeed())
+		got.Data = map[string][]byte{"value": []byte("updated")}
+		g.Expect(c.Update(context.TODO(), got)).To(Succeed())
+
+		got = &corev1.Secret{}
+		g.Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(bootstrapData), got)).To(Succeed())
+		g.Expect(got.Data).To(HaveKeyWithValue("value", []byte("updated")))
+
+		g.Expect(c.Delete(context.TODO(), got)).To(Succeed())
+		g.Expect(c.Get(context.TODO(), client.ObjectKeyFromObject(bootstrapData), &corev1.Secret{})).NotTo(Succeed())
+	})
+}
@@ -0,0 +1,20 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package uncached implements a manager client builder instrumenting the reads of the objects which bypass the
+// manager cache, e.g. ConfigMaps and Secrets, and optionally serving bootstrap data and kubeconfig Secrets from a
+// read-through TTL cache, so that hot loops do not hammer the API server.
+package uncached
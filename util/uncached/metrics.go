@@ -0,0 +1,43 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uncached
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	directReads = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capi_client_direct_reads_total",
+			Help: "Number of reads bypassing the manager cache, served by the API server, by kind and verb.",
+		},
+		[]string{"kind", "verb"},
+	)
+
+	secretCacheHits = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "capi_client_secret_cache_hits_total",
+			Help: "Number of reads of bootstrap data and kubeconfig Secrets served by the read-through TTL cache.",
+		},
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(directReads, secretCacheHits)
+}
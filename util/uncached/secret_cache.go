@@ -0,0 +1,90 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package uncached
+
+import (
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// secretCache is a TTL cache of Secrets.
+type secretCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	lock    sync.Mutex
+	entries map[client.ObjectKey]secretCacheEntry
+}
+
+type secretCacheEntry struct {
+	secret  *corev1.Secret
+	expires time.Time
+}
+
+func newSecretCache(ttl time.Duration) *secretCache {
+	return &secretCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[client.ObjectKey]secretCacheEntry{},
+	}
+}
+
+// get copies the cached Secret with the given key into s, and returns true if the Secret is cached and not expired.
+func (c *secretCache) get(key client.ObjectKey, s *corev1.Secret) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return false
+	}
+	entry.secret.DeepCopyInto(s)
+	return true
+}
+
+// set caches a copy of a Secret, and purges the expired ones.
+func (c *secretCache) set(key client.ObjectKey, s *corev1.Secret) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	for k, entry := range c.entries {
+		if !now.Before(entry.expires) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = secretCacheEntry{secret: s.DeepCopy(), expires: now.Add(c.ttl)}
+}
+
+func (c *secretCache) delete(key client.ObjectKey) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, key)
+}
+
+func (c *secretCache) clear() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.entries = map[client.ObjectKey]secretCacheEntry{}
+}